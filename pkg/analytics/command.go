@@ -7,6 +7,7 @@ import (
 	"fmt"
 	esSinkClient "github.com/opengovern/og-util/pkg/es/ingest/client"
 	"github.com/opengovern/og-util/pkg/jq"
+	"github.com/opengovern/og-util/pkg/opengovernance-es-sdk"
 
 	"github.com/nats-io/nats.go/jetstream"
 	"github.com/opengovern/og-util/pkg/config"
@@ -76,6 +77,7 @@ type Worker struct {
 	schedulerClient describeClient.SchedulerServiceClient
 	inventoryClient inventoryClient.InventoryServiceClient
 	sinkClient      esSinkClient.EsSinkServiceClient
+	esClient        opengovernance.Client
 }
 
 func NewWorker(
@@ -136,6 +138,19 @@ func NewWorker(
 	w.schedulerClient = describeClient.NewSchedulerServiceClient(conf.Scheduler.BaseURL)
 	w.inventoryClient = inventoryClient.NewInventoryServiceClient(conf.Inventory.BaseURL)
 	w.sinkClient = esSinkClient.NewEsSinkServiceClient(logger, conf.EsSink.BaseURL)
+
+	w.esClient, err = opengovernance.NewClient(opengovernance.ClientConfig{
+		Addresses:     []string{conf.ElasticSearch.Address},
+		Username:      &conf.ElasticSearch.Username,
+		Password:      &conf.ElasticSearch.Password,
+		IsOnAks:       &conf.ElasticSearch.IsOnAks,
+		IsOpenSearch:  &conf.ElasticSearch.IsOpenSearch,
+		AwsRegion:     &conf.ElasticSearch.AwsRegion,
+		AssumeRoleArn: &conf.ElasticSearch.AssumeRoleArn,
+	})
+	if err != nil {
+		return nil, err
+	}
 	return w, nil
 }
 
@@ -189,7 +204,7 @@ func (w *Worker) Run(ctx context.Context) error {
 
 		w.logger.Info("Running the job", zap.Uint("id", job.JobID))
 
-		result := job.Do(w.jq, w.db, steampipeConn, w.onboardClient, w.schedulerClient, w.inventoryClient, w.sinkClient, w.logger, w.config, ctx)
+		result := job.Do(w.jq, w.db, steampipeConn, w.onboardClient, w.schedulerClient, w.inventoryClient, w.sinkClient, w.esClient, w.logger, w.config, ctx)
 
 		w.logger.Info("Job finished", zap.Uint("jobID", job.JobID))
 
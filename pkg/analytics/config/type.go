@@ -17,4 +17,19 @@ type WorkerConfig struct {
 	TelemetryWorkspaceID string `yaml:"telemetry_workspace_id"`
 	TelemetryHostname    string `yaml:"telemetry_hostname"`
 	TelemetryBaseURL     string `yaml:"telemetry_base_url"`
+
+	SpendAnomaly SpendAnomalyConfig `yaml:"spend_anomaly"`
+}
+
+type SpendAnomalyConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Sensitivity is one of low, medium and high
+	Sensitivity         string `yaml:"sensitivity"`
+	MinCostDelta        int    `yaml:"min_cost_delta"`
+	MinDeviationPercent int    `yaml:"min_deviation_percent"`
+	HistoryDays         int    `yaml:"history_days"`
+	// EvaluationDays is the number of most recent days with spend that are checked for anomalies on each job
+	EvaluationDays int    `yaml:"evaluation_days"`
+	WebhookURL     string `yaml:"webhook_url"`
+	WebhookSecret  string `yaml:"webhook_secret"`
 }
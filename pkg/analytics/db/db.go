@@ -14,6 +14,11 @@ func (db Database) Initialize() error {
 	err := db.orm.AutoMigrate(
 		&AnalyticMetric{},
 		&MetricTag{},
+		&TagPolicy{},
+		&TagPolicyKey{},
+		&SpendAnomaly{},
+		&SpendAnomalyContributor{},
+		&SpendAnomalyMute{},
 	)
 	if err != nil {
 		return err
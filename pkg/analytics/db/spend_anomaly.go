@@ -0,0 +1,239 @@
+package db
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SpendAnomalyStatus string
+
+const (
+	SpendAnomalyStatusOpen         SpendAnomalyStatus = "open"
+	SpendAnomalyStatusAcknowledged SpendAnomalyStatus = "acknowledged"
+)
+
+// SpendAnomaly is a day on which the spend of a connection deviated from its baseline.
+// Expected and actual costs are the totals of the evaluated spend metrics of the connection.
+type SpendAnomaly struct {
+	ID               string `gorm:"primaryKey"`
+	ConnectionID     string `gorm:"index"`
+	ConnectionName   string
+	Connector        string
+	Date             string `gorm:"index"`
+	DateEpoch        int64
+	ExpectedCost     float64
+	ActualCost       float64
+	Deviation        float64
+	DeviationPercent float64
+	Score            float64
+
+	Status         SpendAnomalyStatus
+	AcknowledgedBy string
+	AcknowledgedAt *time.Time
+	Note           string
+	// NotifiedAt is when the anomaly was sent to the webhook, nil if it has not been sent yet
+	NotifiedAt *time.Time
+	JobID      uint
+
+	Contributors []SpendAnomalyContributor `gorm:"foreignKey:AnomalyID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// SpendAnomalyContributor is the deviation of a single spend metric on the day of the anomaly.
+type SpendAnomalyContributor struct {
+	AnomalyID        string `gorm:"primaryKey"`
+	MetricID         string `gorm:"primaryKey"`
+	MetricName       string
+	ExpectedCost     float64
+	ActualCost       float64
+	Deviation        float64
+	DeviationPercent float64
+	Score            float64
+	Anomalous        bool
+}
+
+// SpendAnomalyMute stops anomalies from being raised for a connection, or for a single metric of it
+// when MetricID is set, until it expires.
+type SpendAnomalyMute struct {
+	ID           uint `gorm:"primaryKey"`
+	ConnectionID string
+	MetricID     string
+	Reason       string
+	CreatedBy    string
+	ExpiresAt    *time.Time
+	CreatedAt    time.Time
+}
+
+// MutedBy reports whether the mutes cover the connection of the anomaly, or each of its anomalous metrics.
+func (a SpendAnomaly) MutedBy(mutes []SpendAnomalyMute) bool {
+	metricMuted := func(metricID string) bool {
+		for _, mute := range mutes {
+			if mute.ConnectionID == a.ConnectionID && (mute.MetricID == "" || mute.MetricID == metricID) {
+				return true
+			}
+		}
+		return false
+	}
+	if metricMuted("") {
+		return true
+	}
+
+	anomalous := 0
+	for _, c := range a.Contributors {
+		if !c.Anomalous {
+			continue
+		}
+		anomalous++
+		if !metricMuted(c.MetricID) {
+			return false
+		}
+	}
+	return anomalous > 0
+}
+
+// UpsertSpendAnomaly creates the anomaly or updates its costs and contributors, keeping the
+// acknowledgement and notification state of an existing one.
+func (db Database) UpsertSpendAnomaly(anomaly SpendAnomaly) error {
+	return db.orm.Transaction(func(tx *gorm.DB) error {
+		contributors := anomaly.Contributors
+		anomaly.Contributors = nil
+		if anomaly.Status == "" {
+			anomaly.Status = SpendAnomalyStatusOpen
+		}
+		err := tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"connection_name", "connector", "expected_cost", "actual_cost",
+				"deviation", "deviation_percent", "score", "job_id", "updated_at"}),
+		}).Create(&anomaly).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Where("anomaly_id = ?", anomaly.ID).Delete(&SpendAnomalyContributor{}).Error
+		if err != nil {
+			return err
+		}
+		for _, contributor := range contributors {
+			contributor.AnomalyID = anomaly.ID
+			err = tx.Create(&contributor).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (db Database) ListSpendAnomalies(connectionIDs []string, status *SpendAnomalyStatus, startTime, endTime *time.Time) ([]SpendAnomaly, error) {
+	var s []SpendAnomaly
+	tx := db.orm.Model(SpendAnomaly{}).Preload(clause.Associations)
+	if len(connectionIDs) > 0 {
+		tx = tx.Where("connection_id IN ?", connectionIDs)
+	}
+	if status != nil {
+		tx = tx.Where("status = ?", *status)
+	}
+	if startTime != nil {
+		tx = tx.Where("date_epoch >= ?", startTime.UnixMilli())
+	}
+	if endTime != nil {
+		tx = tx.Where("date_epoch <= ?", endTime.UnixMilli())
+	}
+	tx = tx.Order("date_epoch desc").Order("connection_id").Find(&s)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+
+	return s, nil
+}
+
+func (db Database) GetSpendAnomaly(id string) (*SpendAnomaly, error) {
+	var s SpendAnomaly
+	tx := db.orm.Model(SpendAnomaly{}).Preload(clause.Associations).Where("id = ?", id).First(&s)
+	if tx.Error != nil {
+		if tx.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, tx.Error
+	}
+
+	return &s, nil
+}
+
+func (db Database) AcknowledgeSpendAnomaly(id, userID, note string) error {
+	now := time.Now()
+	return db.orm.Model(SpendAnomaly{}).Where("id = ?", id).Updates(map[string]any{
+		"status":          SpendAnomalyStatusAcknowledged,
+		"acknowledged_by": userID,
+		"acknowledged_at": now,
+		"note":            note,
+	}).Error
+}
+
+// ListUnnotifiedSpendAnomalies returns the open anomalies that have not been sent to the webhook yet, leaving out
+// the ones muted since they were detected.
+func (db Database) ListUnnotifiedSpendAnomalies() ([]SpendAnomaly, error) {
+	var s []SpendAnomaly
+	tx := db.orm.Model(SpendAnomaly{}).Preload(clause.Associations).
+		Where("status = ?", SpendAnomalyStatusOpen).
+		Where("notified_at IS NULL").
+		Order("date_epoch").Find(&s)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+
+	mutes, err := db.ListSpendAnomalyMutes(true)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]SpendAnomaly, 0, len(s))
+	for _, anomaly := range s {
+		if !anomaly.MutedBy(mutes) {
+			res = append(res, anomaly)
+		}
+	}
+	return res, nil
+}
+
+func (db Database) MarkSpendAnomalyNotified(id string, notifiedAt time.Time) error {
+	return db.orm.Model(SpendAnomaly{}).Where("id = ?", id).Update("notified_at", notifiedAt).Error
+}
+
+func (db Database) ListSpendAnomalyMutes(activeOnly bool) ([]SpendAnomalyMute, error) {
+	var s []SpendAnomalyMute
+	tx := db.orm.Model(SpendAnomalyMute{})
+	if activeOnly {
+		tx = tx.Where("expires_at IS NULL OR expires_at > ?", time.Now())
+	}
+	tx = tx.Order("id").Find(&s)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+
+	return s, nil
+}
+
+func (db Database) CreateSpendAnomalyMute(mute *SpendAnomalyMute) error {
+	return db.orm.Create(mute).Error
+}
+
+func (db Database) GetSpendAnomalyMute(id uint) (*SpendAnomalyMute, error) {
+	var s SpendAnomalyMute
+	tx := db.orm.Model(SpendAnomalyMute{}).Where("id = ?", id).First(&s)
+	if tx.Error != nil {
+		if tx.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, tx.Error
+	}
+
+	return &s, nil
+}
+
+func (db Database) DeleteSpendAnomalyMute(id uint) error {
+	return db.orm.Where("id = ?", id).Delete(&SpendAnomalyMute{}).Error
+}
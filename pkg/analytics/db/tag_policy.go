@@ -0,0 +1,112 @@
+package db
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TagCaseRule string
+
+const (
+	TagCaseRuleAny   TagCaseRule = ""
+	TagCaseRuleLower TagCaseRule = "lower"
+	TagCaseRuleUpper TagCaseRule = "upper"
+)
+
+// TagPolicy is a set of rules the tags of the resources in its scope must follow.
+// An empty scope field matches everything.
+type TagPolicy struct {
+	ID          string `gorm:"primaryKey"`
+	Title       string
+	Description string
+	Enabled     bool
+
+	Connectors    pq.StringArray `gorm:"type:text[]"`
+	ConnectionIDs pq.StringArray `gorm:"type:text[]"`
+	ResourceTypes pq.StringArray `gorm:"type:text[]"`
+
+	// OwnerTagKey is the tag used to attribute resources to an owner in coverage reports
+	OwnerTagKey string
+
+	Keys []TagPolicyKey `gorm:"foreignKey:TagPolicyID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type TagPolicyKey struct {
+	TagPolicyID   string `gorm:"primaryKey"`
+	Key           string `gorm:"primaryKey"`
+	Required      bool
+	AllowedValues pq.StringArray `gorm:"type:text[]"`
+	ValueRegex    string
+	ValueCase     TagCaseRule
+}
+
+func (db Database) ListTagPolicies(enabledOnly bool) ([]TagPolicy, error) {
+	var s []TagPolicy
+	tx := db.orm.Model(TagPolicy{}).Preload(clause.Associations)
+	if enabledOnly {
+		tx = tx.Where("enabled = ?", true)
+	}
+	tx = tx.Order("id").Find(&s)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+
+	return s, nil
+}
+
+func (db Database) GetTagPolicy(id string) (*TagPolicy, error) {
+	var s TagPolicy
+	tx := db.orm.Model(TagPolicy{}).Preload(clause.Associations).Where("id = ?", id).First(&s)
+	if tx.Error != nil {
+		if tx.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, tx.Error
+	}
+
+	return &s, nil
+}
+
+// UpsertTagPolicy creates the policy or replaces it, including all of its key rules.
+func (db Database) UpsertTagPolicy(policy TagPolicy) error {
+	return db.orm.Transaction(func(tx *gorm.DB) error {
+		keys := policy.Keys
+		policy.Keys = nil
+		err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"title", "description", "enabled", "connectors", "connection_ids", "resource_types", "owner_tag_key", "updated_at"}),
+		}).Create(&policy).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Where("tag_policy_id = ?", policy.ID).Delete(&TagPolicyKey{}).Error
+		if err != nil {
+			return err
+		}
+		for _, key := range keys {
+			key.TagPolicyID = policy.ID
+			err = tx.Create(&key).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (db Database) DeleteTagPolicy(id string) error {
+	return db.orm.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("tag_policy_id = ?", id).Delete(&TagPolicyKey{}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Where("id = ?", id).Delete(&TagPolicy{}).Error
+	})
+}
@@ -0,0 +1,85 @@
+package resourcecollection
+
+import (
+	"fmt"
+
+	"github.com/opengovern/og-util/pkg/opengovernance-es-sdk"
+	"github.com/opengovern/og-util/pkg/source"
+)
+
+const (
+	MembersIndex           = "resource_collection_members"
+	MembershipChangesIndex = "resource_collection_membership_changes"
+)
+
+// maxTermsCount is the default index.max_terms_count, a terms filter can not have more values.
+const maxTermsCount = 65536
+
+// MembersFilter matches the member resource IDs on the field. Large collections are split over several terms
+// filters so they stay under the terms limit.
+func MembersFilter(field string, resourceIDs []string) opengovernance.BoolFilter {
+	if len(resourceIDs) <= maxTermsCount {
+		return opengovernance.NewTermsFilter(field, resourceIDs)
+	}
+	var filters []opengovernance.BoolFilter
+	for start := 0; start < len(resourceIDs); start += maxTermsCount {
+		end := min(start+maxTermsCount, len(resourceIDs))
+		filters = append(filters, opengovernance.NewTermsFilter(field, resourceIDs[start:end]))
+	}
+	return opengovernance.NewBoolShouldFilter(filters...)
+}
+
+type Member struct {
+	EsID    string `json:"es_id"`
+	EsIndex string `json:"es_index"`
+
+	ResourceCollectionID string      `json:"resource_collection_id"`
+	ResourceID           string      `json:"resource_id"`
+	ResourceName         string      `json:"resource_name"`
+	ResourceType         string      `json:"resource_type"`
+	ConnectionID         string      `json:"connection_id"`
+	Connector            source.Type `json:"connector"`
+	Location             string      `json:"location"`
+
+	JobID   uint  `json:"job_id"`
+	AddedAt int64 `json:"added_at"`
+}
+
+func (r Member) KeysAndIndex() ([]string, string) {
+	return []string{
+		r.ResourceCollectionID,
+		r.ResourceID,
+		r.ConnectionID,
+	}, MembersIndex
+}
+
+type ChangeType string
+
+const (
+	ChangeTypeAdded   ChangeType = "added"
+	ChangeTypeRemoved ChangeType = "removed"
+)
+
+type MembershipChange struct {
+	EsID    string `json:"es_id"`
+	EsIndex string `json:"es_index"`
+
+	ResourceCollectionID string     `json:"resource_collection_id"`
+	ResourceID           string     `json:"resource_id"`
+	ResourceName         string     `json:"resource_name"`
+	ResourceType         string     `json:"resource_type"`
+	ConnectionID         string     `json:"connection_id"`
+	Change               ChangeType `json:"change"`
+
+	JobID     uint  `json:"job_id"`
+	ChangedAt int64 `json:"changed_at"`
+}
+
+func (r MembershipChange) KeysAndIndex() ([]string, string) {
+	return []string{
+		r.ResourceCollectionID,
+		r.ResourceID,
+		r.ConnectionID,
+		fmt.Sprintf("%d", r.JobID),
+	}, MembershipChangesIndex
+}
@@ -0,0 +1,39 @@
+package resourcecollection
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMembersFilter(t *testing.T) {
+	filter, err := json.Marshal(MembersFilter("resource_id", []string{"a", "b"}))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"terms":{"resource_id":["a","b"]}}`, string(filter))
+
+	resourceIDs := make([]string, 2*maxTermsCount+1)
+	for i := range resourceIDs {
+		resourceIDs[i] = fmt.Sprintf("r%d", i)
+	}
+	var split struct {
+		Bool struct {
+			Should []struct {
+				Terms map[string][]string `json:"terms"`
+			} `json:"should"`
+		} `json:"bool"`
+	}
+	filter, err = json.Marshal(MembersFilter("kaytuResourceID", resourceIDs))
+	assert.NoError(t, err)
+	assert.NoError(t, json.Unmarshal(filter, &split))
+	assert.Len(t, split.Bool.Should, 3)
+	var total int
+	for _, terms := range split.Bool.Should {
+		assert.LessOrEqual(t, len(terms.Terms["kaytuResourceID"]), maxTermsCount)
+		total += len(terms.Terms["kaytuResourceID"])
+	}
+	assert.Equal(t, len(resourceIDs), total)
+	assert.Equal(t, "r0", split.Bool.Should[0].Terms["kaytuResourceID"][0])
+	assert.Equal(t, resourceIDs[len(resourceIDs)-1], split.Bool.Should[2].Terms["kaytuResourceID"][0])
+}
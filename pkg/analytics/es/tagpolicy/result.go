@@ -0,0 +1,40 @@
+package tagpolicy
+
+import (
+	"github.com/opengovern/og-util/pkg/source"
+)
+
+const (
+	TagPolicyResultsIndex = "tag_policy_results"
+)
+
+type TagPolicyResult struct {
+	EsID    string `json:"es_id"`
+	EsIndex string `json:"es_index"`
+
+	PolicyID     string      `json:"policy_id"`
+	ResourceID   string      `json:"resource_id"`
+	ResourceName string      `json:"resource_name"`
+	ResourceType string      `json:"resource_type"`
+	ConnectionID string      `json:"connection_id"`
+	Connector    source.Type `json:"connector"`
+	Location     string      `json:"location"`
+	Owner        string      `json:"owner"`
+
+	Compliant     bool     `json:"compliant"`
+	Reason        string   `json:"reason"`
+	Violations    []string `json:"violations"`
+	ViolatingKeys []string `json:"violating_keys"`
+	Suggestions   []string `json:"suggestions"`
+
+	JobID       uint  `json:"job_id"`
+	EvaluatedAt int64 `json:"evaluated_at"`
+}
+
+func (r TagPolicyResult) KeysAndIndex() ([]string, string) {
+	return []string{
+		r.PolicyID,
+		r.ResourceID,
+		r.ConnectionID,
+	}, TagPolicyResultsIndex
+}
@@ -10,6 +10,7 @@ import (
 	esSinkClient "github.com/opengovern/og-util/pkg/es/ingest/client"
 	"github.com/opengovern/og-util/pkg/httpclient"
 	"github.com/opengovern/og-util/pkg/jq"
+	"github.com/opengovern/og-util/pkg/opengovernance-es-sdk"
 	"github.com/opengovern/og-util/pkg/source"
 	inventoryApi "github.com/opengovern/opengovernance/pkg/inventory/api"
 	"github.com/opengovern/opengovernance/pkg/utils"
@@ -38,6 +39,12 @@ import (
 type Job struct {
 	JobID                 uint
 	ResourceCollectionIDs []string
+	// TagPolicies jobs only evaluate the tag policies, they are scheduled once a discovery completes
+	TagPolicies bool
+
+	// spendSummaries are the spend summaries written by this job, the anomaly detection evaluates them
+	// without waiting for the index to be refreshed
+	spendSummaries []spend.ConnectionMetricTrendSummary
 }
 
 type JobResult struct {
@@ -54,6 +61,7 @@ func (j *Job) Do(
 	schedulerClient describeClient.SchedulerServiceClient,
 	inventoryClient inventoryClient.InventoryServiceClient,
 	sinkClient esSinkClient.EsSinkServiceClient,
+	esClient opengovernance.Client,
 	logger *zap.Logger,
 	config config.WorkerConfig,
 	ctx context.Context,
@@ -69,22 +77,12 @@ func (j *Job) Do(
 		return result
 	}
 
-	encodedResourceCollectionFilters := make(map[string]string)
-	if len(j.ResourceCollectionIDs) > 0 {
-		ctx2 := &httpclient.Context{UserRole: authApi.InternalRole}
-		ctx2.Ctx = ctx
-		rcs, err := inventoryClient.ListResourceCollectionsMetadata(ctx2,
-			j.ResourceCollectionIDs)
-		if err != nil {
+	if j.TagPolicies {
+		if err := j.EvaluateTagPolicies(ctx, db, esClient, sinkClient, logger); err != nil {
+			logger.Error("failed to evaluate tag policies", zap.Error(err))
 			return fail(err)
 		}
-		for _, rc := range rcs {
-			filtersJson, err := json.Marshal(rc.Filters)
-			if err != nil {
-				return fail(err)
-			}
-			encodedResourceCollectionFilters[rc.ID] = base64.StdEncoding.EncodeToString(filtersJson)
-		}
+		return result
 	}
 
 	err := steampipeConn.SetConfigTableValue(ctx, steampipe.KaytuConfigKeyAccountID, "all")
@@ -101,10 +99,43 @@ func (j *Job) Do(
 	}
 	defer steampipeConn.UnsetConfigTableValue(ctx, steampipe.KaytuConfigKeyClientType)
 
+	if len(j.ResourceCollectionIDs) > 0 {
+		// membership is materialized first, the filters of some collections are derived from it
+		if err := j.MaterializeResourceCollections(ctx, steampipeConn, esClient, sinkClient, onboardClient, inventoryClient, logger); err != nil {
+			logger.Error("failed to materialize resource collections", zap.Error(err))
+			fail(err)
+		}
+	}
+
+	encodedResourceCollectionFilters := make(map[string]string)
+	if len(j.ResourceCollectionIDs) > 0 {
+		ctx2 := &httpclient.Context{UserRole: authApi.InternalRole}
+		ctx2.Ctx = ctx
+		rcs, err := inventoryClient.ListResourceCollectionsMetadata(ctx2,
+			j.ResourceCollectionIDs)
+		if err != nil {
+			return fail(err)
+		}
+		for _, rc := range rcs {
+			filtersJson, err := json.Marshal(rc.Filters)
+			if err != nil {
+				return fail(err)
+			}
+			encodedResourceCollectionFilters[rc.ID] = base64.StdEncoding.EncodeToString(filtersJson)
+		}
+	}
+
 	if err := j.Run(ctx, jq, db, encodedResourceCollectionFilters, steampipeConn, schedulerClient, onboardClient, sinkClient, inventoryClient, logger, config); err != nil {
 		fail(err)
 	}
 
+	if len(j.ResourceCollectionIDs) == 0 && config.SpendAnomaly.Enabled {
+		if err := j.DetectSpendAnomalies(ctx, db, esClient, logger, config.SpendAnomaly); err != nil {
+			// anomaly detection is an add-on to the job, it does not fail the spend metrics already computed
+			logger.Error("failed to detect spend anomalies", zap.Error(err))
+		}
+	}
+
 	if config.DoTelemetry {
 		// send telemetry
 		j.SendTelemetry(ctx, logger, config, onboardClient, inventoryClient)
@@ -704,6 +735,7 @@ func (j *Job) DoSpendMetric(ctx context.Context, jq *jq.JobQueue, steampipeDB *s
 		item.EsIndex = idx
 
 		msgs = append(msgs, item)
+		j.spendSummaries = append(j.spendSummaries, item)
 	}
 	for _, item := range connectorResultMap {
 		for _, v := range item.ConnectorsMap {
@@ -0,0 +1,286 @@
+package analytics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	authApi "github.com/opengovern/og-util/pkg/api"
+	"github.com/opengovern/og-util/pkg/es"
+	esSinkClient "github.com/opengovern/og-util/pkg/es/ingest/client"
+	"github.com/opengovern/og-util/pkg/httpclient"
+	"github.com/opengovern/og-util/pkg/opengovernance-es-sdk"
+	"github.com/opengovern/og-util/pkg/steampipe"
+	esResourceCollection "github.com/opengovern/opengovernance/pkg/analytics/es/resourcecollection"
+	"github.com/opengovern/opengovernance/pkg/analytics/resourcecollection"
+	describeEs "github.com/opengovern/opengovernance/pkg/describe/es"
+	inventoryApi "github.com/opengovern/opengovernance/pkg/inventory/api"
+	inventoryClient "github.com/opengovern/opengovernance/pkg/inventory/client"
+	onboardClient "github.com/opengovern/opengovernance/pkg/onboard/client"
+	"go.uber.org/zap"
+)
+
+const resourceCollectionMembersBatchSize = 1000
+
+// MaterializeResourceCollections resolves the members of the job's resource collections from the lookup index,
+// records the resources added and removed since the previous run and reports the membership to inventory.
+func (j *Job) MaterializeResourceCollections(ctx context.Context, steampipeConn *steampipe.Database, esClient opengovernance.Client, sinkClient esSinkClient.EsSinkServiceClient, onboardClient onboardClient.OnboardServiceClient, inventoryClient inventoryClient.InventoryServiceClient, logger *zap.Logger) error {
+	httpCtx := &httpclient.Context{Ctx: ctx, UserRole: authApi.InternalRole}
+	rcs, err := inventoryClient.ListResourceCollectionsMetadata(httpCtx, j.ResourceCollectionIDs)
+	if err != nil {
+		return err
+	}
+
+	connections, err := onboardClient.ListSources(httpCtx, nil)
+	if err != nil {
+		return err
+	}
+	accountConnections := make(map[string]string)
+	connectionAccounts := make(map[string]string)
+	for _, c := range connections {
+		accountConnections[strings.ToLower(c.ConnectionID)] = c.ID.String()
+		connectionAccounts[c.ID.String()] = c.ConnectionID
+	}
+
+	var firstErr error
+	for _, rc := range rcs {
+		membership, filters, err := j.materializeResourceCollection(ctx, steampipeConn, esClient, sinkClient, rc, accountConnections, connectionAccounts, logger)
+		if err != nil {
+			logger.Error("failed to materialize resource collection", zap.String("resourceCollectionID", rc.ID), zap.Error(err))
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+
+		err = inventoryClient.UpdateResourceCollectionMembership(httpCtx, rc.ID, inventoryApi.UpdateResourceCollectionMembershipRequest{
+			ResourceCollectionMembership: *membership,
+			Filters:                      filters,
+		})
+		if err != nil {
+			logger.Error("failed to update resource collection membership", zap.String("resourceCollectionID", rc.ID), zap.Error(err))
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
+func (j *Job) materializeResourceCollection(ctx context.Context, steampipeConn *steampipe.Database, esClient opengovernance.Client, sinkClient esSinkClient.EsSinkServiceClient,
+	rc inventoryApi.ResourceCollection, accountConnections, connectionAccounts map[string]string, logger *zap.Logger) (*inventoryApi.ResourceCollectionMembership, []opengovernance.ResourceCollectionFilter, error) {
+	memberKey := func(resourceID, connectionID string) string {
+		return fmt.Sprintf("%s|%s", resourceID, connectionID)
+	}
+
+	var lookupFilters []opengovernance.BoolFilter
+	var sqlResourceIDs map[string]bool
+	if rc.Definition != nil {
+		lookupFilters = resourcecollection.LookupFilters(*rc.Definition)
+		if rc.Definition.SQLPredicate != "" {
+			if err := resourcecollection.ValidateSQLPredicate(rc.Definition.SQLPredicate); err != nil {
+				return nil, nil, err
+			}
+			res, err := steampipeConn.QueryAll(ctx, resourcecollection.SQLQuery(rc.Definition.SQLPredicate))
+			if err != nil {
+				return nil, nil, err
+			}
+			sqlResourceIDs = make(map[string]bool)
+			for _, row := range res.Data {
+				if len(row) == 0 {
+					continue
+				}
+				if resourceID, ok := row[0].(string); ok {
+					sqlResourceIDs[resourceID] = true
+				}
+			}
+		}
+	} else {
+		lookupFilters = resourcecollection.LookupFiltersFromFilters(rc.Filters, accountConnections)
+	}
+
+	previous := make(map[string]esResourceCollection.Member)
+	err := resourcecollection.ForEachMember(ctx, logger, esClient, rc.ID, func(member esResourceCollection.Member) {
+		previous[memberKey(member.ResourceID, member.ConnectionID)] = member
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	paginator, err := opengovernance.NewPaginator(esClient.ES(), es.InventorySummaryIndex, lookupFilters, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() {
+		if err := paginator.Deallocate(ctx); err != nil {
+			logger.Error("failed to deallocate paginator", zap.Error(err))
+		}
+	}()
+	paginator.UpdatePageSize(resourceCollectionMembersBatchSize)
+
+	now := time.Now()
+	var docs []es.Doc
+	flush := func() error {
+		if len(docs) == 0 {
+			return nil
+		}
+		if _, err := sinkClient.Ingest(&httpclient.Context{Ctx: ctx, UserRole: authApi.InternalRole}, docs); err != nil {
+			logger.Error("failed to send resource collection members to es sink", zap.Error(err))
+			return err
+		}
+		docs = nil
+		return nil
+	}
+	addDoc := func(doc es.Doc) error {
+		docs = append(docs, doc)
+		if len(docs) >= resourceCollectionMembersBatchSize {
+			return flush()
+		}
+		return nil
+	}
+
+	membership := inventoryApi.ResourceCollectionMembership{
+		JobID:          j.JobID,
+		MaterializedAt: &now,
+	}
+	current := make(map[string]bool)
+	memberAccounts := make(map[string]bool)
+	for !paginator.Done() {
+		var response lookupResourceSearchResponse
+		if err := paginator.Search(ctx, &response); err != nil {
+			return nil, nil, err
+		}
+
+		for _, hit := range response.Hits.Hits {
+			resource := hit.Source
+			if sqlResourceIDs != nil && !sqlResourceIDs[resource.ResourceID] {
+				continue
+			}
+			key := memberKey(resource.ResourceID, resource.SourceID)
+			if current[key] {
+				continue
+			}
+			current[key] = true
+			if accountID, ok := connectionAccounts[resource.SourceID]; ok {
+				memberAccounts[accountID] = true
+			}
+
+			member := esResourceCollection.Member{
+				ResourceCollectionID: rc.ID,
+				ResourceID:           resource.ResourceID,
+				ResourceName:         resource.Name,
+				ResourceType:         strings.ToLower(resource.ResourceType),
+				ConnectionID:         resource.SourceID,
+				Connector:            resource.SourceType,
+				Location:             resource.Location,
+				JobID:                j.JobID,
+				AddedAt:              now.UnixMilli(),
+			}
+			if prev, ok := previous[key]; ok {
+				member.AddedAt = prev.AddedAt
+			} else {
+				membership.AddedCount++
+				if err := addDoc(j.membershipChange(member, esResourceCollection.ChangeTypeAdded, now)); err != nil {
+					return nil, nil, err
+				}
+			}
+			keys, idx := member.KeysAndIndex()
+			member.EsID = es.HashOf(keys...)
+			member.EsIndex = idx
+			if err := addDoc(member); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		hits := int64(len(response.Hits.Hits))
+		if hits > 0 {
+			paginator.UpdateState(hits, response.Hits.Hits[hits-1].Sort, response.PitID)
+		} else {
+			paginator.UpdateState(hits, nil, "")
+		}
+	}
+
+	for key, member := range previous {
+		if current[key] {
+			continue
+		}
+		membership.RemovedCount++
+		if err := addDoc(j.membershipChange(member, esResourceCollection.ChangeTypeRemoved, now)); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := flush(); err != nil {
+		return nil, nil, err
+	}
+	membership.MemberCount = len(current)
+
+	if err := j.deleteOldResourceCollectionMembers(ctx, sinkClient, rc.ID, logger); err != nil {
+		return nil, nil, err
+	}
+
+	// collections whose definition is not expressible as filters are scoped to the accounts of their members
+	var filters []opengovernance.ResourceCollectionFilter
+	if rc.Definition != nil {
+		if _, exact := resourcecollection.Filters(*rc.Definition, nil); !exact {
+			accountIDs := make([]string, 0, len(memberAccounts))
+			for accountID := range memberAccounts {
+				accountIDs = append(accountIDs, accountID)
+			}
+			filters, _ = resourcecollection.Filters(*rc.Definition, accountIDs)
+		}
+	}
+
+	return &membership, filters, nil
+}
+
+func (j *Job) membershipChange(member esResourceCollection.Member, change esResourceCollection.ChangeType, changedAt time.Time) esResourceCollection.MembershipChange {
+	doc := esResourceCollection.MembershipChange{
+		ResourceCollectionID: member.ResourceCollectionID,
+		ResourceID:           member.ResourceID,
+		ResourceName:         member.ResourceName,
+		ResourceType:         member.ResourceType,
+		ConnectionID:         member.ConnectionID,
+		Change:               change,
+		JobID:                j.JobID,
+		ChangedAt:            changedAt.UnixMilli(),
+	}
+	keys, idx := doc.KeysAndIndex()
+	doc.EsID = es.HashOf(keys...)
+	doc.EsIndex = idx
+	return doc
+}
+
+func (j *Job) deleteOldResourceCollectionMembers(ctx context.Context, sinkClient esSinkClient.EsSinkServiceClient, resourceCollectionID string, logger *zap.Logger) error {
+	root := map[string]any{
+		"query": map[string]any{
+			"bool": map[string]any{
+				"filter": []opengovernance.BoolFilter{
+					opengovernance.NewTermFilter("resource_collection_id", resourceCollectionID),
+					opengovernance.NewRangeFilter("job_id", "", "", fmt.Sprintf("%d", j.JobID), ""),
+				},
+			},
+		},
+	}
+	rootJson, err := json.Marshal(root)
+	if err != nil {
+		return err
+	}
+
+	task := describeEs.DeleteTask{
+		DiscoveryJobID: j.JobID,
+		ResourceType:   "resource-collection-member",
+		TaskType:       describeEs.DeleteTaskTypeQuery,
+		Query:          string(rootJson),
+		QueryIndex:     esResourceCollection.MembersIndex,
+	}
+	keys, idx := task.KeysAndIndex()
+	task.EsID = es.HashOf(keys...)
+	task.EsIndex = idx
+	if _, err := sinkClient.Ingest(&httpclient.Context{Ctx: ctx, UserRole: authApi.InternalRole}, []es.Doc{task}); err != nil {
+		logger.Error("failed to send delete message to elastic", zap.Error(err))
+		return err
+	}
+	return nil
+}
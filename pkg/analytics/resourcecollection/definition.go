@@ -0,0 +1,284 @@
+package resourcecollection
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/opengovern/og-util/pkg/opengovernance-es-sdk"
+	inventoryApi "github.com/opengovern/opengovernance/pkg/inventory/api"
+	pg_query "github.com/pganalyze/pg_query_go/v4"
+)
+
+const sqlPredicateTable = "kaytu_lookup"
+
+var (
+	// sqlPredicateColumns are the columns of kaytu_lookup a predicate can use
+	sqlPredicateColumns = []string{"resource_id", "name", "connector", "resource_type", "resource_group", "region",
+		"connection_id", "created_at", "tags"}
+	// sqlPredicateFunctions are the functions a predicate can call, optionally qualified by pg_catalog
+	sqlPredicateFunctions = []string{"lower", "upper", "length", "btrim", "ltrim", "rtrim", "starts_with",
+		"split_part", "substring", "jsonb_exists"}
+	// sqlPredicateNodes are the expression nodes a predicate can be made of, anything reading other
+	// relations, such as sub queries, is left out
+	sqlPredicateNodes = []string{"A_Expr", "BoolExpr", "NullTest", "BooleanTest", "ColumnRef", "A_Const", "TypeCast",
+		"TypeName", "FuncCall", "CoalesceExpr", "CaseExpr", "CaseWhen", "A_ArrayExpr", "List", "String", "Integer",
+		"Float", "Boolean", "BitString"}
+	// sqlPredicateSelectFields are the fields of the select statement a predicate is placed in, any other
+	// one is set by a predicate escaping its where clause
+	sqlPredicateSelectFields = []string{"targetList", "fromClause", "whereClause", "limitOption", "op"}
+)
+
+// Filters converts a definition to the resource collection filters used to scope steampipe queries.
+// Connection IDs are given as provider account IDs. The returned filters select a superset of the
+// definition when exact is false, which is the case for SQL predicates and tag keys without values.
+func Filters(def inventoryApi.ResourceCollectionDefinition, accountIDs []string) (filters []opengovernance.ResourceCollectionFilter, exact bool) {
+	exact = def.SQLPredicate == ""
+
+	base := opengovernance.ResourceCollectionFilter{
+		AccountIDs:    accountIDs,
+		Regions:       def.Regions,
+		ResourceTypes: def.ResourceTypes,
+	}
+	for _, connector := range def.Connectors {
+		base.Connectors = append(base.Connectors, connector.String())
+	}
+
+	keys := make([]string, 0, len(def.Tags))
+	for key, values := range def.Tags {
+		if len(values) == 0 {
+			exact = false
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	// every combination of the tag values is a separate filter, the filters are or-ed together
+	combinations := []map[string]string{{}}
+	for _, key := range keys {
+		var next []map[string]string
+		for _, combination := range combinations {
+			for _, value := range def.Tags[key] {
+				tags := make(map[string]string, len(combination)+1)
+				for k, v := range combination {
+					tags[k] = v
+				}
+				tags[key] = value
+				next = append(next, tags)
+			}
+		}
+		combinations = next
+	}
+
+	for _, tags := range combinations {
+		filter := base
+		if len(tags) > 0 {
+			filter.Tags = tags
+		}
+		filters = append(filters, filter)
+	}
+	return filters, exact
+}
+
+// LookupFilters returns the filters selecting the resources of the definition in the lookup index.
+// The SQL predicate is not part of the filters and is applied separately.
+func LookupFilters(def inventoryApi.ResourceCollectionDefinition) []opengovernance.BoolFilter {
+	var filters []opengovernance.BoolFilter
+	if len(def.Connectors) > 0 {
+		connectors := make([]string, 0, len(def.Connectors))
+		for _, c := range def.Connectors {
+			connectors = append(connectors, c.String())
+		}
+		filters = append(filters, opengovernance.NewTermsFilter("source_type", connectors))
+	}
+	if len(def.ConnectionIDs) > 0 {
+		filters = append(filters, opengovernance.NewTermsFilter("source_id", def.ConnectionIDs))
+	}
+	if len(def.ResourceTypes) > 0 {
+		filters = append(filters, opengovernance.NewTermsFilter("resource_type", lower(def.ResourceTypes)))
+	}
+	if len(def.Regions) > 0 {
+		filters = append(filters, opengovernance.NewTermsFilter("location", def.Regions))
+	}
+	for key, values := range def.Tags {
+		filters = append(filters, tagFilter(key, values))
+	}
+	return filters
+}
+
+// LookupFiltersFromFilters returns the filters selecting the resources matching any of the resource
+// collection filters in the lookup index. accountConnections maps the lowercase provider account IDs
+// to connection IDs.
+func LookupFiltersFromFilters(filters []opengovernance.ResourceCollectionFilter, accountConnections map[string]string) []opengovernance.BoolFilter {
+	var should []opengovernance.BoolFilter
+	for _, filter := range filters {
+		var must []opengovernance.BoolFilter
+		if len(filter.Connectors) > 0 {
+			must = append(must, opengovernance.NewTermsFilter("source_type", filter.Connectors))
+		}
+		if len(filter.AccountIDs) > 0 {
+			connectionIDs := make([]string, 0, len(filter.AccountIDs))
+			for _, accountID := range filter.AccountIDs {
+				if connectionID, ok := accountConnections[strings.ToLower(accountID)]; ok {
+					connectionIDs = append(connectionIDs, connectionID)
+				}
+			}
+			must = append(must, opengovernance.NewTermsFilter("source_id", connectionIDs))
+		}
+		if len(filter.ResourceTypes) > 0 {
+			must = append(must, opengovernance.NewTermsFilter("resource_type", lower(filter.ResourceTypes)))
+		}
+		if len(filter.Regions) > 0 {
+			must = append(must, opengovernance.NewTermsFilter("location", filter.Regions))
+		}
+		for key, value := range filter.Tags {
+			must = append(must, tagFilter(key, []string{value}))
+		}
+		should = append(should, opengovernance.NewBoolMustFilter(must...))
+	}
+	if len(should) == 0 {
+		// a collection without filters has no members
+		return []opengovernance.BoolFilter{opengovernance.NewTermsFilter("resource_id", []string{})}
+	}
+	return []opengovernance.BoolFilter{opengovernance.NewBoolShouldFilter(should...)}
+}
+
+// ValidateSQLPredicate parses the predicate as the where clause of the kaytu_lookup query and rejects it
+// unless it is a single expression over the columns of kaytu_lookup.
+func ValidateSQLPredicate(predicate string) error {
+	scan, err := pg_query.Scan(predicate)
+	if err != nil {
+		return fmt.Errorf("invalid sql predicate: %w", err)
+	}
+	for _, token := range scan.GetTokens() {
+		if token.GetToken() == pg_query.Token_SQL_COMMENT || token.GetToken() == pg_query.Token_C_COMMENT {
+			return errors.New("sql predicate must not have comments")
+		}
+	}
+
+	// the predicate is parsed without the parentheses SQLQuery adds, so it can not close them
+	tree, err := pg_query.ParseToJSON(fmt.Sprintf("SELECT resource_id FROM %s WHERE %s", sqlPredicateTable, predicate))
+	if err != nil {
+		return fmt.Errorf("invalid sql predicate: %w", err)
+	}
+	var parsed struct {
+		Stmts []struct {
+			Stmt struct {
+				SelectStmt map[string]json.RawMessage `json:"SelectStmt"`
+			} `json:"stmt"`
+		} `json:"stmts"`
+	}
+	if err := json.Unmarshal([]byte(tree), &parsed); err != nil {
+		return err
+	}
+	if len(parsed.Stmts) != 1 || parsed.Stmts[0].Stmt.SelectStmt == nil {
+		return errors.New("sql predicate must be a single expression")
+	}
+	stmt := parsed.Stmts[0].Stmt.SelectStmt
+	for field := range stmt {
+		if !slices.Contains(sqlPredicateSelectFields, field) {
+			return errors.New("sql predicate must be a single expression")
+		}
+	}
+	if string(stmt["op"]) != `"SETOP_NONE"` {
+		return errors.New("sql predicate must be a single expression")
+	}
+
+	var where any
+	if err := json.Unmarshal(stmt["whereClause"], &where); err != nil {
+		return err
+	}
+	return validateSQLPredicateNode(where)
+}
+
+func validateSQLPredicateNode(node any) error {
+	switch v := node.(type) {
+	case []any:
+		for _, item := range v {
+			if err := validateSQLPredicateNode(item); err != nil {
+				return err
+			}
+		}
+	case map[string]any:
+		for key, value := range v {
+			// node types are capitalized, their fields are not
+			if key != "" && unicode.IsUpper([]rune(key)[0]) {
+				if !slices.Contains(sqlPredicateNodes, key) {
+					return fmt.Errorf("sql predicate can not use %s", key)
+				}
+				fields, _ := value.(map[string]any)
+				switch key {
+				case "ColumnRef":
+					names, ok := sqlPredicateNames(fields["fields"])
+					if !ok || len(names) > 2 || (len(names) == 2 && names[0] != sqlPredicateTable) ||
+						!slices.Contains(sqlPredicateColumns, names[len(names)-1]) {
+						return fmt.Errorf("sql predicate can only use the columns %s", strings.Join(sqlPredicateColumns, ", "))
+					}
+				case "FuncCall":
+					names, ok := sqlPredicateNames(fields["funcname"])
+					if !ok || len(names) > 2 || (len(names) == 2 && names[0] != "pg_catalog") ||
+						!slices.Contains(sqlPredicateFunctions, names[len(names)-1]) {
+						return fmt.Errorf("sql predicate can only call the functions %s", strings.Join(sqlPredicateFunctions, ", "))
+					}
+				}
+			}
+			if err := validateSQLPredicateNode(value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// sqlPredicateNames returns the lowercase names of a list of String nodes.
+func sqlPredicateNames(node any) ([]string, bool) {
+	items, ok := node.([]any)
+	if !ok || len(items) == 0 {
+		return nil, false
+	}
+	names := make([]string, 0, len(items))
+	for _, item := range items {
+		m, _ := item.(map[string]any)
+		str, ok := m["String"].(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		name, ok := str["sval"].(string)
+		if !ok {
+			return nil, false
+		}
+		names = append(names, strings.ToLower(name))
+	}
+	return names, true
+}
+
+// SQLQuery returns the steampipe query listing the IDs of the resources matching the predicate.
+func SQLQuery(predicate string) string {
+	return fmt.Sprintf("SELECT resource_id FROM kaytu_lookup WHERE (%s)", predicate)
+}
+
+func tagFilter(key string, values []string) opengovernance.BoolFilter {
+	tagFilters := []opengovernance.BoolFilter{
+		opengovernance.NewTermFilter("canonical_tags.key", strings.ToLower(key)),
+	}
+	if len(values) > 0 {
+		tagFilters = append(tagFilters, opengovernance.NewTermsFilter("canonical_tags.value", lower(values)))
+	}
+	return opengovernance.NewNestedFilter("canonical_tags", opengovernance.NewBoolMustFilter(tagFilters...))
+}
+
+func lower(values []string) []string {
+	if len(values) == 0 {
+		return nil
+	}
+	res := make([]string, 0, len(values))
+	for _, v := range values {
+		res = append(res, strings.ToLower(v))
+	}
+	return res
+}
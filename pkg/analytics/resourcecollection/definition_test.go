@@ -0,0 +1,66 @@
+package resourcecollection
+
+import (
+	"testing"
+
+	"github.com/opengovern/og-util/pkg/source"
+	inventoryApi "github.com/opengovern/opengovernance/pkg/inventory/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFiltersTagCombinations(t *testing.T) {
+	filters, exact := Filters(inventoryApi.ResourceCollectionDefinition{
+		Tags: map[string][]string{
+			"env":  {"prod", "staging"},
+			"team": {"core"},
+		},
+		ResourceTypes: []string{"AWS::EC2::Instance"},
+		Connectors:    []source.Type{source.CloudAWS},
+	}, []string{"123456789012"})
+
+	assert.True(t, exact)
+	assert.Len(t, filters, 2)
+	assert.Equal(t, map[string]string{"env": "prod", "team": "core"}, filters[0].Tags)
+	assert.Equal(t, map[string]string{"env": "staging", "team": "core"}, filters[1].Tags)
+	for _, filter := range filters {
+		assert.Equal(t, []string{"AWS"}, filter.Connectors)
+		assert.Equal(t, []string{"123456789012"}, filter.AccountIDs)
+		assert.Equal(t, []string{"AWS::EC2::Instance"}, filter.ResourceTypes)
+	}
+}
+
+func TestFiltersNotExact(t *testing.T) {
+	filters, exact := Filters(inventoryApi.ResourceCollectionDefinition{
+		Tags: map[string][]string{"owner": nil},
+	}, nil)
+	assert.False(t, exact)
+	assert.Len(t, filters, 1)
+	assert.Empty(t, filters[0].Tags)
+
+	_, exact = Filters(inventoryApi.ResourceCollectionDefinition{
+		Regions:      []string{"us-east-1"},
+		SQLPredicate: "name like 'prod-%'",
+	}, nil)
+	assert.False(t, exact)
+}
+
+func TestValidateSQLPredicate(t *testing.T) {
+	assert.NoError(t, ValidateSQLPredicate("name like 'prod-%' and (region = 'us-east-1' or region = 'eu-west-1')"))
+	assert.NoError(t, ValidateSQLPredicate("name = 'a(b'"))
+	assert.Error(t, ValidateSQLPredicate("true; drop table x"))
+	assert.Error(t, ValidateSQLPredicate("true) or (true"))
+	assert.Error(t, ValidateSQLPredicate("true -- comment"))
+	assert.Error(t, ValidateSQLPredicate("name = 'unterminated"))
+
+	assert.NoError(t, ValidateSQLPredicate("lower(name) like 'prod-%' and tags->>'env' = 'prod' and kaytu_lookup.region in ('us-east-1')"))
+	assert.NoError(t, ValidateSQLPredicate("trim(name) = E'a\\'b' and created_at::int > 0 and name <> $$--$$"))
+	assert.Error(t, ValidateSQLPredicate("name = E'\\'' ) or true; drop table x; --'"))
+	assert.Error(t, ValidateSQLPredicate("name = $$x$$) union select resource_id from kaytu_lookup where ($$x$$ = 'x'"))
+	assert.Error(t, ValidateSQLPredicate("resource_id in (select resource_id from aws_ec2_instance)"))
+	assert.Error(t, ValidateSQLPredicate("exists (select 1)"))
+	assert.Error(t, ValidateSQLPredicate("pg_read_file('/etc/passwd') <> ''"))
+	assert.Error(t, ValidateSQLPredicate("secret = 'x'"))
+	assert.Error(t, ValidateSQLPredicate("other.name = 'x'"))
+	assert.Error(t, ValidateSQLPredicate("true order by name"))
+	assert.Error(t, ValidateSQLPredicate("true /* comment */"))
+}
@@ -0,0 +1,129 @@
+package resourcecollection
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/opengovern/og-util/pkg/opengovernance-es-sdk"
+	"github.com/opengovern/opengovernance/pkg/analytics/es/resourcecollection"
+	"go.uber.org/zap"
+)
+
+const (
+	membersPageSize = 1000
+	membersCacheTTL = time.Minute
+)
+
+type memberHit struct {
+	ID     string                    `json:"_id"`
+	Source resourcecollection.Member `json:"_source"`
+	Sort   []any                     `json:"sort"`
+}
+
+type membersSearchResponse struct {
+	PitID string `json:"pit_id"`
+	Hits  struct {
+		Hits []memberHit `json:"hits"`
+	} `json:"hits"`
+}
+
+// ForEachMember calls fn for every materialized member of the resource collection.
+func ForEachMember(ctx context.Context, logger *zap.Logger, client opengovernance.Client, resourceCollectionID string, fn func(member resourcecollection.Member)) error {
+	filters := []opengovernance.BoolFilter{
+		opengovernance.NewTermFilter("resource_collection_id", resourceCollectionID),
+	}
+	paginator, err := opengovernance.NewPaginator(client.ES(), resourcecollection.MembersIndex, filters, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := paginator.Deallocate(ctx); err != nil {
+			logger.Error("failed to deallocate paginator", zap.Error(err))
+		}
+	}()
+	paginator.UpdatePageSize(membersPageSize)
+
+	for !paginator.Done() {
+		var response membersSearchResponse
+		if err := paginator.Search(ctx, &response); err != nil {
+			return err
+		}
+		for _, hit := range response.Hits.Hits {
+			fn(hit.Source)
+		}
+
+		hits := int64(len(response.Hits.Hits))
+		if hits > 0 {
+			paginator.UpdateState(hits, response.Hits.Hits[hits-1].Sort, response.PitID)
+		} else {
+			paginator.UpdateState(hits, nil, "")
+		}
+	}
+	return nil
+}
+
+type memberCacheEntry struct {
+	resourceIDs []string
+	expiresAt   time.Time
+}
+
+// MemberCache keeps the member resource IDs of resource collections for a short time, they are resolved on
+// every request scoped to resource collections. The zero value is ready to use.
+type MemberCache struct {
+	mu      sync.Mutex
+	entries map[string]memberCacheEntry
+}
+
+// ResourceIDs returns the resource IDs of the materialized members of the resource collections. The returned
+// slice is shared with the cache and must not be modified.
+func (c *MemberCache) ResourceIDs(ctx context.Context, logger *zap.Logger, client opengovernance.Client, resourceCollectionIDs []string) ([]string, error) {
+	if len(resourceCollectionIDs) == 1 {
+		return c.collectionResourceIDs(ctx, logger, client, resourceCollectionIDs[0])
+	}
+
+	seen := make(map[string]bool)
+	var resourceIDs []string
+	for _, resourceCollectionID := range resourceCollectionIDs {
+		ids, err := c.collectionResourceIDs(ctx, logger, client, resourceCollectionID)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range ids {
+			if !seen[id] {
+				seen[id] = true
+				resourceIDs = append(resourceIDs, id)
+			}
+		}
+	}
+	return resourceIDs, nil
+}
+
+func (c *MemberCache) collectionResourceIDs(ctx context.Context, logger *zap.Logger, client opengovernance.Client, resourceCollectionID string) ([]string, error) {
+	c.mu.Lock()
+	entry, ok := c.entries[resourceCollectionID]
+	c.mu.Unlock()
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry.resourceIDs, nil
+	}
+
+	seen := make(map[string]bool)
+	var resourceIDs []string
+	err := ForEachMember(ctx, logger, client, resourceCollectionID, func(member resourcecollection.Member) {
+		if !seen[member.ResourceID] {
+			seen[member.ResourceID] = true
+			resourceIDs = append(resourceIDs, member.ResourceID)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	if c.entries == nil {
+		c.entries = make(map[string]memberCacheEntry)
+	}
+	c.entries[resourceCollectionID] = memberCacheEntry{resourceIDs: resourceIDs, expiresAt: time.Now().Add(membersCacheTTL)}
+	c.mu.Unlock()
+	return resourceIDs, nil
+}
@@ -0,0 +1,301 @@
+package analytics
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/opengovern/og-util/pkg/es"
+	"github.com/opengovern/og-util/pkg/opengovernance-es-sdk"
+	"github.com/opengovern/opengovernance/pkg/analytics/config"
+	"github.com/opengovern/opengovernance/pkg/analytics/db"
+	"github.com/opengovern/opengovernance/pkg/analytics/es/spend"
+	"github.com/opengovern/opengovernance/pkg/analytics/spendanomaly"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultSpendAnomalyEvaluationDays = 3
+	spendAnomalyHistoryBatchSize      = 1000
+
+	spendAnomalySignatureHeader = "X-Signature-256"
+	spendAnomalyWebhookTimeout  = 10 * time.Second
+)
+
+type spendConnectionSummaryHit struct {
+	ID     string                             `json:"_id"`
+	Source spend.ConnectionMetricTrendSummary `json:"_source"`
+	Sort   []any                              `json:"sort"`
+}
+
+type spendConnectionSummarySearchResponse struct {
+	PitID string `json:"pit_id"`
+	Hits  struct {
+		Hits []spendConnectionSummaryHit `json:"hits"`
+	} `json:"hits"`
+}
+
+type spendAnomalyWebhookContributor struct {
+	MetricID         string  `json:"metric_id"`
+	MetricName       string  `json:"metric_name"`
+	ExpectedCost     float64 `json:"expected_cost"`
+	ActualCost       float64 `json:"actual_cost"`
+	Deviation        float64 `json:"deviation"`
+	DeviationPercent float64 `json:"deviation_percent"`
+	Anomalous        bool    `json:"anomalous"`
+}
+
+type spendAnomalyWebhookPayload struct {
+	Event            string                           `json:"event"`
+	AnomalyID        string                           `json:"anomaly_id"`
+	ConnectionID     string                           `json:"connection_id"`
+	ConnectionName   string                           `json:"connection_name"`
+	Connector        string                           `json:"connector"`
+	Date             string                           `json:"date"`
+	ExpectedCost     float64                          `json:"expected_cost"`
+	ActualCost       float64                          `json:"actual_cost"`
+	Deviation        float64                          `json:"deviation"`
+	DeviationPercent float64                          `json:"deviation_percent"`
+	Score            float64                          `json:"score"`
+	Contributors     []spendAnomalyWebhookContributor `json:"contributors"`
+	AcknowledgePath  string                           `json:"acknowledge_path"`
+}
+
+func spendAnomalyDetectorConfig(conf config.SpendAnomalyConfig) spendanomaly.Config {
+	return spendanomaly.Config{
+		Sensitivity:         spendanomaly.Sensitivity(strings.ToLower(conf.Sensitivity)),
+		MinCostDelta:        float64(conf.MinCostDelta),
+		MinDeviationPercent: float64(conf.MinDeviationPercent),
+		HistoryDays:         conf.HistoryDays,
+	}
+}
+
+// DetectSpendAnomalies checks the most recent days of the connection spend written by this job against the
+// baseline of each metric and connection, stores the anomalies and sends the new ones to the webhook.
+func (j *Job) DetectSpendAnomalies(ctx context.Context, dbc db.Database, esClient opengovernance.Client, logger *zap.Logger, conf config.SpendAnomalyConfig) error {
+	if len(j.spendSummaries) == 0 {
+		return nil
+	}
+
+	evaluationDays := conf.EvaluationDays
+	if evaluationDays <= 0 {
+		evaluationDays = defaultSpendAnomalyEvaluationDays
+	}
+	detectorConf := spendAnomalyDetectorConfig(conf)
+	historyDays := detectorConf.HistoryDays
+	if historyDays <= 0 {
+		historyDays = spendanomaly.DefaultHistoryDays
+	}
+
+	dateSet := make(map[int64]time.Time)
+	for _, summary := range j.spendSummaries {
+		date := time.UnixMilli(summary.DateEpoch).UTC()
+		dateSet[date.UnixMilli()] = date
+	}
+	var dates []time.Time
+	for _, date := range dateSet {
+		dates = append(dates, date)
+	}
+	sort.Slice(dates, func(i, k int) bool {
+		return dates[i].Before(dates[k])
+	})
+	if len(dates) > evaluationDays {
+		dates = dates[len(dates)-evaluationDays:]
+	}
+	historyStart := dates[0].AddDate(0, 0, -historyDays)
+
+	summaries, err := j.listSpendConnectionSummaries(ctx, esClient, logger, historyStart)
+	if err != nil {
+		return err
+	}
+	// the summaries of this job replace the indexed ones of the same date and metric
+	for _, summary := range j.spendSummaries {
+		summaries[summary.Date+"|"+summary.MetricID] = summary
+	}
+
+	series := make(map[spendanomaly.SeriesKey][]spendanomaly.Point)
+	metricNames := make(map[string]string)
+	connections := make(map[string]spend.PerConnectionMetricTrendSummary)
+	for _, summary := range summaries {
+		metricNames[summary.MetricID] = summary.MetricName
+		date := time.UnixMilli(summary.DateEpoch).UTC()
+		for _, conn := range summary.Connections {
+			key := spendanomaly.SeriesKey{MetricID: summary.MetricID, ConnectionID: conn.ConnectionID}
+			series[key] = append(series[key], spendanomaly.Point{Date: date, Cost: conn.CostValue})
+			connections[conn.ConnectionID] = conn
+		}
+	}
+
+	mutes, err := dbc.ListSpendAnomalyMutes(true)
+	if err != nil {
+		return err
+	}
+	muted := func(key spendanomaly.SeriesKey) bool {
+		for _, mute := range mutes {
+			if mute.ConnectionID == key.ConnectionID && (mute.MetricID == "" || mute.MetricID == key.MetricID) {
+				return true
+			}
+		}
+		return false
+	}
+
+	anomalies := spendanomaly.Detect(series, dates, detectorConf, muted)
+	for _, anomaly := range anomalies {
+		date := anomaly.Date.Format("2006-01-02")
+		conn := connections[anomaly.ConnectionID]
+		record := db.SpendAnomaly{
+			ID:               es.HashOf(anomaly.ConnectionID, date),
+			ConnectionID:     anomaly.ConnectionID,
+			ConnectionName:   conn.ConnectionName,
+			Connector:        conn.Connector.String(),
+			Date:             date,
+			DateEpoch:        anomaly.Date.UnixMilli(),
+			ExpectedCost:     anomaly.Expected,
+			ActualCost:       anomaly.Actual,
+			Deviation:        anomaly.Deviation,
+			DeviationPercent: anomaly.DeviationPercent,
+			Score:            anomaly.Score,
+			Status:           db.SpendAnomalyStatusOpen,
+			JobID:            j.JobID,
+		}
+		for _, c := range anomaly.Contributors {
+			record.Contributors = append(record.Contributors, db.SpendAnomalyContributor{
+				MetricID:         c.MetricID,
+				MetricName:       metricNames[c.MetricID],
+				ExpectedCost:     c.Expected,
+				ActualCost:       c.Actual,
+				Deviation:        c.Deviation,
+				DeviationPercent: c.DeviationPercent,
+				Score:            c.Score,
+				Anomalous:        c.Anomalous,
+			})
+		}
+		if err := dbc.UpsertSpendAnomaly(record); err != nil {
+			return err
+		}
+	}
+	logger.Info("done with spend anomaly detection",
+		zap.Int("series_count", len(series)),
+		zap.Int("date_count", len(dates)),
+		zap.Int("anomaly_count", len(anomalies)))
+
+	if conf.WebhookURL == "" {
+		return nil
+	}
+	return j.notifySpendAnomalies(ctx, dbc, logger, conf)
+}
+
+// listSpendConnectionSummaries returns the indexed connection spend summaries since start keyed by date and metric.
+func (j *Job) listSpendConnectionSummaries(ctx context.Context, esClient opengovernance.Client, logger *zap.Logger, start time.Time) (map[string]spend.ConnectionMetricTrendSummary, error) {
+	filters := []opengovernance.BoolFilter{
+		opengovernance.NewRangeFilter("date_epoch", "", fmt.Sprintf("%d", start.UnixMilli()), "", ""),
+	}
+	paginator, err := opengovernance.NewPaginator(esClient.ES(), spend.AnalyticsSpendConnectionSummaryIndex, filters, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := paginator.Deallocate(ctx); err != nil {
+			logger.Error("failed to deallocate paginator", zap.Error(err))
+		}
+	}()
+	paginator.UpdatePageSize(spendAnomalyHistoryBatchSize)
+
+	summaries := make(map[string]spend.ConnectionMetricTrendSummary)
+	for !paginator.Done() {
+		var response spendConnectionSummarySearchResponse
+		if err := paginator.Search(ctx, &response); err != nil {
+			return nil, err
+		}
+		for _, hit := range response.Hits.Hits {
+			summaries[hit.Source.Date+"|"+hit.Source.MetricID] = hit.Source
+		}
+
+		hits := int64(len(response.Hits.Hits))
+		if hits > 0 {
+			paginator.UpdateState(hits, response.Hits.Hits[hits-1].Sort, response.PitID)
+		} else {
+			paginator.UpdateState(hits, nil, "")
+		}
+	}
+	return summaries, nil
+}
+
+// notifySpendAnomalies sends the open anomalies that have not been sent yet to the webhook, one request each.
+// A failed request is retried on the next job.
+func (j *Job) notifySpendAnomalies(ctx context.Context, dbc db.Database, logger *zap.Logger, conf config.SpendAnomalyConfig) error {
+	anomalies, err := dbc.ListUnnotifiedSpendAnomalies()
+	if err != nil {
+		return err
+	}
+
+	client := http.Client{Timeout: spendAnomalyWebhookTimeout}
+	for _, anomaly := range anomalies {
+		payload := spendAnomalyWebhookPayload{
+			Event:            "spend_anomaly",
+			AnomalyID:        anomaly.ID,
+			ConnectionID:     anomaly.ConnectionID,
+			ConnectionName:   anomaly.ConnectionName,
+			Connector:        anomaly.Connector,
+			Date:             anomaly.Date,
+			ExpectedCost:     anomaly.ExpectedCost,
+			ActualCost:       anomaly.ActualCost,
+			Deviation:        anomaly.Deviation,
+			DeviationPercent: anomaly.DeviationPercent,
+			Score:            anomaly.Score,
+			AcknowledgePath:  fmt.Sprintf("/inventory/api/v2/analytics/spend/anomalies/%s/acknowledge", anomaly.ID),
+		}
+		for _, c := range anomaly.Contributors {
+			payload.Contributors = append(payload.Contributors, spendAnomalyWebhookContributor{
+				MetricID:         c.MetricID,
+				MetricName:       c.MetricName,
+				ExpectedCost:     c.ExpectedCost,
+				ActualCost:       c.ActualCost,
+				Deviation:        c.Deviation,
+				DeviationPercent: c.DeviationPercent,
+				Anomalous:        c.Anomalous,
+			})
+		}
+		body, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, conf.WebhookURL, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if conf.WebhookSecret != "" {
+			mac := hmac.New(sha256.New, []byte(conf.WebhookSecret))
+			mac.Write(body)
+			req.Header.Set(spendAnomalySignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			logger.Error("failed to send spend anomaly to webhook", zap.String("anomalyID", anomaly.ID), zap.Error(err))
+			continue
+		}
+		io.Copy(io.Discard, res.Body)
+		res.Body.Close()
+		if res.StatusCode < 200 || res.StatusCode >= 300 {
+			logger.Error("spend anomaly webhook returned an error", zap.String("anomalyID", anomaly.ID), zap.Int("status_code", res.StatusCode))
+			continue
+		}
+
+		if err := dbc.MarkSpendAnomalyNotified(anomaly.ID, time.Now()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
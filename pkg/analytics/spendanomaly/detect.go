@@ -0,0 +1,297 @@
+package spendanomaly
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+type Sensitivity string
+
+const (
+	SensitivityLow    Sensitivity = "low"
+	SensitivityMedium Sensitivity = "medium"
+	SensitivityHigh   Sensitivity = "high"
+)
+
+// Threshold returns the number of standard deviations from the baseline a cost must be off by to be flagged.
+func (s Sensitivity) Threshold() float64 {
+	switch s {
+	case SensitivityLow:
+		return 4
+	case SensitivityHigh:
+		return 2
+	default:
+		return 3
+	}
+}
+
+const (
+	DefaultHistoryDays    = 56
+	DefaultMinHistoryDays = 14
+	MaxContributors       = 5
+
+	baselineFitIterations = 20
+)
+
+type Config struct {
+	Sensitivity Sensitivity
+	// MinCostDelta and MinDeviationPercent keep small absolute or relative changes from being flagged on series
+	// that barely move.
+	MinCostDelta        float64
+	MinDeviationPercent float64
+	// HistoryDays is the window before the evaluated date the baseline is fitted on, a series needs at least
+	// MinHistoryDays of cost in it to be evaluated.
+	HistoryDays    int
+	MinHistoryDays int
+}
+
+func (c Config) withDefaults() Config {
+	if c.HistoryDays <= 0 {
+		c.HistoryDays = DefaultHistoryDays
+	}
+	if c.MinHistoryDays <= 0 {
+		c.MinHistoryDays = DefaultMinHistoryDays
+	}
+	return c
+}
+
+type Point struct {
+	Date time.Time
+	Cost float64
+}
+
+// SeriesKey identifies the daily spend of a metric in a connection.
+type SeriesKey struct {
+	MetricID     string
+	ConnectionID string
+}
+
+type Result struct {
+	Expected         float64
+	Actual           float64
+	Deviation        float64
+	DeviationPercent float64
+	Score            float64
+	Anomalous        bool
+}
+
+type Contributor struct {
+	MetricID string
+	Result
+}
+
+// Anomaly is the spend of a connection on a date with at least one anomalous metric. Expected and actual are the
+// totals of the evaluated metrics of the connection, the contributors are the anomalous metrics followed by the
+// others, sorted by how much they deviate from their baseline.
+type Anomaly struct {
+	ConnectionID     string
+	Date             time.Time
+	Expected         float64
+	Actual           float64
+	Deviation        float64
+	DeviationPercent float64
+	// Score is the score of the most deviating anomalous metric
+	Score        float64
+	Contributors []Contributor
+}
+
+// Baseline is a linear trend with a day of week offset fitted on the daily cost of a series.
+type Baseline struct {
+	origin    time.Time
+	intercept float64
+	slope     float64
+	weekday   [7]float64
+	// sigma is the standard deviation of the cost around the baseline
+	sigma float64
+}
+
+func day(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func (b Baseline) x(date time.Time) float64 {
+	return day(date).Sub(b.origin).Hours() / 24
+}
+
+// Expected returns the baseline cost of the date, never below zero.
+func (b Baseline) Expected(date time.Time) float64 {
+	return math.Max(0, b.intercept+b.slope*b.x(date)+b.weekday[day(date).Weekday()])
+}
+
+// FitBaseline fits the baseline on the history of a series, the days missing from the history are left out of
+// the fit rather than taken as zero cost.
+func FitBaseline(history []Point) Baseline {
+	b := Baseline{}
+	if len(history) == 0 {
+		return b
+	}
+	b.origin = day(history[0].Date)
+	for _, p := range history {
+		if d := day(p.Date); d.Before(b.origin) {
+			b.origin = d
+		}
+	}
+
+	// the trend and the day of week offsets are fitted in turns on the cost without the other, a week dip at
+	// the end of the history would otherwise tilt the trend
+	n := float64(len(history))
+	for i := 0; i < baselineFitIterations; i++ {
+		var sumX, sumY, sumXY, sumXX float64
+		for _, p := range history {
+			x := b.x(p.Date)
+			y := p.Cost - b.weekday[day(p.Date).Weekday()]
+			sumX += x
+			sumY += y
+			sumXY += x * y
+			sumXX += x * x
+		}
+		b.slope = 0
+		if denominator := n*sumXX - sumX*sumX; denominator != 0 {
+			b.slope = (n*sumXY - sumX*sumY) / denominator
+		}
+		b.intercept = (sumY - b.slope*sumX) / n
+
+		var weekdaySum, weekdayCount [7]float64
+		for _, p := range history {
+			wd := day(p.Date).Weekday()
+			weekdaySum[wd] += p.Cost - (b.intercept + b.slope*b.x(p.Date))
+			weekdayCount[wd]++
+		}
+		for wd := range b.weekday {
+			b.weekday[wd] = 0
+			if weekdayCount[wd] > 0 {
+				b.weekday[wd] = weekdaySum[wd] / weekdayCount[wd]
+			}
+		}
+	}
+
+	var mean float64
+	for _, p := range history {
+		mean += p.Cost / n
+	}
+	var squares float64
+	for _, p := range history {
+		residual := p.Cost - (b.intercept + b.slope*b.x(p.Date) + b.weekday[day(p.Date).Weekday()])
+		squares += residual * residual
+	}
+	// a flat series has no deviation, the floor keeps the score of any change finite
+	b.sigma = math.Max(math.Sqrt(squares/n), math.Max(0.01*math.Abs(mean), 0.01))
+	return b
+}
+
+// Evaluate compares the cost of the series on date with the baseline fitted on the history before it. It returns
+// false when the series has no cost on date or not enough history.
+func Evaluate(series []Point, date time.Time, conf Config) (Result, bool) {
+	conf = conf.withDefaults()
+	date = day(date)
+	start := date.AddDate(0, 0, -conf.HistoryDays)
+
+	var history []Point
+	actual, found := 0.0, false
+	for _, p := range series {
+		d := day(p.Date)
+		switch {
+		case d.Equal(date):
+			actual, found = actual+p.Cost, true
+		case !d.Before(start) && d.Before(date):
+			history = append(history, p)
+		}
+	}
+	if !found || len(history) < conf.MinHistoryDays {
+		return Result{}, false
+	}
+
+	baseline := FitBaseline(history)
+	res := Result{
+		Expected: baseline.Expected(date),
+		Actual:   actual,
+	}
+	res.Deviation = res.Actual - res.Expected
+	res.Score = res.Deviation / baseline.sigma
+	if res.Expected > 0 {
+		res.DeviationPercent = res.Deviation / res.Expected * 100
+	} else if res.Deviation != 0 {
+		res.DeviationPercent = 100
+	}
+
+	res.Anomalous = math.Abs(res.Score) >= conf.Sensitivity.Threshold() &&
+		math.Abs(res.Deviation) >= conf.MinCostDelta &&
+		math.Abs(res.DeviationPercent) >= conf.MinDeviationPercent
+	return res, true
+}
+
+// Detect evaluates every series that is not muted on each of the dates and returns an anomaly for each connection
+// and date with an anomalous metric.
+func Detect(series map[SeriesKey][]Point, dates []time.Time, conf Config, muted func(SeriesKey) bool) []Anomaly {
+	type connectionDate struct {
+		connectionID string
+		date         time.Time
+	}
+
+	evaluated := make(map[connectionDate][]Contributor)
+	for key, points := range series {
+		if muted != nil && muted(key) {
+			continue
+		}
+		for _, date := range dates {
+			res, ok := Evaluate(points, date, conf)
+			if !ok {
+				continue
+			}
+			cd := connectionDate{connectionID: key.ConnectionID, date: day(date)}
+			evaluated[cd] = append(evaluated[cd], Contributor{MetricID: key.MetricID, Result: res})
+		}
+	}
+
+	var anomalies []Anomaly
+	for cd, contributors := range evaluated {
+		anomaly := Anomaly{
+			ConnectionID: cd.connectionID,
+			Date:         cd.date,
+		}
+		anomalous := false
+		for _, c := range contributors {
+			anomaly.Expected += c.Expected
+			anomaly.Actual += c.Actual
+			if c.Anomalous {
+				anomalous = true
+				if math.Abs(c.Score) > math.Abs(anomaly.Score) {
+					anomaly.Score = c.Score
+				}
+			}
+		}
+		if !anomalous {
+			continue
+		}
+		anomaly.Deviation = anomaly.Actual - anomaly.Expected
+		if anomaly.Expected > 0 {
+			anomaly.DeviationPercent = anomaly.Deviation / anomaly.Expected * 100
+		}
+
+		sort.Slice(contributors, func(i, j int) bool {
+			if contributors[i].Anomalous != contributors[j].Anomalous {
+				return contributors[i].Anomalous
+			}
+			di, dj := math.Abs(contributors[i].Deviation), math.Abs(contributors[j].Deviation)
+			if di != dj {
+				return di > dj
+			}
+			return contributors[i].MetricID < contributors[j].MetricID
+		})
+		if len(contributors) > MaxContributors {
+			contributors = contributors[:MaxContributors]
+		}
+		anomaly.Contributors = contributors
+		anomalies = append(anomalies, anomaly)
+	}
+
+	sort.Slice(anomalies, func(i, j int) bool {
+		if !anomalies[i].Date.Equal(anomalies[j].Date) {
+			return anomalies[i].Date.Before(anomalies[j].Date)
+		}
+		return anomalies[i].ConnectionID < anomalies[j].ConnectionID
+	})
+	return anomalies
+}
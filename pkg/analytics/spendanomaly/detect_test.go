@@ -0,0 +1,104 @@
+package spendanomaly
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testStart = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC) // a Monday
+
+// weeklySeries has a weekday cost of 100, a weekend cost of 40 and a growth of 1 per day.
+func weeklySeries(days int) []Point {
+	var points []Point
+	for i := 0; i < days; i++ {
+		date := testStart.AddDate(0, 0, i)
+		cost := 100.0 + float64(i)
+		if wd := date.Weekday(); wd == time.Saturday || wd == time.Sunday {
+			cost = 40.0 + float64(i)
+		}
+		points = append(points, Point{Date: date, Cost: cost})
+	}
+	return points
+}
+
+func TestEvaluateSeasonalBaseline(t *testing.T) {
+	series := weeklySeries(57)
+	date := testStart.AddDate(0, 0, 56) // a Monday, expected 156
+
+	res, ok := Evaluate(series, date, Config{})
+	require.True(t, ok)
+	assert.InDelta(t, 156, res.Expected, 0.5)
+	assert.False(t, res.Anomalous)
+
+	// the weekend drop is part of the baseline
+	weekend := testStart.AddDate(0, 0, 55) // a Sunday
+	res, ok = Evaluate(series, weekend, Config{})
+	require.True(t, ok)
+	assert.InDelta(t, 95, res.Expected, 0.5)
+	assert.False(t, res.Anomalous)
+}
+
+func TestEvaluateSpike(t *testing.T) {
+	series := weeklySeries(57)
+	series[56].Cost = 400
+
+	res, ok := Evaluate(series, series[56].Date, Config{MinCostDelta: 10, MinDeviationPercent: 20})
+	require.True(t, ok)
+	assert.True(t, res.Anomalous)
+	assert.InDelta(t, 244, res.Deviation, 0.5)
+	assert.Greater(t, res.Score, 3.0)
+
+	// a change below the minimum delta is not flagged however unusual it is
+	res, ok = Evaluate(series, series[56].Date, Config{MinCostDelta: 1000})
+	require.True(t, ok)
+	assert.False(t, res.Anomalous)
+}
+
+func TestEvaluateNotEnoughHistory(t *testing.T) {
+	series := weeklySeries(10)
+	_, ok := Evaluate(series, series[9].Date, Config{})
+	assert.False(t, ok)
+
+	// no cost on the date
+	_, ok = Evaluate(weeklySeries(30), testStart.AddDate(0, 0, 40), Config{})
+	assert.False(t, ok)
+}
+
+func TestSensitivityThreshold(t *testing.T) {
+	assert.Greater(t, SensitivityLow.Threshold(), SensitivityMedium.Threshold())
+	assert.Greater(t, SensitivityMedium.Threshold(), SensitivityHigh.Threshold())
+	assert.Equal(t, SensitivityMedium.Threshold(), Sensitivity("").Threshold())
+}
+
+func TestDetect(t *testing.T) {
+	date := testStart.AddDate(0, 0, 56)
+
+	spiking := weeklySeries(57)
+	spiking[56].Cost = 400
+	series := map[SeriesKey][]Point{
+		{MetricID: "ec2", ConnectionID: "conn-1"}: spiking,
+		{MetricID: "s3", ConnectionID: "conn-1"}:  weeklySeries(57),
+		{MetricID: "ec2", ConnectionID: "conn-2"}: weeklySeries(57),
+		{MetricID: "rds", ConnectionID: "conn-3"}: spiking,
+	}
+	muted := func(key SeriesKey) bool {
+		return key.ConnectionID == "conn-3"
+	}
+
+	anomalies := Detect(series, []time.Time{date}, Config{MinCostDelta: 10}, muted)
+	require.Len(t, anomalies, 1)
+
+	anomaly := anomalies[0]
+	assert.Equal(t, "conn-1", anomaly.ConnectionID)
+	assert.True(t, anomaly.Date.Equal(date))
+	assert.InDelta(t, 556, anomaly.Actual, 0.5)
+	assert.InDelta(t, 312, anomaly.Expected, 1)
+	require.Len(t, anomaly.Contributors, 2)
+	assert.Equal(t, "ec2", anomaly.Contributors[0].MetricID)
+	assert.True(t, anomaly.Contributors[0].Anomalous)
+	assert.Equal(t, "s3", anomaly.Contributors[1].MetricID)
+	assert.False(t, anomaly.Contributors[1].Anomalous)
+}
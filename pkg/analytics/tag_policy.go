@@ -0,0 +1,176 @@
+package analytics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	authApi "github.com/opengovern/og-util/pkg/api"
+	"github.com/opengovern/og-util/pkg/es"
+	esSinkClient "github.com/opengovern/og-util/pkg/es/ingest/client"
+	"github.com/opengovern/og-util/pkg/httpclient"
+	"github.com/opengovern/og-util/pkg/opengovernance-es-sdk"
+	"github.com/opengovern/opengovernance/pkg/analytics/db"
+	"github.com/opengovern/opengovernance/pkg/analytics/es/tagpolicy"
+	evaluator "github.com/opengovern/opengovernance/pkg/analytics/tagpolicy"
+	describeEs "github.com/opengovern/opengovernance/pkg/describe/es"
+	"go.uber.org/zap"
+)
+
+const tagPolicyResultsBatchSize = 1000
+
+type lookupResourceHit struct {
+	ID     string            `json:"_id"`
+	Source es.LookupResource `json:"_source"`
+	Sort   []any             `json:"sort"`
+}
+
+type lookupResourceSearchResponse struct {
+	PitID string `json:"pit_id"`
+	Hits  struct {
+		Hits []lookupResourceHit `json:"hits"`
+	} `json:"hits"`
+}
+
+// EvaluateTagPolicies evaluates the enabled tag policies against every resource in the lookup index
+// and replaces the results of previous jobs. Policies with an invalid value regex are skipped.
+func (j *Job) EvaluateTagPolicies(ctx context.Context, dbc db.Database, esClient opengovernance.Client, sinkClient esSinkClient.EsSinkServiceClient, logger *zap.Logger) error {
+	dbPolicies, err := dbc.ListTagPolicies(true)
+	if err != nil {
+		return err
+	}
+	policies := make([]*evaluator.Policy, 0, len(dbPolicies))
+	for _, dbPolicy := range dbPolicies {
+		policy, err := evaluator.Compile(dbPolicy)
+		if err != nil {
+			logger.Error("failed to compile tag policy", zap.String("policyID", dbPolicy.ID), zap.Error(err))
+			continue
+		}
+		policies = append(policies, policy)
+	}
+	if len(policies) == 0 {
+		// the results of the deleted policies are still removed
+		return j.deleteOldTagPolicyResults(ctx, sinkClient, logger)
+	}
+
+	paginator, err := opengovernance.NewPaginator(esClient.ES(), es.InventorySummaryIndex, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := paginator.Deallocate(ctx); err != nil {
+			logger.Error("failed to deallocate paginator", zap.Error(err))
+		}
+	}()
+	paginator.UpdatePageSize(tagPolicyResultsBatchSize)
+
+	now := time.Now().UnixMilli()
+	var docs []es.Doc
+	flush := func() error {
+		if len(docs) == 0 {
+			return nil
+		}
+		if _, err := sinkClient.Ingest(&httpclient.Context{Ctx: ctx, UserRole: authApi.InternalRole}, docs); err != nil {
+			logger.Error("failed to send tag policy results to es sink", zap.Error(err))
+			return err
+		}
+		docs = nil
+		return nil
+	}
+
+	for !paginator.Done() {
+		var response lookupResourceSearchResponse
+		if err := paginator.Search(ctx, &response); err != nil {
+			return err
+		}
+
+		for _, hit := range response.Hits.Hits {
+			resource := hit.Source
+			tags := make(map[string][]string)
+			for _, tag := range resource.Tags {
+				tags[tag.Key] = append(tags[tag.Key], tag.Value)
+			}
+
+			for _, policy := range policies {
+				if !evaluator.InScope(policy.TagPolicy, resource.SourceType.String(), resource.SourceID, resource.ResourceType) {
+					continue
+				}
+				evaluation := policy.Evaluate(tags)
+
+				result := tagpolicy.TagPolicyResult{
+					PolicyID:      policy.ID,
+					ResourceID:    resource.ResourceID,
+					ResourceName:  resource.Name,
+					ResourceType:  strings.ToLower(resource.ResourceType),
+					ConnectionID:  resource.SourceID,
+					Connector:     resource.SourceType,
+					Location:      resource.Location,
+					Owner:         evaluation.Owner,
+					Compliant:     evaluation.Compliant,
+					Reason:        strings.Join(evaluation.Violations, "; "),
+					Violations:    evaluation.Violations,
+					ViolatingKeys: evaluation.ViolatingKeys,
+					Suggestions:   evaluation.Suggestions,
+					JobID:         j.JobID,
+					EvaluatedAt:   now,
+				}
+				keys, idx := result.KeysAndIndex()
+				result.EsID = es.HashOf(keys...)
+				result.EsIndex = idx
+				docs = append(docs, result)
+			}
+
+			if len(docs) >= tagPolicyResultsBatchSize {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+		}
+
+		hits := int64(len(response.Hits.Hits))
+		if hits > 0 {
+			paginator.UpdateState(hits, response.Hits.Hits[hits-1].Sort, response.PitID)
+		} else {
+			paginator.UpdateState(hits, nil, "")
+		}
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+
+	return j.deleteOldTagPolicyResults(ctx, sinkClient, logger)
+}
+
+func (j *Job) deleteOldTagPolicyResults(ctx context.Context, sinkClient esSinkClient.EsSinkServiceClient, logger *zap.Logger) error {
+	root := map[string]any{
+		"query": map[string]any{
+			"bool": map[string]any{
+				"filter": []opengovernance.BoolFilter{
+					opengovernance.NewRangeFilter("job_id", "", "", fmt.Sprintf("%d", j.JobID), ""),
+				},
+			},
+		},
+	}
+	rootJson, err := json.Marshal(root)
+	if err != nil {
+		return err
+	}
+
+	task := describeEs.DeleteTask{
+		DiscoveryJobID: j.JobID,
+		ResourceType:   "tag-policy-result",
+		TaskType:       describeEs.DeleteTaskTypeQuery,
+		Query:          string(rootJson),
+		QueryIndex:     tagpolicy.TagPolicyResultsIndex,
+	}
+	keys, idx := task.KeysAndIndex()
+	task.EsID = es.HashOf(keys...)
+	task.EsIndex = idx
+	if _, err := sinkClient.Ingest(&httpclient.Context{Ctx: ctx, UserRole: authApi.InternalRole}, []es.Doc{task}); err != nil {
+		logger.Error("failed to send delete message to elastic", zap.Error(err))
+		return err
+	}
+	return nil
+}
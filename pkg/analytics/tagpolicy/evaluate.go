@@ -0,0 +1,180 @@
+package tagpolicy
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/opengovern/opengovernance/pkg/analytics/db"
+)
+
+const DefaultOwnerTagKey = "owner"
+
+type Evaluation struct {
+	Compliant     bool
+	Owner         string
+	Violations    []string
+	ViolatingKeys []string
+	Suggestions   []string
+}
+
+// InScope reports whether a resource is governed by the policy.
+func InScope(policy db.TagPolicy, connector, connectionID, resourceType string) bool {
+	if len(policy.Connectors) > 0 && !containsFold(policy.Connectors, connector) {
+		return false
+	}
+	if len(policy.ConnectionIDs) > 0 && !containsFold(policy.ConnectionIDs, connectionID) {
+		return false
+	}
+	if len(policy.ResourceTypes) > 0 && !containsFold(policy.ResourceTypes, resourceType) {
+		return false
+	}
+	return true
+}
+
+// Policy is a tag policy with its value regexes compiled, to be evaluated against many resources.
+type Policy struct {
+	db.TagPolicy
+	// valueRegexes holds the compiled value regex of each key rule, nil for the rules without one
+	valueRegexes []*regexp.Regexp
+}
+
+// Compile compiles the value regexes of the policy.
+func Compile(policy db.TagPolicy) (*Policy, error) {
+	res := Policy{
+		TagPolicy:    policy,
+		valueRegexes: make([]*regexp.Regexp, len(policy.Keys)),
+	}
+	for i, rule := range policy.Keys {
+		if rule.ValueRegex == "" {
+			continue
+		}
+		valueRegex, err := regexp.Compile(rule.ValueRegex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value regex for tag %s: %w", rule.Key, err)
+		}
+		res.valueRegexes[i] = valueRegex
+	}
+	return &res, nil
+}
+
+// Evaluate checks the tags of a resource against the policy. Keys and values that only differ from
+// the expected ones by case are reported with a normalization suggestion, e.g. Env instead of env.
+func (p *Policy) Evaluate(tags map[string][]string) Evaluation {
+	policy := p.TagPolicy
+	var res Evaluation
+
+	ownerKey := policy.OwnerTagKey
+	if ownerKey == "" {
+		ownerKey = DefaultOwnerTagKey
+	}
+	if _, values, ok := lookupTag(tags, ownerKey); ok && len(values) > 0 {
+		res.Owner = values[0]
+	}
+
+	for i, rule := range policy.Keys {
+		key, values, ok := lookupTag(tags, rule.Key)
+		if !ok {
+			if rule.Required {
+				res.addViolation(rule.Key, fmt.Sprintf("missing required tag %s", rule.Key), "")
+			}
+			continue
+		}
+		if key != rule.Key {
+			res.addViolation(rule.Key, fmt.Sprintf("tag key %s should be %s", key, rule.Key),
+				fmt.Sprintf("rename tag key %s to %s", key, rule.Key))
+		}
+
+		valueRegex := p.valueRegexes[i]
+		for _, value := range values {
+			if value == "" {
+				if rule.Required {
+					res.addViolation(rule.Key, fmt.Sprintf("tag %s is empty", rule.Key), "")
+				}
+				continue
+			}
+
+			if len(rule.AllowedValues) > 0 && !contains(rule.AllowedValues, value) {
+				if allowed, ok := findFold(rule.AllowedValues, value); ok {
+					res.addViolation(rule.Key, fmt.Sprintf("value %s of tag %s should be %s", value, rule.Key, allowed),
+						fmt.Sprintf("change value of tag %s from %s to %s", rule.Key, value, allowed))
+				} else {
+					res.addViolation(rule.Key, fmt.Sprintf("value %s of tag %s is not one of %s", value, rule.Key, strings.Join(rule.AllowedValues, ", ")), "")
+				}
+			}
+
+			if valueRegex != nil && !valueRegex.MatchString(value) {
+				res.addViolation(rule.Key, fmt.Sprintf("value %s of tag %s does not match %s", value, rule.Key, rule.ValueRegex), "")
+			}
+
+			switch rule.ValueCase {
+			case db.TagCaseRuleLower:
+				if value != strings.ToLower(value) {
+					res.addViolation(rule.Key, fmt.Sprintf("value %s of tag %s should be lower case", value, rule.Key),
+						fmt.Sprintf("change value of tag %s from %s to %s", rule.Key, value, strings.ToLower(value)))
+				}
+			case db.TagCaseRuleUpper:
+				if value != strings.ToUpper(value) {
+					res.addViolation(rule.Key, fmt.Sprintf("value %s of tag %s should be upper case", value, rule.Key),
+						fmt.Sprintf("change value of tag %s from %s to %s", rule.Key, value, strings.ToUpper(value)))
+				}
+			}
+		}
+	}
+
+	res.Compliant = len(res.Violations) == 0
+	sort.Strings(res.ViolatingKeys)
+	return res
+}
+
+func (e *Evaluation) addViolation(key, violation, suggestion string) {
+	e.Violations = append(e.Violations, violation)
+	if !contains(e.ViolatingKeys, key) {
+		e.ViolatingKeys = append(e.ViolatingKeys, key)
+	}
+	if suggestion != "" && !contains(e.Suggestions, suggestion) {
+		e.Suggestions = append(e.Suggestions, suggestion)
+	}
+}
+
+// lookupTag returns the tag with the exact key, or else the first one whose key matches case-insensitively.
+func lookupTag(tags map[string][]string, key string) (string, []string, bool) {
+	if values, ok := tags[key]; ok {
+		return key, values, true
+	}
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if strings.EqualFold(k, key) {
+			return k, tags[k], true
+		}
+	}
+	return "", nil, false
+}
+
+func contains(arr []string, s string) bool {
+	for _, v := range arr {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(arr []string, s string) bool {
+	_, ok := findFold(arr, s)
+	return ok
+}
+
+func findFold(arr []string, s string) (string, bool) {
+	for _, v := range arr {
+		if strings.EqualFold(v, s) {
+			return v, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,83 @@
+package tagpolicy
+
+import (
+	"testing"
+
+	"github.com/opengovern/opengovernance/pkg/analytics/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testPolicy = db.TagPolicy{
+	ID: "mandatory-tags",
+	Keys: []db.TagPolicyKey{
+		{Key: "owner", Required: true},
+		{Key: "cost-center", Required: true, ValueRegex: `^cc-[0-9]+$`},
+		{Key: "env", Required: true, AllowedValues: []string{"prod", "staging", "dev"}},
+		{Key: "team", ValueCase: db.TagCaseRuleLower},
+	},
+}
+
+func TestEvaluateCompliant(t *testing.T) {
+	policy, err := Compile(testPolicy)
+	require.NoError(t, err)
+	res := policy.Evaluate(map[string][]string{
+		"owner":       {"alice"},
+		"cost-center": {"cc-42"},
+		"env":         {"prod"},
+	})
+	assert.True(t, res.Compliant)
+	assert.Equal(t, "alice", res.Owner)
+	assert.Empty(t, res.Violations)
+}
+
+func TestEvaluateViolations(t *testing.T) {
+	policy, err := Compile(testPolicy)
+	require.NoError(t, err)
+	res := policy.Evaluate(map[string][]string{
+		"Env":         {"Prod"},
+		"cost-center": {"finance"},
+		"team":        {"Platform"},
+	})
+	assert.False(t, res.Compliant)
+	assert.Equal(t, []string{"cost-center", "env", "owner", "team"}, res.ViolatingKeys)
+	assert.Contains(t, res.Violations, "missing required tag owner")
+	assert.Contains(t, res.Suggestions, "rename tag key Env to env")
+	assert.Contains(t, res.Suggestions, "change value of tag env from Prod to prod")
+	assert.Contains(t, res.Suggestions, "change value of tag team from Platform to platform")
+}
+
+func TestCompileInvalidRegex(t *testing.T) {
+	_, err := Compile(db.TagPolicy{Keys: []db.TagPolicyKey{{Key: "a", ValueRegex: "("}}})
+	assert.Error(t, err)
+}
+
+func TestEvaluateValueRegexPerRule(t *testing.T) {
+	policy, err := Compile(db.TagPolicy{Keys: []db.TagPolicyKey{
+		{Key: "env"},
+		{Key: "cost-center", ValueRegex: `^cc-[0-9]+$`},
+		{Key: "project", ValueRegex: `^[a-z]+$`},
+	}})
+	require.NoError(t, err)
+
+	for _, tags := range []map[string][]string{
+		{"env": {"Prod"}, "cost-center": {"cc-1"}, "project": {"web"}},
+		{"env": {"cc-1"}},
+	} {
+		assert.True(t, policy.Evaluate(tags).Compliant, tags)
+	}
+
+	res := policy.Evaluate(map[string][]string{"cost-center": {"web"}, "project": {"cc-1"}})
+	assert.Equal(t, []string{"cost-center", "project"}, res.ViolatingKeys)
+	assert.Equal(t, []string{
+		"value web of tag cost-center does not match ^cc-[0-9]+$",
+		"value cc-1 of tag project does not match ^[a-z]+$",
+	}, res.Violations)
+}
+
+func TestInScope(t *testing.T) {
+	policy := db.TagPolicy{Connectors: []string{"AWS"}, ResourceTypes: []string{"AWS::EC2::Instance"}}
+	assert.True(t, InScope(policy, "AWS", "c1", "aws::ec2::instance"))
+	assert.False(t, InScope(policy, "Azure", "c1", "aws::ec2::instance"))
+	assert.False(t, InScope(policy, "AWS", "c1", "aws::s3::bucket"))
+}
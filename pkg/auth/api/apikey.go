@@ -6,9 +6,33 @@ import (
 	"time"
 )
 
+type APIKeyScope string
+
+const (
+	APIKeyScopeFindingsRead     APIKeyScope = "findings:read"
+	APIKeyScopeQueryRun         APIKeyScope = "query:run"
+	APIKeyScopeDiscoveryTrigger APIKeyScope = "discovery:trigger"
+)
+
+var APIKeyScopes = []APIKeyScope{
+	APIKeyScopeFindingsRead,
+	APIKeyScopeQueryRun,
+	APIKeyScopeDiscoveryTrigger,
+}
+
 type CreateAPIKeyRequest struct {
-	Name     string   `json:"name"`                                                 // Name of the key
-	RoleName api.Role `json:"roleName" enums:"admin,editor,viewer" example:"admin"` // Name of the role
+	Name      string        `json:"name"`                                                               // Name of the key
+	RoleName  api.Role      `json:"roleName" enums:"admin,editor,viewer" example:"admin"`               // Name of the role, defaults to editor and can not be higher than the role of the creator
+	ExpiresAt *time.Time    `json:"expiresAt,omitempty" example:"2024-03-31T09:36:09.855Z"`             // Expiration timestamp in UTC, the key never expires if not set
+	Scopes    []APIKeyScope `json:"scopes,omitempty" enums:"findings:read,query:run,discovery:trigger"` // Restricts the key to the given operations, the key can access everything its role allows if not set
+
+	// Restricts the key to the given resources, in addition to the restrictions of the creator's role binding
+	ConnectionIDs []string `json:"connectionIDs,omitempty"`
+	RoleBindingScope
+}
+
+type RotateAPIKeyRequest struct {
+	GracePeriodHours *int `json:"gracePeriodHours,omitempty" example:"24"` // Hours the previous secret stays valid, defaults to 24
 }
 
 type CreateAPIKeyResponse struct {
@@ -18,6 +42,16 @@ type CreateAPIKeyResponse struct {
 	CreatedAt time.Time `json:"createdAt" example:"2023-03-31T09:36:09.855Z"`         // Creation timestamp in UTC
 	RoleName  api.Role  `json:"roleName" enums:"admin,editor,viewer" example:"admin"` // Name of the role
 	Token     string    `json:"token"`                                                // Token of the key
+
+	ExpiresAt *time.Time    `json:"expiresAt,omitempty" example:"2024-03-31T09:36:09.855Z"` // Expiration timestamp in UTC
+	Scopes    []APIKeyScope `json:"scopes,omitempty" enums:"findings:read,query:run,discovery:trigger"`
+}
+
+type RotateAPIKeyResponse struct {
+	ID                      uint       `json:"id" example:"1"`                                                       // Unique identifier for the key
+	Name                    string     `json:"name" example:"example"`                                               // Name of the key
+	Token                   string     `json:"token"`                                                                // New token of the key
+	PreviousTokenValidUntil *time.Time `json:"previousTokenValidUntil,omitempty" example:"2023-04-01T09:36:09.855Z"` // The previous token is rejected after this timestamp
 }
 
 type WorkspaceApiKey struct {
@@ -29,6 +63,14 @@ type WorkspaceApiKey struct {
 	CreatorUserID string    `json:"creatorUserID" example:"auth|123456789"`               // Unique identifier of the user who created the key
 	Active        bool      `json:"active" example:"true"`                                // Activity state of the key
 	MaskedKey     string    `json:"maskedKey" example:"abc...de"`                         // Masked key
+
+	Scopes                  []APIKeyScope `json:"scopes,omitempty" enums:"findings:read,query:run,discovery:trigger"`
+	ExpiresAt               *time.Time    `json:"expiresAt,omitempty" example:"2024-03-31T09:36:09.855Z"`               // Expiration timestamp in UTC
+	LastUsedAt              *time.Time    `json:"lastUsedAt,omitempty" example:"2023-04-21T08:53:09.928Z"`              // Last time the key was used, tracked with a precision of a minute
+	PreviousTokenValidUntil *time.Time    `json:"previousTokenValidUntil,omitempty" example:"2023-04-01T09:36:09.855Z"` // End of the grace period of the token replaced by the last rotation
+
+	ConnectionIDs []string `json:"connectionIDs,omitempty"`
+	RoleBindingScope
 }
 
 type UpdateKeyRoleRequest struct {
@@ -0,0 +1,26 @@
+package api
+
+import "github.com/opengovern/opengovernance/pkg/auth/audit"
+
+type AuditEntry struct {
+	ID uint64 `json:"id"`
+	audit.Event
+	PrevHash string `json:"prevHash"` // Hash of the previous entry of the chain
+	Hash     string `json:"hash"`     // sha256 of PrevHash and the event
+}
+
+type ListAuditEntriesResponse struct {
+	Entries    []AuditEntry `json:"entries"`
+	NextCursor *uint64      `json:"nextCursor,omitempty"` // Pass as cursor to get the next (older) page
+}
+
+type VerifyAuditLogResponse struct {
+	Valid          bool    `json:"valid"`
+	CheckedEntries int64   `json:"checkedEntries"`
+	FirstInvalidID *uint64 `json:"firstInvalidId,omitempty"`
+	Reason         string  `json:"reason,omitempty"`
+}
+
+type AuditLogSettings struct {
+	RetentionDays int `json:"retentionDays" validate:"required,min=1"`
+}
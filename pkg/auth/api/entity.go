@@ -2,6 +2,7 @@ package api
 
 import (
 	"github.com/opengovern/og-util/pkg/api"
+	"github.com/opengovern/opengovernance/pkg/auth/audit"
 	"time"
 )
 
@@ -14,7 +15,22 @@ type PutRoleBindingRequest struct {
 	UserID        string   `json:"userId" validate:"required" example:"auth|123456789"`                      // Unique identifier for the User
 	RoleName      api.Role `json:"roleName" validate:"required" enums:"admin,editor,viewer" example:"admin"` // Name of the role
 	ConnectionIDs []string `json:"connectionIDs"`                                                            // Name of the role
+
+	RoleBindingScope
+}
+
+// RoleBindingScope restricts a role binding to the union of the given connection groups and the connections of
+// the given resource collections, and optionally to the given benchmarks. Empty fields do not restrict.
+type RoleBindingScope struct {
+	ConnectionGroups      []string `json:"connectionGroups,omitempty" example:"UltraSightApplication"` // Connection groups the binding is restricted to
+	ResourceCollectionIDs []string `json:"resourceCollectionIDs,omitempty" example:"rc-production"`    // Resource collections the binding is restricted to
+	BenchmarkIDs          []string `json:"benchmarkIDs,omitempty" example:"aws_cis_v200"`              // Benchmarks the binding is restricted to
+}
+
+func (s RoleBindingScope) IsEmpty() bool {
+	return len(s.ConnectionGroups) == 0 && len(s.ResourceCollectionIDs) == 0 && len(s.BenchmarkIDs) == 0
 }
+
 type RolesListResponse struct {
 	RoleName    api.Role `json:"roleName" enums:"admin,editor,viewer" example:"admin"`                                                                                                                                                                                                      // Name of the role
 	Description string   `json:"description" example:"The Administrator role is a super user role with all of the capabilities that can be assigned to a role, and its enables access to all data & configuration on a Kaytu Workspace. You cannot edit or delete the Administrator role."` // Role Description and accesses
@@ -64,6 +80,8 @@ type WorkspaceRoleBinding struct {
 	LastActivity        *string      `json:"lastActivity" example:"2023-04-21T08:53:09.928Z"`      // Last activity timestamp in UTC
 	CreatedAt           *string      `json:"createdAt" example:"2023-03-31T09:36:09.855Z"`         // Creation timestamp in UTC
 	ScopedConnectionIDs []string     `json:"scopedConnectionIDs"`
+
+	RoleBindingScope
 }
 
 type GetWorkspaceRoleBindingResponse []WorkspaceRoleBinding // List of Workspace Role Binding objects
@@ -85,6 +103,8 @@ type GetUsersResponse struct {
 	Email         string   `json:"email" example:"johndoe@example.com"`                  // Email address of the user
 	EmailVerified bool     `json:"emailVerified" example:"true"`                         // Is email verified or not
 	RoleName      api.Role `json:"roleName" enums:"admin,editor,viewer" example:"admin"` // Name of the role
+
+	PrincipalType audit.ActorType `json:"principalType" enums:"user,service_account" example:"user"` // Service accounts are listed after the users
 }
 
 type GetUsersRequest struct {
@@ -122,6 +142,8 @@ type RoleBinding struct {
 	WorkspaceName       string   `json:"workspaceName" example:"demo"`                         // Name of the workspace
 	RoleName            api.Role `json:"roleName" enums:"admin,editor,viewer" example:"admin"` // Name of the binding role
 	ScopedConnectionIDs []string `json:"scopedConnectionIDs"`
+
+	RoleBindingScope
 }
 
 type Theme string
@@ -0,0 +1,44 @@
+package api
+
+import "github.com/opengovern/og-util/pkg/api"
+
+type OidcClaimType string
+
+const (
+	OidcClaimTypeGroup       OidcClaimType = "group"        // matches the groups claim
+	OidcClaimTypeEmailDomain OidcClaimType = "email_domain" // matches the domain of the email
+	OidcClaimTypeClaim       OidcClaimType = "claim"        // matches the claim named by Claim
+)
+
+type OidcRoleRule struct {
+	ClaimType OidcClaimType `json:"claimType" validate:"required,oneof=group email_domain claim" enums:"group,email_domain,claim" example:"group"`
+	Claim     string        `json:"claim,omitempty" example:"department"`                                 // Claim to match, for the claim type, nested claims are separated by dots
+	Value     string        `json:"value" validate:"required" example:"kaytu-admins"`                     // Value to match, case-insensitive, * matches any characters
+	Role      api.Role      `json:"role" validate:"required" enums:"admin,editor,viewer" example:"admin"` // Role given by the rule
+
+	RoleBindingScope
+}
+
+type OidcRoleMapping struct {
+	Rules         []OidcRoleRule `json:"rules" validate:"dive"`
+	DefaultRole   api.Role       `json:"defaultRole" enums:"admin,editor,viewer" example:"viewer"` // Role of the users no rule matches, required with rules unless denyUnmatched is set
+	DenyUnmatched bool           `json:"denyUnmatched"`                                            // Deny access to the users no rule matches instead
+}
+
+type OidcRoleDryRunRequest struct {
+	Token  string         `json:"token,omitempty"`  // ID token issued by the identity provider
+	Claims map[string]any `json:"claims,omitempty"` // Claims to evaluate instead of a token
+}
+
+type OidcRoleDryRunResponse struct {
+	Email              string   `json:"email"`
+	Groups             []string `json:"groups"`
+	EmailDomainAllowed bool     `json:"emailDomainAllowed"`
+	RulesConfigured    bool     `json:"rulesConfigured"` // The role is left as is if no rule is configured
+	MatchedRules       []int    `json:"matchedRules"`    // Indexes of the matching rules
+	Denied             bool     `json:"denied"`
+	Reason             string   `json:"reason,omitempty"`
+	Role               api.Role `json:"role,omitempty"` // Empty if the role is left as is
+
+	RoleBindingScope
+}
@@ -0,0 +1,17 @@
+package api
+
+import "github.com/opengovern/og-util/pkg/api"
+
+type ScimGroupRole struct {
+	GroupName string   `json:"groupName" validate:"required" example:"kaytu-admins"`                 // Display name of the group in the identity provider
+	Role      api.Role `json:"role" validate:"required" enums:"admin,editor,viewer" example:"admin"` // Role given to the members of the group
+}
+
+type PutScimGroupRolesRequest struct {
+	GroupRoles []ScimGroupRole `json:"groupRoles" validate:"dive"`
+}
+
+type CreateScimTokenResponse struct {
+	Token   string `json:"token"`   // Bearer token of the SCIM client, only shown once
+	BaseURL string `json:"baseUrl"` // Address of the SCIM endpoints
+}
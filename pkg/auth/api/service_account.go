@@ -0,0 +1,57 @@
+package api
+
+import (
+	"time"
+
+	"github.com/opengovern/og-util/pkg/api"
+)
+
+type ServiceAccount struct {
+	ID            uint      `json:"id" example:"1"`                                             // Unique identifier for the service account
+	UserID        string    `json:"userId" example:"service-account|1"`                         // User ID of the requests of the service account, e.g. in the created_by of jobs
+	Name          string    `json:"name" example:"ci-pipeline"`                                 // Name of the service account
+	Description   string    `json:"description,omitempty" example:"Runs the compliance checks"` // Description of the service account
+	OwnerTeam     string    `json:"ownerTeam,omitempty" example:"platform"`                     // Team responsible for the service account
+	RoleName      api.Role  `json:"roleName" enums:"admin,editor,viewer" example:"viewer"`      // Role of the service account in the workspace
+	Disabled      bool      `json:"disabled" example:"false"`                                   // Disabled service accounts can not authenticate
+	CreatorUserID string    `json:"creatorUserID" example:"auth|123456789"`                     // Unique identifier of the user who created the service account
+	CreatedAt     time.Time `json:"createdAt" example:"2023-03-31T09:36:09.855Z"`               // Creation timestamp in UTC
+	UpdatedAt     time.Time `json:"updatedAt" example:"2023-04-21T08:53:09.928Z"`               // Last update timestamp in UTC
+
+	MaskedClientSecret    string     `json:"maskedClientSecret,omitempty" example:"sas...de"`                    // Masked client secret, empty if the client credentials grant is disabled
+	ClientSecretCreatedAt *time.Time `json:"clientSecretCreatedAt,omitempty" example:"2023-04-21T08:53:09.928Z"` // Creation timestamp of the client secret in UTC
+
+	ConnectionIDs []string `json:"connectionIDs,omitempty"`
+	RoleBindingScope
+}
+
+type ServiceAccountRequest struct {
+	Name        string   `json:"name" validate:"required" example:"ci-pipeline"`
+	Description string   `json:"description" example:"Runs the compliance checks"`
+	OwnerTeam   string   `json:"ownerTeam" example:"platform"`
+	RoleName    api.Role `json:"roleName" enums:"admin,editor,viewer" example:"viewer"` // Name of the role, defaults to viewer and can not be higher than the role of the creator
+	Disabled    bool     `json:"disabled" example:"false"`
+
+	// Restricts the service account to the given resources
+	ConnectionIDs []string `json:"connectionIDs,omitempty"`
+	RoleBindingScope
+}
+
+type CreateServiceAccountClientSecretResponse struct {
+	ClientID     string `json:"clientId" example:"service-account|1"` // Client ID of the client credentials grant
+	ClientSecret string `json:"clientSecret"`                         // Client secret, only shown once
+}
+
+// ServiceAccountTokenRequest is an OAuth 2.0 client credentials token request, the client may also
+// authenticate with basic auth.
+type ServiceAccountTokenRequest struct {
+	GrantType    string `json:"grant_type" form:"grant_type" example:"client_credentials"`
+	ClientID     string `json:"client_id" form:"client_id" example:"service-account|1"`
+	ClientSecret string `json:"client_secret" form:"client_secret"`
+}
+
+type ServiceAccountTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type" example:"Bearer"`
+	ExpiresIn   int    `json:"expires_in" example:"3600"` // Lifetime of the token in seconds
+}
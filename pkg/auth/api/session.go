@@ -0,0 +1,29 @@
+package api
+
+import "time"
+
+type UserSession struct {
+	TokenID     string     `json:"tokenId"`                                                // Identifier of the token, its jti claim or a hash of the token
+	UserID      string     `json:"userId" example:"dex|johndoe@example.com"`               // Unique identifier of the user
+	Email       string     `json:"email" example:"johndoe@example.com"`                    // Email address of the user
+	IssuedAt    *time.Time `json:"issuedAt,omitempty" example:"2023-04-21T08:53:09.928Z"`  // Issue timestamp of the token in UTC
+	ExpiresAt   *time.Time `json:"expiresAt,omitempty" example:"2023-04-22T08:53:09.928Z"` // Expiration timestamp of the token in UTC
+	FirstSeenAt time.Time  `json:"firstSeenAt" example:"2023-04-21T08:53:09.928Z"`         // First time the token was used, in UTC
+	LastSeenAt  time.Time  `json:"lastSeenAt" example:"2023-04-21T09:53:09.928Z"`          // Last time the token was used in UTC, tracked with a precision of a minute
+	SourceIP    string     `json:"sourceIp,omitempty" example:"10.0.0.1"`                  // Address the token was last used from
+	UserAgent   string     `json:"userAgent,omitempty"`                                    // User agent the token was last used with
+}
+
+type SessionSettings struct {
+	MaxTokenAgeMinutes     int  `json:"maxTokenAgeMinutes" validate:"min=0" example:"720"`    // Tokens issued before this many minutes are rejected, 0 disables the limit
+	LockoutThreshold       int  `json:"lockoutThreshold" validate:"min=0" example:"5"`        // Failed local password logins locking the user out, 0 disables the lockout
+	LockoutDurationMinutes int  `json:"lockoutDurationMinutes" validate:"min=0" example:"15"` // Minutes the user stays locked out
+	RequireMFA             bool `json:"requireMfa" example:"false"`                           // Rejects the ID tokens without a multi-factor method in their amr claim
+}
+
+type LoginLockout struct {
+	UserID         string     `json:"userId" example:"dex|johndoe@example.com"`
+	FailedAttempts int        `json:"failedAttempts" example:"5"`
+	LastFailedAt   time.Time  `json:"lastFailedAt" example:"2023-04-21T08:53:09.928Z"`
+	LockedUntil    *time.Time `json:"lockedUntil,omitempty" example:"2023-04-21T09:08:09.928Z"`
+}
@@ -0,0 +1,23 @@
+package auth
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/opengovern/opengovernance/pkg/auth/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIKeyScopesAllow(t *testing.T) {
+	findings := []string{string(api.APIKeyScopeFindingsRead)}
+	assert.True(t, apiKeyScopesAllow(nil, http.MethodDelete, "/compliance/api/v1/benchmarks"))
+	assert.True(t, apiKeyScopesAllow(findings, http.MethodPost, "/compliance/api/v1/findings"))
+	assert.True(t, apiKeyScopesAllow(findings, http.MethodGet, "/kaytu/compliance/api/v1/findings/single/abc"))
+	assert.False(t, apiKeyScopesAllow(findings, http.MethodPost, "/inventory/api/v1/query/run"))
+	assert.False(t, apiKeyScopesAllow(findings, http.MethodDelete, "/compliance/api/v1/findings"))
+
+	query := []string{string(api.APIKeyScopeQueryRun), string(api.APIKeyScopeDiscoveryTrigger)}
+	assert.True(t, apiKeyScopesAllow(query, http.MethodPost, "/inventory/api/v3/query/run"))
+	assert.True(t, apiKeyScopesAllow(query, http.MethodPut, "/schedule/api/v1/describe/trigger/abc"))
+	assert.False(t, apiKeyScopesAllow(query, http.MethodGet, "/inventory/api/v3/queries"))
+}
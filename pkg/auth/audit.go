@@ -0,0 +1,239 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	envoyauth "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
+	"github.com/jackc/pgtype"
+	"github.com/opengovern/opengovernance/pkg/auth/api"
+	"github.com/opengovern/opengovernance/pkg/auth/audit"
+	"github.com/opengovern/opengovernance/pkg/auth/db"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultAuditLogRetentionDays = 365
+	auditLogRetentionPeriod      = time.Hour
+	auditLogVerifyPageSize       = 1000
+)
+
+func auditEntryFromEvent(event audit.Event) (db.AuditEntry, error) {
+	entry := db.AuditEntry{
+		Type:           string(event.Type),
+		Time:           event.Time,
+		Service:        event.Service,
+		ActorID:        event.ActorID,
+		ActorType:      string(event.ActorType),
+		APIKeyID:       event.APIKeyID,
+		Role:           event.Role,
+		WorkspaceID:    event.WorkspaceID,
+		Method:         event.Method,
+		Route:          event.Route,
+		Path:           event.Path,
+		RequestSummary: event.RequestSummary,
+		Outcome:        string(event.Outcome),
+		StatusCode:     event.StatusCode,
+		Reason:         event.Reason,
+		SourceIP:       event.SourceIP,
+	}
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	if len(event.TargetIDs) > 0 {
+		targetIDs, err := json.Marshal(event.TargetIDs)
+		if err != nil {
+			return entry, err
+		}
+		if err := entry.TargetIDs.Set(targetIDs); err != nil {
+			return entry, err
+		}
+	} else {
+		entry.TargetIDs.Status = pgtype.Null
+	}
+	return entry, nil
+}
+
+func auditEntryToApi(entry db.AuditEntry) api.AuditEntry {
+	var targetIDs map[string]string
+	if entry.TargetIDs.Status == pgtype.Present {
+		_ = json.Unmarshal(entry.TargetIDs.Bytes, &targetIDs)
+	}
+	return api.AuditEntry{
+		ID: entry.ID,
+		Event: audit.Event{
+			Type:           audit.EventType(entry.Type),
+			Time:           entry.Time.UTC(),
+			Service:        entry.Service,
+			ActorID:        entry.ActorID,
+			ActorType:      audit.ActorType(entry.ActorType),
+			APIKeyID:       entry.APIKeyID,
+			Role:           entry.Role,
+			WorkspaceID:    entry.WorkspaceID,
+			Method:         entry.Method,
+			Route:          entry.Route,
+			Path:           entry.Path,
+			TargetIDs:      targetIDs,
+			RequestSummary: entry.RequestSummary,
+			Outcome:        audit.Outcome(entry.Outcome),
+			StatusCode:     entry.StatusCode,
+			Reason:         entry.Reason,
+			SourceIP:       entry.SourceIP,
+		},
+		PrevHash: entry.PrevHash,
+		Hash:     entry.Hash,
+	}
+}
+
+// storeAuditEvents appends the events to the audit log, it is the sink of the recorder of the auth service.
+func (s *Server) storeAuditEvents(_ context.Context, events []audit.Event) error {
+	entries := make([]db.AuditEntry, 0, len(events))
+	for _, event := range events {
+		entry, err := auditEntryFromEvent(event)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, entry)
+	}
+	return s.db.AppendAuditEntries(s.auditLogKey, entries)
+}
+
+// recordCheckEvent records a request rejected while checking its token, user or API key.
+func (s *Server) recordCheckEvent(req *envoyauth.CheckRequest, user *userClaim, outcome audit.Outcome, reason string) {
+	httpRequest := req.GetAttributes().GetRequest().GetHttp()
+	event := audit.Event{
+		Type:      audit.EventTypeSecurity,
+		Service:   "auth",
+		ActorType: audit.ActorTypeAnonymous,
+		Method:    httpRequest.GetMethod(),
+		Path:      httpRequest.GetPath(),
+		Outcome:   outcome,
+		Reason:    reason,
+		SourceIP:  checkSourceIP(req),
+	}
+	if outcome == audit.OutcomeUnauthenticated {
+		event.StatusCode = http.StatusUnauthorized
+	} else {
+		event.StatusCode = http.StatusForbidden
+	}
+	if user != nil {
+		event.ActorID = user.ExternalUserID
+		event.ActorType = audit.ActorTypeUser
+		if user.apiKey != nil {
+			event.ActorType = audit.ActorTypeAPIKey
+			event.APIKeyID = strconv.FormatUint(uint64(user.apiKey.ID), 10)
+		}
+		if user.serviceAccount != nil {
+			event.ActorType = audit.ActorTypeServiceAccount
+		}
+	}
+	s.auditRecorder.Record(event)
+}
+
+// checkSourceIP returns the address envoy appended to x-forwarded-for, the earlier ones are sent by the client
+// and can not be trusted. The address of the connection is used if the header is missing.
+func checkSourceIP(req *envoyauth.CheckRequest) string {
+	headers := req.GetAttributes().GetRequest().GetHttp().GetHeaders()
+	for _, key := range []string{"x-forwarded-for", "X-Forwarded-For"} {
+		if value := headers[key]; value != "" {
+			addresses := strings.Split(value, ",")
+			return strings.TrimSpace(addresses[len(addresses)-1])
+		}
+	}
+	return req.GetAttributes().GetSource().GetAddress().GetSocketAddress().GetAddress()
+}
+
+func (s *Server) AuditLogRetentionLoop() {
+	for {
+		if err := s.applyAuditLogRetention(); err != nil {
+			s.logger.Error("failed to apply audit log retention", zap.Error(err))
+		}
+		time.Sleep(auditLogRetentionPeriod)
+	}
+}
+
+func (s *Server) applyAuditLogRetention() error {
+	days, err := s.db.GetAuditLogRetentionDays()
+	if err != nil {
+		return err
+	}
+	if days <= 0 {
+		days = defaultAuditLogRetentionDays
+	}
+
+	deleted, err := s.db.DeleteAuditEntriesBefore(s.auditLogKey, time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		return err
+	}
+	if deleted > 0 {
+		s.logger.Info("deleted expired audit log entries", zap.Int64("count", deleted), zap.Int("retentionDays", days))
+	}
+	return nil
+}
+
+// verifyAuditLog recomputes the hash chain. The chain starts at the last entry removed by the retention, or
+// from scratch if none has been removed, so removing the start of the log is detected as well.
+func (s *Server) verifyAuditLog() (api.VerifyAuditLogResponse, error) {
+	checkpoint, err := s.db.GetAuditLogCheckpoint()
+	if err != nil {
+		return api.VerifyAuditLogResponse{}, err
+	}
+	return verifyAuditChain(s.auditLogKey, checkpoint, s.db.ListAuditEntriesAfter)
+}
+
+// verifyAuditChain checks the entries returned page by page by listAfter against the checkpoint and the key.
+func verifyAuditChain(key []byte, checkpoint *db.AuditLogCheckpoint, listAfter func(id uint64, limit int) ([]db.AuditEntry, error)) (api.VerifyAuditLogResponse, error) {
+	var res api.VerifyAuditLogResponse
+	var lastID uint64
+	prevHash := ""
+	if checkpoint != nil {
+		if !hmac.Equal([]byte(checkpoint.MAC), []byte(checkpoint.ComputeMAC(key))) {
+			res.Reason = "the retention checkpoint has been modified"
+			return res, nil
+		}
+		lastID, prevHash = checkpoint.ID, checkpoint.Hash
+	}
+	for {
+		entries, err := listAfter(lastID, auditLogVerifyPageSize)
+		if err != nil {
+			return res, err
+		}
+		for _, entry := range entries {
+			invalid := func(reason string) (api.VerifyAuditLogResponse, error) {
+				id := entry.ID
+				res.FirstInvalidID = &id
+				res.Reason = reason
+				return res, nil
+			}
+
+			if entry.PrevHash != prevHash {
+				if res.CheckedEntries == 0 {
+					return invalid(fmt.Sprintf("entries before entry %d have been removed", entry.ID))
+				}
+				return invalid(fmt.Sprintf("entry %d does not follow entry %d", entry.ID, lastID))
+			}
+			hash, err := entry.ComputeHash(key)
+			if err != nil {
+				return res, err
+			}
+			if !hmac.Equal([]byte(hash), []byte(entry.Hash)) {
+				return invalid(fmt.Sprintf("entry %d has been modified", entry.ID))
+			}
+
+			res.CheckedEntries++
+			prevHash = entry.Hash
+			lastID = entry.ID
+		}
+		if len(entries) < auditLogVerifyPageSize {
+			break
+		}
+	}
+	res.Valid = true
+	return res, nil
+}
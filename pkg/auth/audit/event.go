@@ -0,0 +1,76 @@
+package audit
+
+import (
+	"strings"
+	"time"
+)
+
+type EventType string
+
+const (
+	EventTypeAction   EventType = "action"
+	EventTypeSecurity EventType = "security"
+)
+
+type Outcome string
+
+const (
+	OutcomeSuccess         Outcome = "success"
+	OutcomeFailure         Outcome = "failure"
+	OutcomeDenied          Outcome = "denied"
+	OutcomeUnauthenticated Outcome = "unauthenticated"
+)
+
+type ActorType string
+
+const (
+	ActorTypeUser      ActorType = "user"
+	ActorTypeAPIKey    ActorType = "api_key"
+	ActorTypeAnonymous ActorType = "anonymous"
+
+	ActorTypeServiceAccount ActorType = "service_account"
+	ActorTypeSystem         ActorType = "system"
+)
+
+// ServiceAccountIDPrefix prefixes the user ID the auth service sets for the requests of a service account.
+const ServiceAccountIDPrefix = "service-account|"
+
+// SystemUserID is the creator of the jobs triggered by the services themselves.
+const SystemUserID = "system"
+
+// ActorTypeOf returns the type of the principal with the given user ID, e.g. the CreatedBy of a job.
+func ActorTypeOf(userID string) ActorType {
+	switch {
+	case userID == "":
+		return ActorTypeAnonymous
+	case userID == SystemUserID:
+		return ActorTypeSystem
+	case strings.HasPrefix(userID, ServiceAccountIDPrefix):
+		return ActorTypeServiceAccount
+	}
+	return ActorTypeUser
+}
+
+type Event struct {
+	Type           EventType         `json:"type"`
+	Time           time.Time         `json:"time"`
+	Service        string            `json:"service"`
+	ActorID        string            `json:"actorId,omitempty"`
+	ActorType      ActorType         `json:"actorType"`
+	APIKeyID       string            `json:"apiKeyId,omitempty"`
+	Role           string            `json:"role,omitempty"`
+	WorkspaceID    string            `json:"workspaceId,omitempty"`
+	Method         string            `json:"method"`
+	Route          string            `json:"route,omitempty"`
+	Path           string            `json:"path"`
+	TargetIDs      map[string]string `json:"targetIds,omitempty"`
+	RequestSummary string            `json:"requestSummary,omitempty"`
+	Outcome        Outcome           `json:"outcome"`
+	StatusCode     int               `json:"statusCode,omitempty"`
+	Reason         string            `json:"reason,omitempty"`
+	SourceIP       string            `json:"sourceIp,omitempty"`
+}
+
+type CreateEventsRequest struct {
+	Events []Event `json:"events"`
+}
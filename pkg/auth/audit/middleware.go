@@ -0,0 +1,155 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	authApi "github.com/opengovern/og-util/pkg/api"
+	"github.com/opengovern/og-util/pkg/httpserver"
+)
+
+// XKaytuAPIKeyIDHeader is set by the auth service on requests authenticated with an API key.
+const XKaytuAPIKeyIDHeader = "X-Kaytu-ApiKeyId"
+
+const maxSummaryBodySize = 64 * 1024
+
+// Middleware records the mutating requests handled by the service, and any request rejected for missing
+// authentication or permissions, to the audit log. Requests between services are not recorded.
+func Middleware(recorder *Recorder) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			req := ctx.Request()
+			if recorder == nil || req.Header.Get(httpserver.XKaytuUserRoleHeader) == string(authApi.InternalRole) {
+				return next(ctx)
+			}
+
+			mutating := isMutating(req.Method)
+			var summary string
+			if mutating {
+				summary = requestSummary(req)
+			}
+
+			err := next(ctx)
+
+			status := responseStatus(ctx, err)
+			event := Event{
+				Method:         req.Method,
+				Route:          ctx.Path(),
+				Path:           req.URL.Path,
+				RequestSummary: summary,
+				StatusCode:     status,
+				SourceIP:       ctx.RealIP(),
+			}
+			switch {
+			case status == http.StatusUnauthorized:
+				event.Type, event.Outcome = EventTypeSecurity, OutcomeUnauthenticated
+			case status == http.StatusForbidden:
+				event.Type, event.Outcome = EventTypeSecurity, OutcomeDenied
+			case !mutating:
+				return err
+			case status >= http.StatusBadRequest:
+				event.Type, event.Outcome = EventTypeAction, OutcomeFailure
+			default:
+				event.Type, event.Outcome = EventTypeAction, OutcomeSuccess
+			}
+			if err != nil {
+				event.Reason = errorMessage(err)
+			}
+			SetActor(&event, req.Header)
+			if names := ctx.ParamNames(); len(names) > 0 {
+				event.TargetIDs = make(map[string]string)
+				for i, value := range ctx.ParamValues() {
+					if i < len(names) {
+						event.TargetIDs[names[i]] = value
+					}
+				}
+			}
+
+			recorder.Record(event)
+			return err
+		}
+	}
+}
+
+// SetActor fills the actor of the event from the headers set by the auth service.
+func SetActor(event *Event, header http.Header) {
+	event.ActorID = header.Get(httpserver.XKaytuUserIDHeader)
+	event.Role = header.Get(httpserver.XKaytuUserRoleHeader)
+	event.WorkspaceID = header.Get(httpserver.XKaytuWorkspaceIDHeader)
+	event.APIKeyID = header.Get(XKaytuAPIKeyIDHeader)
+	switch {
+	case strings.HasPrefix(event.ActorID, ServiceAccountIDPrefix):
+		event.ActorType = ActorTypeServiceAccount
+	case event.APIKeyID != "":
+		event.ActorType = ActorTypeAPIKey
+	case event.ActorID != "":
+		event.ActorType = ActorTypeUser
+	default:
+		event.ActorType = ActorTypeAnonymous
+	}
+}
+
+func isMutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// requestSummary lists the query parameters and body fields of the request, leaving their values out
+// since they may hold credentials.
+func requestSummary(req *http.Request) string {
+	var parts []string
+	if query := req.URL.Query(); len(query) > 0 {
+		keys := make([]string, 0, len(query))
+		for k := range query {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		parts = append(parts, fmt.Sprintf("query: %s", strings.Join(keys, ", ")))
+	}
+
+	if req.Body != nil && strings.HasPrefix(req.Header.Get(echo.HeaderContentType), echo.MIMEApplicationJSON) {
+		body, err := io.ReadAll(io.LimitReader(req.Body, maxSummaryBodySize))
+		req.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), req.Body))
+		if err == nil {
+			var fields map[string]json.RawMessage
+			if json.Unmarshal(body, &fields) == nil && len(fields) > 0 {
+				keys := make([]string, 0, len(fields))
+				for k := range fields {
+					keys = append(keys, k)
+				}
+				sort.Strings(keys)
+				parts = append(parts, fmt.Sprintf("body: %s", strings.Join(keys, ", ")))
+			}
+		}
+	}
+	return strings.Join(parts, "; ")
+}
+
+func responseStatus(ctx echo.Context, err error) int {
+	if err == nil {
+		return ctx.Response().Status
+	}
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.Code
+	}
+	return http.StatusInternalServerError
+}
+
+func errorMessage(err error) string {
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) {
+		return fmt.Sprintf("%v", httpErr.Message)
+	}
+	return err.Error()
+}
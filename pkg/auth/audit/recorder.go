@@ -0,0 +1,126 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	authApi "github.com/opengovern/og-util/pkg/api"
+	"github.com/opengovern/og-util/pkg/httpclient"
+	"github.com/opengovern/og-util/pkg/httpserver"
+	"go.uber.org/zap"
+)
+
+const (
+	queueSize   = 10000
+	batchSize   = 100
+	flushPeriod = 2 * time.Second
+)
+
+// Sink stores a batch of audit events.
+type Sink func(ctx context.Context, events []Event) error
+
+// ServiceSink sends the events to the audit log of the auth service at authBaseURL.
+func ServiceSink(authBaseURL string) Sink {
+	url := fmt.Sprintf("%s/api/v1/audit/events", strings.TrimSuffix(authBaseURL, "/"))
+	return func(ctx context.Context, events []Event) error {
+		payload, err := json.Marshal(CreateEventsRequest{Events: events})
+		if err != nil {
+			return fmt.Errorf("json marshal: %w", err)
+		}
+		headers := map[string]string{
+			httpserver.XKaytuUserRoleHeader: string(authApi.InternalRole),
+		}
+		_, err = httpclient.DoRequest(ctx, http.MethodPost, url, headers, payload, nil)
+		return err
+	}
+}
+
+// Recorder queues the audit events of a service and hands them to the sink in batches, so recording
+// does not slow down the requests.
+type Recorder struct {
+	logger  *zap.Logger
+	service string
+	sink    Sink
+	events  chan Event
+}
+
+func NewRecorder(ctx context.Context, logger *zap.Logger, service string, sink Sink) *Recorder {
+	r := &Recorder{
+		logger:  logger.Named("audit"),
+		service: service,
+		sink:    sink,
+		events:  make(chan Event, queueSize),
+	}
+	go r.run(ctx)
+	return r
+}
+
+// NewServiceRecorder returns a recorder sending to the auth service at authBaseURL, or nil, which records
+// nothing, if the address is not configured.
+func NewServiceRecorder(ctx context.Context, logger *zap.Logger, service string, authBaseURL string) *Recorder {
+	if authBaseURL == "" {
+		logger.Warn("auth service address is not set, audit events will not be recorded", zap.String("service", service))
+		return nil
+	}
+	return NewRecorder(ctx, logger, service, ServiceSink(authBaseURL))
+}
+
+func (r *Recorder) Record(event Event) {
+	if r == nil {
+		return
+	}
+	if event.Service == "" {
+		event.Service = r.service
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	select {
+	case r.events <- event:
+	default:
+		r.logger.Error("audit event queue is full, dropping event",
+			zap.String("method", event.Method),
+			zap.String("path", event.Path),
+			zap.String("actorId", event.ActorID))
+	}
+}
+
+func (r *Recorder) run(ctx context.Context) {
+	ticker := time.NewTicker(flushPeriod)
+	defer ticker.Stop()
+
+	var batch []Event
+	flush := func(ctx context.Context) {
+		if len(batch) == 0 {
+			return
+		}
+		if err := r.sink(ctx, batch); err != nil {
+			r.logger.Error("failed to store audit events", zap.Int("count", len(batch)), zap.Error(err))
+			// keep them for the next flush, unless the sink has been failing for long
+			if len(batch) < queueSize {
+				return
+			}
+		}
+		batch = nil
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			flush(context.Background())
+			return
+		case event := <-r.events:
+			batch = append(batch, event)
+			if len(batch) >= batchSize {
+				flush(ctx)
+			}
+		case <-ticker.C:
+			flush(ctx)
+		}
+	}
+}
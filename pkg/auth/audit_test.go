@@ -0,0 +1,141 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	envoycore "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	envoyauth "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
+	"github.com/jackc/pgtype"
+	"github.com/opengovern/opengovernance/pkg/auth/db"
+	"github.com/stretchr/testify/assert"
+)
+
+var testAuditLogKey = []byte("audit-log-key")
+
+func testAuditEntries(t *testing.T, prevHash string, firstID uint64, n int) []db.AuditEntry {
+	entries := make([]db.AuditEntry, 0, n)
+	for i := 0; i < n; i++ {
+		entry := db.AuditEntry{
+			ID:         firstID + uint64(i),
+			Type:       "security",
+			Time:       time.Date(2024, 3, 1, 12, 0, i, 123456789, time.UTC),
+			Service:    "auth",
+			ActorID:    "alice",
+			Method:     "GET",
+			Path:       "/api/v1/users",
+			Outcome:    "denied",
+			StatusCode: 403,
+		}
+		entry.TargetIDs.Status = pgtype.Null
+		entries = append(entries, entry)
+	}
+	assert.NoError(t, db.ChainAuditEntries(testAuditLogKey, prevHash, entries))
+	return entries
+}
+
+func TestComputeHash(t *testing.T) {
+	entry := testAuditEntries(t, "", 1, 1)[0]
+	hash, err := entry.ComputeHash(testAuditLogKey)
+	assert.NoError(t, err)
+	assert.Equal(t, entry.Hash, hash)
+	assert.Len(t, hash, 64)
+
+	otherKey, err := entry.ComputeHash([]byte("other-key"))
+	assert.NoError(t, err)
+	assert.NotEqual(t, hash, otherKey, "the hash depends on the key")
+
+	modified := entry
+	modified.Outcome = "allowed"
+	modifiedHash, err := modified.ComputeHash(testAuditLogKey)
+	assert.NoError(t, err)
+	assert.NotEqual(t, hash, modifiedHash)
+
+	withTargets := entry
+	assert.NoError(t, withTargets.TargetIDs.Set([]byte(`{"user": "bob"}`)))
+	targetsHash, err := withTargets.ComputeHash(testAuditLogKey)
+	assert.NoError(t, err)
+	assert.NotEqual(t, hash, targetsHash)
+}
+
+func TestChainAuditEntries(t *testing.T) {
+	entries := testAuditEntries(t, "start", 1, 3)
+	assert.Equal(t, "start", entries[0].PrevHash)
+	assert.Equal(t, entries[0].Hash, entries[1].PrevHash)
+	assert.Equal(t, entries[1].Hash, entries[2].PrevHash)
+	// the time is stored with the precision postgres keeps
+	assert.Equal(t, 123456000, entries[0].Time.Nanosecond())
+}
+
+func TestVerifyAuditChain(t *testing.T) {
+	listAfter := func(entries []db.AuditEntry) func(id uint64, limit int) ([]db.AuditEntry, error) {
+		return func(id uint64, limit int) ([]db.AuditEntry, error) {
+			var res []db.AuditEntry
+			for _, entry := range entries {
+				if entry.ID > id && len(res) < limit {
+					res = append(res, entry)
+				}
+			}
+			return res, nil
+		}
+	}
+
+	entries := testAuditEntries(t, "", 1, 5)
+	res, err := verifyAuditChain(testAuditLogKey, nil, listAfter(entries))
+	assert.NoError(t, err)
+	assert.True(t, res.Valid)
+	assert.Equal(t, int64(5), res.CheckedEntries)
+
+	checkpoint := &db.AuditLogCheckpoint{ID: entries[1].ID, Hash: entries[1].Hash}
+	checkpoint.MAC = checkpoint.ComputeMAC(testAuditLogKey)
+	res, err = verifyAuditChain(testAuditLogKey, checkpoint, listAfter(entries[2:]))
+	assert.NoError(t, err)
+	assert.True(t, res.Valid, "the retention removed the start of the log")
+	assert.Equal(t, int64(3), res.CheckedEntries)
+
+	invalid := func(name string, key []byte, checkpoint *db.AuditLogCheckpoint, entries []db.AuditEntry, wantID uint64) {
+		res, err := verifyAuditChain(key, checkpoint, listAfter(entries))
+		assert.NoError(t, err, name)
+		assert.False(t, res.Valid, name)
+		assert.NotEmpty(t, res.Reason, name)
+		if wantID == 0 {
+			assert.Nil(t, res.FirstInvalidID, name)
+		} else if assert.NotNil(t, res.FirstInvalidID, name) {
+			assert.Equal(t, wantID, *res.FirstInvalidID, name)
+		}
+	}
+
+	modified := append([]db.AuditEntry{}, entries...)
+	modified[2].ActorID = "mallory"
+	invalid("modified", testAuditLogKey, nil, modified, 3)
+
+	rehashed := append([]db.AuditEntry{}, entries...)
+	rehashed[2].ActorID = "mallory"
+	rehashed[2].Hash, err = rehashed[2].ComputeHash([]byte("guessed-key"))
+	assert.NoError(t, err)
+	invalid("rehashed without the key", testAuditLogKey, nil, rehashed, 3)
+
+	invalid("wrong key", []byte("other-key"), nil, entries, 1)
+	invalid("start removed", testAuditLogKey, nil, entries[2:], 3)
+	invalid("middle removed", testAuditLogKey, nil, append(append([]db.AuditEntry{}, entries[:2]...), entries[3:]...), 4)
+
+	forged := &db.AuditLogCheckpoint{ID: entries[2].ID, Hash: entries[3].PrevHash, MAC: checkpoint.MAC}
+	invalid("checkpoint moved", testAuditLogKey, forged, entries[3:], 0)
+}
+
+func TestCheckSourceIP(t *testing.T) {
+	request := func(headers map[string]string) *envoyauth.CheckRequest {
+		return &envoyauth.CheckRequest{Attributes: &envoyauth.AttributeContext{
+			Source: &envoyauth.AttributeContext_Peer{Address: &envoycore.Address{
+				Address: &envoycore.Address_SocketAddress{SocketAddress: &envoycore.SocketAddress{Address: "10.0.0.5"}},
+			}},
+			Request: &envoyauth.AttributeContext_Request{Http: &envoyauth.AttributeContext_HttpRequest{Headers: headers}},
+		}}
+	}
+
+	assert.Equal(t, "203.0.113.7", checkSourceIP(request(map[string]string{"x-forwarded-for": "203.0.113.7"})))
+	assert.Equal(t, "203.0.113.7", checkSourceIP(request(map[string]string{"x-forwarded-for": "1.2.3.4, 203.0.113.7"})),
+		"addresses sent by the client are ignored")
+	assert.Equal(t, "10.0.0.5", checkSourceIP(request(map[string]string{"x-real-ip": "1.2.3.4"})))
+	assert.Equal(t, "10.0.0.5", checkSourceIP(request(nil)))
+}
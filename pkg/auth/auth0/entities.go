@@ -31,6 +31,8 @@ type Metadata struct {
 	MemberSince     *string              `json:"memberSince,omitempty"`
 	LastLogin       *string              `json:"userLastLogin,omitempty"`
 	ConnectionIDs   map[string][]string  `json:"connectionIDs"`
+	// RoleScopes holds the scope of the role binding in each workspace, besides the connections in ConnectionIDs
+	RoleScopes map[string]api.RoleBindingScope `json:"roleScopes,omitempty"`
 }
 
 type User struct {
@@ -11,6 +11,8 @@ import (
 	"time"
 )
 
+var ErrUserDisabled = errors.New("user disabled")
+
 type Service struct {
 	domain       string
 	clientID     string
@@ -109,7 +111,7 @@ func (a *Service) GetOrCreateUser(userID, email string) (*User, error) {
 	}
 
 	if user.Disabled {
-		return nil, errors.New("user disabled")
+		return nil, ErrUserDisabled
 	}
 
 	resp, err := DbUserToApi(user)
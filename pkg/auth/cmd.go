@@ -15,10 +15,14 @@ import (
 	"os"
 	"strconv"
 
+	"github.com/opengovern/opengovernance/pkg/auth/audit"
 	"github.com/opengovern/opengovernance/pkg/auth/auth0"
 	"github.com/opengovern/opengovernance/pkg/auth/db"
 
 	client2 "github.com/opengovern/opengovernance/pkg/compliance/client"
+	client6 "github.com/opengovern/opengovernance/pkg/inventory/client"
+	metadataClient "github.com/opengovern/opengovernance/pkg/metadata/client"
+	client5 "github.com/opengovern/opengovernance/pkg/onboard/client"
 	"github.com/opengovern/opengovernance/pkg/workspace/client"
 	client3 "github.com/opengovern/opengovernance/services/integration/client"
 
@@ -54,11 +58,16 @@ var (
 	kaytuPublicKeyStr  = os.Getenv("KAYTU_PUBLIC_KEY")
 	kaytuPrivateKeyStr = os.Getenv("KAYTU_PRIVATE_KEY")
 
+	// auditLogHMACKey signs the audit log hash chain, it is kept out of the database the log is stored in
+	auditLogHMACKey = os.Getenv("AUDIT_LOG_HMAC_KEY")
+
 	workspaceBaseUrl   = os.Getenv("WORKSPACE_BASE_URL")
 	complianceBaseUrl  = os.Getenv("COMPLIANCE_BASE_URL")
 	integrationBaseUrl = os.Getenv("INTEGRATION_BASE_URL")
 	describeBaseUrl    = os.Getenv("DESCRIBE_BASE_URL")
 	metadataBaseUrl    = os.Getenv("METADATA_BASE_URL")
+	onboardBaseUrl     = os.Getenv("ONBOARD_BASE_URL")
+	inventoryBaseUrl   = os.Getenv("INVENTORY_BASE_URL")
 )
 
 func Command() *cobra.Command {
@@ -109,6 +118,11 @@ func start(ctx context.Context) error {
 		return fmt.Errorf("open id connect dex verifier: %w", err)
 	}
 
+	dexClient, err := newDexClient(dexGrpcAddress)
+	if err != nil {
+		return fmt.Errorf("dex client: %w", err)
+	}
+
 	logger.Info("Instantiated a new Open ID Connect verifier")
 	//m := email.NewSendGridClient(mailApiKey, mailSender, mailSenderName, logger)
 
@@ -116,12 +130,18 @@ func start(ctx context.Context) error {
 	complianceClient := client2.NewComplianceClient(complianceBaseUrl)
 	integrationClient := client3.NewIntegrationServiceClient(integrationBaseUrl)
 	schedulerClient := client4.NewSchedulerServiceClient(describeBaseUrl)
+	onboardClient := client5.NewOnboardServiceClient(onboardBaseUrl)
+	inventoryClient := client6.NewInventoryServiceClient(inventoryBaseUrl)
 
 	inviteTTL, err := strconv.ParseInt(auth0InviteTTL, 10, 64)
 	if err != nil {
 		return fmt.Errorf("failed to parse auth0InviteTTL=%s due to %v", auth0InviteTTL, err)
 	}
 
+	if auditLogHMACKey == "" {
+		return fmt.Errorf("AUDIT_LOG_HMAC_KEY is required to sign the audit log")
+	}
+
 	// setup postgres connection
 	cfg := postgres.Config{
 		Host:    conf.PostgreSQL.Host,
@@ -276,17 +296,25 @@ func start(ctx context.Context) error {
 		verifierNative:          verifierNative,
 		verifierPennywiseNative: verifierPennywiseNative,
 		dexVerifier:             dexVerifier,
+		dexClient:               dexClient,
+		dexPasswordLoginPath:    dexPasswordLoginPath(dexAuthDomain),
 		logger:                  logger,
 		workspaceClient:         workspaceClient,
 		complianceClient:        complianceClient,
 		integrationClient:       integrationClient,
+		onboardClient:           onboardClient,
+		inventoryClient:         inventoryClient,
+		metadataClient:          metadataClient.NewMetadataServiceClient(metadataBaseUrl),
 		db:                      adb,
 		auth0Service:            auth0Service,
 		updateLoginUserList:     nil,
 		updateLogin:             make(chan User, 100000),
+		auditLogKey:             []byte(auditLogHMACKey),
 	}
+	authServer.auditRecorder = audit.NewRecorder(ctx, logger, "auth", authServer.storeAuditEvents)
 	go authServer.WorkspaceMapUpdater()
 	go authServer.UpdateLastLoginLoop()
+	go authServer.AuditLogRetentionLoop()
 
 	errors := make(chan error, 1)
 	go func() {
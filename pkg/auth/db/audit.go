@@ -0,0 +1,292 @@
+package db
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgtype"
+	"gorm.io/gorm"
+)
+
+// auditLogLockID is the advisory lock held while appending to the audit log so that replicas of the
+// auth service extend the hash chain one at a time.
+const auditLogLockID = 7300433
+
+const (
+	auditLogRetentionKey  = "audit_log_retention_days"
+	auditLogCheckpointKey = "audit_log_retention_checkpoint"
+)
+
+type AuditEntry struct {
+	ID             uint64    `gorm:"primaryKey"`
+	Type           string    `gorm:"index"`
+	Time           time.Time `gorm:"index"`
+	Service        string
+	ActorID        string `gorm:"index"`
+	ActorType      string
+	APIKeyID       string
+	Role           string
+	WorkspaceID    string
+	Method         string
+	Route          string
+	Path           string
+	TargetIDs      pgtype.JSONB
+	RequestSummary string
+	Outcome        string `gorm:"index"`
+	StatusCode     int
+	Reason         string
+	SourceIP       string
+
+	PrevHash string
+	Hash     string
+}
+
+// AuditLogCheckpoint is the last entry removed by the retention, the oldest remaining entry follows it. The
+// MAC keeps the checkpoint from being moved past entries removed by hand.
+type AuditLogCheckpoint struct {
+	ID   uint64 `json:"id"`
+	Hash string `json:"hash"`
+	MAC  string `json:"mac"`
+}
+
+func (c AuditLogCheckpoint) ComputeMAC(key []byte) string {
+	return auditLogMAC(key, []byte(fmt.Sprintf("%d:%s", c.ID, c.Hash)))
+}
+
+type AuditEntryFilters struct {
+	Types      []string
+	Outcomes   []string
+	ActorIDs   []string
+	ActorTypes []string
+	Services   []string
+	Methods    []string
+	Route      string
+	TargetID   string
+	From       *time.Time
+	To         *time.Time
+}
+
+// ComputeHash returns the HMAC chaining the entry to the entry before it. It depends on what is stored and on
+// the key, which is kept out of the database so the chain can not be rebuilt by who can write to it.
+func (e AuditEntry) ComputeHash(key []byte) (string, error) {
+	var targetIDs map[string]string
+	if e.TargetIDs.Status == pgtype.Present {
+		if err := json.Unmarshal(e.TargetIDs.Bytes, &targetIDs); err != nil {
+			return "", err
+		}
+	}
+
+	content, err := json.Marshal([]any{
+		e.PrevHash, e.Type, e.Time.UTC().Format(time.RFC3339Nano), e.Service, e.ActorID, e.ActorType, e.APIKeyID,
+		e.Role, e.WorkspaceID, e.Method, e.Route, e.Path, targetIDs, e.RequestSummary, e.Outcome, e.StatusCode,
+		e.Reason, e.SourceIP,
+	})
+	if err != nil {
+		return "", err
+	}
+	return auditLogMAC(key, content), nil
+}
+
+func auditLogMAC(key, content []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(content)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// ChainAuditEntries sets the hashes of the entries, chaining the first one to prevHash.
+func ChainAuditEntries(key []byte, prevHash string, entries []AuditEntry) error {
+	for i := range entries {
+		// postgres keeps microseconds, the hash has to match what is read back
+		entries[i].Time = entries[i].Time.UTC().Truncate(time.Microsecond)
+		entries[i].PrevHash = prevHash
+		hash, err := entries[i].ComputeHash(key)
+		if err != nil {
+			return err
+		}
+		entries[i].Hash = hash
+		prevHash = hash
+	}
+	return nil
+}
+
+// AppendAuditEntries adds the entries to the end of the audit log, setting their hashes.
+func (db Database) AppendAuditEntries(key []byte, entries []AuditEntry) error {
+	return db.Orm.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", auditLogLockID).Error; err != nil {
+			return err
+		}
+
+		var last AuditEntry
+		res := tx.Model(&AuditEntry{}).Order("id DESC").Limit(1).Find(&last)
+		if res.Error != nil {
+			return res.Error
+		}
+		prevHash := ""
+		if res.RowsAffected > 0 {
+			prevHash = last.Hash
+		}
+
+		if err := ChainAuditEntries(key, prevHash, entries); err != nil {
+			return err
+		}
+		return tx.Create(&entries).Error
+	})
+}
+
+// ListAuditEntries returns the entries matching the filters, newest first, older than the cursor if given.
+func (db Database) ListAuditEntries(filters AuditEntryFilters, cursor *uint64, limit int) ([]AuditEntry, error) {
+	tx := db.Orm.Model(&AuditEntry{})
+	if len(filters.Types) > 0 {
+		tx = tx.Where("type IN ?", filters.Types)
+	}
+	if len(filters.Outcomes) > 0 {
+		tx = tx.Where("outcome IN ?", filters.Outcomes)
+	}
+	if len(filters.ActorIDs) > 0 {
+		tx = tx.Where("actor_id IN ?", filters.ActorIDs)
+	}
+	if len(filters.ActorTypes) > 0 {
+		tx = tx.Where("actor_type IN ?", filters.ActorTypes)
+	}
+	if len(filters.Services) > 0 {
+		tx = tx.Where("service IN ?", filters.Services)
+	}
+	if len(filters.Methods) > 0 {
+		tx = tx.Where("method IN ?", filters.Methods)
+	}
+	if filters.Route != "" {
+		tx = tx.Where("route LIKE ?", filters.Route+"%")
+	}
+	if filters.TargetID != "" {
+		tx = tx.Where("EXISTS (SELECT 1 FROM jsonb_each_text(target_ids) WHERE value = ?)", filters.TargetID)
+	}
+	if filters.From != nil {
+		tx = tx.Where("time >= ?", *filters.From)
+	}
+	if filters.To != nil {
+		tx = tx.Where("time <= ?", *filters.To)
+	}
+	if cursor != nil {
+		tx = tx.Where("id < ?", *cursor)
+	}
+
+	var entries []AuditEntry
+	tx = tx.Order("id DESC").Limit(limit).Find(&entries)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return entries, nil
+}
+
+// ListAuditEntriesAfter returns the entries following the given id in chain order.
+func (db Database) ListAuditEntriesAfter(id uint64, limit int) ([]AuditEntry, error) {
+	var entries []AuditEntry
+	tx := db.Orm.Model(&AuditEntry{}).Where("id > ?", id).Order("id ASC").Limit(limit).Find(&entries)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return entries, nil
+}
+
+// DeleteAuditEntriesBefore removes the entries older than the given time from the start of the chain.
+// Entries are not appended in time order, so the removal stops at the first entry that is not older than the
+// given time to keep the chain contiguous, the expired entries after it are removed once it expires. The last
+// removed entry is stored as the checkpoint the oldest remaining entry is verified against.
+func (db Database) DeleteAuditEntriesBefore(key []byte, t time.Time) (int64, error) {
+	var deleted int64
+	err := db.Orm.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", auditLogLockID).Error; err != nil {
+			return err
+		}
+
+		var kept AuditEntry
+		res := tx.Model(&AuditEntry{}).Where("time >= ?", t).Order("id ASC").Limit(1).Find(&kept)
+		if res.Error != nil {
+			return res.Error
+		}
+		expired := tx.Model(&AuditEntry{}).Where("time < ?", t)
+		if res.RowsAffected > 0 {
+			expired = expired.Where("id < ?", kept.ID)
+		}
+		var last AuditEntry
+		res = expired.Order("id DESC").Limit(1).Find(&last)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return nil
+		}
+
+		res = tx.Where("id <= ? AND time < ?", last.ID, t).Delete(&AuditEntry{})
+		if res.Error != nil {
+			return res.Error
+		}
+		deleted = res.RowsAffected
+
+		checkpoint := AuditLogCheckpoint{ID: last.ID, Hash: last.Hash}
+		checkpoint.MAC = checkpoint.ComputeMAC(key)
+		value, err := json.Marshal(checkpoint)
+		if err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("key = ?", auditLogCheckpointKey).Delete(&Configuration{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&Configuration{Key: auditLogCheckpointKey, Value: string(value)}).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	return deleted, nil
+}
+
+// GetAuditLogCheckpoint returns the last entry removed by the retention, nil if none has been removed.
+func (db Database) GetAuditLogCheckpoint() (*AuditLogCheckpoint, error) {
+	var c Configuration
+	tx := db.Orm.Model(&Configuration{}).Where("key = ?", auditLogCheckpointKey).Limit(1).Find(&c)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return nil, nil
+	}
+	var checkpoint AuditLogCheckpoint
+	if err := json.Unmarshal([]byte(c.Value), &checkpoint); err != nil {
+		return nil, err
+	}
+	return &checkpoint, nil
+}
+
+// GetAuditLogRetentionDays returns the configured retention, 0 if it is not set.
+func (db Database) GetAuditLogRetentionDays() (int, error) {
+	var c Configuration
+	tx := db.Orm.Model(&Configuration{}).Where("key = ?", auditLogRetentionKey).Limit(1).Find(&c)
+	if tx.Error != nil {
+		return 0, tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return 0, nil
+	}
+	var days int
+	if err := json.Unmarshal([]byte(c.Value), &days); err != nil {
+		return 0, err
+	}
+	return days, nil
+}
+
+func (db Database) SetAuditLogRetentionDays(days int) error {
+	value, err := json.Marshal(days)
+	if err != nil {
+		return err
+	}
+	return db.Orm.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("key = ?", auditLogRetentionKey).Delete(&Configuration{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&Configuration{Key: auditLogRetentionKey, Value: string(value)}).Error
+	})
+}
@@ -20,6 +20,15 @@ func (db Database) Initialize() error {
 		&WorkspaceMap{},
 		&User{},
 		&Configuration{},
+		&AuditEntry{},
+		&ScimGroup{},
+		&ScimGroupRole{},
+		&OidcRoleRule{},
+		&ServiceAccount{},
+		&UserSession{},
+		&RevokedToken{},
+		&UserSessionRevocation{},
+		&LoginLockout{},
 	)
 	if err != nil {
 		return err
@@ -89,6 +98,7 @@ func (db Database) CountApiKeys(workspaceID string) (int64, error) {
 	tx := db.Orm.Model(&ApiKey{}).
 		Where("workspace_id", workspaceID).
 		Where("revoked", "false").
+		Where("expires_at IS NULL OR expires_at > ?", time.Now()).
 		Count(&s)
 	if tx.Error != nil {
 		return 0, tx.Error
@@ -121,6 +131,39 @@ func (db Database) GetApiKey(workspaceID string, id uint) (*ApiKey, error) {
 	return &s, nil
 }
 
+func (db Database) GetApiKeyForUser(userID string, id uint) (*ApiKey, error) {
+	var s ApiKey
+	tx := db.Orm.Model(&ApiKey{}).
+		Where("creator_user_id", userID).
+		Where("id", id).
+		Where("revoked", "false").
+		First(&s)
+	if tx.Error != nil {
+		if errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, tx.Error
+	}
+	return &s, nil
+}
+
+// GetApiKeyByHash returns the non-revoked key whose current secret, or previous secret within its grace period,
+// has the given hash.
+func (db Database) GetApiKeyByHash(keyHash string) (*ApiKey, error) {
+	var s ApiKey
+	tx := db.Orm.Model(&ApiKey{}).
+		Where("revoked", "false").
+		Where("key_hash = ? OR (previous_key_hash = ? AND previous_key_expires_at > ?)", keyHash, keyHash, time.Now()).
+		First(&s)
+	if tx.Error != nil {
+		if errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, tx.Error
+	}
+	return &s, nil
+}
+
 func (db Database) AddApiKey(key *ApiKey) error {
 	tx := db.Orm.Create(key)
 
@@ -175,6 +218,32 @@ func (db Database) UpdateAPIKeyRole(workspaceID string, id uint, role api.Role)
 	return nil
 }
 
+func (db Database) UpdateAPIKeyLastUsed(id uint, lastUsedAt time.Time) error {
+	tx := db.Orm.Model(&ApiKey{}).
+		Where("id", id).
+		Update("last_used_at", lastUsedAt)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	return nil
+}
+
+func (db Database) RotateAPIKey(userID string, id uint, maskedKey, keyHash, previousKeyHash string, previousKeyExpiresAt *time.Time) error {
+	tx := db.Orm.Model(&ApiKey{}).
+		Where("creator_user_id", userID).
+		Where("id", id).
+		Updates(map[string]any{
+			"masked_key":              maskedKey,
+			"key_hash":                keyHash,
+			"previous_key_hash":       previousKeyHash,
+			"previous_key_expires_at": previousKeyExpiresAt,
+		})
+	if tx.Error != nil {
+		return tx.Error
+	}
+	return nil
+}
+
 func (db Database) UpsertWorkspaceMap(workspaceID string, name string) error {
 	tx := db.Orm.Model(&WorkspaceMap{}).Clauses(
 		clause.OnConflict{
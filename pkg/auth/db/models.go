@@ -35,6 +35,41 @@ type ApiKey struct {
 	Revoked       bool
 	MaskedKey     string
 	KeyHash       string
+	Scopes        pq.StringArray `gorm:"type:text[]"`
+	ExpiresAt     *time.Time
+	LastUsedAt    *time.Time
+
+	ConnectionIDs         pq.StringArray `gorm:"type:text[]"`
+	ConnectionGroups      pq.StringArray `gorm:"type:text[]"`
+	ResourceCollectionIDs pq.StringArray `gorm:"type:text[]"`
+	BenchmarkIDs          pq.StringArray `gorm:"type:text[]"`
+
+	// PreviousKeyHash keeps the secret replaced by the last rotation valid until PreviousKeyExpiresAt
+	PreviousKeyHash      string
+	PreviousKeyExpiresAt *time.Time
+}
+
+// ServiceAccount is a principal owned by the workspace, its API keys use the service-account user ID as
+// their creator so they outlive the users managing them.
+type ServiceAccount struct {
+	gorm.Model
+	Name          string `gorm:"index"`
+	Description   string
+	OwnerTeam     string
+	Role          api.Role
+	Disabled      bool
+	CreatorUserID string
+
+	ConnectionIDs         pq.StringArray `gorm:"type:text[]"`
+	ConnectionGroups      pq.StringArray `gorm:"type:text[]"`
+	ResourceCollectionIDs pq.StringArray `gorm:"type:text[]"`
+	BenchmarkIDs          pq.StringArray `gorm:"type:text[]"`
+
+	// ClientSecretHash is empty while the client credentials grant is disabled, tokens issued before
+	// ClientSecretCreatedAt are rejected
+	ClientSecretHash      string
+	MaskedClientSecret    string
+	ClientSecretCreatedAt *time.Time
 }
 
 type User struct {
@@ -69,6 +104,73 @@ type User struct {
 	Disabled              bool
 }
 
+// ScimGroup is a group pushed by the identity provider through SCIM.
+type ScimGroup struct {
+	ID          string `gorm:"primaryKey"`
+	DisplayName string `gorm:"uniqueIndex"`
+	ExternalID  string
+	Members     pq.StringArray `gorm:"type:text[]"` // UserUuid of the members
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// ScimGroupRole maps the SCIM group with the given display name to a role in the workspace.
+type ScimGroupRole struct {
+	GroupName string `gorm:"primaryKey"`
+	Role      api.Role
+}
+
+// OidcRoleRule gives a role, and optionally a scope, to the users whose ID token matches it.
+type OidcRoleRule struct {
+	ID        uint `gorm:"primaryKey"`
+	Priority  int  // position of the rule in the mapping
+	ClaimType string
+	Claim     string
+	Value     string
+	Role      api.Role
+
+	ConnectionGroups      pq.StringArray `gorm:"type:text[]"`
+	ResourceCollectionIDs pq.StringArray `gorm:"type:text[]"`
+	BenchmarkIDs          pq.StringArray `gorm:"type:text[]"`
+}
+
+// UserSession is a token seen by the auth service, tracked from its first use until it expires.
+type UserSession struct {
+	TokenID     string `gorm:"primaryKey"`
+	UserID      string `gorm:"index"`
+	Email       string
+	IssuedAt    *time.Time
+	ExpiresAt   *time.Time `gorm:"index"`
+	FirstSeenAt time.Time
+	LastSeenAt  time.Time
+	SourceIP    string
+	UserAgent   string
+}
+
+// RevokedToken is a revoked session, kept until the token expires.
+type RevokedToken struct {
+	TokenID   string `gorm:"primaryKey"`
+	UserID    string
+	ExpiresAt *time.Time
+	RevokedAt time.Time
+	RevokedBy string
+}
+
+// UserSessionRevocation rejects the tokens of the user issued before RevokedAt.
+type UserSessionRevocation struct {
+	UserID    string `gorm:"primaryKey"`
+	RevokedAt time.Time
+	RevokedBy string
+}
+
+// LoginLockout counts the failed local password logins of a user.
+type LoginLockout struct {
+	UserID         string `gorm:"primaryKey"`
+	FailedAttempts int
+	LastFailedAt   time.Time
+	LockedUntil    *time.Time
+}
+
 type WorkspaceMap struct {
 	ID   string `gorm:"primaryKey"`
 	Name string `gorm:"index"`
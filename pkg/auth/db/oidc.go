@@ -0,0 +1,64 @@
+package db
+
+import (
+	"encoding/json"
+
+	"github.com/opengovern/og-util/pkg/api"
+	"gorm.io/gorm"
+)
+
+const oidcRoleMappingSettingsKey = "oidc_role_mapping_settings"
+
+// OidcRoleMappingSettings decides what happens to the users no rule matches, they are denied unless a
+// default role is set.
+type OidcRoleMappingSettings struct {
+	DefaultRole   api.Role `json:"defaultRole"`
+	DenyUnmatched bool     `json:"denyUnmatched"`
+}
+
+func (db Database) ListOidcRoleRules() ([]OidcRoleRule, error) {
+	var rules []OidcRoleRule
+	tx := db.Orm.Model(&OidcRoleRule{}).Order("priority, id").Find(&rules)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return rules, nil
+}
+
+// SetOidcRoleRules replaces the rules and their settings.
+func (db Database) SetOidcRoleRules(rules []OidcRoleRule, settings OidcRoleMappingSettings) error {
+	value, err := json.Marshal(settings)
+	if err != nil {
+		return err
+	}
+	return db.Orm.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&OidcRoleRule{}).Error; err != nil {
+			return err
+		}
+		if len(rules) > 0 {
+			if err := tx.Create(&rules).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Unscoped().Where("key = ?", oidcRoleMappingSettingsKey).Delete(&Configuration{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&Configuration{Key: oidcRoleMappingSettingsKey, Value: string(value)}).Error
+	})
+}
+
+func (db Database) GetOidcRoleMappingSettings() (OidcRoleMappingSettings, error) {
+	var settings OidcRoleMappingSettings
+	var c Configuration
+	tx := db.Orm.Model(&Configuration{}).Where("key = ?", oidcRoleMappingSettingsKey).Limit(1).Find(&c)
+	if tx.Error != nil {
+		return settings, tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return settings, nil
+	}
+	if err := json.Unmarshal([]byte(c.Value), &settings); err != nil {
+		return settings, err
+	}
+	return settings, nil
+}
@@ -0,0 +1,148 @@
+package db
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const scimTokenHashKey = "scim_token_hash"
+
+func (db Database) ListUsers() ([]User, error) {
+	var users []User
+	tx := db.Orm.Model(&User{}).Order("id").Find(&users)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return users, nil
+}
+
+// GetUserByUuid returns the user with the given UserUuid, nil if there is none.
+func (db Database) GetUserByUuid(id uuid.UUID) (*User, error) {
+	var user User
+	tx := db.Orm.Model(&User{}).Where("user_uuid = ?", id).Limit(1).Find(&user)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return nil, nil
+	}
+	return &user, nil
+}
+
+func (db Database) UpdateUser(user *User) error {
+	return db.Orm.Save(user).Error
+}
+
+func (db Database) RevokeAllUserAPIKeys(userID string) error {
+	tx := db.Orm.Model(&ApiKey{}).
+		Where("creator_user_id", userID).
+		Updates(ApiKey{Revoked: true})
+	return tx.Error
+}
+
+func (db Database) ListScimGroups() ([]ScimGroup, error) {
+	var groups []ScimGroup
+	tx := db.Orm.Model(&ScimGroup{}).Order("id").Find(&groups)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return groups, nil
+}
+
+// GetScimGroup returns the group with the given id, nil if there is none.
+func (db Database) GetScimGroup(id string) (*ScimGroup, error) {
+	var group ScimGroup
+	tx := db.Orm.Model(&ScimGroup{}).Where("id = ?", id).Limit(1).Find(&group)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return nil, nil
+	}
+	return &group, nil
+}
+
+// GetScimGroupByName returns the group with the given display name, nil if there is none.
+func (db Database) GetScimGroupByName(displayName string) (*ScimGroup, error) {
+	var group ScimGroup
+	tx := db.Orm.Model(&ScimGroup{}).Where("display_name = ?", displayName).Limit(1).Find(&group)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return nil, nil
+	}
+	return &group, nil
+}
+
+func (db Database) ListScimGroupsByMember(userUuid string) ([]ScimGroup, error) {
+	var groups []ScimGroup
+	tx := db.Orm.Model(&ScimGroup{}).Where("? = ANY(members)", userUuid).Find(&groups)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return groups, nil
+}
+
+func (db Database) CreateScimGroup(group *ScimGroup) error {
+	return db.Orm.Create(group).Error
+}
+
+func (db Database) UpdateScimGroup(group *ScimGroup) error {
+	return db.Orm.Save(group).Error
+}
+
+func (db Database) DeleteScimGroup(id string) error {
+	return db.Orm.Where("id = ?", id).Delete(&ScimGroup{}).Error
+}
+
+// RemoveScimGroupMember removes the user from all the groups.
+func (db Database) RemoveScimGroupMember(userUuid string) error {
+	return db.Orm.Model(&ScimGroup{}).
+		Where("? = ANY(members)", userUuid).
+		Update("members", gorm.Expr("array_remove(members, ?)", userUuid)).Error
+}
+
+func (db Database) ListScimGroupRoles() ([]ScimGroupRole, error) {
+	var roles []ScimGroupRole
+	tx := db.Orm.Model(&ScimGroupRole{}).Order("group_name").Find(&roles)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return roles, nil
+}
+
+// SetScimGroupRoles replaces the group to role mapping.
+func (db Database) SetScimGroupRoles(roles []ScimGroupRole) error {
+	return db.Orm.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&ScimGroupRole{}).Error; err != nil {
+			return err
+		}
+		if len(roles) == 0 {
+			return nil
+		}
+		return tx.Create(&roles).Error
+	})
+}
+
+// GetScimTokenHash returns the hash of the SCIM token, empty if no token has been created.
+func (db Database) GetScimTokenHash() (string, error) {
+	var c Configuration
+	tx := db.Orm.Model(&Configuration{}).Where("key = ?", scimTokenHashKey).Limit(1).Find(&c)
+	if tx.Error != nil {
+		return "", tx.Error
+	}
+	return c.Value, nil
+}
+
+func (db Database) SetScimTokenHash(hash string) error {
+	return db.Orm.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("key = ?", scimTokenHashKey).Delete(&Configuration{}).Error; err != nil {
+			return err
+		}
+		if hash == "" {
+			return nil
+		}
+		return tx.Create(&Configuration{Key: scimTokenHashKey, Value: hash}).Error
+	})
+}
@@ -0,0 +1,70 @@
+package db
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func (db Database) ListServiceAccounts() ([]ServiceAccount, error) {
+	var s []ServiceAccount
+	tx := db.Orm.Model(&ServiceAccount{}).Order("id").Find(&s)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return s, nil
+}
+
+func (db Database) GetServiceAccount(id uint) (*ServiceAccount, error) {
+	var s ServiceAccount
+	tx := db.Orm.Model(&ServiceAccount{}).Where("id = ?", id).First(&s)
+	if tx.Error != nil {
+		if errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, tx.Error
+	}
+	return &s, nil
+}
+
+func (db Database) GetServiceAccountByName(name string) (*ServiceAccount, error) {
+	var s ServiceAccount
+	tx := db.Orm.Model(&ServiceAccount{}).Where("name = ?", name).First(&s)
+	if tx.Error != nil {
+		if errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, tx.Error
+	}
+	return &s, nil
+}
+
+func (db Database) CreateServiceAccount(sa *ServiceAccount) error {
+	return db.Orm.Create(sa).Error
+}
+
+func (db Database) UpdateServiceAccount(sa *ServiceAccount) error {
+	return db.Orm.Save(sa).Error
+}
+
+// DeleteServiceAccount deletes the service account and revokes the API keys of its user ID.
+func (db Database) DeleteServiceAccount(id uint, userID string) error {
+	return db.Orm.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&ApiKey{}).Where("creator_user_id", userID).Updates(ApiKey{Revoked: true}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&ServiceAccount{}, id).Error
+	})
+}
+
+func (db Database) SetServiceAccountClientSecret(id uint, secretHash, maskedSecret string, createdAt *time.Time) error {
+	tx := db.Orm.Model(&ServiceAccount{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"client_secret_hash":       secretHash,
+			"masked_client_secret":     maskedSecret,
+			"client_secret_created_at": createdAt,
+		})
+	return tx.Error
+}
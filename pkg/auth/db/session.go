@@ -0,0 +1,224 @@
+package db
+
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	sessionSettingsKey   = "session_settings"
+	sessionsRevokedAtKey = "sessions_revoked_at"
+
+	DefaultLockoutThreshold       = 5
+	DefaultLockoutDurationMinutes = 15
+)
+
+// SessionSettings configures the checks done on the tokens of the users.
+type SessionSettings struct {
+	MaxTokenAgeMinutes     int  `json:"maxTokenAgeMinutes"`
+	LockoutThreshold       int  `json:"lockoutThreshold"`
+	LockoutDurationMinutes int  `json:"lockoutDurationMinutes"`
+	RequireMFA             bool `json:"requireMfa"`
+}
+
+func (db Database) GetSessionSettings() (SessionSettings, error) {
+	settings := SessionSettings{
+		LockoutThreshold:       DefaultLockoutThreshold,
+		LockoutDurationMinutes: DefaultLockoutDurationMinutes,
+	}
+	var c Configuration
+	tx := db.Orm.Model(&Configuration{}).Where("key = ?", sessionSettingsKey).Limit(1).Find(&c)
+	if tx.Error != nil {
+		return settings, tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return settings, nil
+	}
+	if err := json.Unmarshal([]byte(c.Value), &settings); err != nil {
+		return settings, err
+	}
+	return settings, nil
+}
+
+func (db Database) SetSessionSettings(settings SessionSettings) error {
+	value, err := json.Marshal(settings)
+	if err != nil {
+		return err
+	}
+	return db.setConfiguration(sessionSettingsKey, string(value))
+}
+
+// GetSessionsRevokedAt returns the time the sessions of all the users were revoked at, nil if they never were.
+func (db Database) GetSessionsRevokedAt() (*time.Time, error) {
+	var c Configuration
+	tx := db.Orm.Model(&Configuration{}).Where("key = ?", sessionsRevokedAtKey).Limit(1).Find(&c)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, c.Value)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (db Database) SetSessionsRevokedAt(t time.Time) error {
+	return db.setConfiguration(sessionsRevokedAtKey, t.UTC().Format(time.RFC3339))
+}
+
+func (db Database) setConfiguration(key, value string) error {
+	return db.Orm.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("key = ?", key).Delete(&Configuration{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&Configuration{Key: key, Value: value}).Error
+	})
+}
+
+// TouchUserSession creates the session or updates the time it was last seen at.
+func (db Database) TouchUserSession(session UserSession) error {
+	tx := db.Orm.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "token_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"last_seen_at", "source_ip", "user_agent"}),
+	}).Create(&session)
+	return tx.Error
+}
+
+// ListActiveUserSessions returns the sessions that have not expired nor been revoked, of the user if userID is set.
+func (db Database) ListActiveUserSessions(userID string) ([]UserSession, error) {
+	var s []UserSession
+	tx := db.Orm.Model(&UserSession{}).
+		Where("expires_at IS NULL OR expires_at > ?", time.Now()).
+		Where("token_id NOT IN (?)", db.Orm.Model(&RevokedToken{}).Select("token_id")).
+		Where("NOT EXISTS (?)", db.Orm.Model(&UserSessionRevocation{}).
+			Select("1").
+			Where("user_session_revocations.user_id = user_sessions.user_id").
+			Where("user_session_revocations.revoked_at > user_sessions.issued_at"))
+	if userID != "" {
+		tx = tx.Where("user_id = ?", userID)
+	}
+	tx = tx.Order("last_seen_at DESC").Find(&s)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return s, nil
+}
+
+func (db Database) GetUserSession(tokenID string) (*UserSession, error) {
+	var s UserSession
+	tx := db.Orm.Model(&UserSession{}).Where("token_id = ?", tokenID).Limit(1).Find(&s)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return nil, nil
+	}
+	return &s, nil
+}
+
+func (db Database) RevokeToken(token RevokedToken) error {
+	return db.Orm.Clauses(clause.OnConflict{DoNothing: true}).Create(&token).Error
+}
+
+// ListRevokedTokens returns the revoked tokens that have not expired.
+func (db Database) ListRevokedTokens() ([]RevokedToken, error) {
+	var s []RevokedToken
+	tx := db.Orm.Model(&RevokedToken{}).
+		Where("expires_at IS NULL OR expires_at > ?", time.Now()).
+		Find(&s)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return s, nil
+}
+
+func (db Database) RevokeUserSessions(revocation UserSessionRevocation) error {
+	return db.Orm.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"revoked_at", "revoked_by"}),
+	}).Create(&revocation).Error
+}
+
+func (db Database) ListUserSessionRevocations() ([]UserSessionRevocation, error) {
+	var s []UserSessionRevocation
+	tx := db.Orm.Model(&UserSessionRevocation{}).Find(&s)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return s, nil
+}
+
+// DeleteExpiredSessions removes the sessions and revoked tokens that expired before the given time.
+func (db Database) DeleteExpiredSessions(before time.Time) error {
+	return db.Orm.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("expires_at < ?", before).Delete(&UserSession{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("expires_at < ?", before).Delete(&RevokedToken{}).Error
+	})
+}
+
+// RecordLoginFailure counts a failed login of the user and locks it for lockoutDuration once the
+// threshold is reached, returning the updated lockout.
+func (db Database) RecordLoginFailure(userID string, threshold int, lockoutDuration time.Duration) (*LoginLockout, error) {
+	var lockout LoginLockout
+	err := db.Orm.Transaction(func(tx *gorm.DB) error {
+		t := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("user_id = ?", userID).Limit(1).Find(&lockout)
+		if t.Error != nil {
+			return t.Error
+		}
+		now := time.Now()
+		if t.RowsAffected == 0 {
+			lockout = LoginLockout{UserID: userID}
+		}
+		// the attempts of an expired lockout do not count
+		if lockout.LockedUntil != nil && now.After(*lockout.LockedUntil) {
+			lockout.FailedAttempts = 0
+			lockout.LockedUntil = nil
+		}
+		lockout.FailedAttempts++
+		lockout.LastFailedAt = now
+		if threshold > 0 && lockout.FailedAttempts >= threshold {
+			lockedUntil := now.Add(lockoutDuration)
+			lockout.LockedUntil = &lockedUntil
+		}
+		return tx.Save(&lockout).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &lockout, nil
+}
+
+func (db Database) GetLoginLockout(userID string) (*LoginLockout, error) {
+	var s LoginLockout
+	tx := db.Orm.Model(&LoginLockout{}).Where("user_id = ?", userID).Limit(1).Find(&s)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return nil, nil
+	}
+	return &s, nil
+}
+
+// ListActiveLoginLockouts returns the users that are locked out.
+func (db Database) ListActiveLoginLockouts() ([]LoginLockout, error) {
+	var s []LoginLockout
+	tx := db.Orm.Model(&LoginLockout{}).Where("locked_until > ?", time.Now()).Find(&s)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return s, nil
+}
+
+// ResetLoginLockout clears the failed logins of the user, after a successful login or when an admin unlocks it.
+func (db Database) ResetLoginLockout(userID string) error {
+	return db.Orm.Where("user_id = ?", userID).Delete(&LoginLockout{}).Error
+}
@@ -3,9 +3,8 @@ package auth
 import (
 	"context"
 	"crypto/rsa"
-	"crypto/sha512"
 	_ "embed"
-	"encoding/hex"
+	"encoding/csv"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -24,7 +23,6 @@ import (
 	"google.golang.org/grpc/codes"
 	"net/http"
 	"net/url"
-	"reflect"
 	"strconv"
 	"strings"
 	"time"
@@ -36,7 +34,9 @@ import (
 	metadataClient "github.com/opengovern/opengovernance/pkg/metadata/client"
 	"github.com/opengovern/opengovernance/pkg/metadata/models"
 
+	"github.com/opengovern/opengovernance/pkg/auth/audit"
 	"github.com/opengovern/opengovernance/pkg/auth/db"
+	"github.com/opengovern/opengovernance/pkg/auth/scim"
 
 	"github.com/golang-jwt/jwt"
 	"github.com/opengovern/opengovernance/pkg/auth/auth0"
@@ -68,9 +68,11 @@ type httpRoutes struct {
 }
 
 func (r *httpRoutes) Register(e *echo.Echo) {
-	v1 := e.Group("/api/v1")
+	// the check endpoint records its own security events, see Server.Check
+	e.GET("/api/v1/check", r.Check)
 
-	v1.GET("/check", r.Check)
+	auditMiddleware := audit.Middleware(r.authServer.auditRecorder)
+	v1 := e.Group("/api/v1", auditMiddleware)
 
 	v1.PUT("/user/role/binding", httpserver.AuthorizeHandler(r.PutRoleBinding, api2.AdminRole))
 	v1.DELETE("/user/role/binding", httpserver.AuthorizeHandler(r.DeleteRoleBinding, api2.AdminRole))
@@ -84,11 +86,55 @@ func (r *httpRoutes) Register(e *echo.Echo) {
 
 	v1.POST("/key/create", httpserver.AuthorizeHandler(r.CreateAPIKey, api2.EditorRole))
 	v1.GET("/keys", httpserver.AuthorizeHandler(r.ListAPIKeys, api2.EditorRole))
+	v1.POST("/key/:id/rotate", httpserver.AuthorizeHandler(r.RotateAPIKey, api2.EditorRole))
 	v1.DELETE("/key/:name/delete", httpserver.AuthorizeHandler(r.DeleteAPIKey, api2.EditorRole))
 
 	v1.POST("/workspace-map/update", httpserver.AuthorizeHandler(r.UpdateWorkspaceMap, api2.InternalRole))
 
-	v3 := e.Group("/api/v3")
+	v1.GET("/audit/entries", httpserver.AuthorizeHandler(r.ListAuditEntries, api2.AdminRole))
+	v1.GET("/audit/export", httpserver.AuthorizeHandler(r.ExportAuditEntries, api2.AdminRole))
+	v1.GET("/audit/verify", httpserver.AuthorizeHandler(r.VerifyAuditLog, api2.AdminRole))
+	v1.GET("/audit/settings", httpserver.AuthorizeHandler(r.GetAuditLogSettings, api2.AdminRole))
+	v1.PUT("/audit/settings", httpserver.AuthorizeHandler(r.PutAuditLogSettings, api2.AdminRole))
+	v1.POST("/audit/events", httpserver.AuthorizeHandler(r.CreateAuditEvents, api2.InternalRole))
+
+	v1.POST("/scim/token", httpserver.AuthorizeHandler(r.CreateScimToken, api2.AdminRole))
+	v1.DELETE("/scim/token", httpserver.AuthorizeHandler(r.DeleteScimToken, api2.AdminRole))
+	v1.GET("/scim/group-roles", httpserver.AuthorizeHandler(r.ListScimGroupRoles, api2.AdminRole))
+	v1.PUT("/scim/group-roles", httpserver.AuthorizeHandler(r.PutScimGroupRoles, api2.AdminRole))
+
+	v1.GET("/oidc/role-mapping", httpserver.AuthorizeHandler(r.GetOidcRoleMapping, api2.AdminRole))
+	v1.PUT("/oidc/role-mapping", httpserver.AuthorizeHandler(r.PutOidcRoleMapping, api2.AdminRole))
+	v1.POST("/oidc/role-mapping/dry-run", httpserver.AuthorizeHandler(r.OidcRoleMappingDryRun, api2.AdminRole))
+
+	v1.GET("/service-accounts", httpserver.AuthorizeHandler(r.ListServiceAccounts, api2.EditorRole))
+	v1.POST("/service-accounts", httpserver.AuthorizeHandler(r.CreateServiceAccount, api2.AdminRole))
+	v1.GET("/service-accounts/:id", httpserver.AuthorizeHandler(r.GetServiceAccount, api2.EditorRole))
+	v1.PUT("/service-accounts/:id", httpserver.AuthorizeHandler(r.UpdateServiceAccount, api2.AdminRole))
+	v1.DELETE("/service-accounts/:id", httpserver.AuthorizeHandler(r.DeleteServiceAccount, api2.AdminRole))
+	v1.GET("/service-accounts/:id/keys", httpserver.AuthorizeHandler(r.ListServiceAccountKeys, api2.AdminRole))
+	v1.POST("/service-accounts/:id/keys", httpserver.AuthorizeHandler(r.CreateServiceAccountKey, api2.AdminRole))
+	v1.POST("/service-accounts/:id/keys/:key_id/rotate", httpserver.AuthorizeHandler(r.RotateServiceAccountKey, api2.AdminRole))
+	v1.DELETE("/service-accounts/:id/keys/:key_id", httpserver.AuthorizeHandler(r.DeleteServiceAccountKey, api2.AdminRole))
+	v1.POST("/service-accounts/:id/client-secret", httpserver.AuthorizeHandler(r.CreateServiceAccountClientSecret, api2.AdminRole))
+	v1.DELETE("/service-accounts/:id/client-secret", httpserver.AuthorizeHandler(r.DeleteServiceAccountClientSecret, api2.AdminRole))
+	// the client authenticates with its client secret, checked by the handler itself
+	v1.POST("/service-accounts/token", r.ServiceAccountToken)
+
+	v1.GET("/sessions", httpserver.AuthorizeHandler(r.ListSessions, api2.AdminRole))
+	v1.DELETE("/sessions/:token_id", httpserver.AuthorizeHandler(r.RevokeSession, api2.AdminRole))
+	v1.POST("/sessions/revoke", httpserver.AuthorizeHandler(r.RevokeAllSessions, api2.AdminRole))
+	v1.POST("/user/:user_id/sessions/revoke", httpserver.AuthorizeHandler(r.RevokeUserSessions, api2.AdminRole))
+	v1.GET("/sessions/settings", httpserver.AuthorizeHandler(r.GetSessionSettings, api2.AdminRole))
+	v1.PUT("/sessions/settings", httpserver.AuthorizeHandler(r.PutSessionSettings, api2.AdminRole))
+	v1.GET("/lockouts", httpserver.AuthorizeHandler(r.ListLoginLockouts, api2.AdminRole))
+	v1.DELETE("/lockouts/:user_id", httpserver.AuthorizeHandler(r.DeleteLoginLockout, api2.AdminRole))
+
+	// the SCIM client authenticates with the SCIM token, checked by the server itself
+	scimServer := scim.NewServer(r.scimStore(), r.authServer.verifyScimToken, scimBaseURL())
+	scimServer.Register(e.Group("/scim/v2", auditMiddleware))
+
+	v3 := e.Group("/api/v3", auditMiddleware)
 	v3.POST("/user/create", httpserver.AuthorizeHandler(r.CreateUser, api2.ViewerRole))
 	v3.POST("/user/update", httpserver.AuthorizeHandler(r.UpdateUser, api2.ViewerRole))
 	v3.GET("/user/password/check", httpserver.AuthorizeHandler(r.CheckUserPasswordChangeRequired, api2.ViewerRole))
@@ -403,6 +449,14 @@ func (r *httpRoutes) PutRoleBinding(ctx echo.Context) error {
 		auth0User.AppMetadata.ConnectionIDs = map[string][]string{}
 	}
 	auth0User.AppMetadata.ConnectionIDs[workspaceID] = req.ConnectionIDs
+	if auth0User.AppMetadata.RoleScopes == nil {
+		auth0User.AppMetadata.RoleScopes = map[string]api.RoleBindingScope{}
+	}
+	if req.RoleBindingScope.IsEmpty() {
+		delete(auth0User.AppMetadata.RoleScopes, workspaceID)
+	} else {
+		auth0User.AppMetadata.RoleScopes[workspaceID] = req.RoleBindingScope
+	}
 	err = r.auth0Service.PatchUserAppMetadata(req.UserID, auth0User.AppMetadata, nil)
 	if err != nil {
 		return err
@@ -533,6 +587,7 @@ func (r *httpRoutes) GetWorkspaceRoleBindings(ctx echo.Context) error {
 			LastActivity:        u.AppMetadata.LastLogin,
 			CreatedAt:           u.AppMetadata.MemberSince,
 			ScopedConnectionIDs: u.AppMetadata.ConnectionIDs[workspaceID],
+			RoleBindingScope:    u.AppMetadata.RoleScopes[workspaceID],
 		})
 	}
 
@@ -546,7 +601,8 @@ func (r *httpRoutes) GetWorkspaceRoleBindings(ctx echo.Context) error {
 // GetUsers godoc
 //
 //	@Summary		List Users
-//	@Description	Retrieves a list of users who are members of the workspace.
+//	@Description	Retrieves a list of users who are members of the workspace, followed by the service accounts of the workspace.
+//	@Description	Service accounts are left out when filtering by email.
 //	@Security		BearerToken
 //	@Tags			users
 //	@Produce		json
@@ -573,8 +629,29 @@ func (r *httpRoutes) GetUsers(ctx echo.Context) error {
 			Email:         u.Email,
 			EmailVerified: u.EmailVerified,
 			RoleName:      u.AppMetadata.WorkspaceAccess[workspaceID],
+			PrincipalType: audit.ActorTypeUser,
 		})
 	}
+
+	// service accounts have no email
+	if req.Email == nil && req.EmailVerified == nil {
+		serviceAccounts, err := r.db.ListServiceAccounts()
+		if err != nil {
+			r.logger.Error("failed to list service accounts", zap.Error(err))
+			return err
+		}
+		for _, sa := range serviceAccounts {
+			if req.RoleName != nil && *req.RoleName != sa.Role {
+				continue
+			}
+			resp = append(resp, api.GetUsersResponse{
+				UserID:        serviceAccountUserID(sa.ID),
+				UserName:      sa.Name,
+				RoleName:      sa.Role,
+				PrincipalType: audit.ActorTypeServiceAccount,
+			})
+		}
+	}
 	return ctx.JSON(http.StatusOK, resp)
 }
 
@@ -719,23 +796,8 @@ func (r *httpRoutes) Invite(ctx echo.Context) error {
 		return err
 	}
 
-	if allowedEmailDomains, ok := cnf.GetValue().([]string); ok {
-		passed := false
-		if len(allowedEmailDomains) > 0 {
-			for _, domain := range allowedEmailDomains {
-				if strings.HasSuffix(req.Email, domain) {
-					passed = true
-				}
-			}
-		} else {
-			passed = true
-		}
-
-		if !passed {
-			return echo.NewHTTPError(http.StatusNotAcceptable, "email domain not allowed")
-		}
-	} else {
-		fmt.Printf("failed to parse allowed domains, type: %s, value: %v", reflect.TypeOf(cnf.GetValue()).Name(), cnf.GetValue())
+	if !emailDomainAllowed(req.Email, parseAllowedEmailDomains(cnf.GetValue())) {
+		return echo.NewHTTPError(http.StatusNotAcceptable, "email domain not allowed")
 	}
 
 	us, err := r.auth0Service.SearchByEmail(req.Email)
@@ -812,6 +874,7 @@ func (r *httpRoutes) ChangeUserPreferences(ctx echo.Context) error {
 //
 //	@Summary		Create Workspace Key
 //	@Description	Creates workspace key for the defined role with the defined name in the workspace.
+//	@Description	The role can not be higher than the role of the creator, scopes restrict the key to the given operations.
 //	@Security		BearerToken
 //	@Tags			keys
 //	@Produce		json
@@ -826,6 +889,17 @@ func (r *httpRoutes) CreateAPIKey(ctx echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, err)
 	}
 
+	if req.RoleName == "" {
+		req.RoleName = api2.EditorRole
+	}
+	if roleRank(req.RoleName) < 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid role")
+	}
+	userRole := httpserver.GetUserRole(ctx)
+	if roleRank(userRole) < 0 || roleRank(req.RoleName) > roleRank(userRole) {
+		return echo.NewHTTPError(http.StatusForbidden, "key role can not be higher than your role")
+	}
+
 	usr, err := r.auth0Service.GetUser(userID)
 	if err != nil {
 		r.logger.Error("failed to get user", zap.Error(err))
@@ -836,53 +910,69 @@ func (r *httpRoutes) CreateAPIKey(ctx echo.Context) error {
 		return errors.New("failed to find user in auth0")
 	}
 
-	u := userClaim{
-		WorkspaceAccess: map[string]api2.Role{
-			"kaytu": api2.EditorRole,
-		},
-		GlobalAccess:   nil,
-		Email:          usr.Email,
-		ExternalUserID: usr.UserId,
-	}
+	return r.createAPIKey(ctx, userID, usr.Email, req)
+}
 
-	if r.kaytuPrivateKey == nil {
-		return echo.NewHTTPError(http.StatusBadRequest, "kaytu api key is disabled")
+// createAPIKey creates a key of the user or service account with the given ID.
+func (r *httpRoutes) createAPIKey(ctx echo.Context, creatorUserID, email string, req api.CreateAPIKeyRequest) error {
+	if roleRank(req.RoleName) < 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid role")
 	}
-	token, err := jwt.NewWithClaims(jwt.SigningMethodRS256, &u).SignedString(r.kaytuPrivateKey)
-	if err != nil {
-		r.logger.Error("failed to create token", zap.Error(err))
-		return err
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return echo.NewHTTPError(http.StatusBadRequest, "expiration time should be in the future")
+	}
+	var scopes []string
+	for _, scope := range req.Scopes {
+		if !isValidAPIKeyScope(scope) {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("invalid scope %s", scope))
+		}
+		scopes = append(scopes, string(scope))
 	}
 
-	masked := fmt.Sprintf("%s...%s", token[:3], token[len(token)-2:])
+	maxKeys := defaultMaxAPIKeys
+	metadataService := metadataClient.NewMetadataServiceClient(r.metadataBaseUrl)
+	cnf, err := metadataService.GetConfigMetadata(httpclient.FromEchoContext(ctx), models.MetadataKeyWorkspaceMaxKeys)
+	if err != nil && !errors.Is(err, metadataClient.ErrConfigNotFound) {
+		r.logger.Error("failed to get workspace max keys", zap.Error(err))
+		return err
+	}
+	if err == nil {
+		if value, ok := cnf.GetValue().(int); ok {
+			maxKeys = value
+		}
+	}
 
-	hash := sha512.New()
-	_, err = hash.Write([]byte(token))
+	currentKeyCount, err := r.db.CountApiKeys("kaytu")
 	if err != nil {
-		r.logger.Error("failed to hash token", zap.Error(err))
+		r.logger.Error("failed to get workspace API Keys count", zap.Error(err))
 		return err
 	}
-	keyHash := hex.EncodeToString(hash.Sum(nil))
-	r.logger.Info("hashed token")
+	if currentKeyCount >= int64(maxKeys) {
+		return echo.NewHTTPError(http.StatusNotAcceptable, "maximum number of keys for workspace reached")
+	}
 
-	currentKeyCount, err := r.db.CountApiKeysForUser(userID)
+	token, masked, keyHash, err := r.newAPIKeyToken(creatorUserID, email)
 	if err != nil {
-		r.logger.Error("failed to get user API Keys count", zap.Error(err))
 		return err
 	}
-	if currentKeyCount > 5 {
-		return echo.NewHTTPError(http.StatusNotAcceptable, "maximum number of keys for user reached")
-	}
+
 	r.logger.Info("creating API Key")
 	apikey := db.ApiKey{
 		Name:          req.Name,
-		Role:          api2.EditorRole,
-		CreatorUserID: userID,
+		Role:          req.RoleName,
+		CreatorUserID: creatorUserID,
 		WorkspaceID:   "kaytu",
 		Active:        true,
 		Revoked:       false,
 		MaskedKey:     masked,
 		KeyHash:       keyHash,
+		Scopes:        scopes,
+		ExpiresAt:     req.ExpiresAt,
+
+		ConnectionIDs:         req.ConnectionIDs,
+		ConnectionGroups:      req.ConnectionGroups,
+		ResourceCollectionIDs: req.ResourceCollectionIDs,
+		BenchmarkIDs:          req.BenchmarkIDs,
 	}
 
 	r.logger.Info("adding API Key")
@@ -899,9 +989,124 @@ func (r *httpRoutes) CreateAPIKey(ctx echo.Context) error {
 		CreatedAt: apikey.CreatedAt,
 		RoleName:  apikey.Role,
 		Token:     token,
+		ExpiresAt: apikey.ExpiresAt,
+		Scopes:    req.Scopes,
+	})
+}
+
+// RotateAPIKey godoc
+//
+//	@Summary		Rotate Workspace Key
+//	@Description	Issues a new token for the key. The previous token stays valid for the grace period.
+//	@Security		BearerToken
+//	@Tags			keys
+//	@Produce		json
+//	@Param			id		path		string					true	"Key ID"
+//	@Param			request	body		api.RotateAPIKeyRequest	false	"Request Body"
+//	@Success		200		{object}	api.RotateAPIKeyResponse
+//	@Router			/auth/api/v1/key/{id}/rotate [post]
+func (r *httpRoutes) RotateAPIKey(ctx echo.Context) error {
+	userID := httpserver.GetUserID(ctx)
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid key id")
+	}
+
+	usr, err := r.auth0Service.GetUser(userID)
+	if err != nil {
+		r.logger.Error("failed to get user", zap.Error(err))
+		return err
+	}
+	if usr == nil {
+		return errors.New("failed to find user in auth0")
+	}
+
+	return r.rotateAPIKey(ctx, userID, usr.Email, uint(id))
+}
+
+// rotateAPIKey issues a new token for the key of the user or service account with the given ID.
+func (r *httpRoutes) rotateAPIKey(ctx echo.Context, ownerUserID, email string, id uint) error {
+	var req api.RotateAPIKeyRequest
+	if err := bindValidate(ctx, &req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+
+	gracePeriod := defaultAPIKeyGracePeriod
+	if req.GracePeriodHours != nil {
+		if *req.GracePeriodHours < 0 || *req.GracePeriodHours > maxAPIKeyGracePeriodHours {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("grace period should be between 0 and %d hours", maxAPIKeyGracePeriodHours))
+		}
+		gracePeriod = time.Duration(*req.GracePeriodHours) * time.Hour
+	}
+
+	key, err := r.db.GetApiKeyForUser(ownerUserID, id)
+	if err != nil {
+		r.logger.Error("failed to get API Key", zap.Error(err))
+		return err
+	}
+	if key == nil {
+		return echo.NewHTTPError(http.StatusNotFound, "key not found")
+	}
+
+	token, masked, keyHash, err := r.newAPIKeyToken(ownerUserID, email)
+	if err != nil {
+		return err
+	}
+
+	previousKeyHash := ""
+	var previousKeyExpiresAt *time.Time
+	if gracePeriod > 0 {
+		previousKeyHash = key.KeyHash
+		validUntil := time.Now().Add(gracePeriod)
+		previousKeyExpiresAt = &validUntil
+	}
+	err = r.db.RotateAPIKey(ownerUserID, key.ID, masked, keyHash, previousKeyHash, previousKeyExpiresAt)
+	if err != nil {
+		r.logger.Error("failed to rotate API Key", zap.Error(err))
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, api.RotateAPIKeyResponse{
+		ID:                      key.ID,
+		Name:                    key.Name,
+		Token:                   token,
+		PreviousTokenValidUntil: previousKeyExpiresAt,
 	})
 }
 
+// newAPIKeyToken signs a new token for the user or service account and returns it with its masked form and hash.
+func (r *httpRoutes) newAPIKeyToken(userID, email string) (string, string, string, error) {
+	if r.kaytuPrivateKey == nil {
+		return "", "", "", echo.NewHTTPError(http.StatusBadRequest, "kaytu api key is disabled")
+	}
+
+	u := userClaim{
+		WorkspaceAccess: map[string]api2.Role{
+			"kaytu": api2.EditorRole,
+		},
+		GlobalAccess:   nil,
+		Email:          email,
+		ExternalUserID: userID,
+		TokenID:        uuid.New().String(),
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodRS256, &u).SignedString(r.kaytuPrivateKey)
+	if err != nil {
+		r.logger.Error("failed to create token", zap.Error(err))
+		return "", "", "", err
+	}
+
+	masked := fmt.Sprintf("%s...%s", token[:3], token[len(token)-2:])
+
+	keyHash, err := hashAPIKey(token)
+	if err != nil {
+		r.logger.Error("failed to hash token", zap.Error(err))
+		return "", "", "", err
+	}
+	r.logger.Info("hashed token")
+
+	return token, masked, keyHash, nil
+}
+
 // DeleteAPIKey godoc
 //
 //	@Summary		Delete Workspace Key
@@ -960,20 +1165,39 @@ func (r *httpRoutes) ListAPIKeys(ctx echo.Context) error {
 
 	var resp []api.WorkspaceApiKey
 	for _, key := range keys {
-		resp = append(resp, api.WorkspaceApiKey{
-			ID:            key.ID,
-			CreatedAt:     key.CreatedAt,
-			Name:          key.Name,
-			RoleName:      key.Role,
-			CreatorUserID: key.CreatorUserID,
-			Active:        key.Active,
-			MaskedKey:     key.MaskedKey,
-		})
+		resp = append(resp, toWorkspaceApiKey(key))
 	}
 
 	return ctx.JSON(http.StatusOK, resp)
 }
 
+func toWorkspaceApiKey(key db.ApiKey) api.WorkspaceApiKey {
+	apiKey := api.WorkspaceApiKey{
+		ID:            key.ID,
+		CreatedAt:     key.CreatedAt,
+		Name:          key.Name,
+		RoleName:      key.Role,
+		CreatorUserID: key.CreatorUserID,
+		Active:        key.Active,
+		MaskedKey:     key.MaskedKey,
+		ExpiresAt:     key.ExpiresAt,
+		LastUsedAt:    key.LastUsedAt,
+		ConnectionIDs: key.ConnectionIDs,
+		RoleBindingScope: api.RoleBindingScope{
+			ConnectionGroups:      key.ConnectionGroups,
+			ResourceCollectionIDs: key.ResourceCollectionIDs,
+			BenchmarkIDs:          key.BenchmarkIDs,
+		},
+	}
+	for _, scope := range key.Scopes {
+		apiKey.Scopes = append(apiKey.Scopes, api.APIKeyScope(scope))
+	}
+	if key.PreviousKeyExpiresAt != nil && key.PreviousKeyExpiresAt.After(time.Now()) {
+		apiKey.PreviousTokenValidUntil = key.PreviousKeyExpiresAt
+	}
+	return apiKey
+}
+
 func (r *httpRoutes) UpdateWorkspaceMap(ctx echo.Context) error {
 	err := r.authServer.updateWorkspaceMap()
 	if err != nil {
@@ -1307,6 +1531,13 @@ func (r *httpRoutes) DoDeleteUser(emailAddress string) error {
 		r.logger.Error("failed to delete user", zap.Error(err))
 		return echo.NewHTTPError(http.StatusBadRequest, "failed to create user")
 	}
+	// the tokens of the user would recreate it on their next request
+	err = r.db.RevokeUserSessions(db.UserSessionRevocation{UserID: user.UserId, RevokedAt: time.Now()})
+	if err != nil {
+		r.logger.Error("failed to revoke user sessions", zap.Error(err))
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to revoke user sessions")
+	}
+	r.authServer.invalidateSessionState()
 	return nil
 }
 
@@ -1394,6 +1625,9 @@ func (r *httpRoutes) ResetUserPassword(ctx echo.Context) error {
 		return echo.NewHTTPError(http.StatusNotFound, "user not found")
 	}
 	if !resp.Verified {
+		if _, err := r.authServer.recordLoginFailure(user.Email); err != nil {
+			r.logger.Error("failed to record login failure", zap.Error(err))
+		}
 		return echo.NewHTTPError(http.StatusUnauthorized, "current password is not correct")
 	}
 
@@ -1437,3 +1671,1162 @@ func (r *httpRoutes) ResetUserPassword(ctx echo.Context) error {
 
 	return ctx.NoContent(http.StatusOK)
 }
+
+const (
+	defaultAuditEntriesPageSize = 100
+	maxAuditEntriesPageSize     = 1000
+)
+
+func auditEntryFiltersFromQuery(ctx echo.Context) (db.AuditEntryFilters, error) {
+	filters := db.AuditEntryFilters{
+		Types:      httpserver.QueryArrayParam(ctx, "type"),
+		Outcomes:   httpserver.QueryArrayParam(ctx, "outcome"),
+		ActorIDs:   httpserver.QueryArrayParam(ctx, "actorId"),
+		ActorTypes: httpserver.QueryArrayParam(ctx, "actorType"),
+		Services:   httpserver.QueryArrayParam(ctx, "service"),
+		Methods:    httpserver.QueryArrayParam(ctx, "method"),
+		Route:      ctx.QueryParam("route"),
+		TargetID:   ctx.QueryParam("targetId"),
+	}
+	for i, method := range filters.Methods {
+		filters.Methods[i] = strings.ToUpper(method)
+	}
+	for param, t := range map[string]**time.Time{"from": &filters.From, "to": &filters.To} {
+		value := ctx.QueryParam(param)
+		if value == "" {
+			continue
+		}
+		unix, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return filters, echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("invalid %s", param))
+		}
+		tm := time.Unix(unix, 0)
+		*t = &tm
+	}
+	return filters, nil
+}
+
+// ListAuditEntries godoc
+//
+//	@Summary		List audit log entries
+//	@Description	Returns the audit log entries matching the filters, newest first
+//	@Security		BearerToken
+//	@Tags			audit
+//	@Produce		json
+//	@Param			type		query		[]string	false	"Entry types"	Enums(action, security)
+//	@Param			outcome		query		[]string	false	"Outcomes"		Enums(success, failure, denied, unauthenticated)
+//	@Param			actorId		query		[]string	false	"Actor user IDs"
+//	@Param			actorType	query		[]string	false	"Actor types"	Enums(user, api_key, anonymous)
+//	@Param			service		query		[]string	false	"Services"
+//	@Param			method		query		[]string	false	"HTTP methods"
+//	@Param			route		query		string		false	"Route prefix"
+//	@Param			targetId	query		string		false	"ID of a target of the action"
+//	@Param			from		query		int			false	"Start of the time range in unix seconds"
+//	@Param			to			query		int			false	"End of the time range in unix seconds"
+//	@Param			cursor		query		int			false	"Return the entries older than this entry ID"
+//	@Param			perPage		query		int			false	"Page size, defaults to 100, at most 1000"
+//	@Success		200			{object}	api.ListAuditEntriesResponse
+//	@Router			/auth/api/v1/audit/entries [get]
+func (r *httpRoutes) ListAuditEntries(ctx echo.Context) error {
+	filters, err := auditEntryFiltersFromQuery(ctx)
+	if err != nil {
+		return err
+	}
+
+	var cursor *uint64
+	if value := ctx.QueryParam("cursor"); value != "" {
+		c, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid cursor")
+		}
+		cursor = &c
+	}
+	perPage := defaultAuditEntriesPageSize
+	if value := ctx.QueryParam("perPage"); value != "" {
+		perPage, err = strconv.Atoi(value)
+		if err != nil || perPage <= 0 || perPage > maxAuditEntriesPageSize {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid perPage")
+		}
+	}
+
+	entries, err := r.db.ListAuditEntries(filters, cursor, perPage)
+	if err != nil {
+		r.logger.Error("failed to list audit entries", zap.Error(err))
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to list audit entries")
+	}
+
+	res := api.ListAuditEntriesResponse{
+		Entries: make([]api.AuditEntry, 0, len(entries)),
+	}
+	for _, entry := range entries {
+		res.Entries = append(res.Entries, auditEntryToApi(entry))
+	}
+	if len(entries) == perPage {
+		next := entries[len(entries)-1].ID
+		res.NextCursor = &next
+	}
+	return ctx.JSON(http.StatusOK, res)
+}
+
+// ExportAuditEntries godoc
+//
+//	@Summary		Export audit log entries
+//	@Description	Streams all audit log entries matching the filters, newest first, as CSV or JSON lines
+//	@Security		BearerToken
+//	@Tags			audit
+//	@Produce		text/csv
+//	@Produce		application/x-ndjson
+//	@Param			format		query	string		false	"Export format, defaults to jsonl"	Enums(csv, jsonl)
+//	@Param			type		query	[]string	false	"Entry types"	Enums(action, security)
+//	@Param			outcome		query	[]string	false	"Outcomes"		Enums(success, failure, denied, unauthenticated)
+//	@Param			actorId		query	[]string	false	"Actor user IDs"
+//	@Param			actorType	query	[]string	false	"Actor types"	Enums(user, api_key, anonymous)
+//	@Param			service		query	[]string	false	"Services"
+//	@Param			method		query	[]string	false	"HTTP methods"
+//	@Param			route		query	string		false	"Route prefix"
+//	@Param			targetId	query	string		false	"ID of a target of the action"
+//	@Param			from		query	int			false	"Start of the time range in unix seconds"
+//	@Param			to			query	int			false	"End of the time range in unix seconds"
+//	@Success		200
+//	@Router			/auth/api/v1/audit/export [get]
+func (r *httpRoutes) ExportAuditEntries(ctx echo.Context) error {
+	filters, err := auditEntryFiltersFromQuery(ctx)
+	if err != nil {
+		return err
+	}
+
+	format := strings.ToLower(ctx.QueryParam("format"))
+	var write func(entry api.AuditEntry) error
+	resp := ctx.Response()
+	switch format {
+	case "", "jsonl":
+		format = "jsonl"
+		resp.Header().Set(echo.HeaderContentType, "application/x-ndjson")
+		encoder := json.NewEncoder(resp)
+		write = func(entry api.AuditEntry) error {
+			return encoder.Encode(entry)
+		}
+	case "csv":
+		resp.Header().Set(echo.HeaderContentType, "text/csv")
+		writer := csv.NewWriter(resp)
+		defer writer.Flush()
+		header := []string{"id", "time", "type", "service", "actor_id", "actor_type", "api_key_id", "role",
+			"workspace_id", "method", "route", "path", "target_ids", "request_summary", "outcome", "status_code",
+			"reason", "source_ip", "prev_hash", "hash"}
+		headerWritten := false
+		write = func(entry api.AuditEntry) error {
+			if !headerWritten {
+				if err := writer.Write(header); err != nil {
+					return err
+				}
+				headerWritten = true
+			}
+			targetIDs := ""
+			if len(entry.TargetIDs) > 0 {
+				b, err := json.Marshal(entry.TargetIDs)
+				if err != nil {
+					return err
+				}
+				targetIDs = string(b)
+			}
+			return writer.Write([]string{
+				strconv.FormatUint(entry.ID, 10), entry.Time.Format(time.RFC3339Nano), string(entry.Type),
+				entry.Service, entry.ActorID, string(entry.ActorType), entry.APIKeyID, entry.Role, entry.WorkspaceID,
+				entry.Method, entry.Route, entry.Path, targetIDs, entry.RequestSummary, string(entry.Outcome),
+				strconv.Itoa(entry.StatusCode), entry.Reason, entry.SourceIP, entry.PrevHash, entry.Hash,
+			})
+		}
+	default:
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid format, valid values are csv and jsonl")
+	}
+	resp.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=audit-log.%s", format))
+	resp.WriteHeader(http.StatusOK)
+
+	var cursor *uint64
+	for {
+		entries, err := r.db.ListAuditEntries(filters, cursor, maxAuditEntriesPageSize)
+		if err != nil {
+			// the status is already sent, all that can be done is to cut the export short
+			r.logger.Error("failed to list audit entries for export", zap.Error(err))
+			return nil
+		}
+		for _, entry := range entries {
+			if err := write(auditEntryToApi(entry)); err != nil {
+				r.logger.Error("failed to write audit entry to export", zap.Error(err))
+				return nil
+			}
+		}
+		if len(entries) < maxAuditEntriesPageSize {
+			return nil
+		}
+		next := entries[len(entries)-1].ID
+		cursor = &next
+	}
+}
+
+// VerifyAuditLog godoc
+//
+//	@Summary		Verify audit log
+//	@Description	Recomputes the hash chain of the audit log and reports the first entry that has been modified or removed
+//	@Security		BearerToken
+//	@Tags			audit
+//	@Produce		json
+//	@Success		200	{object}	api.VerifyAuditLogResponse
+//	@Router			/auth/api/v1/audit/verify [get]
+func (r *httpRoutes) VerifyAuditLog(ctx echo.Context) error {
+	res, err := r.authServer.verifyAuditLog()
+	if err != nil {
+		r.logger.Error("failed to verify audit log", zap.Error(err))
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to verify audit log")
+	}
+	return ctx.JSON(http.StatusOK, res)
+}
+
+// GetAuditLogSettings godoc
+//
+//	@Summary	Get audit log settings
+//	@Security	BearerToken
+//	@Tags		audit
+//	@Produce	json
+//	@Success	200	{object}	api.AuditLogSettings
+//	@Router		/auth/api/v1/audit/settings [get]
+func (r *httpRoutes) GetAuditLogSettings(ctx echo.Context) error {
+	days, err := r.db.GetAuditLogRetentionDays()
+	if err != nil {
+		r.logger.Error("failed to get audit log retention", zap.Error(err))
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to get audit log settings")
+	}
+	if days <= 0 {
+		days = defaultAuditLogRetentionDays
+	}
+	return ctx.JSON(http.StatusOK, api.AuditLogSettings{RetentionDays: days})
+}
+
+// PutAuditLogSettings godoc
+//
+//	@Summary	Update audit log settings
+//	@Security	BearerToken
+//	@Tags		audit
+//	@Accept		json
+//	@Produce	json
+//	@Param		request	body		api.AuditLogSettings	true	"Audit log settings"
+//	@Success	200		{object}	api.AuditLogSettings
+//	@Router		/auth/api/v1/audit/settings [put]
+func (r *httpRoutes) PutAuditLogSettings(ctx echo.Context) error {
+	var req api.AuditLogSettings
+	if err := bindValidate(ctx, &req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := r.db.SetAuditLogRetentionDays(req.RetentionDays); err != nil {
+		r.logger.Error("failed to set audit log retention", zap.Error(err))
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to update audit log settings")
+	}
+	return ctx.JSON(http.StatusOK, req)
+}
+
+func (r *httpRoutes) CreateAuditEvents(ctx echo.Context) error {
+	var req audit.CreateEventsRequest
+	if err := bindValidate(ctx, &req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if len(req.Events) == 0 {
+		return ctx.NoContent(http.StatusOK)
+	}
+
+	if err := r.authServer.storeAuditEvents(ctx.Request().Context(), req.Events); err != nil {
+		r.logger.Error("failed to store audit events", zap.Error(err))
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to store audit events")
+	}
+	return ctx.NoContent(http.StatusOK)
+}
+
+func (r *httpRoutes) scimStore() *scimStore {
+	return &scimStore{logger: r.logger.Named("scim"), db: r.db}
+}
+
+// CreateScimToken godoc
+//
+//	@Summary		Create SCIM token
+//	@Description	Creates the bearer token of the SCIM client, replacing the previous one
+//	@Security		BearerToken
+//	@Tags			scim
+//	@Produce		json
+//	@Success		200	{object}	api.CreateScimTokenResponse
+//	@Router			/auth/api/v1/scim/token [post]
+func (r *httpRoutes) CreateScimToken(ctx echo.Context) error {
+	token, err := newScimToken()
+	if err != nil {
+		r.logger.Error("failed to generate scim token", zap.Error(err))
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to create scim token")
+	}
+	hash, err := hashAPIKey(token)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to create scim token")
+	}
+	if err := r.db.SetScimTokenHash(hash); err != nil {
+		r.logger.Error("failed to store scim token", zap.Error(err))
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to create scim token")
+	}
+	return ctx.JSON(http.StatusOK, api.CreateScimTokenResponse{
+		Token:   token,
+		BaseURL: scimBaseURL(),
+	})
+}
+
+// DeleteScimToken godoc
+//
+//	@Summary		Delete SCIM token
+//	@Description	Revokes the bearer token of the SCIM client
+//	@Security		BearerToken
+//	@Tags			scim
+//	@Success		200
+//	@Router			/auth/api/v1/scim/token [delete]
+func (r *httpRoutes) DeleteScimToken(ctx echo.Context) error {
+	if err := r.db.SetScimTokenHash(""); err != nil {
+		r.logger.Error("failed to delete scim token", zap.Error(err))
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to delete scim token")
+	}
+	return ctx.NoContent(http.StatusOK)
+}
+
+// ListScimGroupRoles godoc
+//
+//	@Summary	List SCIM group roles
+//	@Security	BearerToken
+//	@Tags		scim
+//	@Produce	json
+//	@Success	200	{object}	[]api.ScimGroupRole
+//	@Router		/auth/api/v1/scim/group-roles [get]
+func (r *httpRoutes) ListScimGroupRoles(ctx echo.Context) error {
+	roles, err := r.db.ListScimGroupRoles()
+	if err != nil {
+		r.logger.Error("failed to list scim group roles", zap.Error(err))
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to list scim group roles")
+	}
+	resp := make([]api.ScimGroupRole, 0, len(roles))
+	for _, role := range roles {
+		resp = append(resp, api.ScimGroupRole{GroupName: role.GroupName, Role: role.Role})
+	}
+	return ctx.JSON(http.StatusOK, resp)
+}
+
+// PutScimGroupRoles godoc
+//
+//	@Summary		Set SCIM group roles
+//	@Description	Replaces the mapping of SCIM groups to roles and applies it to the members of the groups
+//	@Security		BearerToken
+//	@Tags			scim
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		api.PutScimGroupRolesRequest	true	"Group roles"
+//	@Success		200		{object}	[]api.ScimGroupRole
+//	@Router			/auth/api/v1/scim/group-roles [put]
+func (r *httpRoutes) PutScimGroupRoles(ctx echo.Context) error {
+	var req api.PutScimGroupRolesRequest
+	if err := bindValidate(ctx, &req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	roles := make([]db.ScimGroupRole, 0, len(req.GroupRoles))
+	seen := make(map[string]bool)
+	for _, groupRole := range req.GroupRoles {
+		if roleRank(groupRole.Role) < 0 {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("invalid role %s", groupRole.Role))
+		}
+		name := strings.ToLower(groupRole.GroupName)
+		if seen[name] {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("group %s is mapped more than once", groupRole.GroupName))
+		}
+		seen[name] = true
+		roles = append(roles, db.ScimGroupRole{GroupName: groupRole.GroupName, Role: groupRole.Role})
+	}
+
+	if err := r.db.SetScimGroupRoles(roles); err != nil {
+		r.logger.Error("failed to set scim group roles", zap.Error(err))
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to set scim group roles")
+	}
+	if err := r.scimStore().syncAllUserRoles(); err != nil {
+		r.logger.Error("failed to apply scim group roles", zap.Error(err))
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to apply scim group roles")
+	}
+	return r.ListScimGroupRoles(ctx)
+}
+
+// GetOidcRoleMapping godoc
+//
+//	@Summary		Get OIDC role mapping
+//	@Description	Returns the rules giving roles to the users from the claims of their ID token
+//	@Security		BearerToken
+//	@Tags			oidc
+//	@Produce		json
+//	@Success		200	{object}	api.OidcRoleMapping
+//	@Router			/auth/api/v1/oidc/role-mapping [get]
+func (r *httpRoutes) GetOidcRoleMapping(ctx echo.Context) error {
+	rules, err := r.db.ListOidcRoleRules()
+	if err != nil {
+		r.logger.Error("failed to list role rules", zap.Error(err))
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to get role mapping")
+	}
+	settings, err := r.db.GetOidcRoleMappingSettings()
+	if err != nil {
+		r.logger.Error("failed to get role mapping settings", zap.Error(err))
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to get role mapping")
+	}
+
+	resp := api.OidcRoleMapping{
+		Rules:         make([]api.OidcRoleRule, 0, len(rules)),
+		DefaultRole:   settings.DefaultRole,
+		DenyUnmatched: settings.DenyUnmatched,
+	}
+	for _, rule := range rules {
+		resp.Rules = append(resp.Rules, api.OidcRoleRule{
+			ClaimType: api.OidcClaimType(rule.ClaimType),
+			Claim:     rule.Claim,
+			Value:     rule.Value,
+			Role:      rule.Role,
+			RoleBindingScope: api.RoleBindingScope{
+				ConnectionGroups:      rule.ConnectionGroups,
+				ResourceCollectionIDs: rule.ResourceCollectionIDs,
+				BenchmarkIDs:          rule.BenchmarkIDs,
+			},
+		})
+	}
+	return ctx.JSON(http.StatusOK, resp)
+}
+
+// PutOidcRoleMapping godoc
+//
+//	@Summary		Set OIDC role mapping
+//	@Description	Replaces the rules giving roles to the users from the claims of their ID token. The rules are
+//	@Description	applied on the next request of each user, use the dry run to check them beforehand.
+//	@Security		BearerToken
+//	@Tags			oidc
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		api.OidcRoleMapping	true	"Role mapping"
+//	@Success		200		{object}	api.OidcRoleMapping
+//	@Router			/auth/api/v1/oidc/role-mapping [put]
+func (r *httpRoutes) PutOidcRoleMapping(ctx echo.Context) error {
+	var req api.OidcRoleMapping
+	if err := bindValidate(ctx, &req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if req.DefaultRole != "" && roleRank(req.DefaultRole) < 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("invalid default role %s", req.DefaultRole))
+	}
+	if len(req.Rules) > 0 && req.DefaultRole == "" && !req.DenyUnmatched {
+		return echo.NewHTTPError(http.StatusBadRequest, "rules need a default role or denying unmatched users")
+	}
+
+	rules := make([]db.OidcRoleRule, 0, len(req.Rules))
+	for i, rule := range req.Rules {
+		if roleRank(rule.Role) < 0 {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("invalid role %s in rule %d", rule.Role, i))
+		}
+		if rule.ClaimType == api.OidcClaimTypeClaim && rule.Claim == "" {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("rule %d matches a claim without naming it", i))
+		}
+		rules = append(rules, db.OidcRoleRule{
+			Priority:              i,
+			ClaimType:             string(rule.ClaimType),
+			Claim:                 rule.Claim,
+			Value:                 rule.Value,
+			Role:                  rule.Role,
+			ConnectionGroups:      rule.ConnectionGroups,
+			ResourceCollectionIDs: rule.ResourceCollectionIDs,
+			BenchmarkIDs:          rule.BenchmarkIDs,
+		})
+	}
+
+	err := r.db.SetOidcRoleRules(rules, db.OidcRoleMappingSettings{
+		DefaultRole:   req.DefaultRole,
+		DenyUnmatched: req.DenyUnmatched,
+	})
+	if err != nil {
+		r.logger.Error("failed to set role rules", zap.Error(err))
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to set role mapping")
+	}
+	r.authServer.invalidateOidcRoleMapping()
+	return r.GetOidcRoleMapping(ctx)
+}
+
+// OidcRoleMappingDryRun godoc
+//
+//	@Summary		Dry run OIDC role mapping
+//	@Description	Shows the role the user of an ID token, or of the given claims, would get from the role mapping
+//	@Security		BearerToken
+//	@Tags			oidc
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		api.OidcRoleDryRunRequest	true	"Token or claims"
+//	@Success		200		{object}	api.OidcRoleDryRunResponse
+//	@Router			/auth/api/v1/oidc/role-mapping/dry-run [post]
+func (r *httpRoutes) OidcRoleMappingDryRun(ctx echo.Context) error {
+	var req api.OidcRoleDryRunRequest
+	if err := bindValidate(ctx, &req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	claims := req.Claims
+	if token := strings.TrimSpace(strings.TrimPrefix(req.Token, "Bearer ")); token != "" {
+		idToken, err := r.authServer.dexVerifier.Verify(ctx.Request().Context(), token)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("invalid token: %v", err))
+		}
+		claims = nil
+		if err := idToken.Claims(&claims); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("invalid token claims: %v", err))
+		}
+	}
+	if claims == nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "token or claims are required")
+	}
+
+	res, err := r.authServer.oidcRoleDryRun(ctx.Request().Context(), claims)
+	if err != nil {
+		r.logger.Error("failed to evaluate role rules", zap.Error(err))
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to evaluate role mapping")
+	}
+	return ctx.JSON(http.StatusOK, res)
+}
+
+func (r *httpRoutes) getServiceAccountParam(ctx echo.Context) (*db.ServiceAccount, error) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "invalid service account id")
+	}
+	sa, err := r.db.GetServiceAccount(uint(id))
+	if err != nil {
+		r.logger.Error("failed to get service account", zap.Error(err))
+		return nil, err
+	}
+	if sa == nil {
+		return nil, echo.NewHTTPError(http.StatusNotFound, "service account not found")
+	}
+	return sa, nil
+}
+
+// validateServiceAccountRequest checks the request and fills the service account with it.
+func (r *httpRoutes) validateServiceAccountRequest(ctx echo.Context, req api.ServiceAccountRequest, sa *db.ServiceAccount) error {
+	if req.RoleName == "" {
+		req.RoleName = api2.ViewerRole
+	}
+	if roleRank(req.RoleName) < 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid role")
+	}
+	userRole := httpserver.GetUserRole(ctx)
+	if roleRank(userRole) < 0 || roleRank(req.RoleName) > roleRank(userRole) {
+		return echo.NewHTTPError(http.StatusForbidden, "service account role can not be higher than your role")
+	}
+	if req.Name != sa.Name {
+		existing, err := r.db.GetServiceAccountByName(req.Name)
+		if err != nil {
+			r.logger.Error("failed to get service account", zap.Error(err))
+			return err
+		}
+		if existing != nil {
+			return echo.NewHTTPError(http.StatusConflict, "service account with the same name already exists")
+		}
+	}
+
+	sa.Name = req.Name
+	sa.Description = req.Description
+	sa.OwnerTeam = req.OwnerTeam
+	sa.Role = req.RoleName
+	sa.Disabled = req.Disabled
+	sa.ConnectionIDs = req.ConnectionIDs
+	sa.ConnectionGroups = req.ConnectionGroups
+	sa.ResourceCollectionIDs = req.ResourceCollectionIDs
+	sa.BenchmarkIDs = req.BenchmarkIDs
+	return nil
+}
+
+// ListServiceAccounts godoc
+//
+//	@Summary		List service accounts
+//	@Description	Lists the service accounts of the workspace
+//	@Security		BearerToken
+//	@Tags			service-accounts
+//	@Produce		json
+//	@Success		200	{array}	api.ServiceAccount
+//	@Router			/auth/api/v1/service-accounts [get]
+func (r *httpRoutes) ListServiceAccounts(ctx echo.Context) error {
+	serviceAccounts, err := r.db.ListServiceAccounts()
+	if err != nil {
+		r.logger.Error("failed to list service accounts", zap.Error(err))
+		return err
+	}
+	resp := make([]api.ServiceAccount, 0, len(serviceAccounts))
+	for _, sa := range serviceAccounts {
+		resp = append(resp, toServiceAccount(sa))
+	}
+	return ctx.JSON(http.StatusOK, resp)
+}
+
+// CreateServiceAccount godoc
+//
+//	@Summary		Create service account
+//	@Description	Creates a service account owned by the workspace. Its keys and client secret do not depend on
+//	@Description	the user who created them. The role can not be higher than the role of the creator.
+//	@Security		BearerToken
+//	@Tags			service-accounts
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		api.ServiceAccountRequest	true	"Service account"
+//	@Success		200		{object}	api.ServiceAccount
+//	@Router			/auth/api/v1/service-accounts [post]
+func (r *httpRoutes) CreateServiceAccount(ctx echo.Context) error {
+	var req api.ServiceAccountRequest
+	if err := bindValidate(ctx, &req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	sa := db.ServiceAccount{CreatorUserID: httpserver.GetUserID(ctx)}
+	if err := r.validateServiceAccountRequest(ctx, req, &sa); err != nil {
+		return err
+	}
+	if err := r.db.CreateServiceAccount(&sa); err != nil {
+		r.logger.Error("failed to create service account", zap.Error(err))
+		return err
+	}
+	return ctx.JSON(http.StatusOK, toServiceAccount(sa))
+}
+
+// GetServiceAccount godoc
+//
+//	@Summary		Get service account
+//	@Security		BearerToken
+//	@Tags			service-accounts
+//	@Produce		json
+//	@Param			id	path		string	true	"Service account ID"
+//	@Success		200	{object}	api.ServiceAccount
+//	@Router			/auth/api/v1/service-accounts/{id} [get]
+func (r *httpRoutes) GetServiceAccount(ctx echo.Context) error {
+	sa, err := r.getServiceAccountParam(ctx)
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(http.StatusOK, toServiceAccount(*sa))
+}
+
+// UpdateServiceAccount godoc
+//
+//	@Summary		Update service account
+//	@Description	Replaces the service account, the keys of the service account can not act with a higher role
+//	@Description	than its new role. Disabled service accounts can not authenticate.
+//	@Security		BearerToken
+//	@Tags			service-accounts
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string						true	"Service account ID"
+//	@Param			request	body		api.ServiceAccountRequest	true	"Service account"
+//	@Success		200		{object}	api.ServiceAccount
+//	@Router			/auth/api/v1/service-accounts/{id} [put]
+func (r *httpRoutes) UpdateServiceAccount(ctx echo.Context) error {
+	sa, err := r.getServiceAccountParam(ctx)
+	if err != nil {
+		return err
+	}
+	var req api.ServiceAccountRequest
+	if err := bindValidate(ctx, &req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := r.validateServiceAccountRequest(ctx, req, sa); err != nil {
+		return err
+	}
+	if err := r.db.UpdateServiceAccount(sa); err != nil {
+		r.logger.Error("failed to update service account", zap.Error(err))
+		return err
+	}
+	return ctx.JSON(http.StatusOK, toServiceAccount(*sa))
+}
+
+// DeleteServiceAccount godoc
+//
+//	@Summary		Delete service account
+//	@Description	Deletes the service account and revokes its keys
+//	@Security		BearerToken
+//	@Tags			service-accounts
+//	@Param			id	path	string	true	"Service account ID"
+//	@Success		200
+//	@Router			/auth/api/v1/service-accounts/{id} [delete]
+func (r *httpRoutes) DeleteServiceAccount(ctx echo.Context) error {
+	sa, err := r.getServiceAccountParam(ctx)
+	if err != nil {
+		return err
+	}
+	if err := r.db.DeleteServiceAccount(sa.ID, serviceAccountUserID(sa.ID)); err != nil {
+		r.logger.Error("failed to delete service account", zap.Error(err))
+		return err
+	}
+	return ctx.NoContent(http.StatusOK)
+}
+
+// ListServiceAccountKeys godoc
+//
+//	@Summary		List service account keys
+//	@Security		BearerToken
+//	@Tags			service-accounts
+//	@Produce		json
+//	@Param			id	path	string	true	"Service account ID"
+//	@Success		200	{array}	api.WorkspaceApiKey
+//	@Router			/auth/api/v1/service-accounts/{id}/keys [get]
+func (r *httpRoutes) ListServiceAccountKeys(ctx echo.Context) error {
+	sa, err := r.getServiceAccountParam(ctx)
+	if err != nil {
+		return err
+	}
+	keys, err := r.db.ListApiKeysForUser(serviceAccountUserID(sa.ID))
+	if err != nil {
+		return err
+	}
+	resp := make([]api.WorkspaceApiKey, 0, len(keys))
+	for _, key := range keys {
+		resp = append(resp, toWorkspaceApiKey(key))
+	}
+	return ctx.JSON(http.StatusOK, resp)
+}
+
+// CreateServiceAccountKey godoc
+//
+//	@Summary		Create service account key
+//	@Description	Creates a key of the service account. The role defaults to the role of the service account and
+//	@Description	can not be higher than it, scopes restrict the key to the given operations.
+//	@Security		BearerToken
+//	@Tags			service-accounts
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string					true	"Service account ID"
+//	@Param			request	body		api.CreateAPIKeyRequest	true	"Request Body"
+//	@Success		200		{object}	api.CreateAPIKeyResponse
+//	@Router			/auth/api/v1/service-accounts/{id}/keys [post]
+func (r *httpRoutes) CreateServiceAccountKey(ctx echo.Context) error {
+	sa, err := r.getServiceAccountParam(ctx)
+	if err != nil {
+		return err
+	}
+	var req api.CreateAPIKeyRequest
+	if err := bindValidate(ctx, &req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+	if req.RoleName == "" {
+		req.RoleName = sa.Role
+	}
+	if roleRank(req.RoleName) > roleRank(sa.Role) {
+		return echo.NewHTTPError(http.StatusBadRequest, "key role can not be higher than the role of the service account")
+	}
+	return r.createAPIKey(ctx, serviceAccountUserID(sa.ID), "", req)
+}
+
+// RotateServiceAccountKey godoc
+//
+//	@Summary		Rotate service account key
+//	@Description	Issues a new token for the key. The previous token stays valid for the grace period.
+//	@Security		BearerToken
+//	@Tags			service-accounts
+//	@Produce		json
+//	@Param			id		path		string					true	"Service account ID"
+//	@Param			key_id	path		string					true	"Key ID"
+//	@Param			request	body		api.RotateAPIKeyRequest	false	"Request Body"
+//	@Success		200		{object}	api.RotateAPIKeyResponse
+//	@Router			/auth/api/v1/service-accounts/{id}/keys/{key_id}/rotate [post]
+func (r *httpRoutes) RotateServiceAccountKey(ctx echo.Context) error {
+	sa, err := r.getServiceAccountParam(ctx)
+	if err != nil {
+		return err
+	}
+	keyID, err := strconv.ParseUint(ctx.Param("key_id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid key id")
+	}
+	return r.rotateAPIKey(ctx, serviceAccountUserID(sa.ID), "", uint(keyID))
+}
+
+// DeleteServiceAccountKey godoc
+//
+//	@Summary		Delete service account key
+//	@Security		BearerToken
+//	@Tags			service-accounts
+//	@Param			id		path	string	true	"Service account ID"
+//	@Param			key_id	path	string	true	"Key ID"
+//	@Success		200
+//	@Router			/auth/api/v1/service-accounts/{id}/keys/{key_id} [delete]
+func (r *httpRoutes) DeleteServiceAccountKey(ctx echo.Context) error {
+	sa, err := r.getServiceAccountParam(ctx)
+	if err != nil {
+		return err
+	}
+	keyID, err := strconv.ParseUint(ctx.Param("key_id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid key id")
+	}
+	userID := serviceAccountUserID(sa.ID)
+	key, err := r.db.GetApiKeyForUser(userID, uint(keyID))
+	if err != nil {
+		return err
+	}
+	if key == nil {
+		return echo.NewHTTPError(http.StatusNotFound, "key not found")
+	}
+	if err := r.db.RevokeUserAPIKey(userID, key.ID); err != nil {
+		return err
+	}
+	return ctx.NoContent(http.StatusOK)
+}
+
+// CreateServiceAccountClientSecret godoc
+//
+//	@Summary		Create service account client secret
+//	@Description	Enables the client credentials grant of the service account with a new client secret, replacing
+//	@Description	the previous one. The tokens issued with the previous secret are rejected.
+//	@Security		BearerToken
+//	@Tags			service-accounts
+//	@Produce		json
+//	@Param			id	path		string	true	"Service account ID"
+//	@Success		200	{object}	api.CreateServiceAccountClientSecretResponse
+//	@Router			/auth/api/v1/service-accounts/{id}/client-secret [post]
+func (r *httpRoutes) CreateServiceAccountClientSecret(ctx echo.Context) error {
+	sa, err := r.getServiceAccountParam(ctx)
+	if err != nil {
+		return err
+	}
+	if r.kaytuPrivateKey == nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "kaytu api key is disabled")
+	}
+
+	secret, err := newServiceAccountSecret()
+	if err != nil {
+		r.logger.Error("failed to generate client secret", zap.Error(err))
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to create client secret")
+	}
+	secretHash, err := hashAPIKey(secret)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to create client secret")
+	}
+	masked := fmt.Sprintf("%s...%s", secret[:len(serviceAccountSecretPrefix)+3], secret[len(secret)-2:])
+	now := time.Now()
+	if err := r.db.SetServiceAccountClientSecret(sa.ID, secretHash, masked, &now); err != nil {
+		r.logger.Error("failed to store client secret", zap.Error(err))
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to create client secret")
+	}
+	return ctx.JSON(http.StatusOK, api.CreateServiceAccountClientSecretResponse{
+		ClientID:     serviceAccountUserID(sa.ID),
+		ClientSecret: secret,
+	})
+}
+
+// DeleteServiceAccountClientSecret godoc
+//
+//	@Summary		Delete service account client secret
+//	@Description	Disables the client credentials grant of the service account and rejects the tokens it issued
+//	@Security		BearerToken
+//	@Tags			service-accounts
+//	@Param			id	path	string	true	"Service account ID"
+//	@Success		200
+//	@Router			/auth/api/v1/service-accounts/{id}/client-secret [delete]
+func (r *httpRoutes) DeleteServiceAccountClientSecret(ctx echo.Context) error {
+	sa, err := r.getServiceAccountParam(ctx)
+	if err != nil {
+		return err
+	}
+	if err := r.db.SetServiceAccountClientSecret(sa.ID, "", "", nil); err != nil {
+		r.logger.Error("failed to delete client secret", zap.Error(err))
+		return err
+	}
+	return ctx.NoContent(http.StatusOK)
+}
+
+// ServiceAccountToken godoc
+//
+//	@Summary		Service account token
+//	@Description	OAuth 2.0 client credentials grant of the service accounts, the client authenticates with the
+//	@Description	client_id and client_secret parameters or with basic auth. The token is a bearer token valid for an hour.
+//	@Tags			service-accounts
+//	@Accept			x-www-form-urlencoded
+//	@Produce		json
+//	@Param			request	body		api.ServiceAccountTokenRequest	true	"Token request"
+//	@Success		200		{object}	api.ServiceAccountTokenResponse
+//	@Router			/auth/api/v1/service-accounts/token [post]
+func (r *httpRoutes) ServiceAccountToken(ctx echo.Context) error {
+	oauthError := func(status int, code string) error {
+		return ctx.JSON(status, map[string]string{"error": code})
+	}
+
+	var req api.ServiceAccountTokenRequest
+	if err := ctx.Bind(&req); err != nil {
+		return oauthError(http.StatusBadRequest, "invalid_request")
+	}
+	if clientID, clientSecret, ok := ctx.Request().BasicAuth(); ok {
+		// the credentials of basic auth are form encoded, see RFC 6749 section 2.3.1
+		if id, err := url.QueryUnescape(clientID); err == nil {
+			clientID = id
+		}
+		if secret, err := url.QueryUnescape(clientSecret); err == nil {
+			clientSecret = secret
+		}
+		req.ClientID, req.ClientSecret = clientID, clientSecret
+	}
+	if req.GrantType != clientCredentialsTokenUse {
+		return oauthError(http.StatusBadRequest, "unsupported_grant_type")
+	}
+	if r.kaytuPrivateKey == nil {
+		return oauthError(http.StatusBadRequest, "unauthorized_client")
+	}
+
+	sa, err := r.authServer.verifyClientSecret(req.ClientID, req.ClientSecret)
+	if err != nil {
+		r.logger.Warn("service account authentication failed", zap.String("clientId", req.ClientID), zap.Error(err))
+		return oauthError(http.StatusUnauthorized, "invalid_client")
+	}
+
+	now := time.Now()
+	u := userClaim{
+		ExternalUserID: serviceAccountUserID(sa.ID),
+		TokenID:        uuid.New().String(),
+		IssuedAt:       now.Unix(),
+		ExpiresAt:      now.Add(serviceAccountTokenTTL).Unix(),
+		TokenUse:       clientCredentialsTokenUse,
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodRS256, &u).SignedString(r.kaytuPrivateKey)
+	if err != nil {
+		r.logger.Error("failed to create token", zap.Error(err))
+		return oauthError(http.StatusInternalServerError, "server_error")
+	}
+	return ctx.JSON(http.StatusOK, api.ServiceAccountTokenResponse{
+		AccessToken: token,
+		TokenType:   "Bearer",
+		ExpiresIn:   int(serviceAccountTokenTTL.Seconds()),
+	})
+}
+
+// ListSessions godoc
+//
+//	@Summary		List active sessions
+//	@Description	Lists the tokens of the users that have been used and have not expired nor been revoked,
+//	@Description	with the time and address they were last used at. API keys are not listed.
+//	@Security		BearerToken
+//	@Tags			sessions
+//	@Produce		json
+//	@Param			userId	query	string	false	"Only lists the sessions of the user"
+//	@Success		200		{array}	api.UserSession
+//	@Router			/auth/api/v1/sessions [get]
+func (r *httpRoutes) ListSessions(ctx echo.Context) error {
+	sessions, err := r.db.ListActiveUserSessions(ctx.QueryParam("userId"))
+	if err != nil {
+		r.logger.Error("failed to list sessions", zap.Error(err))
+		return err
+	}
+	revokedAt, err := r.db.GetSessionsRevokedAt()
+	if err != nil {
+		r.logger.Error("failed to get sessions revocation time", zap.Error(err))
+		return err
+	}
+
+	resp := make([]api.UserSession, 0, len(sessions))
+	for _, session := range sessions {
+		if revokedAt != nil && session.IssuedAt != nil && session.IssuedAt.Before(*revokedAt) {
+			continue
+		}
+		resp = append(resp, api.UserSession{
+			TokenID:     session.TokenID,
+			UserID:      session.UserID,
+			Email:       session.Email,
+			IssuedAt:    session.IssuedAt,
+			ExpiresAt:   session.ExpiresAt,
+			FirstSeenAt: session.FirstSeenAt,
+			LastSeenAt:  session.LastSeenAt,
+			SourceIP:    session.SourceIP,
+			UserAgent:   session.UserAgent,
+		})
+	}
+	return ctx.JSON(http.StatusOK, resp)
+}
+
+// RevokeSession godoc
+//
+//	@Summary		Revoke session
+//	@Description	Rejects the token with the given ID until it expires
+//	@Security		BearerToken
+//	@Tags			sessions
+//	@Param			token_id	path	string	true	"Token ID"
+//	@Success		200
+//	@Router			/auth/api/v1/sessions/{token_id} [delete]
+func (r *httpRoutes) RevokeSession(ctx echo.Context) error {
+	tokenID := ctx.Param("token_id")
+	session, err := r.db.GetUserSession(tokenID)
+	if err != nil {
+		r.logger.Error("failed to get session", zap.Error(err))
+		return err
+	}
+	if session == nil {
+		return echo.NewHTTPError(http.StatusNotFound, "session not found")
+	}
+
+	err = r.db.RevokeToken(db.RevokedToken{
+		TokenID:   session.TokenID,
+		UserID:    session.UserID,
+		ExpiresAt: session.ExpiresAt,
+		RevokedAt: time.Now(),
+		RevokedBy: httpserver.GetUserID(ctx),
+	})
+	if err != nil {
+		r.logger.Error("failed to revoke session", zap.Error(err))
+		return err
+	}
+	r.authServer.invalidateSessionState()
+	return ctx.NoContent(http.StatusOK)
+}
+
+// RevokeUserSessions godoc
+//
+//	@Summary		Revoke user sessions
+//	@Description	Rejects the tokens of the user issued until now, the user has to log in again
+//	@Security		BearerToken
+//	@Tags			sessions
+//	@Param			user_id	path	string	true	"User ID"
+//	@Success		200
+//	@Router			/auth/api/v1/user/{user_id}/sessions/revoke [post]
+func (r *httpRoutes) RevokeUserSessions(ctx echo.Context) error {
+	userID, err := url.QueryUnescape(ctx.Param("user_id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid user id")
+	}
+
+	err = r.db.RevokeUserSessions(db.UserSessionRevocation{
+		UserID:    userID,
+		RevokedAt: time.Now(),
+		RevokedBy: httpserver.GetUserID(ctx),
+	})
+	if err != nil {
+		r.logger.Error("failed to revoke user sessions", zap.Error(err))
+		return err
+	}
+	r.authServer.invalidateSessionState()
+	return ctx.NoContent(http.StatusOK)
+}
+
+// RevokeAllSessions godoc
+//
+//	@Summary		Revoke all sessions
+//	@Description	Rejects the tokens of all the users issued until now, including the token of the request.
+//	@Description	API keys and service accounts are not affected.
+//	@Security		BearerToken
+//	@Tags			sessions
+//	@Success		200
+//	@Router			/auth/api/v1/sessions/revoke [post]
+func (r *httpRoutes) RevokeAllSessions(ctx echo.Context) error {
+	if err := r.db.SetSessionsRevokedAt(time.Now()); err != nil {
+		r.logger.Error("failed to revoke sessions", zap.Error(err))
+		return err
+	}
+	r.authServer.invalidateSessionState()
+	return ctx.NoContent(http.StatusOK)
+}
+
+// GetSessionSettings godoc
+//
+//	@Summary		Get session settings
+//	@Security		BearerToken
+//	@Tags			sessions
+//	@Produce		json
+//	@Success		200	{object}	api.SessionSettings
+//	@Router			/auth/api/v1/sessions/settings [get]
+func (r *httpRoutes) GetSessionSettings(ctx echo.Context) error {
+	settings, err := r.db.GetSessionSettings()
+	if err != nil {
+		r.logger.Error("failed to get session settings", zap.Error(err))
+		return err
+	}
+	return ctx.JSON(http.StatusOK, api.SessionSettings{
+		MaxTokenAgeMinutes:     settings.MaxTokenAgeMinutes,
+		LockoutThreshold:       settings.LockoutThreshold,
+		LockoutDurationMinutes: settings.LockoutDurationMinutes,
+		RequireMFA:             settings.RequireMFA,
+	})
+}
+
+// PutSessionSettings godoc
+//
+//	@Summary		Set session settings
+//	@Description	Sets the max token age, the lockout of the local users and the multi-factor requirement.
+//	@Description	Multi-factor authentication can only be required if the identity provider sets the amr claim.
+//	@Security		BearerToken
+//	@Tags			sessions
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		api.SessionSettings	true	"Settings"
+//	@Success		200		{object}	api.SessionSettings
+//	@Router			/auth/api/v1/sessions/settings [put]
+func (r *httpRoutes) PutSessionSettings(ctx echo.Context) error {
+	var req api.SessionSettings
+	if err := bindValidate(ctx, &req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if req.LockoutThreshold > 0 && req.LockoutDurationMinutes == 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "lockout duration is required with a lockout threshold")
+	}
+
+	err := r.db.SetSessionSettings(db.SessionSettings{
+		MaxTokenAgeMinutes:     req.MaxTokenAgeMinutes,
+		LockoutThreshold:       req.LockoutThreshold,
+		LockoutDurationMinutes: req.LockoutDurationMinutes,
+		RequireMFA:             req.RequireMFA,
+	})
+	if err != nil {
+		r.logger.Error("failed to set session settings", zap.Error(err))
+		return err
+	}
+	r.authServer.invalidateSessionState()
+	return ctx.JSON(http.StatusOK, req)
+}
+
+// ListLoginLockouts godoc
+//
+//	@Summary		List locked out users
+//	@Description	Lists the local users locked out after repeated failed logins
+//	@Security		BearerToken
+//	@Tags			sessions
+//	@Produce		json
+//	@Success		200	{array}	api.LoginLockout
+//	@Router			/auth/api/v1/lockouts [get]
+func (r *httpRoutes) ListLoginLockouts(ctx echo.Context) error {
+	lockouts, err := r.db.ListActiveLoginLockouts()
+	if err != nil {
+		r.logger.Error("failed to list lockouts", zap.Error(err))
+		return err
+	}
+	resp := make([]api.LoginLockout, 0, len(lockouts))
+	for _, lockout := range lockouts {
+		resp = append(resp, api.LoginLockout{
+			UserID:         lockout.UserID,
+			FailedAttempts: lockout.FailedAttempts,
+			LastFailedAt:   lockout.LastFailedAt,
+			LockedUntil:    lockout.LockedUntil,
+		})
+	}
+	return ctx.JSON(http.StatusOK, resp)
+}
+
+// DeleteLoginLockout godoc
+//
+//	@Summary		Unlock user
+//	@Description	Clears the failed logins of the user, unlocking it
+//	@Security		BearerToken
+//	@Tags			sessions
+//	@Param			user_id	path	string	true	"User ID"
+//	@Success		200
+//	@Router			/auth/api/v1/lockouts/{user_id} [delete]
+func (r *httpRoutes) DeleteLoginLockout(ctx echo.Context) error {
+	userID, err := url.QueryUnescape(ctx.Param("user_id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid user id")
+	}
+	if err := r.db.ResetLoginLockout(strings.ToLower(userID)); err != nil {
+		r.logger.Error("failed to reset lockout", zap.Error(err))
+		return err
+	}
+	r.authServer.invalidateSessionState()
+	return ctx.NoContent(http.StatusOK)
+}
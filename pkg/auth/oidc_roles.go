@@ -0,0 +1,444 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	api3 "github.com/opengovern/og-util/pkg/api"
+	"github.com/opengovern/og-util/pkg/httpclient"
+	"github.com/opengovern/opengovernance/pkg/auth/api"
+	"github.com/opengovern/opengovernance/pkg/auth/db"
+	metadataClient "github.com/opengovern/opengovernance/pkg/metadata/client"
+	"github.com/opengovern/opengovernance/pkg/metadata/models"
+	"go.uber.org/zap"
+)
+
+const (
+	oidcRoleMappingTTL     = 30 * time.Second
+	allowedEmailDomainsTTL = time.Minute
+)
+
+var (
+	errEmailDomainNotAllowed = errors.New("email domain not allowed")
+	errNoRoleRuleMatches     = errors.New("no role rule matches the token")
+)
+
+// oidcRoleMappingCache keeps the role rules and the allowed email domains for a short time, they are
+// used on every checked request with an ID token. The rules are applied once per token, the outcome is
+// kept until the token expires or the rules are replaced.
+type oidcRoleMappingCache struct {
+	mu        sync.Mutex
+	rules     []db.OidcRoleRule
+	settings  db.OidcRoleMappingSettings
+	expiresAt time.Time
+
+	domains          []string
+	domainsExpiresAt time.Time
+
+	mainWorkspaceID string
+	appliedTokens   map[string]oidcAppliedToken
+	sweptAt         time.Time
+}
+
+type oidcAppliedToken struct {
+	err       error
+	expiresAt time.Time
+}
+
+// appliedToken returns the outcome of applying the rules to the token of the session, if they have been.
+func (s *Server) appliedToken(sessionID string) (oidcAppliedToken, bool) {
+	c := &s.oidcRoleMappingCache
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	applied, ok := c.appliedTokens[sessionID]
+	if !ok || time.Now().After(applied.expiresAt) {
+		return oidcAppliedToken{}, false
+	}
+	return applied, true
+}
+
+func (s *Server) setAppliedToken(sessionID string, user *userClaim, err error) {
+	if sessionID == "" || user.ExpiresAt == 0 {
+		return
+	}
+	c := &s.oidcRoleMappingCache
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	if c.appliedTokens == nil {
+		c.appliedTokens = make(map[string]oidcAppliedToken)
+	}
+	if now.Sub(c.sweptAt) > oidcRoleMappingTTL {
+		for id, applied := range c.appliedTokens {
+			if now.After(applied.expiresAt) {
+				delete(c.appliedTokens, id)
+			}
+		}
+		c.sweptAt = now
+	}
+	c.appliedTokens[sessionID] = oidcAppliedToken{err: err, expiresAt: time.Unix(user.ExpiresAt, 0)}
+}
+
+// mainWorkspaceID returns the ID of the main workspace, it does not change once created.
+func (s *Server) mainWorkspaceID() (string, error) {
+	c := &s.oidcRoleMappingCache
+	c.mu.Lock()
+	id := c.mainWorkspaceID
+	c.mu.Unlock()
+	if id != "" {
+		return id, nil
+	}
+
+	wm, err := s.db.GetWorkspaceMapByName("main")
+	if err != nil {
+		return "", err
+	}
+	c.mu.Lock()
+	c.mainWorkspaceID = wm.ID
+	c.mu.Unlock()
+	return wm.ID, nil
+}
+
+func (s *Server) oidcRoleMapping() ([]db.OidcRoleRule, db.OidcRoleMappingSettings, error) {
+	c := &s.oidcRoleMappingCache
+	c.mu.Lock()
+	rules, settings, expiresAt := c.rules, c.settings, c.expiresAt
+	c.mu.Unlock()
+	if time.Now().Before(expiresAt) {
+		return rules, settings, nil
+	}
+
+	rules, err := s.db.ListOidcRoleRules()
+	if err != nil {
+		return nil, db.OidcRoleMappingSettings{}, err
+	}
+	settings, err = s.db.GetOidcRoleMappingSettings()
+	if err != nil {
+		return nil, db.OidcRoleMappingSettings{}, err
+	}
+
+	c.mu.Lock()
+	c.rules, c.settings, c.expiresAt = rules, settings, time.Now().Add(oidcRoleMappingTTL)
+	c.mu.Unlock()
+	return rules, settings, nil
+}
+
+func (s *Server) invalidateOidcRoleMapping() {
+	s.oidcRoleMappingCache.mu.Lock()
+	s.oidcRoleMappingCache.expiresAt = time.Time{}
+	s.oidcRoleMappingCache.appliedTokens = nil
+	s.oidcRoleMappingCache.mu.Unlock()
+}
+
+// allowedEmailDomains returns the allowed_email_domains of the workspace. The last known value is kept if
+// the metadata service can not be reached.
+func (s *Server) allowedEmailDomains(ctx context.Context) []string {
+	c := &s.oidcRoleMappingCache
+	c.mu.Lock()
+	domains, expiresAt := c.domains, c.domainsExpiresAt
+	c.mu.Unlock()
+	if time.Now().Before(expiresAt) {
+		return domains
+	}
+
+	cnf, err := s.metadataClient.GetConfigMetadata(&httpclient.Context{Ctx: ctx, UserRole: api3.InternalRole},
+		models.MetadataKeyAllowedEmailDomains)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if errors.Is(err, metadataClient.ErrConfigNotFound) {
+		c.domains, c.domainsExpiresAt = nil, time.Now().Add(allowedEmailDomainsTTL)
+		return nil
+	}
+	if err != nil {
+		s.logger.Error("failed to get allowed email domains", zap.Error(err))
+		c.domainsExpiresAt = time.Now().Add(allowedEmailDomainsTTL / 4)
+		return c.domains
+	}
+	c.domains = parseAllowedEmailDomains(cnf.GetValue())
+	c.domainsExpiresAt = time.Now().Add(allowedEmailDomainsTTL)
+	return c.domains
+}
+
+// parseAllowedEmailDomains reads the value of allowed_email_domains, a JSON list or a comma separated list.
+func parseAllowedEmailDomains(value any) []string {
+	var domains []string
+	switch v := value.(type) {
+	case string:
+		if err := json.Unmarshal([]byte(v), &domains); err != nil {
+			domains = strings.Split(v, ",")
+		}
+	case []string:
+		domains = v
+	case []any:
+		for _, d := range v {
+			domains = append(domains, fmt.Sprint(d))
+		}
+	}
+
+	var resp []string
+	for _, domain := range domains {
+		domain = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(domain)), "@")
+		if domain != "" {
+			resp = append(resp, domain)
+		}
+	}
+	return resp
+}
+
+// emailDomainAllowed reports whether the email belongs to one of the domains or their subdomains. Any
+// email is allowed if no domain is set.
+func emailDomainAllowed(email string, domains []string) bool {
+	if len(domains) == 0 {
+		return true
+	}
+	domain := emailDomain(email)
+	for _, allowed := range domains {
+		if domain == allowed || strings.HasSuffix(domain, "."+allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+func emailDomain(email string) string {
+	idx := strings.LastIndex(email, "@")
+	if idx < 0 {
+		return ""
+	}
+	return strings.ToLower(email[idx+1:])
+}
+
+// claimValues returns the values of the claim as strings, nested claims are separated by dots.
+func claimValues(claims map[string]any, name string) []string {
+	var current any = claims
+	for _, part := range strings.Split(name, ".") {
+		m, ok := current.(map[string]any)
+		if !ok {
+			return nil
+		}
+		current = m[part]
+	}
+
+	switch v := current.(type) {
+	case nil:
+		return nil
+	case []any:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			values = append(values, fmt.Sprint(item))
+		}
+		return values
+	case string:
+		return []string{v}
+	default:
+		return []string{fmt.Sprint(v)}
+	}
+}
+
+func matchValue(pattern, value string) bool {
+	pattern, value = strings.ToLower(pattern), strings.ToLower(value)
+	if matched, err := path.Match(pattern, value); err == nil {
+		return matched
+	}
+	return pattern == value
+}
+
+func oidcRuleMatches(rule db.OidcRoleRule, email string, claims map[string]any) bool {
+	var values []string
+	switch api.OidcClaimType(rule.ClaimType) {
+	case api.OidcClaimTypeGroup:
+		values = claimValues(claims, "groups")
+	case api.OidcClaimTypeEmailDomain:
+		values = []string{emailDomain(email)}
+	case api.OidcClaimTypeClaim:
+		values = claimValues(claims, rule.Claim)
+	}
+	for _, value := range values {
+		if matchValue(rule.Value, value) {
+			return true
+		}
+	}
+	return false
+}
+
+type oidcRoleDecision struct {
+	matchedRules []int
+	denied       bool
+	role         api3.Role
+	scope        api.RoleBindingScope
+}
+
+// evaluateOidcRoleRules returns the highest role of the matching rules. The scope is unrestricted if one of
+// the matching rules with that role is, otherwise it is the union of their scopes. Users no rule matches get
+// the default role, they are denied if unmatched users are denied or no default role is set, so a role given
+// by a rule that no longer matches is never kept.
+func evaluateOidcRoleRules(rules []db.OidcRoleRule, settings db.OidcRoleMappingSettings, email string, claims map[string]any) oidcRoleDecision {
+	var decision oidcRoleDecision
+	var scopes []api.RoleBindingScope
+	for i, rule := range rules {
+		if !oidcRuleMatches(rule, email, claims) {
+			continue
+		}
+		decision.matchedRules = append(decision.matchedRules, i)
+		scope := api.RoleBindingScope{
+			ConnectionGroups:      rule.ConnectionGroups,
+			ResourceCollectionIDs: rule.ResourceCollectionIDs,
+			BenchmarkIDs:          rule.BenchmarkIDs,
+		}
+		switch rank := roleRank(rule.Role); {
+		case decision.role == "" || rank > roleRank(decision.role):
+			decision.role = rule.Role
+			scopes = []api.RoleBindingScope{scope}
+		case rank == roleRank(decision.role):
+			scopes = append(scopes, scope)
+		}
+	}
+
+	if len(decision.matchedRules) == 0 {
+		if settings.DenyUnmatched || settings.DefaultRole == "" {
+			decision.denied = true
+			return decision
+		}
+		decision.role = settings.DefaultRole
+		return decision
+	}
+
+	for _, scope := range scopes {
+		if scope.IsEmpty() {
+			return decision
+		}
+	}
+	for _, scope := range scopes {
+		decision.scope.ConnectionGroups = appendUnique(decision.scope.ConnectionGroups, scope.ConnectionGroups...)
+		decision.scope.ResourceCollectionIDs = appendUnique(decision.scope.ResourceCollectionIDs, scope.ResourceCollectionIDs...)
+		decision.scope.BenchmarkIDs = appendUnique(decision.scope.BenchmarkIDs, scope.BenchmarkIDs...)
+	}
+	return decision
+}
+
+func appendUnique(list []string, values ...string) []string {
+	for _, v := range values {
+		if !slices.Contains(list, v) {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+func sameScope(a, b api.RoleBindingScope) bool {
+	return slices.Equal(a.ConnectionGroups, b.ConnectionGroups) &&
+		slices.Equal(a.ResourceCollectionIDs, b.ResourceCollectionIDs) &&
+		slices.Equal(a.BenchmarkIDs, b.BenchmarkIDs)
+}
+
+// applyOidcRoleRules enforces the allowed email domains on users logging in with an ID token and sets
+// their role in the main workspace from the role rules, creating them on their first login. Roles are
+// left as they are while no rule is configured. The rules are evaluated once per token of the session.
+func (s *Server) applyOidcRoleRules(ctx context.Context, user *userClaim, sessionID string) error {
+	if user.idTokenClaims == nil {
+		return nil
+	}
+	if !emailDomainAllowed(user.Email, s.allowedEmailDomains(ctx)) {
+		return errEmailDomainNotAllowed
+	}
+	if applied, ok := s.appliedToken(sessionID); ok {
+		return applied.err
+	}
+
+	rules, settings, err := s.oidcRoleMapping()
+	if err != nil {
+		return fmt.Errorf("role rules: %w", err)
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+	decision := evaluateOidcRoleRules(rules, settings, user.Email, user.idTokenClaims)
+	if decision.denied {
+		s.setAppliedToken(sessionID, user, errNoRoleRuleMatches)
+		return errNoRoleRuleMatches
+	}
+	if err := s.setOidcRole(user, decision); err != nil {
+		return err
+	}
+	s.setAppliedToken(sessionID, user, nil)
+	return nil
+}
+
+// setOidcRole gives the user the role and scope of the decision in the main workspace.
+func (s *Server) setOidcRole(user *userClaim, decision oidcRoleDecision) error {
+	theUser, err := s.auth0Service.GetOrCreateUser(user.ExternalUserID, user.Email)
+	if err != nil {
+		return err
+	}
+	workspaceID, err := s.mainWorkspaceID()
+	if err != nil {
+		return err
+	}
+	metadata := theUser.AppMetadata
+	if metadata.WorkspaceAccess[workspaceID] == decision.role && sameScope(metadata.RoleScopes[workspaceID], decision.scope) {
+		return nil
+	}
+
+	if metadata.WorkspaceAccess == nil {
+		metadata.WorkspaceAccess = map[string]api3.Role{}
+	}
+	metadata.WorkspaceAccess[workspaceID] = decision.role
+	if metadata.RoleScopes == nil {
+		metadata.RoleScopes = map[string]api.RoleBindingScope{}
+	}
+	if decision.scope.IsEmpty() {
+		delete(metadata.RoleScopes, workspaceID)
+	} else {
+		metadata.RoleScopes[workspaceID] = decision.scope
+	}
+	if err := s.auth0Service.PatchUserAppMetadata(theUser.UserId, metadata, nil); err != nil {
+		return err
+	}
+	s.logger.Info("user role set from id token",
+		zap.String("email", user.Email),
+		zap.String("role", string(decision.role)),
+		zap.Ints("matchedRules", decision.matchedRules))
+	return nil
+}
+
+// oidcRoleDryRun shows what applyOidcRoleRules would do for the claims of an ID token.
+func (s *Server) oidcRoleDryRun(ctx context.Context, claims map[string]any) (api.OidcRoleDryRunResponse, error) {
+	var res api.OidcRoleDryRunResponse
+	if values := claimValues(claims, "email"); len(values) > 0 {
+		res.Email = strings.ToLower(strings.TrimSpace(values[0]))
+	}
+	res.Groups = claimValues(claims, "groups")
+
+	res.EmailDomainAllowed = emailDomainAllowed(res.Email, s.allowedEmailDomains(ctx))
+	if !res.EmailDomainAllowed {
+		res.Denied = true
+		res.Reason = errEmailDomainNotAllowed.Error()
+	}
+
+	rules, settings, err := s.oidcRoleMapping()
+	if err != nil {
+		return res, err
+	}
+	res.RulesConfigured = len(rules) > 0
+	if !res.RulesConfigured {
+		return res, nil
+	}
+	decision := evaluateOidcRoleRules(rules, settings, res.Email, claims)
+	res.MatchedRules = decision.matchedRules
+	if decision.denied {
+		res.Denied = true
+		res.Reason = errNoRoleRuleMatches.Error()
+	}
+	if !res.Denied {
+		res.Role = decision.role
+		res.RoleBindingScope = decision.scope
+	}
+	return res, nil
+}
@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	api2 "github.com/opengovern/og-util/pkg/api"
+	"github.com/opengovern/opengovernance/pkg/auth/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmailDomainAllowed(t *testing.T) {
+	assert.True(t, emailDomainAllowed("alice@example.com", nil))
+	assert.Equal(t, []string{"example.com", "corp.io"}, parseAllowedEmailDomains(`["Example.com", "@corp.io"]`))
+	assert.Equal(t, []string{"example.com", "corp.io"}, parseAllowedEmailDomains("example.com, corp.io"))
+
+	domains := parseAllowedEmailDomains([]any{"example.com"})
+	assert.True(t, emailDomainAllowed("alice@Example.com", domains))
+	assert.True(t, emailDomainAllowed("alice@eu.example.com", domains))
+	assert.False(t, emailDomainAllowed("alice@badexample.com", domains))
+	assert.False(t, emailDomainAllowed("alice", domains))
+}
+
+func TestEvaluateOidcRoleRules(t *testing.T) {
+	rules := []db.OidcRoleRule{
+		{ClaimType: "group", Value: "kaytu-admins", Role: api2.AdminRole},
+		{ClaimType: "group", Value: "team-*", Role: api2.EditorRole, ConnectionGroups: []string{"prod"}},
+		{ClaimType: "claim", Claim: "org.department", Value: "finance", Role: api2.EditorRole, BenchmarkIDs: []string{"cis"}},
+		{ClaimType: "email_domain", Value: "contractor.io", Role: api2.ViewerRole},
+	}
+	settings := db.OidcRoleMappingSettings{DefaultRole: api2.ViewerRole}
+
+	decision := evaluateOidcRoleRules(rules, settings, "a@example.com", map[string]any{
+		"groups": []any{"team-data", "kaytu-admins"},
+	})
+	assert.Equal(t, api2.AdminRole, decision.role)
+	assert.True(t, decision.scope.IsEmpty())
+	assert.Equal(t, []int{0, 1}, decision.matchedRules)
+
+	decision = evaluateOidcRoleRules(rules, settings, "a@example.com", map[string]any{
+		"groups": []any{"Team-Data"},
+		"org":    map[string]any{"department": "finance"},
+	})
+	assert.Equal(t, api2.EditorRole, decision.role)
+	assert.Equal(t, []string{"prod"}, decision.scope.ConnectionGroups)
+	assert.Equal(t, []string{"cis"}, decision.scope.BenchmarkIDs)
+
+	decision = evaluateOidcRoleRules(rules, settings, "a@example.com", map[string]any{})
+	assert.False(t, decision.denied)
+	assert.Equal(t, api2.ViewerRole, decision.role)
+
+	// without a default role, users the rules no longer match do not keep the role they gave
+	decision = evaluateOidcRoleRules(rules, db.OidcRoleMappingSettings{}, "a@example.com", map[string]any{})
+	assert.True(t, decision.denied)
+	assert.Empty(t, decision.role)
+
+	settings.DenyUnmatched = true
+	assert.True(t, evaluateOidcRoleRules(rules, settings, "a@example.com", nil).denied)
+	assert.False(t, evaluateOidcRoleRules(rules, settings, "b@contractor.io", nil).denied)
+}
+
+func TestAppliedToken(t *testing.T) {
+	s := &Server{}
+	user := &userClaim{ExpiresAt: time.Now().Add(time.Hour).Unix()}
+	s.setAppliedToken("a", user, nil)
+	s.setAppliedToken("b", user, errNoRoleRuleMatches)
+	s.setAppliedToken("expired", &userClaim{ExpiresAt: time.Now().Add(-time.Minute).Unix()}, nil)
+
+	applied, ok := s.appliedToken("a")
+	assert.True(t, ok)
+	assert.NoError(t, applied.err)
+	applied, ok = s.appliedToken("b")
+	assert.True(t, ok)
+	assert.ErrorIs(t, applied.err, errNoRoleRuleMatches)
+	_, ok = s.appliedToken("expired")
+	assert.False(t, ok)
+
+	s.invalidateOidcRoleMapping()
+	_, ok = s.appliedToken("a")
+	assert.False(t, ok)
+}
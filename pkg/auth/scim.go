@@ -0,0 +1,525 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	dexApi "github.com/dexidp/dex/api/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgtype"
+	api2 "github.com/opengovern/og-util/pkg/api"
+	"github.com/opengovern/opengovernance/pkg/auth/auth0"
+	"github.com/opengovern/opengovernance/pkg/auth/db"
+	"github.com/opengovern/opengovernance/pkg/auth/scim"
+	"go.uber.org/zap"
+)
+
+const (
+	scimBasePath    = "/auth/scim/v2"
+	scimTokenPrefix = "scim_"
+	// scimUserID is the user id set on the requests of the SCIM client
+	scimUserID = "scim"
+)
+
+// scimBaseURL returns the address the SCIM client reaches the endpoints at.
+func scimBaseURL() string {
+	if kaytuHost == "" {
+		return scimBasePath
+	}
+	host := strings.TrimSuffix(kaytuHost, "/")
+	if !strings.HasPrefix(host, "http://") && !strings.HasPrefix(host, "https://") {
+		host = "https://" + host
+	}
+	return host + scimBasePath
+}
+
+func newScimToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return scimTokenPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// verifyScimToken reports whether the token is the SCIM token of the workspace.
+func (s *Server) verifyScimToken(token string) bool {
+	if !strings.HasPrefix(token, scimTokenPrefix) {
+		return false
+	}
+	stored, err := s.db.GetScimTokenHash()
+	if err != nil {
+		s.logger.Error("failed to get scim token", zap.Error(err))
+		return false
+	}
+	if stored == "" {
+		return false
+	}
+	hash, err := hashAPIKey(token)
+	if err != nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(hash), []byte(stored)) == 1
+}
+
+// scimStore keeps the SCIM users as db.User and the groups as db.ScimGroup. The members of the groups
+// get the highest role mapped to their groups, see syncUserRoles.
+type scimStore struct {
+	logger *zap.Logger
+	db     db.Database
+}
+
+func (st *scimStore) ListUsers(_ context.Context) ([]scim.User, error) {
+	users, err := st.db.ListUsers()
+	if err != nil {
+		return nil, err
+	}
+	groups, err := st.db.ListScimGroups()
+	if err != nil {
+		return nil, err
+	}
+	resp := make([]scim.User, 0, len(users))
+	for _, user := range users {
+		resp = append(resp, toScimUser(user, groups))
+	}
+	return resp, nil
+}
+
+func (st *scimStore) GetUser(_ context.Context, id string) (*scim.User, error) {
+	user, err := st.getUser(id)
+	if err != nil {
+		return nil, err
+	}
+	groups, err := st.db.ListScimGroupsByMember(id)
+	if err != nil {
+		return nil, err
+	}
+	resp := toScimUser(*user, groups)
+	return &resp, nil
+}
+
+func (st *scimStore) getUser(id string) (*db.User, error) {
+	userUuid, err := uuid.Parse(id)
+	if err != nil {
+		return nil, scim.ErrNotFound
+	}
+	user, err := st.db.GetUserByUuid(userUuid)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, scim.ErrNotFound
+	}
+	return user, nil
+}
+
+func (st *scimStore) CreateUser(ctx context.Context, user scim.User) (*scim.User, error) {
+	email := strings.ToLower(strings.TrimSpace(user.PrimaryEmail()))
+	existing, err := st.db.GetUsersByEmail(email)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) > 0 {
+		return nil, fmt.Errorf("%w: user %s", scim.ErrConflict, email)
+	}
+
+	wm, err := st.db.GetWorkspaceMapByName("main")
+	if err != nil {
+		return nil, err
+	}
+	memberSince := time.Now().Format("2006-01-02 15:00:00 MST")
+	appMetadata, err := json.Marshal(auth0.Metadata{
+		WorkspaceAccess: map[string]api2.Role{wm.ID: api2.ViewerRole},
+		MemberSince:     &memberSince,
+	})
+	if err != nil {
+		return nil, err
+	}
+	appMetadataJsonb := pgtype.JSONB{}
+	if err := appMetadataJsonb.Set(appMetadata); err != nil {
+		return nil, err
+	}
+	userMetadataJsonb := pgtype.JSONB{}
+	if err := userMetadataJsonb.Set([]byte("")); err != nil {
+		return nil, err
+	}
+
+	newUser := &db.User{
+		UserUuid:     uuid.New(),
+		Email:        email,
+		Username:     email,
+		IdLifecycle:  db.UserLifecycleActive,
+		Role:         api2.ViewerRole,
+		UserId:       fmt.Sprintf("dex|%s", email),
+		AppMetadata:  appMetadataJsonb,
+		UserMetadata: userMetadataJsonb,
+	}
+	setScimUserAttributes(newUser, user)
+	if err := st.db.CreateUser(newUser); err != nil {
+		return nil, err
+	}
+	st.logger.Info("user provisioned through scim", zap.String("email", email))
+
+	return st.GetUser(ctx, newUser.UserUuid.String())
+}
+
+func (st *scimStore) ReplaceUser(ctx context.Context, user scim.User) (*scim.User, error) {
+	existing, err := st.getUser(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	email := strings.ToLower(strings.TrimSpace(user.PrimaryEmail()))
+	if email != existing.Email {
+		return nil, scim.NewError(http.StatusBadRequest, scim.ScimTypeMutability, "the email of a user can not be changed")
+	}
+
+	wasDisabled := existing.Disabled
+	setScimUserAttributes(existing, user)
+	if err := st.db.UpdateUser(existing); err != nil {
+		return nil, err
+	}
+	if !wasDisabled && existing.Disabled {
+		err := st.db.RevokeUserSessions(db.UserSessionRevocation{UserID: existing.UserId, RevokedAt: time.Now(), RevokedBy: scimUserID})
+		if err != nil {
+			return nil, err
+		}
+		st.logger.Info("user deactivated through scim", zap.String("email", email))
+	}
+
+	return st.GetUser(ctx, user.ID)
+}
+
+func setScimUserAttributes(dbUser *db.User, user scim.User) {
+	dbUser.ExternalId = user.ExternalID
+	dbUser.Disabled = !user.IsActive()
+	dbUser.Name = dbUser.Email
+	dbUser.GivenName, dbUser.FamilyName = "", ""
+	if user.Name != nil {
+		dbUser.GivenName = user.Name.GivenName
+		dbUser.FamilyName = user.Name.FamilyName
+		if user.Name.Formatted != "" {
+			dbUser.Name = user.Name.Formatted
+		}
+	}
+	if user.DisplayName != "" {
+		dbUser.Name = user.DisplayName
+	}
+}
+
+// DeleteUser removes the user along with its password and API keys.
+func (st *scimStore) DeleteUser(_ context.Context, id string) error {
+	user, err := st.getUser(id)
+	if err != nil {
+		return err
+	}
+
+	if user.Connector == "local" {
+		dexClient, err := newDexClient(dexGrpcAddress)
+		if err != nil {
+			return fmt.Errorf("dex client: %w", err)
+		}
+		if _, err := dexClient.DeletePassword(context.TODO(), &dexApi.DeletePasswordReq{Email: user.Email}); err != nil {
+			return fmt.Errorf("delete dex password: %w", err)
+		}
+	}
+	if err := st.db.RevokeAllUserAPIKeys(user.UserId); err != nil {
+		return err
+	}
+	if err := st.db.RemoveScimGroupMember(id); err != nil {
+		return err
+	}
+	if err := st.db.DeleteUser(user.UserId); err != nil {
+		return err
+	}
+	if err := st.db.RevokeUserSessions(db.UserSessionRevocation{UserID: user.UserId, RevokedAt: time.Now(), RevokedBy: scimUserID}); err != nil {
+		return err
+	}
+	st.logger.Info("user deprovisioned through scim", zap.String("email", user.Email))
+	return nil
+}
+
+func (st *scimStore) ListGroups(_ context.Context) ([]scim.Group, error) {
+	groups, err := st.db.ListScimGroups()
+	if err != nil {
+		return nil, err
+	}
+	users, err := st.usersByUuid()
+	if err != nil {
+		return nil, err
+	}
+	resp := make([]scim.Group, 0, len(groups))
+	for _, group := range groups {
+		resp = append(resp, toScimGroup(group, users))
+	}
+	return resp, nil
+}
+
+func (st *scimStore) GetGroup(_ context.Context, id string) (*scim.Group, error) {
+	group, err := st.db.GetScimGroup(id)
+	if err != nil {
+		return nil, err
+	}
+	if group == nil {
+		return nil, scim.ErrNotFound
+	}
+	users, err := st.usersByUuid()
+	if err != nil {
+		return nil, err
+	}
+	resp := toScimGroup(*group, users)
+	return &resp, nil
+}
+
+func (st *scimStore) CreateGroup(ctx context.Context, group scim.Group) (*scim.Group, error) {
+	existing, err := st.db.GetScimGroupByName(group.DisplayName)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("%w: group %s", scim.ErrConflict, group.DisplayName)
+	}
+	members, err := st.memberIDs(group.Members)
+	if err != nil {
+		return nil, err
+	}
+
+	dbGroup := &db.ScimGroup{
+		ID:          uuid.New().String(),
+		DisplayName: group.DisplayName,
+		ExternalID:  group.ExternalID,
+		Members:     members,
+	}
+	if err := st.db.CreateScimGroup(dbGroup); err != nil {
+		return nil, err
+	}
+	if err := st.syncUserRoles(members); err != nil {
+		return nil, err
+	}
+	return st.GetGroup(ctx, dbGroup.ID)
+}
+
+func (st *scimStore) ReplaceGroup(ctx context.Context, group scim.Group) (*scim.Group, error) {
+	dbGroup, err := st.db.GetScimGroup(group.ID)
+	if err != nil {
+		return nil, err
+	}
+	if dbGroup == nil {
+		return nil, scim.ErrNotFound
+	}
+	if group.DisplayName != dbGroup.DisplayName {
+		existing, err := st.db.GetScimGroupByName(group.DisplayName)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			return nil, fmt.Errorf("%w: group %s", scim.ErrConflict, group.DisplayName)
+		}
+	}
+	members, err := st.memberIDs(group.Members)
+	if err != nil {
+		return nil, err
+	}
+
+	affected := append(append([]string{}, dbGroup.Members...), members...)
+	dbGroup.DisplayName = group.DisplayName
+	dbGroup.ExternalID = group.ExternalID
+	dbGroup.Members = members
+	if err := st.db.UpdateScimGroup(dbGroup); err != nil {
+		return nil, err
+	}
+	if err := st.syncUserRoles(affected); err != nil {
+		return nil, err
+	}
+	return st.GetGroup(ctx, dbGroup.ID)
+}
+
+func (st *scimStore) DeleteGroup(_ context.Context, id string) error {
+	dbGroup, err := st.db.GetScimGroup(id)
+	if err != nil {
+		return err
+	}
+	if dbGroup == nil {
+		return scim.ErrNotFound
+	}
+	if err := st.db.DeleteScimGroup(id); err != nil {
+		return err
+	}
+	return st.syncUserRoles(dbGroup.Members)
+}
+
+// memberIDs returns the ids of the members, which must be existing users.
+func (st *scimStore) memberIDs(members []scim.Reference) ([]string, error) {
+	ids := make([]string, 0, len(members))
+	seen := make(map[string]bool)
+	for _, member := range members {
+		if seen[member.Value] {
+			continue
+		}
+		seen[member.Value] = true
+		if _, err := st.getUser(member.Value); err != nil {
+			if errors.Is(err, scim.ErrNotFound) {
+				return nil, scim.NewError(http.StatusBadRequest, scim.ScimTypeInvalidValue,
+					fmt.Sprintf("member %s is not a user", member.Value))
+			}
+			return nil, err
+		}
+		ids = append(ids, member.Value)
+	}
+	return ids, nil
+}
+
+func (st *scimStore) usersByUuid() (map[string]db.User, error) {
+	users, err := st.db.ListUsers()
+	if err != nil {
+		return nil, err
+	}
+	resp := make(map[string]db.User, len(users))
+	for _, user := range users {
+		resp[user.UserUuid.String()] = user
+	}
+	return resp, nil
+}
+
+// syncUserRoles sets the role of the users in the main workspace to the highest role mapped to their
+// groups, or viewer if none of their groups is mapped. Nothing is changed until a mapping is configured,
+// so that pushing groups does not demote anyone by itself.
+func (st *scimStore) syncUserRoles(userUuids []string) error {
+	mappings, err := st.db.ListScimGroupRoles()
+	if err != nil {
+		return err
+	}
+	if len(mappings) == 0 {
+		return nil
+	}
+	groupRoles := make(map[string]api2.Role)
+	for _, m := range mappings {
+		groupRoles[strings.ToLower(m.GroupName)] = m.Role
+	}
+	wm, err := st.db.GetWorkspaceMapByName("main")
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool)
+	for _, id := range userUuids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		user, err := st.getUser(id)
+		if errors.Is(err, scim.ErrNotFound) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		groups, err := st.db.ListScimGroupsByMember(id)
+		if err != nil {
+			return err
+		}
+		role := api2.ViewerRole
+		for _, group := range groups {
+			if r, ok := groupRoles[strings.ToLower(group.DisplayName)]; ok && roleRank(r) > roleRank(role) {
+				role = r
+			}
+		}
+
+		var metadata auth0.Metadata
+		if len(user.AppMetadata.Bytes) > 0 {
+			if err := json.Unmarshal(user.AppMetadata.Bytes, &metadata); err != nil {
+				return err
+			}
+		}
+		if user.Role == role && metadata.WorkspaceAccess[wm.ID] == role {
+			continue
+		}
+		if metadata.WorkspaceAccess == nil {
+			metadata.WorkspaceAccess = map[string]api2.Role{}
+		}
+		metadata.WorkspaceAccess[wm.ID] = role
+		appMetadata, err := json.Marshal(metadata)
+		if err != nil {
+			return err
+		}
+		if err := user.AppMetadata.Set(appMetadata); err != nil {
+			return err
+		}
+		user.Role = role
+		if err := st.db.UpdateUser(user); err != nil {
+			return err
+		}
+		st.logger.Info("user role set from scim groups", zap.String("email", user.Email), zap.String("role", string(role)))
+	}
+	return nil
+}
+
+// syncAllUserRoles applies the group to role mapping to the members of all the groups.
+func (st *scimStore) syncAllUserRoles() error {
+	groups, err := st.db.ListScimGroups()
+	if err != nil {
+		return err
+	}
+	var members []string
+	for _, group := range groups {
+		members = append(members, group.Members...)
+	}
+	return st.syncUserRoles(members)
+}
+
+func toScimUser(user db.User, groups []db.ScimGroup) scim.User {
+	id := user.UserUuid.String()
+	active := !user.Disabled
+	created, lastModified := user.CreatedAt, user.UpdatedAt
+	resp := scim.User{
+		ID:          id,
+		ExternalID:  user.ExternalId,
+		UserName:    user.Email,
+		DisplayName: user.Name,
+		Emails:      []scim.Email{{Value: user.Email, Type: "work", Primary: true}},
+		Active:      &active,
+		Meta: &scim.Meta{
+			Created:      &created,
+			LastModified: &lastModified,
+		},
+	}
+	if user.GivenName != "" || user.FamilyName != "" {
+		resp.Name = &scim.Name{
+			GivenName:  user.GivenName,
+			FamilyName: user.FamilyName,
+			Formatted:  strings.TrimSpace(user.GivenName + " " + user.FamilyName),
+		}
+	}
+	for _, group := range groups {
+		for _, member := range group.Members {
+			if member == id {
+				resp.Groups = append(resp.Groups, scim.Reference{Value: group.ID, Display: group.DisplayName})
+				break
+			}
+		}
+	}
+	return resp
+}
+
+func toScimGroup(group db.ScimGroup, users map[string]db.User) scim.Group {
+	created, lastModified := group.CreatedAt, group.UpdatedAt
+	resp := scim.Group{
+		ID:          group.ID,
+		ExternalID:  group.ExternalID,
+		DisplayName: group.DisplayName,
+		Meta: &scim.Meta{
+			Created:      &created,
+			LastModified: &lastModified,
+		},
+	}
+	for _, member := range group.Members {
+		resp.Members = append(resp.Members, scim.Reference{Value: member, Display: users[member].Email})
+	}
+	return resp
+}
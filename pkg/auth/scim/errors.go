@@ -0,0 +1,62 @@
+package scim
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+)
+
+const (
+	ScimTypeInvalidFilter = "invalidFilter"
+	ScimTypeInvalidPath   = "invalidPath"
+	ScimTypeInvalidSyntax = "invalidSyntax"
+	ScimTypeInvalidValue  = "invalidValue"
+	ScimTypeMutability    = "mutability"
+	ScimTypeNoTarget      = "noTarget"
+	ScimTypeUniqueness    = "uniqueness"
+)
+
+var (
+	// ErrNotFound is returned by the store when the resource does not exist.
+	ErrNotFound = errors.New("resource not found")
+	// ErrConflict is returned by the store when a unique attribute is already taken.
+	ErrConflict = errors.New("resource already exists")
+)
+
+// Error is the error response of the SCIM protocol.
+type Error struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	ScimType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail,omitempty"`
+
+	code int
+}
+
+func NewError(code int, scimType, detail string) *Error {
+	return &Error{
+		Schemas:  []string{SchemaError},
+		Status:   strconv.Itoa(code),
+		ScimType: scimType,
+		Detail:   detail,
+		code:     code,
+	}
+}
+
+func (e *Error) Error() string {
+	if e.ScimType != "" {
+		return e.ScimType + ": " + e.Detail
+	}
+	return e.Detail
+}
+
+func (e *Error) Code() int {
+	if e.code == 0 {
+		return http.StatusInternalServerError
+	}
+	return e.code
+}
+
+func badRequest(scimType, detail string) *Error {
+	return NewError(http.StatusBadRequest, scimType, detail)
+}
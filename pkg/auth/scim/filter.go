@@ -0,0 +1,435 @@
+package scim
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Filter is a parsed SCIM filter expression (RFC 7644 section 3.4.2.2), evaluated against the JSON
+// representation of a resource.
+type Filter interface {
+	Matches(resource map[string]any) bool
+}
+
+type andFilter struct{ left, right Filter }
+
+func (f andFilter) Matches(r map[string]any) bool { return f.left.Matches(r) && f.right.Matches(r) }
+
+type orFilter struct{ left, right Filter }
+
+func (f orFilter) Matches(r map[string]any) bool { return f.left.Matches(r) || f.right.Matches(r) }
+
+type notFilter struct{ filter Filter }
+
+func (f notFilter) Matches(r map[string]any) bool { return !f.filter.Matches(r) }
+
+type attrFilter struct {
+	path  []string
+	op    string
+	value any
+}
+
+func (f attrFilter) Matches(r map[string]any) bool {
+	values := lookup(r, f.path)
+	switch f.op {
+	case "pr":
+		for _, v := range values {
+			if present(v) {
+				return true
+			}
+		}
+		return false
+	case "ne":
+		for _, v := range values {
+			if compare(v, "eq", f.value) {
+				return false
+			}
+		}
+		return true
+	}
+	for _, v := range values {
+		if compare(v, f.op, f.value) {
+			return true
+		}
+	}
+	return false
+}
+
+// valuePathFilter matches if an element of the multi-valued attribute matches the inner filter,
+// e.g. emails[type eq "work"].
+type valuePathFilter struct {
+	attr   string
+	filter Filter
+}
+
+func (f valuePathFilter) Matches(r map[string]any) bool {
+	for _, element := range lookup(r, []string{f.attr}) {
+		if m, ok := element.(map[string]any); ok && f.filter.Matches(m) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseFilter parses a filter expression, returning an invalidFilter error if it is malformed.
+func ParseFilter(expr string) (Filter, error) {
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return nil, badRequest(ScimTypeInvalidFilter, err.Error())
+	}
+	p := &filterParser{tokens: tokens}
+	f, err := p.parseOr()
+	if err == nil && p.pos < len(p.tokens) {
+		err = fmt.Errorf("unexpected %q", p.tokens[p.pos].text)
+	}
+	if err != nil {
+		return nil, badRequest(ScimTypeInvalidFilter, err.Error())
+	}
+	return f, nil
+}
+
+type tokenKind int
+
+const (
+	tokenWord tokenKind = iota
+	tokenString
+	tokenOpenParen
+	tokenCloseParen
+	tokenOpenBracket
+	tokenCloseBracket
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+func tokenize(expr string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+		case c == '(':
+			tokens = append(tokens, token{kind: tokenOpenParen, text: "("})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{kind: tokenCloseParen, text: ")"})
+			i++
+		case c == '[':
+			tokens = append(tokens, token{kind: tokenOpenBracket, text: "["})
+			i++
+		case c == ']':
+			tokens = append(tokens, token{kind: tokenCloseBracket, text: "]"})
+			i++
+		case c == '"':
+			j := i + 1
+			for ; j < len(expr); j++ {
+				if expr[j] == '\\' {
+					j++
+					continue
+				}
+				if expr[j] == '"' {
+					break
+				}
+			}
+			if j >= len(expr) {
+				return nil, fmt.Errorf("unterminated string at %d", i)
+			}
+			var s string
+			if err := json.Unmarshal([]byte(expr[i:j+1]), &s); err != nil {
+				return nil, fmt.Errorf("invalid string at %d: %v", i, err)
+			}
+			tokens = append(tokens, token{kind: tokenString, text: s})
+			i = j + 1
+		default:
+			j := i
+			for j < len(expr) && !strings.ContainsRune(" \t\n()[]\"", rune(expr[j])) {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokenWord, text: expr[i:j]})
+			i = j
+		}
+	}
+	return tokens, nil
+}
+
+type filterParser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *filterParser) peek() *token {
+	if p.pos < len(p.tokens) {
+		return &p.tokens[p.pos]
+	}
+	return nil
+}
+
+func (p *filterParser) next() (token, error) {
+	if p.pos >= len(p.tokens) {
+		return token{}, fmt.Errorf("unexpected end of filter")
+	}
+	t := p.tokens[p.pos]
+	p.pos++
+	return t, nil
+}
+
+func (p *filterParser) peekKeyword(keyword string) bool {
+	t := p.peek()
+	return t != nil && t.kind == tokenWord && strings.EqualFold(t.text, keyword)
+}
+
+func (p *filterParser) expect(kind tokenKind, text string) error {
+	t, err := p.next()
+	if err != nil {
+		return err
+	}
+	if t.kind != kind {
+		return fmt.Errorf("expected %q, got %q", text, t.text)
+	}
+	return nil
+}
+
+func (p *filterParser) parseOr() (Filter, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekKeyword("or") {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orFilter{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (Filter, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekKeyword("and") {
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andFilter{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseUnary() (Filter, error) {
+	if p.peekKeyword("not") {
+		p.pos++
+		if err := p.expect(tokenOpenParen, "("); err != nil {
+			return nil, err
+		}
+		f, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokenCloseParen, ")"); err != nil {
+			return nil, err
+		}
+		return notFilter{filter: f}, nil
+	}
+
+	t, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	switch t.kind {
+	case tokenOpenParen:
+		f, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokenCloseParen, ")"); err != nil {
+			return nil, err
+		}
+		return f, nil
+	case tokenWord:
+	default:
+		return nil, fmt.Errorf("expected attribute, got %q", t.text)
+	}
+
+	path := attributePath(t.text)
+	if next := p.peek(); next != nil && next.kind == tokenOpenBracket {
+		if len(path) != 1 {
+			return nil, fmt.Errorf("invalid value path %q", t.text)
+		}
+		p.pos++
+		f, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokenCloseBracket, "]"); err != nil {
+			return nil, err
+		}
+		return valuePathFilter{attr: path[0], filter: f}, nil
+	}
+
+	opToken, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	op := strings.ToLower(opToken.text)
+	switch op {
+	case "pr":
+		return attrFilter{path: path, op: op}, nil
+	case "eq", "ne", "co", "sw", "ew", "gt", "ge", "lt", "le":
+	default:
+		return nil, fmt.Errorf("unsupported operator %q", opToken.text)
+	}
+
+	valueToken, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	value, err := filterValue(valueToken)
+	if err != nil {
+		return nil, err
+	}
+	return attrFilter{path: path, op: op, value: value}, nil
+}
+
+func filterValue(t token) (any, error) {
+	switch t.kind {
+	case tokenString:
+		return t.text, nil
+	case tokenWord:
+		switch strings.ToLower(t.text) {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null":
+			return nil, nil
+		}
+		if n, err := strconv.ParseFloat(t.text, 64); err == nil {
+			return n, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid value %q", t.text)
+}
+
+// attributePath splits an attribute name into its sub-attributes, dropping the schema URN prefix if any.
+func attributePath(name string) []string {
+	if idx := strings.LastIndex(name, ":"); idx >= 0 {
+		name = name[idx+1:]
+	}
+	return strings.Split(name, ".")
+}
+
+// lookup returns the values at the path, flattening multi-valued attributes. Attribute names are
+// case-insensitive.
+func lookup(r map[string]any, path []string) []any {
+	current := []any{r}
+	for _, name := range path {
+		var next []any
+		for _, v := range current {
+			m, ok := v.(map[string]any)
+			if !ok {
+				continue
+			}
+			key, ok := findKey(m, name)
+			if !ok {
+				continue
+			}
+			if list, ok := m[key].([]any); ok {
+				next = append(next, list...)
+			} else {
+				next = append(next, m[key])
+			}
+		}
+		current = next
+	}
+	return current
+}
+
+func findKey(m map[string]any, name string) (string, bool) {
+	if _, ok := m[name]; ok {
+		return name, true
+	}
+	for k := range m {
+		if strings.EqualFold(k, name) {
+			return k, true
+		}
+	}
+	return "", false
+}
+
+func present(v any) bool {
+	switch v := v.(type) {
+	case nil:
+		return false
+	case string:
+		return v != ""
+	case []any:
+		return len(v) > 0
+	case map[string]any:
+		return len(v) > 0
+	}
+	return true
+}
+
+func compare(v any, op string, value any) bool {
+	switch value := value.(type) {
+	case nil:
+		return op == "eq" && !present(v)
+	case bool:
+		b, ok := v.(bool)
+		return ok && op == "eq" && b == value
+	case float64:
+		n, ok := v.(float64)
+		if !ok {
+			return false
+		}
+		switch op {
+		case "eq":
+			return n == value
+		case "gt":
+			return n > value
+		case "ge":
+			return n >= value
+		case "lt":
+			return n < value
+		case "le":
+			return n <= value
+		}
+		return false
+	case string:
+		s, ok := v.(string)
+		if !ok {
+			return false
+		}
+		s, value = strings.ToLower(s), strings.ToLower(value)
+		switch op {
+		case "eq":
+			return s == value
+		case "co":
+			return strings.Contains(s, value)
+		case "sw":
+			return strings.HasPrefix(s, value)
+		case "ew":
+			return strings.HasSuffix(s, value)
+		case "gt":
+			return s > value
+		case "ge":
+			return s >= value
+		case "lt":
+			return s < value
+		case "le":
+			return s <= value
+		}
+	}
+	return false
+}
@@ -0,0 +1,242 @@
+package scim
+
+import (
+	"fmt"
+	"strings"
+)
+
+type patchPath struct {
+	attr   string
+	filter Filter
+	sub    string
+}
+
+// parsePatchPath parses the path of a PATCH operation, e.g. "name.givenName", "members" or
+// `emails[type eq "work"].value`.
+func parsePatchPath(path string) (patchPath, error) {
+	head, rest := path, ""
+	if idx := strings.Index(path, "["); idx >= 0 {
+		head, rest = path[:idx], path[idx:]
+	}
+	if idx := strings.LastIndex(head, ":"); idx >= 0 {
+		head = head[idx+1:]
+	}
+
+	if rest == "" {
+		parts := strings.SplitN(head, ".", 2)
+		p := patchPath{attr: parts[0]}
+		if len(parts) == 2 {
+			p.sub = parts[1]
+		}
+		if p.attr == "" {
+			return p, badRequest(ScimTypeInvalidPath, fmt.Sprintf("invalid path %q", path))
+		}
+		return p, nil
+	}
+
+	end := strings.LastIndex(rest, "]")
+	if end < 0 || head == "" {
+		return patchPath{}, badRequest(ScimTypeInvalidPath, fmt.Sprintf("invalid path %q", path))
+	}
+	filter, err := ParseFilter(rest[1:end])
+	if err != nil {
+		return patchPath{}, badRequest(ScimTypeInvalidPath, fmt.Sprintf("invalid path %q: %v", path, err))
+	}
+	p := patchPath{attr: head, filter: filter}
+	if tail := rest[end+1:]; tail != "" {
+		if !strings.HasPrefix(tail, ".") || len(tail) == 1 {
+			return p, badRequest(ScimTypeInvalidPath, fmt.Sprintf("invalid path %q", path))
+		}
+		p.sub = tail[1:]
+	}
+	return p, nil
+}
+
+// applyPatch applies the operation to the JSON representation of a resource.
+func applyPatch(resource map[string]any, operation PatchOperation) error {
+	op := strings.ToLower(operation.Op)
+	switch op {
+	case "add", "replace", "remove":
+	default:
+		return badRequest(ScimTypeInvalidSyntax, fmt.Sprintf("unsupported operation %q", operation.Op))
+	}
+
+	if operation.Path == "" {
+		if op == "remove" {
+			return badRequest(ScimTypeNoTarget, "remove operation requires a path")
+		}
+		values, ok := operation.Value.(map[string]any)
+		if !ok {
+			return badRequest(ScimTypeInvalidValue, "operation without path requires an object value")
+		}
+		for k, v := range values {
+			if err := applyPatch(resource, PatchOperation{Op: op, Path: k, Value: v}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	path, err := parsePatchPath(operation.Path)
+	if err != nil {
+		return err
+	}
+	key, ok := findKey(resource, path.attr)
+	if !ok {
+		key = path.attr
+	}
+
+	if path.filter != nil {
+		return patchFiltered(resource, key, path, op, operation.Value)
+	}
+
+	if path.sub != "" {
+		container, ok := resource[key].(map[string]any)
+		if !ok {
+			if op == "remove" {
+				return nil
+			}
+			container = map[string]any{}
+			resource[key] = container
+		}
+		subKey, ok := findKey(container, path.sub)
+		if !ok {
+			subKey = path.sub
+		}
+		if op == "remove" {
+			delete(container, subKey)
+		} else {
+			container[subKey] = operation.Value
+		}
+		return nil
+	}
+
+	existing, isList := resource[key].([]any)
+	switch op {
+	case "remove":
+		// some clients remove members by value instead of using a filter
+		if values, ok := operation.Value.([]any); ok && isList {
+			resource[key] = removeReferences(existing, values)
+		} else {
+			delete(resource, key)
+		}
+	case "add":
+		values, valueIsList := operation.Value.([]any)
+		if isList || valueIsList {
+			if !valueIsList {
+				values = []any{operation.Value}
+			}
+			resource[key] = appendReferences(existing, values)
+		} else {
+			resource[key] = operation.Value
+		}
+	case "replace":
+		resource[key] = operation.Value
+	}
+	return nil
+}
+
+func patchFiltered(resource map[string]any, key string, path patchPath, op string, value any) error {
+	list, _ := resource[key].([]any)
+	var matched []int
+	for i, element := range list {
+		if m, ok := element.(map[string]any); ok && path.filter.Matches(m) {
+			matched = append(matched, i)
+		}
+	}
+	if len(matched) == 0 {
+		if op == "remove" {
+			return nil
+		}
+		return badRequest(ScimTypeNoTarget, fmt.Sprintf("no value of %s matches the filter", key))
+	}
+
+	if op == "remove" && path.sub == "" {
+		remaining := make([]any, 0, len(list))
+		next := 0
+		for i, element := range list {
+			if next < len(matched) && matched[next] == i {
+				next++
+				continue
+			}
+			remaining = append(remaining, element)
+		}
+		resource[key] = remaining
+		return nil
+	}
+
+	for _, i := range matched {
+		element := list[i].(map[string]any)
+		switch {
+		case path.sub == "" && op != "remove":
+			values, ok := value.(map[string]any)
+			if !ok {
+				return badRequest(ScimTypeInvalidValue, fmt.Sprintf("value of %s must be an object", key))
+			}
+			for k, v := range values {
+				element[k] = v
+			}
+		case op == "remove":
+			if subKey, ok := findKey(element, path.sub); ok {
+				delete(element, subKey)
+			}
+		default:
+			subKey, ok := findKey(element, path.sub)
+			if !ok {
+				subKey = path.sub
+			}
+			element[subKey] = value
+		}
+	}
+	return nil
+}
+
+func referenceValue(v any) (string, bool) {
+	m, ok := v.(map[string]any)
+	if !ok {
+		return "", false
+	}
+	key, ok := findKey(m, "value")
+	if !ok {
+		return "", false
+	}
+	s, ok := m[key].(string)
+	return s, ok
+}
+
+// appendReferences adds the values to the list, skipping the references already in it.
+func appendReferences(list []any, values []any) []any {
+	seen := make(map[string]bool)
+	for _, v := range list {
+		if ref, ok := referenceValue(v); ok {
+			seen[ref] = true
+		}
+	}
+	for _, v := range values {
+		if ref, ok := referenceValue(v); ok {
+			if seen[ref] {
+				continue
+			}
+			seen[ref] = true
+		}
+		list = append(list, v)
+	}
+	return list
+}
+
+func removeReferences(list []any, values []any) []any {
+	removed := make(map[string]bool)
+	for _, v := range values {
+		if ref, ok := referenceValue(v); ok {
+			removed[ref] = true
+		}
+	}
+	remaining := make([]any, 0, len(list))
+	for _, v := range list {
+		if ref, ok := referenceValue(v); ok && removed[ref] {
+			continue
+		}
+		remaining = append(remaining, v)
+	}
+	return remaining
+}
@@ -0,0 +1,503 @@
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	defaultCount = 100
+	maxCount     = 1000
+)
+
+// Store keeps the users and groups provisioned through SCIM. Returned resources have their id, meta
+// timestamps and, for users, their groups set.
+type Store interface {
+	ListUsers(ctx context.Context) ([]User, error)
+	GetUser(ctx context.Context, id string) (*User, error)
+	CreateUser(ctx context.Context, user User) (*User, error)
+	ReplaceUser(ctx context.Context, user User) (*User, error)
+	DeleteUser(ctx context.Context, id string) error
+
+	ListGroups(ctx context.Context) ([]Group, error)
+	GetGroup(ctx context.Context, id string) (*Group, error)
+	CreateGroup(ctx context.Context, group Group) (*Group, error)
+	ReplaceGroup(ctx context.Context, group Group) (*Group, error)
+	DeleteGroup(ctx context.Context, id string) error
+}
+
+// TokenVerifier reports whether the bearer token is allowed to use the SCIM endpoints.
+type TokenVerifier func(token string) bool
+
+// Server serves the Users and Groups resources of SCIM 2.0 (RFC 7644).
+type Server struct {
+	store   Store
+	verify  TokenVerifier
+	baseURL string
+}
+
+// NewServer returns a server backed by the store. baseURL is the address the endpoints are reachable at
+// by the clients, used for the location of the resources.
+func NewServer(store Store, verify TokenVerifier, baseURL string) *Server {
+	return &Server{
+		store:   store,
+		verify:  verify,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+func (s *Server) Register(g *echo.Group) {
+	g.Use(s.authenticate)
+
+	g.GET("/ServiceProviderConfig", s.handle(s.GetServiceProviderConfig))
+	g.GET("/ResourceTypes", s.handle(s.ListResourceTypes))
+
+	g.GET("/Users", s.handle(s.ListUsers))
+	g.POST("/Users", s.handle(s.CreateUser))
+	g.GET("/Users/:id", s.handle(s.GetUser))
+	g.PUT("/Users/:id", s.handle(s.ReplaceUser))
+	g.PATCH("/Users/:id", s.handle(s.PatchUser))
+	g.DELETE("/Users/:id", s.handle(s.DeleteUser))
+
+	g.GET("/Groups", s.handle(s.ListGroups))
+	g.POST("/Groups", s.handle(s.CreateGroup))
+	g.GET("/Groups/:id", s.handle(s.GetGroup))
+	g.PUT("/Groups/:id", s.handle(s.ReplaceGroup))
+	g.PATCH("/Groups/:id", s.handle(s.PatchGroup))
+	g.DELETE("/Groups/:id", s.handle(s.DeleteGroup))
+}
+
+func (s *Server) authenticate(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		token, ok := strings.CutPrefix(ctx.Request().Header.Get(echo.HeaderAuthorization), "Bearer ")
+		if !ok || token == "" || !s.verify(token) {
+			ctx.Response().Header().Set(echo.HeaderWWWAuthenticate, `Bearer realm="scim"`)
+			return writeError(ctx, NewError(http.StatusUnauthorized, "", "invalid bearer token"))
+		}
+		return next(ctx)
+	}
+}
+
+// handle writes the errors of the handler as SCIM errors.
+func (s *Server) handle(h echo.HandlerFunc) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		err := h(ctx)
+		if err == nil {
+			return nil
+		}
+
+		var scimErr *Error
+		switch {
+		case errors.As(err, &scimErr):
+		case errors.Is(err, ErrNotFound):
+			scimErr = NewError(http.StatusNotFound, "", "resource not found")
+		case errors.Is(err, ErrConflict):
+			scimErr = NewError(http.StatusConflict, ScimTypeUniqueness, err.Error())
+		default:
+			ctx.Logger().Error(err)
+			scimErr = NewError(http.StatusInternalServerError, "", "internal error")
+		}
+		return writeError(ctx, scimErr)
+	}
+}
+
+func writeError(ctx echo.Context, err *Error) error {
+	return writeJSON(ctx, err.Code(), err)
+}
+
+func writeJSON(ctx echo.Context, code int, v any) error {
+	ctx.Response().Header().Set(echo.HeaderContentType, MIMEApplicationSCIM)
+	ctx.Response().WriteHeader(code)
+	return json.NewEncoder(ctx.Response()).Encode(v)
+}
+
+func decodeBody(ctx echo.Context, v any) error {
+	if err := json.NewDecoder(ctx.Request().Body).Decode(v); err != nil {
+		return badRequest(ScimTypeInvalidSyntax, fmt.Sprintf("invalid request body: %v", err))
+	}
+	return nil
+}
+
+func (s *Server) GetServiceProviderConfig(ctx echo.Context) error {
+	return writeJSON(ctx, http.StatusOK, ServiceProviderConfig{
+		Schemas: []string{SchemaServiceProviderConfig},
+		Patch:   Supported{Supported: true},
+		Filter:  FilterSupported{Supported: true, MaxResults: maxCount},
+		AuthenticationSchemes: []AuthenticationScheme{{
+			Type:        "oauthbearertoken",
+			Name:        "OAuth Bearer Token",
+			Description: "Authentication with the SCIM token of the workspace",
+			Primary:     true,
+		}},
+	})
+}
+
+func (s *Server) ListResourceTypes(ctx echo.Context) error {
+	resources := []any{
+		ResourceType{Schemas: []string{SchemaResourceType}, ID: "User", Name: "User", Endpoint: "/Users", Schema: SchemaUser},
+		ResourceType{Schemas: []string{SchemaResourceType}, ID: "Group", Name: "Group", Endpoint: "/Groups", Schema: SchemaGroup},
+	}
+	return writeJSON(ctx, http.StatusOK, ListResponse{
+		Schemas:      []string{SchemaListResponse},
+		TotalResults: len(resources),
+		StartIndex:   1,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	})
+}
+
+func (s *Server) ListUsers(ctx echo.Context) error {
+	users, err := s.store.ListUsers(ctx.Request().Context())
+	if err != nil {
+		return err
+	}
+	resources := make([]any, 0, len(users))
+	for _, user := range users {
+		resources = append(resources, s.userResource(user))
+	}
+	return s.list(ctx, resources)
+}
+
+func (s *Server) GetUser(ctx echo.Context) error {
+	user, err := s.store.GetUser(ctx.Request().Context(), ctx.Param("id"))
+	if err != nil {
+		return err
+	}
+	return s.writeResource(ctx, http.StatusOK, s.userResource(*user))
+}
+
+func (s *Server) CreateUser(ctx echo.Context) error {
+	var user User
+	if err := decodeBody(ctx, &user); err != nil {
+		return err
+	}
+	if err := validateUser(user); err != nil {
+		return err
+	}
+	user.ID = ""
+
+	created, err := s.store.CreateUser(ctx.Request().Context(), user)
+	if err != nil {
+		return err
+	}
+	resource := s.userResource(*created)
+	ctx.Response().Header().Set(echo.HeaderLocation, resource.Meta.Location)
+	return s.writeResource(ctx, http.StatusCreated, resource)
+}
+
+func (s *Server) ReplaceUser(ctx echo.Context) error {
+	var user User
+	if err := decodeBody(ctx, &user); err != nil {
+		return err
+	}
+	if err := validateUser(user); err != nil {
+		return err
+	}
+	user.ID = ctx.Param("id")
+
+	replaced, err := s.store.ReplaceUser(ctx.Request().Context(), user)
+	if err != nil {
+		return err
+	}
+	return s.writeResource(ctx, http.StatusOK, s.userResource(*replaced))
+}
+
+func (s *Server) PatchUser(ctx echo.Context) error {
+	var req PatchRequest
+	if err := decodeBody(ctx, &req); err != nil {
+		return err
+	}
+	user, err := s.store.GetUser(ctx.Request().Context(), ctx.Param("id"))
+	if err != nil {
+		return err
+	}
+
+	var patched User
+	if err := patchResource(*user, req.Operations, &patched); err != nil {
+		return err
+	}
+	if err := validateUser(patched); err != nil {
+		return err
+	}
+	patched.ID = user.ID
+
+	replaced, err := s.store.ReplaceUser(ctx.Request().Context(), patched)
+	if err != nil {
+		return err
+	}
+	return s.writeResource(ctx, http.StatusOK, s.userResource(*replaced))
+}
+
+func (s *Server) DeleteUser(ctx echo.Context) error {
+	if err := s.store.DeleteUser(ctx.Request().Context(), ctx.Param("id")); err != nil {
+		return err
+	}
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+func (s *Server) ListGroups(ctx echo.Context) error {
+	groups, err := s.store.ListGroups(ctx.Request().Context())
+	if err != nil {
+		return err
+	}
+	resources := make([]any, 0, len(groups))
+	for _, group := range groups {
+		resources = append(resources, s.groupResource(group))
+	}
+	return s.list(ctx, resources)
+}
+
+func (s *Server) GetGroup(ctx echo.Context) error {
+	group, err := s.store.GetGroup(ctx.Request().Context(), ctx.Param("id"))
+	if err != nil {
+		return err
+	}
+	return s.writeResource(ctx, http.StatusOK, s.groupResource(*group))
+}
+
+func (s *Server) CreateGroup(ctx echo.Context) error {
+	var group Group
+	if err := decodeBody(ctx, &group); err != nil {
+		return err
+	}
+	if group.DisplayName == "" {
+		return badRequest(ScimTypeInvalidValue, "displayName is required")
+	}
+	group.ID = ""
+
+	created, err := s.store.CreateGroup(ctx.Request().Context(), group)
+	if err != nil {
+		return err
+	}
+	resource := s.groupResource(*created)
+	ctx.Response().Header().Set(echo.HeaderLocation, resource.Meta.Location)
+	return s.writeResource(ctx, http.StatusCreated, resource)
+}
+
+func (s *Server) ReplaceGroup(ctx echo.Context) error {
+	var group Group
+	if err := decodeBody(ctx, &group); err != nil {
+		return err
+	}
+	if group.DisplayName == "" {
+		return badRequest(ScimTypeInvalidValue, "displayName is required")
+	}
+	group.ID = ctx.Param("id")
+
+	replaced, err := s.store.ReplaceGroup(ctx.Request().Context(), group)
+	if err != nil {
+		return err
+	}
+	return s.writeResource(ctx, http.StatusOK, s.groupResource(*replaced))
+}
+
+func (s *Server) PatchGroup(ctx echo.Context) error {
+	var req PatchRequest
+	if err := decodeBody(ctx, &req); err != nil {
+		return err
+	}
+	group, err := s.store.GetGroup(ctx.Request().Context(), ctx.Param("id"))
+	if err != nil {
+		return err
+	}
+
+	var patched Group
+	if err := patchResource(*group, req.Operations, &patched); err != nil {
+		return err
+	}
+	if patched.DisplayName == "" {
+		return badRequest(ScimTypeInvalidValue, "displayName is required")
+	}
+	patched.ID = group.ID
+
+	replaced, err := s.store.ReplaceGroup(ctx.Request().Context(), patched)
+	if err != nil {
+		return err
+	}
+	return s.writeResource(ctx, http.StatusOK, s.groupResource(*replaced))
+}
+
+func (s *Server) DeleteGroup(ctx echo.Context) error {
+	if err := s.store.DeleteGroup(ctx.Request().Context(), ctx.Param("id")); err != nil {
+		return err
+	}
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+func validateUser(user User) error {
+	if strings.TrimSpace(user.UserName) == "" {
+		return badRequest(ScimTypeInvalidValue, "userName is required")
+	}
+	return nil
+}
+
+func (s *Server) userResource(user User) User {
+	user.Schemas = []string{SchemaUser}
+	if user.Meta == nil {
+		user.Meta = &Meta{}
+	}
+	user.Meta.ResourceType = "User"
+	user.Meta.Location = fmt.Sprintf("%s/Users/%s", s.baseURL, user.ID)
+	for i := range user.Groups {
+		user.Groups[i].Ref = fmt.Sprintf("%s/Groups/%s", s.baseURL, user.Groups[i].Value)
+	}
+	return user
+}
+
+func (s *Server) groupResource(group Group) Group {
+	group.Schemas = []string{SchemaGroup}
+	if group.Meta == nil {
+		group.Meta = &Meta{}
+	}
+	group.Meta.ResourceType = "Group"
+	group.Meta.Location = fmt.Sprintf("%s/Groups/%s", s.baseURL, group.ID)
+	for i := range group.Members {
+		group.Members[i].Ref = fmt.Sprintf("%s/Users/%s", s.baseURL, group.Members[i].Value)
+	}
+	return group
+}
+
+func (s *Server) writeResource(ctx echo.Context, code int, resource any) error {
+	excluded := excludedAttributes(ctx)
+	if len(excluded) == 0 {
+		return writeJSON(ctx, code, resource)
+	}
+	m, err := toMap(resource)
+	if err != nil {
+		return err
+	}
+	return writeJSON(ctx, code, exclude(m, excluded))
+}
+
+// list filters and pages the resources as requested by the filter, startIndex and count parameters.
+func (s *Server) list(ctx echo.Context, resources []any) error {
+	startIndex, count := 1, defaultCount
+	if v := ctx.QueryParam("startIndex"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return badRequest(ScimTypeInvalidValue, "invalid startIndex")
+		}
+		if n > 1 {
+			startIndex = n
+		}
+	}
+	if v := ctx.QueryParam("count"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return badRequest(ScimTypeInvalidValue, "invalid count")
+		}
+		count = min(max(n, 0), maxCount)
+	}
+
+	var filter Filter
+	if expr := ctx.QueryParam("filter"); expr != "" {
+		f, err := ParseFilter(expr)
+		if err != nil {
+			return err
+		}
+		filter = f
+	}
+	excluded := excludedAttributes(ctx)
+
+	matched := make([]map[string]any, 0, len(resources))
+	for _, resource := range resources {
+		m, err := toMap(resource)
+		if err != nil {
+			return err
+		}
+		if filter == nil || filter.Matches(m) {
+			matched = append(matched, m)
+		}
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		return fmt.Sprint(matched[i]["id"]) < fmt.Sprint(matched[j]["id"])
+	})
+
+	page := make([]any, 0, count)
+	for i := startIndex - 1; i < len(matched) && len(page) < count; i++ {
+		page = append(page, exclude(matched[i], excluded))
+	}
+	return writeJSON(ctx, http.StatusOK, ListResponse{
+		Schemas:      []string{SchemaListResponse},
+		TotalResults: len(matched),
+		StartIndex:   startIndex,
+		ItemsPerPage: len(page),
+		Resources:    page,
+	})
+}
+
+func excludedAttributes(ctx echo.Context) []string {
+	v := ctx.QueryParam("excludedAttributes")
+	if v == "" {
+		return nil
+	}
+	var attrs []string
+	for _, attr := range strings.Split(v, ",") {
+		if attr = strings.TrimSpace(attr); attr != "" {
+			attrs = append(attrs, attributePath(attr)[0])
+		}
+	}
+	return attrs
+}
+
+// exclude drops the attributes from the resource, id and schemas are always returned.
+func exclude(m map[string]any, attrs []string) map[string]any {
+	for _, attr := range attrs {
+		if key, ok := findKey(m, attr); ok && key != "id" && key != "schemas" {
+			delete(m, key)
+		}
+	}
+	return m
+}
+
+func toMap(v any) (map[string]any, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]any
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// patchResource applies the operations to the resource and decodes the result into patched.
+func patchResource(resource any, operations []PatchOperation, patched any) error {
+	m, err := toMap(resource)
+	if err != nil {
+		return err
+	}
+	for _, operation := range operations {
+		if err := applyPatch(m, operation); err != nil {
+			return err
+		}
+	}
+	// some clients send booleans as strings
+	if key, ok := findKey(m, "active"); ok {
+		if v, ok := m[key].(string); ok {
+			active, err := strconv.ParseBool(v)
+			if err != nil {
+				return badRequest(ScimTypeInvalidValue, fmt.Sprintf("invalid active value %q", v))
+			}
+			m[key] = active
+		}
+	}
+
+	b, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, patched); err != nil {
+		return badRequest(ScimTypeInvalidValue, err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,317 @@
+package scim
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+)
+
+const testToken = "scim_test"
+
+type memoryStore struct {
+	mu     sync.Mutex
+	nextID int
+	users  map[string]User
+	groups map[string]Group
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{users: map[string]User{}, groups: map[string]Group{}}
+}
+
+func (m *memoryStore) newID() string {
+	m.nextID++
+	return fmt.Sprintf("%04d", m.nextID)
+}
+
+func (m *memoryStore) withGroups(user User) *User {
+	user.Groups = nil
+	for _, group := range m.groups {
+		for _, member := range group.Members {
+			if member.Value == user.ID {
+				user.Groups = append(user.Groups, Reference{Value: group.ID, Display: group.DisplayName})
+			}
+		}
+	}
+	return &user
+}
+
+func (m *memoryStore) ListUsers(_ context.Context) ([]User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var users []User
+	for _, user := range m.users {
+		users = append(users, *m.withGroups(user))
+	}
+	return users, nil
+}
+
+func (m *memoryStore) GetUser(_ context.Context, id string) (*User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	user, ok := m.users[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return m.withGroups(user), nil
+}
+
+func (m *memoryStore) CreateUser(_ context.Context, user User) (*User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, u := range m.users {
+		if strings.EqualFold(u.UserName, user.UserName) {
+			return nil, ErrConflict
+		}
+	}
+	user.ID = m.newID()
+	m.users[user.ID] = user
+	return m.withGroups(user), nil
+}
+
+func (m *memoryStore) ReplaceUser(_ context.Context, user User) (*User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.users[user.ID]; !ok {
+		return nil, ErrNotFound
+	}
+	m.users[user.ID] = user
+	return m.withGroups(user), nil
+}
+
+func (m *memoryStore) DeleteUser(_ context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.users[id]; !ok {
+		return ErrNotFound
+	}
+	delete(m.users, id)
+	return nil
+}
+
+func (m *memoryStore) ListGroups(_ context.Context) ([]Group, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var groups []Group
+	for _, group := range m.groups {
+		groups = append(groups, group)
+	}
+	return groups, nil
+}
+
+func (m *memoryStore) GetGroup(_ context.Context, id string) (*Group, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	group, ok := m.groups[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &group, nil
+}
+
+func (m *memoryStore) CreateGroup(_ context.Context, group Group) (*Group, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	group.ID = m.newID()
+	m.groups[group.ID] = group
+	return &group, nil
+}
+
+func (m *memoryStore) ReplaceGroup(_ context.Context, group Group) (*Group, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.groups[group.ID]; !ok {
+		return nil, ErrNotFound
+	}
+	m.groups[group.ID] = group
+	return &group, nil
+}
+
+func (m *memoryStore) DeleteGroup(_ context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.groups[id]; !ok {
+		return ErrNotFound
+	}
+	delete(m.groups, id)
+	return nil
+}
+
+// client is a minimal SCIM client, sending requests the way identity providers do.
+type client struct {
+	t       *testing.T
+	baseURL string
+	token   string
+}
+
+func (c client) do(method, path string, body any, out any) int {
+	var reader *bytes.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		require.NoError(c.t, err)
+		reader = bytes.NewReader(b)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+	req, err := http.NewRequest(method, c.baseURL+path, reader)
+	require.NoError(c.t, err)
+	req.Header.Set(echo.HeaderContentType, MIMEApplicationSCIM)
+	if c.token != "" {
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+c.token)
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(c.t, err)
+	defer res.Body.Close()
+	if out != nil && res.StatusCode != http.StatusNoContent {
+		require.NoError(c.t, json.NewDecoder(res.Body).Decode(out))
+	}
+	return res.StatusCode
+}
+
+func newTestServer(t *testing.T) (*httptest.Server, client) {
+	e := echo.New()
+	NewServer(newMemoryStore(), func(token string) bool { return token == testToken }, "https://kaytu.test/auth/scim/v2").
+		Register(e.Group("/scim/v2"))
+	ts := httptest.NewServer(e)
+	t.Cleanup(ts.Close)
+	return ts, client{t: t, baseURL: ts.URL + "/scim/v2", token: testToken}
+}
+
+func TestAuthentication(t *testing.T) {
+	_, c := newTestServer(t)
+
+	var scimErr Error
+	c.token = ""
+	require.Equal(t, http.StatusUnauthorized, c.do(http.MethodGet, "/Users", nil, &scimErr))
+	require.Equal(t, "401", scimErr.Status)
+
+	c.token = "scim_wrong"
+	require.Equal(t, http.StatusUnauthorized, c.do(http.MethodGet, "/Users", nil, nil))
+
+	c.token = testToken
+	var config ServiceProviderConfig
+	require.Equal(t, http.StatusOK, c.do(http.MethodGet, "/ServiceProviderConfig", nil, &config))
+	require.True(t, config.Patch.Supported)
+}
+
+func TestUserLifecycle(t *testing.T) {
+	_, c := newTestServer(t)
+
+	var created User
+	require.Equal(t, http.StatusCreated, c.do(http.MethodPost, "/Users", User{
+		Schemas:    []string{SchemaUser},
+		UserName:   "alice@example.com",
+		ExternalID: "00u1",
+		Name:       &Name{GivenName: "Alice", FamilyName: "Smith"},
+		Emails:     []Email{{Value: "alice@example.com", Type: "work", Primary: true}},
+	}, &created))
+	require.NotEmpty(t, created.ID)
+	require.True(t, created.IsActive())
+	require.Equal(t, "https://kaytu.test/auth/scim/v2/Users/"+created.ID, created.Meta.Location)
+
+	var scimErr Error
+	require.Equal(t, http.StatusConflict, c.do(http.MethodPost, "/Users", User{UserName: "alice@example.com"}, &scimErr))
+	require.Equal(t, ScimTypeUniqueness, scimErr.ScimType)
+	require.Equal(t, http.StatusCreated, c.do(http.MethodPost, "/Users", User{UserName: "bob@example.com"}, nil))
+
+	// the identity provider looks the user up before provisioning it
+	var list ListResponse
+	filter := url.QueryEscape(`userName eq "ALICE@example.com"`)
+	require.Equal(t, http.StatusOK, c.do(http.MethodGet, "/Users?filter="+filter, nil, &list))
+	require.Equal(t, 1, list.TotalResults)
+	list = ListResponse{}
+	filter = url.QueryEscape(`emails[type eq "work" and value ew "@example.com"] or externalId eq "00u1"`)
+	require.Equal(t, http.StatusOK, c.do(http.MethodGet, "/Users?filter="+filter, nil, &list))
+	require.Equal(t, 1, list.TotalResults)
+	list = ListResponse{}
+	require.Equal(t, http.StatusOK, c.do(http.MethodGet, "/Users?startIndex=2&count=1", nil, &list))
+	require.Equal(t, 2, list.TotalResults)
+	require.Equal(t, 1, list.ItemsPerPage)
+	require.Equal(t, http.StatusBadRequest, c.do(http.MethodGet, "/Users?filter="+url.QueryEscape(`userName xx "a"`), nil, &scimErr))
+	require.Equal(t, ScimTypeInvalidFilter, scimErr.ScimType)
+
+	// deactivation as sent by Azure AD, with a capitalized operation and a string boolean
+	var patched User
+	require.Equal(t, http.StatusOK, c.do(http.MethodPatch, "/Users/"+created.ID, PatchRequest{
+		Schemas: []string{SchemaPatchOp},
+		Operations: []PatchOperation{
+			{Op: "Replace", Path: "active", Value: "False"},
+			{Op: "replace", Path: "name.givenName", Value: "Alicia"},
+		},
+	}, &patched))
+	require.False(t, patched.IsActive())
+	require.Equal(t, "Alicia", patched.Name.GivenName)
+	require.Equal(t, "Smith", patched.Name.FamilyName)
+
+	// reactivation as sent by Okta, without a path
+	require.Equal(t, http.StatusOK, c.do(http.MethodPatch, "/Users/"+created.ID, PatchRequest{
+		Schemas:    []string{SchemaPatchOp},
+		Operations: []PatchOperation{{Op: "replace", Value: map[string]any{"active": true}}},
+	}, &patched))
+	require.True(t, patched.IsActive())
+
+	require.Equal(t, http.StatusNoContent, c.do(http.MethodDelete, "/Users/"+created.ID, nil, nil))
+	require.Equal(t, http.StatusNotFound, c.do(http.MethodGet, "/Users/"+created.ID, nil, &scimErr))
+}
+
+func TestGroupMembership(t *testing.T) {
+	_, c := newTestServer(t)
+
+	var alice, bob User
+	require.Equal(t, http.StatusCreated, c.do(http.MethodPost, "/Users", User{UserName: "alice@example.com"}, &alice))
+	require.Equal(t, http.StatusCreated, c.do(http.MethodPost, "/Users", User{UserName: "bob@example.com"}, &bob))
+
+	var group Group
+	require.Equal(t, http.StatusCreated, c.do(http.MethodPost, "/Groups", Group{
+		DisplayName: "kaytu-admins",
+		Members:     []Reference{{Value: alice.ID}},
+	}, &group))
+
+	require.Equal(t, http.StatusOK, c.do(http.MethodPatch, "/Groups/"+group.ID, PatchRequest{
+		Schemas: []string{SchemaPatchOp},
+		Operations: []PatchOperation{
+			{Op: "add", Path: "members", Value: []any{map[string]any{"value": bob.ID}, map[string]any{"value": alice.ID}}},
+		},
+	}, &group))
+	require.Len(t, group.Members, 2)
+
+	var user User
+	require.Equal(t, http.StatusOK, c.do(http.MethodGet, "/Users/"+bob.ID, nil, &user))
+	require.Len(t, user.Groups, 1)
+	require.Equal(t, "kaytu-admins", user.Groups[0].Display)
+
+	require.Equal(t, http.StatusOK, c.do(http.MethodPatch, "/Groups/"+group.ID, PatchRequest{
+		Schemas:    []string{SchemaPatchOp},
+		Operations: []PatchOperation{{Op: "remove", Path: fmt.Sprintf(`members[value eq "%s"]`, alice.ID)}},
+	}, &group))
+	require.Len(t, group.Members, 1)
+	require.Equal(t, bob.ID, group.Members[0].Value)
+
+	// Azure AD removes members by value
+	var emptied Group
+	require.Equal(t, http.StatusOK, c.do(http.MethodPatch, "/Groups/"+group.ID, PatchRequest{
+		Schemas:    []string{SchemaPatchOp},
+		Operations: []PatchOperation{{Op: "Remove", Path: "members", Value: []any{map[string]any{"value": bob.ID}}}},
+	}, &emptied))
+	require.Equal(t, group.ID, emptied.ID)
+	require.Empty(t, emptied.Members)
+
+	var list ListResponse
+	filter := url.QueryEscape(`displayName eq "kaytu-admins"`)
+	require.Equal(t, http.StatusOK, c.do(http.MethodGet, "/Groups?excludedAttributes=members&filter="+filter, nil, &list))
+	require.Equal(t, 1, list.TotalResults)
+	require.NotContains(t, list.Resources[0], "members")
+
+	require.Equal(t, http.StatusNoContent, c.do(http.MethodDelete, "/Groups/"+group.ID, nil, nil))
+}
@@ -0,0 +1,146 @@
+package auth
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	api3 "github.com/opengovern/og-util/pkg/api"
+	"github.com/opengovern/og-util/pkg/httpclient"
+	"github.com/opengovern/opengovernance/pkg/auth/api"
+	"github.com/opengovern/opengovernance/pkg/utils"
+)
+
+const scopeCacheTTL = time.Minute
+
+type scopeCacheEntry struct {
+	connectionIDs []string
+	expiresAt     time.Time
+}
+
+// scopeCache keeps the connections of connection groups and resource collections for a short time,
+// they are resolved on every checked request of a scoped user.
+type scopeCache struct {
+	mu      sync.Mutex
+	entries map[string]scopeCacheEntry
+}
+
+func (c *scopeCache) get(key string, fetch func() ([]string, error)) ([]string, error) {
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry.connectionIDs, nil
+	}
+
+	connectionIDs, err := fetch()
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	if c.entries == nil {
+		c.entries = make(map[string]scopeCacheEntry)
+	}
+	c.entries[key] = scopeCacheEntry{connectionIDs: connectionIDs, expiresAt: time.Now().Add(scopeCacheTTL)}
+	c.mu.Unlock()
+	return connectionIDs, nil
+}
+
+// resolvedScope is the scope of a request, nil fields do not restrict.
+type resolvedScope struct {
+	connectionIDs         []string
+	resourceCollectionIDs []string
+	benchmarkIDs          []string
+}
+
+// resolveScope returns the connections of the given connections, connection groups and resource collections
+// together with the resource collections and benchmarks the scope is restricted to. The connections of a resource
+// collection only restrict the connection level APIs, the services further restrict the resources to the members
+// of the resource collections sent in the resource collections scope header.
+func (s *Server) resolveScope(ctx context.Context, connectionIDs []string, scope api.RoleBindingScope) (resolvedScope, error) {
+	res := resolvedScope{
+		resourceCollectionIDs: scope.ResourceCollectionIDs,
+		benchmarkIDs:          scope.BenchmarkIDs,
+	}
+	if len(connectionIDs) == 0 && len(scope.ConnectionGroups) == 0 && len(scope.ResourceCollectionIDs) == 0 {
+		return res, nil
+	}
+
+	clientCtx := &httpclient.Context{Ctx: ctx, UserRole: api3.InternalRole}
+	connections := make(map[string]bool)
+	for _, connectionID := range connectionIDs {
+		connections[connectionID] = true
+	}
+	for _, group := range scope.ConnectionGroups {
+		ids, err := s.scopeCache.get("connection-group/"+group, func() ([]string, error) {
+			connectionGroup, err := s.onboardClient.GetConnectionGroup(clientCtx, group)
+			if err != nil {
+				return nil, err
+			}
+			return connectionGroup.ConnectionIds, nil
+		})
+		if err != nil {
+			return res, err
+		}
+		for _, id := range ids {
+			connections[id] = true
+		}
+	}
+	for _, resourceCollectionID := range scope.ResourceCollectionIDs {
+		ids, err := s.scopeCache.get("resource-collection/"+resourceCollectionID, func() ([]string, error) {
+			count, err := s.inventoryClient.CountResourceCollectionMembers(clientCtx, resourceCollectionID)
+			if err != nil {
+				return nil, err
+			}
+			ids := make([]string, 0, len(count.ConnectionIDs))
+			for id := range count.ConnectionIDs {
+				ids = append(ids, id)
+			}
+			return ids, nil
+		})
+		if err != nil {
+			return res, err
+		}
+		for _, id := range ids {
+			connections[id] = true
+		}
+	}
+
+	res.connectionIDs = make([]string, 0, len(connections))
+	for id := range connections {
+		res.connectionIDs = append(res.connectionIDs, id)
+	}
+	if len(res.connectionIDs) == 0 {
+		res.connectionIDs = []string{utils.ScopeNoMatch}
+	}
+	return res, nil
+}
+
+// intersect returns the scope allowing only what both scopes allow.
+func (r resolvedScope) intersect(o resolvedScope) resolvedScope {
+	return resolvedScope{
+		connectionIDs:         intersectScope(r.connectionIDs, o.connectionIDs),
+		resourceCollectionIDs: intersectScope(r.resourceCollectionIDs, o.resourceCollectionIDs),
+		benchmarkIDs:          intersectScope(r.benchmarkIDs, o.benchmarkIDs),
+	}
+}
+
+func intersectScope(a, b []string) []string {
+	if len(a) == 0 {
+		return b
+	}
+	if len(b) == 0 {
+		return a
+	}
+	var res []string
+	for _, item := range a {
+		if utils.Includes(b, item) {
+			res = append(res, item)
+		}
+	}
+	if len(res) == 0 {
+		return []string{utils.ScopeNoMatch}
+	}
+	return res
+}
@@ -0,0 +1,112 @@
+package auth
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/opengovern/og-util/pkg/httpclient"
+	"github.com/opengovern/opengovernance/pkg/auth/api"
+	inventoryApi "github.com/opengovern/opengovernance/pkg/inventory/api"
+	inventoryClient "github.com/opengovern/opengovernance/pkg/inventory/client"
+	onboardApi "github.com/opengovern/opengovernance/pkg/onboard/api"
+	onboardClient "github.com/opengovern/opengovernance/pkg/onboard/client"
+	"github.com/opengovern/opengovernance/pkg/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+type scopeOnboardClient struct {
+	onboardClient.OnboardServiceClient
+	groups map[string][]string
+	calls  int
+}
+
+func (c *scopeOnboardClient) GetConnectionGroup(_ *httpclient.Context, name string) (*onboardApi.ConnectionGroup, error) {
+	c.calls++
+	return &onboardApi.ConnectionGroup{Name: name, ConnectionIds: c.groups[name]}, nil
+}
+
+type scopeInventoryClient struct {
+	inventoryClient.InventoryServiceClient
+	members map[string]map[string]int
+}
+
+func (c *scopeInventoryClient) CountResourceCollectionMembers(_ *httpclient.Context, id string) (*inventoryApi.CountResourceCollectionMembersResponse, error) {
+	return &inventoryApi.CountResourceCollectionMembersResponse{ConnectionIDs: c.members[id]}, nil
+}
+
+func TestResolveScope(t *testing.T) {
+	onboard := &scopeOnboardClient{groups: map[string][]string{"prod": {"c1", "c2"}, "empty": nil}}
+	s := &Server{
+		onboardClient:   onboard,
+		inventoryClient: &scopeInventoryClient{members: map[string]map[string]int{"rc1": {"c2": 3, "c3": 1}}},
+	}
+
+	tests := []struct {
+		name              string
+		connectionIDs     []string
+		scope             api.RoleBindingScope
+		wantConnectionIDs []string
+	}{
+		{name: "unscoped", wantConnectionIDs: nil},
+		{name: "benchmarks only", scope: api.RoleBindingScope{BenchmarkIDs: []string{"b1"}}, wantConnectionIDs: nil},
+		{name: "connections", connectionIDs: []string{"c1"}, wantConnectionIDs: []string{"c1"}},
+		{name: "connection group", scope: api.RoleBindingScope{ConnectionGroups: []string{"prod"}}, wantConnectionIDs: []string{"c1", "c2"}},
+		{
+			name:              "resource collection",
+			scope:             api.RoleBindingScope{ResourceCollectionIDs: []string{"rc1"}},
+			wantConnectionIDs: []string{"c2", "c3"},
+		},
+		{
+			name:              "union",
+			connectionIDs:     []string{"c4"},
+			scope:             api.RoleBindingScope{ConnectionGroups: []string{"prod"}, ResourceCollectionIDs: []string{"rc1"}},
+			wantConnectionIDs: []string{"c1", "c2", "c3", "c4"},
+		},
+		{name: "empty group", scope: api.RoleBindingScope{ConnectionGroups: []string{"empty"}}, wantConnectionIDs: []string{utils.ScopeNoMatch}},
+	}
+	for _, tt := range tests {
+		res, err := s.resolveScope(context.Background(), tt.connectionIDs, tt.scope)
+		assert.NoError(t, err, tt.name)
+		sort.Strings(res.connectionIDs)
+		assert.Equal(t, tt.wantConnectionIDs, res.connectionIDs, tt.name)
+		assert.Equal(t, tt.scope.ResourceCollectionIDs, res.resourceCollectionIDs, tt.name)
+		assert.Equal(t, tt.scope.BenchmarkIDs, res.benchmarkIDs, tt.name)
+	}
+
+	// connection groups are cached between requests
+	calls := onboard.calls
+	_, err := s.resolveScope(context.Background(), nil, api.RoleBindingScope{ConnectionGroups: []string{"prod"}})
+	assert.NoError(t, err)
+	assert.Equal(t, calls, onboard.calls)
+}
+
+func TestIntersectScope(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []string
+		want []string
+	}{
+		{name: "both unrestricted", want: nil},
+		{name: "left unrestricted", b: []string{"c1"}, want: []string{"c1"}},
+		{name: "right unrestricted", a: []string{"c1"}, want: []string{"c1"}},
+		{name: "overlap", a: []string{"c1", "c2"}, b: []string{"c2", "c3"}, want: []string{"c2"}},
+		{name: "disjoint", a: []string{"c1"}, b: []string{"c2"}, want: []string{utils.ScopeNoMatch}},
+		{name: "no match stays no match", a: []string{utils.ScopeNoMatch}, b: []string{"c1"}, want: []string{utils.ScopeNoMatch}},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, intersectScope(tt.a, tt.b), tt.name)
+	}
+}
+
+func TestResolvedScopeIntersect(t *testing.T) {
+	user := resolvedScope{connectionIDs: []string{"c1", "c2"}, benchmarkIDs: []string{"b1"}}
+	key := resolvedScope{connectionIDs: []string{"c2"}, resourceCollectionIDs: []string{"rc1"}, benchmarkIDs: []string{"b2"}}
+
+	assert.Equal(t, resolvedScope{
+		connectionIDs:         []string{"c2"},
+		resourceCollectionIDs: []string{"rc1"},
+		benchmarkIDs:          []string{utils.ScopeNoMatch},
+	}, user.intersect(key))
+	assert.Equal(t, user, user.intersect(resolvedScope{}))
+}
@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"github.com/coreos/go-oidc/v3/oidc"
+	dexApi "github.com/dexidp/dex/api/v2"
 	envoycore "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	envoyauth "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
 	envoytype "github.com/envoyproxy/go-control-plane/envoy/type/v3"
@@ -16,15 +17,21 @@ import (
 	api3 "github.com/opengovern/og-util/pkg/api"
 	"github.com/opengovern/og-util/pkg/httpserver"
 	"github.com/opengovern/opengovernance/pkg/auth/api"
+	"github.com/opengovern/opengovernance/pkg/auth/audit"
 	"github.com/opengovern/opengovernance/pkg/auth/auth0"
 	"github.com/opengovern/opengovernance/pkg/auth/db"
 	client2 "github.com/opengovern/opengovernance/pkg/compliance/client"
+	client5 "github.com/opengovern/opengovernance/pkg/inventory/client"
+	metadataClient "github.com/opengovern/opengovernance/pkg/metadata/client"
+	client4 "github.com/opengovern/opengovernance/pkg/onboard/client"
+	"github.com/opengovern/opengovernance/pkg/utils"
 	"github.com/opengovern/opengovernance/pkg/workspace/client"
 	client3 "github.com/opengovern/opengovernance/services/integration/client"
 	"go.uber.org/zap"
 	"google.golang.org/genproto/googleapis/rpc/status"
 	"gorm.io/gorm"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -43,15 +50,28 @@ type Server struct {
 	verifierNative          *oidc.IDTokenVerifier
 	verifierPennywiseNative *oidc.IDTokenVerifier
 	dexVerifier             *oidc.IDTokenVerifier
+	dexClient               dexApi.DexClient
+	dexPasswordLoginPath    string
 	logger                  *zap.Logger
 	workspaceClient         client.WorkspaceServiceClient
 	complianceClient        client2.ComplianceServiceClient
 	integrationClient       client3.IntegrationServiceClient
+	onboardClient           client4.OnboardServiceClient
+	inventoryClient         client5.InventoryServiceClient
 	db                      db.Database
 	auth0Service            *auth0.Service
 
 	updateLoginUserList []User
 	updateLogin         chan User
+
+	scopeCache scopeCache
+
+	metadataClient       metadataClient.MetadataServiceClient
+	oidcRoleMappingCache oidcRoleMappingCache
+	sessionStateCache    sessionStateCache
+
+	auditRecorder *audit.Recorder
+	auditLogKey   []byte
 }
 
 type DexClaims struct {
@@ -183,6 +203,17 @@ func (s *Server) Check(ctx context.Context, req *envoyauth.CheckRequest) (*envoy
 		authHeader = headers[strings.ToLower(echo.HeaderAuthorization)]
 	}
 
+	if strings.HasPrefix(httpRequest.Path, scimBasePath+"/") {
+		return s.checkScim(req, authHeader, unAuth), nil
+	}
+	if httpRequest.Path == serviceAccountTokenPath {
+		// the client authenticates with its client secret, checked by the endpoint itself
+		return &envoyauth.CheckResponse{Status: &status.Status{Code: int32(rpc.OK)}}, nil
+	}
+	if s.isPasswordLogin(httpRequest) {
+		return s.checkPasswordLogin(ctx, req), nil
+	}
+
 	user, err := s.Verify(ctx, authHeader)
 	if err != nil {
 		s.logger.Warn("denied access due to unsuccessful token verification",
@@ -190,16 +221,9 @@ func (s *Server) Check(ctx context.Context, req *envoyauth.CheckRequest) (*envoy
 			zap.String("path", httpRequest.Path),
 			zap.String("method", httpRequest.Method),
 			zap.Error(err))
-		return unAuth, nil
-	}
-
-	user.Email = strings.ToLower(strings.TrimSpace(user.Email))
-	if user.Email == "" {
-		s.logger.Warn("denied access due to failure to get email from token",
-			zap.String("reqId", httpRequest.Id),
-			zap.String("path", httpRequest.Path),
-			zap.String("method", httpRequest.Method),
-			zap.Error(err))
+		if authHeader != "" {
+			s.recordCheckEvent(req, nil, audit.OutcomeUnauthenticated, "token verification failed")
+		}
 		return unAuth, nil
 	}
 
@@ -212,29 +236,70 @@ func (s *Server) Check(ctx context.Context, req *envoyauth.CheckRequest) (*envoy
 		workspaceName = headerWorkspace
 	}
 
-	theUser, err := s.auth0Service.GetOrCreateUser(user.ExternalUserID, user.Email)
-	if err != nil {
-		s.logger.Warn("failed to getOrCreate user",
-			zap.String("userId", user.ExternalUserID),
-			zap.String("email", user.Email),
-			zap.Error(err))
-		if errors.Is(err, errors.New("user disabled")) {
+	var rb api.RoleBinding
+	var sessionID string
+	if user.serviceAccount != nil {
+		rb, err = s.serviceAccountRoleBinding(workspaceName, user.serviceAccount)
+	} else {
+		user.Email = strings.ToLower(strings.TrimSpace(user.Email))
+		if user.Email == "" {
+			s.logger.Warn("denied access due to failure to get email from token",
+				zap.String("reqId", httpRequest.Id),
+				zap.String("path", httpRequest.Path),
+				zap.String("method", httpRequest.Method),
+				zap.Error(err))
+			s.recordCheckEvent(req, user, audit.OutcomeUnauthenticated, "token has no email")
 			return unAuth, nil
 		}
-	}
-	user.WorkspaceAccess = theUser.AppMetadata.WorkspaceAccess
-	user.GlobalAccess = theUser.AppMetadata.GlobalAccess
-	user.MemberSince = theUser.AppMetadata.MemberSince
-	user.UserLastLogin = theUser.AppMetadata.LastLogin
-	user.ColorBlindMode = theUser.AppMetadata.ColorBlindMode
-	user.Theme = theUser.AppMetadata.Theme
-	user.ConnectionIDs = theUser.AppMetadata.ConnectionIDs
 
-	if user.WorkspaceAccess == nil {
-		user.WorkspaceAccess = map[string]api3.Role{}
-	}
+		if user.apiKey == nil {
+			sessionID = tokenSessionID(user, authHeader)
+			if err := s.checkSession(user, sessionID); err != nil {
+				s.logger.Warn("denied access due to session checks",
+					zap.String("reqId", httpRequest.Id),
+					zap.String("userId", user.ExternalUserID),
+					zap.Error(err))
+				s.recordCheckEvent(req, user, audit.OutcomeUnauthenticated, err.Error())
+				return unAuth, nil
+			}
+		}
+
+		if err := s.applyOidcRoleRules(ctx, user, sessionID); err != nil {
+			s.logger.Warn("denied access due to role rules",
+				zap.String("reqId", httpRequest.Id),
+				zap.String("email", user.Email),
+				zap.Error(err))
+			s.recordCheckEvent(req, user, audit.OutcomeDenied, err.Error())
+			return unAuth, nil
+		}
+
+		var theUser *auth0.User
+		theUser, err = s.auth0Service.GetOrCreateUser(user.ExternalUserID, user.Email)
+		if err != nil {
+			s.logger.Warn("failed to getOrCreate user",
+				zap.String("userId", user.ExternalUserID),
+				zap.String("email", user.Email),
+				zap.Error(err))
+			if errors.Is(err, auth0.ErrUserDisabled) {
+				s.recordCheckEvent(req, user, audit.OutcomeDenied, "user disabled")
+			}
+			return unAuth, nil
+		}
+		user.WorkspaceAccess = theUser.AppMetadata.WorkspaceAccess
+		user.GlobalAccess = theUser.AppMetadata.GlobalAccess
+		user.MemberSince = theUser.AppMetadata.MemberSince
+		user.UserLastLogin = theUser.AppMetadata.LastLogin
+		user.ColorBlindMode = theUser.AppMetadata.ColorBlindMode
+		user.Theme = theUser.AppMetadata.Theme
+		user.ConnectionIDs = theUser.AppMetadata.ConnectionIDs
+		user.RoleScopes = theUser.AppMetadata.RoleScopes
+
+		if user.WorkspaceAccess == nil {
+			user.WorkspaceAccess = map[string]api3.Role{}
+		}
 
-	rb, err := s.GetWorkspaceByName(workspaceName, user)
+		rb, err = s.GetWorkspaceByName(workspaceName, user)
+	}
 	if err != nil {
 		s.logger.Warn("denied access due to failure in getting workspace",
 			zap.String("reqId", httpRequest.Id),
@@ -242,10 +307,74 @@ func (s *Server) Check(ctx context.Context, req *envoyauth.CheckRequest) (*envoy
 			zap.String("method", httpRequest.Method),
 			zap.String("workspace", workspaceName),
 			zap.Error(err))
+		s.recordCheckEvent(req, user, audit.OutcomeDenied, "no access to workspace "+workspaceName)
 		return unAuth, nil
 	}
 
-	go s.UpdateLastLogin(user)
+	scope, err := s.resolveScope(ctx, rb.ScopedConnectionIDs, rb.RoleBindingScope)
+	if err != nil {
+		s.logger.Warn("denied access due to failure in resolving role binding scope",
+			zap.String("reqId", httpRequest.Id),
+			zap.String("path", httpRequest.Path),
+			zap.String("method", httpRequest.Method),
+			zap.Error(err))
+		return unAuth, nil
+	}
+
+	if user.apiKey != nil {
+		keyScope, err := s.resolveScope(ctx, user.apiKey.ConnectionIDs, api.RoleBindingScope{
+			ConnectionGroups:      user.apiKey.ConnectionGroups,
+			ResourceCollectionIDs: user.apiKey.ResourceCollectionIDs,
+			BenchmarkIDs:          user.apiKey.BenchmarkIDs,
+		})
+		if err != nil {
+			s.logger.Warn("denied access due to failure in resolving api key scope",
+				zap.String("reqId", httpRequest.Id),
+				zap.Uint("keyID", user.apiKey.ID),
+				zap.Error(err))
+			return unAuth, nil
+		}
+		scope = scope.intersect(keyScope)
+
+		// the key acts with the role it was created with, or with its creator's current role if that is lower,
+		// the creator of the keys of a service account is the service account itself
+		if roleRank(rb.RoleName) < 0 || roleRank(user.apiKey.Role) < roleRank(rb.RoleName) {
+			rb.RoleName = user.apiKey.Role
+		}
+		if !apiKeyScopesAllow(user.apiKey.Scopes, httpRequest.Method, httpRequest.Path) {
+			s.logger.Warn("denied access due to api key scopes",
+				zap.String("reqId", httpRequest.Id),
+				zap.String("path", httpRequest.Path),
+				zap.String("method", httpRequest.Method),
+				zap.Uint("keyID", user.apiKey.ID),
+				zap.Strings("scopes", user.apiKey.Scopes))
+			s.recordCheckEvent(req, user, audit.OutcomeDenied, "api key scopes do not allow the request")
+			return &envoyauth.CheckResponse{
+				Status: &status.Status{
+					Code: int32(rpc.PERMISSION_DENIED),
+				},
+				HttpResponse: &envoyauth.CheckResponse_DeniedResponse{
+					DeniedResponse: &envoyauth.DeniedHttpResponse{
+						Status: &envoytype.HttpStatus{Code: http.StatusForbidden},
+						Body:   http.StatusText(http.StatusForbidden),
+					},
+				},
+			}, nil
+		}
+		go s.updateAPIKeyLastUsed(user.apiKey)
+	}
+
+	if user.serviceAccount == nil {
+		go s.UpdateLastLogin(user)
+	}
+	if sessionID != "" {
+		go s.touchSession(req, user, sessionID)
+	}
+
+	var apiKeyID string
+	if user.apiKey != nil {
+		apiKeyID = strconv.FormatUint(uint64(user.apiKey.ID), 10)
+	}
 
 	return &envoyauth.CheckResponse{
 		Status: &status.Status{
@@ -282,7 +411,25 @@ func (s *Server) Check(ctx context.Context, req *envoyauth.CheckRequest) (*envoy
 					{
 						Header: &envoycore.HeaderValue{
 							Key:   httpserver.XKaytuUserConnectionsScope,
-							Value: strings.Join(rb.ScopedConnectionIDs, ","),
+							Value: strings.Join(scope.connectionIDs, ","),
+						},
+					},
+					{
+						Header: &envoycore.HeaderValue{
+							Key:   utils.XKaytuUserResourceCollectionsScope,
+							Value: strings.Join(scope.resourceCollectionIDs, ","),
+						},
+					},
+					{
+						Header: &envoycore.HeaderValue{
+							Key:   utils.XKaytuUserBenchmarksScope,
+							Value: strings.Join(scope.benchmarkIDs, ","),
+						},
+					},
+					{
+						Header: &envoycore.HeaderValue{
+							Key:   audit.XKaytuAPIKeyIDHeader,
+							Value: apiKeyID,
 						},
 					},
 				},
@@ -292,16 +439,27 @@ func (s *Server) Check(ctx context.Context, req *envoyauth.CheckRequest) (*envoy
 }
 
 type userClaim struct {
-	WorkspaceAccess map[string]api3.Role `json:"https://app.kaytu.io/workspaceAccess"`
-	GlobalAccess    *api3.Role           `json:"https://app.kaytu.io/globalAccess"`
-	Email           string               `json:"https://app.kaytu.io/email"`
-	MemberSince     *string              `json:"https://app.kaytu.io/memberSince"`
-	UserLastLogin   *string              `json:"https://app.kaytu.io/userLastLogin"`
-	ColorBlindMode  *bool                `json:"https://app.kaytu.io/colorBlindMode"`
-	Theme           *api.Theme           `json:"https://app.kaytu.io/theme"`
-	ConnectionIDs   map[string][]string  `json:"https://app.kaytu.io/connectionIDs"`
+	WorkspaceAccess map[string]api3.Role            `json:"https://app.kaytu.io/workspaceAccess"`
+	GlobalAccess    *api3.Role                      `json:"https://app.kaytu.io/globalAccess"`
+	Email           string                          `json:"https://app.kaytu.io/email"`
+	MemberSince     *string                         `json:"https://app.kaytu.io/memberSince"`
+	UserLastLogin   *string                         `json:"https://app.kaytu.io/userLastLogin"`
+	ColorBlindMode  *bool                           `json:"https://app.kaytu.io/colorBlindMode"`
+	Theme           *api.Theme                      `json:"https://app.kaytu.io/theme"`
+	ConnectionIDs   map[string][]string             `json:"https://app.kaytu.io/connectionIDs"`
+	RoleScopes      map[string]api.RoleBindingScope `json:"https://app.kaytu.io/roleScopes"`
 
 	ExternalUserID string `json:"sub"`
+	TokenID        string `json:"jti,omitempty"`
+	IssuedAt       int64  `json:"iat,omitempty"`
+	ExpiresAt      int64  `json:"exp,omitempty"`
+	// TokenUse is set on the tokens issued by the client credentials grant of the service accounts
+	TokenUse string `json:"token_use,omitempty"`
+
+	apiKey         *db.ApiKey
+	serviceAccount *db.ServiceAccount
+	// idTokenClaims are the claims of the ID token issued by dex, used by the role rules
+	idTokenClaims map[string]any
 }
 
 func (u userClaim) Valid() error {
@@ -366,10 +524,21 @@ func (s *Server) Verify(ctx context.Context, authToken string) (*userClaim, erro
 		if claimsMap.Email == "" {
 			claimsMap.Email = "admin@example.com"
 		}
+		var idTokenClaims map[string]any
+		if err = json.Unmarshal(claims, &idTokenClaims); err != nil {
+			return nil, err
+		}
 
+		var issuedAt int64
+		if !dv.IssuedAt.IsZero() {
+			issuedAt = dv.IssuedAt.Unix()
+		}
 		return &userClaim{
 			Email:          claimsMap.Email,
 			ExternalUserID: fmt.Sprintf("dex|%s", claimsMap.Email),
+			IssuedAt:       issuedAt,
+			ExpiresAt:      dv.Expiry.Unix(),
+			idTokenClaims:  idTokenClaims,
 		}, nil
 	} else {
 		s.logger.Error("dex verifier verify error", zap.Error(err))
@@ -380,6 +549,27 @@ func (s *Server) Verify(ctx context.Context, authToken string) (*userClaim, erro
 			return s.kaytuPublicKey, nil
 		})
 		if errk == nil {
+			if u.TokenUse == clientCredentialsTokenUse {
+				sa, err := s.verifyServiceAccountToken(&u)
+				if err != nil {
+					return nil, err
+				}
+				u.serviceAccount = sa
+				return &u, nil
+			}
+			key, err := s.verifyAPIKey(token)
+			if err != nil {
+				return nil, err
+			}
+			u.apiKey = key
+			if _, ok := parseServiceAccountUserID(key.CreatorUserID); ok {
+				sa, err := s.activeServiceAccount(key.CreatorUserID)
+				if err != nil {
+					return nil, err
+				}
+				u.ExternalUserID = key.CreatorUserID
+				u.serviceAccount = sa
+			}
 			return &u, nil
 		} else {
 			fmt.Println("failed to auth with kaytu cred due to", errk)
@@ -412,6 +602,7 @@ func (s *Server) GetWorkspaceByName(workspaceName string, user *userClaim) (api.
 		rb.WorkspaceName = workspaceName
 		rb.WorkspaceID = workspaceID
 		rb.ScopedConnectionIDs = user.ConnectionIDs[workspaceID]
+		rb.RoleBindingScope = user.RoleScopes[workspaceID]
 
 		if rl, ok := user.WorkspaceAccess[workspaceID]; ok {
 			rb.RoleName = rl
@@ -480,3 +671,31 @@ func newDexOidcVerifier(ctx context.Context, domain, clientId string) (*oidc.IDT
 		SkipIssuerCheck:   true,
 	}), nil
 }
+
+// checkScim lets the requests of the SCIM client through, the SCIM token is not a user token.
+func (s *Server) checkScim(req *envoyauth.CheckRequest, authHeader string, unAuth *envoyauth.CheckResponse) *envoyauth.CheckResponse {
+	token, ok := strings.CutPrefix(authHeader, "Bearer ")
+	if !ok || !s.verifyScimToken(token) {
+		if authHeader != "" {
+			s.recordCheckEvent(req, nil, audit.OutcomeUnauthenticated, "invalid scim token")
+		}
+		return unAuth
+	}
+	return &envoyauth.CheckResponse{
+		Status: &status.Status{
+			Code: int32(rpc.OK),
+		},
+		HttpResponse: &envoyauth.CheckResponse_OkResponse{
+			OkResponse: &envoyauth.OkHttpResponse{
+				Headers: []*envoycore.HeaderValueOption{
+					{
+						Header: &envoycore.HeaderValue{
+							Key:   httpserver.XKaytuUserIDHeader,
+							Value: scimUserID,
+						},
+					},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,152 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/opengovern/opengovernance/pkg/auth/api"
+	"github.com/opengovern/opengovernance/pkg/auth/audit"
+	"github.com/opengovern/opengovernance/pkg/auth/db"
+)
+
+const (
+	serviceAccountTokenPath    = "/auth/api/v1/service-accounts/token"
+	serviceAccountTokenTTL     = time.Hour
+	clientCredentialsTokenUse  = "client_credentials"
+	serviceAccountSecretPrefix = "sas_"
+)
+
+func serviceAccountUserID(id uint) string {
+	return audit.ServiceAccountIDPrefix + strconv.FormatUint(uint64(id), 10)
+}
+
+func parseServiceAccountUserID(userID string) (uint, bool) {
+	idStr, ok := strings.CutPrefix(userID, audit.ServiceAccountIDPrefix)
+	if !ok {
+		return 0, false
+	}
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func newServiceAccountSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return serviceAccountSecretPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// activeServiceAccount returns the enabled service account with the given user ID.
+func (s *Server) activeServiceAccount(userID string) (*db.ServiceAccount, error) {
+	id, ok := parseServiceAccountUserID(userID)
+	if !ok {
+		return nil, errors.New("invalid service account id")
+	}
+	sa, err := s.db.GetServiceAccount(id)
+	if err != nil {
+		return nil, err
+	}
+	if sa == nil {
+		return nil, errors.New("service account not found")
+	}
+	if sa.Disabled {
+		return nil, errors.New("service account is disabled")
+	}
+	return sa, nil
+}
+
+// verifyServiceAccountToken checks a token issued by the client credentials grant against its service account.
+func (s *Server) verifyServiceAccountToken(u *userClaim) (*db.ServiceAccount, error) {
+	sa, err := s.activeServiceAccount(u.ExternalUserID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkServiceAccountToken(u, sa, time.Now()); err != nil {
+		return nil, err
+	}
+	return sa, nil
+}
+
+// checkServiceAccountToken rejects expired tokens and tokens issued before the current client secret was created.
+func checkServiceAccountToken(u *userClaim, sa *db.ServiceAccount, now time.Time) error {
+	if u.ExpiresAt == 0 || now.After(time.Unix(u.ExpiresAt, 0)) {
+		return errors.New("service account token expired")
+	}
+	if sa.ClientSecretHash == "" || sa.ClientSecretCreatedAt == nil ||
+		time.Unix(u.IssuedAt, 0).Before(sa.ClientSecretCreatedAt.Truncate(time.Second)) {
+		return errors.New("service account client secret revoked")
+	}
+	return nil
+}
+
+// verifyClientSecret returns the enabled service account of the client credentials.
+func (s *Server) verifyClientSecret(clientID, clientSecret string) (*db.ServiceAccount, error) {
+	sa, err := s.activeServiceAccount(clientID)
+	if err != nil {
+		return nil, err
+	}
+	secretHash, err := hashAPIKey(clientSecret)
+	if err != nil {
+		return nil, err
+	}
+	if sa.ClientSecretHash == "" || subtle.ConstantTimeCompare([]byte(secretHash), []byte(sa.ClientSecretHash)) != 1 {
+		return nil, errors.New("invalid client secret")
+	}
+	return sa, nil
+}
+
+// serviceAccountRoleBinding returns the role binding of a service account, it is owned by the workspace so
+// it has the same binding in every workspace it is used in.
+func (s *Server) serviceAccountRoleBinding(workspaceName string, sa *db.ServiceAccount) (api.RoleBinding, error) {
+	rb := api.RoleBinding{
+		UserID:              serviceAccountUserID(sa.ID),
+		RoleName:            sa.Role,
+		ScopedConnectionIDs: sa.ConnectionIDs,
+		RoleBindingScope: api.RoleBindingScope{
+			ConnectionGroups:      sa.ConnectionGroups,
+			ResourceCollectionIDs: sa.ResourceCollectionIDs,
+			BenchmarkIDs:          sa.BenchmarkIDs,
+		},
+	}
+	if workspaceName != "kaytu" {
+		workspaceID, err := s.GetWorkspaceIDByName(workspaceName)
+		if err != nil {
+			return rb, err
+		}
+		rb.WorkspaceName = workspaceName
+		rb.WorkspaceID = workspaceID
+	}
+	return rb, nil
+}
+
+func toServiceAccount(sa db.ServiceAccount) api.ServiceAccount {
+	return api.ServiceAccount{
+		ID:                    sa.ID,
+		UserID:                serviceAccountUserID(sa.ID),
+		Name:                  sa.Name,
+		Description:           sa.Description,
+		OwnerTeam:             sa.OwnerTeam,
+		RoleName:              sa.Role,
+		Disabled:              sa.Disabled,
+		CreatorUserID:         sa.CreatorUserID,
+		CreatedAt:             sa.CreatedAt,
+		UpdatedAt:             sa.UpdatedAt,
+		MaskedClientSecret:    sa.MaskedClientSecret,
+		ClientSecretCreatedAt: sa.ClientSecretCreatedAt,
+		ConnectionIDs:         sa.ConnectionIDs,
+		RoleBindingScope: api.RoleBindingScope{
+			ConnectionGroups:      sa.ConnectionGroups,
+			ResourceCollectionIDs: sa.ResourceCollectionIDs,
+			BenchmarkIDs:          sa.BenchmarkIDs,
+		},
+	}
+}
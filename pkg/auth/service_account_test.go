@@ -0,0 +1,50 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/opengovern/opengovernance/pkg/auth/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseServiceAccountUserID(t *testing.T) {
+	id, ok := parseServiceAccountUserID(serviceAccountUserID(42))
+	assert.True(t, ok)
+	assert.Equal(t, uint(42), id)
+
+	for _, userID := range []string{"", "42", "dex|alice@example.com", serviceAccountUserID(1) + "x"} {
+		_, ok := parseServiceAccountUserID(userID)
+		assert.False(t, ok, userID)
+	}
+}
+
+func TestCheckServiceAccountToken(t *testing.T) {
+	now := time.Now()
+	secretCreatedAt := now.Add(-time.Hour)
+	sa := &db.ServiceAccount{ClientSecretHash: "hash", ClientSecretCreatedAt: &secretCreatedAt}
+
+	tests := []struct {
+		name      string
+		issuedAt  time.Time
+		expiresAt int64
+		sa        *db.ServiceAccount
+		wantErr   bool
+	}{
+		{name: "valid", issuedAt: now.Add(-time.Minute), expiresAt: now.Add(time.Hour).Unix(), sa: sa},
+		{name: "issued with the secret", issuedAt: secretCreatedAt, expiresAt: now.Add(time.Hour).Unix(), sa: sa},
+		{name: "expired", issuedAt: now.Add(-2 * time.Hour), expiresAt: now.Add(-time.Minute).Unix(), sa: sa, wantErr: true},
+		{name: "no expiry", issuedAt: now.Add(-time.Minute), sa: sa, wantErr: true},
+		{name: "issued before the secret", issuedAt: secretCreatedAt.Add(-time.Minute), expiresAt: now.Add(time.Hour).Unix(), sa: sa, wantErr: true},
+		{name: "secret revoked", issuedAt: now.Add(-time.Minute), expiresAt: now.Add(time.Hour).Unix(), sa: &db.ServiceAccount{}, wantErr: true},
+	}
+	for _, tt := range tests {
+		u := &userClaim{ExternalUserID: serviceAccountUserID(1), IssuedAt: tt.issuedAt.Unix(), ExpiresAt: tt.expiresAt}
+		err := checkServiceAccountToken(u, tt.sa, now)
+		if tt.wantErr {
+			assert.Error(t, err, tt.name)
+		} else {
+			assert.NoError(t, err, tt.name)
+		}
+	}
+}
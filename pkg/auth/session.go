@@ -0,0 +1,356 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	dexApi "github.com/dexidp/dex/api/v2"
+	envoyauth "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
+	envoytype "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	"github.com/gogo/googleapis/google/rpc"
+	"github.com/opengovern/opengovernance/pkg/auth/audit"
+	"github.com/opengovern/opengovernance/pkg/auth/db"
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/status"
+)
+
+const (
+	sessionStateTTL        = 15 * time.Second
+	sessionTouchPeriod     = time.Minute
+	sessionPrunePeriod     = time.Hour
+	localUserIDPrefix      = "dex|"
+	localConnectorID       = "local"
+	maxSessionUserAgentLen = 256
+	// envoyPartialBodyHeader is set by envoy when the forwarded body was cut at max_request_bytes
+	envoyPartialBodyHeader = "x-envoy-auth-partial-body"
+)
+
+var (
+	errSessionRevoked = errors.New("session revoked")
+	errTokenTooOld    = errors.New("token is older than the max token age")
+	errNoIssueTime    = errors.New("token has no issue time")
+	errUserLockedOut  = errors.New("user is locked out")
+	errMFARequired    = errors.New("multi-factor authentication required")
+)
+
+// mfaMethods are the authentication methods of the amr claim counting as multi-factor, see RFC 8176.
+var mfaMethods = []string{"mfa", "otp", "hwk", "swk", "sms", "sc"}
+
+// sessionState is the revocation list consulted on every checked request.
+type sessionState struct {
+	settings      db.SessionSettings
+	revokedAt     *time.Time
+	userRevokedAt map[string]time.Time
+	revokedTokens map[string]bool
+}
+
+type sessionStateCache struct {
+	mu        sync.Mutex
+	state     *sessionState
+	expiresAt time.Time
+	prunedAt  time.Time
+
+	// touched keeps the last time each session was written, the sessions are written at most once per sessionTouchPeriod
+	touched map[string]time.Time
+}
+
+func (s *Server) loadSessionState() (*sessionState, error) {
+	settings, err := s.db.GetSessionSettings()
+	if err != nil {
+		return nil, err
+	}
+	revokedAt, err := s.db.GetSessionsRevokedAt()
+	if err != nil {
+		return nil, err
+	}
+	revocations, err := s.db.ListUserSessionRevocations()
+	if err != nil {
+		return nil, err
+	}
+	tokens, err := s.db.ListRevokedTokens()
+	if err != nil {
+		return nil, err
+	}
+
+	state := &sessionState{
+		settings:      settings,
+		revokedAt:     revokedAt,
+		userRevokedAt: make(map[string]time.Time, len(revocations)),
+		revokedTokens: make(map[string]bool, len(tokens)),
+	}
+	for _, r := range revocations {
+		state.userRevokedAt[r.UserID] = r.RevokedAt
+	}
+	for _, t := range tokens {
+		state.revokedTokens[t.TokenID] = true
+	}
+	return state, nil
+}
+
+// sessionState returns the cached session state. The last known state is kept if it can not be loaded.
+func (s *Server) sessionState() (*sessionState, error) {
+	c := &s.sessionStateCache
+	c.mu.Lock()
+	state, expiresAt := c.state, c.expiresAt
+	c.mu.Unlock()
+	if state != nil && time.Now().Before(expiresAt) {
+		return state, nil
+	}
+
+	loaded, err := s.loadSessionState()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err != nil {
+		if c.state == nil {
+			return nil, err
+		}
+		s.logger.Error("failed to load session state", zap.Error(err))
+		c.expiresAt = time.Now().Add(sessionStateTTL / 4)
+		return c.state, nil
+	}
+	c.state, c.expiresAt = loaded, time.Now().Add(sessionStateTTL)
+
+	if time.Since(c.prunedAt) > sessionPrunePeriod {
+		c.prunedAt = time.Now()
+		for id, t := range c.touched {
+			if time.Since(t) > sessionTouchPeriod {
+				delete(c.touched, id)
+			}
+		}
+		go func() {
+			if err := s.db.DeleteExpiredSessions(time.Now()); err != nil {
+				s.logger.Error("failed to delete expired sessions", zap.Error(err))
+			}
+		}()
+	}
+	return c.state, nil
+}
+
+func (s *Server) invalidateSessionState() {
+	s.sessionStateCache.mu.Lock()
+	s.sessionStateCache.expiresAt = time.Time{}
+	s.sessionStateCache.mu.Unlock()
+}
+
+// tokenSessionID identifies the session of a token, by its jti claim or by a hash of the token.
+func tokenSessionID(user *userClaim, authHeader string) string {
+	if user.TokenID != "" {
+		return user.TokenID
+	}
+	hash := sha256.Sum256([]byte(strings.TrimSpace(strings.TrimPrefix(authHeader, "Bearer "))))
+	return hex.EncodeToString(hash[:16])
+}
+
+func localUserID(email string) string {
+	return localUserIDPrefix + strings.ToLower(strings.TrimSpace(email))
+}
+
+func unixTime(sec int64) *time.Time {
+	if sec == 0 {
+		return nil
+	}
+	t := time.Unix(sec, 0)
+	return &t
+}
+
+// checkSession rejects the revoked and too old tokens of the users and, if required, the ID tokens without
+// multi-factor authentication. Tokens without an issue time are rejected, they could not be checked against
+// the revocation times nor the max token age. The lockout only stops new password logins, the issued tokens
+// stay valid.
+func (s *Server) checkSession(user *userClaim, sessionID string) error {
+	state, err := s.sessionState()
+	if err != nil {
+		return err
+	}
+	if state.revokedTokens[sessionID] {
+		return errSessionRevoked
+	}
+
+	if user.IssuedAt <= 0 {
+		return errNoIssueTime
+	}
+	issuedAt := time.Unix(user.IssuedAt, 0)
+	if state.revokedAt != nil && issuedAt.Before(*state.revokedAt) {
+		return errSessionRevoked
+	}
+	if revokedAt, ok := state.userRevokedAt[user.ExternalUserID]; ok && issuedAt.Before(revokedAt) {
+		return errSessionRevoked
+	}
+	maxAge := time.Duration(state.settings.MaxTokenAgeMinutes) * time.Minute
+	if maxAge > 0 && time.Since(issuedAt) > maxAge {
+		return errTokenTooOld
+	}
+
+	if state.settings.RequireMFA && user.idTokenClaims != nil {
+		if !slices.ContainsFunc(claimValues(user.idTokenClaims, "amr"), func(method string) bool {
+			return slices.Contains(mfaMethods, strings.ToLower(method))
+		}) {
+			return errMFARequired
+		}
+	}
+	return nil
+}
+
+// touchSession records the use of the session of the user.
+func (s *Server) touchSession(req *envoyauth.CheckRequest, user *userClaim, sessionID string) {
+	now := time.Now()
+	c := &s.sessionStateCache
+	c.mu.Lock()
+	if c.touched == nil {
+		c.touched = map[string]time.Time{}
+	}
+	if last, ok := c.touched[sessionID]; ok && now.Before(last.Add(sessionTouchPeriod)) {
+		c.mu.Unlock()
+		return
+	}
+	c.touched[sessionID] = now
+	c.mu.Unlock()
+
+	userAgent := req.GetAttributes().GetRequest().GetHttp().GetHeaders()["user-agent"]
+	if len(userAgent) > maxSessionUserAgentLen {
+		userAgent = userAgent[:maxSessionUserAgentLen]
+	}
+	err := s.db.TouchUserSession(db.UserSession{
+		TokenID:     sessionID,
+		UserID:      user.ExternalUserID,
+		Email:       user.Email,
+		IssuedAt:    unixTime(user.IssuedAt),
+		ExpiresAt:   unixTime(user.ExpiresAt),
+		FirstSeenAt: now,
+		LastSeenAt:  now,
+		SourceIP:    checkSourceIP(req),
+		UserAgent:   userAgent,
+	})
+	if err != nil {
+		s.logger.Error("failed to update session", zap.String("userId", user.ExternalUserID), zap.Error(err))
+	}
+}
+
+// recordLoginFailure counts a failed local password login of the user, locking it out once the threshold
+// of the session settings is reached.
+func (s *Server) recordLoginFailure(email string) (*db.LoginLockout, error) {
+	settings, err := s.db.GetSessionSettings()
+	if err != nil {
+		return nil, err
+	}
+	if settings.LockoutThreshold <= 0 {
+		return nil, nil
+	}
+	lockout, err := s.db.RecordLoginFailure(localUserID(email), settings.LockoutThreshold,
+		time.Duration(settings.LockoutDurationMinutes)*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	if lockout.LockedUntil != nil {
+		s.logger.Warn("user locked out after failed logins",
+			zap.String("email", email),
+			zap.Int("failedAttempts", lockout.FailedAttempts),
+			zap.Time("lockedUntil", *lockout.LockedUntil))
+	}
+	return lockout, nil
+}
+
+// dexPasswordLoginPath is the path dex receives the local password login form on, under the path of its issuer.
+func dexPasswordLoginPath(issuer string) string {
+	u, err := url.Parse(issuer)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSuffix(u.Path, "/") + "/auth/" + localConnectorID + "/login"
+}
+
+// isPasswordLogin reports whether the request posts the login form of the dex local password connector.
+func (s *Server) isPasswordLogin(httpRequest *envoyauth.AttributeContext_HttpRequest) bool {
+	if s.dexPasswordLoginPath == "" || httpRequest.GetMethod() != http.MethodPost {
+		return false
+	}
+	path, _, _ := strings.Cut(httpRequest.GetPath(), "?")
+	return path == s.dexPasswordLoginPath
+}
+
+// checkPasswordLogin guards the dex local password login, read from the request body forwarded by envoy. The
+// locked out users are rejected before dex checks their password, otherwise the password is checked against dex
+// to count the failures of the existing users toward the lockout and dex answers the login itself. Logins are
+// refused while envoy does not forward their whole body, the lockout could not be enforced on them.
+func (s *Server) checkPasswordLogin(ctx context.Context, req *envoyauth.CheckRequest) *envoyauth.CheckResponse {
+	allow := &envoyauth.CheckResponse{Status: &status.Status{Code: int32(rpc.OK)}}
+
+	httpRequest := req.GetAttributes().GetRequest().GetHttp()
+	body := httpRequest.GetBody()
+	if body == "" {
+		body = string(httpRequest.GetRawBody())
+	}
+	if body == "" || httpRequest.GetHeaders()[envoyPartialBodyHeader] == "true" {
+		s.logger.Error("password login body not forwarded, the ext_authz filter of envoy needs with_request_body",
+			zap.String("path", httpRequest.GetPath()))
+		return deniedResponse(rpc.UNAVAILABLE, http.StatusServiceUnavailable)
+	}
+
+	form, err := url.ParseQuery(body)
+	email := strings.ToLower(strings.TrimSpace(form.Get("login")))
+	if err != nil || email == "" || form.Get("password") == "" {
+		return allow
+	}
+
+	lockout, err := s.db.GetLoginLockout(localUserID(email))
+	if err != nil {
+		s.logger.Error("failed to get lockout", zap.Error(err))
+		return deniedResponse(rpc.UNAVAILABLE, http.StatusServiceUnavailable)
+	}
+	if lockout != nil && lockout.LockedUntil != nil && time.Now().Before(*lockout.LockedUntil) {
+		s.recordCheckEvent(req, &userClaim{Email: email, ExternalUserID: localUserID(email)},
+			audit.OutcomeDenied, errUserLockedOut.Error())
+		return deniedResponse(rpc.PERMISSION_DENIED, http.StatusLocked)
+	}
+
+	resp, err := s.dexClient.VerifyPassword(ctx, &dexApi.VerifyPasswordReq{
+		Email:    email,
+		Password: form.Get("password"),
+	})
+	if err != nil {
+		s.logger.Error("failed to validate dex password", zap.Error(err))
+		return allow
+	}
+	if resp.NotFound {
+		return allow
+	}
+	if !resp.Verified {
+		lockout, err := s.recordLoginFailure(email)
+		if err != nil {
+			s.logger.Error("failed to record login failure", zap.Error(err))
+		}
+		if lockout != nil && lockout.LockedUntil != nil {
+			return deniedResponse(rpc.PERMISSION_DENIED, http.StatusLocked)
+		}
+		return allow
+	}
+
+	if lockout != nil {
+		if err := s.db.ResetLoginLockout(localUserID(email)); err != nil {
+			s.logger.Error("failed to reset lockout", zap.Error(err))
+		}
+	}
+	return allow
+}
+
+func deniedResponse(code rpc.Code, httpStatus int) *envoyauth.CheckResponse {
+	return &envoyauth.CheckResponse{
+		Status: &status.Status{
+			Code: int32(code),
+		},
+		HttpResponse: &envoyauth.CheckResponse_DeniedResponse{
+			DeniedResponse: &envoyauth.DeniedHttpResponse{
+				Status: &envoytype.HttpStatus{Code: envoytype.StatusCode(httpStatus)},
+				Body:   http.StatusText(httpStatus),
+			},
+		},
+	}
+}
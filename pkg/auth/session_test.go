@@ -0,0 +1,87 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	envoyauth "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
+	"github.com/gogo/googleapis/google/rpc"
+	"github.com/opengovern/opengovernance/pkg/auth/db"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestCheckSession(t *testing.T) {
+	now := time.Now()
+	s := &Server{}
+	s.sessionStateCache.expiresAt = now.Add(time.Hour)
+	s.sessionStateCache.state = &sessionState{
+		settings:      db.SessionSettings{MaxTokenAgeMinutes: 60},
+		userRevokedAt: map[string]time.Time{"dex|bob@example.com": now.Add(-10 * time.Minute)},
+		revokedTokens: map[string]bool{"revoked": true},
+	}
+
+	user := func(email string, issuedAgo time.Duration) *userClaim {
+		return &userClaim{Email: email, ExternalUserID: "dex|" + email, IssuedAt: now.Add(-issuedAgo).Unix()}
+	}
+	assert.NoError(t, s.checkSession(user("alice@example.com", time.Minute), "a"))
+	assert.ErrorIs(t, s.checkSession(user("alice@example.com", time.Minute), "revoked"), errSessionRevoked)
+	assert.ErrorIs(t, s.checkSession(user("alice@example.com", 2*time.Hour), "a"), errTokenTooOld)
+	assert.ErrorIs(t, s.checkSession(user("bob@example.com", 20*time.Minute), "b"), errSessionRevoked)
+	assert.NoError(t, s.checkSession(user("bob@example.com", 5*time.Minute), "b"))
+	assert.ErrorIs(t, s.checkSession(&userClaim{Email: "alice@example.com", ExternalUserID: "dex|alice@example.com"}, "a"),
+		errNoIssueTime)
+
+	s.sessionStateCache.state.settings.RequireMFA = true
+	withClaims := user("alice@example.com", time.Minute)
+	withClaims.idTokenClaims = map[string]any{"amr": []any{"pwd"}}
+	assert.ErrorIs(t, s.checkSession(withClaims, "a"), errMFARequired)
+	withClaims.idTokenClaims = map[string]any{"amr": []any{"pwd", "otp"}}
+	assert.NoError(t, s.checkSession(withClaims, "a"))
+}
+
+func TestIsPasswordLogin(t *testing.T) {
+	s := &Server{dexPasswordLoginPath: dexPasswordLoginPath("https://example.com/dex/")}
+	assert.Equal(t, "/dex/auth/local/login", s.dexPasswordLoginPath)
+
+	tests := []struct {
+		method string
+		path   string
+		want   bool
+	}{
+		{method: http.MethodPost, path: "/dex/auth/local/login", want: true},
+		{method: http.MethodPost, path: "/dex/auth/local/login?back=&state=abc", want: true},
+		{method: http.MethodGet, path: "/dex/auth/local/login", want: false},
+		{method: http.MethodPost, path: "/dex/auth/oidc/login", want: false},
+		{method: http.MethodPost, path: "/auth/api/v3/user/create", want: false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, s.isPasswordLogin(&envoyauth.AttributeContext_HttpRequest{Method: tt.method, Path: tt.path}),
+			tt.method+" "+tt.path)
+	}
+}
+
+func TestCheckPasswordLoginWithoutBody(t *testing.T) {
+	s := &Server{logger: zap.NewNop()}
+	request := func(body string, headers map[string]string) *envoyauth.CheckRequest {
+		return &envoyauth.CheckRequest{Attributes: &envoyauth.AttributeContext{
+			Request: &envoyauth.AttributeContext_Request{Http: &envoyauth.AttributeContext_HttpRequest{
+				Method:  http.MethodPost,
+				Path:    "/dex/auth/local/login",
+				Body:    body,
+				Headers: headers,
+			}},
+		}}
+	}
+
+	// the lockout can not be enforced without the body, the login is refused instead of let through
+	for name, req := range map[string]*envoyauth.CheckRequest{
+		"no body":      request("", nil),
+		"partial body": request("login=alice%40example.com&pass", map[string]string{envoyPartialBodyHeader: "true"}),
+	} {
+		resp := s.checkPasswordLogin(context.Background(), req)
+		assert.Equal(t, int32(rpc.UNAVAILABLE), resp.GetStatus().GetCode(), name)
+	}
+}
@@ -27,6 +27,7 @@ type ServerConfig struct {
 	Onboard       config.KaytuService
 	Inventory     config.KaytuService
 	Metadata      config.KaytuService
+	Auth          config.KaytuService
 	OpenAI        OpenAI
 	Http          config.HttpServer
 
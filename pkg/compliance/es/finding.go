@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"github.com/opengovern/opengovernance/pkg/analytics/es/resourcecollection"
 	"github.com/opengovern/opengovernance/pkg/compliance/api"
 	"github.com/opengovern/opengovernance/pkg/types"
 	"go.uber.org/zap"
@@ -96,12 +97,15 @@ type FindingsCountQueryHit struct {
 	} `json:"aggregations"`
 }
 
-func FindingsCountByControlID(ctx context.Context, logger *zap.Logger, client opengovernance.Client, resourceIDs []string, provider []source.Type, connectionID []string, notConnectionID []string, resourceTypes []string, benchmarkID []string, controlID []string, severity []types.FindingSeverity, lastTransitionFrom *time.Time, lastTransitionTo *time.Time, evaluatedAtFrom *time.Time, evaluatedAtTo *time.Time, stateActive []bool, conformanceStatuses []types.ConformanceStatus) (map[string]map[string]int64, error) {
+func FindingsCountByControlID(ctx context.Context, logger *zap.Logger, client opengovernance.Client, resourceIDs []string, kaytuResourceIDs []string, provider []source.Type, connectionID []string, notConnectionID []string, resourceTypes []string, benchmarkID []string, controlID []string, severity []types.FindingSeverity, lastTransitionFrom *time.Time, lastTransitionTo *time.Time, evaluatedAtFrom *time.Time, evaluatedAtTo *time.Time, stateActive []bool, conformanceStatuses []types.ConformanceStatus) (map[string]map[string]int64, error) {
 	idx := types.FindingsIndex
 	var filters []opengovernance.BoolFilter
 	if len(resourceIDs) > 0 {
 		filters = append(filters, opengovernance.NewTermsFilter("resourceID", resourceIDs))
 	}
+	if len(kaytuResourceIDs) > 0 {
+		filters = append(filters, resourcecollection.MembersFilter("kaytuResourceID", kaytuResourceIDs))
+	}
 	if len(resourceTypes) > 0 {
 		filters = append(filters, opengovernance.NewTermsFilter("resourceType", resourceTypes))
 	}
@@ -221,7 +225,7 @@ func FindingsCountByControlID(ctx context.Context, logger *zap.Logger, client op
 	return controlIDCount, nil
 }
 
-func FindingsQuery(ctx context.Context, logger *zap.Logger, client opengovernance.Client, resourceIDs []string, provider []source.Type,
+func FindingsQuery(ctx context.Context, logger *zap.Logger, client opengovernance.Client, resourceIDs []string, kaytuResourceIDs []string, provider []source.Type,
 	connectionID []string, notConnectionID []string, resourceTypes []string, benchmarkID []string, controlID []string,
 	severity []types.FindingSeverity, lastTransitionFrom *time.Time, lastTransitionTo *time.Time,
 	evaluatedAtFrom *time.Time, evaluatedAtTo *time.Time, stateActive []bool, conformanceStatuses []types.ConformanceStatus,
@@ -323,6 +327,9 @@ func FindingsQuery(ctx context.Context, logger *zap.Logger, client opengovernanc
 	if len(resourceIDs) > 0 {
 		filters = append(filters, opengovernance.NewTermsFilter("resourceID", resourceIDs))
 	}
+	if len(kaytuResourceIDs) > 0 {
+		filters = append(filters, resourcecollection.MembersFilter("kaytuResourceID", kaytuResourceIDs))
+	}
 	if len(resourceTypes) > 0 {
 		filters = append(filters, opengovernance.NewTermsFilter("resourceType", resourceTypes))
 	}
@@ -523,7 +530,7 @@ type FindingFiltersAggregationResponse struct {
 }
 
 func FindingsFiltersQuery(ctx context.Context, logger *zap.Logger, client opengovernance.Client,
-	resourceIDs []string, connector []source.Type, connectionID []string, notConnectionID []string,
+	resourceIDs []string, kaytuResourceIDs []string, connector []source.Type, connectionID []string, notConnectionID []string,
 	resourceTypes []string, benchmarkID []string, controlID []string, severity []types.FindingSeverity,
 	lastTransitionFrom *time.Time, lastTransitionTo *time.Time,
 	evaluatedAtFrom *time.Time, evaluatedAtTo *time.Time,
@@ -535,6 +542,9 @@ func FindingsFiltersQuery(ctx context.Context, logger *zap.Logger, client opengo
 	if len(resourceIDs) > 0 {
 		filters = append(filters, opengovernance.NewTermsFilter("resourceID", resourceIDs))
 	}
+	if len(kaytuResourceIDs) > 0 {
+		filters = append(filters, resourcecollection.MembersFilter("kaytuResourceID", kaytuResourceIDs))
+	}
 	if len(resourceTypes) > 0 {
 		filters = append(filters, opengovernance.NewTermsFilter("resourceType", resourceTypes))
 	}
@@ -1441,7 +1451,7 @@ func FetchFindingByID(ctx context.Context, logger *zap.Logger, client opengovern
 	return &resp.Hits.Hits[0].Source, nil
 }
 
-func FindingsQueryV2(ctx context.Context, logger *zap.Logger, client opengovernance.Client, resourceIDs []string, notResourceIDs []string,
+func FindingsQueryV2(ctx context.Context, logger *zap.Logger, client opengovernance.Client, resourceIDs []string, notResourceIDs []string, kaytuResourceIDs []string,
 	provider []source.Type, connectionID []string, notConnectionID []string, resourceTypes []string, notResourceTypes []string,
 	benchmarkID []string, notBenchmarkID []string, controlID []string, notControlID []string, severity []types.FindingSeverity,
 	notSeverity []types.FindingSeverity, lastTransitionFrom *time.Time, lastTransitionTo *time.Time, notLastTransitionFrom *time.Time,
@@ -1528,6 +1538,9 @@ func FindingsQueryV2(ctx context.Context, logger *zap.Logger, client opengoverna
 	if len(resourceIDs) > 0 {
 		filters = append(filters, opengovernance.NewTermsFilter("resourceID", resourceIDs))
 	}
+	if len(kaytuResourceIDs) > 0 {
+		filters = append(filters, resourcecollection.MembersFilter("kaytuResourceID", kaytuResourceIDs))
+	}
 	if len(notResourceIDs) > 0 {
 		filters = append(filters, opengovernance.NewBoolMustNotFilter(opengovernance.NewTermsFilter("resourceID", notResourceIDs)))
 	}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	helmv2 "github.com/fluxcd/helm-controller/api/v2beta1"
+	"github.com/opengovern/opengovernance/pkg/analytics/resourcecollection"
+	"github.com/opengovern/opengovernance/pkg/auth/audit"
 	metadataClient "github.com/opengovern/opengovernance/pkg/metadata/client"
 	"github.com/opengovern/opengovernance/services/migrator/db/model"
 	"github.com/sashabaranov/go-openai"
@@ -39,6 +41,10 @@ type HttpHandler struct {
 	metadataClient  metadataClient.MetadataServiceClient
 	openAIClient    *openai.Client
 	kubeClient      client.Client
+	auditRecorder   *audit.Recorder
+
+	// memberCache keeps the members of the resource collections requests are scoped to
+	memberCache resourcecollection.MemberCache
 }
 
 func NewKubeClient() (client.Client, error) {
@@ -146,6 +152,7 @@ func InitializeHttpHandler(
 	h.inventoryClient = inventoryClient.NewInventoryServiceClient(conf.Inventory.BaseURL)
 	h.metadataClient = metadataClient.NewMetadataServiceClient(conf.Metadata.BaseURL)
 	h.openAIClient = openai.NewClient(conf.OpenAI.Token)
+	h.auditRecorder = audit.NewServiceRecorder(ctx, logger, "compliance", conf.Auth.BaseURL)
 
 	kubeClient, err := NewKubeClient()
 	if err != nil {
@@ -16,6 +16,7 @@ import (
 	httpserver2 "github.com/opengovern/og-util/pkg/httpserver"
 	"github.com/opengovern/og-util/pkg/model"
 	"github.com/opengovern/og-util/pkg/source"
+	"github.com/opengovern/opengovernance/pkg/auth/audit"
 	"github.com/opengovern/opengovernance/pkg/compliance/api"
 	"github.com/opengovern/opengovernance/pkg/compliance/db"
 	"github.com/opengovern/opengovernance/pkg/compliance/es"
@@ -58,7 +59,9 @@ const (
 )
 
 func (h *HttpHandler) Register(e *echo.Echo) {
-	v1 := e.Group("/api/v1")
+	e.Use(audit.Middleware(h.auditRecorder))
+
+	v1 := e.Group("/api/v1", checkBenchmarkScope)
 
 	benchmarks := v1.Group("/benchmarks")
 
@@ -121,7 +124,7 @@ func (h *HttpHandler) Register(e *echo.Echo) {
 	ai := v1.Group("/ai")
 	ai.POST("/control/:controlID/remediation", httpserver2.AuthorizeHandler(h.GetControlRemediation, authApi.ViewerRole))
 
-	v3 := e.Group("/api/v3")
+	v3 := e.Group("/api/v3", checkBenchmarkScope)
 
 	v3.GET("/benchmarks/tags", httpserver2.AuthorizeHandler(h.ListBenchmarksTags, authApi.ViewerRole))
 	v3.POST("/benchmarks", httpserver2.AuthorizeHandler(h.ListBenchmarksFiltered, authApi.ViewerRole))
@@ -150,6 +153,38 @@ func (h *HttpHandler) Register(e *echo.Echo) {
 	v3.GET("/benchmarks/:benchmark_id/nested", httpserver2.AuthorizeHandler(h.ListBenchmarksNestedForBenchmark, authApi.ViewerRole))
 }
 
+// checkBenchmarkScope rejects requests for a benchmark outside the benchmark scope of the caller.
+func checkBenchmarkScope(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		for _, param := range []string{"benchmark_id", "benchmarkId"} {
+			if benchmarkID := ctx.Param(param); benchmarkID != "" {
+				if err := utils.CheckAccessToBenchmarkID(ctx, benchmarkID); err != nil {
+					return err
+				}
+			}
+		}
+		return next(ctx)
+	}
+}
+
+// scopedResourceIDs returns the member resources of the resource collections the request is scoped to, nil if
+// the request is not scoped to resource collections.
+func (h *HttpHandler) scopedResourceIDs(echoCtx echo.Context) ([]string, error) {
+	return utils.ScopeResourceIDs(echoCtx, func(resourceCollectionIDs []string) ([]string, error) {
+		return h.memberCache.ResourceIDs(echoCtx.Request().Context(), h.logger, h.client, resourceCollectionIDs)
+	})
+}
+
+// resolveResourceIDs narrows the requested resources to the resource collection scope of the request.
+func (h *HttpHandler) resolveResourceIDs(echoCtx echo.Context, resourceIDs []string) ([]string, error) {
+	scopedResourceIDs, err := h.scopedResourceIDs(echoCtx)
+	if err != nil {
+		h.logger.Error("failed to get the members of the scoped resource collections", zap.Error(err))
+		return nil, err
+	}
+	return utils.ResolveResourceIDs(scopedResourceIDs, resourceIDs)
+}
+
 func bindValidate(ctx echo.Context, i any) error {
 	if err := ctx.Bind(i); err != nil {
 		return err
@@ -236,10 +271,18 @@ func (h *HttpHandler) GetFindings(echoCtx echo.Context) error {
 	if err != nil {
 		return err
 	}
-	//req.Filters.ConnectionID, err = httpserver2.ResolveConnectionIDs(echoCtx, req.Filters.ConnectionID)
-	//if err != nil {
-	//	return err
-	//}
+	req.Filters.ConnectionID, err = httpserver2.ResolveConnectionIDs(echoCtx, req.Filters.ConnectionID)
+	if err != nil {
+		return err
+	}
+	req.Filters.BenchmarkID, err = utils.ResolveBenchmarkIDs(echoCtx, req.Filters.BenchmarkID)
+	if err != nil {
+		return err
+	}
+	kaytuResourceIDs, err := h.scopedResourceIDs(echoCtx)
+	if err != nil {
+		return err
+	}
 
 	var response api.GetFindingsResponse
 
@@ -293,7 +336,7 @@ func (h *HttpHandler) GetFindings(echoCtx echo.Context) error {
 		allSourcesMap[src.ID.String()] = &src
 	}
 
-	res, totalCount, err := es.FindingsQuery(ctx, h.logger, h.client, req.Filters.ResourceID, req.Filters.Connector,
+	res, totalCount, err := es.FindingsQuery(ctx, h.logger, h.client, req.Filters.ResourceID, kaytuResourceIDs, req.Filters.Connector,
 		req.Filters.ConnectionID, req.Filters.NotConnectionID, req.Filters.ResourceTypeID, req.Filters.BenchmarkID,
 		req.Filters.ControlID, req.Filters.Severity, lastEventFrom, lastEventTo, evaluatedAtFrom, evaluatedAtTo,
 		req.Filters.StateActive, esConformanceStatuses, req.Sort, req.Limit, req.AfterSortKey, req.Filters.JobID)
@@ -451,6 +494,13 @@ func (h *HttpHandler) GetSingleResourceFinding(echoCtx echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	}
 	kaytuResourceID := req.KaytuResourceId
+	scopedResourceIDs, err := h.scopedResourceIDs(echoCtx)
+	if err != nil {
+		return err
+	}
+	if err := utils.CheckAccessToResourceID(scopedResourceIDs, kaytuResourceID); err != nil {
+		return err
+	}
 
 	lookupResourceRes, err := es.FetchLookupByResourceIDBatch(ctx, h.client, []string{kaytuResourceID})
 	if err != nil {
@@ -736,6 +786,14 @@ func (h *HttpHandler) GetFindingFilterValues(echoCtx echo.Context) error {
 	if err != nil {
 		return err
 	}
+	req.BenchmarkID, err = utils.ResolveBenchmarkIDs(echoCtx, req.BenchmarkID)
+	if err != nil {
+		return err
+	}
+	kaytuResourceIDs, err := h.scopedResourceIDs(echoCtx)
+	if err != nil {
+		return err
+	}
 
 	if len(req.ConformanceStatus) == 0 {
 		req.ConformanceStatus = []api.ConformanceStatus{api.ConformanceStatusFailed}
@@ -817,7 +875,7 @@ func (h *HttpHandler) GetFindingFilterValues(echoCtx echo.Context) error {
 	}
 
 	possibleFilters, err := es.FindingsFiltersQuery(ctx, h.logger, h.client,
-		req.ResourceID, req.Connector, req.ConnectionID, req.NotConnectionID,
+		req.ResourceID, kaytuResourceIDs, req.Connector, req.ConnectionID, req.NotConnectionID,
 		req.ResourceTypeID,
 		req.BenchmarkID, req.ControlID,
 		req.Severity,
@@ -1748,6 +1806,14 @@ func (h *HttpHandler) GetFindingEvents(echoCtx echo.Context) error {
 	if err != nil {
 		return err
 	}
+	req.Filters.BenchmarkID, err = utils.ResolveBenchmarkIDs(echoCtx, req.Filters.BenchmarkID)
+	if err != nil {
+		return err
+	}
+	req.Filters.KaytuResourceID, err = h.resolveResourceIDs(echoCtx, req.Filters.KaytuResourceID)
+	if err != nil {
+		return err
+	}
 
 	var response api.GetFindingEventsResponse
 
@@ -1989,6 +2055,14 @@ func (h *HttpHandler) GetFindingEventFilterValues(echoCtx echo.Context) error {
 	if err != nil {
 		return err
 	}
+	req.BenchmarkID, err = utils.ResolveBenchmarkIDs(echoCtx, req.BenchmarkID)
+	if err != nil {
+		return err
+	}
+	req.KaytuResourceID, err = h.resolveResourceIDs(echoCtx, req.KaytuResourceID)
+	if err != nil {
+		return err
+	}
 
 	if len(req.ConformanceStatus) == 0 {
 		req.ConformanceStatus = []api.ConformanceStatus{api.ConformanceStatusFailed}
@@ -2559,7 +2633,10 @@ func (h *HttpHandler) ListBenchmarksSummary(echoCtx echo.Context) error {
 	}
 
 	connectors := source.ParseTypes(httpserver2.QueryArrayParam(echoCtx, "connector"))
-	resourceCollections := httpserver2.QueryArrayParam(echoCtx, "resourceCollection")
+	resourceCollections, err := utils.ResolveResourceCollectionIDs(echoCtx, httpserver2.QueryArrayParam(echoCtx, "resourceCollection"))
+	if err != nil {
+		return err
+	}
 	timeAt := time.Now()
 	if timeAtStr := echoCtx.QueryParam("timeAt"); timeAtStr != "" {
 		timeAtInt, err := strconv.ParseInt(timeAtStr, 10, 64)
@@ -2784,7 +2861,10 @@ func (h *HttpHandler) GetBenchmarkSummary(echoCtx echo.Context) error {
 	}
 
 	connectors := source.ParseTypes(httpserver2.QueryArrayParam(echoCtx, "connector"))
-	resourceCollections := httpserver2.QueryArrayParam(echoCtx, "resourceCollection")
+	resourceCollections, err := utils.ResolveResourceCollectionIDs(echoCtx, httpserver2.QueryArrayParam(echoCtx, "resourceCollection"))
+	if err != nil {
+		return err
+	}
 	timeAt := time.Now()
 	if timeAtStr := echoCtx.QueryParam("timeAt"); timeAtStr != "" {
 		timeAtInt, err := strconv.ParseInt(timeAtStr, 10, 64)
@@ -3254,7 +3334,10 @@ func (h *HttpHandler) GetBenchmarkTrend(echoCtx echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "too many connection IDs")
 	}
 	connectors := source.ParseTypes(httpserver2.QueryArrayParam(echoCtx, "connector"))
-	resourceCollections := httpserver2.QueryArrayParam(echoCtx, "resourceCollection")
+	resourceCollections, err := utils.ResolveResourceCollectionIDs(echoCtx, httpserver2.QueryArrayParam(echoCtx, "resourceCollection"))
+	if err != nil {
+		return err
+	}
 	endTime := time.Now()
 	if endTimeStr := echoCtx.QueryParam("endTime"); endTimeStr != "" {
 		endTimeInt, err := strconv.ParseInt(endTimeStr, 10, 64)
@@ -3471,12 +3554,16 @@ func (h *HttpHandler) ListControlsFiltered(echoCtx echo.Context) error {
 		for _, c := range controls {
 			controlIDs = append(controlIDs, c.ID)
 		}
+		kaytuResourceIDs, err := h.scopedResourceIDs(echoCtx)
+		if err != nil {
+			return err
+		}
 		if req.FindingFilters != nil {
 			benchmarksFilter := benchmarks
 			if len(req.FindingFilters.BenchmarkID) > 0 {
 				benchmarksFilter = req.FindingFilters.BenchmarkID
 			}
-			fRes, err = es.FindingsCountByControlID(ctx, h.logger, h.client, req.FindingFilters.ResourceID,
+			fRes, err = es.FindingsCountByControlID(ctx, h.logger, h.client, req.FindingFilters.ResourceID, kaytuResourceIDs,
 				req.FindingFilters.Connector, connectionIDs, req.FindingFilters.NotConnectionID,
 				req.FindingFilters.ResourceTypeID, benchmarksFilter, controlIDs, req.FindingFilters.Severity,
 				lastEventFrom, lastEventTo, evaluatedAtFrom, evaluatedAtTo, req.FindingFilters.StateActive, esConformanceStatuses)
@@ -3484,7 +3571,7 @@ func (h *HttpHandler) ListControlsFiltered(echoCtx echo.Context) error {
 				return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 			}
 		} else {
-			fRes, err = es.FindingsCountByControlID(ctx, h.logger, h.client, nil, nil, connectionIDs, nil,
+			fRes, err = es.FindingsCountByControlID(ctx, h.logger, h.client, nil, kaytuResourceIDs, nil, connectionIDs, nil,
 				nil, benchmarks, controlIDs, nil, lastEventFrom, lastEventTo, evaluatedAtFrom,
 				evaluatedAtTo, nil, esConformanceStatuses)
 		}
@@ -3780,8 +3867,12 @@ func (h *HttpHandler) ControlsFilteredSummary(echoCtx echo.Context) error {
 		for _, c := range controls {
 			controlIDs = append(controlIDs, c.ID)
 		}
+		kaytuResourceIDs, err := h.scopedResourceIDs(echoCtx)
+		if err != nil {
+			return err
+		}
 		if req.FindingFilters != nil {
-			fRes, err = es.FindingsCountByControlID(ctx, h.logger, h.client, req.FindingFilters.ResourceID,
+			fRes, err = es.FindingsCountByControlID(ctx, h.logger, h.client, req.FindingFilters.ResourceID, kaytuResourceIDs,
 				req.FindingFilters.Connector, req.FindingFilters.ConnectionID, req.FindingFilters.NotConnectionID,
 				req.FindingFilters.ResourceTypeID, req.FindingFilters.BenchmarkID, controlIDs, req.FindingFilters.Severity,
 				lastEventFrom, lastEventTo, evaluatedAtFrom, evaluatedAtTo, req.FindingFilters.StateActive, esConformanceStatuses)
@@ -3789,7 +3880,7 @@ func (h *HttpHandler) ControlsFilteredSummary(echoCtx echo.Context) error {
 				return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 			}
 		} else {
-			fRes, err = es.FindingsCountByControlID(ctx, h.logger, h.client, nil, nil, nil, nil,
+			fRes, err = es.FindingsCountByControlID(ctx, h.logger, h.client, nil, kaytuResourceIDs, nil, nil, nil,
 				nil, nil, controlIDs, nil, lastEventFrom, lastEventTo, evaluatedAtFrom,
 				evaluatedAtTo, nil, esConformanceStatuses)
 		}
@@ -5808,6 +5899,14 @@ func (h *HttpHandler) GetFindingsV2(echoCtx echo.Context) error {
 	if err != nil {
 		return err
 	}
+	req.Filters.BenchmarkID, err = utils.ResolveBenchmarkIDs(echoCtx, req.Filters.BenchmarkID)
+	if err != nil {
+		return err
+	}
+	kaytuResourceIDs, err := h.scopedResourceIDs(echoCtx)
+	if err != nil {
+		return err
+	}
 
 	var response api.GetFindingsResponse
 
@@ -5863,7 +5962,7 @@ func (h *HttpHandler) GetFindingsV2(echoCtx echo.Context) error {
 	//	evaluatedAtTo = utils.GetPointer(time.Unix(*req.Filters.EvaluatedAt.To, 0))
 	//}
 
-	res, totalCount, err := es.FindingsQueryV2(ctx, h.logger, h.client, req.Filters.ResourceID, req.Filters.NotResourceID, nil,
+	res, totalCount, err := es.FindingsQueryV2(ctx, h.logger, h.client, req.Filters.ResourceID, req.Filters.NotResourceID, kaytuResourceIDs, nil,
 		connectionIds, nil, req.Filters.ResourceType, req.Filters.NotResourceType, req.Filters.BenchmarkID,
 		req.Filters.NotBenchmarkID, req.Filters.ControlID, req.Filters.NotControlID,
 		req.Filters.Severity, req.Filters.NotSeverity, lastEventFrom, lastEventTo, notLastEventFrom, notLastEventTo,
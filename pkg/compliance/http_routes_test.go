@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"github.com/opengovern/og-util/pkg/httpserver"
 	"io"
+	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
@@ -19,6 +20,8 @@ import (
 
 	"github.com/labstack/echo/v4"
 	"github.com/opengovern/opengovernance/pkg/compliance/db"
+	"github.com/opengovern/opengovernance/pkg/utils"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 	"go.uber.org/zap"
 )
@@ -149,3 +152,40 @@ func TestHttpServer(t *testing.T) {
 func (s *HttpServerSuite) TestDatabaseTableStructure() {
 	time.Sleep(5 * time.Minute)
 }
+
+func TestCheckBenchmarkScope(t *testing.T) {
+	handler := checkBenchmarkScope(func(ctx echo.Context) error {
+		return ctx.NoContent(http.StatusOK)
+	})
+
+	tests := []struct {
+		name    string
+		scope   string
+		param   string
+		id      string
+		wantErr bool
+	}{
+		{name: "unscoped", param: "benchmark_id", id: "b1"},
+		{name: "in scope", scope: "b1,b2", param: "benchmark_id", id: "b2"},
+		{name: "outside", scope: "b1", param: "benchmark_id", id: "b3", wantErr: true},
+		{name: "camel case param", scope: "b1", param: "benchmarkId", id: "b3", wantErr: true},
+		{name: "no benchmark in path", scope: "b1"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if tt.scope != "" {
+			req.Header.Set(utils.XKaytuUserBenchmarksScope, tt.scope)
+		}
+		ctx := echo.New().NewContext(req, httptest.NewRecorder())
+		if tt.param != "" {
+			ctx.SetParamNames(tt.param)
+			ctx.SetParamValues(tt.id)
+		}
+		err := handler(ctx)
+		if tt.wantErr {
+			assert.Error(t, err, tt.name)
+		} else {
+			assert.NoError(t, err, tt.name)
+		}
+	}
+}
@@ -10,6 +10,8 @@ import (
 	"strings"
 
 	es2 "github.com/opengovern/og-util/pkg/es"
+	esResourceCollection "github.com/opengovern/opengovernance/pkg/analytics/es/resourcecollection"
+	"github.com/opengovern/opengovernance/pkg/analytics/resourcecollection"
 	"github.com/opengovern/opengovernance/pkg/compliance/es"
 	types2 "github.com/opengovern/opengovernance/pkg/compliance/summarizer/types"
 	es3 "github.com/opengovern/opengovernance/pkg/describe/es"
@@ -66,8 +68,9 @@ func (w *Worker) RunJob(ctx context.Context, j types2.Job) error {
 		ResourcesFindings:       make(map[string]types.ResourceFinding),
 		ResourcesFindingsIsDone: make(map[string]bool),
 
-		ResourceCollectionCache: map[string]inventoryApi.ResourceCollection{},
-		ConnectionCache:         map[string]onboardApi.Connection{},
+		ResourceCollectionCache:   map[string]inventoryApi.ResourceCollection{},
+		ResourceCollectionMembers: map[string]map[string]bool{},
+		ConnectionCache:           map[string]onboardApi.Connection{},
 	}
 
 	resourceCollections, err := w.inventoryClient.ListResourceCollections(&httpclient.Context{Ctx: ctx, UserRole: api.InternalRole})
@@ -78,6 +81,22 @@ func (w *Worker) RunJob(ctx context.Context, j types2.Job) error {
 	for _, rc := range resourceCollections {
 		rc := rc
 		jd.ResourceCollectionCache[rc.ID] = rc
+
+		if rc.Definition == nil || rc.Membership == nil {
+			continue
+		}
+		if _, exact := resourcecollection.Filters(*rc.Definition, nil); exact {
+			continue
+		}
+		members := make(map[string]bool)
+		err = resourcecollection.ForEachMember(ctx, w.logger, w.esClient, rc.ID, func(member esResourceCollection.Member) {
+			members[member.ResourceID] = true
+		})
+		if err != nil {
+			w.logger.Error("failed to list resource collection members", zap.String("resourceCollectionID", rc.ID), zap.Error(err))
+			return err
+		}
+		jd.ResourceCollectionMembers[rc.ID] = members
 	}
 
 	connections, err := w.onboardClient.ListSources(&httpclient.Context{Ctx: ctx, UserRole: api.InternalRole}, nil)
@@ -20,7 +20,9 @@ type JobDocs struct {
 	LastResourceIdType      string          `json:"-"`
 	// caches, these are not marshalled and only used
 	ResourceCollectionCache map[string]inventoryApi.ResourceCollection `json:"-"`
-	ConnectionCache         map[string]onboardApi.Connection           `json:"-"`
+	// ResourceCollectionMembers holds the member resource IDs of the collections scoped by their materialized membership
+	ResourceCollectionMembers map[string]map[string]bool       `json:"-"`
+	ConnectionCache           map[string]onboardApi.Connection `json:"-"`
 }
 
 func (jd *JobDocs) AddFinding(logger *zap.Logger, job Job,
@@ -91,81 +93,86 @@ func (jd *JobDocs) AddFinding(logger *zap.Logger, job Job,
 	for rcId, rc := range jd.ResourceCollectionCache {
 		// check if resource is in this resource collection
 		isIn := false
-		for _, filter := range rc.Filters {
-			found := false
-
-			for _, connector := range filter.Connectors {
-				if strings.ToLower(connector) == strings.ToLower(finding.Connector.String()) {
-					found = true
-					break
+		if members, ok := jd.ResourceCollectionMembers[rcId]; ok {
+			// the materialized membership is exact where the filters of the collection only select a superset
+			isIn = members[resource.ResourceID]
+		} else {
+			for _, filter := range rc.Filters {
+				found := false
+
+				for _, connector := range filter.Connectors {
+					if strings.ToLower(connector) == strings.ToLower(finding.Connector.String()) {
+						found = true
+						break
+					}
 				}
-			}
-			if !found && len(filter.Connectors) > 0 {
-				continue
-			}
-
-			found = false
-			for _, resourceType := range filter.ResourceTypes {
-				if strings.ToLower(resourceType) == strings.ToLower(finding.ResourceType) {
-					found = true
-					break
+				if !found && len(filter.Connectors) > 0 {
+					continue
 				}
-			}
-			if !found && len(filter.ResourceTypes) > 0 {
-				continue
-			}
 
-			found = false
-			for _, accountId := range filter.AccountIDs {
-				if conn, ok := jd.ConnectionCache[strings.ToLower(accountId)]; ok {
-					if strings.ToLower(conn.ID.String()) == strings.ToLower(finding.ConnectionID) {
+				found = false
+				for _, resourceType := range filter.ResourceTypes {
+					if strings.ToLower(resourceType) == strings.ToLower(finding.ResourceType) {
 						found = true
 						break
 					}
 				}
-			}
-			if !found && len(filter.AccountIDs) > 0 {
-				continue
-			}
+				if !found && len(filter.ResourceTypes) > 0 {
+					continue
+				}
 
-			found = false
-			for _, region := range filter.Regions {
-				if strings.ToLower(region) == strings.ToLower(resource.Location) {
-					found = true
-					break
+				found = false
+				for _, accountId := range filter.AccountIDs {
+					if conn, ok := jd.ConnectionCache[strings.ToLower(accountId)]; ok {
+						if strings.ToLower(conn.ID.String()) == strings.ToLower(finding.ConnectionID) {
+							found = true
+							break
+						}
+					}
+				}
+				if !found && len(filter.AccountIDs) > 0 {
+					continue
 				}
-			}
-			if !found && len(filter.Regions) > 0 {
-				continue
-			}
 
-			found = false
-			for k, v := range filter.Tags {
-				k := strings.ToLower(k)
-				v := strings.ToLower(v)
+				found = false
+				for _, region := range filter.Regions {
+					if strings.ToLower(region) == strings.ToLower(resource.Location) {
+						found = true
+						break
+					}
+				}
+				if !found && len(filter.Regions) > 0 {
+					continue
+				}
 
-				isMatch := false
-				for _, resourceTag := range resource.Tags {
-					if strings.ToLower(resourceTag.Key) == k {
-						if strings.ToLower(resourceTag.Value) == v {
-							isMatch = true
-							break
+				found = false
+				for k, v := range filter.Tags {
+					k := strings.ToLower(k)
+					v := strings.ToLower(v)
+
+					isMatch := false
+					for _, resourceTag := range resource.Tags {
+						if strings.ToLower(resourceTag.Key) == k {
+							if strings.ToLower(resourceTag.Value) == v {
+								isMatch = true
+								break
+							}
 						}
 					}
+					if !isMatch {
+						found = false
+						break
+					}
+					found = true
 				}
-				if !isMatch {
-					found = false
-					break
+
+				if !found && len(filter.Tags) > 0 {
+					continue
 				}
-				found = true
-			}
 
-			if !found && len(filter.Tags) > 0 {
-				continue
+				isIn = true
+				break
 			}
-
-			isIn = true
-			break
 		}
 		if !isIn {
 			continue
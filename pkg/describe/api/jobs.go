@@ -3,6 +3,7 @@ package api
 import (
 	"github.com/opengovern/og-util/pkg/source"
 	"github.com/opengovern/opengovernance/pkg/analytics/api"
+	"github.com/opengovern/opengovernance/pkg/auth/audit"
 	queryrunner "github.com/opengovern/opengovernance/pkg/inventory/query-runner"
 	"time"
 )
@@ -276,6 +277,7 @@ type GetAsyncQueryRunJobStatusResponse struct {
 	CreatedAt      time.Time                     `json:"created_at"`
 	UpdatedAt      time.Time                     `json:"updated_at"`
 	CreatedBy      string                        `json:"created_by"`
+	CreatedByType  audit.ActorType               `json:"created_by_type" enums:"user,service_account,system"`
 	JobStatus      queryrunner.QueryRunnerStatus `json:"job_status"`
 	FailureMessage string                        `json:"failure_message"`
 }
@@ -450,6 +452,7 @@ type ListComplianceJobsHistoryItem struct {
 	SummarizerJobs []string          `json:"summarizer_jobs"`
 	TriggerType    string            `json:"trigger_type"`
 	CreatedBy      string            `json:"created_by"`
+	CreatedByType  audit.ActorType   `json:"created_by_type" enums:"user,service_account,system"`
 	JobStatus      string            `json:"job_status"`
 	CreatedAt      time.Time         `json:"created_at"`
 	UpdatedAt      time.Time         `json:"updated_at"`
@@ -461,11 +464,12 @@ type ListComplianceJobsHistoryResponse struct {
 }
 
 type RunQueryResponse struct {
-	ID        uint                          `json:"id"`
-	CreatedAt time.Time                     `json:"created_at"`
-	QueryId   string                        `json:"query_id"`
-	CreatedBy string                        `json:"created_by"`
-	Status    queryrunner.QueryRunnerStatus `json:"status"`
+	ID            uint                          `json:"id"`
+	CreatedAt     time.Time                     `json:"created_at"`
+	QueryId       string                        `json:"query_id"`
+	CreatedBy     string                        `json:"created_by"`
+	CreatedByType audit.ActorType               `json:"created_by_type" enums:"user,service_account,system"`
+	Status        queryrunner.QueryRunnerStatus `json:"status"`
 }
 
 type GetIntegrationDiscoveryProgressRequest struct {
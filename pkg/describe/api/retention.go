@@ -0,0 +1,29 @@
+package api
+
+import "time"
+
+type RetentionTargetType string
+
+const (
+	RetentionTargetTypeIndex RetentionTargetType = "index"
+	RetentionTargetTypeTable RetentionTargetType = "table"
+)
+
+type RetentionTargetReport struct {
+	Target        string              `json:"target"`
+	Type          RetentionTargetType `json:"type" enums:"index,table"`
+	RetentionDays int                 `json:"retention_days"`
+	Cutoff        time.Time           `json:"cutoff"`
+	// Removed is the number of documents or rows deleted, or that would be deleted on a dry run
+	Removed int64  `json:"removed"`
+	Error   string `json:"error,omitempty"`
+}
+
+type RetentionReport struct {
+	DryRun            bool                    `json:"dry_run"`
+	DataRetentionDays int                     `json:"data_retention_days"`
+	StartedAt         time.Time               `json:"started_at"`
+	FinishedAt        time.Time               `json:"finished_at"`
+	TotalRemoved      int64                   `json:"total_removed"`
+	Targets           []RetentionTargetReport `json:"targets"`
+}
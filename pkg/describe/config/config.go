@@ -22,8 +22,10 @@ type SchedulerConfig struct {
 	EventHubConnectionString   string `yaml:"event_hub_connection_string"`
 	ServiceBusConnectionString string `yaml:"service_bus_connection_string"`
 	ServerlessProvider         string `yaml:"serverless_provider"`
+	RetentionDryRun            bool   `yaml:"retention_dry_run"`
 	ElasticSearch              config.ElasticSearch
 	Onboard                    config.KaytuService
+	Auth                       config.KaytuService
 	NATS                       config.NATS
 	Vault                      vault.Config `yaml:"vault" koanf:"vault"`
 }
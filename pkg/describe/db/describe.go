@@ -56,6 +56,23 @@ func (db Database) CountQueuedDescribeConnectionJobs(manuals bool) (int64, error
 	return count, nil
 }
 
+// CountRunningDescribeConnectionJobs counts the discovery jobs of the last day that have not finished yet.
+func (db Database) CountRunningDescribeConnectionJobs() (int64, error) {
+	var count int64
+	tx := db.ORM.Model(&model.DescribeConnectionJob{}).
+		Where("status IN ? AND created_at > now() - interval '1 day'", []api.DescribeResourceJobStatus{
+			api.DescribeResourceJobCreated,
+			api.DescribeResourceJobQueued,
+			api.DescribeResourceJobInProgress,
+			api.DescribeResourceJobOldResourceDeletion,
+		}).
+		Count(&count)
+	if tx.Error != nil {
+		return 0, tx.Error
+	}
+	return count, nil
+}
+
 func (db Database) CountDescribeConnectionJobsRunOverLast10Minutes(manuals bool) (int64, error) {
 	var count int64
 	tx := db.ORM.Model(&model.DescribeConnectionJob{}).
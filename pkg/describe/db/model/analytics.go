@@ -10,6 +10,7 @@ type AnalyticsJobType string
 const (
 	AnalyticsJobTypeNormal             AnalyticsJobType = "normal"
 	AnalyticsJobTypeResourceCollection AnalyticsJobType = "resource_collection"
+	AnalyticsJobTypeTagPolicy          AnalyticsJobType = "tag_policy"
 )
 
 type AnalyticsJob struct {
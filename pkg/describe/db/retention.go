@@ -0,0 +1,83 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/opengovern/opengovernance/pkg/compliance/runner"
+	"github.com/opengovern/opengovernance/pkg/describe/api"
+	"github.com/opengovern/opengovernance/pkg/describe/db/model"
+	queryrunner "github.com/opengovern/opengovernance/pkg/inventory/query-runner"
+	"gorm.io/gorm"
+)
+
+type RetentionTable string
+
+const (
+	RetentionTableDescribeConnectionJobs RetentionTable = "describe_connection_jobs"
+	RetentionTableComplianceRunners      RetentionTable = "compliance_runners"
+	RetentionTableQueryRunnerJobs        RetentionTable = "query_runner_jobs"
+)
+
+var RetentionTables = []RetentionTable{
+	RetentionTableDescribeConnectionJobs,
+	RetentionTableComplianceRunners,
+	RetentionTableQueryRunnerJobs,
+}
+
+// finishedJobsOlderThan selects the rows of the table that reached a final status before t.
+// Jobs that are still queued or running are never pruned.
+func (db Database) finishedJobsOlderThan(table RetentionTable, t time.Time) (*gorm.DB, any, error) {
+	switch table {
+	case RetentionTableDescribeConnectionJobs:
+		return db.ORM.Model(&model.DescribeConnectionJob{}).Where("updated_at < ?", t).
+			Where("status IN ?", []api.DescribeResourceJobStatus{
+				api.DescribeResourceJobSucceeded,
+				api.DescribeResourceJobFailed,
+				api.DescribeResourceJobTimeout,
+				api.DescribeResourceJobCanceled,
+			}), &model.DescribeConnectionJob{}, nil
+	case RetentionTableComplianceRunners:
+		return db.ORM.Model(&model.ComplianceRunner{}).Where("updated_at < ?", t).
+			Where("status IN ?", []runner.ComplianceRunnerStatus{
+				runner.ComplianceRunnerSucceeded,
+				runner.ComplianceRunnerFailed,
+				runner.ComplianceRunnerTimeOut,
+				runner.ComplianceRunnerCanceled,
+			}), &model.ComplianceRunner{}, nil
+	case RetentionTableQueryRunnerJobs:
+		return db.ORM.Model(&model.QueryRunnerJob{}).Where("updated_at < ?", t).
+			Where("status IN ?", []queryrunner.QueryRunnerStatus{
+				queryrunner.QueryRunnerSucceeded,
+				queryrunner.QueryRunnerFailed,
+				queryrunner.QueryRunnerTimeOut,
+				queryrunner.QueryRunnerCanceled,
+			}), &model.QueryRunnerJob{}, nil
+	}
+	return nil, nil, fmt.Errorf("unknown retention table: %s", table)
+}
+
+func (db Database) CountFinishedJobsOlderThan(table RetentionTable, t time.Time) (int64, error) {
+	tx, _, err := db.finishedJobsOlderThan(table, t)
+	if err != nil {
+		return 0, err
+	}
+	var count int64
+	tx = tx.Unscoped().Count(&count)
+	if tx.Error != nil {
+		return 0, tx.Error
+	}
+	return count, nil
+}
+
+func (db Database) DeleteFinishedJobsOlderThan(table RetentionTable, t time.Time) (int64, error) {
+	tx, value, err := db.finishedJobsOlderThan(table, t)
+	if err != nil {
+		return 0, err
+	}
+	tx = tx.Unscoped().Delete(value)
+	if tx.Error != nil {
+		return 0, tx.Error
+	}
+	return tx.RowsAffected, nil
+}
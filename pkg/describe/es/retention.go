@@ -0,0 +1,63 @@
+package es
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/opengovern/og-util/pkg/opengovernance-es-sdk"
+	"github.com/opensearch-project/opensearch-go/v2/opensearchutil"
+)
+
+func olderThanQuery(timeField string, cutoff int64) map[string]any {
+	return map[string]any{
+		"query": map[string]any{
+			"range": map[string]any{
+				timeField: map[string]any{
+					"lt": cutoff,
+				},
+			},
+		},
+	}
+}
+
+// CountDocumentsOlderThan returns the number of documents in the index whose timeField is before cutoff.
+// The cutoff must be in the same unit the field is stored in.
+func CountDocumentsOlderThan(ctx context.Context, client opengovernance.Client, index, timeField string, cutoff int64) (int64, error) {
+	es := client.ES()
+	res, err := es.Count(
+		es.Count.WithContext(ctx),
+		es.Count.WithIndex(index),
+		es.Count.WithBody(opensearchutil.NewJSONReader(olderThanQuery(timeField, cutoff))),
+	)
+	defer opengovernance.CloseSafe(res)
+	if err != nil {
+		return 0, err
+	} else if err := opengovernance.CheckError(res); err != nil {
+		if opengovernance.IsIndexNotFoundErr(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		return 0, fmt.Errorf("read response: %w", err)
+	}
+	var response opengovernance.CountResponse
+	if err := json.Unmarshal(b, &response); err != nil {
+		return 0, fmt.Errorf("unmarshal response: %w", err)
+	}
+	return response.Count, nil
+}
+
+// DeleteDocumentsOlderThan deletes the documents in the index whose timeField is before cutoff and
+// returns how many were removed.
+func DeleteDocumentsOlderThan(ctx context.Context, client opengovernance.Client, index, timeField string, cutoff int64) (int64, error) {
+	res, err := opengovernance.DeleteByQuery(ctx, client.ES(), []string{index}, olderThanQuery(timeField, cutoff))
+	if err != nil {
+		return 0, err
+	}
+	return int64(res.Deleted), nil
+}
@@ -31,6 +31,7 @@ import (
 	"github.com/opengovern/og-util/pkg/ticker"
 	"github.com/opengovern/og-util/proto/src/golang"
 	"github.com/opengovern/opengovernance/pkg/analytics"
+	"github.com/opengovern/opengovernance/pkg/auth/audit"
 	"github.com/opengovern/opengovernance/pkg/checkup"
 	checkupAPI "github.com/opengovern/opengovernance/pkg/checkup/api"
 	"github.com/opengovern/opengovernance/pkg/compliance/client"
@@ -42,9 +43,9 @@ import (
 	"github.com/opengovern/opengovernance/pkg/describe/db/model"
 	"github.com/opengovern/opengovernance/pkg/describe/schedulers/compliance"
 	"github.com/opengovern/opengovernance/pkg/describe/schedulers/discovery"
+	"github.com/opengovern/opengovernance/pkg/describe/schedulers/retention"
 	inventoryClient "github.com/opengovern/opengovernance/pkg/inventory/client"
 	metadataClient "github.com/opengovern/opengovernance/pkg/metadata/client"
-	"github.com/opengovern/opengovernance/pkg/metadata/models"
 	onboardClient "github.com/opengovern/opengovernance/pkg/onboard/client"
 	"github.com/opengovern/opengovernance/pkg/utils"
 	workspaceClient "github.com/opengovern/opengovernance/pkg/workspace/client"
@@ -110,14 +111,14 @@ type Scheduler struct {
 	httpServer *HttpServer
 	grpcServer *grpc.Server
 
-	describeIntervalHours      time.Duration
-	fullDiscoveryIntervalHours time.Duration
-	costDiscoveryIntervalHours time.Duration
+	describeIntervalHours      intervalDuration
+	fullDiscoveryIntervalHours intervalDuration
+	costDiscoveryIntervalHours intervalDuration
 	describeTimeoutHours       int64
 	checkupIntervalHours       int64
 	mustSummarizeIntervalHours int64
-	analyticsIntervalHours     time.Duration
-	complianceIntervalHours    time.Duration
+	analyticsIntervalHours     intervalDuration
+	complianceIntervalHours    intervalDuration
 
 	logger           *zap.Logger
 	workspaceClient  workspaceClient.WorkspaceServiceClient
@@ -149,6 +150,7 @@ type Scheduler struct {
 	complianceScheduler  *compliance.JobScheduler
 	discoveryScheduler   *discovery.Scheduler
 	queryRunnerScheduler *queryrunnerscheduler.JobScheduler
+	retentionManager     *retention.Manager
 	conf                 config.SchedulerConfig
 }
 
@@ -264,21 +266,21 @@ func InitializeScheduler(
 		s.logger.Error("Failed to parse describe interval hours", zap.Error(err))
 		return nil, err
 	}
-	s.describeIntervalHours = time.Duration(describeIntervalHours) * time.Hour
+	s.describeIntervalHours.SetDefault(time.Duration(describeIntervalHours) * time.Hour)
 
 	fullDiscoveryIntervalHours, err := strconv.ParseInt(FullDiscoveryIntervalHours, 10, 64)
 	if err != nil {
 		s.logger.Error("Failed to parse full discovery interval hours", zap.Error(err))
 		return nil, err
 	}
-	s.fullDiscoveryIntervalHours = time.Duration(fullDiscoveryIntervalHours) * time.Hour
+	s.fullDiscoveryIntervalHours.SetDefault(time.Duration(fullDiscoveryIntervalHours) * time.Hour)
 
 	costDiscoveryIntervalHours, err := strconv.ParseInt(CostDiscoveryIntervalHours, 10, 64)
 	if err != nil {
 		s.logger.Error("Failed to parse cost discovery interval hours", zap.Error(err))
 		return nil, err
 	}
-	s.costDiscoveryIntervalHours = time.Duration(costDiscoveryIntervalHours) * time.Hour
+	s.costDiscoveryIntervalHours.SetDefault(time.Duration(costDiscoveryIntervalHours) * time.Hour)
 
 	s.describeTimeoutHours, err = strconv.ParseInt(describeTimeoutHours, 10, 64)
 	if err != nil {
@@ -303,9 +305,9 @@ func InitializeScheduler(
 		s.logger.Error("Failed to parse analytics interval hours", zap.Error(err))
 		return nil, err
 	}
-	s.analyticsIntervalHours = time.Duration(analyticsIntervalHours) * time.Hour
+	s.analyticsIntervalHours.SetDefault(time.Duration(analyticsIntervalHours) * time.Hour)
 
-	s.complianceIntervalHours = time.Duration(conf.ComplianceIntervalHours) * time.Hour
+	s.complianceIntervalHours.SetDefault(time.Duration(conf.ComplianceIntervalHours) * time.Hour)
 
 	s.metadataClient = metadataClient.NewMetadataServiceClient(MetadataBaseURL)
 	s.workspaceClient = workspaceClient.NewWorkspaceClient(WorkspaceBaseURL)
@@ -349,9 +351,11 @@ func InitializeScheduler(
 		s.logger,
 		s.complianceClient,
 		s.onboardClient,
+		s.inventoryClient,
 		s.db,
 		s.es,
 	)
+	s.retentionManager = retention.New(s.logger, s.db, s.es, s.metadataClient, conf.RetentionDryRun)
 	return s, nil
 }
 
@@ -407,69 +411,7 @@ func (s *Scheduler) Run(ctx context.Context) error {
 
 	var wg sync.WaitGroup
 
-	httpCtx := &httpclient.Context{
-		UserRole: authAPI.ViewerRole,
-	}
-	httpCtx.Ctx = ctx
-	describeJobIntM, err := s.metadataClient.GetConfigMetadata(httpCtx, models.MetadataKeyDescribeJobInterval)
-	if err != nil {
-		s.logger.Error("failed to set describe interval due to error", zap.Error(err))
-	} else {
-		if v, ok := describeJobIntM.GetValue().(int); ok {
-			s.describeIntervalHours = time.Duration(v) * time.Hour
-			s.logger.Info("set describe interval", zap.Int64("interval", int64(s.describeIntervalHours.Hours())))
-		} else {
-			s.logger.Error("failed to set describe interval due to invalid type", zap.String("type", string(describeJobIntM.GetType())))
-		}
-	}
-
-	fullDiscoveryJobIntM, err := s.metadataClient.GetConfigMetadata(httpCtx, models.MetadataKeyFullDiscoveryJobInterval)
-	if err != nil {
-		s.logger.Error("failed to set describe interval due to error", zap.Error(err))
-	} else {
-		if v, ok := fullDiscoveryJobIntM.GetValue().(int); ok {
-			s.fullDiscoveryIntervalHours = time.Duration(v) * time.Hour
-			s.logger.Info("set describe interval", zap.Int64("interval", int64(s.fullDiscoveryIntervalHours.Hours())))
-		} else {
-			s.logger.Error("failed to set describe interval due to invalid type", zap.String("type", string(fullDiscoveryJobIntM.GetType())))
-		}
-	}
-
-	costDiscoveryJobIntM, err := s.metadataClient.GetConfigMetadata(httpCtx, models.MetadataKeyCostDiscoveryJobInterval)
-	if err != nil {
-		s.logger.Error("failed to set describe interval due to error", zap.Error(err))
-	} else {
-		if v, ok := costDiscoveryJobIntM.GetValue().(int); ok {
-			s.costDiscoveryIntervalHours = time.Duration(v) * time.Hour
-			s.logger.Info("set describe interval", zap.Int64("interval", int64(s.costDiscoveryIntervalHours.Hours())))
-		} else {
-			s.logger.Error("failed to set describe interval due to invalid type", zap.String("type", string(costDiscoveryJobIntM.GetType())))
-		}
-	}
-
-	analyticsJobIntM, err := s.metadataClient.GetConfigMetadata(httpCtx, models.MetadataKeyMetricsJobInterval)
-	if err != nil {
-		s.logger.Error("failed to set describe interval due to error", zap.Error(err))
-	} else {
-		if v, ok := analyticsJobIntM.GetValue().(int); ok {
-			s.analyticsIntervalHours = time.Duration(v) * time.Hour
-			s.logger.Info("set analytics interval", zap.Int64("interval", int64(s.analyticsIntervalHours.Hours())))
-		} else {
-			s.logger.Error("failed to set analytics interval due to invalid type", zap.String("type", string(analyticsJobIntM.GetType())))
-		}
-	}
-
-	complianceJobIntM, err := s.metadataClient.GetConfigMetadata(httpCtx, models.MetadataKeyComplianceJobInterval)
-	if err != nil {
-		s.logger.Error("failed to set describe interval due to error", zap.Error(err))
-	} else {
-		if v, ok := complianceJobIntM.GetValue().(int); ok {
-			s.complianceIntervalHours = time.Duration(v) * time.Hour
-			s.logger.Info("set compliance interval", zap.Int64("interval", int64(s.complianceIntervalHours.Hours())))
-		} else {
-			s.logger.Error("failed to set compliance interval due to invalid type", zap.String("type", string(complianceJobIntM.GetType())))
-		}
-	}
+	s.loadIntervals(ctx)
 
 	s.logger.Info("starting scheduler")
 
@@ -489,6 +431,9 @@ func (s *Scheduler) Run(ctx context.Context) error {
 	utils.EnsureRunGoroutine(func() {
 		s.RunAnalyticsJobScheduler(ctx)
 	})
+	utils.EnsureRunGoroutine(func() {
+		s.RunTagPolicyJobScheduler(ctx)
+	})
 
 	wg.Add(1)
 	utils.EnsureRunGoroutine(func() {
@@ -524,7 +469,7 @@ func (s *Scheduler) Run(ctx context.Context) error {
 		s.db,
 		s.jq,
 		s.es,
-		s.complianceIntervalHours,
+		s.complianceIntervalHours.Get,
 	)
 	s.complianceScheduler.Run(ctx)
 	utils.EnsureRunGoroutine(func() {
@@ -548,6 +493,12 @@ func (s *Scheduler) Run(ctx context.Context) error {
 	utils.EnsureRunGoroutine(func() {
 		s.RunScheduledJobCleanup()
 	})
+	utils.EnsureRunGoroutine(func() {
+		s.retentionManager.Run(ctx)
+	})
+	if err := s.RunConfigChangeConsumer(ctx); err != nil {
+		s.logger.Error("failed to consume config changes, interval changes need a restart", zap.Error(err))
+	}
 	utils.EnsureRunGoroutine(func() {
 		s.UpdateDescribedResourceCountScheduler()
 	})
@@ -572,6 +523,7 @@ func (s *Scheduler) Run(ctx context.Context) error {
 		}
 	}()
 
+	s.httpServer.auditRecorder = audit.NewServiceRecorder(ctx, s.logger, "scheduler", s.conf.Auth.BaseURL)
 	go func() {
 		if err := httpserver.RegisterAndStart(ctx, s.logger, s.httpServer.Address, s.httpServer); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			s.logger.Fatal("failed to serve http server", zap.Error(err))
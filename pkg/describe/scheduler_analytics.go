@@ -13,6 +13,7 @@ import (
 	"github.com/opengovern/opengovernance/pkg/analytics"
 	analyticsApi "github.com/opengovern/opengovernance/pkg/analytics/api"
 	"github.com/opengovern/opengovernance/pkg/describe/db/model"
+	"github.com/opengovern/opengovernance/pkg/describe/schedulers/discovery"
 	inventoryApi "github.com/opengovern/opengovernance/pkg/inventory/api"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
@@ -50,7 +51,7 @@ func (s *Scheduler) RunAnalyticsJobScheduler(ctx context.Context) {
 			AnalyticsJobsCount.WithLabelValues("failure").Inc()
 			continue
 		}
-		if lastJob == nil || lastJob.CreatedAt.Add(s.analyticsIntervalHours).Before(time.Now()) {
+		if lastJob == nil || lastJob.CreatedAt.Add(s.analyticsIntervalHours.Get()).Before(time.Now()) {
 			_, err := s.scheduleAnalyticsJob(model.AnalyticsJobTypeNormal, ctx)
 			if err != nil {
 				s.logger.Error("failure on scheduleAnalyticsJob", zap.Error(err))
@@ -63,7 +64,7 @@ func (s *Scheduler) RunAnalyticsJobScheduler(ctx context.Context) {
 			AnalyticsJobsCount.WithLabelValues("failure").Inc()
 			continue
 		}
-		if lastJob == nil || lastJob.CreatedAt.Add(s.analyticsIntervalHours).Before(time.Now()) {
+		if lastJob == nil || lastJob.CreatedAt.Add(s.analyticsIntervalHours.Get()).Before(time.Now()) {
 			_, err := s.scheduleAnalyticsJob(model.AnalyticsJobTypeResourceCollection, ctx)
 			if err != nil {
 				s.logger.Error("failure on scheduleAnalyticsJob", zap.Error(err))
@@ -73,6 +74,61 @@ func (s *Scheduler) RunAnalyticsJobScheduler(ctx context.Context) {
 	}
 }
 
+// RunTagPolicyJobScheduler schedules the evaluation of the tag policies once the resources of a completed discovery
+// are ingested. Running discoveries delay the evaluation up to the analytics interval so that it covers all of them.
+func (s *Scheduler) RunTagPolicyJobScheduler(ctx context.Context) {
+	s.logger.Info("Scheduling tag policy jobs on discovery completion")
+
+	t := ticker.NewTicker(JobSchedulingInterval, time.Second*10)
+	defer t.Stop()
+	for ; ; <-t.C {
+		lastDiscovery, err := s.db.GetLastSuccessfulDescribeJob()
+		if err != nil {
+			s.logger.Error("Failed to get the last successful discovery job", zap.Error(err))
+			continue
+		}
+		if lastDiscovery == nil {
+			continue
+		}
+		lastJob, err := s.db.FetchLastAnalyticsJobForJobType(model.AnalyticsJobTypeTagPolicy)
+		if err != nil {
+			s.logger.Error("Failed to find the last job to check for AnalyticsJob on tag policies", zap.Error(err))
+			AnalyticsJobsCount.WithLabelValues("failure").Inc()
+			continue
+		}
+		var lastEvaluation *time.Time
+		if lastJob != nil {
+			lastEvaluation = &lastJob.CreatedAt
+		}
+		running, err := s.db.CountRunningDescribeConnectionJobs()
+		if err != nil {
+			s.logger.Error("Failed to count running discovery jobs", zap.Error(err))
+			continue
+		}
+
+		if tagPolicyEvaluationDue(lastDiscovery.UpdatedAt, lastEvaluation, running > 0, s.analyticsIntervalHours.Get(), time.Now()) {
+			if _, err := s.scheduleAnalyticsJob(model.AnalyticsJobTypeTagPolicy, ctx); err != nil {
+				s.logger.Error("failure on scheduleAnalyticsJob", zap.Error(err))
+			}
+		}
+	}
+}
+
+// tagPolicyEvaluationDue reports whether a discovery completed after the last evaluation and its resources are
+// ingested. Running discoveries hold the evaluation back until the last one is older than maxWait.
+func tagPolicyEvaluationDue(lastDiscovery time.Time, lastEvaluation *time.Time, discoveryRunning bool, maxWait time.Duration, now time.Time) bool {
+	if lastEvaluation != nil && !lastDiscovery.After(*lastEvaluation) {
+		return false
+	}
+	if now.Before(lastDiscovery.Add(discovery.QueryCacheIngestionDelay)) {
+		return false
+	}
+	if discoveryRunning && lastEvaluation != nil && now.Before(lastEvaluation.Add(maxWait)) {
+		return false
+	}
+	return true
+}
+
 func (s *Scheduler) scheduleAnalyticsJob(analyticsJobType model.AnalyticsJobType, ctx context.Context) (uint, error) {
 	lastJob, err := s.db.FetchLastAnalyticsJobForJobType(analyticsJobType)
 	if err != nil {
@@ -140,6 +196,7 @@ func (s *Scheduler) enqueueAnalyticsJobs(job model.AnalyticsJob, ctx context.Con
 	aJobJson, err := json.Marshal(analytics.Job{
 		JobID:                 job.ID,
 		ResourceCollectionIDs: resourceCollectionIds,
+		TagPolicies:           job.Type == model.AnalyticsJobTypeTagPolicy,
 	})
 	if err != nil {
 		s.logger.Error("Failed to marshal analytics.Job", zap.Error(err))
@@ -0,0 +1,36 @@
+package describe
+
+import (
+	"testing"
+	"time"
+
+	"github.com/opengovern/opengovernance/pkg/describe/schedulers/discovery"
+	"github.com/opengovern/opengovernance/pkg/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTagPolicyEvaluationDue(t *testing.T) {
+	now := time.Now()
+	ingested := now.Add(-discovery.QueryCacheIngestionDelay)
+	before := ingested.Add(-time.Hour)
+	maxWait := 24 * time.Hour
+
+	tests := []struct {
+		name           string
+		lastDiscovery  time.Time
+		lastEvaluation *time.Time
+		running        bool
+		want           bool
+	}{
+		{name: "never evaluated", lastDiscovery: ingested, want: true},
+		{name: "discovered after the evaluation", lastDiscovery: ingested, lastEvaluation: &before, want: true},
+		{name: "evaluated after the discovery", lastDiscovery: before, lastEvaluation: &ingested},
+		{name: "still being ingested", lastDiscovery: now.Add(-time.Second), lastEvaluation: &before},
+		{name: "discovery running", lastDiscovery: ingested, lastEvaluation: &before, running: true},
+		{name: "discovery running past the max wait", lastDiscovery: ingested, lastEvaluation: utils.GetPointer(now.Add(-maxWait)), running: true, want: true},
+		{name: "discovery running, never evaluated", lastDiscovery: ingested, running: true, want: true},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, tagPolicyEvaluationDue(tt.lastDiscovery, tt.lastEvaluation, tt.running, maxWait, now), tt.name)
+	}
+}
@@ -0,0 +1,131 @@
+package describe
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	authAPI "github.com/opengovern/og-util/pkg/api"
+	"github.com/opengovern/og-util/pkg/httpclient"
+	metadataAPI "github.com/opengovern/opengovernance/pkg/metadata/api"
+	metadataClient "github.com/opengovern/opengovernance/pkg/metadata/client"
+	"github.com/opengovern/opengovernance/pkg/metadata/models"
+	"go.uber.org/zap"
+)
+
+// intervalDuration is a job interval that configuration change events update while the schedulers read it.
+type intervalDuration struct {
+	v   atomic.Int64
+	def time.Duration
+}
+
+// SetDefault sets the interval from the environment, it is restored when the workspace setting is deleted.
+func (d *intervalDuration) SetDefault(v time.Duration) {
+	d.def = v
+	d.Set(v)
+}
+
+func (d *intervalDuration) Reset() {
+	d.Set(d.def)
+}
+
+func (d *intervalDuration) Get() time.Duration {
+	return time.Duration(d.v.Load())
+}
+
+func (d *intervalDuration) Set(v time.Duration) {
+	d.v.Store(int64(v))
+}
+
+var intervalMetadataKeys = []models.MetadataKey{
+	models.MetadataKeyDescribeJobInterval,
+	models.MetadataKeyFullDiscoveryJobInterval,
+	models.MetadataKeyCostDiscoveryJobInterval,
+	models.MetadataKeyMetricsJobInterval,
+	models.MetadataKeyComplianceJobInterval,
+}
+
+func (s *Scheduler) interval(key models.MetadataKey) *intervalDuration {
+	switch key {
+	case models.MetadataKeyDescribeJobInterval:
+		return &s.describeIntervalHours
+	case models.MetadataKeyFullDiscoveryJobInterval:
+		return &s.fullDiscoveryIntervalHours
+	case models.MetadataKeyCostDiscoveryJobInterval:
+		return &s.costDiscoveryIntervalHours
+	case models.MetadataKeyMetricsJobInterval:
+		return &s.analyticsIntervalHours
+	case models.MetadataKeyComplianceJobInterval:
+		return &s.complianceIntervalHours
+	}
+	return nil
+}
+
+func (s *Scheduler) setInterval(key models.MetadataKey, hours int) {
+	interval := s.interval(key)
+	if interval == nil {
+		return
+	}
+	interval.Set(time.Duration(hours) * time.Hour)
+	s.logger.Info("set interval", zap.String("key", key.String()), zap.Int("interval", hours))
+}
+
+// resetInterval restores the interval from the environment once the workspace setting is deleted.
+func (s *Scheduler) resetInterval(key models.MetadataKey) {
+	interval := s.interval(key)
+	if interval == nil {
+		return
+	}
+	interval.Reset()
+	s.logger.Info("reset interval", zap.String("key", key.String()), zap.Duration("interval", interval.Get()))
+}
+
+// loadIntervals overrides the job intervals from the environment with the ones set in the workspace metadata.
+func (s *Scheduler) loadIntervals(ctx context.Context) {
+	httpCtx := &httpclient.Context{
+		UserRole: authAPI.ViewerRole,
+	}
+	httpCtx.Ctx = ctx
+	for _, key := range intervalMetadataKeys {
+		cnf, err := s.metadataClient.GetConfigMetadata(httpCtx, key)
+		if err != nil {
+			s.logger.Error("failed to set interval due to error", zap.String("key", key.String()), zap.Error(err))
+			continue
+		}
+		v, ok := cnf.GetValue().(int)
+		if !ok {
+			s.logger.Error("failed to set interval due to invalid type", zap.String("key", key.String()), zap.String("type", string(cnf.GetType())))
+			continue
+		}
+		s.setInterval(key, v)
+	}
+}
+
+// RunConfigChangeConsumer applies interval changes published by the metadata service as they happen.
+func (s *Scheduler) RunConfigChangeConsumer(ctx context.Context) error {
+	if err := metadataClient.SetupConfigEventsStream(ctx, s.jq); err != nil {
+		return err
+	}
+
+	_, err := metadataClient.ConsumeConfigChanges(ctx, s.jq, "describe-scheduler-config", func(event metadataAPI.ConfigChangedEvent) {
+		if event.Kind != string(models.ConfigKindMetadata) {
+			return
+		}
+		key, err := models.ParseMetadataKey(event.Key)
+		if err != nil || s.interval(key) == nil {
+			return
+		}
+		if event.Value == nil {
+			s.resetInterval(key)
+			return
+		}
+		hours, err := strconv.Atoi(*event.Value)
+		if err != nil {
+			s.logger.Error("invalid interval in config change", zap.String("key", event.Key), zap.Error(err))
+			return
+		}
+		s.setInterval(key, hours)
+	})
+	return err
+}
@@ -387,11 +387,11 @@ func (s *Scheduler) retryFailedJobs(ctx context.Context) error {
 			isFastDiscovery, isCostDiscovery = resourceType.FastDiscovery, resourceType.CostDiscovery
 		}
 
-		describeCycle := s.fullDiscoveryIntervalHours
+		describeCycle := s.fullDiscoveryIntervalHours.Get()
 		if isFastDiscovery {
-			describeCycle = s.describeIntervalHours
+			describeCycle = s.describeIntervalHours.Get()
 		} else if isCostDiscovery {
-			describeCycle = s.costDiscoveryIntervalHours
+			describeCycle = s.costDiscoveryIntervalHours.Get()
 		}
 
 		if failedJob.CreatedAt.Before(time.Now().Add(-1 * describeCycle)) {
@@ -452,16 +452,16 @@ func (s *Scheduler) describe(connection apiOnboard.Connection, resourceType stri
 
 	if job != nil {
 		if scheduled {
-			interval := s.fullDiscoveryIntervalHours
+			interval := s.fullDiscoveryIntervalHours.Get()
 			if connection.Connector == source.CloudAWS {
 				rt, _ := aws.GetResourceType(resourceType)
 				if rt != nil {
 					if rt.FastDiscovery {
 						discoveryType = model.DiscoveryType_Fast
-						interval = s.describeIntervalHours
+						interval = s.describeIntervalHours.Get()
 					} else if rt.CostDiscovery {
 						discoveryType = model.DiscoveryType_Cost
-						interval = s.costDiscoveryIntervalHours
+						interval = s.costDiscoveryIntervalHours.Get()
 					}
 				}
 			} else if connection.Connector == source.CloudAzure {
@@ -469,10 +469,10 @@ func (s *Scheduler) describe(connection apiOnboard.Connection, resourceType stri
 				if rt != nil {
 					if rt.FastDiscovery {
 						discoveryType = model.DiscoveryType_Fast
-						interval = s.describeIntervalHours
+						interval = s.describeIntervalHours.Get()
 					} else if rt.CostDiscovery {
 						discoveryType = model.DiscoveryType_Cost
-						interval = s.costDiscoveryIntervalHours
+						interval = s.costDiscoveryIntervalHours.Get()
 					}
 				}
 			}
@@ -19,6 +19,7 @@ import (
 	"github.com/opengovern/og-util/pkg/ticker"
 	"github.com/opengovern/opengovernance/pkg/describe/api"
 	"github.com/opengovern/opengovernance/pkg/describe/es"
+	"github.com/opengovern/opengovernance/pkg/types"
 	"go.uber.org/zap"
 )
 
@@ -149,6 +150,10 @@ func (s *Scheduler) RunDescribeJobResultsConsumer(ctx context.Context) error {
 
 			ResultsProcessedCount.WithLabelValues(string(result.DescribeJob.SourceType), "successful").Inc()
 
+			if result.Status == api.DescribeResourceJobSucceeded {
+				s.discoveryScheduler.InvalidateQueryCache(result.DescribeJob.ResourceType)
+			}
+
 			if err := msg.Ack(); err != nil {
 				s.logger.Error("failure while sending ack for message", zap.Error(err))
 			}
@@ -183,11 +188,11 @@ func (s *Scheduler) handleTimeoutForDiscoveryJobs() {
 			s.logger.Error(fmt.Sprintf("failed to get resource type %s", r), zap.Error(err))
 		}
 		if resourceType.FastDiscovery {
-			interval = s.describeIntervalHours
+			interval = s.describeIntervalHours.Get()
 		} else if resourceType.CostDiscovery {
-			interval = s.costDiscoveryIntervalHours
+			interval = s.costDiscoveryIntervalHours.Get()
 		} else {
-			interval = s.fullDiscoveryIntervalHours
+			interval = s.fullDiscoveryIntervalHours.Get()
 		}
 
 		if _, err := s.db.UpdateResourceTypeDescribeConnectionJobsTimedOut(r, interval); err != nil {
@@ -202,11 +207,11 @@ func (s *Scheduler) handleTimeoutForDiscoveryJobs() {
 			s.logger.Error(fmt.Sprintf("failed to get resource type %s", r), zap.Error(err))
 		}
 		if resourceType.FastDiscovery {
-			interval = s.describeIntervalHours
+			interval = s.describeIntervalHours.Get()
 		} else if resourceType.CostDiscovery {
-			interval = s.costDiscoveryIntervalHours
+			interval = s.costDiscoveryIntervalHours.Get()
 		} else {
-			interval = s.fullDiscoveryIntervalHours
+			interval = s.fullDiscoveryIntervalHours.Get()
 		}
 
 		if _, err := s.db.UpdateResourceTypeDescribeConnectionJobsTimedOut(r, interval); err != nil {
@@ -342,6 +347,14 @@ func (s *Scheduler) cleanupOldResources(ctx context.Context, res DescribeJobResu
 		}
 	}
 
+	if err := s.scheduleStaleResourceEdgesCleanup(ctx, res); err != nil {
+		s.logger.Error("failed to schedule deleting stale resource edges",
+			zap.Uint("jobId", res.JobID),
+			zap.String("connection_id", res.DescribeJob.SourceID),
+			zap.String("resource_type", res.DescribeJob.ResourceType),
+			zap.Error(err))
+	}
+
 	s.logger.Info("scheduled deleting old resources",
 		zap.Uint("jobId", res.JobID),
 		zap.String("connection_id", res.DescribeJob.SourceID),
@@ -352,8 +365,50 @@ func (s *Scheduler) cleanupOldResources(ctx context.Context, res DescribeJobResu
 	return int64(deletedCount), nil
 }
 
+// scheduleStaleResourceEdgesCleanup removes the edges extracted by previous describe jobs of the same
+// connection and resource type. Edges of resources described by this job are re-extracted by the es sink.
+func (s *Scheduler) scheduleStaleResourceEdgesCleanup(ctx context.Context, res DescribeJobResult) error {
+	filters := []opengovernance.BoolFilter{
+		opengovernance.NewTermFilter("source_id", res.DescribeJob.SourceID),
+		opengovernance.NewTermFilter("resource_type", strings.ToLower(res.DescribeJob.ResourceType)),
+		opengovernance.NewRangeFilter("resource_job_id", "", "", fmt.Sprintf("%d", res.JobID), ""),
+	}
+	root := map[string]any{
+		"query": map[string]any{
+			"bool": map[string]any{
+				"filter": filters,
+			},
+		},
+	}
+	rootJson, err := json.Marshal(root)
+	if err != nil {
+		return err
+	}
+
+	task := es.DeleteTask{
+		DiscoveryJobID: res.JobID,
+		ConnectionID:   res.DescribeJob.SourceID,
+		ResourceType:   res.DescribeJob.ResourceType,
+		Connector:      res.DescribeJob.SourceType,
+		TaskType:       es.DeleteTaskTypeQuery,
+		Query:          string(rootJson),
+		QueryIndex:     types.ResourceEdgesIndex,
+	}
+	keys, idx := task.KeysAndIndex()
+	task.EsID = es2.HashOf(keys...)
+	task.EsIndex = idx
+	if _, err := s.sinkClient.Ingest(&httpclient.Context{Ctx: ctx, UserRole: authApi.InternalRole}, []es2.Doc{task}); err != nil {
+		return err
+	}
+	return nil
+}
+
 func (s *Scheduler) cleanupDescribeResourcesForConnections(ctx context.Context, connectionIds []string) {
 	for _, connectionId := range connectionIds {
+		if err := s.cleanupResourceEdgesForConnection(connectionId); err != nil {
+			s.logger.Error("failed to delete resource edges from open-search", zap.Error(err), zap.String("connection_id", connectionId))
+		}
+
 		var searchAfter []any
 		for {
 			esResp, err := es.GetResourceIDsForAccountFromES(ctx, s.es, connectionId, searchAfter, 1000)
@@ -411,6 +466,28 @@ func (s *Scheduler) cleanupDescribeResourcesForConnections(ctx context.Context,
 	return
 }
 
+func (s *Scheduler) cleanupResourceEdgesForConnection(connectionId string) error {
+	root := map[string]any{
+		"query": map[string]any{
+			"term": map[string]any{
+				"source_id": connectionId,
+			},
+		},
+	}
+	query, err := json.Marshal(root)
+	if err != nil {
+		return err
+	}
+
+	res, err := s.es.ES().DeleteByQuery([]string{types.ResourceEdgesIndex}, bytes.NewReader(query))
+	if err != nil {
+		return err
+	}
+
+	opengovernance.CloseSafe(res)
+	return nil
+}
+
 func (s *Scheduler) cleanupDescribeResourcesForConnectionAndResourceType(connectionId, resourceType string) error {
 	root := make(map[string]any)
 	root["query"] = map[string]any{
@@ -442,7 +519,7 @@ func (s *Scheduler) cleanupDescribeResourcesForConnectionAndResourceType(connect
 
 	opengovernance.CloseSafe(res)
 
-	res, err = s.es.ES().DeleteByQuery([]string{InventorySummaryIndex}, bytes.NewReader(query))
+	res, err = s.es.ES().DeleteByQuery([]string{InventorySummaryIndex, types.ResourceEdgesIndex}, bytes.NewReader(query))
 	if err != nil {
 		return err
 	}
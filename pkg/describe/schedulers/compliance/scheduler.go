@@ -12,7 +12,7 @@ import (
 
 func (s *JobScheduler) runScheduler() error {
 	s.logger.Info("scheduleComplianceJob")
-	if s.complianceIntervalHours <= 0 {
+	if s.complianceIntervalHours() <= 0 {
 		s.logger.Info("compliance interval is negative or zero, skipping compliance job scheduling")
 		return nil
 	}
@@ -70,7 +70,7 @@ func (s *JobScheduler) runScheduler() error {
 			return err
 		}
 
-		timeAt := time.Now().Add(-s.complianceIntervalHours)
+		timeAt := time.Now().Add(-s.complianceIntervalHours())
 		if complianceJob == nil ||
 			complianceJob.CreatedAt.Before(timeAt) {
 
@@ -26,7 +26,7 @@ type JobScheduler struct {
 	db                      db.Database
 	jq                      *jq.JobQueue
 	esClient                opengovernance.Client
-	complianceIntervalHours time.Duration
+	complianceIntervalHours func() time.Duration
 }
 
 func New(
@@ -38,7 +38,7 @@ func New(
 	db db.Database,
 	jq *jq.JobQueue,
 	esClient opengovernance.Client,
-	complianceIntervalHours time.Duration,
+	complianceIntervalHours func() time.Duration,
 ) *JobScheduler {
 	return &JobScheduler{
 		runSetupNatsStreams:     runSetupNatsStreams,
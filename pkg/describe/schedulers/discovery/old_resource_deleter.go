@@ -66,6 +66,7 @@ func (s *Scheduler) runDeleter(ctx context.Context) error {
 				s.logger.Error("failed to update describe connection job status", zap.Error(err))
 				continue
 			}
+			s.InvalidateQueryCache(job.ResourceType)
 		case es.DeleteTaskTypeQuery:
 			var query any
 			err = json.Unmarshal([]byte(task.Source.Query), &query)
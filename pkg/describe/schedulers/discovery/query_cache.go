@@ -0,0 +1,97 @@
+package discovery
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	authApi "github.com/opengovern/og-util/pkg/api"
+	"github.com/opengovern/og-util/pkg/httpclient"
+	"github.com/opengovern/og-util/pkg/ticker"
+	inventoryClient "github.com/opengovern/opengovernance/pkg/inventory/client"
+	"go.uber.org/zap"
+)
+
+const (
+	// QueryCacheIngestionDelay is how long the resources of a completed discovery take to be searchable, the es sink
+	// flushes its bulk indexer every 30 seconds and the indices refresh every second.
+	QueryCacheIngestionDelay     = time.Minute
+	queryCacheInvalidateInterval = 10 * time.Second
+)
+
+type pendingInvalidation struct {
+	first time.Time
+	last  time.Time
+}
+
+// queryCacheInvalidator drops the cached results of the queries reading from a resource type once the resources of
+// its completed discoveries have been ingested, results cached before then would be stale.
+type queryCacheInvalidator struct {
+	logger          *zap.Logger
+	inventoryClient inventoryClient.InventoryServiceClient
+
+	mu      sync.Mutex
+	pending map[string]pendingInvalidation
+}
+
+// add schedules the invalidation of the resource type after the ingestion delay. A resource type discovered again
+// before then is invalidated once more after the ingestion delay of the last discovery.
+func (q *queryCacheInvalidator) add(resourceType string, now time.Time) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.pending == nil {
+		q.pending = make(map[string]pendingInvalidation)
+	}
+	p, ok := q.pending[resourceType]
+	if !ok {
+		p.first = now
+	}
+	p.last = now
+	q.pending[resourceType] = p
+}
+
+// due returns the resource types to invalidate now.
+func (q *queryCacheInvalidator) due(now time.Time) []string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var resourceTypes []string
+	for resourceType, p := range q.pending {
+		if now.Before(p.first.Add(QueryCacheIngestionDelay)) {
+			continue
+		}
+		resourceTypes = append(resourceTypes, resourceType)
+		if p.last.After(p.first) {
+			q.pending[resourceType] = pendingInvalidation{first: p.last, last: p.last}
+		} else {
+			delete(q.pending, resourceType)
+		}
+	}
+	return resourceTypes
+}
+
+func (q *queryCacheInvalidator) run(ctx context.Context) {
+	t := ticker.NewTicker(queryCacheInvalidateInterval, time.Second)
+	defer t.Stop()
+
+	for ; ; <-t.C {
+		resourceTypes := q.due(time.Now())
+		if len(resourceTypes) == 0 {
+			continue
+		}
+		count, err := q.inventoryClient.InvalidateQueryCache(&httpclient.Context{Ctx: ctx, UserRole: authApi.InternalRole}, resourceTypes)
+		if err != nil {
+			q.logger.Error("failed to invalidate query cache", zap.Strings("resourceTypes", resourceTypes), zap.Error(err))
+			for _, resourceType := range resourceTypes {
+				q.add(resourceType, time.Now())
+			}
+			continue
+		}
+		q.logger.Info("invalidated query cache", zap.Strings("resourceTypes", resourceTypes), zap.Int64("count", count))
+	}
+}
+
+// InvalidateQueryCache drops the cached results of the queries reading from the resource type once the resources
+// of its completed discovery have been ingested.
+func (s *Scheduler) InvalidateQueryCache(resourceType string) {
+	s.queryCache.add(resourceType, time.Now())
+}
@@ -0,0 +1,44 @@
+package discovery
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryCacheInvalidatorDue(t *testing.T) {
+	var q queryCacheInvalidator
+	start := time.Now()
+
+	q.add("AWS::EC2::Instance", start)
+	q.add("AWS::S3::Bucket", start.Add(30*time.Second))
+	assert.Empty(t, q.due(start.Add(QueryCacheIngestionDelay-time.Second)), "resources are still being ingested")
+
+	assert.Equal(t, []string{"AWS::EC2::Instance"}, q.due(start.Add(QueryCacheIngestionDelay)))
+	assert.Empty(t, q.due(start.Add(QueryCacheIngestionDelay)), "invalidated once")
+
+	due := q.due(start.Add(30*time.Second + QueryCacheIngestionDelay))
+	assert.Equal(t, []string{"AWS::S3::Bucket"}, due)
+	assert.Empty(t, q.pending)
+}
+
+func TestQueryCacheInvalidatorRediscovered(t *testing.T) {
+	var q queryCacheInvalidator
+	start := time.Now()
+
+	q.add("AWS::EC2::Instance", start)
+	q.add("AWS::EC2::Instance", start.Add(40*time.Second))
+	q.add("AWS::EC2::Volume", start.Add(10*time.Second))
+
+	// the first discovery is not delayed by the second one
+	due := q.due(start.Add(QueryCacheIngestionDelay + 10*time.Second))
+	sort.Strings(due)
+	assert.Equal(t, []string{"AWS::EC2::Instance", "AWS::EC2::Volume"}, due)
+
+	// the resources of the second discovery are invalidated once they are ingested as well
+	assert.Empty(t, q.due(start.Add(QueryCacheIngestionDelay+30*time.Second)))
+	assert.Equal(t, []string{"AWS::EC2::Instance"}, q.due(start.Add(QueryCacheIngestionDelay+40*time.Second)))
+	assert.Empty(t, q.pending)
+}
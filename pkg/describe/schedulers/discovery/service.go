@@ -6,6 +6,7 @@ import (
 	"github.com/opengovern/opengovernance/pkg/compliance/client"
 	config2 "github.com/opengovern/opengovernance/pkg/describe/config"
 	"github.com/opengovern/opengovernance/pkg/describe/db"
+	inventoryClient "github.com/opengovern/opengovernance/pkg/inventory/client"
 	onboardClient "github.com/opengovern/opengovernance/pkg/onboard/client"
 	"github.com/opengovern/opengovernance/pkg/utils"
 	"go.uber.org/zap"
@@ -16,18 +17,22 @@ type Scheduler struct {
 	logger           *zap.Logger
 	complianceClient client.ComplianceServiceClient
 	onboardClient    onboardClient.OnboardServiceClient
+	inventoryClient  inventoryClient.InventoryServiceClient
 	db               db.Database
 	esClient         opengovernance.Client
+	queryCache       *queryCacheInvalidator
 }
 
-func New(conf config2.SchedulerConfig, logger *zap.Logger, complianceClient client.ComplianceServiceClient, onboardClient onboardClient.OnboardServiceClient, db db.Database, esClient opengovernance.Client) *Scheduler {
+func New(conf config2.SchedulerConfig, logger *zap.Logger, complianceClient client.ComplianceServiceClient, onboardClient onboardClient.OnboardServiceClient, inventoryClient inventoryClient.InventoryServiceClient, db db.Database, esClient opengovernance.Client) *Scheduler {
 	return &Scheduler{
 		conf:             conf,
 		logger:           logger,
 		complianceClient: complianceClient,
 		onboardClient:    onboardClient,
+		inventoryClient:  inventoryClient,
 		db:               db,
 		esClient:         esClient,
+		queryCache:       &queryCacheInvalidator{logger: logger, inventoryClient: inventoryClient},
 	}
 }
 
@@ -35,4 +40,7 @@ func (s *Scheduler) Run(ctx context.Context) {
 	utils.EnsureRunGoroutine(func() {
 		s.OldResourceDeleter(ctx)
 	})
+	utils.EnsureRunGoroutine(func() {
+		s.queryCache.run(ctx)
+	})
 }
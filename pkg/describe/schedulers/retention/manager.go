@@ -0,0 +1,161 @@
+package retention
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	authAPI "github.com/opengovern/og-util/pkg/api"
+	"github.com/opengovern/og-util/pkg/httpclient"
+	"github.com/opengovern/og-util/pkg/opengovernance-es-sdk"
+	"github.com/opengovern/og-util/pkg/ticker"
+	"github.com/opengovern/opengovernance/pkg/describe/api"
+	"github.com/opengovern/opengovernance/pkg/describe/db"
+	"github.com/opengovern/opengovernance/pkg/describe/es"
+	metadataClient "github.com/opengovern/opengovernance/pkg/metadata/client"
+	"github.com/opengovern/opengovernance/pkg/metadata/models"
+	"go.uber.org/zap"
+)
+
+const RetentionInterval = 6 * time.Hour
+
+var ErrRetentionInProgress = errors.New("retention run already in progress")
+
+type Manager struct {
+	logger         *zap.Logger
+	db             db.Database
+	esClient       opengovernance.Client
+	metadataClient metadataClient.MetadataServiceClient
+	// dryRun makes the scheduled runs only report what they would remove
+	dryRun bool
+
+	runMu      sync.Mutex
+	reportMu   sync.RWMutex
+	lastReport *api.RetentionReport
+}
+
+func New(logger *zap.Logger, db db.Database, esClient opengovernance.Client, metadataClient metadataClient.MetadataServiceClient, dryRun bool) *Manager {
+	return &Manager{
+		logger:         logger.Named("retention"),
+		db:             db,
+		esClient:       esClient,
+		metadataClient: metadataClient,
+		dryRun:         dryRun,
+	}
+}
+
+func (m *Manager) Run(ctx context.Context) {
+	m.logger.Info("Scheduling retention on a timer", zap.Bool("dry_run", m.dryRun))
+
+	t := ticker.NewTicker(RetentionInterval, time.Second*10)
+	defer t.Stop()
+
+	for ; ; <-t.C {
+		report, err := m.Apply(ctx, m.dryRun)
+		if err != nil {
+			m.logger.Error("failed to apply retention", zap.Error(err))
+			continue
+		}
+		m.logger.Info("applied retention",
+			zap.Bool("dry_run", report.DryRun),
+			zap.Int("data_retention_days", report.DataRetentionDays),
+			zap.Int64("total_removed", report.TotalRemoved),
+		)
+	}
+}
+
+// LastReport returns the report of the latest run, or nil if retention has not run yet.
+func (m *Manager) LastReport() *api.RetentionReport {
+	m.reportMu.RLock()
+	defer m.reportMu.RUnlock()
+	return m.lastReport
+}
+
+// Apply removes the documents and job rows older than their retention. On a dry run nothing is
+// deleted and the report holds what would have been removed. A failing target is recorded in the
+// report and does not stop the others.
+func (m *Manager) Apply(ctx context.Context, dryRun bool) (*api.RetentionReport, error) {
+	if !m.runMu.TryLock() {
+		return nil, ErrRetentionInProgress
+	}
+	defer m.runMu.Unlock()
+
+	now := time.Now()
+	report := api.RetentionReport{
+		DryRun:            dryRun,
+		DataRetentionDays: m.dataRetentionDays(ctx),
+		StartedAt:         now,
+	}
+
+	for _, policy := range IndexPolicies {
+		days := retentionDays(report.DataRetentionDays, policy.MinDays, policy.MaxDays)
+		target := api.RetentionTargetReport{
+			Target:        policy.Index,
+			Type:          api.RetentionTargetTypeIndex,
+			RetentionDays: days,
+			Cutoff:        now.AddDate(0, 0, -days),
+		}
+		cutoff := cutoffValue(target.Cutoff, policy.Unit)
+
+		var err error
+		if dryRun {
+			target.Removed, err = es.CountDocumentsOlderThan(ctx, m.esClient, policy.Index, policy.TimeField, cutoff)
+		} else {
+			target.Removed, err = es.DeleteDocumentsOlderThan(ctx, m.esClient, policy.Index, policy.TimeField, cutoff)
+		}
+		if err != nil {
+			m.logger.Error("failed to apply index retention", zap.String("index", policy.Index), zap.Error(err))
+			target.Error = err.Error()
+		}
+		report.TotalRemoved += target.Removed
+		report.Targets = append(report.Targets, target)
+	}
+
+	for _, policy := range TablePolicies {
+		days := retentionDays(report.DataRetentionDays, policy.MinDays, policy.MaxDays)
+		target := api.RetentionTargetReport{
+			Target:        string(policy.Table),
+			Type:          api.RetentionTargetTypeTable,
+			RetentionDays: days,
+			Cutoff:        now.AddDate(0, 0, -days),
+		}
+
+		var err error
+		if dryRun {
+			target.Removed, err = m.db.CountFinishedJobsOlderThan(policy.Table, target.Cutoff)
+		} else {
+			target.Removed, err = m.db.DeleteFinishedJobsOlderThan(policy.Table, target.Cutoff)
+		}
+		if err != nil {
+			m.logger.Error("failed to apply table retention", zap.String("table", string(policy.Table)), zap.Error(err))
+			target.Error = err.Error()
+		}
+		report.TotalRemoved += target.Removed
+		report.Targets = append(report.Targets, target)
+	}
+
+	report.FinishedAt = time.Now()
+
+	m.reportMu.Lock()
+	m.lastReport = &report
+	m.reportMu.Unlock()
+
+	return &report, nil
+}
+
+func (m *Manager) dataRetentionDays(ctx context.Context) int {
+	cnf, err := m.metadataClient.GetConfigMetadata(&httpclient.Context{Ctx: ctx, UserRole: authAPI.InternalRole}, models.MetadataKeyDataRetention)
+	if err != nil {
+		if !errors.Is(err, metadataClient.ErrConfigNotFound) {
+			m.logger.Error("failed to get data retention, using default", zap.Error(err))
+		}
+		return DefaultDataRetentionDays
+	}
+	days, ok := cnf.GetValue().(int)
+	if !ok || days <= 0 {
+		m.logger.Warn("invalid data retention, using default", zap.Any("value", cnf.GetValue()))
+		return DefaultDataRetentionDays
+	}
+	return days
+}
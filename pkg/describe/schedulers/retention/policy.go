@@ -0,0 +1,63 @@
+package retention
+
+import (
+	"time"
+
+	"github.com/opengovern/opengovernance/pkg/analytics/es/spend"
+	"github.com/opengovern/opengovernance/pkg/describe/db"
+	"github.com/opengovern/opengovernance/pkg/types"
+)
+
+// DefaultDataRetentionDays is used when the data_retention_duration metadata key is not set.
+const DefaultDataRetentionDays = 366
+
+// IndexPolicy describes how documents of an index are aged out. The retention of the index is the
+// workspace data retention clamped to [MinDays, MaxDays]; a zero bound is ignored.
+type IndexPolicy struct {
+	Index     string
+	TimeField string
+	// Unit is the resolution the time field is stored in, either time.Second or time.Millisecond
+	Unit    time.Duration
+	MinDays int
+	MaxDays int
+}
+
+type TablePolicy struct {
+	Table   db.RetentionTable
+	MinDays int
+	MaxDays int
+}
+
+var IndexPolicies = []IndexPolicy{
+	{Index: types.FindingsIndex, TimeField: "evaluatedAt", Unit: time.Millisecond, MinDays: 7},
+	{Index: types.FindingEventsIndex, TimeField: "evaluatedAt", Unit: time.Millisecond, MinDays: 7},
+	{Index: types.QueryRunIndex, TimeField: "evaluatedAt", Unit: time.Millisecond, MinDays: 1, MaxDays: 30},
+	{Index: types.BenchmarkSummaryIndex, TimeField: "EvaluatedAtEpoch", Unit: time.Second, MinDays: 30},
+	// spend trends are compared year over year, so at least a year is always kept
+	{Index: spend.AnalyticsSpendConnectionSummaryIndex, TimeField: "evaluated_at", Unit: time.Millisecond, MinDays: 366},
+	{Index: spend.AnalyticsSpendConnectorSummaryIndex, TimeField: "evaluated_at", Unit: time.Millisecond, MinDays: 366},
+}
+
+var TablePolicies = []TablePolicy{
+	{Table: db.RetentionTableDescribeConnectionJobs, MinDays: 7},
+	{Table: db.RetentionTableComplianceRunners, MinDays: 7},
+	{Table: db.RetentionTableQueryRunnerJobs, MinDays: 7},
+}
+
+func retentionDays(dataRetentionDays, minDays, maxDays int) int {
+	days := dataRetentionDays
+	if minDays > 0 && days < minDays {
+		days = minDays
+	}
+	if maxDays > 0 && days > maxDays {
+		days = maxDays
+	}
+	return days
+}
+
+func cutoffValue(cutoff time.Time, unit time.Duration) int64 {
+	if unit == time.Second {
+		return cutoff.Unix()
+	}
+	return cutoff.UnixMilli()
+}
@@ -0,0 +1,45 @@
+package retention
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetentionDays(t *testing.T) {
+	tests := []struct {
+		name              string
+		dataRetentionDays int
+		minDays           int
+		maxDays           int
+		want              int
+	}{
+		{name: "unbounded", dataRetentionDays: 90, want: 90},
+		{name: "within bounds", dataRetentionDays: 90, minDays: 7, maxDays: 365, want: 90},
+		{name: "below min", dataRetentionDays: 3, minDays: 7, want: 7},
+		{name: "above max", dataRetentionDays: 90, minDays: 1, maxDays: 30, want: 30},
+		{name: "on min", dataRetentionDays: 7, minDays: 7, want: 7},
+		{name: "on max", dataRetentionDays: 30, maxDays: 30, want: 30},
+		{name: "zero bounds ignored", dataRetentionDays: 400, want: 400},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, retentionDays(tt.dataRetentionDays, tt.minDays, tt.maxDays), tt.name)
+	}
+}
+
+func TestCutoffValue(t *testing.T) {
+	cutoff := time.Date(2024, 3, 1, 12, 30, 15, 500*int(time.Millisecond), time.UTC)
+
+	tests := []struct {
+		name string
+		unit time.Duration
+		want int64
+	}{
+		{name: "seconds", unit: time.Second, want: 1709296215},
+		{name: "milliseconds", unit: time.Millisecond, want: 1709296215500},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, cutoffValue(cutoff, tt.unit), tt.name)
+	}
+}
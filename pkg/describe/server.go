@@ -12,6 +12,7 @@ import (
 	"github.com/opengovern/og-util/pkg/httpclient"
 	"github.com/opengovern/og-util/pkg/httpserver"
 	"github.com/opengovern/og-util/pkg/opengovernance-es-sdk"
+	"github.com/opengovern/opengovernance/pkg/auth/audit"
 	runner2 "github.com/opengovern/opengovernance/pkg/compliance/runner"
 	queryrunner "github.com/opengovern/opengovernance/pkg/inventory/query-runner"
 	onboardClient "github.com/opengovern/opengovernance/pkg/onboard/client"
@@ -36,6 +37,7 @@ import (
 	"github.com/opengovern/opengovernance/pkg/describe/db"
 	model2 "github.com/opengovern/opengovernance/pkg/describe/db/model"
 	"github.com/opengovern/opengovernance/pkg/describe/es"
+	"github.com/opengovern/opengovernance/pkg/describe/schedulers/retention"
 	onboardapi "github.com/opengovern/opengovernance/pkg/onboard/api"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
@@ -48,6 +50,7 @@ type HttpServer struct {
 	Scheduler     *Scheduler
 	onboardClient onboardClient.OnboardServiceClient
 	kubeClient    k8sclient.Client
+	auditRecorder *audit.Recorder
 }
 
 func NewHTTPServer(
@@ -63,6 +66,8 @@ func NewHTTPServer(
 }
 
 func (h HttpServer) Register(e *echo.Echo) {
+	e.Use(audit.Middleware(h.auditRecorder))
+
 	v1 := e.Group("/api/v1")
 
 	v1.PUT("/describe/trigger/:connection_id", httpserver.AuthorizeHandler(h.TriggerPerConnectionDescribeJob, apiAuth.AdminRole))
@@ -114,6 +119,9 @@ func (h HttpServer) Register(e *echo.Echo) {
 
 	v3.PUT("/sample/purge", httpserver.AuthorizeHandler(h.PurgeSampleData, apiAuth.AdminRole))
 
+	v3.POST("/retention/run", httpserver.AuthorizeHandler(h.RunRetention, apiAuth.AdminRole))
+	v3.GET("/retention/report", httpserver.AuthorizeHandler(h.GetRetentionReport, apiAuth.AdminRole))
+
 	v3.GET("/integration/discovery/last-job", httpserver.AuthorizeHandler(h.GetIntegrationLastDiscoveryJob, apiAuth.ViewerRole))
 }
 
@@ -178,6 +186,13 @@ func (h HttpServer) ListJobs(ctx echo.Context) error {
 		return err
 	}
 	for _, job := range describeJobs {
+		if job.ConnectionID != "" && httpserver.CheckAccessToConnectionID(ctx, job.ConnectionID) != nil {
+			continue
+		}
+		if job.JobType == "compliance" && utils.CheckAccessToBenchmarkID(ctx, job.Title) != nil {
+			continue
+		}
+
 		var jobSRC onboardapi.Connection
 		for _, src := range srcs {
 			if src.ID.String() == job.ConnectionID {
@@ -1282,6 +1297,9 @@ func (h HttpServer) GetDescribeJobsHistory(ctx echo.Context) error {
 		ctx.Logger().Errorf("bind the request: %v", err)
 		return echo.NewHTTPError(http.StatusBadRequest, "invalid request")
 	}
+	if err := httpserver.CheckAccessToConnectionID(ctx, connectionId); err != nil {
+		return err
+	}
 
 	var jobsResults []api.GetDescribeJobsHistoryResponse
 
@@ -1360,8 +1378,15 @@ func (h HttpServer) GetComplianceJobsHistory(ctx echo.Context) error {
 	}
 
 	connectionId := ctx.Param("connection_id")
+	if err := httpserver.CheckAccessToConnectionID(ctx, connectionId); err != nil {
+		return err
+	}
+	benchmarkIDs, err := utils.ResolveBenchmarkIDs(ctx, request.BenchmarkId)
+	if err != nil {
+		return err
+	}
 
-	jobs, err := h.DB.ListComplianceJobsByFilters([]string{connectionId}, request.BenchmarkId, request.JobStatus, &request.StartTime, request.EndTime)
+	jobs, err := h.DB.ListComplianceJobsByFilters([]string{connectionId}, benchmarkIDs, request.JobStatus, &request.StartTime, request.EndTime)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
@@ -1925,6 +1950,7 @@ func (h HttpServer) GetAsyncQueryRunJobStatus(ctx echo.Context) error {
 		CreatedAt:      j.CreatedAt,
 		UpdatedAt:      j.UpdatedAt,
 		CreatedBy:      j.CreatedBy,
+		CreatedByType:  audit.ActorTypeOf(j.CreatedBy),
 		JobStatus:      j.Status,
 		FailureMessage: j.FailureMessage,
 	}
@@ -1985,6 +2011,10 @@ func (h HttpServer) ListDescribeJobs(ctx echo.Context) error {
 		}
 		connectionIDs = append(connectionIDs, c.ID.String())
 	}
+	connectionIDs, err := httpserver.ResolveConnectionIDs(ctx, connectionIDs)
+	if err != nil {
+		return err
+	}
 
 	var jobsResults []api.GetDescribeJobsHistoryResponse
 
@@ -2102,8 +2132,16 @@ func (h HttpServer) ListComplianceJobs(ctx echo.Context) error {
 		}
 		connectionIDs = append(connectionIDs, c.ID.String())
 	}
+	connectionIDs, err := httpserver.ResolveConnectionIDs(ctx, connectionIDs)
+	if err != nil {
+		return err
+	}
+	benchmarkIDs, err := utils.ResolveBenchmarkIDs(ctx, request.BenchmarkId)
+	if err != nil {
+		return err
+	}
 
-	jobs, err := h.DB.ListComplianceJobsByFilters(connectionIDs, request.BenchmarkId, request.JobStatus, &request.StartTime, request.EndTime)
+	jobs, err := h.DB.ListComplianceJobsByFilters(connectionIDs, benchmarkIDs, request.JobStatus, &request.StartTime, request.EndTime)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
@@ -2541,6 +2579,9 @@ func (h HttpServer) GetDescribeJobsHistoryByIntegration(ctx echo.Context) error
 
 	connectionInfo := make(map[string]api.IntegrationInfo)
 	for _, c := range connections {
+		if httpserver.CheckAccessToConnectionID(ctx, c.ID.String()) != nil {
+			continue
+		}
 		connectionInfo[c.ID.String()] = api.IntegrationInfo{
 			IntegrationTracker: c.ID.String(),
 			Integration:        c.Connector.String(),
@@ -2651,6 +2692,13 @@ func (h HttpServer) GetComplianceJobsHistoryByIntegration(ctx echo.Context) erro
 		}
 		connections = connectionsTmp[0]
 	}
+	if err := httpserver.CheckAccessToConnectionID(ctx, connections.ID.String()); err != nil {
+		return err
+	}
+	benchmarkIDs, err := utils.ResolveBenchmarkIDs(ctx, request.BenchmarkId)
+	if err != nil {
+		return err
+	}
 
 	connectionInfo := make(map[string]api.IntegrationInfo)
 	connectionInfo[connections.ID.String()] = api.IntegrationInfo{
@@ -2662,7 +2710,7 @@ func (h HttpServer) GetComplianceJobsHistoryByIntegration(ctx echo.Context) erro
 
 	var jobsResults []api.GetComplianceJobsHistoryResponse
 	for _, c := range connectionInfo {
-		jobs, err := h.DB.ListComplianceJobsByFilters([]string{c.IntegrationTracker}, request.BenchmarkId, request.JobStatus, &request.StartTime, request.EndTime)
+		jobs, err := h.DB.ListComplianceJobsByFilters([]string{c.IntegrationTracker}, benchmarkIDs, request.JobStatus, &request.StartTime, request.EndTime)
 		if err != nil {
 			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 		}
@@ -3725,11 +3773,12 @@ func (h *HttpServer) RunQuery(ctx echo.Context) error {
 	}
 
 	response := api.RunQueryResponse{
-		ID:        jobId,
-		QueryId:   queryId,
-		CreatedAt: job.CreatedAt,
-		CreatedBy: userID,
-		Status:    job.Status,
+		ID:            jobId,
+		QueryId:       queryId,
+		CreatedAt:     job.CreatedAt,
+		CreatedBy:     userID,
+		CreatedByType: audit.ActorTypeOf(userID),
+		Status:        job.Status,
 	}
 	return ctx.JSON(http.StatusOK, response)
 }
@@ -3777,6 +3826,47 @@ func (s *HttpServer) PurgeSampleData(c echo.Context) error {
 	return c.NoContent(http.StatusOK)
 }
 
+// RunRetention godoc
+//
+//	@Summary		Apply the data retention policy
+//	@Description	Deletes findings, finding events, query run results, benchmark summaries, spend and finished job rows
+//	@Description	older than their retention. With dry_run only the number of documents and rows that would be removed is reported.
+//	@Security		BearerToken
+//	@Tags			scheduler
+//	@Produce		json
+//	@Param			dry_run	query		bool	false	"Report without deleting"
+//	@Success		200		{object}	api.RetentionReport
+//	@Router			/schedule/api/v3/retention/run [post]
+func (h HttpServer) RunRetention(ctx echo.Context) error {
+	dryRun := ctx.QueryParam("dry_run") == "true"
+
+	report, err := h.Scheduler.retentionManager.Apply(ctx.Request().Context(), dryRun)
+	if err != nil {
+		if errors.Is(err, retention.ErrRetentionInProgress) {
+			return echo.NewHTTPError(http.StatusConflict, err.Error())
+		}
+		h.Scheduler.logger.Error("failed to apply retention", zap.Error(err))
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to apply retention")
+	}
+	return ctx.JSON(http.StatusOK, report)
+}
+
+// GetRetentionReport godoc
+//
+//	@Summary	Get the report of the last retention run
+//	@Security	BearerToken
+//	@Tags		scheduler
+//	@Produce	json
+//	@Success	200	{object}	api.RetentionReport
+//	@Router		/schedule/api/v3/retention/report [get]
+func (h HttpServer) GetRetentionReport(ctx echo.Context) error {
+	report := h.Scheduler.retentionManager.LastReport()
+	if report == nil {
+		return echo.NewHTTPError(http.StatusNotFound, "retention has not run yet")
+	}
+	return ctx.JSON(http.StatusOK, report)
+}
+
 // GetIntegrationDiscoveryProgress godoc
 //
 //	@Summary	Get Integration discovery progress (number of jobs in different states)
@@ -4004,6 +4094,7 @@ func (h HttpServer) ListComplianceJobsHistory(ctx echo.Context) error {
 			SummarizerJobs: j.SummarizerJobs,
 			TriggerType:    string(j.TriggerType),
 			CreatedBy:      j.CreatedBy,
+			CreatedByType:  audit.ActorTypeOf(j.CreatedBy),
 			JobStatus:      string(j.Status),
 			CreatedAt:      j.CreatedAt,
 			UpdatedAt:      j.UpdatedAt,
@@ -25,6 +25,23 @@ type RunQueryResponse struct {
 	Query   string   `json:"query"`   // Query
 	Headers []string `json:"headers"` // Column names
 	Result  [][]any  `json:"result"`  // Result of query. in order to access a specific cell please use Result[Row][Column]
+
+	Cache *QueryResultCacheInfo `json:"cache,omitempty"` // Set when the query result can be cached
+}
+
+type QueryResultCacheInfo struct {
+	Hit      bool      `json:"hit"`       // Whether the result was served from the cache
+	CachedAt time.Time `json:"cached_at"` // When the result was computed
+	// AgeSeconds is how long ago the result was computed, no discovery of the tables the query reads from has completed since
+	AgeSeconds int64 `json:"age_seconds"`
+}
+
+type InvalidateQueryCacheRequest struct {
+	ResourceTypes []string `json:"resource_types"`
+}
+
+type InvalidateQueryCacheResponse struct {
+	InvalidatedCount int64 `json:"invalidated_count"`
 }
 
 type NamedQueryHistory struct {
@@ -43,6 +60,7 @@ type RunQueryByIDRequest struct {
 	ID          string               `json:"id"`
 	Sorts       []NamedQuerySortItem `json:"sorts"`
 	QueryParams map[string]string    `json:"query_params"`
+	BypassCache bool                 `json:"bypass_cache"` // Run the query even if a cached result exists
 }
 
 type ListQueriesFiltersResponse struct {
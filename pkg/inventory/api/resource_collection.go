@@ -23,6 +23,9 @@ type ResourceCollection struct {
 	CreatedAt   time.Time                                 `json:"created_at"`
 	Status      ResourceCollectionStatus                  `json:"status"`
 	Filters     []opengovernance.ResourceCollectionFilter `json:"filters"`
+	// Definition is set for collections created through the API, their filters are derived from it
+	Definition *ResourceCollectionDefinition `json:"definition,omitempty"`
+	Membership *ResourceCollectionMembership `json:"membership,omitempty"`
 
 	Connectors      []source.Type `json:"connectors,omitempty"`
 	LastEvaluatedAt *time.Time    `json:"last_evaluated_at,omitempty"`
@@ -55,3 +58,82 @@ type ResourceCollectionLandscapeCategory struct {
 type ResourceCollectionLandscape struct {
 	Categories []ResourceCollectionLandscapeCategory `json:"categories"`
 }
+
+// ResourceCollectionDefinition selects the resources matching all the given criteria,
+// a tag key with several values matches any of the values.
+type ResourceCollectionDefinition struct {
+	Tags          map[string][]string `json:"tags,omitempty"`
+	ResourceTypes []string            `json:"resource_types,omitempty" example:"aws::ec2::instance"`
+	Connectors    []source.Type       `json:"connectors,omitempty" example:"AWS"`
+	ConnectionIDs []string            `json:"connection_ids,omitempty"`
+	Regions       []string            `json:"regions,omitempty" example:"us-east-1"`
+	// SQLPredicate is a where clause over the kaytu_lookup table
+	SQLPredicate string `json:"sql_predicate,omitempty" example:"name like 'prod-%'"`
+}
+
+type ResourceCollectionRequest struct {
+	ID          string                       `json:"id" validate:"required"`
+	Name        string                       `json:"name" validate:"required"`
+	Description string                       `json:"description"`
+	Tags        map[string][]string          `json:"tags"`
+	Status      ResourceCollectionStatus     `json:"status"`
+	Definition  ResourceCollectionDefinition `json:"definition"`
+}
+
+type ResourceCollectionMembership struct {
+	JobID          uint       `json:"job_id"`
+	MaterializedAt *time.Time `json:"materialized_at,omitempty"`
+	MemberCount    int        `json:"member_count"`
+	AddedCount     int        `json:"added_count"`
+	RemovedCount   int        `json:"removed_count"`
+}
+
+type UpdateResourceCollectionMembershipRequest struct {
+	ResourceCollectionMembership
+	// Filters replaces the filters of collections whose definition is not expressible as filters
+	Filters []opengovernance.ResourceCollectionFilter `json:"filters,omitempty"`
+}
+
+type ResourceCollectionMember struct {
+	ResourceID   string      `json:"resource_id"`
+	ResourceName string      `json:"resource_name"`
+	ResourceType string      `json:"resource_type"`
+	ConnectionID string      `json:"connection_id"`
+	Connector    source.Type `json:"connector"`
+	Location     string      `json:"location"`
+	AddedAt      time.Time   `json:"added_at"`
+}
+
+type ListResourceCollectionMembersResponse struct {
+	TotalCount int64                      `json:"total_count"`
+	Members    []ResourceCollectionMember `json:"members"`
+}
+
+type CountResourceCollectionMembersResponse struct {
+	TotalCount     int            `json:"total_count"`
+	ResourceTypes  map[string]int `json:"resource_types"`
+	ConnectionIDs  map[string]int `json:"connection_ids"`
+	MaterializedAt *time.Time     `json:"materialized_at,omitempty"`
+}
+
+type ResourceCollectionMembershipChangeType string
+
+const (
+	ResourceCollectionMembershipChangeAdded   ResourceCollectionMembershipChangeType = "added"
+	ResourceCollectionMembershipChangeRemoved ResourceCollectionMembershipChangeType = "removed"
+)
+
+type ResourceCollectionMembershipChange struct {
+	ResourceID   string                                 `json:"resource_id"`
+	ResourceName string                                 `json:"resource_name"`
+	ResourceType string                                 `json:"resource_type"`
+	ConnectionID string                                 `json:"connection_id"`
+	Change       ResourceCollectionMembershipChangeType `json:"change"`
+	JobID        uint                                   `json:"job_id"`
+	ChangedAt    time.Time                              `json:"changed_at"`
+}
+
+type ListResourceCollectionMembershipChangesResponse struct {
+	Added   []ResourceCollectionMembershipChange `json:"added"`
+	Removed []ResourceCollectionMembershipChange `json:"removed"`
+}
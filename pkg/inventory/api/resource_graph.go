@@ -0,0 +1,36 @@
+package api
+
+import "github.com/opengovern/og-util/pkg/source"
+
+type ResourceGraphNode struct {
+	ResourceID   string      `json:"resourceID" example:"arn:aws:ec2:us-east-1:123456789012:instance/i-0123456789abcdef0"`
+	ResourceType string      `json:"resourceType" example:"aws::ec2::instance"`
+	Name         string      `json:"name,omitempty" example:"web-1"`
+	ConnectionID string      `json:"connectionID,omitempty" example:"8e0f8e7a-1b1c-4e6f-b7e4-9c6af9d2b1c8"`
+	Connector    source.Type `json:"connector,omitempty" example:"AWS"`
+	Location     string      `json:"location,omitempty" example:"us-east-1"`
+	// Distance is the number of hops from the requested resource
+	Distance int `json:"distance" example:"1"`
+	// Discovered is false when the resource is only known as the target of an edge
+	Discovered bool `json:"discovered" example:"true"`
+}
+
+type ResourceGraphEdge struct {
+	FromResourceID   string `json:"fromResourceID"`
+	FromResourceType string `json:"fromResourceType"`
+	ToResourceID     string `json:"toResourceID"`
+	ToResourceType   string `json:"toResourceType"`
+	Relation         string `json:"relation" enums:"contains,attached-to,uses-role,in-network,routes-to"`
+	ConnectionID     string `json:"connectionID"`
+}
+
+type ResourceGraphResponse struct {
+	Nodes []ResourceGraphNode `json:"nodes"`
+	Edges []ResourceGraphEdge `json:"edges"`
+}
+
+type ResourceGraphPathResponse struct {
+	Found bool                `json:"found"`
+	Nodes []ResourceGraphNode `json:"nodes"`
+	Edges []ResourceGraphEdge `json:"edges"`
+}
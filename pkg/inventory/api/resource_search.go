@@ -0,0 +1,66 @@
+package api
+
+import "github.com/opengovern/og-util/pkg/source"
+
+type SearchResourcesRequest struct {
+	// Query is matched against the name, ID (ARN for AWS resources) and tags of the resources
+	Query         string        `json:"query" example:"web"`
+	Connectors    []source.Type `json:"connectors,omitempty" example:"AWS"`
+	ConnectionIDs []string      `json:"connectionIDs,omitempty"`
+	ResourceTypes []string      `json:"resourceTypes,omitempty" example:"aws::ec2::instance"`
+	Regions       []string      `json:"regions,omitempty" example:"us-east-1"`
+	// Tags filters by tag key and any of the given values, an empty value list only requires the key
+	Tags map[string][]string `json:"tags,omitempty"`
+	// SearchAfter is the searchAfter of the previous page
+	SearchAfter []any `json:"searchAfter,omitempty"`
+	Size        int   `json:"size,omitempty" example:"20"`
+	FacetSize   int   `json:"facetSize,omitempty" example:"20"`
+}
+
+type ResourceLink struct {
+	Method string `json:"method" example:"POST"`
+	Path   string `json:"path" example:"/compliance/api/v1/findings/resource"`
+	Body   any    `json:"body,omitempty"`
+}
+
+type SearchResourceLinks struct {
+	// Findings returns the findings of the resource, see GetSingleResourceFinding
+	Findings ResourceLink `json:"findings"`
+	// Cost returns the estimated cost of the resource
+	Cost ResourceLink `json:"cost"`
+}
+
+type SearchResourceHit struct {
+	ResourceID   string              `json:"resourceID" example:"arn:aws:ec2:us-east-1:123456789012:instance/i-0123456789abcdef0"`
+	Name         string              `json:"name" example:"web-1"`
+	ResourceType string              `json:"resourceType" example:"aws::ec2::instance"`
+	Connector    source.Type         `json:"connector" example:"AWS"`
+	ConnectionID string              `json:"connectionID" example:"8e0f8e7a-1b1c-4e6f-b7e4-9c6af9d2b1c8"`
+	Location     string              `json:"location" example:"us-east-1"`
+	Tags         map[string][]string `json:"tags,omitempty"`
+	Score        float64             `json:"score" example:"4.5"`
+	Links        SearchResourceLinks `json:"links"`
+}
+
+type FacetBucket struct {
+	Key   string `json:"key" example:"aws::ec2::instance"`
+	Count int    `json:"count" example:"42"`
+}
+
+type SearchResourcesFacets struct {
+	Connectors    []FacetBucket `json:"connectors"`
+	ConnectionIDs []FacetBucket `json:"connectionIDs"`
+	ResourceTypes []FacetBucket `json:"resourceTypes"`
+	Regions       []FacetBucket `json:"regions"`
+	TagKeys       []FacetBucket `json:"tagKeys"`
+	// TagValues holds the most common values of each tag key in TagKeys
+	TagValues map[string][]FacetBucket `json:"tagValues"`
+}
+
+type SearchResourcesResponse struct {
+	TotalCount int64                 `json:"totalCount" example:"120"`
+	Resources  []SearchResourceHit   `json:"resources"`
+	Facets     SearchResourcesFacets `json:"facets"`
+	// SearchAfter is to be passed in the next request to get the next page, empty on the last page
+	SearchAfter []any `json:"searchAfter,omitempty"`
+}
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"time"
+)
+
+type SpendAnomalyContributor struct {
+	MetricID         string  `json:"metric_id" example:"spend_aws_ec2"`
+	MetricName       string  `json:"metric_name" example:"Amazon EC2"`
+	ExpectedCost     float64 `json:"expected_cost" example:"120.5"`
+	ActualCost       float64 `json:"actual_cost" example:"410.2"`
+	Deviation        float64 `json:"deviation" example:"289.7"`
+	DeviationPercent float64 `json:"deviation_percent" example:"240.4"`
+	Score            float64 `json:"score" example:"6.3"`
+	// Anomalous is false for the metrics of the connection that stayed within their baseline
+	Anomalous bool `json:"anomalous" example:"true"`
+}
+
+type SpendAnomaly struct {
+	ID               string                    `json:"id" example:"2f1c0e5bd0b7..."`
+	ConnectionID     string                    `json:"connection_id" example:"8e0f8e7a-1b1a-4e6f-8d5e-6e6f1c9c6f43"`
+	ConnectionName   string                    `json:"connection_name" example:"production"`
+	Connector        string                    `json:"connector" example:"AWS"`
+	Date             string                    `json:"date" example:"2024-05-14"`
+	ExpectedCost     float64                   `json:"expected_cost" example:"350.1"`
+	ActualCost       float64                   `json:"actual_cost" example:"640.3"`
+	Deviation        float64                   `json:"deviation" example:"290.2"`
+	DeviationPercent float64                   `json:"deviation_percent" example:"82.9"`
+	Score            float64                   `json:"score" example:"6.3"`
+	Status           string                    `json:"status" example:"open" enums:"open,acknowledged"`
+	AcknowledgedBy   string                    `json:"acknowledged_by,omitempty"`
+	AcknowledgedAt   *time.Time                `json:"acknowledged_at,omitempty"`
+	Note             string                    `json:"note,omitempty"`
+	NotifiedAt       *time.Time                `json:"notified_at,omitempty"`
+	Contributors     []SpendAnomalyContributor `json:"contributors"`
+	CreatedAt        time.Time                 `json:"created_at"`
+	UpdatedAt        time.Time                 `json:"updated_at"`
+}
+
+type AcknowledgeSpendAnomalyRequest struct {
+	Note string `json:"note" example:"Planned load test"`
+}
+
+type SpendAnomalyMute struct {
+	ID           uint       `json:"id" example:"1"`
+	ConnectionID string     `json:"connection_id" example:"8e0f8e7a-1b1a-4e6f-8d5e-6e6f1c9c6f43"`
+	MetricID     string     `json:"metric_id,omitempty" example:"spend_aws_ec2"`
+	Reason       string     `json:"reason" example:"Migration in progress"`
+	CreatedBy    string     `json:"created_by"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+type CreateSpendAnomalyMuteRequest struct {
+	ConnectionID string `json:"connection_id" validate:"required"`
+	// MetricID mutes a single spend metric of the connection, all of them when empty
+	MetricID  string     `json:"metric_id"`
+	Reason    string     `json:"reason"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
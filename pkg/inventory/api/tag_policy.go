@@ -0,0 +1,82 @@
+package api
+
+import (
+	"time"
+
+	"github.com/opengovern/og-util/pkg/source"
+)
+
+type TagPolicyKey struct {
+	Key           string   `json:"key" example:"env"`
+	Required      bool     `json:"required" example:"true"`
+	AllowedValues []string `json:"allowed_values,omitempty" example:"prod,staging,dev"`
+	ValueRegex    string   `json:"value_regex,omitempty" example:"^[a-z-]+$"`
+	// ValueCase is one of "", lower or upper
+	ValueCase string `json:"value_case,omitempty" example:"lower"`
+}
+
+type TagPolicy struct {
+	ID            string         `json:"id" example:"9a3e6bb5-1a0f-4b34-b1d4-2a4a2b2b8b3c"`
+	Title         string         `json:"title" example:"Mandatory tags"`
+	Description   string         `json:"description"`
+	Enabled       bool           `json:"enabled" example:"true"`
+	Connectors    []source.Type  `json:"connectors,omitempty" example:"AWS"`
+	ConnectionIDs []string       `json:"connection_ids,omitempty"`
+	ResourceTypes []string       `json:"resource_types,omitempty" example:"aws::ec2::instance"`
+	OwnerTagKey   string         `json:"owner_tag_key,omitempty" example:"owner"`
+	Keys          []TagPolicyKey `json:"keys"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+}
+
+type TagPolicyRequest struct {
+	Title         string         `json:"title" validate:"required"`
+	Description   string         `json:"description"`
+	Enabled       bool           `json:"enabled"`
+	Connectors    []source.Type  `json:"connectors"`
+	ConnectionIDs []string       `json:"connection_ids"`
+	ResourceTypes []string       `json:"resource_types"`
+	OwnerTagKey   string         `json:"owner_tag_key"`
+	Keys          []TagPolicyKey `json:"keys" validate:"required"`
+}
+
+type TagPolicyCoverageItem struct {
+	Key               string  `json:"key" example:"aws::ec2::instance"`
+	TotalCount        int     `json:"total_count" example:"120"`
+	CompliantCount    int     `json:"compliant_count" example:"90"`
+	NonCompliantCount int     `json:"non_compliant_count" example:"30"`
+	CoveragePercent   float64 `json:"coverage_percent" example:"75"`
+}
+
+type TagPolicyCoverageResponse struct {
+	GroupBy         string                  `json:"group_by" example:"resource_type"`
+	TotalCount      int                     `json:"total_count"`
+	CompliantCount  int                     `json:"compliant_count"`
+	CoveragePercent float64                 `json:"coverage_percent"`
+	Items           []TagPolicyCoverageItem `json:"items"`
+}
+
+type TagPolicyNonCompliantResource struct {
+	PolicyID      string      `json:"policy_id"`
+	ResourceID    string      `json:"resource_id"`
+	ResourceName  string      `json:"resource_name"`
+	ResourceType  string      `json:"resource_type"`
+	ConnectionID  string      `json:"connection_id"`
+	Connector     source.Type `json:"connector"`
+	Location      string      `json:"location"`
+	Owner         string      `json:"owner"`
+	Violations    []string    `json:"violations"`
+	ViolatingKeys []string    `json:"violating_keys"`
+	Suggestions   []string    `json:"suggestions"`
+	EvaluatedAt   time.Time   `json:"evaluated_at"`
+}
+
+type ListTagPolicyNonCompliantResourcesResponse struct {
+	TotalCount int64                           `json:"total_count"`
+	Resources  []TagPolicyNonCompliantResource `json:"resources"`
+}
+
+type TagPolicySuggestion struct {
+	Suggestion    string `json:"suggestion" example:"rename tag key Env to env"`
+	ResourceCount int    `json:"resource_count" example:"12"`
+}
@@ -24,12 +24,15 @@ type InventoryServiceClient interface {
 	ListResourceCollections(ctx *httpclient.Context) ([]api.ResourceCollection, error)
 	GetResourceCollectionMetadata(ctx *httpclient.Context, id string) (*api.ResourceCollection, error)
 	ListResourceCollectionsMetadata(ctx *httpclient.Context, ids []string) ([]api.ResourceCollection, error)
+	UpdateResourceCollectionMembership(ctx *httpclient.Context, id string, req api.UpdateResourceCollectionMembershipRequest) error
+	CountResourceCollectionMembers(ctx *httpclient.Context, id string) (*api.CountResourceCollectionMembersResponse, error)
 	ListAnalyticsMetrics(ctx *httpclient.Context, metricType *analyticsDB.MetricType) ([]api.AnalyticsMetric, error)
 	ListAnalyticsMetricsSummary(ctx *httpclient.Context, metricType *analyticsDB.MetricType, metricIds []string, connectionIds []string, startTime, endTime *time.Time) (*api.ListMetricsResponse, error)
 	ListAnalyticsMetricTrend(ctx *httpclient.Context, metricIds []string, connectionIds []string, startTime, endTime *time.Time) ([]api.ResourceTypeTrendDatapoint, error)
 	ListAnalyticsSpendTrend(ctx *httpclient.Context, metricIds []string, connectionIds []string, startTime, endTime *time.Time) ([]api.CostTrendDatapoint, error)
 	GetTablesResourceCategories(ctx *httpclient.Context, tables []string) ([]api.CategoriesTables, error)
 	GetResourceCategories(ctx *httpclient.Context, tables []string, categories []string) (*api.GetResourceCategoriesResponse, error)
+	InvalidateQueryCache(ctx *httpclient.Context, resourceTypes []string) (int64, error)
 }
 
 type inventoryClient struct {
@@ -58,6 +61,24 @@ func (s *inventoryClient) RunQuery(ctx *httpclient.Context, req api.RunQueryRequ
 	return &resp, nil
 }
 
+func (s *inventoryClient) InvalidateQueryCache(ctx *httpclient.Context, resourceTypes []string) (int64, error) {
+	url := fmt.Sprintf("%s/api/v3/query/cache/invalidate", s.baseURL)
+
+	reqBytes, err := json.Marshal(api.InvalidateQueryCacheRequest{ResourceTypes: resourceTypes})
+	if err != nil {
+		return 0, err
+	}
+
+	var resp api.InvalidateQueryCacheResponse
+	if statusCode, err := httpclient.DoRequest(ctx.Ctx, http.MethodPost, url, ctx.ToHeaders(), reqBytes, &resp); err != nil {
+		if 400 <= statusCode && statusCode < 500 {
+			return 0, echo.NewHTTPError(statusCode, err.Error())
+		}
+		return 0, err
+	}
+	return resp.InvalidatedCount, nil
+}
+
 func (s *inventoryClient) CountResources(ctx *httpclient.Context) (int64, error) {
 	url := fmt.Sprintf("%s/api/v2/resources/count", s.baseURL)
 
@@ -356,6 +377,36 @@ func (s *inventoryClient) ListResourceCollections(ctx *httpclient.Context) ([]ap
 	return response, nil
 }
 
+func (s *inventoryClient) UpdateResourceCollectionMembership(ctx *httpclient.Context, id string, req api.UpdateResourceCollectionMembershipRequest) error {
+	url := fmt.Sprintf("%s/api/v2/resource-collection/%s/membership", s.baseURL, id)
+
+	reqBytes, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	if statusCode, err := httpclient.DoRequest(ctx.Ctx, http.MethodPut, url, ctx.ToHeaders(), reqBytes, nil); err != nil {
+		if 400 <= statusCode && statusCode < 500 {
+			return echo.NewHTTPError(statusCode, err.Error())
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *inventoryClient) CountResourceCollectionMembers(ctx *httpclient.Context, id string) (*api.CountResourceCollectionMembersResponse, error) {
+	url := fmt.Sprintf("%s/api/v2/resource-collection/%s/members/count", s.baseURL, id)
+
+	var response api.CountResourceCollectionMembersResponse
+	if statusCode, err := httpclient.DoRequest(ctx.Ctx, http.MethodGet, url, ctx.ToHeaders(), nil, &response); err != nil {
+		if 400 <= statusCode && statusCode < 500 {
+			return nil, echo.NewHTTPError(statusCode, err.Error())
+		}
+		return nil, err
+	}
+	return &response, nil
+}
+
 func (s *inventoryClient) ListAnalyticsMetricTrend(ctx *httpclient.Context, metricIds []string, connectionIds []string, startTime, endTime *time.Time) ([]api.ResourceTypeTrendDatapoint, error) {
 	url := fmt.Sprintf("%s/api/v2/analytics/trend", s.baseURL)
 	firstParamAttached := false
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"github.com/opengovern/og-util/pkg/httpserver"
+	"github.com/opengovern/opengovernance/pkg/auth/audit"
 	"os"
 
 	"github.com/opengovern/og-util/pkg/config"
@@ -30,6 +31,7 @@ var (
 	OnboardBaseUrl    = os.Getenv("ONBOARD_BASE_URL")
 	ComplianceBaseUrl = os.Getenv("COMPLIANCE_BASE_URL")
 	MetadataBaseUrl   = os.Getenv("METADATA_BASE_URL")
+	AuthBaseUrl       = os.Getenv("AUTH_BASE_URL")
 
 	HttpAddress = os.Getenv("HTTP_ADDRESS")
 )
@@ -61,6 +63,7 @@ func start(ctx context.Context, cnf config3.InventoryConfig) error {
 	if err != nil {
 		return fmt.Errorf("init http handler: %w", err)
 	}
+	handler.auditRecorder = audit.NewServiceRecorder(ctx, logger, "inventory", AuthBaseUrl)
 
 	return httpserver.RegisterAndStart(ctx, logger, HttpAddress, handler)
 }
@@ -3,10 +3,12 @@ package inventory
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
+	"github.com/jackc/pgtype"
 	"github.com/lib/pq"
 	"github.com/opengovern/og-util/pkg/model"
 	"github.com/opengovern/og-util/pkg/source"
@@ -31,9 +33,15 @@ func (db Database) Initialize() error {
 		&NamedQuery{},
 		&NamedQueryTag{},
 		&NamedQueryHistory{},
+		&QueryResultCache{},
 		&ResourceTypeTag{},
 		&analyticsDb.AnalyticMetric{},
 		&analyticsDb.MetricTag{},
+		&analyticsDb.TagPolicy{},
+		&analyticsDb.TagPolicyKey{},
+		&analyticsDb.SpendAnomaly{},
+		&analyticsDb.SpendAnomalyContributor{},
+		&analyticsDb.SpendAnomalyMute{},
 		&ResourceCollection{},
 		&ResourceCollectionTag{},
 		&ResourceTypeV2{},
@@ -339,6 +347,38 @@ func (db Database) UpdateQueryHistory(query string) error {
 	return nil
 }
 
+func (db Database) GetQueryResultCache(key string) (*QueryResultCache, error) {
+	var entry QueryResultCache
+	tx := db.orm.Model(&QueryResultCache{}).Where("key = ?", key).First(&entry)
+	if tx.Error != nil {
+		if errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, tx.Error
+	}
+	return &entry, nil
+}
+
+func (db Database) UpsertQueryResultCache(entry QueryResultCache) error {
+	return db.orm.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"tables", "resource_types", "any_resource_type", "result", "created_at"}),
+	}).Create(&entry).Error
+}
+
+// InvalidateQueryResultCache removes the cached results of the queries reading from any of the resource types.
+func (db Database) InvalidateQueryResultCache(resourceTypes []string) (int64, error) {
+	for i, rt := range resourceTypes {
+		resourceTypes[i] = strings.ToLower(rt)
+	}
+	tx := db.orm.Where("any_resource_type = ? OR resource_types && ?", true, pq.StringArray(resourceTypes)).
+		Delete(&QueryResultCache{})
+	if tx.Error != nil {
+		return 0, tx.Error
+	}
+	return tx.RowsAffected, nil
+}
+
 func (db Database) ListResourceTypeTagsKeysWithPossibleValues(connectorTypes []source.Type, doSummarize *bool) (map[string][]string, error) {
 	var tags []ResourceTypeTag
 	tx := db.orm.Model(ResourceTypeTag{}).Joins("JOIN resource_types ON resource_type_tags.resource_type = resource_types.resource_type")
@@ -421,6 +461,12 @@ func (db Database) ListResourceCollections(ids []string, statuses []ResourceColl
 				return nil, err
 			}
 		}
+		if len(resourceCollections[i].DefinitionJson.Bytes) > 0 {
+			err := json.Unmarshal(resourceCollections[i].DefinitionJson.Bytes, &resourceCollections[i].Definition)
+			if err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	return resourceCollections, nil
@@ -439,10 +485,74 @@ func (db Database) GetResourceCollection(collectionID string) (*ResourceCollecti
 			return nil, err
 		}
 	}
+	if len(collection.DefinitionJson.Bytes) > 0 {
+		err := json.Unmarshal(collection.DefinitionJson.Bytes, &collection.Definition)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	return &collection, nil
 }
 
+// UpsertResourceCollection creates or replaces a resource collection defined through the API along with its tags.
+func (db Database) UpsertResourceCollection(collection ResourceCollection) error {
+	return db.orm.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"name", "description", "status", "filters_json", "definition_json", "updated_at", "deleted_at"}),
+		}).Omit("Tags").Create(&collection).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Where("resource_collection_id = ?", collection.ID).Unscoped().Delete(&ResourceCollectionTag{}).Error
+		if err != nil {
+			return err
+		}
+		for _, tag := range collection.Tags {
+			tag.ResourceCollectionID = collection.ID
+			if err := tx.Create(&tag).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteResourceCollection deletes a resource collection defined through the API, collections
+// coming from the configuration are left untouched.
+func (db Database) DeleteResourceCollection(collectionID string) (int64, error) {
+	tx := db.orm.Where("id = ? AND definition_json IS NOT NULL", collectionID).Unscoped().Delete(&ResourceCollection{})
+	if tx.Error != nil {
+		return 0, tx.Error
+	}
+	return tx.RowsAffected, nil
+}
+
+// UpdateResourceCollectionMembership stores the result of the last membership materialization,
+// filters are only replaced when given.
+func (db Database) UpdateResourceCollectionMembership(collectionID string, membership ResourceCollection, filtersJson *pgtype.JSONB) error {
+	values := map[string]any{
+		"membership_job_id": membership.MembershipJobID,
+		"materialized_at":   membership.MaterializedAt,
+		"member_count":      membership.MemberCount,
+		"added_count":       membership.AddedCount,
+		"removed_count":     membership.RemovedCount,
+	}
+	if filtersJson != nil {
+		values["filters_json"] = *filtersJson
+	}
+	tx := db.orm.Model(&ResourceCollection{}).Where("id = ?", collectionID).Updates(values)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
 func (db Database) ListNamedQueriesUniqueProviders() ([]string, error) {
 	var connectors []string
 
@@ -0,0 +1,202 @@
+package es
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/opengovern/og-util/pkg/opengovernance-es-sdk"
+	"github.com/opengovern/opengovernance/pkg/analytics/es/resourcecollection"
+)
+
+type ResourceCollectionMemberHit struct {
+	ID     string                    `json:"_id"`
+	Score  float64                   `json:"_score"`
+	Index  string                    `json:"_index"`
+	Source resourcecollection.Member `json:"_source"`
+	Sort   []any                     `json:"sort"`
+}
+
+type ResourceCollectionMembersResponse struct {
+	Hits struct {
+		Total opengovernance.SearchTotal    `json:"total"`
+		Hits  []ResourceCollectionMemberHit `json:"hits"`
+	} `json:"hits"`
+	Aggregations struct {
+		ResourceTypes ResourceSearchBuckets `json:"resource_types"`
+		ConnectionIDs ResourceSearchBuckets `json:"connection_ids"`
+	} `json:"aggregations"`
+}
+
+type ResourceCollectionMembershipChangeHit struct {
+	ID     string                              `json:"_id"`
+	Score  float64                             `json:"_score"`
+	Index  string                              `json:"_index"`
+	Source resourcecollection.MembershipChange `json:"_source"`
+	Sort   []any                               `json:"sort"`
+}
+
+type ResourceCollectionMembershipChangesResponse struct {
+	Hits struct {
+		Total opengovernance.SearchTotal              `json:"total"`
+		Hits  []ResourceCollectionMembershipChangeHit `json:"hits"`
+	} `json:"hits"`
+}
+
+func resourceCollectionMemberFilters(resourceCollectionID string, resourceTypes, connectionIDs []string) []any {
+	filters := []any{
+		map[string]any{"term": map[string]any{"resource_collection_id": resourceCollectionID}},
+	}
+	if len(resourceTypes) > 0 {
+		lowerResourceTypes := make([]string, 0, len(resourceTypes))
+		for _, rt := range resourceTypes {
+			lowerResourceTypes = append(lowerResourceTypes, strings.ToLower(rt))
+		}
+		filters = append(filters, map[string]any{"terms": map[string]any{"resource_type": lowerResourceTypes}})
+	}
+	if len(connectionIDs) > 0 {
+		filters = append(filters, map[string]any{"terms": map[string]any{"connection_id": connectionIDs}})
+	}
+	return filters
+}
+
+func ListResourceCollectionMembers(ctx context.Context, client opengovernance.Client, resourceCollectionID string, resourceTypes, connectionIDs []string, from, size int) ([]resourcecollection.Member, int64, error) {
+	query := map[string]any{
+		"from": from,
+		"size": size,
+		"query": map[string]any{
+			"bool": map[string]any{
+				"filter": resourceCollectionMemberFilters(resourceCollectionID, resourceTypes, connectionIDs),
+			},
+		},
+		"sort": []map[string]any{
+			{"resource_type": "asc"},
+			{"resource_id": "asc"},
+		},
+		"track_total_hits": true,
+	}
+	queryBytes, err := json.Marshal(query)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var response ResourceCollectionMembersResponse
+	err = client.Search(ctx, resourcecollection.MembersIndex, string(queryBytes), &response)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var members []resourcecollection.Member
+	for _, hit := range response.Hits.Hits {
+		members = append(members, hit.Source)
+	}
+	return members, response.Hits.Total.Value, nil
+}
+
+// CountResourceCollectionMembers returns the number of members of the resource collection in total, per resource type and per connection.
+func CountResourceCollectionMembers(ctx context.Context, client opengovernance.Client, resourceCollectionID string, size int) (int64, map[string]int, map[string]int, error) {
+	terms := func(field string) map[string]any {
+		return map[string]any{
+			"terms": map[string]any{
+				"field": field,
+				"size":  size,
+			},
+		}
+	}
+	query := map[string]any{
+		"size": 0,
+		"query": map[string]any{
+			"bool": map[string]any{
+				"filter": resourceCollectionMemberFilters(resourceCollectionID, nil, nil),
+			},
+		},
+		"track_total_hits": true,
+		"aggs": map[string]any{
+			"resource_types": terms("resource_type"),
+			"connection_ids": terms("connection_id"),
+		},
+	}
+	queryBytes, err := json.Marshal(query)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+
+	var response ResourceCollectionMembersResponse
+	err = client.Search(ctx, resourcecollection.MembersIndex, string(queryBytes), &response)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+
+	resourceTypes := make(map[string]int)
+	for _, bucket := range response.Aggregations.ResourceTypes.Buckets {
+		resourceTypes[bucket.Key] = bucket.DocCount
+	}
+	connectionIDs := make(map[string]int)
+	for _, bucket := range response.Aggregations.ConnectionIDs.Buckets {
+		connectionIDs[bucket.Key] = bucket.DocCount
+	}
+	return response.Hits.Total.Value, resourceTypes, connectionIDs, nil
+}
+
+// ListResourceCollectionMembershipChanges returns the membership changes of the given job, or of all the
+// jobs since the given time in milliseconds when jobID is zero.
+func ListResourceCollectionMembershipChanges(ctx context.Context, client opengovernance.Client, resourceCollectionID string, jobID uint, sinceMillis int64, size int) ([]resourcecollection.MembershipChange, error) {
+	filters := []any{
+		map[string]any{"term": map[string]any{"resource_collection_id": resourceCollectionID}},
+	}
+	if jobID != 0 {
+		filters = append(filters, map[string]any{"term": map[string]any{"job_id": jobID}})
+	} else {
+		filters = append(filters, map[string]any{"range": map[string]any{"changed_at": map[string]any{"gte": sinceMillis}}})
+	}
+
+	query := map[string]any{
+		"size": size,
+		"query": map[string]any{
+			"bool": map[string]any{
+				"filter": filters,
+			},
+		},
+		"sort": []map[string]any{
+			{"changed_at": "desc"},
+			{"resource_id": "asc"},
+		},
+	}
+	queryBytes, err := json.Marshal(query)
+	if err != nil {
+		return nil, err
+	}
+
+	var response ResourceCollectionMembershipChangesResponse
+	err = client.Search(ctx, resourcecollection.MembershipChangesIndex, string(queryBytes), &response)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []resourcecollection.MembershipChange
+	for _, hit := range response.Hits.Hits {
+		changes = append(changes, hit.Source)
+	}
+	return changes, nil
+}
+
+func DeleteResourceCollectionMembers(ctx context.Context, client opengovernance.Client, resourceCollectionID string) error {
+	root := map[string]any{
+		"query": map[string]any{
+			"term": map[string]any{"resource_collection_id": resourceCollectionID},
+		},
+	}
+	query, err := json.Marshal(root)
+	if err != nil {
+		return err
+	}
+
+	res, err := client.ES().DeleteByQuery([]string{resourcecollection.MembersIndex, resourcecollection.MembershipChangesIndex}, bytes.NewReader(query),
+		client.ES().DeleteByQuery.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	opengovernance.CloseSafe(res)
+	return nil
+}
@@ -0,0 +1,132 @@
+package es
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/opengovern/og-util/pkg/es"
+	"github.com/opengovern/og-util/pkg/opengovernance-es-sdk"
+	"github.com/opengovern/opengovernance/pkg/describe"
+	"github.com/opengovern/opengovernance/pkg/inventory/graph"
+	"github.com/opengovern/opengovernance/pkg/types"
+)
+
+type ResourceEdgeHit struct {
+	ID     string             `json:"_id"`
+	Score  float64            `json:"_score"`
+	Index  string             `json:"_index"`
+	Source types.ResourceEdge `json:"_source"`
+	Sort   []any              `json:"sort"`
+}
+
+type ResourceEdgesResponse struct {
+	Hits struct {
+		Total opengovernance.SearchTotal `json:"total"`
+		Hits  []ResourceEdgeHit          `json:"hits"`
+	} `json:"hits"`
+}
+
+// FetchResourceEdges returns the edges starting (outgoing), ending (incoming) or touching (both) any of the given resources.
+func FetchResourceEdges(ctx context.Context, client opengovernance.Client, resourceIDs []string, direction graph.Direction, relations []string, connectionIDs []string) ([]types.ResourceEdge, error) {
+	if len(resourceIDs) == 0 {
+		return nil, nil
+	}
+
+	var should []any
+	if direction != graph.DirectionIncoming {
+		should = append(should, map[string]any{"terms": map[string]any{"from_resource_id": resourceIDs}})
+	}
+	if direction != graph.DirectionOutgoing {
+		should = append(should, map[string]any{"terms": map[string]any{"to_resource_id": resourceIDs}})
+	}
+	filters := []any{
+		map[string]any{"bool": map[string]any{"should": should, "minimum_should_match": 1}},
+	}
+	if len(relations) > 0 {
+		filters = append(filters, map[string]any{"terms": map[string]any{"relation": relations}})
+	}
+	if len(connectionIDs) > 0 {
+		filters = append(filters, map[string]any{"terms": map[string]any{"source_id": connectionIDs}})
+	}
+
+	var edges []types.ResourceEdge
+	var searchAfter []any
+	for {
+		root := map[string]any{
+			"size": EsFetchPageSize,
+			"query": map[string]any{
+				"bool": map[string]any{
+					"filter": filters,
+				},
+			},
+			"sort": []map[string]any{
+				{"_id": "asc"},
+			},
+		}
+		if searchAfter != nil {
+			root["search_after"] = searchAfter
+		}
+		queryBytes, err := json.Marshal(root)
+		if err != nil {
+			return nil, err
+		}
+
+		var response ResourceEdgesResponse
+		err = client.Search(ctx, types.ResourceEdgesIndex, string(queryBytes), &response)
+		if err != nil {
+			return nil, err
+		}
+		for _, hit := range response.Hits.Hits {
+			edges = append(edges, hit.Source)
+			searchAfter = hit.Sort
+		}
+		if len(response.Hits.Hits) < EsFetchPageSize || len(edges) >= graph.MaxNodes*10 {
+			break
+		}
+	}
+
+	return edges, nil
+}
+
+type LookupResourcesResponse struct {
+	Hits struct {
+		Hits []struct {
+			Source es.LookupResource `json:"_source"`
+		} `json:"hits"`
+	} `json:"hits"`
+}
+
+// FetchLookupResourcesByIDs returns the lookup documents of the given resources keyed by resource id.
+func FetchLookupResourcesByIDs(ctx context.Context, client opengovernance.Client, resourceIDs []string) (map[string]es.LookupResource, error) {
+	res := make(map[string]es.LookupResource)
+	for start := 0; start < len(resourceIDs); start += EsFetchPageSize {
+		end := start + EsFetchPageSize
+		if end > len(resourceIDs) {
+			end = len(resourceIDs)
+		}
+
+		root := map[string]any{
+			"size": EsFetchPageSize,
+			"query": map[string]any{
+				"terms": map[string]any{
+					"resource_id": resourceIDs[start:end],
+				},
+			},
+		}
+		queryBytes, err := json.Marshal(root)
+		if err != nil {
+			return nil, err
+		}
+
+		var response LookupResourcesResponse
+		err = client.Search(ctx, describe.InventorySummaryIndex, string(queryBytes), &response)
+		if err != nil {
+			return nil, err
+		}
+		for _, hit := range response.Hits.Hits {
+			res[hit.Source.ResourceID] = hit.Source
+		}
+	}
+
+	return res, nil
+}
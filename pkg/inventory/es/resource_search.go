@@ -0,0 +1,233 @@
+package es
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/opengovern/og-util/pkg/es"
+	"github.com/opengovern/og-util/pkg/opengovernance-es-sdk"
+	"github.com/opengovern/og-util/pkg/source"
+	"github.com/opengovern/opengovernance/pkg/analytics/es/resourcecollection"
+	"github.com/opengovern/opengovernance/pkg/describe"
+)
+
+type ResourceSearchFilters struct {
+	Connectors    []source.Type
+	ConnectionIDs []string
+	ResourceIDs   []string
+	ResourceTypes []string
+	Regions       []string
+	Tags          map[string][]string
+}
+
+type ResourceSearchHit struct {
+	ID     string            `json:"_id"`
+	Score  float64           `json:"_score"`
+	Index  string            `json:"_index"`
+	Source es.LookupResource `json:"_source"`
+	Sort   []any             `json:"sort"`
+}
+
+type ResourceSearchBucket struct {
+	Key      string `json:"key"`
+	DocCount int    `json:"doc_count"`
+}
+
+type ResourceSearchBuckets struct {
+	Buckets []ResourceSearchBucket `json:"buckets"`
+}
+
+type ResourceSearchTagKeyBucket struct {
+	ResourceSearchBucket
+	Values ResourceSearchBuckets `json:"values"`
+}
+
+type ResourceSearchResponse struct {
+	Hits struct {
+		Total opengovernance.SearchTotal `json:"total"`
+		Hits  []ResourceSearchHit        `json:"hits"`
+	} `json:"hits"`
+	Aggregations struct {
+		Connectors    ResourceSearchBuckets `json:"connectors"`
+		ConnectionIDs ResourceSearchBuckets `json:"connection_ids"`
+		ResourceTypes ResourceSearchBuckets `json:"resource_types"`
+		Regions       ResourceSearchBuckets `json:"regions"`
+		Tags          struct {
+			Keys struct {
+				Buckets []ResourceSearchTagKeyBucket `json:"buckets"`
+			} `json:"keys"`
+		} `json:"tags"`
+	} `json:"aggregations"`
+}
+
+var wildcardEscaper = strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`)
+
+// resourceSearchTextQuery ranks exact ID and name matches above prefix and partial matches
+// on the name, ID and tags of the resource.
+func resourceSearchTextQuery(text string) map[string]any {
+	contains := "*" + wildcardEscaper.Replace(text) + "*"
+	prefix := wildcardEscaper.Replace(text) + "*"
+	wildcard := func(field, value string, boost float64) map[string]any {
+		return map[string]any{
+			"wildcard": map[string]any{
+				field: map[string]any{
+					"value":            value,
+					"case_insensitive": true,
+					"boost":            boost,
+				},
+			},
+		}
+	}
+	term := func(field, value string, boost float64) map[string]any {
+		return map[string]any{
+			"term": map[string]any{
+				field: map[string]any{
+					"value":            value,
+					"case_insensitive": true,
+					"boost":            boost,
+				},
+			},
+		}
+	}
+
+	return map[string]any{
+		"bool": map[string]any{
+			"should": []any{
+				term("resource_id", text, 10),
+				term("name", text, 8),
+				wildcard("name", prefix, 4),
+				wildcard("name", contains, 2),
+				wildcard("resource_id", contains, 1),
+				map[string]any{
+					"nested": map[string]any{
+						"path": "canonical_tags",
+						"query": map[string]any{
+							"bool": map[string]any{
+								"should": []any{
+									wildcard("canonical_tags.value", contains, 1),
+									wildcard("canonical_tags.key", contains, 0.5),
+								},
+							},
+						},
+						"score_mode": "max",
+					},
+				},
+			},
+			"minimum_should_match": 1,
+		},
+	}
+}
+
+func resourceSearchFilters(filters ResourceSearchFilters) []any {
+	var res []any
+	if len(filters.Connectors) > 0 {
+		connectors := make([]string, 0, len(filters.Connectors))
+		for _, c := range filters.Connectors {
+			connectors = append(connectors, c.String())
+		}
+		res = append(res, map[string]any{"terms": map[string]any{"source_type": connectors}})
+	}
+	if len(filters.ConnectionIDs) > 0 {
+		res = append(res, map[string]any{"terms": map[string]any{"source_id": filters.ConnectionIDs}})
+	}
+	if len(filters.ResourceIDs) > 0 {
+		res = append(res, resourcecollection.MembersFilter("resource_id", filters.ResourceIDs))
+	}
+	if len(filters.ResourceTypes) > 0 {
+		resourceTypes := make([]string, 0, len(filters.ResourceTypes))
+		for _, rt := range filters.ResourceTypes {
+			resourceTypes = append(resourceTypes, strings.ToLower(rt))
+		}
+		res = append(res, map[string]any{"terms": map[string]any{"resource_type": resourceTypes}})
+	}
+	if len(filters.Regions) > 0 {
+		res = append(res, map[string]any{"terms": map[string]any{"location": filters.Regions}})
+	}
+	for key, values := range filters.Tags {
+		tagFilters := []any{
+			map[string]any{"term": map[string]any{"canonical_tags.key": strings.ToLower(key)}},
+		}
+		if len(values) > 0 {
+			lowerValues := make([]string, 0, len(values))
+			for _, v := range values {
+				lowerValues = append(lowerValues, strings.ToLower(v))
+			}
+			tagFilters = append(tagFilters, map[string]any{"terms": map[string]any{"canonical_tags.value": lowerValues}})
+		}
+		res = append(res, map[string]any{
+			"nested": map[string]any{
+				"path": "canonical_tags",
+				"query": map[string]any{
+					"bool": map[string]any{"filter": tagFilters},
+				},
+			},
+		})
+	}
+	return res
+}
+
+// resourceSearchQuery builds the search request, results are sorted by score and paged with search_after.
+func resourceSearchQuery(text string, filters ResourceSearchFilters, searchAfter []any, size, facetSize int) map[string]any {
+	boolQuery := map[string]any{
+		"filter": resourceSearchFilters(filters),
+	}
+	if text = strings.TrimSpace(text); text != "" {
+		boolQuery["must"] = []any{resourceSearchTextQuery(text)}
+	}
+
+	terms := func(field string) map[string]any {
+		return map[string]any{
+			"terms": map[string]any{
+				"field": field,
+				"size":  facetSize,
+			},
+		}
+	}
+	tagKeys := terms("canonical_tags.key")
+	tagKeys["aggs"] = map[string]any{
+		"values": terms("canonical_tags.value"),
+	}
+	root := map[string]any{
+		"size": size,
+		"query": map[string]any{
+			"bool": boolQuery,
+		},
+		"sort": []map[string]any{
+			{"_score": "desc"},
+			{"_id": "asc"},
+		},
+		"track_total_hits": true,
+		"aggs": map[string]any{
+			"connectors":     terms("source_type"),
+			"connection_ids": terms("source_id"),
+			"resource_types": terms("resource_type"),
+			"regions":        terms("location"),
+			"tags": map[string]any{
+				"nested": map[string]any{"path": "canonical_tags"},
+				"aggs": map[string]any{
+					"keys": tagKeys,
+				},
+			},
+		},
+	}
+	if len(searchAfter) > 0 {
+		root["search_after"] = searchAfter
+	}
+	return root
+}
+
+// SearchResources runs a ranked search over the lookup index.
+func SearchResources(ctx context.Context, client opengovernance.Client, text string, filters ResourceSearchFilters, searchAfter []any, size, facetSize int) (*ResourceSearchResponse, error) {
+	queryBytes, err := json.Marshal(resourceSearchQuery(text, filters, searchAfter, size, facetSize))
+	if err != nil {
+		return nil, err
+	}
+
+	var response ResourceSearchResponse
+	err = client.Search(ctx, describe.InventorySummaryIndex, string(queryBytes), &response)
+	if err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
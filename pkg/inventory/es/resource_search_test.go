@@ -0,0 +1,154 @@
+package es
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/opengovern/og-util/pkg/source"
+	"github.com/opengovern/opengovernance/pkg/analytics/es/resourcecollection"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResourceSearchTextQuery(t *testing.T) {
+	tests := []struct {
+		text         string
+		wantPrefix   string
+		wantContains string
+	}{
+		{text: "web-1", wantPrefix: "web-1*", wantContains: "*web-1*"},
+		{text: "a*b?c", wantPrefix: `a\*b\?c*`, wantContains: `*a\*b\?c*`},
+		{text: `back\slash`, wantPrefix: `back\\slash*`, wantContains: `*back\\slash*`},
+	}
+	for _, tt := range tests {
+		query := resourceSearchTextQuery(tt.text)
+		boolQuery := query["bool"].(map[string]any)
+		assert.Equal(t, 1, boolQuery["minimum_should_match"], tt.text)
+
+		should := boolQuery["should"].([]any)
+		assert.Len(t, should, 6, tt.text)
+		clause := func(i int, kind, field string) map[string]any {
+			return should[i].(map[string]any)[kind].(map[string]any)[field].(map[string]any)
+		}
+		// exact matches use the text as is and rank first
+		assert.Equal(t, tt.text, clause(0, "term", "resource_id")["value"], tt.text)
+		assert.Equal(t, 10.0, clause(0, "term", "resource_id")["boost"], tt.text)
+		assert.Equal(t, tt.text, clause(1, "term", "name")["value"], tt.text)
+		assert.Equal(t, tt.wantPrefix, clause(2, "wildcard", "name")["value"], tt.text)
+		assert.Equal(t, tt.wantContains, clause(3, "wildcard", "name")["value"], tt.text)
+		assert.Equal(t, tt.wantContains, clause(4, "wildcard", "resource_id")["value"], tt.text)
+		assert.Equal(t, true, clause(4, "wildcard", "resource_id")["case_insensitive"], tt.text)
+
+		nested := should[5].(map[string]any)["nested"].(map[string]any)
+		assert.Equal(t, "canonical_tags", nested["path"], tt.text)
+	}
+}
+
+func TestResourceSearchFilters(t *testing.T) {
+	tests := []struct {
+		name    string
+		filters ResourceSearchFilters
+		want    []any
+	}{
+		{name: "empty", filters: ResourceSearchFilters{}, want: nil},
+		{
+			name:    "connectors",
+			filters: ResourceSearchFilters{Connectors: []source.Type{source.CloudAWS, source.CloudAzure}},
+			want:    []any{map[string]any{"terms": map[string]any{"source_type": []string{"AWS", "Azure"}}}},
+		},
+		{
+			name:    "connections and resources",
+			filters: ResourceSearchFilters{ConnectionIDs: []string{"c1"}, ResourceIDs: []string{"r1", "r2"}},
+			want: []any{
+				map[string]any{"terms": map[string]any{"source_id": []string{"c1"}}},
+				resourcecollection.MembersFilter("resource_id", []string{"r1", "r2"}),
+			},
+		},
+		{
+			name:    "resource types are lowercased",
+			filters: ResourceSearchFilters{ResourceTypes: []string{"AWS::EC2::Instance"}, Regions: []string{"us-east-1"}},
+			want: []any{
+				map[string]any{"terms": map[string]any{"resource_type": []string{"aws::ec2::instance"}}},
+				map[string]any{"terms": map[string]any{"location": []string{"us-east-1"}}},
+			},
+		},
+		{
+			name:    "tag with values",
+			filters: ResourceSearchFilters{Tags: map[string][]string{"Env": {"Prod"}}},
+			want: []any{map[string]any{"nested": map[string]any{
+				"path": "canonical_tags",
+				"query": map[string]any{"bool": map[string]any{"filter": []any{
+					map[string]any{"term": map[string]any{"canonical_tags.key": "env"}},
+					map[string]any{"terms": map[string]any{"canonical_tags.value": []string{"prod"}}},
+				}}},
+			}}},
+		},
+		{
+			name:    "tag key only",
+			filters: ResourceSearchFilters{Tags: map[string][]string{"owner": nil}},
+			want: []any{map[string]any{"nested": map[string]any{
+				"path": "canonical_tags",
+				"query": map[string]any{"bool": map[string]any{"filter": []any{
+					map[string]any{"term": map[string]any{"canonical_tags.key": "owner"}},
+				}}},
+			}}},
+		},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, resourceSearchFilters(tt.filters), tt.name)
+	}
+}
+
+func TestResourceSearchQuery(t *testing.T) {
+	query := resourceSearchQuery(" web ", ResourceSearchFilters{Regions: []string{"us-east-1"}}, nil, 50, 10)
+	assert.Equal(t, 50, query["size"])
+	assert.Equal(t, []map[string]any{{"_score": "desc"}, {"_id": "asc"}}, query["sort"])
+	assert.NotContains(t, query, "search_after", "the first page has no search_after")
+
+	boolQuery := query["query"].(map[string]any)["bool"].(map[string]any)
+	assert.Equal(t, resourceSearchFilters(ResourceSearchFilters{Regions: []string{"us-east-1"}}), boolQuery["filter"])
+	assert.Equal(t, []any{resourceSearchTextQuery("web")}, boolQuery["must"], "the text is trimmed")
+
+	terms := func(field string) map[string]any {
+		return map[string]any{"terms": map[string]any{"field": field, "size": 10}}
+	}
+	aggs := query["aggs"].(map[string]any)
+	assert.Equal(t, terms("source_type"), aggs["connectors"])
+	assert.Equal(t, terms("source_id"), aggs["connection_ids"])
+	assert.Equal(t, terms("resource_type"), aggs["resource_types"])
+	assert.Equal(t, terms("location"), aggs["regions"])
+	assert.Equal(t, map[string]any{
+		"nested": map[string]any{"path": "canonical_tags"},
+		"aggs": map[string]any{
+			"keys": map[string]any{
+				"terms": map[string]any{"field": "canonical_tags.key", "size": 10},
+				"aggs":  map[string]any{"values": terms("canonical_tags.value")},
+			},
+		},
+	}, aggs["tags"])
+
+	blank := resourceSearchQuery("  ", ResourceSearchFilters{}, nil, 20, 20)
+	assert.NotContains(t, blank["query"].(map[string]any)["bool"], "must", "a blank text matches everything")
+
+	searchAfter := []any{4.5, "doc-20"}
+	next := resourceSearchQuery("web", ResourceSearchFilters{}, searchAfter, 20, 20)
+	assert.Equal(t, searchAfter, next["search_after"])
+}
+
+func TestResourceSearchResponseAggregations(t *testing.T) {
+	body := `{
+		"hits": {"total": {"value": 2, "relation": "eq"}, "hits": []},
+		"aggregations": {
+			"connectors": {"buckets": [{"key": "AWS", "doc_count": 2}]},
+			"tags": {"doc_count": 3, "keys": {"buckets": [
+				{"key": "env", "doc_count": 2, "values": {"buckets": [{"key": "prod", "doc_count": 1}, {"key": "dev", "doc_count": 1}]}}
+			]}}
+		}
+	}`
+	var response ResourceSearchResponse
+	assert.NoError(t, json.Unmarshal([]byte(body), &response))
+	assert.Equal(t, []ResourceSearchBucket{{Key: "AWS", DocCount: 2}}, response.Aggregations.Connectors.Buckets)
+	assert.Equal(t, []ResourceSearchTagKeyBucket{{
+		ResourceSearchBucket: ResourceSearchBucket{Key: "env", DocCount: 2},
+		Values:               ResourceSearchBuckets{Buckets: []ResourceSearchBucket{{Key: "prod", DocCount: 1}, {Key: "dev", DocCount: 1}}},
+	}}, response.Aggregations.Tags.Keys.Buckets)
+}
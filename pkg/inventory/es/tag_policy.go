@@ -0,0 +1,237 @@
+package es
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+
+	"github.com/opengovern/og-util/pkg/opengovernance-es-sdk"
+	"github.com/opengovern/opengovernance/pkg/analytics/es/resourcecollection"
+	"github.com/opengovern/opengovernance/pkg/analytics/es/tagpolicy"
+)
+
+// tagPolicyCardinalityPrecision is the highest precision threshold, the resource counts are exact below it.
+const tagPolicyCardinalityPrecision = 40000
+
+type TagPolicyResultHit struct {
+	ID     string                    `json:"_id"`
+	Score  float64                   `json:"_score"`
+	Index  string                    `json:"_index"`
+	Source tagpolicy.TagPolicyResult `json:"_source"`
+	Sort   []any                     `json:"sort"`
+}
+
+type TagPolicyResultsResponse struct {
+	Hits struct {
+		Total opengovernance.SearchTotal `json:"total"`
+		Hits  []TagPolicyResultHit       `json:"hits"`
+	} `json:"hits"`
+}
+
+type TagPolicyCoverage struct {
+	Total     int
+	Compliant int
+}
+
+type TagPolicyCoverageResponse struct {
+	Aggregations struct {
+		Group struct {
+			Buckets []struct {
+				Key           string `json:"key"`
+				ResourceCount struct {
+					Value int `json:"value"`
+				} `json:"resource_count"`
+				NonCompliant struct {
+					ResourceCount struct {
+						Value int `json:"value"`
+					} `json:"resource_count"`
+				} `json:"non_compliant"`
+			} `json:"buckets"`
+		} `json:"group"`
+	} `json:"aggregations"`
+}
+
+type TagPolicySuggestionsResponse struct {
+	Aggregations struct {
+		Suggestions struct {
+			Buckets []struct {
+				Key      string `json:"key"`
+				DocCount int    `json:"doc_count"`
+			} `json:"buckets"`
+		} `json:"suggestions"`
+	} `json:"aggregations"`
+}
+
+func tagPolicyResultFilters(policyIDs, connectionIDs, resourceIDs, resourceTypes, owners []string) []any {
+	var filters []any
+	if len(policyIDs) > 0 {
+		filters = append(filters, map[string]any{"terms": map[string]any{"policy_id": policyIDs}})
+	}
+	if len(connectionIDs) > 0 {
+		filters = append(filters, map[string]any{"terms": map[string]any{"connection_id": connectionIDs}})
+	}
+	if len(resourceIDs) > 0 {
+		filters = append(filters, resourcecollection.MembersFilter("resource_id", resourceIDs))
+	}
+	if len(resourceTypes) > 0 {
+		filters = append(filters, map[string]any{"terms": map[string]any{"resource_type": resourceTypes}})
+	}
+	if len(owners) > 0 {
+		filters = append(filters, map[string]any{"terms": map[string]any{"owner": owners}})
+	}
+	return filters
+}
+
+// GetTagPolicyCoverage returns the number of evaluated and compliant resources grouped by the given field. A resource
+// with a result for several policies is counted once, and is compliant only if it complies with all of them.
+func GetTagPolicyCoverage(ctx context.Context, client opengovernance.Client, policyIDs, connectionIDs, resourceIDs []string, groupByField string, size int) (map[string]TagPolicyCoverage, error) {
+	query := map[string]any{
+		"size": 0,
+		"query": map[string]any{
+			"bool": map[string]any{
+				"filter": tagPolicyResultFilters(policyIDs, connectionIDs, resourceIDs, nil, nil),
+			},
+		},
+		"aggs": map[string]any{
+			"group": map[string]any{
+				"terms": map[string]any{
+					"field":   groupByField,
+					"size":    size,
+					"missing": "",
+				},
+				"aggs": map[string]any{
+					"resource_count": map[string]any{
+						"cardinality": map[string]any{
+							"field":               "resource_id",
+							"precision_threshold": tagPolicyCardinalityPrecision,
+						},
+					},
+					"non_compliant": map[string]any{
+						"filter": map[string]any{
+							"term": map[string]any{"compliant": false},
+						},
+						"aggs": map[string]any{
+							"resource_count": map[string]any{
+								"cardinality": map[string]any{
+									"field":               "resource_id",
+									"precision_threshold": tagPolicyCardinalityPrecision,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	queryBytes, err := json.Marshal(query)
+	if err != nil {
+		return nil, err
+	}
+
+	var response TagPolicyCoverageResponse
+	err = client.Search(ctx, tagpolicy.TagPolicyResultsIndex, string(queryBytes), &response)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(map[string]TagPolicyCoverage)
+	for _, bucket := range response.Aggregations.Group.Buckets {
+		res[bucket.Key] = TagPolicyCoverage{
+			Total:     bucket.ResourceCount.Value,
+			Compliant: bucket.ResourceCount.Value - bucket.NonCompliant.ResourceCount.Value,
+		}
+	}
+	return res, nil
+}
+
+func ListNonCompliantTagPolicyResults(ctx context.Context, client opengovernance.Client, policyIDs, connectionIDs, resourceIDs, resourceTypes, owners []string, from, size int) ([]tagpolicy.TagPolicyResult, int64, error) {
+	filters := tagPolicyResultFilters(policyIDs, connectionIDs, resourceIDs, resourceTypes, owners)
+	filters = append(filters, map[string]any{"term": map[string]any{"compliant": false}})
+
+	query := map[string]any{
+		"from": from,
+		"size": size,
+		"query": map[string]any{
+			"bool": map[string]any{
+				"filter": filters,
+			},
+		},
+		"sort": []map[string]any{
+			{"resource_id": "asc"},
+			{"policy_id": "asc"},
+		},
+		"track_total_hits": true,
+	}
+	queryBytes, err := json.Marshal(query)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var response TagPolicyResultsResponse
+	err = client.Search(ctx, tagpolicy.TagPolicyResultsIndex, string(queryBytes), &response)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var results []tagpolicy.TagPolicyResult
+	for _, hit := range response.Hits.Hits {
+		results = append(results, hit.Source)
+	}
+	return results, response.Hits.Total.Value, nil
+}
+
+// GetTagPolicySuggestions returns the normalization suggestions and the number of resources each one applies to.
+func GetTagPolicySuggestions(ctx context.Context, client opengovernance.Client, policyIDs, connectionIDs, resourceIDs []string, size int) (map[string]int, error) {
+	query := map[string]any{
+		"size": 0,
+		"query": map[string]any{
+			"bool": map[string]any{
+				"filter": tagPolicyResultFilters(policyIDs, connectionIDs, resourceIDs, nil, nil),
+			},
+		},
+		"aggs": map[string]any{
+			"suggestions": map[string]any{
+				"terms": map[string]any{
+					"field": "suggestions",
+					"size":  size,
+				},
+			},
+		},
+	}
+	queryBytes, err := json.Marshal(query)
+	if err != nil {
+		return nil, err
+	}
+
+	var response TagPolicySuggestionsResponse
+	err = client.Search(ctx, tagpolicy.TagPolicyResultsIndex, string(queryBytes), &response)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(map[string]int)
+	for _, bucket := range response.Aggregations.Suggestions.Buckets {
+		res[bucket.Key] = bucket.DocCount
+	}
+	return res, nil
+}
+
+func DeleteTagPolicyResults(ctx context.Context, client opengovernance.Client, policyID string) error {
+	root := map[string]any{
+		"query": map[string]any{
+			"term": map[string]any{"policy_id": policyID},
+		},
+	}
+	query, err := json.Marshal(root)
+	if err != nil {
+		return err
+	}
+
+	res, err := client.ES().DeleteByQuery([]string{tagpolicy.TagPolicyResultsIndex}, bytes.NewReader(query),
+		client.ES().DeleteByQuery.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	opengovernance.CloseSafe(res)
+	return nil
+}
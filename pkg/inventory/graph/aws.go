@@ -0,0 +1,73 @@
+package graph
+
+import "github.com/opengovern/opengovernance/pkg/types"
+
+const (
+	awsVpcFormat           = "arn:{partition}:ec2:{region}:{account}:vpc/{value}"
+	awsSubnetFormat        = "arn:{partition}:ec2:{region}:{account}:subnet/{value}"
+	awsSecurityGroupFormat = "arn:{partition}:ec2:{region}:{account}:security-group/{value}"
+	awsInstanceFormat      = "arn:{partition}:ec2:{region}:{account}:instance/{value}"
+	awsVolumeFormat        = "arn:{partition}:ec2:{region}:{account}:volume/{value}"
+	awsNetworkIfaceFormat  = "arn:{partition}:ec2:{region}:{account}:network-interface/{value}"
+)
+
+func init() {
+	registerRules("AWS::EC2::Instance",
+		rule{Relation: types.ResourceRelationInNetwork, Path: "Instance.VpcId", TargetType: "AWS::EC2::VPC", Format: awsVpcFormat},
+		rule{Relation: types.ResourceRelationInNetwork, Path: "Instance.SubnetId", TargetType: "AWS::EC2::Subnet", Format: awsSubnetFormat},
+		rule{Relation: types.ResourceRelationAttachedTo, Path: "Instance.SecurityGroups[].GroupId", TargetType: "AWS::EC2::SecurityGroup", Format: awsSecurityGroupFormat},
+		// volume attachments are only read from the instance, Volume.Attachments would store the same edge again
+		rule{Relation: types.ResourceRelationAttachedTo, Path: "Instance.BlockDeviceMappings[].Ebs.VolumeId", TargetType: "AWS::EC2::Volume", Format: awsVolumeFormat},
+		rule{Relation: types.ResourceRelationAttachedTo, Path: "Instance.NetworkInterfaces[].NetworkInterfaceId", TargetType: "AWS::EC2::NetworkInterface", Format: awsNetworkIfaceFormat},
+		rule{Relation: types.ResourceRelationUsesRole, Path: "Instance.IamInstanceProfile.Arn", TargetType: "AWS::IAM::InstanceProfile"},
+	)
+	registerRules("AWS::EC2::Subnet",
+		rule{Relation: types.ResourceRelationContains, Path: "Subnet.VpcId", TargetType: "AWS::EC2::VPC", Format: awsVpcFormat, Reverse: true},
+	)
+	registerRules("AWS::EC2::SecurityGroup",
+		rule{Relation: types.ResourceRelationInNetwork, Path: "SecurityGroup.VpcId", TargetType: "AWS::EC2::VPC", Format: awsVpcFormat},
+	)
+	registerRules("AWS::EC2::NetworkInterface",
+		rule{Relation: types.ResourceRelationInNetwork, Path: "NetworkInterface.SubnetId", TargetType: "AWS::EC2::Subnet", Format: awsSubnetFormat},
+		rule{Relation: types.ResourceRelationAttachedTo, Path: "NetworkInterface.Groups[].GroupId", TargetType: "AWS::EC2::SecurityGroup", Format: awsSecurityGroupFormat},
+	)
+	registerRules("AWS::ElasticLoadBalancingV2::LoadBalancer",
+		rule{Relation: types.ResourceRelationInNetwork, Path: "LoadBalancer.VpcId", TargetType: "AWS::EC2::VPC", Format: awsVpcFormat},
+		rule{Relation: types.ResourceRelationInNetwork, Path: "LoadBalancer.AvailabilityZones[].SubnetId", TargetType: "AWS::EC2::Subnet", Format: awsSubnetFormat},
+		rule{Relation: types.ResourceRelationAttachedTo, Path: "LoadBalancer.SecurityGroups[]", TargetType: "AWS::EC2::SecurityGroup", Format: awsSecurityGroupFormat},
+	)
+	registerRules("AWS::ElasticLoadBalancingV2::TargetGroup",
+		rule{Relation: types.ResourceRelationRoutesTo, Path: "TargetGroup.LoadBalancerArns[]", TargetType: "AWS::ElasticLoadBalancingV2::LoadBalancer", Reverse: true},
+		rule{Relation: types.ResourceRelationRoutesTo, Path: "Health[].Target.Id", TargetType: "AWS::EC2::Instance", Format: awsInstanceFormat, ValuePrefix: "i-"},
+		rule{Relation: types.ResourceRelationRoutesTo, Path: "Health[].Target.Id", TargetType: "AWS::Lambda::Function", ValuePrefix: "arn:"},
+	)
+	registerRules("AWS::ElasticLoadBalancing::LoadBalancer",
+		rule{Relation: types.ResourceRelationInNetwork, Path: "LoadBalancer.VPCId", TargetType: "AWS::EC2::VPC", Format: awsVpcFormat},
+		rule{Relation: types.ResourceRelationAttachedTo, Path: "LoadBalancer.SecurityGroups[]", TargetType: "AWS::EC2::SecurityGroup", Format: awsSecurityGroupFormat},
+		rule{Relation: types.ResourceRelationRoutesTo, Path: "LoadBalancer.Instances[].InstanceId", TargetType: "AWS::EC2::Instance", Format: awsInstanceFormat},
+	)
+	registerRules("AWS::IAM::InstanceProfile",
+		rule{Relation: types.ResourceRelationUsesRole, Path: "InstanceProfile.Roles[].Arn", TargetType: "AWS::IAM::Role"},
+	)
+	registerRules("AWS::Lambda::Function",
+		rule{Relation: types.ResourceRelationUsesRole, Path: "Function.Configuration.Role", TargetType: "AWS::IAM::Role"},
+		rule{Relation: types.ResourceRelationInNetwork, Path: "Function.Configuration.VpcConfig.VpcId", TargetType: "AWS::EC2::VPC", Format: awsVpcFormat},
+		rule{Relation: types.ResourceRelationInNetwork, Path: "Function.Configuration.VpcConfig.SubnetIds[]", TargetType: "AWS::EC2::Subnet", Format: awsSubnetFormat},
+		rule{Relation: types.ResourceRelationAttachedTo, Path: "Function.Configuration.VpcConfig.SecurityGroupIds[]", TargetType: "AWS::EC2::SecurityGroup", Format: awsSecurityGroupFormat},
+	)
+	registerRules("AWS::RDS::DBInstance",
+		rule{Relation: types.ResourceRelationInNetwork, Path: "DBInstance.DBSubnetGroup.VpcId", TargetType: "AWS::EC2::VPC", Format: awsVpcFormat},
+		rule{Relation: types.ResourceRelationInNetwork, Path: "DBInstance.DBSubnetGroup.Subnets[].SubnetIdentifier", TargetType: "AWS::EC2::Subnet", Format: awsSubnetFormat},
+		rule{Relation: types.ResourceRelationAttachedTo, Path: "DBInstance.VpcSecurityGroups[].VpcSecurityGroupId", TargetType: "AWS::EC2::SecurityGroup", Format: awsSecurityGroupFormat},
+		rule{Relation: types.ResourceRelationUsesRole, Path: "DBInstance.AssociatedRoles[].RoleArn", TargetType: "AWS::IAM::Role"},
+	)
+	registerRules("AWS::ECS::TaskDefinition",
+		rule{Relation: types.ResourceRelationUsesRole, Path: "TaskDefinition.TaskRoleArn", TargetType: "AWS::IAM::Role"},
+		rule{Relation: types.ResourceRelationUsesRole, Path: "TaskDefinition.ExecutionRoleArn", TargetType: "AWS::IAM::Role"},
+	)
+	registerRules("AWS::EKS::Cluster",
+		rule{Relation: types.ResourceRelationUsesRole, Path: "Cluster.RoleArn", TargetType: "AWS::IAM::Role"},
+		rule{Relation: types.ResourceRelationInNetwork, Path: "Cluster.ResourcesVpcConfig.VpcId", TargetType: "AWS::EC2::VPC", Format: awsVpcFormat},
+		rule{Relation: types.ResourceRelationInNetwork, Path: "Cluster.ResourcesVpcConfig.SubnetIds[]", TargetType: "AWS::EC2::Subnet", Format: awsSubnetFormat},
+	)
+}
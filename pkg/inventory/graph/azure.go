@@ -0,0 +1,44 @@
+package graph
+
+import "github.com/opengovern/opengovernance/pkg/types"
+
+func init() {
+	registerRules("Microsoft.Compute/virtualMachines",
+		rule{Relation: types.ResourceRelationAttachedTo, Path: "VirtualMachine.properties.networkProfile.networkInterfaces[].id", TargetType: "Microsoft.Network/networkInterfaces"},
+		rule{Relation: types.ResourceRelationAttachedTo, Path: "VirtualMachine.properties.storageProfile.osDisk.managedDisk.id", TargetType: "Microsoft.Compute/disks"},
+		rule{Relation: types.ResourceRelationAttachedTo, Path: "VirtualMachine.properties.storageProfile.dataDisks[].managedDisk.id", TargetType: "Microsoft.Compute/disks"},
+		rule{Relation: types.ResourceRelationUsesRole, Path: "VirtualMachine.identity.userAssignedIdentities{}", TargetType: "Microsoft.ManagedIdentity/userAssignedIdentities"},
+	)
+	registerRules("Microsoft.Network/networkInterfaces",
+		rule{Relation: types.ResourceRelationInNetwork, Path: "Interface.properties.ipConfigurations[].properties.subnet.id", TargetType: "Microsoft.Network/virtualNetworks/subnets"},
+		rule{Relation: types.ResourceRelationAttachedTo, Path: "Interface.properties.ipConfigurations[].properties.publicIPAddress.id", TargetType: "Microsoft.Network/publicIPAddresses"},
+		rule{Relation: types.ResourceRelationAttachedTo, Path: "Interface.properties.networkSecurityGroup.id", TargetType: "Microsoft.Network/networkSecurityGroups"},
+		rule{Relation: types.ResourceRelationRoutesTo, Path: "Interface.properties.ipConfigurations[].properties.loadBalancerBackendAddressPools[].id", TargetType: "Microsoft.Network/loadBalancers", Parent: true, Reverse: true},
+		rule{Relation: types.ResourceRelationRoutesTo, Path: "Interface.properties.ipConfigurations[].properties.applicationGatewayBackendAddressPools[].id", TargetType: "Microsoft.Network/applicationGateways", Parent: true, Reverse: true},
+	)
+	registerRules("Microsoft.Network/virtualNetworks",
+		rule{Relation: types.ResourceRelationContains, Path: "VirtualNetwork.properties.subnets[].id", TargetType: "Microsoft.Network/virtualNetworks/subnets"},
+	)
+	registerRules("Microsoft.Network/loadBalancers",
+		rule{Relation: types.ResourceRelationAttachedTo, Path: "LoadBalancer.properties.frontendIPConfigurations[].properties.publicIPAddress.id", TargetType: "Microsoft.Network/publicIPAddresses"},
+		rule{Relation: types.ResourceRelationInNetwork, Path: "LoadBalancer.properties.frontendIPConfigurations[].properties.subnet.id", TargetType: "Microsoft.Network/virtualNetworks/subnets"},
+	)
+	registerRules("Microsoft.Network/applicationGateways",
+		rule{Relation: types.ResourceRelationAttachedTo, Path: "ApplicationGateway.properties.frontendIPConfigurations[].properties.publicIPAddress.id", TargetType: "Microsoft.Network/publicIPAddresses"},
+		rule{Relation: types.ResourceRelationInNetwork, Path: "ApplicationGateway.properties.gatewayIPConfigurations[].properties.subnet.id", TargetType: "Microsoft.Network/virtualNetworks/subnets"},
+	)
+	registerRules("Microsoft.Network/networkSecurityGroups",
+		rule{Relation: types.ResourceRelationAttachedTo, Path: "SecurityGroup.properties.subnets[].id", TargetType: "Microsoft.Network/virtualNetworks/subnets", Reverse: true},
+	)
+	registerRules("Microsoft.Web/sites",
+		rule{Relation: types.ResourceRelationUsesRole, Path: "Site.identity.userAssignedIdentities{}", TargetType: "Microsoft.ManagedIdentity/userAssignedIdentities"},
+		rule{Relation: types.ResourceRelationInNetwork, Path: "Site.properties.virtualNetworkSubnetId", TargetType: "Microsoft.Network/virtualNetworks/subnets"},
+	)
+	registerRules("Microsoft.ContainerService/managedClusters",
+		rule{Relation: types.ResourceRelationUsesRole, Path: "ManagedCluster.identity.userAssignedIdentities{}", TargetType: "Microsoft.ManagedIdentity/userAssignedIdentities"},
+		rule{Relation: types.ResourceRelationInNetwork, Path: "ManagedCluster.properties.agentPoolProfiles[].vnetSubnetID", TargetType: "Microsoft.Network/virtualNetworks/subnets"},
+	)
+	registerRules("Microsoft.Sql/servers/databases",
+		rule{Relation: types.ResourceRelationContains, Path: "Database.id", TargetType: "Microsoft.Sql/servers", Parent: true, Reverse: true},
+	)
+}
@@ -0,0 +1,198 @@
+package graph
+
+import (
+	"strings"
+
+	"github.com/opengovern/og-util/pkg/source"
+	"github.com/opengovern/opengovernance/pkg/types"
+)
+
+// rule describes how to find the other end of a relationship inside a resource description.
+//
+// Path is a dot separated list of keys into the description. A key suffixed with [] iterates
+// over an array and a key suffixed with {} iterates over an object, yielding its keys when it is
+// the last key of the path.
+type rule struct {
+	Relation   types.ResourceRelation
+	Path       string
+	TargetType string
+
+	// Format rebuilds a full resource id from a short id (e.g. vpc-123) using the placeholders
+	// {partition}, {region}, {account} of the described resource and {value} of the found value.
+	// Values that are already ARNs are kept as they are.
+	Format string
+	// ValuePrefix skips the found values that do not start with it.
+	ValuePrefix string
+	// Parent trims the last child segment from an azure id (e.g. a backend pool to its load balancer).
+	Parent bool
+	// Reverse makes the described resource the target of the edge instead of the source.
+	Reverse bool
+}
+
+var rules = map[string][]rule{}
+
+func registerRules(resourceType string, r ...rule) {
+	resourceType = strings.ToLower(resourceType)
+	rules[resourceType] = append(rules[resourceType], r...)
+}
+
+// SupportedResourceTypes returns the resource types edges can be extracted from.
+func SupportedResourceTypes() []string {
+	res := make([]string, 0, len(rules))
+	for resourceType := range rules {
+		res = append(res, resourceType)
+	}
+	return res
+}
+
+// ExtractEdges extracts the relationships of a described resource document as it is sent to the
+// es sink. Documents that are not described resources, or whose type has no rules, produce no edges.
+// Resource types on the edges are lower-cased, the same way they are in the lookup index.
+func ExtractEdges(doc map[string]any) []types.ResourceEdge {
+	resourceType, _ := doc["resource_type"].(string)
+	description, ok := doc["description"]
+	if resourceType == "" || !ok || description == nil {
+		return nil
+	}
+	resourceType = strings.ToLower(resourceType)
+	resourceRules, ok := rules[resourceType]
+	if !ok {
+		return nil
+	}
+
+	resourceID, _ := doc["id"].(string)
+	arn, _ := doc["arn"].(string)
+	if resourceID == "" {
+		resourceID = arn
+	}
+	if resourceID == "" {
+		return nil
+	}
+	if arn == "" && strings.HasPrefix(resourceID, "arn:") {
+		arn = resourceID
+	}
+	sourceID, _ := doc["source_id"].(string)
+	sourceType, _ := doc["source_type"].(string)
+	createdAt, _ := doc["created_at"].(float64)
+	resourceJobID, _ := doc["resource_job_id"].(float64)
+
+	var edges []types.ResourceEdge
+	seen := map[string]bool{}
+	for _, r := range resourceRules {
+		for _, value := range valuesAt(description, strings.Split(r.Path, ".")) {
+			if r.ValuePrefix != "" && !strings.HasPrefix(value, r.ValuePrefix) {
+				continue
+			}
+			targetID := value
+			if r.Format != "" {
+				targetID = formatAwsID(r.Format, arn, value)
+			}
+			if r.Parent {
+				targetID = azureParentID(targetID)
+			}
+			if targetID == "" || targetID == resourceID {
+				continue
+			}
+
+			edge := types.ResourceEdge{
+				FromResourceID:   resourceID,
+				FromResourceType: resourceType,
+				ToResourceID:     targetID,
+				ToResourceType:   strings.ToLower(r.TargetType),
+				Relation:         r.Relation,
+				SourceID:         sourceID,
+				SourceType:       source.Type(sourceType),
+				ResourceType:     resourceType,
+				ResourceJobID:    uint(resourceJobID),
+				CreatedAt:        int64(createdAt),
+			}
+			if r.Reverse {
+				edge.FromResourceID, edge.ToResourceID = edge.ToResourceID, edge.FromResourceID
+				edge.FromResourceType, edge.ToResourceType = edge.ToResourceType, edge.FromResourceType
+			}
+
+			key := edge.FromResourceID + "|" + edge.ToResourceID + "|" + string(edge.Relation)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			edges = append(edges, edge)
+		}
+	}
+
+	return edges
+}
+
+func valuesAt(v any, path []string) []string {
+	if len(path) == 0 {
+		if s, ok := v.(string); ok && s != "" {
+			return []string{s}
+		}
+		return nil
+	}
+
+	obj, ok := v.(map[string]any)
+	if !ok {
+		return nil
+	}
+
+	key := path[0]
+	switch {
+	case strings.HasSuffix(key, "[]"):
+		arr, ok := obj[strings.TrimSuffix(key, "[]")].([]any)
+		if !ok {
+			return nil
+		}
+		var res []string
+		for _, item := range arr {
+			res = append(res, valuesAt(item, path[1:])...)
+		}
+		return res
+	case strings.HasSuffix(key, "{}"):
+		m, ok := obj[strings.TrimSuffix(key, "{}")].(map[string]any)
+		if !ok {
+			return nil
+		}
+		var res []string
+		for k, item := range m {
+			if len(path) == 1 {
+				res = append(res, k)
+			} else {
+				res = append(res, valuesAt(item, path[1:])...)
+			}
+		}
+		return res
+	default:
+		return valuesAt(obj[key], path[1:])
+	}
+}
+
+// formatAwsID builds an ARN for value using the partition, region and account of the described resource arn.
+func formatAwsID(format, arn, value string) string {
+	if strings.HasPrefix(value, "arn:") {
+		return value
+	}
+	parts := strings.SplitN(arn, ":", 6)
+	if len(parts) < 5 {
+		return ""
+	}
+	replacer := strings.NewReplacer(
+		"{partition}", parts[1],
+		"{region}", parts[3],
+		"{account}", parts[4],
+		"{value}", value,
+	)
+	return replacer.Replace(format)
+}
+
+func azureParentID(id string) string {
+	idx := strings.LastIndex(id, "/")
+	if idx <= 0 {
+		return id
+	}
+	idx = strings.LastIndex(id[:idx], "/")
+	if idx <= 0 {
+		return id
+	}
+	return id[:idx]
+}
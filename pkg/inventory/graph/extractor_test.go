@@ -0,0 +1,109 @@
+package graph
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/opengovern/opengovernance/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decodeDoc(t *testing.T, s string) map[string]any {
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal([]byte(s), &doc))
+	return doc
+}
+
+func TestExtractEdgesAwsInstance(t *testing.T) {
+	doc := decodeDoc(t, `{
+		"id": "arn:aws:ec2:us-east-1:123456789012:instance/i-1",
+		"arn": "arn:aws:ec2:us-east-1:123456789012:instance/i-1",
+		"resource_type": "aws::ec2::instance",
+		"source_id": "conn-1",
+		"source_type": "AWS",
+		"resource_job_id": 42,
+		"description": {
+			"Instance": {
+				"VpcId": "vpc-1",
+				"SubnetId": "subnet-1",
+				"SecurityGroups": [{"GroupId": "sg-1"}, {"GroupId": "sg-2"}],
+				"IamInstanceProfile": {"Arn": "arn:aws:iam::123456789012:instance-profile/web"}
+			}
+		}
+	}`)
+
+	edges := ExtractEdges(doc)
+	require.Len(t, edges, 5)
+
+	byTarget := map[string]types.ResourceEdge{}
+	for _, edge := range edges {
+		assert.Equal(t, "arn:aws:ec2:us-east-1:123456789012:instance/i-1", edge.FromResourceID)
+		assert.Equal(t, uint(42), edge.ResourceJobID)
+		assert.Equal(t, "conn-1", edge.SourceID)
+		byTarget[edge.ToResourceID] = edge
+	}
+	assert.Equal(t, types.ResourceRelationInNetwork, byTarget["arn:aws:ec2:us-east-1:123456789012:vpc/vpc-1"].Relation)
+	assert.Equal(t, types.ResourceRelationAttachedTo, byTarget["arn:aws:ec2:us-east-1:123456789012:security-group/sg-2"].Relation)
+	assert.Equal(t, types.ResourceRelationUsesRole, byTarget["arn:aws:iam::123456789012:instance-profile/web"].Relation)
+}
+
+func TestExtractEdgesAwsVolumeAttachment(t *testing.T) {
+	instance := decodeDoc(t, `{
+		"id": "arn:aws:ec2:us-east-1:123456789012:instance/i-1",
+		"resource_type": "aws::ec2::instance",
+		"description": {
+			"Instance": {
+				"BlockDeviceMappings": [{"Ebs": {"VolumeId": "vol-1"}}]
+			}
+		}
+	}`)
+	volume := decodeDoc(t, `{
+		"id": "arn:aws:ec2:us-east-1:123456789012:volume/vol-1",
+		"resource_type": "aws::ec2::volume",
+		"description": {
+			"Volume": {
+				"Attachments": [{"InstanceId": "i-1"}]
+			}
+		}
+	}`)
+
+	edges := append(ExtractEdges(instance), ExtractEdges(volume)...)
+	require.Len(t, edges, 1, "the attachment is emitted from the instance side only")
+	assert.Equal(t, "arn:aws:ec2:us-east-1:123456789012:instance/i-1", edges[0].FromResourceID)
+	assert.Equal(t, "arn:aws:ec2:us-east-1:123456789012:volume/vol-1", edges[0].ToResourceID)
+	assert.Equal(t, "aws::ec2::volume", edges[0].ToResourceType)
+	assert.Equal(t, types.ResourceRelationAttachedTo, edges[0].Relation)
+}
+
+func TestExtractEdgesReverseAndParent(t *testing.T) {
+	doc := decodeDoc(t, `{
+		"id": "/subscriptions/s/resourceGroups/rg/providers/Microsoft.Network/networkInterfaces/nic",
+		"resource_type": "Microsoft.Network/networkInterfaces",
+		"description": {
+			"Interface": {
+				"properties": {
+					"ipConfigurations": [{
+						"properties": {
+							"loadBalancerBackendAddressPools": [
+								{"id": "/subscriptions/s/resourceGroups/rg/providers/Microsoft.Network/loadBalancers/lb/backendAddressPools/pool"}
+							]
+						}
+					}]
+				}
+			}
+		}
+	}`)
+
+	edges := ExtractEdges(doc)
+	require.Len(t, edges, 1)
+	assert.Equal(t, "/subscriptions/s/resourceGroups/rg/providers/Microsoft.Network/loadBalancers/lb", edges[0].FromResourceID)
+	assert.Equal(t, "microsoft.network/loadbalancers", edges[0].FromResourceType)
+	assert.Equal(t, "/subscriptions/s/resourceGroups/rg/providers/Microsoft.Network/networkInterfaces/nic", edges[0].ToResourceID)
+	assert.Equal(t, types.ResourceRelationRoutesTo, edges[0].Relation)
+}
+
+func TestExtractEdgesIgnoresNonResourceDocs(t *testing.T) {
+	assert.Empty(t, ExtractEdges(decodeDoc(t, `{"resource_id": "x", "resource_type": "aws::ec2::instance"}`)))
+	assert.Empty(t, ExtractEdges(decodeDoc(t, `{"id": "x", "resource_type": "aws::s3::bucket", "description": {}}`)))
+}
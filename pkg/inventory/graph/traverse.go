@@ -0,0 +1,140 @@
+package graph
+
+import (
+	"fmt"
+
+	"github.com/opengovern/opengovernance/pkg/types"
+)
+
+type Direction string
+
+const (
+	DirectionOutgoing Direction = "outgoing"
+	DirectionIncoming Direction = "incoming"
+	DirectionBoth     Direction = "both"
+)
+
+const (
+	MaxHops  = 6
+	MaxNodes = 2000
+)
+
+func ParseDirection(s string) (Direction, error) {
+	switch Direction(s) {
+	case "":
+		return DirectionBoth, nil
+	case DirectionOutgoing, DirectionIncoming, DirectionBoth:
+		return Direction(s), nil
+	}
+	return "", fmt.Errorf("invalid direction %s", s)
+}
+
+// EdgeFetcher returns the edges touching any of the given resources in the given direction.
+type EdgeFetcher func(resourceIDs []string, direction Direction) ([]types.ResourceEdge, error)
+
+// next returns the resource on the other side of edge when walking from one of the current resources,
+// or an empty string if the edge can't be walked in the direction.
+func next(edge types.ResourceEdge, current map[string]bool, direction Direction) string {
+	if direction != DirectionIncoming && current[edge.FromResourceID] {
+		return edge.ToResourceID
+	}
+	if direction != DirectionOutgoing && current[edge.ToResourceID] {
+		return edge.FromResourceID
+	}
+	return ""
+}
+
+// Traverse walks the graph breadth first from resourceID up to hops edges away and returns the walked
+// edges and the distance of every reached resource. The walk stops early once MaxNodes are reached.
+func Traverse(fetch EdgeFetcher, resourceID string, hops int, direction Direction) ([]types.ResourceEdge, map[string]int, error) {
+	depth := map[string]int{resourceID: 0}
+	var edges []types.ResourceEdge
+	seenEdges := map[string]bool{}
+
+	frontier := []string{resourceID}
+	for hop := 1; hop <= hops && len(frontier) > 0 && len(depth) < MaxNodes; hop++ {
+		current := make(map[string]bool, len(frontier))
+		for _, id := range frontier {
+			current[id] = true
+		}
+
+		found, err := fetch(frontier, direction)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		frontier = nil
+		for _, edge := range found {
+			other := next(edge, current, direction)
+			if other == "" {
+				continue
+			}
+			key := edge.FromResourceID + "|" + edge.ToResourceID + "|" + string(edge.Relation)
+			if !seenEdges[key] {
+				seenEdges[key] = true
+				edges = append(edges, edge)
+			}
+			if _, ok := depth[other]; ok {
+				continue
+			}
+			depth[other] = hop
+			frontier = append(frontier, other)
+			if len(depth) >= MaxNodes {
+				break
+			}
+		}
+	}
+
+	return edges, depth, nil
+}
+
+// ShortestPath returns the edges of a shortest path from one resource to another that is at most
+// maxHops long, or nil if there is none.
+func ShortestPath(fetch EdgeFetcher, from, to string, maxHops int, direction Direction) ([]types.ResourceEdge, error) {
+	if from == to {
+		return []types.ResourceEdge{}, nil
+	}
+
+	parent := map[string]types.ResourceEdge{}
+	visited := map[string]bool{from: true}
+
+	frontier := []string{from}
+	for hop := 1; hop <= maxHops && len(frontier) > 0 && len(visited) < MaxNodes; hop++ {
+		current := make(map[string]bool, len(frontier))
+		for _, id := range frontier {
+			current[id] = true
+		}
+
+		found, err := fetch(frontier, direction)
+		if err != nil {
+			return nil, err
+		}
+
+		frontier = nil
+		for _, edge := range found {
+			other := next(edge, current, direction)
+			if other == "" || visited[other] {
+				continue
+			}
+			visited[other] = true
+			parent[other] = edge
+			frontier = append(frontier, other)
+
+			if other == to {
+				var path []types.ResourceEdge
+				for node := to; node != from; {
+					e := parent[node]
+					path = append([]types.ResourceEdge{e}, path...)
+					if e.ToResourceID == node {
+						node = e.FromResourceID
+					} else {
+						node = e.ToResourceID
+					}
+				}
+				return path, nil
+			}
+		}
+	}
+
+	return nil, nil
+}
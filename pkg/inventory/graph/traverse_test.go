@@ -0,0 +1,62 @@
+package graph
+
+import (
+	"testing"
+
+	"github.com/opengovern/opengovernance/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func memoryFetcher(edges []types.ResourceEdge) EdgeFetcher {
+	return func(resourceIDs []string, direction Direction) ([]types.ResourceEdge, error) {
+		ids := map[string]bool{}
+		for _, id := range resourceIDs {
+			ids[id] = true
+		}
+		var res []types.ResourceEdge
+		for _, edge := range edges {
+			if (direction != DirectionIncoming && ids[edge.FromResourceID]) ||
+				(direction != DirectionOutgoing && ids[edge.ToResourceID]) {
+				res = append(res, edge)
+			}
+		}
+		return res, nil
+	}
+}
+
+var testEdges = []types.ResourceEdge{
+	{FromResourceID: "lb", ToResourceID: "tg", Relation: types.ResourceRelationRoutesTo},
+	{FromResourceID: "tg", ToResourceID: "i-1", Relation: types.ResourceRelationRoutesTo},
+	{FromResourceID: "tg", ToResourceID: "i-2", Relation: types.ResourceRelationRoutesTo},
+	{FromResourceID: "i-1", ToResourceID: "profile", Relation: types.ResourceRelationUsesRole},
+	{FromResourceID: "profile", ToResourceID: "role", Relation: types.ResourceRelationUsesRole},
+	{FromResourceID: "i-1", ToResourceID: "vpc", Relation: types.ResourceRelationInNetwork},
+}
+
+func TestTraverse(t *testing.T) {
+	edges, depth, err := Traverse(memoryFetcher(testEdges), "lb", 2, DirectionOutgoing)
+	require.NoError(t, err)
+	assert.Len(t, edges, 3)
+	assert.Equal(t, map[string]int{"lb": 0, "tg": 1, "i-1": 2, "i-2": 2}, depth)
+
+	_, depth, err = Traverse(memoryFetcher(testEdges), "vpc", 1, DirectionBoth)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"vpc": 0, "i-1": 1}, depth)
+}
+
+func TestShortestPath(t *testing.T) {
+	path, err := ShortestPath(memoryFetcher(testEdges), "lb", "role", 5, DirectionOutgoing)
+	require.NoError(t, err)
+	require.Len(t, path, 4)
+	assert.Equal(t, "lb", path[0].FromResourceID)
+	assert.Equal(t, "role", path[3].ToResourceID)
+
+	path, err = ShortestPath(memoryFetcher(testEdges), "lb", "role", 3, DirectionOutgoing)
+	require.NoError(t, err)
+	assert.Nil(t, path)
+
+	path, err = ShortestPath(memoryFetcher(testEdges), "role", "lb", 5, DirectionOutgoing)
+	require.NoError(t, err)
+	assert.Nil(t, path)
+}
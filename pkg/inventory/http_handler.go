@@ -3,6 +3,8 @@ package inventory
 import (
 	"fmt"
 	"github.com/opengovern/og-util/pkg/opengovernance-es-sdk"
+	"github.com/opengovern/opengovernance/pkg/analytics/resourcecollection"
+	"github.com/opengovern/opengovernance/pkg/auth/audit"
 	metadataClient "github.com/opengovern/opengovernance/pkg/metadata/client"
 
 	kaytuAws "github.com/opengovern/og-aws-describer/pkg/opengovernance-es-sdk"
@@ -30,6 +32,11 @@ type HttpHandler struct {
 	complianceClient complianceClient.ComplianceServiceClient
 	metadataClient   metadataClient.MetadataServiceClient
 
+	// memberCache keeps the members of the resource collections requests are scoped to
+	memberCache resourcecollection.MemberCache
+
+	auditRecorder *audit.Recorder
+
 	logger *zap.Logger
 
 	awsPlg, azurePlg, azureADPlg *plugin.Plugin
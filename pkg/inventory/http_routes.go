@@ -3,6 +3,8 @@ package inventory
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -23,19 +25,26 @@ import (
 	"text/template"
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/jackc/pgtype"
 	"github.com/labstack/echo/v4"
 	"github.com/open-policy-agent/opa/rego"
 	kaytuAws "github.com/opengovern/og-aws-describer/aws"
+	awsSteampipe "github.com/opengovern/og-aws-describer/pkg/steampipe"
 	kaytuAzure "github.com/opengovern/og-azure-describer/azure"
+	azureSteampipe "github.com/opengovern/og-azure-describer/pkg/steampipe"
 	"github.com/opengovern/og-util/pkg/describe"
 	"github.com/opengovern/og-util/pkg/model"
 	esSdk "github.com/opengovern/og-util/pkg/opengovernance-es-sdk"
 	"github.com/opengovern/og-util/pkg/source"
 	"github.com/opengovern/og-util/pkg/steampipe"
 	analyticsDB "github.com/opengovern/opengovernance/pkg/analytics/db"
+	"github.com/opengovern/opengovernance/pkg/analytics/resourcecollection"
+	"github.com/opengovern/opengovernance/pkg/auth/audit"
 	"github.com/opengovern/opengovernance/pkg/demo"
 	inventoryApi "github.com/opengovern/opengovernance/pkg/inventory/api"
 	"github.com/opengovern/opengovernance/pkg/inventory/es"
+	"github.com/opengovern/opengovernance/pkg/inventory/graph"
 	"github.com/opengovern/opengovernance/pkg/utils"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
@@ -61,7 +70,13 @@ const (
 	ConnectionGroupParam = "connectionGroup"
 )
 
+// queryResultCacheTTL bounds how long a cached query result is served, in case the invalidation after a discovery
+// is missed.
+const queryResultCacheTTL = 24 * time.Hour
+
 func (h *HttpHandler) Register(e *echo.Echo) {
+	e.Use(audit.Middleware(h.auditRecorder))
+
 	v1 := e.Group("/api/v1")
 
 	queryV1 := v1.Group("/query")
@@ -73,6 +88,10 @@ func (h *HttpHandler) Register(e *echo.Echo) {
 
 	resourcesV2 := v2.Group("/resources")
 	resourcesV2.GET("/count", httpserver.AuthorizeHandler(h.CountResources, api.ViewerRole))
+	resourcesV2.POST("/search", httpserver.AuthorizeHandler(h.SearchResources, api.ViewerRole))
+	resourcesV2.GET("/graph/neighbors", httpserver.AuthorizeHandler(h.GetResourceNeighbors, api.ViewerRole))
+	resourcesV2.GET("/graph/traverse", httpserver.AuthorizeHandler(h.TraverseResourceGraph, api.ViewerRole))
+	resourcesV2.GET("/graph/path", httpserver.AuthorizeHandler(h.GetResourceGraphPath, api.ViewerRole))
 
 	analyticsV2 := v2.Group("/analytics")
 	analyticsV2.GET("/count", httpserver.AuthorizeHandler(h.CountAnalytics, api.ViewerRole))
@@ -92,19 +111,42 @@ func (h *HttpHandler) Register(e *echo.Echo) {
 	analyticsSpend.GET("/composition", httpserver.AuthorizeHandler(h.ListAnalyticsSpendComposition, api.ViewerRole))
 	analyticsSpend.GET("/trend", httpserver.AuthorizeHandler(h.GetAnalyticsSpendTrend, api.ViewerRole))
 	analyticsSpend.GET("/table", httpserver.AuthorizeHandler(h.GetSpendTable, api.ViewerRole))
+	analyticsSpend.GET("/anomalies", httpserver.AuthorizeHandler(h.ListSpendAnomalies, api.ViewerRole))
+	analyticsSpend.GET("/anomalies/mutes", httpserver.AuthorizeHandler(h.ListSpendAnomalyMutes, api.ViewerRole))
+	analyticsSpend.POST("/anomalies/mutes", httpserver.AuthorizeHandler(h.CreateSpendAnomalyMute, api.EditorRole))
+	analyticsSpend.DELETE("/anomalies/mutes/:muteId", httpserver.AuthorizeHandler(h.DeleteSpendAnomalyMute, api.EditorRole))
+	analyticsSpend.GET("/anomalies/:anomalyId", httpserver.AuthorizeHandler(h.GetSpendAnomaly, api.ViewerRole))
+	analyticsSpend.POST("/anomalies/:anomalyId/acknowledge", httpserver.AuthorizeHandler(h.AcknowledgeSpendAnomaly, api.EditorRole))
+
+	tagPolicies := v2.Group("/tag-policies")
+	tagPolicies.GET("", httpserver.AuthorizeHandler(h.ListTagPolicies, api.ViewerRole))
+	tagPolicies.POST("", httpserver.AuthorizeHandler(h.CreateTagPolicy, api.EditorRole))
+	tagPolicies.GET("/coverage", httpserver.AuthorizeHandler(h.GetTagPolicyCoverage, api.ViewerRole))
+	tagPolicies.GET("/non-compliant", httpserver.AuthorizeHandler(h.ListTagPolicyNonCompliantResources, api.ViewerRole))
+	tagPolicies.GET("/suggestions", httpserver.AuthorizeHandler(h.ListTagPolicySuggestions, api.ViewerRole))
+	tagPolicies.GET("/:policyId", httpserver.AuthorizeHandler(h.GetTagPolicy, api.ViewerRole))
+	tagPolicies.PUT("/:policyId", httpserver.AuthorizeHandler(h.UpdateTagPolicy, api.EditorRole))
+	tagPolicies.DELETE("/:policyId", httpserver.AuthorizeHandler(h.DeleteTagPolicy, api.EditorRole))
 
 	connectionsV2 := v2.Group("/connections")
 	connectionsV2.GET("/data", httpserver.AuthorizeHandler(h.ListConnectionsData, api.ViewerRole))
 
-	resourceCollection := v2.Group("/resource-collection")
+	resourceCollection := v2.Group("/resource-collection", checkResourceCollectionScope)
 	resourceCollection.GET("", httpserver.AuthorizeHandler(h.ListResourceCollections, api.ViewerRole))
 	resourceCollection.GET("/:resourceCollectionId", httpserver.AuthorizeHandler(h.GetResourceCollection, api.ViewerRole))
+	resourceCollection.POST("", httpserver.AuthorizeHandler(h.CreateResourceCollection, api.EditorRole))
+	resourceCollection.PUT("/:resourceCollectionId", httpserver.AuthorizeHandler(h.UpdateResourceCollection, api.EditorRole))
+	resourceCollection.DELETE("/:resourceCollectionId", httpserver.AuthorizeHandler(h.DeleteResourceCollection, api.EditorRole))
 	resourceCollection.GET("/:resourceCollectionId/landscape", httpserver.AuthorizeHandler(h.GetResourceCollectionLandscape, api.ViewerRole))
+	resourceCollection.GET("/:resourceCollectionId/members", httpserver.AuthorizeHandler(h.ListResourceCollectionMembers, api.ViewerRole))
+	resourceCollection.GET("/:resourceCollectionId/members/count", httpserver.AuthorizeHandler(h.CountResourceCollectionMembers, api.ViewerRole))
+	resourceCollection.GET("/:resourceCollectionId/members/changes", httpserver.AuthorizeHandler(h.ListResourceCollectionMembershipChanges, api.ViewerRole))
+	resourceCollection.PUT("/:resourceCollectionId/membership", httpserver.AuthorizeHandler(h.UpdateResourceCollectionMembership, api.InternalRole))
 
 	metadata := v2.Group("/metadata")
 	metadata.GET("/resourcetype", httpserver.AuthorizeHandler(h.ListResourceTypeMetadata, api.ViewerRole))
 
-	resourceCollectionMetadata := metadata.Group("/resource-collection")
+	resourceCollectionMetadata := metadata.Group("/resource-collection", checkResourceCollectionScope)
 	resourceCollectionMetadata.GET("", httpserver.AuthorizeHandler(h.ListResourceCollectionsMetadata, api.ViewerRole))
 	resourceCollectionMetadata.GET("/:resourceCollectionId", httpserver.AuthorizeHandler(h.GetResourceCollectionMetadata, api.ViewerRole))
 
@@ -114,6 +156,7 @@ func (h *HttpHandler) Register(e *echo.Echo) {
 	v3.GET("/query/:query_id", httpserver.AuthorizeHandler(h.GetQuery, api.ViewerRole))
 	v3.GET("/queries/tags", httpserver.AuthorizeHandler(h.ListQueriesTags, api.ViewerRole))
 	v3.POST("/query/run", httpserver.AuthorizeHandler(h.RunQueryByID, api.ViewerRole))
+	v3.POST("/query/cache/invalidate", httpserver.AuthorizeHandler(h.InvalidateQueryCache, api.InternalRole))
 	v3.GET("/query/async/run/:run_id/result", httpserver.AuthorizeHandler(h.GetAsyncQueryRunResult, api.ViewerRole))
 	v3.GET("/resources/categories", httpserver.AuthorizeHandler(h.GetResourceCategories, api.ViewerRole))
 	v3.GET("/queries/categories", httpserver.AuthorizeHandler(h.GetQueriesResourceCategories, api.ViewerRole))
@@ -165,6 +208,55 @@ func (h *HttpHandler) getConnectionIdFilterFromParams(ctx echo.Context) ([]strin
 	return connectionIds, nil
 }
 
+// getResourceCollectionFilterFromParams returns the requested resource collections narrowed to the resource
+// collection scope of the request, the scoped collections if none is requested.
+func getResourceCollectionFilterFromParams(ctx echo.Context) ([]string, error) {
+	return utils.ResolveResourceCollectionIDs(ctx, httpserver.QueryArrayParam(ctx, "resourceCollection"))
+}
+
+// getSpendConnectionIdFilterFromParams narrows the connection filter to the connections of the members of the
+// requested resource collections, spend is only tracked per connection.
+func (h *HttpHandler) getSpendConnectionIdFilterFromParams(ctx echo.Context) ([]string, error) {
+	connectionIds, err := h.getConnectionIdFilterFromParams(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resourceCollections, err := getResourceCollectionFilterFromParams(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(resourceCollections) == 0 {
+		return connectionIds, nil
+	}
+
+	requested := make(map[string]bool)
+	for _, connectionId := range connectionIds {
+		requested[connectionId] = true
+	}
+	connectionMap := make(map[string]bool)
+	for _, resourceCollectionId := range resourceCollections {
+		_, _, perConnection, err := es.CountResourceCollectionMembers(ctx.Request().Context(), h.client, resourceCollectionId, EsFetchPageSize)
+		if err != nil {
+			h.logger.Error("failed to count resource collection members", zap.String("resourceCollectionId", resourceCollectionId), zap.Error(err))
+			return nil, err
+		}
+		for connectionId := range perConnection {
+			if len(requested) == 0 || requested[connectionId] {
+				connectionMap[connectionId] = true
+			}
+		}
+	}
+	if len(connectionMap) == 0 {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "resource collection(s) do not have any members in the requested connections")
+	}
+
+	connectionIds = make([]string, 0, len(connectionMap))
+	for connectionId := range connectionMap {
+		connectionIds = append(connectionIds, connectionId)
+	}
+	return connectionIds, nil
+}
+
 func bindValidate(ctx echo.Context, i interface{}) error {
 	if err := ctx.Bind(i); err != nil {
 		return err
@@ -290,7 +382,10 @@ func (h *HttpHandler) ListAnalyticsMetricsHandler(ctx echo.Context) error {
 	if len(connectionIDs) > MaxConns {
 		return ctx.JSON(http.StatusBadRequest, "too many connections")
 	}
-	resourceCollections := httpserver.QueryArrayParam(ctx, "resourceCollection")
+	resourceCollections, err := getResourceCollectionFilterFromParams(ctx)
+	if err != nil {
+		return err
+	}
 	metricIDs := httpserver.QueryArrayParam(ctx, "metricIDs")
 
 	connectorTypes, err = h.getConnectorTypesFromConnectionIDs(ctx, connectorTypes, connectionIDs)
@@ -491,9 +586,15 @@ func (h *HttpHandler) ListAnalyticsTags(ctx echo.Context) error {
 	if metricType == "" {
 		metricType = analyticsDB.MetricTypeAssets
 	}
-	resourceCollections := httpserver.QueryArrayParam(ctx, "resourceCollection")
+	resourceCollections, err := getResourceCollectionFilterFromParams(ctx)
+	if err != nil {
+		return err
+	}
 	if len(resourceCollections) > 0 && metricType == analyticsDB.MetricTypeSpend {
-		return ctx.JSON(http.StatusBadRequest, "ResourceCollections are not supported for spend metrics")
+		connectionIDs, err = h.getSpendConnectionIdFilterFromParams(ctx)
+		if err != nil {
+			return err
+		}
 	}
 
 	aDB := analyticsDB.NewDatabase(h.db.orm)
@@ -608,7 +709,10 @@ func (h *HttpHandler) ListAnalyticsMetricTrend(ctx echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "too many connections")
 	}
 
-	resourceCollections := httpserver.QueryArrayParam(ctx, "resourceCollection")
+	resourceCollections, err := getResourceCollectionFilterFromParams(ctx)
+	if err != nil {
+		return err
+	}
 
 	endTimeStr := ctx.QueryParam("endTime")
 	endTime := time.Now()
@@ -795,7 +899,10 @@ func (h *HttpHandler) ListAnalyticsComposition(ctx echo.Context) error {
 		return ctx.JSON(http.StatusBadRequest, "too many connections")
 	}
 
-	resourceCollections := httpserver.QueryArrayParam(ctx, "resourceCollection")
+	resourceCollections, err := getResourceCollectionFilterFromParams(ctx)
+	if err != nil {
+		return err
+	}
 
 	endTime := time.Now()
 	if endTimeStr := ctx.QueryParam("endTime"); endTimeStr != "" {
@@ -1109,6 +1216,7 @@ func (h *HttpHandler) GetAssetsTable(ctx echo.Context) error {
 //	@Param			connector		query		[]source.Type	false	"Connector type to filter by"
 //	@Param			connectionId	query		[]string		false	"Connection IDs to filter by - mutually exclusive with connectionGroup"
 //	@Param			connectionGroup	query		[]string		false	"Connection group to filter by - mutually exclusive with connectionId"
+//	@Param			resourceCollection	query		[]string		false	"Resource collection IDs to filter by, spend is scoped to the connections of their members"
 //	@Param			startTime		query		int64			false	"timestamp for start in epoch seconds"
 //	@Param			endTime			query		int64			false	"timestamp for end in epoch seconds"
 //	@Param			sortBy			query		string			false	"Sort by field - default is cost"	Enums(dimension,cost,growth,growth_rate)
@@ -1120,7 +1228,7 @@ func (h *HttpHandler) GetAssetsTable(ctx echo.Context) error {
 func (h *HttpHandler) ListAnalyticsSpendMetricsHandler(ctx echo.Context) error {
 	var err error
 	connectorTypes := source.ParseTypes(httpserver.QueryArrayParam(ctx, "connector"))
-	connectionIDs, err := h.getConnectionIdFilterFromParams(ctx)
+	connectionIDs, err := h.getSpendConnectionIdFilterFromParams(ctx)
 	if err != nil {
 		return err
 	}
@@ -1497,6 +1605,7 @@ func (h *HttpHandler) GetMetric(ctx echo.Context) error {
 //	@Param			connector		query		[]source.Type	false	"Connector type to filter by"
 //	@Param			connectionId	query		[]string		false	"Connection IDs to filter by - mutually exclusive with connectionGroup"
 //	@Param			connectionGroup	query		[]string		false	"Connection group to filter by - mutually exclusive with connectionId"
+//	@Param			resourceCollection	query		[]string		false	"Resource collection IDs to filter by, spend is scoped to the connections of their members"
 //	@Param			top				query		int				false	"How many top values to return default is 5"
 //	@Param			startTime		query		int64			false	"timestamp for start in epoch seconds"
 //	@Param			endTime			query		int64			false	"timestamp for end in epoch seconds"
@@ -1506,7 +1615,7 @@ func (h *HttpHandler) ListAnalyticsSpendComposition(ctx echo.Context) error {
 	aDB := analyticsDB.NewDatabase(h.db.orm)
 	var err error
 	connectorTypes := source.ParseTypes(httpserver.QueryArrayParam(ctx, "connector"))
-	connectionIDs, err := h.getConnectionIdFilterFromParams(ctx)
+	connectionIDs, err := h.getSpendConnectionIdFilterFromParams(ctx)
 	if err != nil {
 		return err
 	}
@@ -1639,6 +1748,7 @@ func (h *HttpHandler) ListAnalyticsSpendComposition(ctx echo.Context) error {
 //	@Param			connector		query		[]source.Type	false	"Connector type to filter by"
 //	@Param			connectionId	query		[]string		false	"Connection IDs to filter by - mutually exclusive with connectionGroup"
 //	@Param			connectionGroup	query		[]string		false	"Connection group to filter by - mutually exclusive with connectionId"
+//	@Param			resourceCollection	query		[]string		false	"Resource collection IDs to filter by, spend is scoped to the connections of their members"
 //	@Param			metricIds		query		[]string		false	"Metrics IDs"
 //	@Param			startTime		query		int64			false	"timestamp for start in epoch seconds"
 //	@Param			endTime			query		int64			false	"timestamp for end in epoch seconds"
@@ -1661,7 +1771,7 @@ func (h *HttpHandler) GetAnalyticsSpendTrend(ctx echo.Context) error {
 		metricIds = append(metricIds, m.ID)
 	}
 
-	connectionIDs, err := h.getConnectionIdFilterFromParams(ctx)
+	connectionIDs, err := h.getSpendConnectionIdFilterFromParams(ctx)
 	if err != nil {
 		return err
 	}
@@ -1754,6 +1864,7 @@ func (h *HttpHandler) GetAnalyticsSpendTrend(ctx echo.Context) error {
 //	@Param			dimension		query		string		false	"Dimension of the table, default is metric"		Enums(connection, metric)
 //	@Param			connectionId	query		[]string	false	"Connection IDs to filter by - mutually exclusive with connectionGroup"
 //	@Param			connectionGroup	query		[]string	false	"Connection group to filter by - mutually exclusive with connectionId"
+//	@Param			resourceCollection	query		[]string	false	"Resource collection IDs to filter by, spend is scoped to the connections of their members"
 //	@Param			connector		query		[]string	false	"Connector"
 //	@Param			metricIds		query		[]string	false	"Metrics IDs"
 //
@@ -1774,7 +1885,7 @@ func (h *HttpHandler) GetSpendTable(ctx echo.Context) error {
 	}
 
 	connectors := source.ParseTypes(httpserver.QueryArrayParam(ctx, "connector"))
-	connectionIDs, err := h.getConnectionIdFilterFromParams(ctx)
+	connectionIDs, err := h.getSpendConnectionIdFilterFromParams(ctx)
 	if err != nil {
 		return err
 	}
@@ -1908,7 +2019,10 @@ func (h *HttpHandler) ListConnectionsData(ctx echo.Context) error {
 	performanceStartTime := time.Now()
 	var err error
 	connectionIDs := httpserver.QueryArrayParam(ctx, "connectionId")
-	resourceCollections := httpserver.QueryArrayParam(ctx, "resourceCollection")
+	resourceCollections, err := getResourceCollectionFilterFromParams(ctx)
+	if err != nil {
+		return err
+	}
 	metricIDFilters := httpserver.QueryArrayParam(ctx, "metricId")
 	connectors, err := h.getConnectorTypesFromConnectionIDs(ctx, nil, connectionIDs)
 	if err != nil {
@@ -2284,15 +2398,23 @@ func (h *HttpHandler) RunQuery(ctx echo.Context) error {
 		return fmt.Errorf("failed to execute query template: %w", err)
 	}
 
+	scopedQuery, err := h.scopeQuery(ctx, queryOutput.String())
+	if err != nil {
+		return err
+	}
+
 	var resp *inventoryApi.RunQueryResponse
 	if req.Engine == nil || *req.Engine == inventoryApi.QueryEngine_OdysseusSQL {
-		resp, err = h.RunSQLNamedQuery(outputS, *req.Query, queryOutput.String(), &req)
+		resp, err = h.RunSQLNamedQuery(outputS, *req.Query, queryOutput.String(), scopedQuery, &req)
 		if err != nil {
 			span.RecordError(err)
 			span.SetStatus(codes.Error, err.Error())
 			return err
 		}
 	} else if *req.Engine == inventoryApi.QueryEngine_OdysseusRego {
+		if len(utils.GetScopedConnectionIDs(ctx)) > 0 {
+			return echo.NewHTTPError(http.StatusForbidden, "rego queries are not available to scoped users")
+		}
 		resp, err = h.RunRegoNamedQuery(outputS, *req.Query, queryOutput.String(), &req)
 		if err != nil {
 			span.RecordError(err)
@@ -2354,7 +2476,9 @@ func (h *HttpHandler) CountResources(ctx echo.Context) error {
 	return ctx.JSON(http.StatusOK, totalCount)
 }
 
-func (h *HttpHandler) RunSQLNamedQuery(ctx context.Context, title, query string, req *inventoryApi.RunQueryRequest) (*inventoryApi.RunQueryResponse, error) {
+// RunSQLNamedQuery runs scopedQuery, the query restricted to the scope of the request by scopeQuery, while the
+// history and the response keep the query as written.
+func (h *HttpHandler) RunSQLNamedQuery(ctx context.Context, title, query, scopedQuery string, req *inventoryApi.RunQueryRequest) (*inventoryApi.RunQueryResponse, error) {
 	var err error
 	lastIdx := (req.Page.No - 1) * req.Page.Size
 
@@ -2381,7 +2505,7 @@ func (h *HttpHandler) RunSQLNamedQuery(ctx context.Context, title, query string,
 	}
 
 	h.logger.Info("executing named query", zap.String("query", query))
-	res, err := h.steampipeConn.Query(ctx, query, &lastIdx, &req.Page.Size, orderBy, steampipe.DirectionType(direction))
+	res, err := h.steampipeConn.Query(ctx, scopedQuery, &lastIdx, &req.Page.Size, orderBy, steampipe.DirectionType(direction))
 	if err != nil {
 		return nil, echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	}
@@ -2650,6 +2774,10 @@ func (h *HttpHandler) ListResourceTypeMetadata(ctx echo.Context) error {
 //	@Router			/inventory/api/v2/resource-collection [get]
 func (h *HttpHandler) ListResourceCollections(ctx echo.Context) error {
 	ids := httpserver.QueryArrayParam(ctx, "id")
+	ids, err := utils.ResolveResourceCollectionIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
 
 	statuesString := httpserver.QueryArrayParam(ctx, "status")
 	var statuses []ResourceCollectionStatus
@@ -2959,6 +3087,10 @@ func (h *HttpHandler) GetResourceCollectionLandscape(ctx echo.Context) error {
 //	@Router			/inventory/api/v2/metadata/resource-collection [get]
 func (h *HttpHandler) ListResourceCollectionsMetadata(ctx echo.Context) error {
 	ids := httpserver.QueryArrayParam(ctx, "id")
+	ids, err := utils.ResolveResourceCollectionIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
 
 	statuesString := httpserver.QueryArrayParam(ctx, "status")
 	var statuses []ResourceCollectionStatus
@@ -3001,6 +3133,395 @@ func (h *HttpHandler) GetResourceCollectionMetadata(ctx echo.Context) error {
 	return ctx.JSON(http.StatusOK, resourceCollection.ToApi())
 }
 
+// resourceCollectionFromRequest builds a resource collection from its definition, the filters used to scope
+// steampipe queries are derived from the definition.
+func (h *HttpHandler) resourceCollectionFromRequest(req inventoryApi.ResourceCollectionRequest) (*ResourceCollection, error) {
+	def := req.Definition
+	if len(def.Tags) == 0 && len(def.ResourceTypes) == 0 && len(def.Connectors) == 0 &&
+		len(def.ConnectionIDs) == 0 && len(def.Regions) == 0 && def.SQLPredicate == "" {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "definition must have at least one criteria")
+	}
+	if def.SQLPredicate != "" {
+		if err := resourcecollection.ValidateSQLPredicate(def.SQLPredicate); err != nil {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+	}
+
+	var accountIDs []string
+	if len(def.ConnectionIDs) > 0 {
+		connections, err := h.onboardClient.GetSources(&httpclient.Context{UserRole: api.InternalRole}, def.ConnectionIDs)
+		if err != nil {
+			h.logger.Error("failed to get connections", zap.Error(err))
+			return nil, err
+		}
+		if len(connections) != len(def.ConnectionIDs) {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, "invalid connection ids")
+		}
+		for _, connection := range connections {
+			accountIDs = append(accountIDs, connection.ConnectionID)
+		}
+	}
+	filters, _ := resourcecollection.Filters(def, accountIDs)
+
+	filtersJson, err := json.Marshal(filters)
+	if err != nil {
+		return nil, err
+	}
+	definitionJson, err := json.Marshal(def)
+	if err != nil {
+		return nil, err
+	}
+	collection := ResourceCollection{
+		ID:          req.ID,
+		Name:        req.Name,
+		Description: req.Description,
+		Status:      ResourceCollectionStatus(req.Status),
+		Created:     time.Now(),
+	}
+	if collection.Status == "" {
+		collection.Status = ResourceCollectionStatusActive
+	}
+	if err := collection.FiltersJson.Set(filtersJson); err != nil {
+		return nil, err
+	}
+	if err := collection.DefinitionJson.Set(definitionJson); err != nil {
+		return nil, err
+	}
+	for key, values := range req.Tags {
+		collection.Tags = append(collection.Tags, ResourceCollectionTag{
+			Tag: model.Tag{
+				Key:   key,
+				Value: values,
+			},
+			ResourceCollectionID: req.ID,
+		})
+	}
+	return &collection, nil
+}
+
+// CreateResourceCollection godoc
+//
+//	@Summary		Create resource collection
+//	@Description	Creating a resource collection defined by tags, resource types, connections, regions or an SQL predicate over kaytu_lookup.
+//	@Description	Membership is materialized after the next discovery.
+//	@Security		BearerToken
+//	@Tags			resource_collection
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		inventoryApi.ResourceCollectionRequest	true	"Resource collection"
+//	@Success		201		{object}	inventoryApi.ResourceCollection
+//	@Router			/inventory/api/v2/resource-collection [post]
+func (h *HttpHandler) CreateResourceCollection(ctx echo.Context) error {
+	var req inventoryApi.ResourceCollectionRequest
+	if err := bindValidate(ctx, &req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	existing, err := h.db.GetResourceCollection(req.ID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		h.logger.Error("failed to get resource collection", zap.Error(err))
+		return err
+	}
+	if existing != nil && err == nil {
+		return echo.NewHTTPError(http.StatusConflict, "resource collection already exists")
+	}
+
+	collection, err := h.resourceCollectionFromRequest(req)
+	if err != nil {
+		return err
+	}
+	if err := h.db.UpsertResourceCollection(*collection); err != nil {
+		h.logger.Error("failed to create resource collection", zap.Error(err))
+		return err
+	}
+
+	created, err := h.db.GetResourceCollection(req.ID)
+	if err != nil {
+		h.logger.Error("failed to get created resource collection", zap.Error(err))
+		return err
+	}
+	return ctx.JSON(http.StatusCreated, created.ToApi())
+}
+
+// UpdateResourceCollection godoc
+//
+//	@Summary		Update resource collection
+//	@Description	Updating a resource collection created through the API, collections coming from the configuration cannot be updated.
+//	@Security		BearerToken
+//	@Tags			resource_collection
+//	@Accept			json
+//	@Produce		json
+//	@Param			resourceCollectionId	path		string									true	"Resource collection ID"
+//	@Param			request					body		inventoryApi.ResourceCollectionRequest	true	"Resource collection"
+//	@Success		200						{object}	inventoryApi.ResourceCollection
+//	@Router			/inventory/api/v2/resource-collection/{resourceCollectionId} [put]
+func (h *HttpHandler) UpdateResourceCollection(ctx echo.Context) error {
+	collectionID := ctx.Param("resourceCollectionId")
+	var req inventoryApi.ResourceCollectionRequest
+	req.ID = collectionID
+	if err := bindValidate(ctx, &req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	req.ID = collectionID
+
+	existing, err := h.db.GetResourceCollection(collectionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "resource collection not found")
+		}
+		h.logger.Error("failed to get resource collection", zap.Error(err))
+		return err
+	}
+	if existing.Definition == nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "resource collection is not defined through the API")
+	}
+
+	collection, err := h.resourceCollectionFromRequest(req)
+	if err != nil {
+		return err
+	}
+	collection.Created = existing.Created
+	if err := h.db.UpsertResourceCollection(*collection); err != nil {
+		h.logger.Error("failed to update resource collection", zap.Error(err))
+		return err
+	}
+
+	updated, err := h.db.GetResourceCollection(collectionID)
+	if err != nil {
+		h.logger.Error("failed to get updated resource collection", zap.Error(err))
+		return err
+	}
+	return ctx.JSON(http.StatusOK, updated.ToApi())
+}
+
+// DeleteResourceCollection godoc
+//
+//	@Summary		Delete resource collection
+//	@Description	Deleting a resource collection created through the API along with its materialized membership.
+//	@Security		BearerToken
+//	@Tags			resource_collection
+//	@Param			resourceCollectionId	path	string	true	"Resource collection ID"
+//	@Success		200
+//	@Router			/inventory/api/v2/resource-collection/{resourceCollectionId} [delete]
+func (h *HttpHandler) DeleteResourceCollection(ctx echo.Context) error {
+	collectionID := ctx.Param("resourceCollectionId")
+
+	count, err := h.db.DeleteResourceCollection(collectionID)
+	if err != nil {
+		h.logger.Error("failed to delete resource collection", zap.Error(err))
+		return err
+	}
+	if count == 0 {
+		return echo.NewHTTPError(http.StatusNotFound, "resource collection not found or not defined through the API")
+	}
+	if err := es.DeleteResourceCollectionMembers(ctx.Request().Context(), h.client, collectionID); err != nil {
+		h.logger.Error("failed to delete resource collection members", zap.Error(err), zap.String("resourceCollectionID", collectionID))
+	}
+	return ctx.NoContent(http.StatusOK)
+}
+
+// ListResourceCollectionMembers godoc
+//
+//	@Summary		List resource collection members
+//	@Description	Retrieving the resources of a resource collection as of its last membership materialization.
+//	@Security		BearerToken
+//	@Tags			resource_collection
+//	@Produce		json
+//	@Param			resourceCollectionId	path		string		true	"Resource collection ID"
+//	@Param			resourceType			query		[]string	false	"Resource types to filter by"
+//	@Param			connectionId			query		[]string	false	"Connection IDs to filter by"
+//	@Param			pageNumber				query		int			false	"page number - default is 1"
+//	@Param			pageSize				query		int			false	"page size - default is 20"
+//	@Success		200						{object}	inventoryApi.ListResourceCollectionMembersResponse
+//	@Router			/inventory/api/v2/resource-collection/{resourceCollectionId}/members [get]
+func (h *HttpHandler) ListResourceCollectionMembers(ctx echo.Context) error {
+	collectionID := ctx.Param("resourceCollectionId")
+	resourceTypes := httpserver.QueryArrayParam(ctx, "resourceType")
+	connectionIDs := httpserver.QueryArrayParam(ctx, "connectionId")
+	pageNumber, pageSize, err := utils.PageConfigFromStrings(ctx.QueryParam("pageNumber"), ctx.QueryParam("pageSize"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	members, total, err := es.ListResourceCollectionMembers(ctx.Request().Context(), h.client, collectionID, resourceTypes, connectionIDs,
+		int((pageNumber-1)*pageSize), int(pageSize))
+	if err != nil {
+		h.logger.Error("failed to list resource collection members", zap.Error(err))
+		return err
+	}
+
+	res := inventoryApi.ListResourceCollectionMembersResponse{
+		TotalCount: total,
+		Members:    make([]inventoryApi.ResourceCollectionMember, 0, len(members)),
+	}
+	for _, member := range members {
+		res.Members = append(res.Members, inventoryApi.ResourceCollectionMember{
+			ResourceID:   member.ResourceID,
+			ResourceName: member.ResourceName,
+			ResourceType: member.ResourceType,
+			ConnectionID: member.ConnectionID,
+			Connector:    member.Connector,
+			Location:     member.Location,
+			AddedAt:      time.UnixMilli(member.AddedAt),
+		})
+	}
+	return ctx.JSON(http.StatusOK, res)
+}
+
+// CountResourceCollectionMembers godoc
+//
+//	@Summary		Count resource collection members
+//	@Description	Retrieving the number of resources of a resource collection in total, per resource type and per connection.
+//	@Security		BearerToken
+//	@Tags			resource_collection
+//	@Produce		json
+//	@Param			resourceCollectionId	path		string	true	"Resource collection ID"
+//	@Success		200						{object}	inventoryApi.CountResourceCollectionMembersResponse
+//	@Router			/inventory/api/v2/resource-collection/{resourceCollectionId}/members/count [get]
+func (h *HttpHandler) CountResourceCollectionMembers(ctx echo.Context) error {
+	collectionID := ctx.Param("resourceCollectionId")
+	collection, err := h.db.GetResourceCollection(collectionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "resource collection not found")
+		}
+		return err
+	}
+
+	total, resourceTypes, connectionIDs, err := es.CountResourceCollectionMembers(ctx.Request().Context(), h.client, collectionID, EsFetchPageSize)
+	if err != nil {
+		h.logger.Error("failed to count resource collection members", zap.Error(err))
+		return err
+	}
+	return ctx.JSON(http.StatusOK, inventoryApi.CountResourceCollectionMembersResponse{
+		TotalCount:     int(total),
+		ResourceTypes:  resourceTypes,
+		ConnectionIDs:  connectionIDs,
+		MaterializedAt: collection.MaterializedAt,
+	})
+}
+
+// ListResourceCollectionMembershipChanges godoc
+//
+//	@Summary		List resource collection membership changes
+//	@Description	Retrieving the resources added to and removed from a resource collection in its last membership materialization,
+//	@Description	or in all the materializations since the given time.
+//	@Security		BearerToken
+//	@Tags			resource_collection
+//	@Produce		json
+//	@Param			resourceCollectionId	path		string	true	"Resource collection ID"
+//	@Param			since					query		int64	false	"timestamp in epoch seconds, default is the last materialization"
+//	@Param			limit					query		int		false	"maximum number of changes, default is 1000"
+//	@Success		200						{object}	inventoryApi.ListResourceCollectionMembershipChangesResponse
+//	@Router			/inventory/api/v2/resource-collection/{resourceCollectionId}/members/changes [get]
+func (h *HttpHandler) ListResourceCollectionMembershipChanges(ctx echo.Context) error {
+	collectionID := ctx.Param("resourceCollectionId")
+	collection, err := h.db.GetResourceCollection(collectionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "resource collection not found")
+		}
+		return err
+	}
+
+	limit := 1000
+	if limitStr := ctx.QueryParam("limit"); limitStr != "" {
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 {
+			return echo.NewHTTPError(http.StatusBadRequest, "limit must be a positive number")
+		}
+	}
+
+	res := inventoryApi.ListResourceCollectionMembershipChangesResponse{
+		Added:   []inventoryApi.ResourceCollectionMembershipChange{},
+		Removed: []inventoryApi.ResourceCollectionMembershipChange{},
+	}
+	var jobID uint
+	var since int64
+	if ctx.QueryParam("since") != "" {
+		sinceTime, err := utils.TimeFromQueryParam(ctx, "since", time.Now())
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "since must be a number")
+		}
+		since = sinceTime.UnixMilli()
+	} else {
+		if collection.MaterializedAt == nil {
+			return ctx.JSON(http.StatusOK, res)
+		}
+		jobID = collection.MembershipJobID
+	}
+
+	changes, err := es.ListResourceCollectionMembershipChanges(ctx.Request().Context(), h.client, collectionID, jobID, since, limit)
+	if err != nil {
+		h.logger.Error("failed to list resource collection membership changes", zap.Error(err))
+		return err
+	}
+	for _, change := range changes {
+		apiChange := inventoryApi.ResourceCollectionMembershipChange{
+			ResourceID:   change.ResourceID,
+			ResourceName: change.ResourceName,
+			ResourceType: change.ResourceType,
+			ConnectionID: change.ConnectionID,
+			Change:       inventoryApi.ResourceCollectionMembershipChangeType(change.Change),
+			JobID:        change.JobID,
+			ChangedAt:    time.UnixMilli(change.ChangedAt),
+		}
+		if apiChange.Change == inventoryApi.ResourceCollectionMembershipChangeAdded {
+			res.Added = append(res.Added, apiChange)
+		} else {
+			res.Removed = append(res.Removed, apiChange)
+		}
+	}
+	return ctx.JSON(http.StatusOK, res)
+}
+
+// UpdateResourceCollectionMembership godoc
+//
+//	@Summary		Update resource collection membership
+//	@Description	Storing the result of a membership materialization, called by the analytics worker.
+//	@Security		BearerToken
+//	@Tags			resource_collection
+//	@Accept			json
+//	@Param			resourceCollectionId	path	string													true	"Resource collection ID"
+//	@Param			request					body	inventoryApi.UpdateResourceCollectionMembershipRequest	true	"Membership"
+//	@Success		200
+//	@Router			/inventory/api/v2/resource-collection/{resourceCollectionId}/membership [put]
+func (h *HttpHandler) UpdateResourceCollectionMembership(ctx echo.Context) error {
+	collectionID := ctx.Param("resourceCollectionId")
+	var req inventoryApi.UpdateResourceCollectionMembershipRequest
+	if err := bindValidate(ctx, &req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	var filtersJson *pgtype.JSONB
+	if len(req.Filters) > 0 {
+		filtersBytes, err := json.Marshal(req.Filters)
+		if err != nil {
+			return err
+		}
+		filtersJson = &pgtype.JSONB{}
+		if err := filtersJson.Set(filtersBytes); err != nil {
+			return err
+		}
+	}
+
+	err := h.db.UpdateResourceCollectionMembership(collectionID, ResourceCollection{
+		MembershipJobID: req.JobID,
+		MaterializedAt:  req.MaterializedAt,
+		MemberCount:     req.MemberCount,
+		AddedCount:      req.AddedCount,
+		RemovedCount:    req.RemovedCount,
+	}, filtersJson)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "resource collection not found")
+		}
+		h.logger.Error("failed to update resource collection membership", zap.Error(err))
+		return err
+	}
+	return ctx.NoContent(http.StatusOK)
+}
+
 func (h *HttpHandler) connectionsFilter(filter map[string]interface{}) ([]string, error) {
 	var connections []string
 	allConnections, err := h.onboardClient.ListSources(&httpclient.Context{UserRole: api.InternalRole}, []source.Type{source.CloudAWS, source.CloudAzure})
@@ -3222,6 +3743,7 @@ func (h *HttpHandler) RunQueryByID(ctx echo.Context) error {
 	span.SetName("new_RunNamedQuery")
 
 	var query, engineStr string
+	var listOfTables []string
 	if strings.ToLower(req.Type) == "namedquery" || strings.ToLower(req.Type) == "named_query" {
 		namedQuery, err := h.db.GetQuery(req.ID)
 		if err != nil || namedQuery == nil {
@@ -3230,6 +3752,7 @@ func (h *HttpHandler) RunQueryByID(ctx echo.Context) error {
 		}
 		query = namedQuery.Query.QueryToExecute
 		engineStr = namedQuery.Query.Engine
+		listOfTables = namedQuery.Query.ListOfTables
 	} else if strings.ToLower(req.Type) == "control" {
 		control, err := h.complianceClient.GetControl(&httpclient.Context{UserRole: api.InternalRole}, req.ID)
 		if err != nil || control == nil {
@@ -3241,6 +3764,7 @@ func (h *HttpHandler) RunQueryByID(ctx echo.Context) error {
 		}
 		query = control.Query.QueryToExecute
 		engineStr = control.Query.Engine
+		listOfTables = control.Query.ListOfTables
 	} else {
 		return echo.NewHTTPError(http.StatusBadRequest, "Runnable Type is not valid. Options: named_query, control")
 	}
@@ -3262,16 +3786,40 @@ func (h *HttpHandler) RunQueryByID(ctx echo.Context) error {
 		return fmt.Errorf("failed to execute query template: %w", err)
 	}
 
-	var resp *inventoryApi.RunQueryResponse
-	if engine == inventoryApi.QueryEngine_OdysseusSQL {
-		resp, err = h.RunSQLNamedQuery(newCtx, query, queryOutput.String(), &inventoryApi.RunQueryRequest{
-			Page:   req.Page,
-			Query:  &query,
-			Engine: &engine,
-			Sorts:  req.Sorts,
-		})
+	cacheKey, err := queryResultCacheKey(req, query, engine)
+	if err != nil {
+		return err
+	}
+	// scoped requests see a subset of the results, they are neither served from nor stored in the cache
+	scoped := len(utils.GetScopedConnectionIDs(ctx)) > 0
+	if readsQueryResultCache(req, scoped) {
+		cached, err := h.getCachedQueryResult(cacheKey)
 		if err != nil {
-			span.RecordError(err)
+			h.logger.Error("failed to get cached query result", zap.Error(err), zap.String("id", req.ID))
+		} else if cached != nil {
+			span.End()
+			return ctx.JSON(200, cached)
+		}
+	}
+
+	if scoped && engine == inventoryApi.QueryEngine_OdysseusRego {
+		return echo.NewHTTPError(http.StatusForbidden, "rego queries are not available to scoped users")
+	}
+	scopedQuery, err := h.scopeQuery(ctx, queryOutput.String())
+	if err != nil {
+		return err
+	}
+
+	var resp *inventoryApi.RunQueryResponse
+	if engine == inventoryApi.QueryEngine_OdysseusSQL {
+		resp, err = h.RunSQLNamedQuery(newCtx, query, queryOutput.String(), scopedQuery, &inventoryApi.RunQueryRequest{
+			Page:   req.Page,
+			Query:  &query,
+			Engine: &engine,
+			Sorts:  req.Sorts,
+		})
+		if err != nil {
+			span.RecordError(err)
 			span.SetStatus(codes.Error, err.Error())
 			return err
 		}
@@ -3288,7 +3836,7 @@ func (h *HttpHandler) RunQueryByID(ctx echo.Context) error {
 			return err
 		}
 	} else {
-		resp, err = h.RunSQLNamedQuery(newCtx, query, queryOutput.String(), &inventoryApi.RunQueryRequest{
+		resp, err = h.RunSQLNamedQuery(newCtx, query, queryOutput.String(), scopedQuery, &inventoryApi.RunQueryRequest{
 			Page:   req.Page,
 			Query:  &query,
 			Engine: &engine,
@@ -3315,10 +3863,157 @@ func (h *HttpHandler) RunQueryByID(ctx echo.Context) error {
 		msg := fmt.Sprintf("Query execution timed out, created an async query run instead: jobid = %v", job.ID)
 		return echo.NewHTTPError(http.StatusRequestTimeout, msg)
 	default:
+		if scoped {
+			return ctx.JSON(200, resp)
+		}
+		if err := h.cacheQueryResult(cacheKey, req, listOfTables, resp); err != nil {
+			h.logger.Error("failed to cache query result", zap.Error(err), zap.String("id", req.ID))
+		}
 		return ctx.JSON(200, resp)
 	}
 }
 
+func getResourceTypeFromTableName(tableName string) string {
+	resourceType := awsSteampipe.ExtractResourceType(tableName)
+	if resourceType == "" {
+		resourceType = azureSteampipe.ExtractResourceType(tableName)
+	}
+	return resourceType
+}
+
+// queryResultCacheKey identifies a query run by the query text, parameters, page, sort and engine.
+func queryResultCacheKey(req inventoryApi.RunQueryByIDRequest, query string, engine inventoryApi.QueryEngine) (string, error) {
+	keyJson, err := json.Marshal(struct {
+		Query       string
+		Engine      inventoryApi.QueryEngine
+		QueryParams map[string]string
+		Page        inventoryApi.Page
+		Sorts       []inventoryApi.NamedQuerySortItem
+	}{
+		Query:       query,
+		Engine:      engine,
+		QueryParams: req.QueryParams,
+		Page:        req.Page,
+		Sorts:       req.Sorts,
+	})
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256(keyJson)
+	return hex.EncodeToString(hash[:]), nil
+}
+
+// readsQueryResultCache reports whether a query run may be served from the cache, the result is stored either way
+// unless the request is scoped.
+func readsQueryResultCache(req inventoryApi.RunQueryByIDRequest, scoped bool) bool {
+	return !req.BypassCache && !scoped
+}
+
+func (h *HttpHandler) getCachedQueryResult(key string) (*inventoryApi.RunQueryResponse, error) {
+	entry, err := h.db.GetQueryResultCache(key)
+	if err != nil || entry == nil {
+		return nil, err
+	}
+	return cachedQueryResult(*entry, time.Now())
+}
+
+// cachedQueryResult returns the result of the cache entry, nil if it has expired.
+func cachedQueryResult(entry QueryResultCache, now time.Time) (*inventoryApi.RunQueryResponse, error) {
+	age := now.Sub(entry.CreatedAt)
+	if age > queryResultCacheTTL {
+		return nil, nil
+	}
+
+	var resp inventoryApi.RunQueryResponse
+	if err := json.Unmarshal(entry.Result.Bytes, &resp); err != nil {
+		return nil, err
+	}
+	resp.Cache = &inventoryApi.QueryResultCacheInfo{
+		Hit:        true,
+		CachedAt:   entry.CreatedAt,
+		AgeSeconds: int64(age.Seconds()),
+	}
+	return &resp, nil
+}
+
+// cacheQueryResult stores the result of a query run. Queries without a known list of tables are not cached
+// since there is no way to tell which discoveries make them stale.
+func (h *HttpHandler) cacheQueryResult(key string, req inventoryApi.RunQueryByIDRequest, listOfTables []string, resp *inventoryApi.RunQueryResponse) error {
+	entry, err := newQueryResultCache(key, req, listOfTables, resp, time.Now())
+	if err != nil || entry == nil {
+		return err
+	}
+	return h.db.UpsertQueryResultCache(*entry)
+}
+
+func newQueryResultCache(key string, req inventoryApi.RunQueryByIDRequest, listOfTables []string, resp *inventoryApi.RunQueryResponse, now time.Time) (*QueryResultCache, error) {
+	if len(listOfTables) == 0 {
+		return nil, nil
+	}
+
+	entry := QueryResultCache{
+		Key:          key,
+		RunnableType: strings.ToLower(req.Type),
+		RunnableID:   req.ID,
+		Tables:       listOfTables,
+		CreatedAt:    now,
+	}
+	resourceTypes := make(map[string]bool)
+	for _, table := range listOfTables {
+		resourceType := strings.ToLower(getResourceTypeFromTableName(table))
+		if resourceType == "" {
+			// e.g. kaytu_resources and kaytu_lookup read from all resource types
+			entry.AnyResourceType = true
+			continue
+		}
+		resourceTypes[resourceType] = true
+	}
+	for resourceType := range resourceTypes {
+		entry.ResourceTypes = append(entry.ResourceTypes, resourceType)
+	}
+
+	resp.Cache = &inventoryApi.QueryResultCacheInfo{
+		Hit:      false,
+		CachedAt: entry.CreatedAt,
+	}
+	resultJson, err := json.Marshal(resp)
+	if err != nil {
+		return nil, err
+	}
+	if err := entry.Result.Set(resultJson); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// InvalidateQueryCache godoc
+//
+//	@Summary		Invalidate query result cache
+//	@Description	Removing the cached results of the queries reading from the given resource types. Called when their discovery completes.
+//	@Security		BearerToken
+//	@Tags			named_query
+//	@Accepts		json
+//	@Produce		json
+//	@Param			request	body		inventoryApi.InvalidateQueryCacheRequest	true	"Request Body"
+//	@Success		200		{object}	inventoryApi.InvalidateQueryCacheResponse
+//	@Router			/inventory/api/v3/query/cache/invalidate [post]
+func (h *HttpHandler) InvalidateQueryCache(ctx echo.Context) error {
+	var req inventoryApi.InvalidateQueryCacheRequest
+	if err := bindValidate(ctx, &req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if len(req.ResourceTypes) == 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "resource_types is required")
+	}
+
+	count, err := h.db.InvalidateQueryResultCache(req.ResourceTypes)
+	if err != nil {
+		h.logger.Error("failed to invalidate query result cache", zap.Error(err))
+		return err
+	}
+	return ctx.JSON(http.StatusOK, inventoryApi.InvalidateQueryCacheResponse{InvalidatedCount: count})
+}
+
 // ListQueriesFilters godoc
 //
 //	@Summary	List possible values for each filter in List Controls
@@ -3701,3 +4396,1112 @@ func (h *HttpHandler) GetParametersQueries(ctx echo.Context) error {
 		ParametersQueries: parametersQueries,
 	})
 }
+
+// resourceEdgeFetcher fetches the edges of the resources, only following the edges between the scoped member
+// resources if scopedResourceIDs is not nil.
+func (h *HttpHandler) resourceEdgeFetcher(ctx context.Context, relations []string, connectionIDs []string, scopedResourceIDs []string) graph.EdgeFetcher {
+	var members map[string]bool
+	if scopedResourceIDs != nil {
+		members = make(map[string]bool, len(scopedResourceIDs))
+		for _, resourceID := range scopedResourceIDs {
+			members[resourceID] = true
+		}
+	}
+	return func(resourceIDs []string, direction graph.Direction) ([]types.ResourceEdge, error) {
+		edges, err := es.FetchResourceEdges(ctx, h.client, resourceIDs, direction, relations, connectionIDs)
+		if err != nil || members == nil {
+			return edges, err
+		}
+		scoped := make([]types.ResourceEdge, 0, len(edges))
+		for _, edge := range edges {
+			if members[edge.FromResourceID] && members[edge.ToResourceID] {
+				scoped = append(scoped, edge)
+			}
+		}
+		return scoped, nil
+	}
+}
+
+func (h *HttpHandler) resourceGraphToApi(ctx context.Context, edges []types.ResourceEdge, depth map[string]int) ([]inventoryApi.ResourceGraphNode, []inventoryApi.ResourceGraphEdge, error) {
+	resourceIDs := make([]string, 0, len(depth))
+	for resourceID := range depth {
+		resourceIDs = append(resourceIDs, resourceID)
+	}
+	lookups, err := es.FetchLookupResourcesByIDs(ctx, h.client, resourceIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resourceTypes := make(map[string]string)
+	apiEdges := make([]inventoryApi.ResourceGraphEdge, 0, len(edges))
+	for _, edge := range edges {
+		resourceTypes[edge.FromResourceID] = edge.FromResourceType
+		resourceTypes[edge.ToResourceID] = edge.ToResourceType
+		apiEdges = append(apiEdges, inventoryApi.ResourceGraphEdge{
+			FromResourceID:   edge.FromResourceID,
+			FromResourceType: edge.FromResourceType,
+			ToResourceID:     edge.ToResourceID,
+			ToResourceType:   edge.ToResourceType,
+			Relation:         string(edge.Relation),
+			ConnectionID:     edge.SourceID,
+		})
+	}
+
+	nodes := make([]inventoryApi.ResourceGraphNode, 0, len(depth))
+	for _, resourceID := range resourceIDs {
+		node := inventoryApi.ResourceGraphNode{
+			ResourceID:   resourceID,
+			ResourceType: resourceTypes[resourceID],
+			Distance:     depth[resourceID],
+		}
+		if lookup, ok := lookups[resourceID]; ok {
+			node.ResourceType = strings.ToLower(lookup.ResourceType)
+			node.Name = lookup.Name
+			node.ConnectionID = lookup.SourceID
+			node.Connector = lookup.SourceType
+			node.Location = lookup.Location
+			node.Discovered = true
+		}
+		nodes = append(nodes, node)
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		if nodes[i].Distance != nodes[j].Distance {
+			return nodes[i].Distance < nodes[j].Distance
+		}
+		return nodes[i].ResourceID < nodes[j].ResourceID
+	})
+
+	return nodes, apiEdges, nil
+}
+
+func (h *HttpHandler) traverseResourceGraph(ctx echo.Context, hops int) error {
+	resourceID := ctx.QueryParam("resourceId")
+	if resourceID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "resourceId is required")
+	}
+	direction, err := graph.ParseDirection(ctx.QueryParam("direction"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	connectionIDs, err := h.getConnectionIdFilterFromParams(ctx)
+	if err != nil {
+		return err
+	}
+	relations := httpserver.QueryArrayParam(ctx, "relation")
+	scopedResourceIDs, err := h.scopedResourceIDs(ctx)
+	if err != nil {
+		return err
+	}
+	if err := utils.CheckAccessToResourceID(scopedResourceIDs, resourceID); err != nil {
+		return err
+	}
+
+	fetch := h.resourceEdgeFetcher(ctx.Request().Context(), relations, connectionIDs, scopedResourceIDs)
+	edges, depth, err := graph.Traverse(fetch, resourceID, hops, direction)
+	if err != nil {
+		h.logger.Error("failed to traverse resource graph", zap.Error(err), zap.String("resourceId", resourceID))
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to traverse resource graph")
+	}
+
+	nodes, apiEdges, err := h.resourceGraphToApi(ctx.Request().Context(), edges, depth)
+	if err != nil {
+		h.logger.Error("failed to fetch resource graph nodes", zap.Error(err))
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to fetch resource graph nodes")
+	}
+
+	return ctx.JSON(http.StatusOK, inventoryApi.ResourceGraphResponse{
+		Nodes: nodes,
+		Edges: apiEdges,
+	})
+}
+
+// GetResourceNeighbors godoc
+//
+//	@Summary		Get resource neighbors
+//	@Description	Retrieving the resources directly related to a resource, e.g. the subnet, security groups and role of an instance.
+//	@Security		BearerToken
+//	@Tags			resource_graph
+//	@Produce		json
+//	@Param			resourceId		query		string		true	"Resource ID"
+//	@Param			direction		query		string		false	"Edge direction, default: both"	Enums(outgoing, incoming, both)
+//	@Param			relation		query		[]string	false	"Relations to follow"	Enums(contains, attached-to, uses-role, in-network, routes-to)
+//	@Param			connectionId	query		[]string	false	"Connection IDs to filter by - mutually exclusive with connectionGroup"
+//	@Param			connectionGroup	query		[]string	false	"Connection group to filter by - mutually exclusive with connectionId"
+//	@Success		200				{object}	inventoryApi.ResourceGraphResponse
+//	@Router			/inventory/api/v2/resources/graph/neighbors [get]
+func (h *HttpHandler) GetResourceNeighbors(ctx echo.Context) error {
+	return h.traverseResourceGraph(ctx, 1)
+}
+
+// TraverseResourceGraph godoc
+//
+//	@Summary		Traverse resource graph
+//	@Description	Retrieving the resources at most N hops away from a resource, together with the edges walked to reach them.
+//	@Security		BearerToken
+//	@Tags			resource_graph
+//	@Produce		json
+//	@Param			resourceId		query		string		true	"Resource ID"
+//	@Param			hops			query		int			false	"Maximum number of hops, default: 2, max: 6"
+//	@Param			direction		query		string		false	"Edge direction, default: both"	Enums(outgoing, incoming, both)
+//	@Param			relation		query		[]string	false	"Relations to follow"	Enums(contains, attached-to, uses-role, in-network, routes-to)
+//	@Param			connectionId	query		[]string	false	"Connection IDs to filter by - mutually exclusive with connectionGroup"
+//	@Param			connectionGroup	query		[]string	false	"Connection group to filter by - mutually exclusive with connectionId"
+//	@Success		200				{object}	inventoryApi.ResourceGraphResponse
+//	@Router			/inventory/api/v2/resources/graph/traverse [get]
+func (h *HttpHandler) TraverseResourceGraph(ctx echo.Context) error {
+	hops := 2
+	if hopsStr := ctx.QueryParam("hops"); hopsStr != "" {
+		hopsVal, err := strconv.Atoi(hopsStr)
+		if err != nil || hopsVal < 1 || hopsVal > graph.MaxHops {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("hops must be a number between 1 and %d", graph.MaxHops))
+		}
+		hops = hopsVal
+	}
+	return h.traverseResourceGraph(ctx, hops)
+}
+
+// GetResourceGraphPath godoc
+//
+//	@Summary		Get path between resources
+//	@Description	Retrieving a shortest path between two resources, e.g. from a public load balancer to an IAM role.
+//	@Security		BearerToken
+//	@Tags			resource_graph
+//	@Produce		json
+//	@Param			from			query		string		true	"Source resource ID"
+//	@Param			to				query		string		true	"Destination resource ID"
+//	@Param			maxHops			query		int			false	"Maximum path length, default: 4, max: 6"
+//	@Param			direction		query		string		false	"Edge direction, default: outgoing"	Enums(outgoing, incoming, both)
+//	@Param			relation		query		[]string	false	"Relations to follow"	Enums(contains, attached-to, uses-role, in-network, routes-to)
+//	@Param			connectionId	query		[]string	false	"Connection IDs to filter by - mutually exclusive with connectionGroup"
+//	@Param			connectionGroup	query		[]string	false	"Connection group to filter by - mutually exclusive with connectionId"
+//	@Success		200				{object}	inventoryApi.ResourceGraphPathResponse
+//	@Router			/inventory/api/v2/resources/graph/path [get]
+func (h *HttpHandler) GetResourceGraphPath(ctx echo.Context) error {
+	from, to := ctx.QueryParam("from"), ctx.QueryParam("to")
+	if from == "" || to == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "from and to are required")
+	}
+	maxHops := 4
+	if maxHopsStr := ctx.QueryParam("maxHops"); maxHopsStr != "" {
+		maxHopsVal, err := strconv.Atoi(maxHopsStr)
+		if err != nil || maxHopsVal < 1 || maxHopsVal > graph.MaxHops {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("maxHops must be a number between 1 and %d", graph.MaxHops))
+		}
+		maxHops = maxHopsVal
+	}
+	direction := graph.DirectionOutgoing
+	if directionStr := ctx.QueryParam("direction"); directionStr != "" {
+		var err error
+		direction, err = graph.ParseDirection(directionStr)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+	}
+	connectionIDs, err := h.getConnectionIdFilterFromParams(ctx)
+	if err != nil {
+		return err
+	}
+	relations := httpserver.QueryArrayParam(ctx, "relation")
+	scopedResourceIDs, err := h.scopedResourceIDs(ctx)
+	if err != nil {
+		return err
+	}
+	for _, resourceID := range []string{from, to} {
+		if err := utils.CheckAccessToResourceID(scopedResourceIDs, resourceID); err != nil {
+			return err
+		}
+	}
+
+	fetch := h.resourceEdgeFetcher(ctx.Request().Context(), relations, connectionIDs, scopedResourceIDs)
+	path, err := graph.ShortestPath(fetch, from, to, maxHops, direction)
+	if err != nil {
+		h.logger.Error("failed to find resource graph path", zap.Error(err), zap.String("from", from), zap.String("to", to))
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to find resource graph path")
+	}
+	if path == nil {
+		return ctx.JSON(http.StatusOK, inventoryApi.ResourceGraphPathResponse{
+			Found: false,
+			Nodes: []inventoryApi.ResourceGraphNode{},
+			Edges: []inventoryApi.ResourceGraphEdge{},
+		})
+	}
+
+	depth := map[string]int{from: 0}
+	for i, edge := range path {
+		if _, ok := depth[edge.FromResourceID]; !ok {
+			depth[edge.FromResourceID] = i + 1
+		}
+		if _, ok := depth[edge.ToResourceID]; !ok {
+			depth[edge.ToResourceID] = i + 1
+		}
+	}
+	nodes, apiEdges, err := h.resourceGraphToApi(ctx.Request().Context(), path, depth)
+	if err != nil {
+		h.logger.Error("failed to fetch resource graph nodes", zap.Error(err))
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to fetch resource graph nodes")
+	}
+
+	return ctx.JSON(http.StatusOK, inventoryApi.ResourceGraphPathResponse{
+		Found: true,
+		Nodes: nodes,
+		Edges: apiEdges,
+	})
+}
+
+func tagPolicyToApi(p analyticsDB.TagPolicy) inventoryApi.TagPolicy {
+	res := inventoryApi.TagPolicy{
+		ID:            p.ID,
+		Title:         p.Title,
+		Description:   p.Description,
+		Enabled:       p.Enabled,
+		Connectors:    source.ParseTypes(p.Connectors),
+		ConnectionIDs: p.ConnectionIDs,
+		ResourceTypes: p.ResourceTypes,
+		OwnerTagKey:   p.OwnerTagKey,
+		Keys:          make([]inventoryApi.TagPolicyKey, 0, len(p.Keys)),
+		CreatedAt:     p.CreatedAt,
+		UpdatedAt:     p.UpdatedAt,
+	}
+	for _, k := range p.Keys {
+		res.Keys = append(res.Keys, inventoryApi.TagPolicyKey{
+			Key:           k.Key,
+			Required:      k.Required,
+			AllowedValues: k.AllowedValues,
+			ValueRegex:    k.ValueRegex,
+			ValueCase:     string(k.ValueCase),
+		})
+	}
+	return res
+}
+
+func tagPolicyFromRequest(id string, req inventoryApi.TagPolicyRequest) (analyticsDB.TagPolicy, error) {
+	if len(req.Keys) == 0 {
+		return analyticsDB.TagPolicy{}, errors.New("at least one key is required")
+	}
+
+	policy := analyticsDB.TagPolicy{
+		ID:            id,
+		Title:         req.Title,
+		Description:   req.Description,
+		Enabled:       req.Enabled,
+		ConnectionIDs: req.ConnectionIDs,
+		ResourceTypes: utils.ToLowerStringSlice(req.ResourceTypes),
+		OwnerTagKey:   req.OwnerTagKey,
+	}
+	for _, c := range req.Connectors {
+		policy.Connectors = append(policy.Connectors, c.String())
+	}
+
+	seen := make(map[string]bool)
+	for _, k := range req.Keys {
+		if k.Key == "" {
+			return analyticsDB.TagPolicy{}, errors.New("key must not be empty")
+		}
+		if seen[k.Key] {
+			return analyticsDB.TagPolicy{}, fmt.Errorf("duplicate key %s", k.Key)
+		}
+		seen[k.Key] = true
+
+		if k.ValueRegex != "" {
+			if _, err := regexp.Compile(k.ValueRegex); err != nil {
+				return analyticsDB.TagPolicy{}, fmt.Errorf("invalid value regex for key %s", k.Key)
+			}
+		}
+		valueCase := analyticsDB.TagCaseRule(strings.ToLower(k.ValueCase))
+		switch valueCase {
+		case analyticsDB.TagCaseRuleAny, analyticsDB.TagCaseRuleLower, analyticsDB.TagCaseRuleUpper:
+		default:
+			return analyticsDB.TagPolicy{}, fmt.Errorf("invalid value case %s for key %s", k.ValueCase, k.Key)
+		}
+
+		policy.Keys = append(policy.Keys, analyticsDB.TagPolicyKey{
+			TagPolicyID:   id,
+			Key:           k.Key,
+			Required:      k.Required,
+			AllowedValues: k.AllowedValues,
+			ValueRegex:    k.ValueRegex,
+			ValueCase:     valueCase,
+		})
+	}
+	return policy, nil
+}
+
+// ListTagPolicies godoc
+//
+//	@Summary		List tag policies
+//	@Description	Retrieving the list of tag policies.
+//	@Security		BearerToken
+//	@Tags			tag_policy
+//	@Produce		json
+//	@Success		200	{object}	[]inventoryApi.TagPolicy
+//	@Router			/inventory/api/v2/tag-policies [get]
+func (h *HttpHandler) ListTagPolicies(ctx echo.Context) error {
+	aDB := analyticsDB.NewDatabase(h.db.orm)
+	policies, err := aDB.ListTagPolicies(false)
+	if err != nil {
+		h.logger.Error("failed to list tag policies", zap.Error(err))
+		return err
+	}
+
+	res := make([]inventoryApi.TagPolicy, 0, len(policies))
+	for _, p := range policies {
+		res = append(res, tagPolicyToApi(p))
+	}
+	return ctx.JSON(http.StatusOK, res)
+}
+
+// GetTagPolicy godoc
+//
+//	@Summary		Get tag policy
+//	@Description	Retrieving a tag policy by id.
+//	@Security		BearerToken
+//	@Tags			tag_policy
+//	@Produce		json
+//	@Param			policyId	path		string	true	"Tag policy ID"
+//	@Success		200			{object}	inventoryApi.TagPolicy
+//	@Router			/inventory/api/v2/tag-policies/{policyId} [get]
+func (h *HttpHandler) GetTagPolicy(ctx echo.Context) error {
+	aDB := analyticsDB.NewDatabase(h.db.orm)
+	policy, err := aDB.GetTagPolicy(ctx.Param("policyId"))
+	if err != nil {
+		h.logger.Error("failed to get tag policy", zap.Error(err))
+		return err
+	}
+	if policy == nil {
+		return echo.NewHTTPError(http.StatusNotFound, "tag policy not found")
+	}
+	return ctx.JSON(http.StatusOK, tagPolicyToApi(*policy))
+}
+
+// CreateTagPolicy godoc
+//
+//	@Summary		Create tag policy
+//	@Description	Creating a tag policy. It is evaluated against all resources after the next discovery.
+//	@Security		BearerToken
+//	@Tags			tag_policy
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		inventoryApi.TagPolicyRequest	true	"Tag policy"
+//	@Success		200		{object}	inventoryApi.TagPolicy
+//	@Router			/inventory/api/v2/tag-policies [post]
+func (h *HttpHandler) CreateTagPolicy(ctx echo.Context) error {
+	var req inventoryApi.TagPolicyRequest
+	if err := bindValidate(ctx, &req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	policy, err := tagPolicyFromRequest(uuid.New().String(), req)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	aDB := analyticsDB.NewDatabase(h.db.orm)
+	if err := aDB.UpsertTagPolicy(policy); err != nil {
+		h.logger.Error("failed to create tag policy", zap.Error(err))
+		return err
+	}
+
+	created, err := aDB.GetTagPolicy(policy.ID)
+	if err != nil || created == nil {
+		h.logger.Error("failed to get created tag policy", zap.Error(err))
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to get created tag policy")
+	}
+	return ctx.JSON(http.StatusOK, tagPolicyToApi(*created))
+}
+
+// UpdateTagPolicy godoc
+//
+//	@Summary		Update tag policy
+//	@Description	Replacing the rules and scope of a tag policy.
+//	@Security		BearerToken
+//	@Tags			tag_policy
+//	@Accept			json
+//	@Produce		json
+//	@Param			policyId	path		string							true	"Tag policy ID"
+//	@Param			request		body		inventoryApi.TagPolicyRequest	true	"Tag policy"
+//	@Success		200			{object}	inventoryApi.TagPolicy
+//	@Router			/inventory/api/v2/tag-policies/{policyId} [put]
+func (h *HttpHandler) UpdateTagPolicy(ctx echo.Context) error {
+	var req inventoryApi.TagPolicyRequest
+	if err := bindValidate(ctx, &req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	aDB := analyticsDB.NewDatabase(h.db.orm)
+	existing, err := aDB.GetTagPolicy(ctx.Param("policyId"))
+	if err != nil {
+		h.logger.Error("failed to get tag policy", zap.Error(err))
+		return err
+	}
+	if existing == nil {
+		return echo.NewHTTPError(http.StatusNotFound, "tag policy not found")
+	}
+
+	policy, err := tagPolicyFromRequest(existing.ID, req)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	policy.CreatedAt = existing.CreatedAt
+
+	if err := aDB.UpsertTagPolicy(policy); err != nil {
+		h.logger.Error("failed to update tag policy", zap.Error(err))
+		return err
+	}
+
+	updated, err := aDB.GetTagPolicy(policy.ID)
+	if err != nil || updated == nil {
+		h.logger.Error("failed to get updated tag policy", zap.Error(err))
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to get updated tag policy")
+	}
+	return ctx.JSON(http.StatusOK, tagPolicyToApi(*updated))
+}
+
+// DeleteTagPolicy godoc
+//
+//	@Summary		Delete tag policy
+//	@Description	Deleting a tag policy and its evaluation results.
+//	@Security		BearerToken
+//	@Tags			tag_policy
+//	@Param			policyId	path	string	true	"Tag policy ID"
+//	@Success		200
+//	@Router			/inventory/api/v2/tag-policies/{policyId} [delete]
+func (h *HttpHandler) DeleteTagPolicy(ctx echo.Context) error {
+	policyID := ctx.Param("policyId")
+
+	aDB := analyticsDB.NewDatabase(h.db.orm)
+	policy, err := aDB.GetTagPolicy(policyID)
+	if err != nil {
+		h.logger.Error("failed to get tag policy", zap.Error(err))
+		return err
+	}
+	if policy == nil {
+		return echo.NewHTTPError(http.StatusNotFound, "tag policy not found")
+	}
+
+	if err := aDB.DeleteTagPolicy(policyID); err != nil {
+		h.logger.Error("failed to delete tag policy", zap.Error(err))
+		return err
+	}
+	if err := es.DeleteTagPolicyResults(ctx.Request().Context(), h.client, policyID); err != nil {
+		h.logger.Error("failed to delete tag policy results", zap.Error(err), zap.String("policyID", policyID))
+	}
+	return ctx.NoContent(http.StatusOK)
+}
+
+// GetTagPolicyCoverage godoc
+//
+//	@Summary		Get tag policy coverage
+//	@Description	Retrieving the percentage of resources compliant with the tag policies, grouped by connection, resource type or owner.
+//	@Security		BearerToken
+//	@Tags			tag_policy
+//	@Produce		json
+//	@Param			policyId		query		[]string	false	"Tag policy IDs, default: all"
+//	@Param			groupBy			query		string		false	"Grouping, default: connection"	Enums(connection, resource_type, owner)
+//	@Param			connectionId	query		[]string	false	"Connection IDs to filter by - mutually exclusive with connectionGroup"
+//	@Param			connectionGroup	query		[]string	false	"Connection group to filter by - mutually exclusive with connectionId"
+//	@Success		200				{object}	inventoryApi.TagPolicyCoverageResponse
+//	@Router			/inventory/api/v2/tag-policies/coverage [get]
+func (h *HttpHandler) GetTagPolicyCoverage(ctx echo.Context) error {
+	policyIDs := httpserver.QueryArrayParam(ctx, "policyId")
+	connectionIDs, err := h.getConnectionIdFilterFromParams(ctx)
+	if err != nil {
+		return err
+	}
+	resourceIDs, err := h.scopedResourceIDs(ctx)
+	if err != nil {
+		return err
+	}
+
+	groupBy := ctx.QueryParam("groupBy")
+	if groupBy == "" {
+		groupBy = "connection"
+	}
+	var field string
+	switch groupBy {
+	case "connection":
+		field = "connection_id"
+	case "resource_type":
+		field = "resource_type"
+	case "owner":
+		field = "owner"
+	default:
+		return echo.NewHTTPError(http.StatusBadRequest, "groupBy must be one of connection, resource_type, owner")
+	}
+
+	coverage, err := es.GetTagPolicyCoverage(ctx.Request().Context(), h.client, policyIDs, connectionIDs, resourceIDs, field, EsFetchPageSize)
+	if err != nil {
+		h.logger.Error("failed to get tag policy coverage", zap.Error(err))
+		return err
+	}
+
+	coveragePercent := func(compliant, total int) float64 {
+		if total == 0 {
+			return 100
+		}
+		return math.Round(float64(compliant)/float64(total)*10000) / 100
+	}
+
+	res := inventoryApi.TagPolicyCoverageResponse{
+		GroupBy: groupBy,
+		Items:   make([]inventoryApi.TagPolicyCoverageItem, 0, len(coverage)),
+	}
+	for key, c := range coverage {
+		res.TotalCount += c.Total
+		res.CompliantCount += c.Compliant
+		res.Items = append(res.Items, inventoryApi.TagPolicyCoverageItem{
+			Key:               key,
+			TotalCount:        c.Total,
+			CompliantCount:    c.Compliant,
+			NonCompliantCount: c.Total - c.Compliant,
+			CoveragePercent:   coveragePercent(c.Compliant, c.Total),
+		})
+	}
+	res.CoveragePercent = coveragePercent(res.CompliantCount, res.TotalCount)
+	sort.Slice(res.Items, func(i, j int) bool {
+		if res.Items[i].CoveragePercent != res.Items[j].CoveragePercent {
+			return res.Items[i].CoveragePercent < res.Items[j].CoveragePercent
+		}
+		return res.Items[i].Key < res.Items[j].Key
+	})
+	return ctx.JSON(http.StatusOK, res)
+}
+
+// ListTagPolicyNonCompliantResources godoc
+//
+//	@Summary		List non-compliant resources
+//	@Description	Retrieving the resources violating the tag policies with the reasons and suggested fixes.
+//	@Security		BearerToken
+//	@Tags			tag_policy
+//	@Produce		json
+//	@Param			policyId		query		[]string	false	"Tag policy IDs, default: all"
+//	@Param			resourceType	query		[]string	false	"Resource types to filter by"
+//	@Param			owner			query		[]string	false	"Owners to filter by"
+//	@Param			connectionId	query		[]string	false	"Connection IDs to filter by - mutually exclusive with connectionGroup"
+//	@Param			connectionGroup	query		[]string	false	"Connection group to filter by - mutually exclusive with connectionId"
+//	@Param			pageSize		query		int			false	"page size - default is 20"
+//	@Param			pageNumber		query		int			false	"page number - default is 1"
+//	@Success		200				{object}	inventoryApi.ListTagPolicyNonCompliantResourcesResponse
+//	@Router			/inventory/api/v2/tag-policies/non-compliant [get]
+func (h *HttpHandler) ListTagPolicyNonCompliantResources(ctx echo.Context) error {
+	policyIDs := httpserver.QueryArrayParam(ctx, "policyId")
+	resourceTypes := utils.ToLowerStringSlice(httpserver.QueryArrayParam(ctx, "resourceType"))
+	owners := httpserver.QueryArrayParam(ctx, "owner")
+	connectionIDs, err := h.getConnectionIdFilterFromParams(ctx)
+	if err != nil {
+		return err
+	}
+	resourceIDs, err := h.scopedResourceIDs(ctx)
+	if err != nil {
+		return err
+	}
+	pageNumber, pageSize, err := utils.PageConfigFromStrings(ctx.QueryParam("pageNumber"), ctx.QueryParam("pageSize"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if pageNumber < 1 || pageSize < 1 || pageNumber*pageSize > EsFetchPageSize {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("pageNumber and pageSize must be positive and cover at most %d results", EsFetchPageSize))
+	}
+
+	results, total, err := es.ListNonCompliantTagPolicyResults(ctx.Request().Context(), h.client, policyIDs, connectionIDs, resourceIDs, resourceTypes, owners,
+		int((pageNumber-1)*pageSize), int(pageSize))
+	if err != nil {
+		h.logger.Error("failed to list non-compliant tag policy results", zap.Error(err))
+		return err
+	}
+
+	res := inventoryApi.ListTagPolicyNonCompliantResourcesResponse{
+		TotalCount: total,
+		Resources:  make([]inventoryApi.TagPolicyNonCompliantResource, 0, len(results)),
+	}
+	for _, r := range results {
+		res.Resources = append(res.Resources, inventoryApi.TagPolicyNonCompliantResource{
+			PolicyID:      r.PolicyID,
+			ResourceID:    r.ResourceID,
+			ResourceName:  r.ResourceName,
+			ResourceType:  r.ResourceType,
+			ConnectionID:  r.ConnectionID,
+			Connector:     r.Connector,
+			Location:      r.Location,
+			Owner:         r.Owner,
+			Violations:    r.Violations,
+			ViolatingKeys: r.ViolatingKeys,
+			Suggestions:   r.Suggestions,
+			EvaluatedAt:   time.UnixMilli(r.EvaluatedAt),
+		})
+	}
+	return ctx.JSON(http.StatusOK, res)
+}
+
+// ListTagPolicySuggestions godoc
+//
+//	@Summary		List tag normalization suggestions
+//	@Description	Retrieving the suggested tag fixes, e.g. renaming Env to env, and the number of resources each one applies to.
+//	@Security		BearerToken
+//	@Tags			tag_policy
+//	@Produce		json
+//	@Param			policyId		query		[]string	false	"Tag policy IDs, default: all"
+//	@Param			connectionId	query		[]string	false	"Connection IDs to filter by - mutually exclusive with connectionGroup"
+//	@Param			connectionGroup	query		[]string	false	"Connection group to filter by - mutually exclusive with connectionId"
+//	@Success		200				{object}	[]inventoryApi.TagPolicySuggestion
+//	@Router			/inventory/api/v2/tag-policies/suggestions [get]
+func (h *HttpHandler) ListTagPolicySuggestions(ctx echo.Context) error {
+	policyIDs := httpserver.QueryArrayParam(ctx, "policyId")
+	connectionIDs, err := h.getConnectionIdFilterFromParams(ctx)
+	if err != nil {
+		return err
+	}
+	resourceIDs, err := h.scopedResourceIDs(ctx)
+	if err != nil {
+		return err
+	}
+
+	suggestions, err := es.GetTagPolicySuggestions(ctx.Request().Context(), h.client, policyIDs, connectionIDs, resourceIDs, EsFetchPageSize)
+	if err != nil {
+		h.logger.Error("failed to get tag policy suggestions", zap.Error(err))
+		return err
+	}
+
+	res := make([]inventoryApi.TagPolicySuggestion, 0, len(suggestions))
+	for s, count := range suggestions {
+		res = append(res, inventoryApi.TagPolicySuggestion{
+			Suggestion:    s,
+			ResourceCount: count,
+		})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].ResourceCount != res[j].ResourceCount {
+			return res[i].ResourceCount > res[j].ResourceCount
+		}
+		return res[i].Suggestion < res[j].Suggestion
+	})
+	return ctx.JSON(http.StatusOK, res)
+}
+
+const (
+	resourceSearchDefaultSize = 20
+	resourceSearchMaxSize     = 1000
+)
+
+// resourceCostQueryID is the named query over pennywise_cost_estimate, taking the resource_id and resource_type query params.
+const resourceCostQueryID = "resource_cost_estimate"
+
+func resourceSearchLinks(resourceID, resourceType string) inventoryApi.SearchResourceLinks {
+	return inventoryApi.SearchResourceLinks{
+		Findings: inventoryApi.ResourceLink{
+			Method: http.MethodPost,
+			Path:   "/compliance/api/v1/findings/resource",
+			Body: map[string]any{
+				"kaytuResourceId": resourceID,
+				"resourceType":    resourceType,
+			},
+		},
+		Cost: inventoryApi.ResourceLink{
+			Method: http.MethodPost,
+			Path:   "/inventory/api/v3/query/run",
+			Body: inventoryApi.RunQueryByIDRequest{
+				Page: inventoryApi.Page{No: 1, Size: 1},
+				Type: "named_query",
+				ID:   resourceCostQueryID,
+				QueryParams: map[string]string{
+					"resource_id":   resourceID,
+					"resource_type": resourceType,
+				},
+			},
+		},
+	}
+}
+
+func searchBucketsToApi(buckets []es.ResourceSearchBucket) []inventoryApi.FacetBucket {
+	res := make([]inventoryApi.FacetBucket, 0, len(buckets))
+	for _, b := range buckets {
+		res = append(res, inventoryApi.FacetBucket{Key: b.Key, Count: b.DocCount})
+	}
+	return res
+}
+
+func searchResourcesToApi(response *es.ResourceSearchResponse, size int) inventoryApi.SearchResourcesResponse {
+	aggs := response.Aggregations
+	res := inventoryApi.SearchResourcesResponse{
+		TotalCount: response.Hits.Total.Value,
+		Resources:  make([]inventoryApi.SearchResourceHit, 0, len(response.Hits.Hits)),
+		Facets: inventoryApi.SearchResourcesFacets{
+			Connectors:    searchBucketsToApi(aggs.Connectors.Buckets),
+			ConnectionIDs: searchBucketsToApi(aggs.ConnectionIDs.Buckets),
+			ResourceTypes: searchBucketsToApi(aggs.ResourceTypes.Buckets),
+			Regions:       searchBucketsToApi(aggs.Regions.Buckets),
+			TagKeys:       make([]inventoryApi.FacetBucket, 0, len(aggs.Tags.Keys.Buckets)),
+			TagValues:     make(map[string][]inventoryApi.FacetBucket, len(aggs.Tags.Keys.Buckets)),
+		},
+	}
+	for _, b := range aggs.Tags.Keys.Buckets {
+		res.Facets.TagKeys = append(res.Facets.TagKeys, inventoryApi.FacetBucket{Key: b.Key, Count: b.DocCount})
+		res.Facets.TagValues[b.Key] = searchBucketsToApi(b.Values.Buckets)
+	}
+	for _, hit := range response.Hits.Hits {
+		r := hit.Source
+		var tags map[string][]string
+		if len(r.Tags) > 0 {
+			tags = make(map[string][]string)
+			for _, t := range r.Tags {
+				tags[t.Key] = append(tags[t.Key], t.Value)
+			}
+		}
+		res.Resources = append(res.Resources, inventoryApi.SearchResourceHit{
+			ResourceID:   r.ResourceID,
+			Name:         r.Name,
+			ResourceType: r.ResourceType,
+			Connector:    r.SourceType,
+			ConnectionID: r.SourceID,
+			Location:     r.Location,
+			Tags:         tags,
+			Score:        hit.Score,
+			Links:        resourceSearchLinks(r.ResourceID, r.ResourceType),
+		})
+	}
+	// a full page may be followed by another one, a shorter page is the last
+	if hits := response.Hits.Hits; len(hits) == size {
+		res.SearchAfter = hits[len(hits)-1].Sort
+	}
+	return res
+}
+
+// SearchResources godoc
+//
+//	@Summary		Search resources
+//	@Description	Searching resources of all types by name, ID, ARN and tags, with facets to narrow down the results.
+//	@Description	Results are ranked by relevance, pass the returned searchAfter to get the next page.
+//	@Security		BearerToken
+//	@Tags			inventory
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		inventoryApi.SearchResourcesRequest	true	"Search request"
+//	@Success		200		{object}	inventoryApi.SearchResourcesResponse
+//	@Router			/inventory/api/v2/resources/search [post]
+func (h *HttpHandler) SearchResources(ctx echo.Context) error {
+	var req inventoryApi.SearchResourcesRequest
+	if err := bindValidate(ctx, &req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if req.Size <= 0 {
+		req.Size = resourceSearchDefaultSize
+	}
+	if req.Size > resourceSearchMaxSize {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("size must be at most %d", resourceSearchMaxSize))
+	}
+	if req.FacetSize <= 0 {
+		req.FacetSize = resourceSearchDefaultSize
+	}
+	if req.FacetSize > resourceSearchMaxSize {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("facetSize must be at most %d", resourceSearchMaxSize))
+	}
+
+	connectionIDs, err := httpserver.ResolveConnectionIDs(ctx, req.ConnectionIDs)
+	if err != nil {
+		return err
+	}
+	resourceIDs, err := h.scopedResourceIDs(ctx)
+	if err != nil {
+		return err
+	}
+
+	response, err := es.SearchResources(ctx.Request().Context(), h.client, req.Query, es.ResourceSearchFilters{
+		Connectors:    req.Connectors,
+		ConnectionIDs: connectionIDs,
+		ResourceIDs:   resourceIDs,
+		ResourceTypes: req.ResourceTypes,
+		Regions:       req.Regions,
+		Tags:          req.Tags,
+	}, req.SearchAfter, req.Size, req.FacetSize)
+	if err != nil {
+		h.logger.Error("failed to search resources", zap.Error(err))
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, searchResourcesToApi(response, req.Size))
+}
+
+func spendAnomalyToApi(a analyticsDB.SpendAnomaly) inventoryApi.SpendAnomaly {
+	res := inventoryApi.SpendAnomaly{
+		ID:               a.ID,
+		ConnectionID:     a.ConnectionID,
+		ConnectionName:   a.ConnectionName,
+		Connector:        a.Connector,
+		Date:             a.Date,
+		ExpectedCost:     a.ExpectedCost,
+		ActualCost:       a.ActualCost,
+		Deviation:        a.Deviation,
+		DeviationPercent: a.DeviationPercent,
+		Score:            a.Score,
+		Status:           string(a.Status),
+		AcknowledgedBy:   a.AcknowledgedBy,
+		AcknowledgedAt:   a.AcknowledgedAt,
+		Note:             a.Note,
+		NotifiedAt:       a.NotifiedAt,
+		Contributors:     make([]inventoryApi.SpendAnomalyContributor, 0, len(a.Contributors)),
+		CreatedAt:        a.CreatedAt,
+		UpdatedAt:        a.UpdatedAt,
+	}
+	// contributors are stored unordered, the most deviating anomalous metrics come first
+	contributors := a.Contributors
+	sort.Slice(contributors, func(i, j int) bool {
+		if contributors[i].Anomalous != contributors[j].Anomalous {
+			return contributors[i].Anomalous
+		}
+		return math.Abs(contributors[i].Deviation) > math.Abs(contributors[j].Deviation)
+	})
+	for _, c := range contributors {
+		res.Contributors = append(res.Contributors, inventoryApi.SpendAnomalyContributor{
+			MetricID:         c.MetricID,
+			MetricName:       c.MetricName,
+			ExpectedCost:     c.ExpectedCost,
+			ActualCost:       c.ActualCost,
+			Deviation:        c.Deviation,
+			DeviationPercent: c.DeviationPercent,
+			Score:            c.Score,
+			Anomalous:        c.Anomalous,
+		})
+	}
+	return res
+}
+
+func spendAnomalyMuteToApi(m analyticsDB.SpendAnomalyMute) inventoryApi.SpendAnomalyMute {
+	return inventoryApi.SpendAnomalyMute{
+		ID:           m.ID,
+		ConnectionID: m.ConnectionID,
+		MetricID:     m.MetricID,
+		Reason:       m.Reason,
+		CreatedBy:    m.CreatedBy,
+		ExpiresAt:    m.ExpiresAt,
+		CreatedAt:    m.CreatedAt,
+	}
+}
+
+// ListSpendAnomalies godoc
+//
+//	@Summary		List spend anomalies
+//	@Description	Retrieving the days on which the spend of a connection deviated from its baseline, newest first.
+//	@Security		BearerToken
+//	@Tags			analytics
+//	@Produce		json
+//	@Param			connectionId	query		[]string	false	"Connection IDs to filter by"
+//	@Param			status			query		string		false	"Status to filter by"	Enums(open, acknowledged)
+//	@Param			startTime		query		int64		false	"Start of the anomaly dates - unix seconds"
+//	@Param			endTime			query		int64		false	"End of the anomaly dates - unix seconds"
+//	@Success		200				{object}	[]inventoryApi.SpendAnomaly
+//	@Router			/inventory/api/v2/analytics/spend/anomalies [get]
+func (h *HttpHandler) ListSpendAnomalies(ctx echo.Context) error {
+	connectionIDs, err := httpserver.ResolveConnectionIDs(ctx, httpserver.QueryArrayParam(ctx, "connectionId"))
+	if err != nil {
+		return err
+	}
+
+	var status *analyticsDB.SpendAnomalyStatus
+	if s := analyticsDB.SpendAnomalyStatus(ctx.QueryParam("status")); s != "" {
+		if s != analyticsDB.SpendAnomalyStatusOpen && s != analyticsDB.SpendAnomalyStatusAcknowledged {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid status")
+		}
+		status = &s
+	}
+
+	var startTime, endTime *time.Time
+	if ctx.QueryParam("startTime") != "" {
+		t, err := utils.TimeFromQueryParam(ctx, "startTime", time.Time{})
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		startTime = &t
+	}
+	if ctx.QueryParam("endTime") != "" {
+		t, err := utils.TimeFromQueryParam(ctx, "endTime", time.Time{})
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		endTime = &t
+	}
+
+	aDB := analyticsDB.NewDatabase(h.db.orm)
+	anomalies, err := aDB.ListSpendAnomalies(connectionIDs, status, startTime, endTime)
+	if err != nil {
+		h.logger.Error("failed to list spend anomalies", zap.Error(err))
+		return err
+	}
+
+	res := make([]inventoryApi.SpendAnomaly, 0, len(anomalies))
+	for _, a := range anomalies {
+		res = append(res, spendAnomalyToApi(a))
+	}
+	return ctx.JSON(http.StatusOK, res)
+}
+
+// GetSpendAnomaly godoc
+//
+//	@Summary		Get spend anomaly
+//	@Description	Retrieving a spend anomaly with the metrics contributing to it.
+//	@Security		BearerToken
+//	@Tags			analytics
+//	@Produce		json
+//	@Param			anomalyId	path		string	true	"Spend anomaly ID"
+//	@Success		200			{object}	inventoryApi.SpendAnomaly
+//	@Router			/inventory/api/v2/analytics/spend/anomalies/{anomalyId} [get]
+func (h *HttpHandler) GetSpendAnomaly(ctx echo.Context) error {
+	aDB := analyticsDB.NewDatabase(h.db.orm)
+	anomaly, err := aDB.GetSpendAnomaly(ctx.Param("anomalyId"))
+	if err != nil {
+		h.logger.Error("failed to get spend anomaly", zap.Error(err))
+		return err
+	}
+	if anomaly == nil {
+		return echo.NewHTTPError(http.StatusNotFound, "spend anomaly not found")
+	}
+	if err := httpserver.CheckAccessToConnectionID(ctx, anomaly.ConnectionID); err != nil {
+		return err
+	}
+	return ctx.JSON(http.StatusOK, spendAnomalyToApi(*anomaly))
+}
+
+// AcknowledgeSpendAnomaly godoc
+//
+//	@Summary		Acknowledge spend anomaly
+//	@Description	Marking a spend anomaly as acknowledged. It is not sent to the webhook again.
+//	@Security		BearerToken
+//	@Tags			analytics
+//	@Accept			json
+//	@Produce		json
+//	@Param			anomalyId	path		string									true	"Spend anomaly ID"
+//	@Param			request		body		inventoryApi.AcknowledgeSpendAnomalyRequest	false	"Acknowledgement"
+//	@Success		200			{object}	inventoryApi.SpendAnomaly
+//	@Router			/inventory/api/v2/analytics/spend/anomalies/{anomalyId}/acknowledge [post]
+func (h *HttpHandler) AcknowledgeSpendAnomaly(ctx echo.Context) error {
+	var req inventoryApi.AcknowledgeSpendAnomalyRequest
+	if ctx.Request().ContentLength > 0 {
+		if err := bindValidate(ctx, &req); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+	}
+
+	aDB := analyticsDB.NewDatabase(h.db.orm)
+	anomaly, err := aDB.GetSpendAnomaly(ctx.Param("anomalyId"))
+	if err != nil {
+		h.logger.Error("failed to get spend anomaly", zap.Error(err))
+		return err
+	}
+	if anomaly == nil {
+		return echo.NewHTTPError(http.StatusNotFound, "spend anomaly not found")
+	}
+	if err := httpserver.CheckAccessToConnectionID(ctx, anomaly.ConnectionID); err != nil {
+		return err
+	}
+
+	if err := aDB.AcknowledgeSpendAnomaly(anomaly.ID, httpserver.GetUserID(ctx), req.Note); err != nil {
+		h.logger.Error("failed to acknowledge spend anomaly", zap.Error(err))
+		return err
+	}
+
+	updated, err := aDB.GetSpendAnomaly(anomaly.ID)
+	if err != nil || updated == nil {
+		h.logger.Error("failed to get acknowledged spend anomaly", zap.Error(err))
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to get acknowledged spend anomaly")
+	}
+	return ctx.JSON(http.StatusOK, spendAnomalyToApi(*updated))
+}
+
+// ListSpendAnomalyMutes godoc
+//
+//	@Summary		List spend anomaly mutes
+//	@Description	Retrieving the connections and metrics spend anomalies are not raised for, including expired mutes.
+//	@Security		BearerToken
+//	@Tags			analytics
+//	@Produce		json
+//	@Success		200	{object}	[]inventoryApi.SpendAnomalyMute
+//	@Router			/inventory/api/v2/analytics/spend/anomalies/mutes [get]
+func (h *HttpHandler) ListSpendAnomalyMutes(ctx echo.Context) error {
+	aDB := analyticsDB.NewDatabase(h.db.orm)
+	mutes, err := aDB.ListSpendAnomalyMutes(false)
+	if err != nil {
+		h.logger.Error("failed to list spend anomaly mutes", zap.Error(err))
+		return err
+	}
+
+	res := make([]inventoryApi.SpendAnomalyMute, 0, len(mutes))
+	for _, m := range mutes {
+		if httpserver.CheckAccessToConnectionID(ctx, m.ConnectionID) != nil {
+			continue
+		}
+		res = append(res, spendAnomalyMuteToApi(m))
+	}
+	return ctx.JSON(http.StatusOK, res)
+}
+
+// CreateSpendAnomalyMute godoc
+//
+//	@Summary		Create spend anomaly mute
+//	@Description	Stopping spend anomalies from being raised for a connection, or for a single metric of it, until the mute expires.
+//	@Security		BearerToken
+//	@Tags			analytics
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		inventoryApi.CreateSpendAnomalyMuteRequest	true	"Mute"
+//	@Success		200		{object}	inventoryApi.SpendAnomalyMute
+//	@Router			/inventory/api/v2/analytics/spend/anomalies/mutes [post]
+func (h *HttpHandler) CreateSpendAnomalyMute(ctx echo.Context) error {
+	var req inventoryApi.CreateSpendAnomalyMuteRequest
+	if err := bindValidate(ctx, &req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return echo.NewHTTPError(http.StatusBadRequest, "expires_at must be in the future")
+	}
+	if err := httpserver.CheckAccessToConnectionID(ctx, req.ConnectionID); err != nil {
+		return err
+	}
+
+	mute := analyticsDB.SpendAnomalyMute{
+		ConnectionID: req.ConnectionID,
+		MetricID:     req.MetricID,
+		Reason:       req.Reason,
+		CreatedBy:    httpserver.GetUserID(ctx),
+		ExpiresAt:    req.ExpiresAt,
+	}
+	aDB := analyticsDB.NewDatabase(h.db.orm)
+	if err := aDB.CreateSpendAnomalyMute(&mute); err != nil {
+		h.logger.Error("failed to create spend anomaly mute", zap.Error(err))
+		return err
+	}
+	return ctx.JSON(http.StatusOK, spendAnomalyMuteToApi(mute))
+}
+
+// DeleteSpendAnomalyMute godoc
+//
+//	@Summary		Delete spend anomaly mute
+//	@Description	Deleting a spend anomaly mute, anomalies are raised again from the next analytics job.
+//	@Security		BearerToken
+//	@Tags			analytics
+//	@Param			muteId	path	string	true	"Mute ID"
+//	@Success		200
+//	@Router			/inventory/api/v2/analytics/spend/anomalies/mutes/{muteId} [delete]
+func (h *HttpHandler) DeleteSpendAnomalyMute(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("muteId"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid mute id")
+	}
+
+	aDB := analyticsDB.NewDatabase(h.db.orm)
+	mute, err := aDB.GetSpendAnomalyMute(uint(id))
+	if err != nil {
+		h.logger.Error("failed to get spend anomaly mute", zap.Error(err))
+		return err
+	}
+	if mute == nil {
+		return echo.NewHTTPError(http.StatusNotFound, "spend anomaly mute not found")
+	}
+	if err := httpserver.CheckAccessToConnectionID(ctx, mute.ConnectionID); err != nil {
+		return err
+	}
+
+	if err := aDB.DeleteSpendAnomalyMute(mute.ID); err != nil {
+		h.logger.Error("failed to delete spend anomaly mute", zap.Error(err))
+		return err
+	}
+	return ctx.NoContent(http.StatusOK)
+}
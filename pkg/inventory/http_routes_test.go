@@ -0,0 +1,141 @@
+package inventory
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/opengovern/og-util/pkg/source"
+	inventoryApi "github.com/opengovern/opengovernance/pkg/inventory/api"
+	"github.com/opengovern/opengovernance/pkg/inventory/es"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryResultCacheKey(t *testing.T) {
+	req := inventoryApi.RunQueryByIDRequest{
+		Page:        inventoryApi.Page{No: 1, Size: 10},
+		Type:        "named_query",
+		ID:          "q1",
+		QueryParams: map[string]string{"region": "us-east-1"},
+	}
+	key, err := queryResultCacheKey(req, "select 1", inventoryApi.QueryEngine_OdysseusSQL)
+	assert.NoError(t, err)
+	assert.Len(t, key, 64)
+
+	same := req
+	same.QueryParams = map[string]string{"region": "us-east-1"}
+	same.BypassCache = true
+	sameKey, err := queryResultCacheKey(same, "select 1", inventoryApi.QueryEngine_OdysseusSQL)
+	assert.NoError(t, err)
+	assert.Equal(t, key, sameKey, "bypassing the cache does not change the key")
+
+	other := func(name string, req inventoryApi.RunQueryByIDRequest, query string, engine inventoryApi.QueryEngine) {
+		otherKey, err := queryResultCacheKey(req, query, engine)
+		assert.NoError(t, err, name)
+		assert.NotEqual(t, key, otherKey, name)
+	}
+	other("query", req, "select 2", inventoryApi.QueryEngine_OdysseusSQL)
+	other("engine", req, "select 1", inventoryApi.QueryEngine_OdysseusRego)
+
+	page := req
+	page.Page.No = 2
+	other("page", page, "select 1", inventoryApi.QueryEngine_OdysseusSQL)
+
+	params := req
+	params.QueryParams = map[string]string{"region": "eu-west-1"}
+	other("params", params, "select 1", inventoryApi.QueryEngine_OdysseusSQL)
+
+	sorts := req
+	sorts.Sorts = []inventoryApi.NamedQuerySortItem{{Field: "name", Direction: "asc"}}
+	other("sorts", sorts, "select 1", inventoryApi.QueryEngine_OdysseusSQL)
+}
+
+func TestCachedQueryResult(t *testing.T) {
+	now := time.Now()
+	req := inventoryApi.RunQueryByIDRequest{Type: "Named_Query", ID: "q1"}
+	resp := &inventoryApi.RunQueryResponse{Title: "q1", Headers: []string{"name"}, Result: [][]any{{"web-1"}}}
+
+	entry, err := newQueryResultCache("key", req, []string{"kaytu_resources"}, resp, now)
+	assert.NoError(t, err)
+	assert.Equal(t, "named_query", entry.RunnableType)
+	assert.True(t, entry.AnyResourceType, "tables of no resource type are invalidated by any discovery")
+	assert.False(t, resp.Cache.Hit)
+
+	noTables, err := newQueryResultCache("key", req, nil, resp, now)
+	assert.NoError(t, err)
+	assert.Nil(t, noTables, "queries without a list of tables are not cached")
+
+	hit, err := cachedQueryResult(*entry, now.Add(time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, "q1", hit.Title)
+	assert.Equal(t, []string{"name"}, hit.Headers)
+	assert.Equal(t, [][]any{{"web-1"}}, hit.Result)
+	assert.True(t, hit.Cache.Hit)
+	assert.Equal(t, int64(3600), hit.Cache.AgeSeconds)
+
+	expired, err := cachedQueryResult(*entry, now.Add(queryResultCacheTTL+time.Second))
+	assert.NoError(t, err)
+	assert.Nil(t, expired)
+}
+
+func TestReadsQueryResultCache(t *testing.T) {
+	assert.True(t, readsQueryResultCache(inventoryApi.RunQueryByIDRequest{}, false))
+	assert.False(t, readsQueryResultCache(inventoryApi.RunQueryByIDRequest{BypassCache: true}, false))
+	assert.False(t, readsQueryResultCache(inventoryApi.RunQueryByIDRequest{}, true))
+}
+
+func TestResourceSearchLinks(t *testing.T) {
+	links := resourceSearchLinks("arn:aws:ec2:us-east-1:123456789012:instance/i-1' OR '1'='1", "aws::ec2::instance")
+	assert.Equal(t, http.MethodPost, links.Findings.Method)
+	assert.Equal(t, "/compliance/api/v1/findings/resource", links.Findings.Path)
+
+	assert.Equal(t, http.MethodPost, links.Cost.Method)
+	assert.Equal(t, "/inventory/api/v3/query/run", links.Cost.Path)
+	assert.Equal(t, inventoryApi.RunQueryByIDRequest{
+		Page: inventoryApi.Page{No: 1, Size: 1},
+		Type: "named_query",
+		ID:   resourceCostQueryID,
+		QueryParams: map[string]string{
+			"resource_id":   "arn:aws:ec2:us-east-1:123456789012:instance/i-1' OR '1'='1",
+			"resource_type": "aws::ec2::instance",
+		},
+	}, links.Cost.Body, "the resource is passed as query params, not spliced into SQL")
+}
+
+func TestSearchResourcesToApi(t *testing.T) {
+	hit := func(id string, score float64) es.ResourceSearchHit {
+		h := es.ResourceSearchHit{ID: "doc-" + id, Score: score, Sort: []any{score, "doc-" + id}}
+		h.Source.ResourceID = id
+		h.Source.ResourceType = "aws::ec2::instance"
+		h.Source.SourceType = source.CloudAWS
+		return h
+	}
+	response := &es.ResourceSearchResponse{}
+	response.Hits.Total.Value = 3
+	response.Hits.Hits = []es.ResourceSearchHit{hit("i-1", 8), hit("i-2", 4)}
+	response.Aggregations.Regions.Buckets = []es.ResourceSearchBucket{{Key: "us-east-1", DocCount: 3}}
+	response.Aggregations.Tags.Keys.Buckets = []es.ResourceSearchTagKeyBucket{
+		{
+			ResourceSearchBucket: es.ResourceSearchBucket{Key: "env", DocCount: 3},
+			Values:               es.ResourceSearchBuckets{Buckets: []es.ResourceSearchBucket{{Key: "prod", DocCount: 2}, {Key: "dev", DocCount: 1}}},
+		},
+		{ResourceSearchBucket: es.ResourceSearchBucket{Key: "owner", DocCount: 1}},
+	}
+
+	res := searchResourcesToApi(response, 2)
+	assert.Equal(t, int64(3), res.TotalCount)
+	assert.Len(t, res.Resources, 2)
+	assert.Equal(t, "i-1", res.Resources[0].ResourceID)
+	assert.Equal(t, resourceSearchLinks("i-1", "aws::ec2::instance"), res.Resources[0].Links)
+	assert.Equal(t, []inventoryApi.FacetBucket{{Key: "us-east-1", Count: 3}}, res.Facets.Regions)
+	assert.Equal(t, []inventoryApi.FacetBucket{}, res.Facets.Connectors)
+	assert.Equal(t, []inventoryApi.FacetBucket{{Key: "env", Count: 3}, {Key: "owner", Count: 1}}, res.Facets.TagKeys)
+	assert.Equal(t, map[string][]inventoryApi.FacetBucket{
+		"env":   {{Key: "prod", Count: 2}, {Key: "dev", Count: 1}},
+		"owner": {},
+	}, res.Facets.TagValues)
+	assert.Equal(t, []any{4.0, "doc-i-2"}, res.SearchAfter, "a full page continues after its last hit")
+
+	last := searchResourcesToApi(response, 20)
+	assert.Nil(t, last.SearchAfter, "a short page is the last one")
+}
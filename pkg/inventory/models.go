@@ -181,6 +181,14 @@ type ResourceCollection struct {
 	FiltersJson pgtype.JSONB `gorm:"type:jsonb"`
 	Description string
 	Status      ResourceCollectionStatus
+	// DefinitionJson is only set for collections created through the API, the filters of those are derived from it
+	DefinitionJson pgtype.JSONB `gorm:"type:jsonb"`
+
+	MembershipJobID uint
+	MaterializedAt  *time.Time
+	MemberCount     int
+	AddedCount      int
+	RemovedCount    int
 
 	Tags    []ResourceCollectionTag `gorm:"foreignKey:ResourceCollectionID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
 	tagsMap map[string][]string     `gorm:"-:all"`
@@ -190,7 +198,8 @@ type ResourceCollection struct {
 	UpdatedAt time.Time
 	DeletedAt gorm.DeletedAt `gorm:"index"`
 
-	Filters []opengovernance.ResourceCollectionFilter `gorm:"-:all"`
+	Filters    []opengovernance.ResourceCollectionFilter `gorm:"-:all"`
+	Definition *api.ResourceCollectionDefinition         `gorm:"-:all"`
 }
 
 func (r ResourceCollection) ToApi() api.ResourceCollection {
@@ -202,6 +211,16 @@ func (r ResourceCollection) ToApi() api.ResourceCollection {
 		CreatedAt:   r.Created,
 		Status:      r.Status.ToApi(),
 		Filters:     r.Filters,
+		Definition:  r.Definition,
+	}
+	if r.MaterializedAt != nil {
+		apiResourceCollection.Membership = &api.ResourceCollectionMembership{
+			JobID:          r.MembershipJobID,
+			MaterializedAt: r.MaterializedAt,
+			MemberCount:    r.MemberCount,
+			AddedCount:     r.AddedCount,
+			RemovedCount:   r.RemovedCount,
+		}
 	}
 	return apiResourceCollection
 }
@@ -248,3 +267,17 @@ func (r CategoriesTables) ToApi() api.CategoriesTables {
 	}
 	return apiResourceType
 }
+
+// QueryResultCache is a cached named query result. Entries are removed once the resources of a completed describe
+// job for one of the resource types the query reads from are ingested, or for any of them when AnyResourceType is
+// set. They are not served after queryResultCacheTTL either way.
+type QueryResultCache struct {
+	Key             string `gorm:"primaryKey"`
+	RunnableType    string
+	RunnableID      string
+	Tables          pq.StringArray `gorm:"type:text[]"`
+	ResourceTypes   pq.StringArray `gorm:"type:text[];index:,type:gin"`
+	AnyResourceType bool
+	Result          pgtype.JSONB
+	CreatedAt       time.Time
+}
@@ -0,0 +1,102 @@
+package inventory
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/opengovern/opengovernance/pkg/utils"
+	pg_query "github.com/pganalyze/pg_query_go/v4"
+)
+
+// scopedQueryNodes are the statements a query of a scoped request may not have anywhere in its tree,
+// data-modifying CTEs included.
+var scopedQueryNodes = []string{"InsertStmt", "UpdateStmt", "DeleteStmt", "MergeStmt"}
+
+// scopeQuery restricts a steampipe query to the scope of the request. The query is wrapped in a select on
+// its kaytu_account_id column, and on its kaytu_resource_id column for requests scoped to resource
+// collections, so queries without these columns fail instead of returning rows outside the scope. Unscoped
+// requests get the query as is.
+func (h *HttpHandler) scopeQuery(ctx echo.Context, query string) (string, error) {
+	connectionIDs := utils.GetScopedConnectionIDs(ctx)
+	if len(connectionIDs) == 0 {
+		return query, nil
+	}
+	resourceIDs, err := h.scopedResourceIDs(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	query = strings.TrimRight(strings.TrimSpace(query), "; \t\n")
+	// the query is parsed without the parentheses it is wrapped in, so it can not close them
+	if err := validateScopedQuery(query); err != nil {
+		return "", echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	conditions := []string{fmt.Sprintf("kaytu_account_id IN (%s)", sqlStringList(connectionIDs))}
+	if resourceIDs != nil {
+		if len(resourceIDs) == 0 {
+			resourceIDs = []string{utils.ScopeNoMatch}
+		}
+		conditions = append(conditions, fmt.Sprintf("kaytu_resource_id IN (%s)", sqlStringList(resourceIDs)))
+	}
+	return fmt.Sprintf("SELECT * FROM (\n%s\n) AS scoped_query WHERE %s", query, strings.Join(conditions, " AND ")), nil
+}
+
+// validateScopedQuery rejects the query unless it is a single select statement.
+func validateScopedQuery(query string) error {
+	tree, err := pg_query.ParseToJSON(query)
+	if err != nil {
+		return fmt.Errorf("invalid query: %w", err)
+	}
+	var parsed struct {
+		Stmts []struct {
+			Stmt map[string]json.RawMessage `json:"stmt"`
+		} `json:"stmts"`
+	}
+	if err := json.Unmarshal([]byte(tree), &parsed); err != nil {
+		return err
+	}
+	if len(parsed.Stmts) != 1 || parsed.Stmts[0].Stmt["SelectStmt"] == nil {
+		return errors.New("query must be a single select statement")
+	}
+	for _, node := range scopedQueryNodes {
+		if strings.Contains(tree, fmt.Sprintf("%q:", node)) {
+			return errors.New("query must be a single select statement")
+		}
+	}
+	return nil
+}
+
+// sqlStringList quotes the values as a list of sql string literals.
+func sqlStringList(values []string) string {
+	quoted := make([]string, 0, len(values))
+	for _, value := range values {
+		quoted = append(quoted, "'"+strings.ReplaceAll(value, "'", "''")+"'")
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// scopedResourceIDs returns the member resources of the resource collections the request is scoped to, nil if
+// the request is not scoped to resource collections.
+func (h *HttpHandler) scopedResourceIDs(ctx echo.Context) ([]string, error) {
+	return utils.ScopeResourceIDs(ctx, func(resourceCollectionIDs []string) ([]string, error) {
+		return h.memberCache.ResourceIDs(ctx.Request().Context(), h.logger, h.client, resourceCollectionIDs)
+	})
+}
+
+// checkResourceCollectionScope rejects requests for a resource collection outside the resource collection
+// scope of the caller.
+func checkResourceCollectionScope(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		if id := ctx.Param("resourceCollectionId"); id != "" {
+			if err := utils.CheckAccessToResourceCollectionID(ctx, id); err != nil {
+				return err
+			}
+		}
+		return next(ctx)
+	}
+}
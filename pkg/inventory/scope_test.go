@@ -0,0 +1,77 @@
+package inventory
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/opengovern/og-util/pkg/httpserver"
+	"github.com/opengovern/opengovernance/pkg/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func scopedContext(headers map[string]string) echo.Context {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	return echo.New().NewContext(req, httptest.NewRecorder())
+}
+
+func TestScopeQuery(t *testing.T) {
+	h := &HttpHandler{}
+
+	query, err := h.scopeQuery(scopedContext(nil), "select * from aws_ec2_instance;")
+	assert.NoError(t, err)
+	assert.Equal(t, "select * from aws_ec2_instance;", query, "unscoped queries are left as is")
+
+	ctx := scopedContext(map[string]string{httpserver.XKaytuUserConnectionsScope: "c1,c'2"})
+	query, err = h.scopeQuery(ctx, "select name, kaytu_account_id from aws_ec2_instance ;\n")
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM (\nselect name, kaytu_account_id from aws_ec2_instance\n) AS scoped_query "+
+		"WHERE kaytu_account_id IN ('c1', 'c''2')", query)
+
+	for _, query := range []string{
+		"select 1; delete from kaytu_configs",
+		"delete from kaytu_configs",
+		"with d as (delete from kaytu_configs returning *) select * from d",
+		"select * from aws_ec2_instance) s union select * from (select * from aws_ec2_instance",
+		"select * from aws_ec2_instance /*",
+	} {
+		_, err := h.scopeQuery(ctx, query)
+		assert.Error(t, err, query)
+	}
+}
+
+func TestCheckResourceCollectionScope(t *testing.T) {
+	handler := checkResourceCollectionScope(func(ctx echo.Context) error {
+		return ctx.NoContent(http.StatusOK)
+	})
+
+	tests := []struct {
+		name    string
+		scope   string
+		id      string
+		wantErr bool
+	}{
+		{name: "unscoped", id: "rc1"},
+		{name: "in scope", scope: "rc1,rc2", id: "rc2"},
+		{name: "outside", scope: "rc1", id: "rc3", wantErr: true},
+		{name: "no match", scope: utils.ScopeNoMatch, id: "rc1", wantErr: true},
+		{name: "no collection in path", scope: "rc1"},
+	}
+	for _, tt := range tests {
+		ctx := scopedContext(map[string]string{utils.XKaytuUserResourceCollectionsScope: tt.scope})
+		if tt.id != "" {
+			ctx.SetParamNames("resourceCollectionId")
+			ctx.SetParamValues(tt.id)
+		}
+		err := handler(ctx)
+		if tt.wantErr {
+			assert.Error(t, err, tt.name)
+		} else {
+			assert.NoError(t, err, tt.name)
+		}
+	}
+}
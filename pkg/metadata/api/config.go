@@ -0,0 +1,45 @@
+package api
+
+type ConfigValueChange struct {
+	Path string `json:"path"`
+	From any    `json:"from"`
+	To   any    `json:"to"`
+}
+
+type ConfigVersionDiffResponse struct {
+	VersionID uint                `json:"versionId"`
+	Kind      string              `json:"kind"`
+	Key       string              `json:"key"`
+	CompareTo string              `json:"compareTo"`
+	From      *string             `json:"from"`
+	To        *string             `json:"to"`
+	Changes   []ConfigValueChange `json:"changes"`
+}
+
+// WorkspaceConfig is the exported form of every metadata key, query parameter and filter in a workspace.
+type WorkspaceConfig struct {
+	Metadata        map[string]any               `json:"metadata"`
+	QueryParameters map[string]string            `json:"queryParameters"`
+	Filters         map[string]map[string]string `json:"filters"`
+}
+
+type ConfigImportAction string
+
+const (
+	ConfigImportActionCreate ConfigImportAction = "create"
+	ConfigImportActionUpdate ConfigImportAction = "update"
+	ConfigImportActionDelete ConfigImportAction = "delete"
+)
+
+type ConfigImportChange struct {
+	Kind   string             `json:"kind"`
+	Key    string             `json:"key"`
+	Action ConfigImportAction `json:"action"`
+	From   *string            `json:"from"`
+	To     *string            `json:"to"`
+}
+
+type ImportConfigResponse struct {
+	DryRun  bool                 `json:"dryRun"`
+	Changes []ConfigImportChange `json:"changes"`
+}
@@ -0,0 +1,13 @@
+package api
+
+import "time"
+
+// ConfigChangedEvent is published on NATS for every versioned change to a metadata key, query parameter or filter.
+type ConfigChangedEvent struct {
+	VersionID uint      `json:"versionId"`
+	Kind      string    `json:"kind"`
+	Key       string    `json:"key"`
+	Value     *string   `json:"value"`
+	Actor     string    `json:"actor"`
+	ChangedAt time.Time `json:"changedAt"`
+}
@@ -0,0 +1,59 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/opengovern/og-util/pkg/jq"
+	"github.com/opengovern/opengovernance/pkg/metadata/api"
+)
+
+const (
+	ConfigEventsStreamName = "metadata-config-events"
+	ConfigChangedTopic     = "metadata-config-changed"
+)
+
+// SetupConfigEventsStream creates the stream configuration changes are published on. Unlike the job
+// queues, every subscribing service receives each event, so messages are kept for a day instead of
+// being removed once consumed.
+func SetupConfigEventsStream(ctx context.Context, q *jq.JobQueue) error {
+	return q.StreamWithConfig(ctx, ConfigEventsStreamName, "metadata configuration change events", []string{ConfigChangedTopic}, jetstream.StreamConfig{
+		Retention:    jetstream.LimitsPolicy,
+		MaxConsumers: -1,
+		MaxMsgs:      10000,
+		MaxAge:       24 * time.Hour,
+		Discard:      jetstream.DiscardOld,
+		Duplicates:   15 * time.Minute,
+		Replicas:     1,
+		Storage:      jetstream.MemoryStorage,
+	})
+}
+
+func PublishConfigChanged(ctx context.Context, q *jq.JobQueue, event api.ConfigChangedEvent) error {
+	b, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = q.Produce(ctx, ConfigChangedTopic, b, fmt.Sprintf("config-version-%d", event.VersionID))
+	return err
+}
+
+// ConsumeConfigChanges calls handler for every configuration change published after the consumer is created.
+func ConsumeConfigChanges(ctx context.Context, q *jq.JobQueue, service string, handler func(api.ConfigChangedEvent)) (jetstream.ConsumeContext, error) {
+	return q.ConsumeWithConfig(ctx, service, ConfigEventsStreamName, []string{ConfigChangedTopic}, jetstream.ConsumerConfig{
+		Replicas:          1,
+		AckPolicy:         jetstream.AckExplicitPolicy,
+		DeliverPolicy:     jetstream.DeliverNewPolicy,
+		MaxAckPending:     -1,
+		InactiveThreshold: time.Hour,
+	}, nil, func(msg jetstream.Msg) {
+		var event api.ConfigChangedEvent
+		if err := json.Unmarshal(msg.Data(), &event); err == nil {
+			handler(event)
+		}
+		_ = msg.Ack()
+	})
+}
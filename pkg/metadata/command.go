@@ -4,6 +4,9 @@ import (
 	"context"
 	"fmt"
 	"github.com/opengovern/og-util/pkg/httpserver"
+	"github.com/opengovern/og-util/pkg/jq"
+	"github.com/opengovern/opengovernance/pkg/auth/audit"
+	"github.com/opengovern/opengovernance/pkg/metadata/client"
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
 	"os"
@@ -18,6 +21,8 @@ var (
 	PostgreSQLSSLMode  = os.Getenv("POSTGRESQL_SSLMODE")
 
 	HttpAddress = os.Getenv("HTTP_ADDRESS")
+	AuthBaseUrl = os.Getenv("AUTH_BASE_URL")
+	NatsURL     = os.Getenv("NATS_URL")
 )
 
 func Command() *cobra.Command {
@@ -46,6 +51,20 @@ func start(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("init http handler: %w", err)
 	}
+	handler.auditRecorder = audit.NewServiceRecorder(ctx, logger, "metadata", AuthBaseUrl)
+
+	if NatsURL != "" {
+		q, err := jq.New(NatsURL, logger)
+		if err != nil {
+			return fmt.Errorf("new job queue: %w", err)
+		}
+		if err := client.SetupConfigEventsStream(ctx, q); err != nil {
+			return fmt.Errorf("setup config events stream: %w", err)
+		}
+		handler.db = handler.db.WithConfigChangeListener(configChangePublisher(ctx, logger, q))
+	} else {
+		logger.Warn("NATS_URL is not set, configuration change events are not published")
+	}
 
 	return httpserver.RegisterAndStart(ctx, logger, HttpAddress, handler)
 }
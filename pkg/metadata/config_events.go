@@ -0,0 +1,31 @@
+package metadata
+
+import (
+	"context"
+
+	"github.com/opengovern/og-util/pkg/jq"
+	"github.com/opengovern/opengovernance/pkg/metadata/api"
+	"github.com/opengovern/opengovernance/pkg/metadata/client"
+	"github.com/opengovern/opengovernance/pkg/metadata/models"
+	"go.uber.org/zap"
+)
+
+// configChangePublisher publishes a ConfigChangedEvent for every recorded version so that services
+// pick up new values without polling. Publishing failures are logged and never fail the write.
+func configChangePublisher(ctx context.Context, logger *zap.Logger, q *jq.JobQueue) func([]models.ConfigVersion) {
+	return func(versions []models.ConfigVersion) {
+		for _, v := range versions {
+			err := client.PublishConfigChanged(ctx, q, api.ConfigChangedEvent{
+				VersionID: v.ID,
+				Kind:      string(v.Kind),
+				Key:       v.Key,
+				Value:     v.Value,
+				Actor:     v.Actor,
+				ChangedAt: v.CreatedAt,
+			})
+			if err != nil {
+				logger.Error("failed to publish config change", zap.String("kind", string(v.Kind)), zap.String("key", v.Key), zap.Error(err))
+			}
+		}
+	}
+}
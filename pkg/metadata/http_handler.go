@@ -3,13 +3,15 @@ package metadata
 import (
 	"fmt"
 	"github.com/opengovern/og-util/pkg/postgres"
+	"github.com/opengovern/opengovernance/pkg/auth/audit"
 	"github.com/opengovern/opengovernance/pkg/metadata/internal/database"
 	"go.uber.org/zap"
 )
 
 type HttpHandler struct {
-	db     database.Database
-	logger *zap.Logger
+	db            database.Database
+	logger        *zap.Logger
+	auditRecorder *audit.Recorder
 }
 
 func InitializeHttpHandler(
@@ -2,8 +2,11 @@ package metadata
 
 import (
 	"errors"
+	"fmt"
+	"github.com/goccy/go-yaml"
 	api3 "github.com/opengovern/og-util/pkg/api"
 	"github.com/opengovern/og-util/pkg/httpserver"
+	"github.com/opengovern/opengovernance/pkg/auth/audit"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
@@ -11,7 +14,9 @@ import (
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 	_ "gorm.io/gorm"
+	"io"
 	"net/http"
+	"strconv"
 
 	"github.com/labstack/echo/v4"
 	"github.com/opengovern/opengovernance/pkg/metadata/api"
@@ -20,6 +25,8 @@ import (
 )
 
 func (h HttpHandler) Register(r *echo.Echo) {
+	r.Use(audit.Middleware(h.auditRecorder))
+
 	v1 := r.Group("/api/v1")
 
 	filter := v1.Group("/filter")
@@ -27,12 +34,20 @@ func (h HttpHandler) Register(r *echo.Echo) {
 	filter.GET("", httpserver.AuthorizeHandler(h.GetFilters, api3.ViewerRole))
 
 	metadata := v1.Group("/metadata")
+	metadata.GET("/schema", httpserver.AuthorizeHandler(h.ListConfigMetadataSchemas, api3.ViewerRole))
 	metadata.GET("/:key", httpserver.AuthorizeHandler(h.GetConfigMetadata, api3.ViewerRole))
 	metadata.POST("", httpserver.AuthorizeHandler(h.SetConfigMetadata, api3.AdminRole))
 
 	queryParameter := v1.Group("/query_parameter")
 	queryParameter.POST("", httpserver.AuthorizeHandler(h.SetQueryParameter, api3.AdminRole))
 	queryParameter.GET("", httpserver.AuthorizeHandler(h.ListQueryParameters, api3.ViewerRole))
+
+	config := v1.Group("/config")
+	config.GET("/versions", httpserver.AuthorizeHandler(h.ListConfigVersions, api3.ViewerRole))
+	config.GET("/versions/:id/diff", httpserver.AuthorizeHandler(h.GetConfigVersionDiff, api3.ViewerRole))
+	config.POST("/versions/:id/rollback", httpserver.AuthorizeHandler(h.RollbackConfigVersion, api3.AdminRole))
+	config.GET("/export", httpserver.AuthorizeHandler(h.ExportConfig, api3.AdminRole))
+	config.POST("/import", httpserver.AuthorizeHandler(h.ImportConfig, api3.AdminRole))
 }
 
 var tracer = otel.Tracer("metadata")
@@ -49,6 +64,14 @@ func bindValidate(ctx echo.Context, i interface{}) error {
 	return nil
 }
 
+// configValueError returns a 400 error for the values rejected by the schema of their key.
+func configValueError(err error) error {
+	if errors.Is(err, models.ErrInvalidConfigValue) {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	return err
+}
+
 // GetConfigMetadata godoc
 //
 //	@Summary		Get key metadata
@@ -109,11 +132,11 @@ func (h HttpHandler) SetConfigMetadata(ctx echo.Context) error {
 	_, span := tracer.Start(ctx.Request().Context(), "new_SetConfigMetadata", trace.WithSpanKind(trace.SpanKindServer))
 	span.SetName("new_SetConfigMetadata")
 
-	err = src.SetConfigMetadata(h.db, key, req.Value)
+	err = src.SetConfigMetadata(h.db, key, req.Value, httpserver.GetUserID(ctx))
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
-		return err
+		return configValueError(err)
 	}
 	span.AddEvent("information", trace.WithAttributes(
 		attribute.String("key", key.String()),
@@ -123,6 +146,19 @@ func (h HttpHandler) SetConfigMetadata(ctx echo.Context) error {
 	return ctx.JSON(http.StatusOK, nil)
 }
 
+// ListConfigMetadataSchemas godoc
+//
+//	@Summary		List metadata key schemas
+//	@Description	Returns the type, constraints, default and description of every metadata key
+//	@Security		BearerToken
+//	@Tags			metadata
+//	@Produce		json
+//	@Success		200	{object}	[]models.ConfigMetadataSchema
+//	@Router			/metadata/api/v1/metadata/schema [get]
+func (h HttpHandler) ListConfigMetadataSchemas(ctx echo.Context) error {
+	return ctx.JSON(http.StatusOK, models.ListConfigMetadataSchemas())
+}
+
 // AddFilter godoc
 //
 //	@Summary	add filter
@@ -141,7 +177,7 @@ func (h HttpHandler) AddFilter(ctx echo.Context) error {
 	_, span := tracer.Start(ctx.Request().Context(), "new_AddFilter", trace.WithSpanKind(trace.SpanKindServer))
 	span.SetName("new_AddFilter")
 
-	err := h.db.AddFilter(models.Filter{Name: req.Name, KeyValue: req.KeyValue})
+	err := h.db.AddFilter(models.Filter{Name: req.Name, KeyValue: req.KeyValue}, httpserver.GetUserID(ctx))
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
@@ -210,7 +246,7 @@ func (h HttpHandler) SetQueryParameter(ctx echo.Context) error {
 
 	_, span := tracer.Start(ctx.Request().Context(), "new_SetQueryParameter", trace.WithSpanKind(trace.SpanKindServer))
 	span.SetName("new_SetQueryParameter")
-	err := h.db.SetQueryParameters(dbQueryParams)
+	err := h.db.SetQueryParameters(dbQueryParams, httpserver.GetUserID(ctx))
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
@@ -254,3 +290,269 @@ func (h HttpHandler) ListQueryParameters(ctx echo.Context) error {
 
 	return ctx.JSON(http.StatusOK, result)
 }
+
+// ListConfigVersions godoc
+//
+//	@Summary		List configuration versions
+//	@Description	Returns the change history of metadata keys, query parameters and filters, newest first
+//	@Security		BearerToken
+//	@Tags			metadata
+//	@Produce		json
+//	@Param			kind	query		string	false	"Kind (metadata, query_parameter, filter)"
+//	@Param			key		query		string	false	"Key or filter name"
+//	@Param			limit	query		int		false	"Maximum number of versions to return"
+//	@Success		200		{object}	[]models.ConfigVersion
+//	@Router			/metadata/api/v1/config/versions [get]
+func (h HttpHandler) ListConfigVersions(ctx echo.Context) error {
+	kind := models.ConfigKind(ctx.QueryParam("kind"))
+	if kind != "" && !kind.IsValid() {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid kind")
+	}
+	limit := 0
+	if l := ctx.QueryParam("limit"); l != "" {
+		var err error
+		limit, err = strconv.Atoi(l)
+		if err != nil || limit < 0 {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid limit")
+		}
+	}
+
+	_, span := tracer.Start(ctx.Request().Context(), "new_ListConfigVersions", trace.WithSpanKind(trace.SpanKindServer))
+	span.SetName("new_ListConfigVersions")
+
+	versions, err := h.db.ListConfigVersions(kind, ctx.QueryParam("key"), limit)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		h.logger.Error("error listing config versions", zap.Error(err))
+		return err
+	}
+	span.End()
+
+	return ctx.JSON(http.StatusOK, versions)
+}
+
+// GetConfigVersionDiff godoc
+//
+//	@Summary		Diff a configuration version
+//	@Description	Returns what the given version changed. Set compare_to to "current" to diff the version against
+//	@Description	the live value, or to another version id to diff the values the two versions set.
+//	@Security		BearerToken
+//	@Tags			metadata
+//	@Produce		json
+//	@Param			id			path		int		true	"Version ID"
+//	@Param			compare_to	query		string	false	"current or a version id"
+//	@Success		200			{object}	api.ConfigVersionDiffResponse
+//	@Router			/metadata/api/v1/config/versions/{id}/diff [get]
+func (h HttpHandler) GetConfigVersionDiff(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid version id")
+	}
+
+	version, err := h.db.GetConfigVersion(uint(id))
+	if err != nil {
+		return err
+	}
+	if version == nil {
+		return echo.NewHTTPError(http.StatusNotFound, "version not found")
+	}
+
+	resp := api.ConfigVersionDiffResponse{
+		VersionID: version.ID,
+		Kind:      string(version.Kind),
+		Key:       version.Key,
+		CompareTo: "previous",
+		From:      version.PreviousValue,
+		To:        version.Value,
+	}
+	switch compareTo := ctx.QueryParam("compare_to"); compareTo {
+	case "", "previous":
+	case "current":
+		current, err := h.db.GetCurrentConfigValue(version.Kind, version.Key)
+		if err != nil {
+			return err
+		}
+		resp.CompareTo = compareTo
+		resp.From = version.Value
+		resp.To = current
+	default:
+		otherID, err := strconv.ParseUint(compareTo, 10, 64)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "compare_to must be previous, current or a version id")
+		}
+		other, err := h.db.GetConfigVersion(uint(otherID))
+		if err != nil {
+			return err
+		}
+		if other == nil {
+			return echo.NewHTTPError(http.StatusNotFound, "version to compare to not found")
+		}
+		if other.Kind != version.Kind || other.Key != version.Key {
+			return echo.NewHTTPError(http.StatusBadRequest, "versions belong to different keys")
+		}
+		resp.CompareTo = compareTo
+		resp.From = version.Value
+		resp.To = other.Value
+	}
+	resp.Changes = src.DiffConfigValues(resp.From, resp.To)
+
+	return ctx.JSON(http.StatusOK, resp)
+}
+
+// RollbackConfigVersion godoc
+//
+//	@Summary		Roll back to a configuration version
+//	@Description	Restores the value set by the given version. The rollback is recorded as a new version.
+//	@Security		BearerToken
+//	@Tags			metadata
+//	@Produce		json
+//	@Param			id	path		int	true	"Version ID"
+//	@Success		200	{object}	models.ConfigVersion
+//	@Router			/metadata/api/v1/config/versions/{id}/rollback [post]
+func (h HttpHandler) RollbackConfigVersion(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid version id")
+	}
+
+	version, err := h.db.GetConfigVersion(uint(id))
+	if err != nil {
+		return err
+	}
+	if version == nil {
+		return echo.NewHTTPError(http.StatusNotFound, "version not found")
+	}
+	if version.Kind == models.ConfigKindMetadata {
+		key, err := models.ParseMetadataKey(version.Key)
+		if err != nil {
+			return err
+		}
+		if err := httpserver.RequireMinRole(ctx, key.GetMinAuthRole()); err != nil {
+			return err
+		}
+	}
+
+	_, span := tracer.Start(ctx.Request().Context(), "new_RollbackConfigVersion", trace.WithSpanKind(trace.SpanKindServer))
+	span.SetName("new_RollbackConfigVersion")
+
+	rollback, err := h.db.RollbackConfigVersion(version.ID, httpserver.GetUserID(ctx))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		h.logger.Error("error rolling back config version", zap.Uint("id", version.ID), zap.Error(err))
+		return configValueError(err)
+	}
+	span.AddEvent("information", trace.WithAttributes(
+		attribute.String("kind", string(version.Kind)),
+		attribute.String("key", version.Key),
+	))
+	span.End()
+
+	if rollback == nil {
+		return ctx.JSON(http.StatusOK, version)
+	}
+	return ctx.JSON(http.StatusOK, rollback)
+}
+
+// ExportConfig godoc
+//
+//	@Summary		Export workspace configuration
+//	@Description	Returns all metadata, query parameters and filters as a single YAML document
+//	@Security		BearerToken
+//	@Tags			metadata
+//	@Produce		application/yaml
+//	@Success		200	{object}	api.WorkspaceConfig
+//	@Router			/metadata/api/v1/config/export [get]
+func (h HttpHandler) ExportConfig(ctx echo.Context) error {
+	cfg, err := src.ExportWorkspaceConfig(h.db)
+	if err != nil {
+		h.logger.Error("error exporting config", zap.Error(err))
+		return err
+	}
+
+	out, err := yaml.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+
+	ctx.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="workspace-config.yaml"`)
+	return ctx.Blob(http.StatusOK, "application/yaml", out)
+}
+
+// ImportConfig godoc
+//
+//	@Summary		Import workspace configuration
+//	@Description	Applies a YAML document produced by the export endpoint in a single transaction, versioning every
+//	@Description	change. With dry_run the planned changes are returned without being applied. With prune, entries
+//	@Description	missing from the document are deleted.
+//	@Security		BearerToken
+//	@Tags			metadata
+//	@Accept			application/yaml
+//	@Produce		json
+//	@Param			dry_run	query		bool	false	"Only return the planned changes"
+//	@Param			prune	query		bool	false	"Delete entries missing from the document"
+//	@Success		200		{object}	api.ImportConfigResponse
+//	@Router			/metadata/api/v1/config/import [post]
+func (h HttpHandler) ImportConfig(ctx echo.Context) error {
+	dryRun := ctx.QueryParam("dry_run") == "true"
+	prune := ctx.QueryParam("prune") == "true"
+
+	body, err := io.ReadAll(ctx.Request().Body)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "failed to read request body")
+	}
+	var cfg api.WorkspaceConfig
+	if err := yaml.Unmarshal(body, &cfg); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("invalid configuration document: %v", err))
+	}
+
+	changes, planned, err := src.PlanWorkspaceConfigImport(h.db, cfg, prune)
+	if err != nil {
+		var httpErr *echo.HTTPError
+		if errors.As(err, &httpErr) {
+			return err
+		}
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	for _, change := range changes {
+		if change.Kind != models.ConfigKindMetadata {
+			continue
+		}
+		key, err := models.ParseMetadataKey(change.Key)
+		if err != nil {
+			return err
+		}
+		if err := httpserver.RequireMinRole(ctx, key.GetMinAuthRole()); err != nil {
+			return err
+		}
+	}
+
+	resp := api.ImportConfigResponse{
+		DryRun:  dryRun,
+		Changes: planned,
+	}
+	if resp.Changes == nil {
+		resp.Changes = []api.ConfigImportChange{}
+	}
+	if dryRun || len(changes) == 0 {
+		return ctx.JSON(http.StatusOK, resp)
+	}
+
+	_, span := tracer.Start(ctx.Request().Context(), "new_ImportConfig", trace.WithSpanKind(trace.SpanKindServer))
+	span.SetName("new_ImportConfig")
+
+	_, err = h.db.ApplyConfigChanges(changes, httpserver.GetUserID(ctx), "import")
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		h.logger.Error("error importing config", zap.Error(err))
+		return configValueError(err)
+	}
+	span.AddEvent("information", trace.WithAttributes(
+		attribute.Int("changes", len(changes)),
+	))
+	span.End()
+
+	return ctx.JSON(http.StatusOK, resp)
+}
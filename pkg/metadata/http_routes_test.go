@@ -110,6 +110,10 @@ func (s *HttpHandlerSuite) AfterTest(suiteName, testName string) {
 
 	tx := s.orm.Exec("DROP TABLE IF EXISTS config_metadata;")
 	require.NoError(tx.Error, "drop ConfigMetadata")
+	tx = s.orm.Exec("DROP TABLE IF EXISTS config_versions;")
+	require.NoError(tx.Error, "drop ConfigVersion")
+	tx = s.orm.Exec("DROP TABLE IF EXISTS filters;")
+	require.NoError(tx.Error, "drop Filter")
 }
 
 func TestHttpHandlerSuite(t *testing.T) {
@@ -176,6 +180,93 @@ func (s *HttpHandlerSuite) TestMetadataKeys() {
 		require.NotEmpty(kType)
 		role := key.GetMinAuthRole()
 		require.NotEmpty(role)
+		require.NotEmpty(key.GetSchema().Description)
 		fmt.Println(key.String() + "," + string(kType))
 	}
 }
+
+func (s *HttpHandlerSuite) TestConfigMetadataValidation() {
+	require := s.Require()
+
+	rec, err := doSimpleJSONRequest(s.router, echo.POST, "/api/v1/metadata", api.SetConfigMetadataRequest{
+		Key:   models.MetadataKeyDescribeJobInterval.String(),
+		Value: -1,
+	}, nil)
+	require.NoError(err, "request")
+	require.Equal(http.StatusBadRequest, rec.Code)
+
+	rec, err = doSimpleJSONRequest(s.router, echo.POST, "/api/v1/metadata", api.SetConfigMetadataRequest{
+		Key:   models.MetadataKeyAssetDiscoveryAWSPolicyARNs.String(),
+		Value: "arn:aws:iam::aws:policy/SecurityAudit,not-an-arn",
+	}, nil)
+	require.NoError(err, "request")
+	require.Equal(http.StatusBadRequest, rec.Code)
+
+	var schemas []models.ConfigMetadataSchema
+	rec, err = doSimpleJSONRequest(s.router, echo.GET, "/api/v1/metadata/schema", nil, &schemas)
+	require.NoError(err, "request")
+	require.Equal(http.StatusOK, rec.Code)
+	require.Len(schemas, len(models.MetadataKeys))
+}
+
+func (s *HttpHandlerSuite) TestConfigVersionRollback() {
+	require := s.Require()
+
+	key := models.MetadataKeyWorkspaceName
+	for _, value := range []string{"first", "second"} {
+		rec, err := doSimpleJSONRequest(s.router, echo.POST, "/api/v1/metadata", api.SetConfigMetadataRequest{
+			Key:   key.String(),
+			Value: value,
+		}, nil)
+		require.NoError(err, "request")
+		require.Equal(http.StatusOK, rec.Code)
+	}
+
+	var versions []models.ConfigVersion
+	rec, err := doSimpleJSONRequest(s.router, echo.GET, "/api/v1/config/versions?kind=metadata&key="+key.String(), nil, &versions)
+	require.NoError(err, "request")
+	require.Equal(http.StatusOK, rec.Code)
+	require.Len(versions, 2)
+	require.Equal("second", *versions[0].Value)
+	require.Equal("first", *versions[0].PreviousValue)
+
+	rec, err = doSimpleJSONRequest(s.router, echo.POST, fmt.Sprintf("/api/v1/config/versions/%d/rollback", versions[1].ID), nil, nil)
+	require.NoError(err, "request")
+	require.Equal(http.StatusOK, rec.Code)
+
+	response := map[string]any{}
+	rec, err = doSimpleJSONRequest(s.router, echo.GET, "/api/v1/metadata/"+key.String(), nil, &response)
+	require.NoError(err, "request")
+	require.Equal(http.StatusOK, rec.Code)
+	require.Equal("first", response["value"])
+}
+
+func (s *HttpHandlerSuite) TestFilterKeyValuesMigration() {
+	require := s.Require()
+
+	// filters written before the key values were serialized as json
+	tx := s.orm.Exec("DROP TABLE IF EXISTS filters;")
+	require.NoError(tx.Error, "drop Filter")
+	tx = s.orm.Exec("CREATE TABLE filters (name text PRIMARY KEY, key_values jsonb);")
+	require.NoError(tx.Error, "create old Filter")
+	tx = s.orm.Exec(`INSERT INTO filters (name, key_values) VALUES ('prod', '{"env": "prod"}'), ('team', '{"team": "core"}');`)
+	require.NoError(tx.Error, "insert old Filter")
+
+	err := s.handler.db.Initialize()
+	require.NoError(err, "initialize db")
+	require.False(s.orm.Migrator().HasColumn(&models.Filter{}, "key_values"))
+
+	filters, err := s.handler.db.ListFilters()
+	require.NoError(err, "list filters")
+	require.Equal([]models.Filter{
+		{Name: "prod", KeyValue: map[string]string{"env": "prod"}},
+		{Name: "team", KeyValue: map[string]string{"team": "core"}},
+	}, filters)
+
+	// migrating again leaves the filters as they are
+	err = s.handler.db.Initialize()
+	require.NoError(err, "initialize db")
+	filters, err = s.handler.db.ListFilters()
+	require.NoError(err, "list filters")
+	require.Len(filters, 2)
+}
@@ -12,12 +12,22 @@ func (db Database) upsertConfigMetadata(configMetadata models.ConfigMetadata) er
 	}).Create(&configMetadata).Error
 }
 
-func (db Database) SetConfigMetadata(cm models.ConfigMetadata) error {
-	return db.upsertConfigMetadata(models.ConfigMetadata{
-		Key:   cm.Key,
-		Type:  cm.Type,
-		Value: cm.Value,
-	})
+func (db Database) SetConfigMetadata(cm models.ConfigMetadata, actor string) error {
+	_, err := db.ApplyConfigChanges([]ConfigChange{{
+		Kind:  models.ConfigKindMetadata,
+		Key:   cm.Key.String(),
+		Value: &cm.Value,
+	}}, actor, "")
+	return err
+}
+
+func (db Database) ListConfigMetadata() ([]models.ConfigMetadata, error) {
+	var configMetadata []models.ConfigMetadata
+	err := db.orm.Order("key").Find(&configMetadata).Error
+	if err != nil {
+		return nil, err
+	}
+	return configMetadata, nil
 }
 
 func (db Database) GetConfigMetadata(key string) (models.IConfigMetadata, error) {
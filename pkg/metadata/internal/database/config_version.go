@@ -0,0 +1,230 @@
+package database
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/opengovern/opengovernance/pkg/metadata/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ConfigChange is a single versioned change to apply. A nil Value removes the entry.
+type ConfigChange struct {
+	Kind  models.ConfigKind
+	Key   string
+	Value *string
+}
+
+func (db Database) currentConfigValue(kind models.ConfigKind, key string) (*string, error) {
+	var value string
+	switch kind {
+	case models.ConfigKindMetadata:
+		var cm models.ConfigMetadata
+		err := db.orm.First(&cm, "key = ?", key).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		value = cm.Value
+	case models.ConfigKindQueryParameter:
+		qp, err := db.GetQueryParameter(key)
+		if err != nil {
+			return nil, err
+		}
+		if qp == nil {
+			return nil, nil
+		}
+		value = qp.Value
+	case models.ConfigKindFilter:
+		var filter models.Filter
+		err := db.orm.First(&filter, "name = ?", key).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		b, err := json.Marshal(filter.KeyValue)
+		if err != nil {
+			return nil, err
+		}
+		value = string(b)
+	default:
+		return nil, fmt.Errorf("unknown config kind: %s", kind)
+	}
+	return &value, nil
+}
+
+func (db Database) writeConfigValue(kind models.ConfigKind, key string, value *string) error {
+	switch kind {
+	case models.ConfigKindMetadata:
+		if value == nil {
+			return db.orm.Unscoped().Delete(&models.ConfigMetadata{}, "key = ?", key).Error
+		}
+		mk, err := models.ParseMetadataKey(key)
+		if err != nil {
+			return err
+		}
+		if err := mk.GetSchema().Validate(*value); err != nil {
+			return err
+		}
+		return db.upsertConfigMetadata(models.ConfigMetadata{
+			Key:   mk,
+			Type:  mk.GetConfigMetadataType(),
+			Value: *value,
+		})
+	case models.ConfigKindQueryParameter:
+		if value == nil {
+			return db.orm.Unscoped().Delete(&models.QueryParameter{}, "key = ?", key).Error
+		}
+		return db.upsertQueryParameter(models.QueryParameter{
+			Key:   key,
+			Value: *value,
+		})
+	case models.ConfigKindFilter:
+		if value == nil {
+			return db.orm.Unscoped().Delete(&models.Filter{}, "name = ?", key).Error
+		}
+		filter := models.Filter{Name: key}
+		if err := json.Unmarshal([]byte(*value), &filter.KeyValue); err != nil {
+			return err
+		}
+		return db.orm.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "name"}},
+			DoUpdates: clause.AssignmentColumns([]string{"key_value"}),
+		}).Create(&filter).Error
+	}
+	return fmt.Errorf("unknown config kind: %s", kind)
+}
+
+// applyConfigChange writes the change and records a version for it. Changes that
+// leave the stored value untouched are not versioned.
+func (db Database) applyConfigChange(change ConfigChange, actor, comment string) (*models.ConfigVersion, error) {
+	previous, err := db.currentConfigValue(change.Kind, change.Key)
+	if err != nil {
+		return nil, err
+	}
+	if sameConfigValue(previous, change.Value) {
+		return nil, nil
+	}
+
+	if err := db.writeConfigValue(change.Kind, change.Key, change.Value); err != nil {
+		return nil, err
+	}
+
+	version := models.ConfigVersion{
+		Kind:          change.Kind,
+		Key:           change.Key,
+		Value:         change.Value,
+		PreviousValue: previous,
+		Actor:         actor,
+		Comment:       comment,
+	}
+	if err := db.orm.Create(&version).Error; err != nil {
+		return nil, err
+	}
+	return &version, nil
+}
+
+// ApplyConfigChanges applies all changes in a single transaction, recording a version for each one.
+func (db Database) ApplyConfigChanges(changes []ConfigChange, actor, comment string) ([]models.ConfigVersion, error) {
+	var versions []models.ConfigVersion
+	err := db.orm.Transaction(func(tx *gorm.DB) error {
+		txDb := Database{orm: tx}
+		for _, change := range changes {
+			version, err := txDb.applyConfigChange(change, actor, comment)
+			if err != nil {
+				return err
+			}
+			if version != nil {
+				versions = append(versions, *version)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	db.notifyConfigChange(versions)
+	return versions, nil
+}
+
+func (db Database) ListConfigVersions(kind models.ConfigKind, key string, limit int) ([]models.ConfigVersion, error) {
+	var versions []models.ConfigVersion
+	tx := db.orm.Model(&models.ConfigVersion{})
+	if kind != "" {
+		tx = tx.Where("kind = ?", kind)
+	}
+	if key != "" {
+		tx = tx.Where("key = ?", key)
+	}
+	if limit > 0 {
+		tx = tx.Limit(limit)
+	}
+	err := tx.Order("id DESC").Find(&versions).Error
+	if err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+func (db Database) GetConfigVersion(id uint) (*models.ConfigVersion, error) {
+	var version models.ConfigVersion
+	err := db.orm.First(&version, "id = ?", id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &version, nil
+}
+
+func (db Database) GetCurrentConfigValue(kind models.ConfigKind, key string) (*string, error) {
+	return db.currentConfigValue(kind, key)
+}
+
+// RollbackConfigVersion restores the value the given version set, recording the rollback as a new version.
+func (db Database) RollbackConfigVersion(id uint, actor string) (*models.ConfigVersion, error) {
+	var result *models.ConfigVersion
+	err := db.orm.Transaction(func(tx *gorm.DB) error {
+		txDb := Database{orm: tx}
+		version, err := txDb.GetConfigVersion(id)
+		if err != nil {
+			return err
+		}
+		if version == nil {
+			return gorm.ErrRecordNotFound
+		}
+		result, err = txDb.applyConfigChange(ConfigChange{
+			Kind:  version.Kind,
+			Key:   version.Key,
+			Value: version.Value,
+		}, actor, fmt.Sprintf("rollback to version %d", version.ID))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if result != nil {
+		db.notifyConfigChange([]models.ConfigVersion{*result})
+	}
+	return result, nil
+}
+
+func (db Database) notifyConfigChange(versions []models.ConfigVersion) {
+	if db.onConfigChange != nil && len(versions) > 0 {
+		db.onConfigChange(versions)
+	}
+}
+
+func sameConfigValue(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
@@ -7,17 +7,31 @@ import (
 
 type Database struct {
 	orm *gorm.DB
+	// onConfigChange is called with the versions recorded by every committed configuration change
+	onConfigChange func([]models.ConfigVersion)
 }
 
 func NewDatabase(orm *gorm.DB) Database {
 	return Database{orm: orm}
 }
 
+func (db Database) WithConfigChangeListener(listener func([]models.ConfigVersion)) Database {
+	db.onConfigChange = listener
+	return db
+}
+
 func (db Database) Initialize() error {
-	err := db.orm.AutoMigrate(
+	err := db.migrateFilterKeyValues()
+	if err != nil {
+		return err
+	}
+
+	err = db.orm.AutoMigrate(
 		&models.ConfigMetadata{},
 		&models.QueryParameter{},
 		&models.QueryView{},
+		&models.Filter{},
+		&models.ConfigVersion{},
 	)
 	if err != nil {
 		return err
@@ -25,3 +39,26 @@ func (db Database) Initialize() error {
 
 	return nil
 }
+
+// migrateFilterKeyValues moves the key values of the filters stored in the old key_values column to the
+// json serialized key_value column.
+func (db Database) migrateFilterKeyValues() error {
+	m := db.orm.Migrator()
+	if !m.HasTable(&models.Filter{}) || !m.HasColumn(&models.Filter{}, "key_values") {
+		return nil
+	}
+	return db.orm.Transaction(func(tx *gorm.DB) error {
+		m := tx.Migrator()
+		if !m.HasColumn(&models.Filter{}, "key_value") {
+			if err := m.AddColumn(&models.Filter{}, "KeyValue"); err != nil {
+				return err
+			}
+		}
+		err := tx.Model(&models.Filter{}).Where("key_value IS NULL").
+			Update("key_value", gorm.Expr("key_values::text")).Error
+		if err != nil {
+			return err
+		}
+		return m.DropColumn(&models.Filter{}, "key_values")
+	})
+}
@@ -1,16 +1,28 @@
 package database
 
 import (
+	"encoding/json"
+
 	"github.com/opengovern/opengovernance/pkg/metadata/models"
 )
 
-func (db Database) AddFilter(filter models.Filter) error {
-	return db.orm.Model(&models.Filter{}).Create(filter).Error
+func (db Database) AddFilter(filter models.Filter, actor string) error {
+	keyValue, err := json.Marshal(filter.KeyValue)
+	if err != nil {
+		return err
+	}
+	value := string(keyValue)
+	_, err = db.ApplyConfigChanges([]ConfigChange{{
+		Kind:  models.ConfigKindFilter,
+		Key:   filter.Name,
+		Value: &value,
+	}}, actor, "")
+	return err
 }
 
 func (db Database) ListFilters() ([]models.Filter, error) {
 	var filters []models.Filter
-	err := db.orm.Model(&models.Filter{}).First(&filters).Error
+	err := db.orm.Model(&models.Filter{}).Order("name").Find(&filters).Error
 	if err != nil {
 		return nil, err
 	}
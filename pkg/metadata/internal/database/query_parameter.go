@@ -14,22 +14,27 @@ func (db Database) upsertQueryParameter(queryParam models.QueryParameter) error
 	}).Create(&queryParam).Error
 }
 
-func (db Database) upsertQueryParameters(queryParam []*models.QueryParameter) error {
-	return db.orm.Clauses(clause.OnConflict{
-		Columns:   []clause.Column{{Name: "key"}},
-		DoUpdates: clause.AssignmentColumns([]string{"value"}),
-	}).Create(queryParam).Error
-}
-
-func (db Database) SetQueryParameter(key string, value string) error {
-	return db.upsertQueryParameter(models.QueryParameter{
+func (db Database) SetQueryParameter(key string, value string, actor string) error {
+	_, err := db.ApplyConfigChanges([]ConfigChange{{
+		Kind:  models.ConfigKindQueryParameter,
 		Key:   key,
-		Value: value,
-	})
+		Value: &value,
+	}}, actor, "")
+	return err
 }
 
-func (db Database) SetQueryParameters(queryParams []*models.QueryParameter) error {
-	return db.upsertQueryParameters(queryParams)
+func (db Database) SetQueryParameters(queryParams []*models.QueryParameter, actor string) error {
+	changes := make([]ConfigChange, 0, len(queryParams))
+	for _, qp := range queryParams {
+		value := qp.Value
+		changes = append(changes, ConfigChange{
+			Kind:  models.ConfigKindQueryParameter,
+			Key:   qp.Key,
+			Value: &value,
+		})
+	}
+	_, err := db.ApplyConfigChanges(changes, actor, "")
+	return err
 }
 
 func (db Database) GetQueryParameter(key string) (*models.QueryParameter, error) {
@@ -46,13 +51,17 @@ func (db Database) GetQueryParameter(key string) (*models.QueryParameter, error)
 
 func (db Database) GetQueryParameters() ([]models.QueryParameter, error) {
 	var queryParams []models.QueryParameter
-	err := db.orm.Find(&queryParams).Error
+	err := db.orm.Order("key").Find(&queryParams).Error
 	if err != nil {
 		return nil, err
 	}
 	return queryParams, nil
 }
 
-func (db Database) DeleteQueryParameter(key string) error {
-	return db.orm.Unscoped().Delete(&models.QueryParameter{}, "key = ?", key).Error
+func (db Database) DeleteQueryParameter(key string, actor string) error {
+	_, err := db.ApplyConfigChanges([]ConfigChange{{
+		Kind: models.ConfigKindQueryParameter,
+		Key:  key,
+	}}, actor, "")
+	return err
 }
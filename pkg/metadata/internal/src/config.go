@@ -0,0 +1,245 @@
+package src
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/opengovern/opengovernance/pkg/metadata/api"
+	"github.com/opengovern/opengovernance/pkg/metadata/internal/database"
+	"github.com/opengovern/opengovernance/pkg/metadata/models"
+)
+
+// ExportWorkspaceConfig returns the current metadata, query parameters and filters of the workspace.
+func ExportWorkspaceConfig(db database.Database) (*api.WorkspaceConfig, error) {
+	cfg := api.WorkspaceConfig{
+		Metadata:        map[string]any{},
+		QueryParameters: map[string]string{},
+		Filters:         map[string]map[string]string{},
+	}
+
+	metadata, err := db.ListConfigMetadata()
+	if err != nil {
+		return nil, err
+	}
+	for _, cm := range metadata {
+		value, err := cm.Type.DeserializeValue(cm.Value)
+		if err != nil {
+			return nil, fmt.Errorf("metadata %s: %w", cm.Key, err)
+		}
+		cfg.Metadata[cm.Key.String()] = value
+	}
+
+	queryParams, err := db.GetQueryParameters()
+	if err != nil {
+		return nil, err
+	}
+	for _, qp := range queryParams {
+		cfg.QueryParameters[qp.Key] = qp.Value
+	}
+
+	filters, err := db.ListFilters()
+	if err != nil {
+		return nil, err
+	}
+	for _, filter := range filters {
+		cfg.Filters[filter.Name] = filter.KeyValue
+	}
+
+	return &cfg, nil
+}
+
+// PlanWorkspaceConfigImport validates the given configuration and returns the changes needed to make the
+// workspace match it. When prune is set, entries missing from the configuration are deleted.
+func PlanWorkspaceConfigImport(db database.Database, cfg api.WorkspaceConfig, prune bool) ([]database.ConfigChange, []api.ConfigImportChange, error) {
+	current, err := ExportWorkspaceConfig(db)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var changes []database.ConfigChange
+	var planned []api.ConfigImportChange
+	plan := func(kind models.ConfigKind, key string, value *string) error {
+		from, err := db.GetCurrentConfigValue(kind, key)
+		if err != nil {
+			return err
+		}
+		var action api.ConfigImportAction
+		switch {
+		case from == nil && value == nil:
+			return nil
+		case from == nil:
+			action = api.ConfigImportActionCreate
+		case value == nil:
+			action = api.ConfigImportActionDelete
+		case *from == *value:
+			return nil
+		default:
+			action = api.ConfigImportActionUpdate
+		}
+		changes = append(changes, database.ConfigChange{Kind: kind, Key: key, Value: value})
+		planned = append(planned, api.ConfigImportChange{
+			Kind:   string(kind),
+			Key:    key,
+			Action: action,
+			From:   from,
+			To:     value,
+		})
+		return nil
+	}
+
+	for _, key := range sortedKeys(cfg.Metadata) {
+		mk, err := models.ParseMetadataKey(key)
+		if err != nil {
+			return nil, nil, fmt.Errorf("metadata %s: %w", key, err)
+		}
+		typ := mk.GetConfigMetadataType()
+		value, err := typ.SerializeValue(normalizeImportedValue(typ, cfg.Metadata[key]))
+		if err != nil {
+			return nil, nil, fmt.Errorf("metadata %s: %w", key, err)
+		}
+		if err := mk.GetSchema().Validate(value); err != nil {
+			return nil, nil, err
+		}
+		if err := plan(models.ConfigKindMetadata, mk.String(), &value); err != nil {
+			return nil, nil, err
+		}
+	}
+	for _, key := range sortedKeys(cfg.QueryParameters) {
+		value := cfg.QueryParameters[key]
+		if err := plan(models.ConfigKindQueryParameter, key, &value); err != nil {
+			return nil, nil, err
+		}
+	}
+	for _, name := range sortedKeys(cfg.Filters) {
+		keyValue, err := json.Marshal(cfg.Filters[name])
+		if err != nil {
+			return nil, nil, err
+		}
+		value := string(keyValue)
+		if err := plan(models.ConfigKindFilter, name, &value); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if prune {
+		for _, key := range sortedKeys(current.Metadata) {
+			if _, ok := cfg.Metadata[key]; !ok {
+				if err := plan(models.ConfigKindMetadata, key, nil); err != nil {
+					return nil, nil, err
+				}
+			}
+		}
+		for _, key := range sortedKeys(current.QueryParameters) {
+			if _, ok := cfg.QueryParameters[key]; !ok {
+				if err := plan(models.ConfigKindQueryParameter, key, nil); err != nil {
+					return nil, nil, err
+				}
+			}
+		}
+		for _, name := range sortedKeys(current.Filters) {
+			if _, ok := cfg.Filters[name]; !ok {
+				if err := plan(models.ConfigKindFilter, name, nil); err != nil {
+					return nil, nil, err
+				}
+			}
+		}
+	}
+
+	return changes, planned, nil
+}
+
+// normalizeImportedValue converts numbers decoded from YAML or JSON into the int the metadata type expects.
+func normalizeImportedValue(typ models.ConfigMetadataType, value any) any {
+	if typ != models.ConfigMetadataTypeInt {
+		return value
+	}
+	switch v := value.(type) {
+	case int64:
+		return int(v)
+	case uint64:
+		return int(v)
+	case float64:
+		if v == math.Trunc(v) {
+			return int(v)
+		}
+	}
+	return value
+}
+
+// DiffConfigValues describes the change between two stored values. Values holding JSON objects, such as
+// filters and JSON metadata, are compared key by key; anything else is reported as a single change.
+func DiffConfigValues(from, to *string) []api.ConfigValueChange {
+	changes := []api.ConfigValueChange{}
+	if from != nil && to != nil && *from == *to {
+		return changes
+	}
+	if from == nil && to == nil {
+		return changes
+	}
+
+	fromObj, fromOk := parseConfigObject(from)
+	toObj, toOk := parseConfigObject(to)
+	if fromOk && toOk {
+		keys := map[string]bool{}
+		for k := range fromObj {
+			keys[k] = true
+		}
+		for k := range toObj {
+			keys[k] = true
+		}
+		for _, k := range sortedKeys(keys) {
+			fromValue, inFrom := fromObj[k]
+			toValue, inTo := toObj[k]
+			if inFrom && inTo && jsonEqual(fromValue, toValue) {
+				continue
+			}
+			changes = append(changes, api.ConfigValueChange{Path: k, From: fromValue, To: toValue})
+		}
+		return changes
+	}
+
+	var fromValue, toValue any
+	if from != nil {
+		fromValue = *from
+	}
+	if to != nil {
+		toValue = *to
+	}
+	return append(changes, api.ConfigValueChange{From: fromValue, To: toValue})
+}
+
+// parseConfigObject treats a missing value as an empty object so that created and deleted
+// filters are still diffed key by key.
+func parseConfigObject(value *string) (map[string]any, bool) {
+	if value == nil {
+		return map[string]any{}, true
+	}
+	var obj map[string]any
+	if err := json.Unmarshal([]byte(*value), &obj); err != nil || obj == nil {
+		return nil, false
+	}
+	return obj, true
+}
+
+func jsonEqual(a, b any) bool {
+	aj, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	bj, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	return string(aj) == string(bj)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
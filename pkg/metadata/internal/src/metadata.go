@@ -45,7 +45,7 @@ func GetConfigMetadata(db database.Database, key string) (models.IConfigMetadata
 	return typedCm, nil
 }
 
-func SetConfigMetadata(db database.Database, key models.MetadataKey, value any) error {
+func SetConfigMetadata(db database.Database, key models.MetadataKey, value any, actor string) error {
 	valueStr, err := key.GetConfigMetadataType().SerializeValue(value)
 	if err != nil {
 		return err
@@ -54,7 +54,7 @@ func SetConfigMetadata(db database.Database, key models.MetadataKey, value any)
 		Key:   key,
 		Type:  key.GetConfigMetadataType(),
 		Value: valueStr,
-	})
+	}, actor)
 	if err != nil {
 		return err
 	}
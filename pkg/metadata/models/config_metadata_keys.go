@@ -39,15 +39,15 @@ const (
 	MetadataKeyWorkspaceMaxKeys         MetadataKey = "workspace_max_keys"
 	MetadataKeyAllowedEmailDomains      MetadataKey = "allowed_email_domains"
 	MetadataKeyAutoDiscoveryMethod      MetadataKey = "auto_discovery_method"
-	// MetadataKeyDescribeJobInterval is the interval in minutes for describe job
+	// MetadataKeyDescribeJobInterval is the interval in hours for describe job
 	MetadataKeyDescribeJobInterval MetadataKey = "describe_job_interval"
-	// MetadataKeyFullDiscoveryJobInterval is the interval in minutes for full describe job
+	// MetadataKeyFullDiscoveryJobInterval is the interval in hours for full describe job
 	MetadataKeyFullDiscoveryJobInterval MetadataKey = "full_discovery_job_interval"
-	// MetadataKeyCostDiscoveryJobInterval is the interval in minutes for cost describe job
+	// MetadataKeyCostDiscoveryJobInterval is the interval in hours for cost describe job
 	MetadataKeyCostDiscoveryJobInterval MetadataKey = "cost_discovery_job_interval"
 	// MetadataKeyHealthCheckJobInterval is the interval in minutes for health check job
 	MetadataKeyHealthCheckJobInterval MetadataKey = "health_check_job_interval"
-	// MetadataKeyMetricsJobInterval is the interval in minutes for metrics job
+	// MetadataKeyMetricsJobInterval is the interval in hours for metrics job
 	MetadataKeyMetricsJobInterval    MetadataKey = "metrics_job_interval"
 	MetadataKeyComplianceJobInterval MetadataKey = "compliance_job_interval"
 	// MetadataKeyDataRetention retention period in days
@@ -0,0 +1,169 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/opengovern/og-util/pkg/api"
+)
+
+// ErrInvalidConfigValue is wrapped by the errors of values that do not match the schema of their key.
+var ErrInvalidConfigValue = errors.New("invalid value")
+
+// ConfigMetadataSchema describes the value a metadata key accepts. Type and MinAuthRole mirror
+// GetConfigMetadataType and GetMinAuthRole; the rest comes from configMetadataSchemas.
+type ConfigMetadataSchema struct {
+	Key         MetadataKey        `json:"key"`
+	Type        ConfigMetadataType `json:"type"`
+	Description string             `json:"description"`
+	Default     any                `json:"default,omitempty"`
+	Min         *int               `json:"min,omitempty"`
+	Max         *int               `json:"max,omitempty"`
+	Enum        []string           `json:"enum,omitempty"`
+	Pattern     string             `json:"pattern,omitempty"`
+	// ListItemPattern is matched against every item of a comma separated string value
+	ListItemPattern string   `json:"listItemPattern,omitempty"`
+	MinAuthRole     api.Role `json:"minAuthRole"`
+	RequiresRestart bool     `json:"requiresRestart"`
+}
+
+func intPtr(v int) *int {
+	return &v
+}
+
+const (
+	awsPolicyARNPattern = `^arn:aws[a-z-]*:iam::(aws|\d{12}):policy/[\w+=,.@/-]+$`
+	azureRoleIDPattern  = `^(/.*/roleDefinitions/)?[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`
+)
+
+var configMetadataSchemas = map[MetadataKey]ConfigMetadataSchema{
+	MetadataKeyWorkspaceOwnership:       {Description: "ID of the user owning the workspace", RequiresRestart: true},
+	MetadataKeyWorkspaceID:              {Description: "ID of the workspace", RequiresRestart: true},
+	MetadataKeyWorkspaceName:            {Description: "Name of the workspace", RequiresRestart: true},
+	MetadataKeyWorkspacePlan:            {Description: "Plan of the workspace"},
+	MetadataKeyWorkspaceCreationTime:    {Description: "Creation time of the workspace in unix milliseconds"},
+	MetadataKeyWorkspaceDateTimeFormat:  {Description: "Date and time format used when displaying dates"},
+	MetadataKeyWorkspaceDebugMode:       {Description: "Enables debug information in the UI", Default: false},
+	MetadataKeyWorkspaceTimeWindow:      {Description: "Default time window of the dashboards"},
+	MetadataKeyAssetManagementEnabled:   {Description: "Enables asset management", Default: true},
+	MetadataKeyComplianceEnabled:        {Description: "Enables compliance", Default: true},
+	MetadataKeyProductManagementEnabled: {Description: "Enables product management", Default: true},
+	MetadataKeyCustomIDP:                {Description: "Custom identity provider of the workspace", RequiresRestart: true},
+	MetadataKeyResourceLimit:            {Description: "Maximum number of resources the workspace can discover", Min: intPtr(0)},
+	MetadataKeyConnectionLimit:          {Description: "Maximum number of integrations the workspace can have", Min: intPtr(0)},
+	MetadataKeyUserLimit:                {Description: "Maximum number of users the workspace can have", Min: intPtr(0)},
+	MetadataKeyAllowInvite:              {Description: "Allows admins to invite users", Default: true},
+	MetadataKeyWorkspaceKeySupport:      {Description: "Allows users to create API keys", Default: true},
+	MetadataKeyWorkspaceMaxKeys:         {Description: "Maximum number of API keys per workspace", Min: intPtr(0)},
+	MetadataKeyAllowedEmailDomains:      {Description: "Email domains users may be invited or provisioned from, as a JSON list"},
+	MetadataKeyAutoDiscoveryMethod:      {Description: "Method used to discover new integrations automatically"},
+	MetadataKeyDescribeJobInterval:      {Description: "Interval in hours between discovery jobs, 0 disables them", Min: intPtr(0), Max: intPtr(24 * 30)},
+	MetadataKeyFullDiscoveryJobInterval: {Description: "Interval in hours between full discovery jobs, 0 disables them", Min: intPtr(0), Max: intPtr(24 * 30)},
+	MetadataKeyCostDiscoveryJobInterval: {Description: "Interval in hours between cost discovery jobs, 0 disables them", Min: intPtr(0), Max: intPtr(24 * 30)},
+	MetadataKeyHealthCheckJobInterval:   {Description: "Interval in minutes between integration health checks", Min: intPtr(1), Max: intPtr(24 * 60)},
+	MetadataKeyMetricsJobInterval:       {Description: "Interval in hours between analytics jobs, 0 disables them", Min: intPtr(0), Max: intPtr(24 * 30)},
+	MetadataKeyComplianceJobInterval:    {Description: "Interval in hours between compliance jobs, 0 disables them", Min: intPtr(0), Max: intPtr(24 * 30)},
+	MetadataKeyDataRetention:            {Description: "Retention period in days of findings, job results and spend", Default: 366, Min: intPtr(1), Max: intPtr(3650)},
+	MetadataKeyAnalyticsGitURL:          {Description: "Git repository the analytics and compliance content is loaded from", Pattern: `^(https?|git|ssh)://\S+$|^git@\S+:\S+$`, RequiresRestart: true},
+	MetadataKeyAssetDiscoveryAWSPolicyARNs: {
+		Description:     "Comma separated ARNs of the IAM policies required for AWS asset discovery",
+		ListItemPattern: awsPolicyARNPattern,
+	},
+	MetadataKeySpendDiscoveryAWSPolicyARNs: {
+		Description:     "Comma separated ARNs of the IAM policies required for AWS spend discovery",
+		ListItemPattern: awsPolicyARNPattern,
+	},
+	MetadataKeyAssetDiscoveryAzureRoleIDs: {
+		Description:     "Comma separated IDs of the Azure role definitions required for asset discovery",
+		ListItemPattern: azureRoleIDPattern,
+	},
+	MetadataKeySpendDiscoveryAzureRoleIDs: {
+		Description:     "Comma separated IDs of the Azure role definitions required for spend discovery",
+		ListItemPattern: azureRoleIDPattern,
+	},
+	MetadataKeyCustomizationEnabled:       {Description: "Allows customizing the workspace content", Default: false},
+	MetadataKeyAWSDiscoveryRequiredOnly:   {Description: "Only discovers the AWS resource types required by enabled benchmarks", Default: false},
+	MetadataKeyAzureDiscoveryRequiredOnly: {Description: "Only discovers the Azure resource types required by enabled benchmarks", Default: false},
+	MetadataKeyAssetDiscoveryEnabled:      {Description: "Enables asset discovery", Default: true},
+	MetadataKeySpendDiscoveryEnabled:      {Description: "Enables spend discovery", Default: true},
+}
+
+func (k MetadataKey) GetSchema() ConfigMetadataSchema {
+	schema := configMetadataSchemas[k]
+	schema.Key = k
+	schema.Type = k.GetConfigMetadataType()
+	schema.MinAuthRole = k.GetMinAuthRole()
+	return schema
+}
+
+func ListConfigMetadataSchemas() []ConfigMetadataSchema {
+	schemas := make([]ConfigMetadataSchema, 0, len(MetadataKeys))
+	for _, k := range MetadataKeys {
+		schemas = append(schemas, k.GetSchema())
+	}
+	return schemas
+}
+
+// Validate checks a serialized value against the schema and returns an error wrapping ErrInvalidConfigValue
+// describing the first violated constraint.
+func (s ConfigMetadataSchema) Validate(value string) error {
+	if err := s.validate(value); err != nil {
+		return fmt.Errorf("%w for %s: %s", ErrInvalidConfigValue, s.Key, err.Error())
+	}
+	return nil
+}
+
+func (s ConfigMetadataSchema) validate(value string) error {
+	switch s.Type {
+	case ConfigMetadataTypeInt:
+		v, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%q is not an integer", value)
+		}
+		if s.Min != nil && v < *s.Min {
+			return fmt.Errorf("must be at least %d", *s.Min)
+		}
+		if s.Max != nil && v > *s.Max {
+			return fmt.Errorf("must be at most %d", *s.Max)
+		}
+	case ConfigMetadataTypeBool:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("%q is not a boolean", value)
+		}
+	case ConfigMetadataTypeJSON:
+		if !json.Valid([]byte(value)) {
+			return fmt.Errorf("malformed JSON")
+		}
+	case ConfigMetadataTypeString:
+		if len(s.Enum) > 0 {
+			found := false
+			for _, e := range s.Enum {
+				if value == e {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return fmt.Errorf("must be one of %s", strings.Join(s.Enum, ", "))
+			}
+		}
+		if s.Pattern != "" && !regexp.MustCompile(s.Pattern).MatchString(value) {
+			return fmt.Errorf("must match %s", s.Pattern)
+		}
+		if s.ListItemPattern != "" && value != "" {
+			re := regexp.MustCompile(s.ListItemPattern)
+			for _, item := range strings.Split(value, ",") {
+				if !re.MatchString(item) {
+					return fmt.Errorf("%q must match %s", item, s.ListItemPattern)
+				}
+			}
+		}
+	default:
+		return fmt.Errorf("unknown type %s", s.Type)
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+type ConfigKind string
+
+const (
+	ConfigKindMetadata       ConfigKind = "metadata"
+	ConfigKindQueryParameter ConfigKind = "query_parameter"
+	ConfigKindFilter         ConfigKind = "filter"
+)
+
+func (k ConfigKind) IsValid() bool {
+	switch k {
+	case ConfigKindMetadata, ConfigKindQueryParameter, ConfigKindFilter:
+		return true
+	}
+	return false
+}
+
+// ConfigVersion is an immutable record of a single change to a metadata key, query parameter or filter.
+// Value is nil when the change removed the entry and PreviousValue is nil when it created it.
+type ConfigVersion struct {
+	ID            uint       `json:"id" gorm:"primaryKey"`
+	Kind          ConfigKind `json:"kind" gorm:"index:idx_config_version_kind_key"`
+	Key           string     `json:"key" gorm:"index:idx_config_version_kind_key"`
+	Value         *string    `json:"value" gorm:"type:text"`
+	PreviousValue *string    `json:"previousValue" gorm:"type:text"`
+	Actor         string     `json:"actor"`
+	Comment       string     `json:"comment"`
+	CreatedAt     time.Time  `json:"createdAt" gorm:"index"`
+}
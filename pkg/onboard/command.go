@@ -6,6 +6,7 @@ import (
 	"github.com/opengovern/og-util/pkg/httpserver"
 	"github.com/opengovern/og-util/pkg/koanf"
 	"github.com/opengovern/og-util/pkg/vault"
+	"github.com/opengovern/opengovernance/pkg/auth/audit"
 	"github.com/opengovern/opengovernance/pkg/onboard/config"
 	"os"
 
@@ -74,6 +75,7 @@ func start(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("init http handler: %w", err)
 	}
+	handler.auditRecorder = audit.NewServiceRecorder(ctx, logger, "onboard", cfg.Auth.BaseURL)
 
 	return httpserver.RegisterAndStart(ctx, logger, cfg.Http.Address, handler)
 }
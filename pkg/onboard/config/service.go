@@ -12,6 +12,7 @@ type OnboardConfig struct {
 	Metadata        koanf.KaytuService `json:"metadata,omitempty" koanf:"metadata"`
 	Inventory       koanf.KaytuService `json:"inventory,omitempty" koanf:"inventory"`
 	Describe        koanf.KaytuService `json:"describe,omitempty" koanf:"describe"`
+	Auth            koanf.KaytuService `json:"auth,omitempty" koanf:"auth"`
 	Vault           vault.Config       `json:"vault,omitempty" koanf:"vault"`
 	MasterAccessKey string             `json:"master_access_key,omitempty" koanf:"master_access_key"`
 	MasterSecretKey string             `json:"master_secret_key,omitempty" koanf:"master_secret_key"`
@@ -6,6 +6,7 @@ import (
 	"github.com/opengovern/og-util/pkg/postgres"
 	"github.com/opengovern/og-util/pkg/steampipe"
 	"github.com/opengovern/og-util/pkg/vault"
+	"github.com/opengovern/opengovernance/pkg/auth/audit"
 	describeClient "github.com/opengovern/opengovernance/pkg/describe/client"
 	metadataClient "github.com/opengovern/opengovernance/pkg/metadata/client"
 	"github.com/opengovern/opengovernance/pkg/onboard/db"
@@ -27,6 +28,7 @@ type HttpHandler struct {
 	vaultKeyId                       string
 	logger                           *zap.Logger
 	masterAccessKey, masterSecretKey string
+	auditRecorder                    *audit.Recorder
 }
 
 func InitializeHttpHandler(
@@ -11,6 +11,7 @@ import (
 	api3 "github.com/opengovern/og-util/pkg/api"
 	"github.com/opengovern/og-util/pkg/httpclient"
 	"github.com/opengovern/og-util/pkg/httpserver"
+	"github.com/opengovern/opengovernance/pkg/auth/audit"
 	"github.com/opengovern/opengovernance/pkg/demo"
 	"github.com/opengovern/opengovernance/pkg/describe/connectors"
 	"github.com/opengovern/opengovernance/pkg/metadata/models"
@@ -53,6 +54,8 @@ const (
 var tracer = otel.Tracer("onboard")
 
 func (h HttpHandler) Register(r *echo.Echo) {
+	r.Use(audit.Middleware(h.auditRecorder))
+
 	v1 := r.Group("/api/v1")
 	v2 := r.Group("/api/v2")
 
@@ -0,0 +1,149 @@
+package kaytu_client
+
+import (
+	"context"
+	"runtime"
+
+	es "github.com/opengovern/og-util/pkg/opengovernance-es-sdk"
+	"github.com/opengovern/og-util/pkg/source"
+	"github.com/opengovern/opengovernance/pkg/steampipe-plugin-kaytu/kaytu-sdk/config"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+const (
+	ResourceEdgesIndex = "resource_edges"
+)
+
+type ResourceEdge struct {
+	// FromResourceID is the resource the relationship starts from.
+	FromResourceID string `json:"from_resource_id"`
+	// FromResourceType is the type of the resource the relationship starts from.
+	FromResourceType string `json:"from_resource_type"`
+	// ToResourceID is the resource the relationship points to.
+	ToResourceID string `json:"to_resource_id"`
+	// ToResourceType is the type of the resource the relationship points to.
+	ToResourceType string `json:"to_resource_type"`
+	// Relation is one of contains, attached-to, uses-role, in-network and routes-to.
+	Relation string `json:"relation"`
+	// SourceID is the aws account id or azure subscription id of the resource the edge was discovered on
+	SourceID string `json:"source_id"`
+	// SourceType is the type of the source of the resource, i.e. AWS Cloud, Azure Cloud.
+	SourceType source.Type `json:"source_type"`
+	// ResourceJobID is the DescribeResourceJob ID that described the resource the edge was discovered on
+	ResourceJobID uint `json:"resource_job_id"`
+	// CreatedAt is when the DescribeSourceJob is created
+	CreatedAt int64 `json:"created_at"`
+}
+
+type ResourceEdgeHit struct {
+	ID      string       `json:"_id"`
+	Score   float64      `json:"_score"`
+	Index   string       `json:"_index"`
+	Type    string       `json:"_type"`
+	Version int64        `json:"_version,omitempty"`
+	Source  ResourceEdge `json:"_source"`
+	Sort    []any        `json:"sort"`
+}
+
+type ResourceEdgeHits struct {
+	Total es.SearchTotal    `json:"total"`
+	Hits  []ResourceEdgeHit `json:"hits"`
+}
+
+type ResourceEdgeSearchResponse struct {
+	PitID string           `json:"pit_id"`
+	Hits  ResourceEdgeHits `json:"hits"`
+}
+
+type ResourceEdgePaginator struct {
+	paginator *es.BaseESPaginator
+}
+
+func (k Client) NewResourceEdgePaginator(filters []es.BoolFilter, limit *int64) (ResourceEdgePaginator, error) {
+	paginator, err := es.NewPaginator(k.ES.ES(), ResourceEdgesIndex, filters, limit)
+	if err != nil {
+		return ResourceEdgePaginator{}, err
+	}
+
+	p := ResourceEdgePaginator{
+		paginator: paginator,
+	}
+
+	return p, nil
+}
+
+func (p ResourceEdgePaginator) HasNext() bool {
+	return !p.paginator.Done()
+}
+
+func (p ResourceEdgePaginator) Close(ctx context.Context) error {
+	return p.paginator.Deallocate(ctx)
+}
+
+func (p ResourceEdgePaginator) NextPage(ctx context.Context) ([]ResourceEdge, error) {
+	var response ResourceEdgeSearchResponse
+	err := p.paginator.SearchWithLog(ctx, &response, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var values []ResourceEdge
+	for _, hit := range response.Hits.Hits {
+		values = append(values, hit.Source)
+	}
+
+	hits := int64(len(response.Hits.Hits))
+	if hits > 0 {
+		p.paginator.UpdateState(hits, response.Hits.Hits[hits-1].Sort, response.PitID)
+	} else {
+		p.paginator.UpdateState(hits, nil, "")
+	}
+
+	return values, nil
+}
+
+var resourceEdgeMapping = map[string]string{
+	"connector":     "source_type",
+	"connection_id": "source_id",
+}
+
+func ListResourceEdges(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (any, error) {
+	plugin.Logger(ctx).Trace("ListResourceEdges", d)
+	runtime.GC()
+	// create service
+	cfg := config.GetConfig(d.Connection)
+	ke, err := config.NewClientCached(cfg, d.ConnectionCache, ctx)
+	if err != nil {
+		plugin.Logger(ctx).Error("ListResourceEdges NewClientCached", "error", err)
+		return nil, err
+	}
+	k := Client{ES: ke}
+
+	paginator, err := k.NewResourceEdgePaginator(
+		es.BuildFilterWithDefaultFieldName(ctx, d.QueryContext, resourceEdgeMapping,
+			"", nil, nil, nil, true),
+		d.QueryContext.Limit)
+	if err != nil {
+		plugin.Logger(ctx).Error("ListResourceEdges NewResourceEdgePaginator", "error", err)
+		return nil, err
+	}
+
+	for paginator.HasNext() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			plugin.Logger(ctx).Error("ListResourceEdges NextPage", "error", err)
+			return nil, err
+		}
+
+		for _, v := range page {
+			d.StreamListItem(ctx, v)
+		}
+	}
+
+	err = paginator.Close(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,155 @@
+package kaytu_client
+
+import (
+	"context"
+	"runtime"
+
+	es "github.com/opengovern/og-util/pkg/opengovernance-es-sdk"
+	"github.com/opengovern/og-util/pkg/source"
+	"github.com/opengovern/opengovernance/pkg/steampipe-plugin-kaytu/kaytu-sdk/config"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+const (
+	TagPolicyResultsIndex = "tag_policy_results"
+)
+
+type TagPolicyResult struct {
+	// PolicyID is the id of the tag policy the resource was evaluated against.
+	PolicyID string `json:"policy_id"`
+	// ResourceID is the globally unique ID of the resource.
+	ResourceID string `json:"resource_id"`
+	// ResourceName is the name of the resource.
+	ResourceName string `json:"resource_name"`
+	// ResourceType is the type of the resource.
+	ResourceType string `json:"resource_type"`
+	// ConnectionID is the aws account id or azure subscription id of the resource
+	ConnectionID string `json:"connection_id"`
+	// Connector is the type of the source of the resource, i.e. AWS Cloud, Azure Cloud.
+	Connector source.Type `json:"connector"`
+	// Location is location/region of the resource
+	Location string `json:"location"`
+	// Owner is the value of the owner tag of the resource
+	Owner string `json:"owner"`
+	// Compliant is true when the tags of the resource follow the policy
+	Compliant bool `json:"compliant"`
+	// Reason lists the violations of the resource
+	Reason        string   `json:"reason"`
+	Violations    []string `json:"violations"`
+	ViolatingKeys []string `json:"violating_keys"`
+	Suggestions   []string `json:"suggestions"`
+	// JobID is the analytics job that evaluated the resource
+	JobID uint `json:"job_id"`
+	// EvaluatedAt is when the resource was evaluated
+	EvaluatedAt int64 `json:"evaluated_at"`
+}
+
+type TagPolicyResultHit struct {
+	ID      string          `json:"_id"`
+	Score   float64         `json:"_score"`
+	Index   string          `json:"_index"`
+	Type    string          `json:"_type"`
+	Version int64           `json:"_version,omitempty"`
+	Source  TagPolicyResult `json:"_source"`
+	Sort    []any           `json:"sort"`
+}
+
+type TagPolicyResultHits struct {
+	Total es.SearchTotal       `json:"total"`
+	Hits  []TagPolicyResultHit `json:"hits"`
+}
+
+type TagPolicyResultSearchResponse struct {
+	PitID string              `json:"pit_id"`
+	Hits  TagPolicyResultHits `json:"hits"`
+}
+
+type TagPolicyResultPaginator struct {
+	paginator *es.BaseESPaginator
+}
+
+func (k Client) NewTagPolicyResultPaginator(filters []es.BoolFilter, limit *int64) (TagPolicyResultPaginator, error) {
+	paginator, err := es.NewPaginator(k.ES.ES(), TagPolicyResultsIndex, filters, limit)
+	if err != nil {
+		return TagPolicyResultPaginator{}, err
+	}
+
+	p := TagPolicyResultPaginator{
+		paginator: paginator,
+	}
+
+	return p, nil
+}
+
+func (p TagPolicyResultPaginator) HasNext() bool {
+	return !p.paginator.Done()
+}
+
+func (p TagPolicyResultPaginator) Close(ctx context.Context) error {
+	return p.paginator.Deallocate(ctx)
+}
+
+func (p TagPolicyResultPaginator) NextPage(ctx context.Context) ([]TagPolicyResult, error) {
+	var response TagPolicyResultSearchResponse
+	err := p.paginator.SearchWithLog(ctx, &response, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var values []TagPolicyResult
+	for _, hit := range response.Hits.Hits {
+		values = append(values, hit.Source)
+	}
+
+	hits := int64(len(response.Hits.Hits))
+	if hits > 0 {
+		p.paginator.UpdateState(hits, response.Hits.Hits[hits-1].Sort, response.PitID)
+	} else {
+		p.paginator.UpdateState(hits, nil, "")
+	}
+
+	return values, nil
+}
+
+var tagPolicyResultMapping = map[string]string{}
+
+func ListTagPolicyResults(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (any, error) {
+	plugin.Logger(ctx).Trace("ListTagPolicyResults", d)
+	runtime.GC()
+	// create service
+	cfg := config.GetConfig(d.Connection)
+	ke, err := config.NewClientCached(cfg, d.ConnectionCache, ctx)
+	if err != nil {
+		plugin.Logger(ctx).Error("ListTagPolicyResults NewClientCached", "error", err)
+		return nil, err
+	}
+	k := Client{ES: ke}
+
+	paginator, err := k.NewTagPolicyResultPaginator(
+		es.BuildFilterWithDefaultFieldName(ctx, d.QueryContext, tagPolicyResultMapping,
+			"", nil, nil, nil, true),
+		d.QueryContext.Limit)
+	if err != nil {
+		plugin.Logger(ctx).Error("ListTagPolicyResults NewTagPolicyResultPaginator", "error", err)
+		return nil, err
+	}
+
+	for paginator.HasNext() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			plugin.Logger(ctx).Error("ListTagPolicyResults NextPage", "error", err)
+			return nil, err
+		}
+
+		for _, v := range page {
+			d.StreamListItem(ctx, v)
+		}
+	}
+
+	err = paginator.Close(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
@@ -169,6 +169,8 @@ func Plugin(ctx context.Context) *plugin.Plugin {
 			"kaytu_findings":               tableKaytuFindings(ctx),
 			"kaytu_resources":              tableKaytuResources(ctx),
 			"kaytu_lookup":                 tableKaytuLookup(ctx),
+			"kaytu_resource_edges":         tableKaytuResourceEdges(ctx),
+			"kaytu_tag_policy_results":     tableKaytuTagPolicyResults(ctx),
 			"kaytu_cost":                   tableKaytuCost(ctx),
 			"pennywise_cost_estimate":      tableKaytuCostEstimate(ctx),
 			"kaytu_connections":            tableKaytuConnections(ctx),
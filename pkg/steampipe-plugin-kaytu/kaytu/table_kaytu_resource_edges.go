@@ -0,0 +1,41 @@
+package kaytu
+
+import (
+	"context"
+
+	kaytu_client "github.com/opengovern/opengovernance/pkg/steampipe-plugin-kaytu/kaytu-client"
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+func tableKaytuResourceEdges(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "kaytu_resource_edges",
+		Description: "Kaytu Resource Relationships",
+		Cache: &plugin.TableCacheOptions{
+			Enabled: false,
+		},
+		List: &plugin.ListConfig{
+			Hydrate: kaytu_client.ListResourceEdges,
+			KeyColumns: plugin.KeyColumnSlice{
+				{Name: "from_resource_id", Require: plugin.Optional},
+				{Name: "to_resource_id", Require: plugin.Optional},
+				{Name: "relation", Require: plugin.Optional},
+				{Name: "from_resource_type", Require: plugin.Optional},
+				{Name: "to_resource_type", Require: plugin.Optional},
+				{Name: "connection_id", Require: plugin.Optional},
+			},
+		},
+		Columns: []*plugin.Column{
+			{Name: "from_resource_id", Type: proto.ColumnType_STRING, Description: "The resource the relationship starts from"},
+			{Name: "from_resource_type", Type: proto.ColumnType_STRING, Description: "The type of the resource the relationship starts from"},
+			{Name: "to_resource_id", Type: proto.ColumnType_STRING, Description: "The resource the relationship points to"},
+			{Name: "to_resource_type", Type: proto.ColumnType_STRING, Description: "The type of the resource the relationship points to"},
+			{Name: "relation", Type: proto.ColumnType_STRING, Description: "One of contains, attached-to, uses-role, in-network and routes-to"},
+			{Name: "connector", Transform: transform.FromField("SourceType"), Type: proto.ColumnType_STRING},
+			{Name: "connection_id", Transform: transform.FromField("SourceID"), Type: proto.ColumnType_STRING, Description: "The connection of the resource the relationship was discovered on"},
+			{Name: "created_at", Type: proto.ColumnType_INT},
+		},
+	}
+}
@@ -0,0 +1,47 @@
+package kaytu
+
+import (
+	"context"
+
+	kaytu_client "github.com/opengovern/opengovernance/pkg/steampipe-plugin-kaytu/kaytu-client"
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+func tableKaytuTagPolicyResults(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "kaytu_tag_policy_results",
+		Description: "Kaytu Tag Policy Evaluation Results",
+		Cache: &plugin.TableCacheOptions{
+			Enabled: false,
+		},
+		List: &plugin.ListConfig{
+			Hydrate: kaytu_client.ListTagPolicyResults,
+			KeyColumns: plugin.KeyColumnSlice{
+				{Name: "policy_id", Require: plugin.Optional},
+				{Name: "resource_id", Require: plugin.Optional},
+				{Name: "resource_type", Require: plugin.Optional},
+				{Name: "connection_id", Require: plugin.Optional},
+				{Name: "owner", Require: plugin.Optional},
+				{Name: "compliant", Require: plugin.Optional},
+			},
+		},
+		Columns: []*plugin.Column{
+			{Name: "policy_id", Type: proto.ColumnType_STRING, Description: "The tag policy the resource was evaluated against"},
+			{Name: "resource_id", Type: proto.ColumnType_STRING},
+			{Name: "resource_name", Type: proto.ColumnType_STRING},
+			{Name: "resource_type", Type: proto.ColumnType_STRING},
+			{Name: "connection_id", Type: proto.ColumnType_STRING},
+			{Name: "connector", Type: proto.ColumnType_STRING},
+			{Name: "location", Type: proto.ColumnType_STRING},
+			{Name: "owner", Type: proto.ColumnType_STRING, Description: "The value of the owner tag of the resource"},
+			{Name: "compliant", Type: proto.ColumnType_BOOL},
+			{Name: "reason", Type: proto.ColumnType_STRING},
+			{Name: "violations", Type: proto.ColumnType_JSON},
+			{Name: "violating_keys", Type: proto.ColumnType_JSON},
+			{Name: "suggestions", Type: proto.ColumnType_JSON},
+			{Name: "job_id", Type: proto.ColumnType_INT},
+			{Name: "evaluated_at", Type: proto.ColumnType_INT},
+		},
+	}
+}
@@ -0,0 +1,47 @@
+package types
+
+import (
+	"github.com/opengovern/og-util/pkg/source"
+)
+
+type ResourceRelation string
+
+const (
+	ResourceRelationContains   ResourceRelation = "contains"
+	ResourceRelationAttachedTo ResourceRelation = "attached-to"
+	ResourceRelationUsesRole   ResourceRelation = "uses-role"
+	ResourceRelationInNetwork  ResourceRelation = "in-network"
+	ResourceRelationRoutesTo   ResourceRelation = "routes-to"
+)
+
+// ResourceEdge is a directed relationship between two described resources, extracted from the
+// description of the resource it was discovered on. Edges are stored next to the lookup index
+// so that the graph can be walked without loading full resource documents.
+type ResourceEdge struct {
+	EsID    string `json:"es_id"`
+	EsIndex string `json:"es_index"`
+
+	FromResourceID   string           `json:"from_resource_id"`
+	FromResourceType string           `json:"from_resource_type"`
+	ToResourceID     string           `json:"to_resource_id"`
+	ToResourceType   string           `json:"to_resource_type"`
+	Relation         ResourceRelation `json:"relation"`
+
+	// SourceID, SourceType and ResourceType belong to the described resource the edge was extracted
+	// from, which is not always the From side of the edge.
+	SourceID      string      `json:"source_id"`
+	SourceType    source.Type `json:"source_type"`
+	ResourceType  string      `json:"resource_type"`
+	ResourceJobID uint        `json:"resource_job_id"`
+	CreatedAt     int64       `json:"created_at"`
+}
+
+func (r ResourceEdge) KeysAndIndex() ([]string, string) {
+	return []string{
+		r.FromResourceID,
+		r.ToResourceID,
+		string(r.Relation),
+		r.SourceID,
+		string(r.SourceType),
+	}, ResourceEdgesIndex
+}
@@ -0,0 +1,115 @@
+package utils
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/opengovern/og-util/pkg/httpserver"
+)
+
+// Headers set by the auth service, next to httpserver.XKaytuUserConnectionsScope, for users and keys with a
+// scoped role binding. A missing header means the request is not restricted.
+const (
+	XKaytuUserBenchmarksScope          = "X-Kaytu-UserBenchmarksScope"
+	XKaytuUserResourceCollectionsScope = "X-Kaytu-UserResourceCollectionsScope"
+)
+
+// ScopeNoMatch is sent as the only item of a scope that resolves to nothing, it matches no connection,
+// benchmark or resource collection so the scope denies everything instead of being ignored.
+const ScopeNoMatch = "00000000-0000-0000-0000-000000000000"
+
+func scopeFromHeader(ctx echo.Context, header string) []string {
+	value := ctx.Request().Header.Get(header)
+	if len(value) == 0 {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
+
+// GetScopedConnectionIDs returns the connections the request is restricted to, nil if it is not restricted.
+func GetScopedConnectionIDs(ctx echo.Context) []string {
+	return scopeFromHeader(ctx, httpserver.XKaytuUserConnectionsScope)
+}
+
+// GetScopedBenchmarkIDs returns the benchmarks the request is restricted to, nil if it is not restricted.
+func GetScopedBenchmarkIDs(ctx echo.Context) []string {
+	return scopeFromHeader(ctx, XKaytuUserBenchmarksScope)
+}
+
+// GetScopedResourceCollectionIDs returns the resource collections the request is restricted to, nil if it is not restricted.
+func GetScopedResourceCollectionIDs(ctx echo.Context) []string {
+	return scopeFromHeader(ctx, XKaytuUserResourceCollectionsScope)
+}
+
+// ResolveBenchmarkIDs narrows the requested benchmarks to the scope of the request, like
+// httpserver.ResolveConnectionIDs does for connections.
+func ResolveBenchmarkIDs(ctx echo.Context, benchmarkIDs []string) ([]string, error) {
+	return resolveScope(GetScopedBenchmarkIDs(ctx), benchmarkIDs, "invalid benchmark ids")
+}
+
+func CheckAccessToBenchmarkID(ctx echo.Context, benchmarkID string) error {
+	return checkScope(GetScopedBenchmarkIDs(ctx), benchmarkID, "Invalid benchmark ID")
+}
+
+// ResolveResourceCollectionIDs narrows the requested resource collections to the scope of the request.
+func ResolveResourceCollectionIDs(ctx echo.Context, resourceCollectionIDs []string) ([]string, error) {
+	return resolveScope(GetScopedResourceCollectionIDs(ctx), resourceCollectionIDs, "invalid resource collection ids")
+}
+
+func CheckAccessToResourceCollectionID(ctx echo.Context, resourceCollectionID string) error {
+	return checkScope(GetScopedResourceCollectionIDs(ctx), resourceCollectionID, "Invalid resource collection ID")
+}
+
+func resolveScope(scope, requested []string, message string) ([]string, error) {
+	if len(scope) == 0 {
+		return requested, nil
+	}
+	if len(requested) == 0 {
+		return scope, nil
+	}
+
+	var res []string
+	for _, id := range requested {
+		if Includes(scope, id) {
+			res = append(res, id)
+		}
+	}
+	if len(res) == 0 {
+		return nil, echo.NewHTTPError(http.StatusForbidden, message)
+	}
+	return res, nil
+}
+
+func checkScope(scope []string, id string, message string) error {
+	if len(scope) == 0 || Includes(scope, id) {
+		return nil
+	}
+	return echo.NewHTTPError(http.StatusForbidden, message)
+}
+
+// ScopeResourceIDs returns the member resources a request scoped to resource collections is restricted to, nil if
+// the request is not scoped to resource collections. Collections without members resolve to ScopeNoMatch.
+func ScopeResourceIDs(ctx echo.Context, members func(resourceCollectionIDs []string) ([]string, error)) ([]string, error) {
+	resourceCollectionIDs := GetScopedResourceCollectionIDs(ctx)
+	if len(resourceCollectionIDs) == 0 {
+		return nil, nil
+	}
+	resourceIDs, err := members(resourceCollectionIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(resourceIDs) == 0 {
+		return []string{ScopeNoMatch}, nil
+	}
+	return resourceIDs, nil
+}
+
+// ResolveResourceIDs narrows the requested resources to the scoped member resources returned by ScopeResourceIDs.
+func ResolveResourceIDs(scopedResourceIDs, resourceIDs []string) ([]string, error) {
+	return resolveScope(scopedResourceIDs, resourceIDs, "invalid resource ids")
+}
+
+func CheckAccessToResourceID(scopedResourceIDs []string, resourceID string) error {
+	return checkScope(scopedResourceIDs, resourceID, "Invalid resource ID")
+}
@@ -0,0 +1,100 @@
+package utils
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/opengovern/og-util/pkg/httpserver"
+	"github.com/stretchr/testify/assert"
+)
+
+func scopedContext(headers map[string]string) echo.Context {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	return echo.New().NewContext(req, httptest.NewRecorder())
+}
+
+func TestScopeHeaders(t *testing.T) {
+	ctx := scopedContext(map[string]string{
+		httpserver.XKaytuUserConnectionsScope: "c1,c2",
+		XKaytuUserBenchmarksScope:             "b1",
+	})
+	assert.Equal(t, []string{"c1", "c2"}, GetScopedConnectionIDs(ctx))
+	assert.Equal(t, []string{"b1"}, GetScopedBenchmarkIDs(ctx))
+	assert.Nil(t, GetScopedResourceCollectionIDs(ctx))
+}
+
+func TestResolveBenchmarkIDs(t *testing.T) {
+	tests := []struct {
+		name      string
+		scope     string
+		requested []string
+		want      []string
+		wantErr   bool
+	}{
+		{name: "unscoped", requested: []string{"b1"}, want: []string{"b1"}},
+		{name: "unscoped all", want: nil},
+		{name: "scoped all", scope: "b1,b2", want: []string{"b1", "b2"}},
+		{name: "narrowed", scope: "b1,b2", requested: []string{"b2", "b3"}, want: []string{"b2"}},
+		{name: "outside", scope: "b1", requested: []string{"b3"}, wantErr: true},
+		{name: "no match", scope: ScopeNoMatch, requested: []string{"b1"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		ctx := scopedContext(map[string]string{XKaytuUserBenchmarksScope: tt.scope})
+		got, err := ResolveBenchmarkIDs(ctx, tt.requested)
+		if tt.wantErr {
+			var httpErr *echo.HTTPError
+			assert.True(t, errors.As(err, &httpErr), tt.name)
+			assert.Equal(t, http.StatusForbidden, httpErr.Code, tt.name)
+			continue
+		}
+		assert.NoError(t, err, tt.name)
+		assert.Equal(t, tt.want, got, tt.name)
+	}
+}
+
+func TestCheckAccessToResourceCollectionID(t *testing.T) {
+	assert.NoError(t, CheckAccessToResourceCollectionID(scopedContext(nil), "rc1"))
+
+	ctx := scopedContext(map[string]string{XKaytuUserResourceCollectionsScope: "rc1"})
+	assert.NoError(t, CheckAccessToResourceCollectionID(ctx, "rc1"))
+	assert.Error(t, CheckAccessToResourceCollectionID(ctx, "rc2"))
+}
+
+func TestScopeResourceIDs(t *testing.T) {
+	members := map[string][]string{"rc1": {"r1", "r2"}, "rc2": nil}
+	lookup := func(resourceCollectionIDs []string) ([]string, error) {
+		var res []string
+		for _, id := range resourceCollectionIDs {
+			res = append(res, members[id]...)
+		}
+		return res, nil
+	}
+
+	resourceIDs, err := ScopeResourceIDs(scopedContext(nil), func([]string) ([]string, error) {
+		t.Fatal("members of an unscoped request are not looked up")
+		return nil, nil
+	})
+	assert.NoError(t, err)
+	assert.Nil(t, resourceIDs)
+
+	resourceIDs, err = ScopeResourceIDs(scopedContext(map[string]string{XKaytuUserResourceCollectionsScope: "rc1"}), lookup)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"r1", "r2"}, resourceIDs)
+	assert.NoError(t, CheckAccessToResourceID(resourceIDs, "r1"))
+	assert.Error(t, CheckAccessToResourceID(resourceIDs, "r3"))
+	narrowed, err := ResolveResourceIDs(resourceIDs, []string{"r2", "r3"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"r2"}, narrowed)
+
+	// a collection without members denies every resource
+	resourceIDs, err = ScopeResourceIDs(scopedContext(map[string]string{XKaytuUserResourceCollectionsScope: "rc2"}), lookup)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{ScopeNoMatch}, resourceIDs)
+	assert.Error(t, CheckAccessToResourceID(resourceIDs, "r1"))
+}
@@ -53,6 +53,53 @@ type GetRDSInstanceRequest struct {
 	IOPs                 float64
 }
 
+type GetAutoscalingGroupCostRequest struct {
+	RegionCode      string
+	InstanceType    string
+	DesiredCapacity float64
+	RootVolumeType  string
+	RootVolumeSize  float64
+}
+
+type GetEFSFileSystemCostRequest struct {
+	RegionCode                 string
+	StorageGB                  float64 // usage
+	InfrequentAccessStorageGB  float64 // usage
+	ThroughputMode             string
+	ProvisionedThroughputMiBps float64
+}
+
+type GetNatGatewayCostRequest struct {
+	RegionCode             string
+	MonthlyDataProcessedGB float64 // usage
+}
+
+type GetS3BucketCostRequest struct {
+	RegionCode           string
+	StorageClass         string
+	StorageGB            float64 // usage
+	MonthlyTier1Requests float64 // usage, PUT, COPY, POST and LIST requests
+	MonthlyTier2Requests float64 // usage, GET and the other requests
+}
+
+type GetEKSClusterCostRequest struct {
+	RegionCode string
+}
+
+type GetEKSNodeGroupCostRequest struct {
+	RegionCode   string
+	InstanceType string
+	DesiredSize  float64
+	DiskSize     float64
+}
+
+type GetElastiCacheCostRequest struct {
+	RegionCode string
+	NodeType   string
+	Engine     string
+	NodeCount  float64
+}
+
 type GetAzureVmRequest struct {
 	RegionCode      string
 	VMSize          string
@@ -88,6 +135,24 @@ type GetAzureVirtualNetworkPeeringRequest struct {
 	MonthlyDataTransferGB *float64
 }
 
+type GetAzureKubernetesClusterRequest struct {
+	RegionCode string
+	SkuTier    string
+	NodeVMSize string
+	NodeCount  float64
+}
+
+type GetAzureStorageAccountRequest struct {
+	RegionCode             string
+	AccountKind            string
+	AccountTier            string
+	ReplicationType        string
+	AccessTier             string
+	StorageGB              float64 // usage
+	MonthlyWriteOperations float64 // usage
+	MonthlyReadOperations  float64 // usage
+}
+
 type GetAzureSqlServersDatabasesRequest struct {
 	RegionCode  string
 	SqlServerDB azure.SqlDatabaseDescription
@@ -102,3 +167,26 @@ type GetAzureSqlServersDatabasesRequest struct {
 	BackupStorageGB int64
 	ResourceId      string
 }
+
+type TerraformResourceCostDiff struct {
+	Address            string
+	Provider           string
+	Type               string
+	PriorMonthlyCost   float64
+	PlannedMonthlyCost float64
+	MonthlyCostDiff    float64
+	// Errors of the components whose price was not found, keyed by the component name
+	Errors map[string]string
+}
+
+type GetTerraformPlanCostResponse struct {
+	Currency           string
+	PriorMonthlyCost   float64
+	PlannedMonthlyCost float64
+	MonthlyCostDiff    float64
+	Resources          []TerraformResourceCostDiff
+	// SkippedAddresses are the resources of the plan that are not estimated
+	SkippedAddresses []string
+	// UnsupportedAddresses are the skipped resources whose type has no cost estimator
+	UnsupportedAddresses []string
+}
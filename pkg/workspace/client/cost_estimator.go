@@ -6,11 +6,13 @@ import (
 	"github.com/opengovern/og-util/pkg/httpclient"
 	"github.com/opengovern/opengovernance/pkg/workspace/api"
 	"net/http"
+	"net/url"
 )
 
 type CostEstimatorPricesClient interface {
 	GetAzure(ctx *httpclient.Context, req api.BaseRequest) (float64, error)
 	GetAWS(ctx *httpclient.Context, req api.BaseRequest) (float64, error)
+	GetTerraformPlanCost(ctx *httpclient.Context, plan []byte, regionCode string) (*api.GetTerraformPlanCostResponse, error)
 }
 
 type costEstimatorClient struct {
@@ -50,3 +52,13 @@ func (s *costEstimatorClient) GetAWS(ctx *httpclient.Context, req api.BaseReques
 	}
 	return response, nil
 }
+
+func (s *costEstimatorClient) GetTerraformPlanCost(ctx *httpclient.Context, plan []byte, regionCode string) (*api.GetTerraformPlanCostResponse, error) {
+	url := fmt.Sprintf("%s/api/v1/costestimator/terraform-plan?regionCode=%s", s.baseURL, url.QueryEscape(regionCode))
+
+	var response api.GetTerraformPlanCostResponse
+	if _, err := httpclient.DoRequest(ctx.Ctx, http.MethodPost, url, ctx.ToHeaders(), plan, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
@@ -1,10 +1,14 @@
 package workspace
 
 import (
+	"errors"
 	"fmt"
 	"github.com/opengovern/opengovernance/pkg/workspace/api"
 	"github.com/opengovern/opengovernance/pkg/workspace/costestimator"
+	"github.com/opengovern/opengovernance/pkg/workspace/costestimator/query"
 	kaytuResources "github.com/opengovern/opengovernance/pkg/workspace/costestimator/resources"
+	"github.com/opengovern/opengovernance/pkg/workspace/costestimator/terraform"
+	"io"
 
 	"github.com/labstack/echo/v4"
 	"net/http"
@@ -25,7 +29,9 @@ func (s *Server) GetAwsCost(ctx echo.Context) error {
 	s.logger.Info(fmt.Sprintf("calculating cost for %v", request))
 	cost, err := costestimator.CalcCosts(s.db, s.logger, "AWS", request.ResourceType,
 		kaytuResources.ResourceRequest{Request: request.Request, Address: request.ResourceId})
-	if err != nil {
+	if errors.Is(err, query.ErrUnsupported) {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	} else if err != nil {
 		return err
 	}
 	s.logger.Info(fmt.Sprintf("calculating cost for %s is done, value: %v", request.ResourceType, cost))
@@ -47,13 +53,41 @@ func (s *Server) GetAzureCost(ctx echo.Context) error {
 	s.logger.Info(fmt.Sprintf("calculating cost for %v", request))
 	cost, err := costestimator.CalcCosts(s.db, s.logger, "Azure", request.ResourceType,
 		kaytuResources.ResourceRequest{Request: request.Request, Address: request.ResourceId})
-	if err != nil {
+	if errors.Is(err, query.ErrUnsupported) {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	} else if err != nil {
 		return err
 	}
 	s.logger.Info(fmt.Sprintf("calculating cost for %s is done, value: %v", request.ResourceType, cost))
 	return ctx.JSON(http.StatusOK, cost)
 }
 
+// GetTerraformPlanCost get the monthly cost diff of a terraform plan
+// route: /workspace/api/v1/costestimator/terraform-plan
+// The body is the output of `terraform show -json <planfile>`, the regionCode query param is used for the aws
+// resources when the plan does not configure the region of the aws provider.
+func (s *Server) GetTerraformPlanCost(ctx echo.Context) error {
+	if err := s.CheckRoleInWorkspace(ctx, nil, nil, ""); err != nil {
+		return err
+	}
+
+	body, err := io.ReadAll(ctx.Request().Body)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	plan, err := terraform.ParsePlan(body)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	response, err := costestimator.CalcPlanCosts(s.db, s.logger, plan, ctx.QueryParam("regionCode"))
+	if err != nil {
+		return err
+	}
+	s.logger.Info(fmt.Sprintf("calculating terraform plan cost is done, diff: %v", response.MonthlyCostDiff))
+	return ctx.JSON(http.StatusOK, response)
+}
+
 func bindValidate(ctx echo.Context, i interface{}) error {
 	if err := ctx.Bind(i); err != nil {
 		return err
@@ -0,0 +1,77 @@
+package aws
+
+import (
+	"github.com/opengovern/opengovernance/pkg/workspace/api"
+	"github.com/shopspring/decimal"
+	"strings"
+
+	"github.com/opengovern/opengovernance/pkg/workspace/costestimator/query"
+)
+
+// AutoscalingGroup represents an Auto Scaling group definition that can be cost-estimated. The instances of the
+// group are estimated as instances of a single type running at the desired capacity.
+type AutoscalingGroup struct {
+	instance *Instance
+
+	// desiredCapacity is the number of instances the group is estimated with.
+	desiredCapacity decimal.Decimal
+}
+
+// autoscalingGroupValues represents the structure of Terraform values for aws_autoscaling_group resource, the
+// instance type is the one of the launch template or launch configuration of the group.
+type autoscalingGroupValues struct {
+	RegionCode      string
+	InstanceType    string
+	DesiredCapacity float64
+	RootVolumeType  string
+	RootVolumeSize  float64
+}
+
+// decodeAutoscalingGroupValues decodes and returns autoscalingGroupValues from a Terraform values map.
+func decodeAutoscalingGroupValues(request api.GetAutoscalingGroupCostRequest) autoscalingGroupValues {
+	return autoscalingGroupValues{
+		RegionCode:      request.RegionCode,
+		InstanceType:    request.InstanceType,
+		DesiredCapacity: request.DesiredCapacity,
+		RootVolumeType:  request.RootVolumeType,
+		RootVolumeSize:  request.RootVolumeSize,
+	}
+}
+
+// newAutoscalingGroup creates a new AutoscalingGroup from autoscalingGroupValues.
+func (p *Provider) newAutoscalingGroup(vals autoscalingGroupValues) *AutoscalingGroup {
+	instVals := instanceValues{
+		RegionCode:       vals.RegionCode,
+		InstanceType:     vals.InstanceType,
+		AvailabilityZone: vals.RegionCode,
+		OperatingSystem:  "Linux",
+	}
+	if vals.RootVolumeType != "" || vals.RootVolumeSize > 0 {
+		instVals.RootBlockDevice = append(instVals.RootBlockDevice, struct {
+			VolumeType string
+			VolumeSize float64
+			IOPS       float64
+		}{VolumeType: vals.RootVolumeType, VolumeSize: vals.RootVolumeSize})
+	}
+
+	desiredCapacity := decimal.NewFromFloat(vals.DesiredCapacity)
+	inst := p.newInstance(instVals)
+	inst.instanceCount = desiredCapacity
+
+	return &AutoscalingGroup{
+		instance:        inst,
+		desiredCapacity: desiredCapacity,
+	}
+}
+
+// Components returns the price component queries that make up this AutoscalingGroup.
+func (asg *AutoscalingGroup) Components() []query.Component {
+	components := asg.instance.Components()
+	for i, comp := range components {
+		// the instance count only applies to the hourly components, the root volume is multiplied here
+		if strings.HasPrefix(comp.Name, "Root volume: ") {
+			components[i].MonthlyQuantity = comp.MonthlyQuantity.Mul(asg.desiredCapacity)
+		}
+	}
+	return components
+}
@@ -0,0 +1,120 @@
+package aws
+
+import (
+	"github.com/opengovern/opengovernance/pkg/workspace/api"
+	"github.com/opengovern/opengovernance/pkg/workspace/costestimator/price"
+	"github.com/opengovern/opengovernance/pkg/workspace/costestimator/product"
+	"github.com/opengovern/opengovernance/pkg/workspace/costestimator/query"
+	"github.com/opengovern/opengovernance/pkg/workspace/costestimator/util"
+	"github.com/shopspring/decimal"
+
+	"github.com/opengovern/opengovernance/pkg/workspace/costestimator/aws/region"
+)
+
+// EFSFileSystem represents an EFS file system definition that can be cost-estimated.
+type EFSFileSystem struct {
+	provider *Provider
+	region   region.Code
+
+	// storageGB and infrequentAccessStorageGB are usage values, the size of a file system is not part of its
+	// definition.
+	storageGB                 decimal.Decimal
+	infrequentAccessStorageGB decimal.Decimal
+
+	// throughputMode describes the throughput mode of the file system.
+	// Valid values: "bursting", "elastic", "provisioned".
+	// Note: only "provisioned" adds a component, the other modes are billed by usage.
+	throughputMode             string
+	provisionedThroughputMiBps decimal.Decimal
+}
+
+// efsFileSystemValues represents the structure of Terraform values for aws_efs_file_system resource.
+type efsFileSystemValues struct {
+	RegionCode                 string
+	StorageGB                  float64
+	InfrequentAccessStorageGB  float64
+	ThroughputMode             string  `mapstructure:"throughput_mode"`
+	ProvisionedThroughputMiBps float64 `mapstructure:"provisioned_throughput_in_mibps"`
+}
+
+// decodeEFSFileSystemValues decodes and returns efsFileSystemValues from a Terraform values map.
+func decodeEFSFileSystemValues(request api.GetEFSFileSystemCostRequest) efsFileSystemValues {
+	return efsFileSystemValues{
+		RegionCode:                 request.RegionCode,
+		StorageGB:                  request.StorageGB,
+		InfrequentAccessStorageGB:  request.InfrequentAccessStorageGB,
+		ThroughputMode:             request.ThroughputMode,
+		ProvisionedThroughputMiBps: request.ProvisionedThroughputMiBps,
+	}
+}
+
+// newEFSFileSystem creates a new EFSFileSystem from efsFileSystemValues.
+func (p *Provider) newEFSFileSystem(vals efsFileSystemValues) *EFSFileSystem {
+	return &EFSFileSystem{
+		provider:                   p,
+		region:                     region.Code(vals.RegionCode),
+		storageGB:                  decimal.NewFromFloat(vals.StorageGB),
+		infrequentAccessStorageGB:  decimal.NewFromFloat(vals.InfrequentAccessStorageGB),
+		throughputMode:             vals.ThroughputMode,
+		provisionedThroughputMiBps: decimal.NewFromFloat(vals.ProvisionedThroughputMiBps),
+	}
+}
+
+// Components returns the price component queries that make up this EFSFileSystem.
+func (efs *EFSFileSystem) Components() []query.Component {
+	components := []query.Component{
+		efs.storageComponent("Storage (standard)", "General Purpose", efs.storageGB),
+	}
+
+	if efs.infrequentAccessStorageGB.IsPositive() {
+		components = append(components, efs.storageComponent("Storage (infrequent access)", "Infrequent Access", efs.infrequentAccessStorageGB))
+	}
+
+	if efs.throughputMode == "provisioned" && efs.provisionedThroughputMiBps.IsPositive() {
+		components = append(components, efs.provisionedThroughputComponent())
+	}
+
+	return components
+}
+
+func (efs *EFSFileSystem) storageComponent(name, storageClass string, quantity decimal.Decimal) query.Component {
+	return query.Component{
+		Name:            name,
+		MonthlyQuantity: quantity,
+		Unit:            "GB",
+		Usage:           true,
+		ProductFilter: &product.Filter{
+			Provider: util.StringPtr(efs.provider.key),
+			Service:  util.StringPtr("AmazonEFS"),
+			Family:   util.StringPtr("Storage"),
+			Location: util.StringPtr(efs.region.String()),
+			AttributeFilters: []*product.AttributeFilter{
+				{Key: "StorageClass", Value: util.StringPtr(storageClass)},
+				{Key: "UsageType", ValueRegex: util.StringPtr("TimedStorage")},
+			},
+		},
+		PriceFilter: &price.Filter{
+			Unit: util.StringPtr("GB-Mo"),
+		},
+	}
+}
+
+func (efs *EFSFileSystem) provisionedThroughputComponent() query.Component {
+	return query.Component{
+		Name:            "Provisioned throughput",
+		MonthlyQuantity: efs.provisionedThroughputMiBps,
+		Unit:            "MiBps",
+		ProductFilter: &product.Filter{
+			Provider: util.StringPtr(efs.provider.key),
+			Service:  util.StringPtr("AmazonEFS"),
+			Family:   util.StringPtr("Provisioned Throughput"),
+			Location: util.StringPtr(efs.region.String()),
+			AttributeFilters: []*product.AttributeFilter{
+				{Key: "UsageType", ValueRegex: util.StringPtr("ProvisionedTP-MiBpsHrs")},
+			},
+		},
+		PriceFilter: &price.Filter{
+			Unit: util.StringPtr("MiBps-Mo"),
+		},
+	}
+}
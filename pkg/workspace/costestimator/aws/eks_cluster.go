@@ -0,0 +1,98 @@
+package aws
+
+import (
+	"github.com/opengovern/opengovernance/pkg/workspace/api"
+	"github.com/opengovern/opengovernance/pkg/workspace/costestimator/price"
+	"github.com/opengovern/opengovernance/pkg/workspace/costestimator/product"
+	"github.com/opengovern/opengovernance/pkg/workspace/costestimator/query"
+	"github.com/opengovern/opengovernance/pkg/workspace/costestimator/util"
+	"github.com/shopspring/decimal"
+
+	"github.com/opengovern/opengovernance/pkg/workspace/costestimator/aws/region"
+)
+
+// EKSCluster represents the control plane of an EKS cluster that can be cost-estimated, the worker nodes are
+// estimated by their node groups.
+type EKSCluster struct {
+	provider *Provider
+	region   region.Code
+}
+
+// eksClusterValues represents the structure of Terraform values for aws_eks_cluster resource.
+type eksClusterValues struct {
+	RegionCode string
+}
+
+// decodeEKSClusterValues decodes and returns eksClusterValues from a Terraform values map.
+func decodeEKSClusterValues(request api.GetEKSClusterCostRequest) eksClusterValues {
+	return eksClusterValues{
+		RegionCode: request.RegionCode,
+	}
+}
+
+// newEKSCluster creates a new EKSCluster from eksClusterValues.
+func (p *Provider) newEKSCluster(vals eksClusterValues) *EKSCluster {
+	return &EKSCluster{
+		provider: p,
+		region:   region.Code(vals.RegionCode),
+	}
+}
+
+// Components returns the price component queries that make up this EKSCluster.
+func (eks *EKSCluster) Components() []query.Component {
+	return []query.Component{eks.controlPlaneComponent()}
+}
+
+func (eks *EKSCluster) controlPlaneComponent() query.Component {
+	return query.Component{
+		Name:           "EKS cluster",
+		HourlyQuantity: decimal.NewFromInt(1),
+		Unit:           "Hrs",
+		ProductFilter: &product.Filter{
+			Provider: util.StringPtr(eks.provider.key),
+			Service:  util.StringPtr("AmazonEKS"),
+			Family:   util.StringPtr("Compute"),
+			Location: util.StringPtr(eks.region.String()),
+			AttributeFilters: []*product.AttributeFilter{
+				{Key: "UsageType", ValueRegex: util.StringPtr("AmazonEKS-Hours:perCluster")},
+			},
+		},
+		PriceFilter: &price.Filter{
+			Unit: util.StringPtr("Hours"),
+		},
+	}
+}
+
+// eksNodeGroupValues represents the structure of Terraform values for aws_eks_node_group resource.
+type eksNodeGroupValues struct {
+	RegionCode   string
+	InstanceType string
+	DesiredSize  float64
+	DiskSize     float64
+}
+
+// decodeEKSNodeGroupValues decodes and returns eksNodeGroupValues from a Terraform values map.
+func decodeEKSNodeGroupValues(request api.GetEKSNodeGroupCostRequest) eksNodeGroupValues {
+	return eksNodeGroupValues{
+		RegionCode:   request.RegionCode,
+		InstanceType: request.InstanceType,
+		DesiredSize:  request.DesiredSize,
+		DiskSize:     request.DiskSize,
+	}
+}
+
+// newEKSNodeGroup creates the AutoscalingGroup a managed node group runs its nodes in from eksNodeGroupValues.
+func (p *Provider) newEKSNodeGroup(vals eksNodeGroupValues) *AutoscalingGroup {
+	diskSize := vals.DiskSize
+	if diskSize <= 0 {
+		// default disk size of the nodes of a managed node group
+		diskSize = 20
+	}
+	return p.newAutoscalingGroup(autoscalingGroupValues{
+		RegionCode:      vals.RegionCode,
+		InstanceType:    vals.InstanceType,
+		DesiredCapacity: vals.DesiredSize,
+		RootVolumeType:  "gp2",
+		RootVolumeSize:  diskSize,
+	})
+}
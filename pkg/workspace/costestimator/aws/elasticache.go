@@ -0,0 +1,99 @@
+package aws
+
+import (
+	"github.com/opengovern/opengovernance/pkg/workspace/api"
+	"github.com/opengovern/opengovernance/pkg/workspace/costestimator/price"
+	"github.com/opengovern/opengovernance/pkg/workspace/costestimator/product"
+	"github.com/opengovern/opengovernance/pkg/workspace/costestimator/query"
+	"github.com/opengovern/opengovernance/pkg/workspace/costestimator/util"
+	"github.com/shopspring/decimal"
+	"strings"
+
+	"github.com/opengovern/opengovernance/pkg/workspace/costestimator/aws/region"
+)
+
+// ElastiCache represents the nodes of an ElastiCache cluster or replication group that can be cost-estimated.
+type ElastiCache struct {
+	provider *Provider
+	region   region.Code
+	nodeType string
+
+	// engine is the cache engine of the nodes.
+	// Valid values: "Redis", "Memcached", "Valkey".
+	engine string
+
+	nodeCount decimal.Decimal
+}
+
+// elastiCacheValues represents the structure of Terraform values for aws_elasticache_cluster and
+// aws_elasticache_replication_group resources.
+type elastiCacheValues struct {
+	RegionCode string
+	NodeType   string `mapstructure:"node_type"`
+	Engine     string `mapstructure:"engine"`
+	NodeCount  float64
+}
+
+// decodeElastiCacheValues decodes and returns elastiCacheValues from a Terraform values map.
+func decodeElastiCacheValues(request api.GetElastiCacheCostRequest) elastiCacheValues {
+	return elastiCacheValues{
+		RegionCode: request.RegionCode,
+		NodeType:   request.NodeType,
+		Engine:     request.Engine,
+		NodeCount:  request.NodeCount,
+	}
+}
+
+// newElastiCache creates a new ElastiCache from elastiCacheValues.
+func (p *Provider) newElastiCache(vals elastiCacheValues) *ElastiCache {
+	cache := &ElastiCache{
+		provider:  p,
+		region:    region.Code(vals.RegionCode),
+		nodeType:  vals.NodeType,
+		engine:    "Redis",
+		nodeCount: decimal.NewFromInt(1),
+	}
+
+	switch strings.ToLower(vals.Engine) {
+	case "memcached":
+		cache.engine = "Memcached"
+	case "valkey":
+		cache.engine = "Valkey"
+	}
+
+	if vals.NodeCount > 0 {
+		cache.nodeCount = decimal.NewFromFloat(vals.NodeCount)
+	}
+
+	return cache
+}
+
+// Components returns the price component queries that make up this ElastiCache.
+func (ec *ElastiCache) Components() []query.Component {
+	return []query.Component{ec.nodeComponent()}
+}
+
+func (ec *ElastiCache) nodeComponent() query.Component {
+	return query.Component{
+		Name:           "Cache nodes",
+		Details:        []string{ec.engine, "on-demand", ec.nodeType},
+		HourlyQuantity: ec.nodeCount,
+		Unit:           "Hrs",
+		ProductFilter: &product.Filter{
+			Provider: util.StringPtr(ec.provider.key),
+			Service:  util.StringPtr("AmazonElastiCache"),
+			Family:   util.StringPtr("Cache Instance"),
+			Location: util.StringPtr(ec.region.String()),
+			AttributeFilters: []*product.AttributeFilter{
+				{Key: "InstanceType", Value: util.StringPtr(ec.nodeType)},
+				{Key: "CacheEngine", Value: util.StringPtr(ec.engine)},
+			},
+		},
+		PriceFilter: &price.Filter{
+			Unit: util.StringPtr("Hrs"),
+			AttributeFilters: []*price.AttributeFilter{
+				{Key: "TermType", Value: util.StringPtr("OnDemand")},
+			},
+		},
+	}
+}
@@ -0,0 +1,90 @@
+package aws
+
+import (
+	"github.com/opengovern/opengovernance/pkg/workspace/api"
+	"github.com/opengovern/opengovernance/pkg/workspace/costestimator/price"
+	"github.com/opengovern/opengovernance/pkg/workspace/costestimator/product"
+	"github.com/opengovern/opengovernance/pkg/workspace/costestimator/query"
+	"github.com/opengovern/opengovernance/pkg/workspace/costestimator/util"
+	"github.com/shopspring/decimal"
+
+	"github.com/opengovern/opengovernance/pkg/workspace/costestimator/aws/region"
+)
+
+// NatGateway represents a NAT gateway definition that can be cost-estimated.
+type NatGateway struct {
+	provider *Provider
+	region   region.Code
+
+	// monthlyDataProcessedGB is a usage value, the data processed by a gateway is not part of its definition.
+	monthlyDataProcessedGB decimal.Decimal
+}
+
+// natGatewayValues represents the structure of Terraform values for aws_nat_gateway resource.
+type natGatewayValues struct {
+	RegionCode             string
+	MonthlyDataProcessedGB float64
+}
+
+// decodeNatGatewayValues decodes and returns natGatewayValues from a Terraform values map.
+func decodeNatGatewayValues(request api.GetNatGatewayCostRequest) natGatewayValues {
+	return natGatewayValues{
+		RegionCode:             request.RegionCode,
+		MonthlyDataProcessedGB: request.MonthlyDataProcessedGB,
+	}
+}
+
+// newNatGateway creates a new NatGateway from natGatewayValues.
+func (p *Provider) newNatGateway(vals natGatewayValues) *NatGateway {
+	return &NatGateway{
+		provider:               p,
+		region:                 region.Code(vals.RegionCode),
+		monthlyDataProcessedGB: decimal.NewFromFloat(vals.MonthlyDataProcessedGB),
+	}
+}
+
+// Components returns the price component queries that make up this NatGateway.
+func (nat *NatGateway) Components() []query.Component {
+	return []query.Component{nat.gatewayComponent(), nat.dataProcessedComponent()}
+}
+
+func (nat *NatGateway) gatewayComponent() query.Component {
+	return query.Component{
+		Name:           "NAT gateway",
+		HourlyQuantity: decimal.NewFromInt(1),
+		Unit:           "Hrs",
+		ProductFilter: &product.Filter{
+			Provider: util.StringPtr(nat.provider.key),
+			Service:  util.StringPtr("AmazonEC2"),
+			Family:   util.StringPtr("NAT Gateway"),
+			Location: util.StringPtr(nat.region.String()),
+			AttributeFilters: []*product.AttributeFilter{
+				{Key: "UsageType", ValueRegex: util.StringPtr("NatGateway-Hours")},
+			},
+		},
+		PriceFilter: &price.Filter{
+			Unit: util.StringPtr("Hrs"),
+		},
+	}
+}
+
+func (nat *NatGateway) dataProcessedComponent() query.Component {
+	return query.Component{
+		Name:            "Data processed",
+		MonthlyQuantity: nat.monthlyDataProcessedGB,
+		Unit:            "GB",
+		Usage:           true,
+		ProductFilter: &product.Filter{
+			Provider: util.StringPtr(nat.provider.key),
+			Service:  util.StringPtr("AmazonEC2"),
+			Family:   util.StringPtr("NAT Gateway"),
+			Location: util.StringPtr(nat.region.String()),
+			AttributeFilters: []*product.AttributeFilter{
+				{Key: "UsageType", ValueRegex: util.StringPtr("NatGateway-Bytes")},
+			},
+		},
+		PriceFilter: &price.Filter{
+			Unit: util.StringPtr("GB"),
+		},
+	}
+}
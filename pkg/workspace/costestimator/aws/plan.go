@@ -0,0 +1,152 @@
+package aws
+
+import (
+	"fmt"
+	"github.com/opengovern/opengovernance/pkg/workspace/costestimator/query"
+	"github.com/opengovern/opengovernance/pkg/workspace/costestimator/util"
+)
+
+// PlanResourceComponents returns Component queries for a resource of a terraform plan from its values. The region
+// code of the provider is used when the values do not pin an availability zone. Resource types without an
+// estimator return query.ErrUnsupported.
+func (p *Provider) PlanResourceComponents(resourceType string, values map[string]any, regionCode string) ([]query.Component, error) {
+	availabilityZone := util.ValueString(values, "availability_zone")
+	if availabilityZone == "" {
+		availabilityZone = regionCode
+	}
+
+	switch resourceType {
+	case "aws_instance":
+		vals := instanceValues{
+			RegionCode:       regionCode,
+			InstanceType:     util.ValueString(values, "instance_type"),
+			Tenancy:          util.ValueString(values, "tenancy"),
+			AvailabilityZone: availabilityZone,
+			// Note: the AMI of a planned instance is not resolved, every instance is estimated as Linux
+			OperatingSystem:  "Linux",
+			EBSOptimized:     util.ValueBool(values, "ebs_optimized"),
+			EnableMonitoring: util.ValueBool(values, "monitoring"),
+		}
+		if vals.InstanceType == "" {
+			return nil, fmt.Errorf("instance_type is not known")
+		}
+		if cs := util.ValueBlock(values, "credit_specification"); cs != nil {
+			vals.CreditSpecification = append(vals.CreditSpecification, struct {
+				CPUCredits string
+			}{CPUCredits: util.ValueString(cs, "cpu_credits")})
+		}
+		if rbd := util.ValueBlock(values, "root_block_device"); rbd != nil {
+			vals.RootBlockDevice = append(vals.RootBlockDevice, struct {
+				VolumeType string
+				VolumeSize float64
+				IOPS       float64
+			}{
+				VolumeType: util.ValueString(rbd, "volume_type"),
+				VolumeSize: util.ValueFloat(rbd, "volume_size"),
+				IOPS:       util.ValueFloat(rbd, "iops"),
+			})
+		}
+		return p.newInstance(vals).Components(), nil
+	case "aws_db_instance":
+		vals := dbInstanceValues{
+			RegionCode:       regionCode,
+			InstanceClass:    util.ValueString(values, "instance_class"),
+			AvailabilityZone: availabilityZone,
+			Engine:           util.ValueString(values, "engine"),
+			LicenseModel:     util.ValueString(values, "license_model"),
+			MultiAZ:          util.ValueBool(values, "multi_az"),
+			AllocatedStorage: util.ValueFloat(values, "allocated_storage"),
+			StorageType:      util.ValueString(values, "storage_type"),
+			IOPS:             util.ValueFloat(values, "iops"),
+		}
+		if vals.InstanceClass == "" {
+			return nil, fmt.Errorf("instance_class is not known")
+		}
+		return p.newDBInstance(vals).Components(), nil
+	case "aws_ebs_volume":
+		vals := volumeValues{
+			AvailabilityZone: availabilityZone,
+			Type:             util.ValueString(values, "type"),
+			Size:             util.ValueFloat(values, "size"),
+			IOPS:             util.ValueFloat(values, "iops"),
+		}
+		return p.newVolume(vals).Components(), nil
+	case "aws_lb", "aws_alb":
+		vals := lbValues{
+			Region:           regionCode,
+			LoadBalancerType: util.ValueString(values, "load_balancer_type"),
+		}
+		return p.newLB(vals).Components(), nil
+	case "aws_elb":
+		// ELB Classic does not have any special configuration.
+		return p.newLB(lbValues{Region: regionCode, LoadBalancerType: "classic"}).Components(), nil
+	case "aws_autoscaling_group":
+		// Note: the launch template of the group is not resolved, only the instance type overridden by a mixed
+		// instances policy is known from the values of the group
+		override := util.ValueBlock(util.ValueBlock(util.ValueBlock(values, "mixed_instances_policy"), "launch_template"), "override")
+		vals := autoscalingGroupValues{
+			RegionCode:      regionCode,
+			InstanceType:    util.ValueString(override, "instance_type"),
+			DesiredCapacity: util.ValueFloat(values, "desired_capacity"),
+		}
+		if vals.InstanceType == "" {
+			return nil, fmt.Errorf("instance type of the launch template is not known")
+		}
+		if _, ok := values["desired_capacity"].(float64); !ok {
+			vals.DesiredCapacity = util.ValueFloat(values, "min_size")
+		}
+		return p.newAutoscalingGroup(vals).Components(), nil
+	case "aws_efs_file_system":
+		vals := efsFileSystemValues{
+			RegionCode:                 regionCode,
+			ThroughputMode:             util.ValueString(values, "throughput_mode"),
+			ProvisionedThroughputMiBps: util.ValueFloat(values, "provisioned_throughput_in_mibps"),
+		}
+		return p.newEFSFileSystem(vals).Components(), nil
+	case "aws_elasticache_cluster":
+		vals := elastiCacheValues{
+			RegionCode: regionCode,
+			NodeType:   util.ValueString(values, "node_type"),
+			Engine:     util.ValueString(values, "engine"),
+			NodeCount:  util.ValueFloat(values, "num_cache_nodes"),
+		}
+		if vals.NodeType == "" {
+			// the nodes of a cluster that joins a replication group are estimated with the group
+			return nil, fmt.Errorf("node_type is not known")
+		}
+		return p.newElastiCache(vals).Components(), nil
+	case "aws_elasticache_replication_group":
+		vals := elastiCacheValues{
+			RegionCode: regionCode,
+			NodeType:   util.ValueString(values, "node_type"),
+			Engine:     util.ValueString(values, "engine"),
+			NodeCount:  util.ValueFloat(values, "num_cache_clusters"),
+		}
+		if vals.NodeType == "" {
+			return nil, fmt.Errorf("node_type is not known")
+		}
+		if shards := util.ValueFloat(values, "num_node_groups"); shards > 0 {
+			vals.NodeCount = shards * (util.ValueFloat(values, "replicas_per_node_group") + 1)
+		}
+		return p.newElastiCache(vals).Components(), nil
+	case "aws_eks_cluster":
+		return p.newEKSCluster(eksClusterValues{RegionCode: regionCode}).Components(), nil
+	case "aws_eks_node_group":
+		vals := eksNodeGroupValues{
+			RegionCode:   regionCode,
+			InstanceType: "t3.medium",
+			DesiredSize:  util.ValueFloat(util.ValueBlock(values, "scaling_config"), "desired_size"),
+			DiskSize:     util.ValueFloat(values, "disk_size"),
+		}
+		if instanceTypes := util.ValueStrings(values, "instance_types"); len(instanceTypes) > 0 {
+			vals.InstanceType = instanceTypes[0]
+		}
+		return p.newEKSNodeGroup(vals).Components(), nil
+	case "aws_nat_gateway":
+		return p.newNatGateway(natGatewayValues{RegionCode: regionCode}).Components(), nil
+	case "aws_s3_bucket":
+		return p.newS3Bucket(s3BucketValues{RegionCode: regionCode}).Components(), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", query.ErrUnsupported, resourceType)
+	}
+}
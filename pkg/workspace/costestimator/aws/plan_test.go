@@ -0,0 +1,157 @@
+package aws_test
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/opengovern/opengovernance/pkg/workspace/costestimator/aws"
+	"github.com/opengovern/opengovernance/pkg/workspace/costestimator/query"
+)
+
+func componentsByName(components []query.Component) map[string]query.Component {
+	byName := make(map[string]query.Component)
+	for _, comp := range components {
+		byName[comp.Name] = comp
+	}
+	return byName
+}
+
+func TestProvider_PlanResourceComponents(t *testing.T) {
+	provider, err := aws.NewProvider("AWS")
+	require.NoError(t, err)
+
+	t.Run("AutoscalingGroup", func(t *testing.T) {
+		values := map[string]any{
+			"desired_capacity": float64(3),
+			"mixed_instances_policy": []any{map[string]any{
+				"launch_template": []any{map[string]any{
+					"override": []any{map[string]any{"instance_type": "m5.large"}},
+				}},
+			}},
+		}
+		components, err := provider.PlanResourceComponents("aws_autoscaling_group", values, "us-east-1")
+		require.NoError(t, err)
+
+		byName := componentsByName(components)
+		require.Contains(t, byName, "Compute")
+		assert.True(t, byName["Compute"].HourlyQuantity.Equal(decimal.NewFromInt(3)))
+		require.Contains(t, byName, "Root volume: Storage")
+		assert.True(t, byName["Root volume: Storage"].MonthlyQuantity.Equal(decimal.NewFromInt(24)))
+	})
+
+	t.Run("AutoscalingGroupLaunchTemplate", func(t *testing.T) {
+		values := map[string]any{
+			"desired_capacity": float64(3),
+			"launch_template":  []any{map[string]any{"name": "web"}},
+		}
+		_, err := provider.PlanResourceComponents("aws_autoscaling_group", values, "us-east-1")
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, query.ErrUnsupported)
+	})
+
+	t.Run("EKSNodeGroup", func(t *testing.T) {
+		values := map[string]any{
+			"instance_types": []any{"c5.xlarge"},
+			"scaling_config": []any{map[string]any{"desired_size": float64(2), "min_size": float64(1)}},
+		}
+		components, err := provider.PlanResourceComponents("aws_eks_node_group", values, "eu-west-1")
+		require.NoError(t, err)
+
+		byName := componentsByName(components)
+		assert.True(t, byName["Compute"].HourlyQuantity.Equal(decimal.NewFromInt(2)))
+		assert.Equal(t, []string{"Linux", "on-demand", "c5.xlarge"}, byName["Compute"].Details)
+		assert.True(t, byName["Root volume: Storage"].MonthlyQuantity.Equal(decimal.NewFromInt(40)))
+	})
+
+	t.Run("ElastiCacheReplicationGroup", func(t *testing.T) {
+		values := map[string]any{
+			"node_type":               "cache.r6g.large",
+			"num_node_groups":         float64(2),
+			"replicas_per_node_group": float64(1),
+		}
+		components, err := provider.PlanResourceComponents("aws_elasticache_replication_group", values, "eu-west-1")
+		require.NoError(t, err)
+		require.Len(t, components, 1)
+		assert.True(t, components[0].HourlyQuantity.Equal(decimal.NewFromInt(4)))
+		assert.Equal(t, []string{"Redis", "on-demand", "cache.r6g.large"}, components[0].Details)
+	})
+
+	tcs := []struct {
+		resourceType string
+		values       map[string]any
+		names        []string
+	}{
+		{
+			resourceType: "aws_eks_cluster",
+			values:       map[string]any{"name": "main"},
+			names:        []string{"EKS cluster"},
+		},
+		{
+			resourceType: "aws_nat_gateway",
+			values:       map[string]any{"connectivity_type": "public"},
+			names:        []string{"NAT gateway", "Data processed"},
+		},
+		{
+			resourceType: "aws_efs_file_system",
+			values:       map[string]any{"throughput_mode": "provisioned", "provisioned_throughput_in_mibps": float64(64)},
+			names:        []string{"Storage (standard)", "Provisioned throughput"},
+		},
+		{
+			resourceType: "aws_s3_bucket",
+			values:       map[string]any{"bucket": "logs"},
+			names:        []string{"Storage", "PUT, COPY, POST, LIST requests", "GET, SELECT, and all other requests"},
+		},
+		{
+			resourceType: "aws_elasticache_cluster",
+			values:       map[string]any{"node_type": "cache.t3.micro", "engine": "memcached", "num_cache_nodes": float64(2)},
+			names:        []string{"Cache nodes"},
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.resourceType, func(t *testing.T) {
+			components, err := provider.PlanResourceComponents(tc.resourceType, tc.values, "eu-west-1")
+			require.NoError(t, err)
+
+			var names []string
+			for _, comp := range components {
+				names = append(names, comp.Name)
+				assert.Equal(t, "eu-west-1", *comp.ProductFilter.Location)
+			}
+			assert.Equal(t, tc.names, names)
+		})
+	}
+
+	t.Run("Unsupported", func(t *testing.T) {
+		components, err := provider.PlanResourceComponents("aws_route53_zone", map[string]any{}, "eu-west-1")
+		assert.ErrorIs(t, err, query.ErrUnsupported)
+		assert.Empty(t, components)
+	})
+}
+
+func TestProvider_ResourceComponents(t *testing.T) {
+	provider, err := aws.NewProvider("AWS")
+	require.NoError(t, err)
+
+	t.Run("NatGateway", func(t *testing.T) {
+		components, err := provider.ResourceComponents(zap.NewNop(), "aws_nat_gateway", map[string]interface{}{
+			"RegionCode":             "us-east-1",
+			"MonthlyDataProcessedGB": float64(100),
+		})
+		require.NoError(t, err)
+
+		byName := componentsByName(components)
+		assert.True(t, byName["Data processed"].MonthlyQuantity.Equal(decimal.NewFromInt(100)))
+		assert.True(t, byName["Data processed"].Usage)
+	})
+
+	t.Run("Unsupported", func(t *testing.T) {
+		for _, resourceType := range []string{"aws_eip", "aws_fsx_lustre_file_system", "aws_dynamodb_table"} {
+			_, err := provider.ResourceComponents(zap.NewNop(), resourceType, map[string]interface{}{})
+			assert.ErrorIs(t, err, query.ErrUnsupported, resourceType)
+		}
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/opengovern/opengovernance/pkg/workspace/costestimator/query"
+	"github.com/opengovern/opengovernance/pkg/workspace/costestimator/util"
 )
 
 // Provider is an implementation of the terraform.Provider, used to extract component queries from
@@ -22,7 +23,8 @@ func NewProvider(key string) (*Provider, error) {
 // Name returns the Provider's common name.
 func (p *Provider) Name() string { return p.key }
 
-// ResourceComponents returns Component queries for a given terraform.Resource.
+// ResourceComponents returns Component queries for a given terraform.Resource. Resource types without an
+// estimator return query.ErrUnsupported.
 func (p *Provider) ResourceComponents(logger *zap.Logger, resourceType string, request any) ([]query.Component, error) {
 	switch resourceType {
 	case "aws_instance":
@@ -38,12 +40,18 @@ func (p *Provider) ResourceComponents(logger *zap.Logger, resourceType string, r
 		}
 		return p.newInstance(*vals).Components(), nil
 	case "aws_autoscaling_group":
-		return nil, nil
-		//vals, err := decodeAutoscalingGroupValues(tfRes.Values)
-		//if err != nil {
-		//	return nil
-		//}
-		//return p.newAutoscalingGroup(rss, vals).Components()
+		req, ok := request.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("could not parse request")
+		}
+		vals := decodeAutoscalingGroupValues(api.GetAutoscalingGroupCostRequest{
+			RegionCode:      util.ValueString(req, "RegionCode"),
+			InstanceType:    util.ValueString(req, "InstanceType"),
+			DesiredCapacity: util.ValueFloat(req, "DesiredCapacity"),
+			RootVolumeType:  util.ValueString(req, "RootVolumeType"),
+			RootVolumeSize:  util.ValueFloat(req, "RootVolumeSize"),
+		})
+		return p.newAutoscalingGroup(vals).Components(), nil
 	case "aws_db_instance":
 		var dbInstanceRequest api.GetRDSInstanceRequest
 		if req, ok := request.(map[string]interface{}); ok {
@@ -76,33 +84,30 @@ func (p *Provider) ResourceComponents(logger *zap.Logger, resourceType string, r
 		vals := decodeVolumeValues(ebsVolumeRequest)
 		return p.newVolume(vals).Components(), nil
 	case "aws_efs_file_system":
-		return nil, nil
-		//vals, err := decodeEFSFileSystemValues(tfRes.Values)
-		//if err != nil {
-		//	return nil
-		//}
-		//return p.newEFSFileSystem(rss, vals).Components()
-	case "aws_elasticache_cluster":
-		return nil, nil
-		//vals, err := decodeElastiCacheValues(tfRes.Values)
-		//if err != nil {
-		//	return nil
-		//}
-		//return p.newElastiCache(vals).Components()
-	case "aws_elasticache_replication_group":
-		return nil, nil
-		//vals, err := decodeElastiCacheReplicationValues(tfRes.Values)
-		//if err != nil {
-		//	return nil
-		//}
-		//return p.newElastiCacheReplication(vals).Components()
-	case "aws_eip":
-		return nil, nil
-		//vals, err := decodeElasticIPValues(tfRes.Values)
-		//if err != nil {
-		//	return nil
-		//}
-		//return p.newElasticIP(vals).Components()
+		req, ok := request.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("could not parse request")
+		}
+		vals := decodeEFSFileSystemValues(api.GetEFSFileSystemCostRequest{
+			RegionCode:                 util.ValueString(req, "RegionCode"),
+			StorageGB:                  util.ValueFloat(req, "StorageGB"),
+			InfrequentAccessStorageGB:  util.ValueFloat(req, "InfrequentAccessStorageGB"),
+			ThroughputMode:             util.ValueString(req, "ThroughputMode"),
+			ProvisionedThroughputMiBps: util.ValueFloat(req, "ProvisionedThroughputMiBps"),
+		})
+		return p.newEFSFileSystem(vals).Components(), nil
+	case "aws_elasticache_cluster", "aws_elasticache_replication_group":
+		req, ok := request.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("could not parse request")
+		}
+		vals := decodeElastiCacheValues(api.GetElastiCacheCostRequest{
+			RegionCode: util.ValueString(req, "RegionCode"),
+			NodeType:   util.ValueString(req, "NodeType"),
+			Engine:     util.ValueString(req, "Engine"),
+			NodeCount:  util.ValueFloat(req, "NodeCount"),
+		})
+		return p.newElastiCache(vals).Components(), nil
 	case "aws_elb":
 		// ELB Classic does not have any special configuration.
 		var ebsVolumeRequest api.GetLBCostRequest
@@ -117,47 +122,26 @@ func (p *Provider) ResourceComponents(logger *zap.Logger, resourceType string, r
 		vals := decodeLBValues(ebsVolumeRequest)
 		return p.newLB(vals).Components(), nil
 	case "aws_eks_cluster":
-		return nil, nil
-		//vals, err := decodeEKSClusterValues(tfRes.Values)
-		//if err != nil {
-		//	return nil
-		//}
-		//return p.newEKSCluster(vals).Components()
+		req, ok := request.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("could not parse request")
+		}
+		vals := decodeEKSClusterValues(api.GetEKSClusterCostRequest{
+			RegionCode: util.ValueString(req, "RegionCode"),
+		})
+		return p.newEKSCluster(vals).Components(), nil
 	case "aws_eks_node_group":
-		return nil, nil
-		//vals, err := decodeEKSNodeGroupValues(tfRes.Values)
-		//if err != nil {
-		//	return nil
-		//}
-		//return p.newEKSNodeGroup(rss, vals).Components()
-	case "aws_fsx_lustre_file_system":
-		return nil, nil
-		//vals, err := decodeFSxLustreFileSystemValues(tfRes.Values)
-		//if err != nil {
-		//	return nil
-		//}
-		//return p.newFSxLustreFileSystem(rss, vals).Components()
-	case "aws_fsx_ontap_file_system":
-		return nil, nil
-		//vals, err := decodeFSxOntapFileSystemValues(tfRes.Values)
-		//if err != nil {
-		//	return nil
-		//}
-		//return p.newFSxOntapFileSystem(rss, vals).Components()
-	case "aws_fsx_openzfs_file_system":
-		return nil, nil
-		//vals, err := decodeFSxOpenzfsFileSystemValues(tfRes.Values)
-		//if err != nil {
-		//	return nil
-		//}
-		//return p.newFSxOpenzfsFileSystem(rss, vals).Components()
-	case "aws_fsx_windows_file_system":
-		return nil, nil
-		//vals, err := decodeFSxWindowsFileSystemValues(tfRes.Values)
-		//if err != nil {
-		//	return nil
-		//}
-		//return p.newFSxWindowsFileSystem(rss, vals).Components()
+		req, ok := request.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("could not parse request")
+		}
+		vals := decodeEKSNodeGroupValues(api.GetEKSNodeGroupCostRequest{
+			RegionCode:   util.ValueString(req, "RegionCode"),
+			InstanceType: util.ValueString(req, "InstanceType"),
+			DesiredSize:  util.ValueFloat(req, "DesiredSize"),
+			DiskSize:     util.ValueFloat(req, "DiskSize"),
+		})
+		return p.newEKSNodeGroup(vals).Components(), nil
 	case "aws_lb", "aws_alb":
 		var loadBalancerRequest api.GetLBCostRequest
 		if req, ok := request.(map[string]interface{}); ok {
@@ -171,13 +155,29 @@ func (p *Provider) ResourceComponents(logger *zap.Logger, resourceType string, r
 		vals := decodeLBValues(loadBalancerRequest)
 		return p.newLB(vals).Components(), nil
 	case "aws_nat_gateway":
-		return nil, nil
-		//vals, err := decodeNatGatewayValues(tfRes.Values)
-		//if err != nil {
-		//	return nil
-		//}
-		//return p.newNatGateway(vals).Components()
+		req, ok := request.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("could not parse request")
+		}
+		vals := decodeNatGatewayValues(api.GetNatGatewayCostRequest{
+			RegionCode:             util.ValueString(req, "RegionCode"),
+			MonthlyDataProcessedGB: util.ValueFloat(req, "MonthlyDataProcessedGB"),
+		})
+		return p.newNatGateway(vals).Components(), nil
+	case "aws_s3_bucket":
+		req, ok := request.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("could not parse request")
+		}
+		vals := decodeS3BucketValues(api.GetS3BucketCostRequest{
+			RegionCode:           util.ValueString(req, "RegionCode"),
+			StorageClass:         util.ValueString(req, "StorageClass"),
+			StorageGB:            util.ValueFloat(req, "StorageGB"),
+			MonthlyTier1Requests: util.ValueFloat(req, "MonthlyTier1Requests"),
+			MonthlyTier2Requests: util.ValueFloat(req, "MonthlyTier2Requests"),
+		})
+		return p.newS3Bucket(vals).Components(), nil
 	default:
-		return nil, nil
+		return nil, fmt.Errorf("%w: %s", query.ErrUnsupported, resourceType)
 	}
 }
@@ -0,0 +1,131 @@
+package aws
+
+import (
+	"github.com/opengovern/opengovernance/pkg/workspace/api"
+	"github.com/opengovern/opengovernance/pkg/workspace/costestimator/price"
+	"github.com/opengovern/opengovernance/pkg/workspace/costestimator/product"
+	"github.com/opengovern/opengovernance/pkg/workspace/costestimator/query"
+	"github.com/opengovern/opengovernance/pkg/workspace/costestimator/util"
+	"github.com/shopspring/decimal"
+
+	"github.com/opengovern/opengovernance/pkg/workspace/costestimator/aws/region"
+)
+
+// s3VolumeTypes maps the S3 storage classes to the volume type of their storage prices.
+var s3VolumeTypes = map[string]string{
+	"STANDARD":            "Standard",
+	"STANDARD_IA":         "Standard - Infrequent Access",
+	"ONEZONE_IA":          "One Zone - Infrequent Access",
+	"INTELLIGENT_TIERING": "Intelligent-Tiering Frequent Access",
+	"GLACIER_IR":          "Glacier Instant Retrieval",
+	"GLACIER":             "Amazon Glacier",
+	"DEEP_ARCHIVE":        "Glacier Deep Archive",
+}
+
+// S3Bucket represents an S3 bucket definition that can be cost-estimated. Every value but the storage class is
+// a usage value, the objects of a bucket are not part of its definition.
+type S3Bucket struct {
+	provider *Provider
+	region   region.Code
+
+	// storageClass is the S3 storage class the objects are stored in, STANDARD when it is not known.
+	storageClass string
+
+	storageGB            decimal.Decimal
+	monthlyTier1Requests decimal.Decimal
+	monthlyTier2Requests decimal.Decimal
+}
+
+// s3BucketValues represents the structure of Terraform values for aws_s3_bucket resource.
+type s3BucketValues struct {
+	RegionCode           string
+	StorageClass         string
+	StorageGB            float64
+	MonthlyTier1Requests float64
+	MonthlyTier2Requests float64
+}
+
+// decodeS3BucketValues decodes and returns s3BucketValues from a Terraform values map.
+func decodeS3BucketValues(request api.GetS3BucketCostRequest) s3BucketValues {
+	return s3BucketValues{
+		RegionCode:           request.RegionCode,
+		StorageClass:         request.StorageClass,
+		StorageGB:            request.StorageGB,
+		MonthlyTier1Requests: request.MonthlyTier1Requests,
+		MonthlyTier2Requests: request.MonthlyTier2Requests,
+	}
+}
+
+// newS3Bucket creates a new S3Bucket from s3BucketValues.
+func (p *Provider) newS3Bucket(vals s3BucketValues) *S3Bucket {
+	bucket := &S3Bucket{
+		provider:             p,
+		region:               region.Code(vals.RegionCode),
+		storageClass:         "STANDARD",
+		storageGB:            decimal.NewFromFloat(vals.StorageGB),
+		monthlyTier1Requests: decimal.NewFromFloat(vals.MonthlyTier1Requests),
+		monthlyTier2Requests: decimal.NewFromFloat(vals.MonthlyTier2Requests),
+	}
+
+	if _, ok := s3VolumeTypes[vals.StorageClass]; ok {
+		bucket.storageClass = vals.StorageClass
+	}
+
+	return bucket
+}
+
+// Components returns the price component queries that make up this S3Bucket.
+func (b *S3Bucket) Components() []query.Component {
+	return []query.Component{
+		b.storageComponent(),
+		b.requestsComponent("PUT, COPY, POST, LIST requests", "S3-API-Tier1", b.monthlyTier1Requests),
+		b.requestsComponent("GET, SELECT, and all other requests", "S3-API-Tier2", b.monthlyTier2Requests),
+	}
+}
+
+func (b *S3Bucket) storageComponent() query.Component {
+	return query.Component{
+		Name:            "Storage",
+		MonthlyQuantity: b.storageGB,
+		Unit:            "GB",
+		Details:         []string{b.storageClass},
+		Usage:           true,
+		ProductFilter: &product.Filter{
+			Provider: util.StringPtr(b.provider.key),
+			Service:  util.StringPtr("AmazonS3"),
+			Family:   util.StringPtr("Storage"),
+			Location: util.StringPtr(b.region.String()),
+			AttributeFilters: []*product.AttributeFilter{
+				{Key: "VolumeType", Value: util.StringPtr(s3VolumeTypes[b.storageClass])},
+			},
+		},
+		PriceFilter: &price.Filter{
+			Unit: util.StringPtr("GB-Mo"),
+			AttributeFilters: []*price.AttributeFilter{
+				{Key: "StartingRange", Value: util.StringPtr("0")},
+			},
+		},
+	}
+}
+
+func (b *S3Bucket) requestsComponent(name, group string, quantity decimal.Decimal) query.Component {
+	return query.Component{
+		Name:            name,
+		MonthlyQuantity: quantity,
+		Unit:            "Requests",
+		Details:         []string{b.storageClass},
+		Usage:           true,
+		ProductFilter: &product.Filter{
+			Provider: util.StringPtr(b.provider.key),
+			Service:  util.StringPtr("AmazonS3"),
+			Family:   util.StringPtr("API Request"),
+			Location: util.StringPtr(b.region.String()),
+			AttributeFilters: []*product.AttributeFilter{
+				{Key: "Group", Value: util.StringPtr(group)},
+			},
+		},
+		PriceFilter: &price.Filter{
+			Unit: util.StringPtr("Requests"),
+		},
+	}
+}
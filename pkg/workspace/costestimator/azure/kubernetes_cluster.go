@@ -0,0 +1,99 @@
+package azure
+
+import (
+	"github.com/opengovern/opengovernance/pkg/workspace/api"
+	"github.com/opengovern/opengovernance/pkg/workspace/costestimator/price"
+	"github.com/opengovern/opengovernance/pkg/workspace/costestimator/product"
+	"github.com/opengovern/opengovernance/pkg/workspace/costestimator/query"
+	"github.com/opengovern/opengovernance/pkg/workspace/costestimator/util"
+	"github.com/shopspring/decimal"
+)
+
+// KubernetesCluster is the entity that holds the logic to calculate price
+// of the azurerm_kubernetes_cluster
+type KubernetesCluster struct {
+	provider *Provider
+
+	location string
+	// skuTier is the tier of the control plane, the "Free" tier has no cost
+	skuTier    string
+	nodeVMSize string
+	nodeCount  int64
+}
+
+// kubernetesClusterValues is holds the values that we need to be able
+// to calculate the price of the KubernetesCluster
+type kubernetesClusterValues struct {
+	Location   string `mapstructure:"location"`
+	SkuTier    string `mapstructure:"sku_tier"`
+	NodeVMSize string `mapstructure:"vm_size"`
+	NodeCount  int64  `mapstructure:"node_count"`
+}
+
+// decodeKubernetesClusterValues decodes and returns kubernetesClusterValues from a Terraform values map.
+func decodeKubernetesClusterValues(request api.GetAzureKubernetesClusterRequest) kubernetesClusterValues {
+	return kubernetesClusterValues{
+		Location:   request.RegionCode,
+		SkuTier:    request.SkuTier,
+		NodeVMSize: request.NodeVMSize,
+		NodeCount:  int64(request.NodeCount),
+	}
+}
+
+// newKubernetesCluster initializes a new KubernetesCluster from the provider
+func (p *Provider) newKubernetesCluster(vals kubernetesClusterValues) *KubernetesCluster {
+	inst := &KubernetesCluster{
+		provider: p,
+
+		location:   getLocationName(vals.Location),
+		skuTier:    "Free",
+		nodeVMSize: vals.NodeVMSize,
+		nodeCount:  vals.NodeCount,
+	}
+
+	if vals.SkuTier != "" {
+		inst.skuTier = vals.SkuTier
+	}
+
+	return inst
+}
+
+// Components returns the price component queries that make up this KubernetesCluster, the nodes of the default
+// node pool are estimated as Linux virtual machines.
+func (inst *KubernetesCluster) Components() []query.Component {
+	var components []query.Component
+
+	if inst.skuTier != "Free" {
+		components = append(components, inst.controlPlaneComponent())
+	}
+
+	if inst.nodeVMSize != "" {
+		nodes := linuxVirtualMachineComponent(inst.provider.key, inst.location, inst.nodeVMSize)
+		nodes.Name = "Default node pool: " + nodes.Name
+		nodes.HourlyQuantity = decimal.NewFromInt(inst.nodeCount)
+		components = append(components, nodes)
+	}
+
+	return components
+}
+
+func (inst *KubernetesCluster) controlPlaneComponent() query.Component {
+	return query.Component{
+		Name:           "Uptime SLA",
+		HourlyQuantity: decimal.NewFromInt(1),
+		Details:        []string{inst.skuTier},
+		ProductFilter: &product.Filter{
+			Provider: util.StringPtr(inst.provider.key),
+			Service:  util.StringPtr("Azure Kubernetes Service"),
+			Family:   util.StringPtr("Compute"),
+			Location: util.StringPtr(inst.location),
+			AttributeFilters: []*product.AttributeFilter{
+				{Key: "sku_name", Value: util.StringPtr(inst.skuTier)},
+				{Key: "meter_name", ValueRegex: util.StringPtr("Uptime SLA")},
+			},
+		},
+		PriceFilter: &price.Filter{
+			Unit: util.StringPtr("1 Hour"),
+		},
+	}
+}
@@ -0,0 +1,115 @@
+package azure
+
+import (
+	"fmt"
+	"github.com/opengovern/opengovernance/pkg/workspace/api"
+	"github.com/opengovern/opengovernance/pkg/workspace/costestimator/query"
+	"github.com/opengovern/opengovernance/pkg/workspace/costestimator/util"
+)
+
+// planResourceTypes are the resource types PlanResourceComponents estimates.
+var planResourceTypes = map[string]bool{
+	"azurerm_linux_virtual_machine":   true,
+	"azurerm_windows_virtual_machine": true,
+	"azurerm_virtual_machine":         true,
+	"azurerm_managed_disk":            true,
+	"azurerm_lb":                      true,
+	"azurerm_load_balancer":           true,
+	"azurerm_virtual_network":         true,
+	"azurerm_kubernetes_cluster":      true,
+	"azurerm_storage_account":         true,
+}
+
+// PlanResourceComponents returns Component queries for a resource of a terraform plan from its values. Resource
+// types without an estimator return query.ErrUnsupported.
+func (p *Provider) PlanResourceComponents(resourceType string, values map[string]any) ([]query.Component, error) {
+	if !planResourceTypes[resourceType] {
+		return nil, fmt.Errorf("%w: %s", query.ErrUnsupported, resourceType)
+	}
+	location := util.ValueString(values, "location")
+	if location == "" {
+		return nil, fmt.Errorf("location is not known")
+	}
+
+	switch resourceType {
+	case "azurerm_linux_virtual_machine":
+		vals := linuxVirtualMachineValues{
+			Size:     util.ValueString(values, "size"),
+			Location: location,
+		}
+		return p.newLinuxVirtualMachine(vals).Components(), nil
+	case "azurerm_windows_virtual_machine":
+		vals := virtualMachineValues{
+			VMSize:          util.ValueString(values, "size"),
+			Location:        location,
+			OperatingSystem: WindowsOS,
+		}
+		return p.newVirtualMachine(vals).Components(), nil
+	case "azurerm_virtual_machine":
+		vals := virtualMachineValues{
+			VMSize:          util.ValueString(values, "vm_size"),
+			Location:        location,
+			OperatingSystem: LinuxOS,
+		}
+		if osDisk := util.ValueBlock(values, "storage_os_disk"); osDisk != nil && util.ValueString(osDisk, "os_type") == string(WindowsOS) {
+			vals.OperatingSystem = WindowsOS
+		} else if util.ValueBlock(values, "os_profile_windows_config") != nil {
+			vals.OperatingSystem = WindowsOS
+		}
+		return p.newVirtualMachine(vals).Components(), nil
+	case "azurerm_managed_disk":
+		vals := managedStorageValues{
+			SkuName:         util.ValueString(values, "storage_account_type"),
+			Location:        location,
+			DiskSize:        int32(util.ValueFloat(values, "disk_size_gb")),
+			BurstingEnabled: util.ValueBool(values, "on_demand_bursting_enabled"),
+			DiskThroughput:  int64(util.ValueFloat(values, "disk_mbps_read_write")),
+			DiskIOPs:        int64(util.ValueFloat(values, "disk_iops_read_write")),
+		}
+		return p.newManagedStorage(vals).Components(), nil
+	case "azurerm_lb", "azurerm_load_balancer":
+		sku := util.ValueString(values, "sku")
+		if sku == "" {
+			sku = "Basic"
+		}
+		tier := util.ValueString(values, "sku_tier")
+		if tier == "" {
+			tier = "Regional"
+		}
+		vals := decodeLoadBalancerValues(api.GetAzureLoadBalancerRequest{
+			RegionCode: location,
+			SkuName:    sku,
+			SkuTier:    tier,
+		})
+		return p.newLoadBalancer(vals).Components(), nil
+	case "azurerm_virtual_network":
+		vals := decodeVirtualNetworkValues(api.GetAzureVirtualNetworkRequest{
+			RegionCode: location,
+		})
+		return p.newVirtualNetwork(vals).Components(), nil
+	case "azurerm_kubernetes_cluster":
+		vals := kubernetesClusterValues{
+			Location: location,
+			SkuTier:  util.ValueString(values, "sku_tier"),
+		}
+		if pool := util.ValueBlock(values, "default_node_pool"); pool != nil {
+			vals.NodeVMSize = util.ValueString(pool, "vm_size")
+			vals.NodeCount = int64(util.ValueFloat(pool, "node_count"))
+			if vals.NodeCount == 0 {
+				// the node count of an auto scaled pool is not known before apply
+				vals.NodeCount = int64(util.ValueFloat(pool, "min_count"))
+			}
+		}
+		return p.newKubernetesCluster(vals).Components(), nil
+	case "azurerm_storage_account":
+		vals := storageAccountValues{
+			Location:        location,
+			AccountKind:     util.ValueString(values, "account_kind"),
+			AccountTier:     util.ValueString(values, "account_tier"),
+			ReplicationType: util.ValueString(values, "account_replication_type"),
+			AccessTier:      util.ValueString(values, "access_tier"),
+		}
+		return p.newStorageAccount(vals).Components(), nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,111 @@
+package azure_test
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/opengovern/opengovernance/pkg/workspace/costestimator/azure"
+	"github.com/opengovern/opengovernance/pkg/workspace/costestimator/query"
+)
+
+func TestProvider_PlanResourceComponents(t *testing.T) {
+	provider, err := azure.NewProvider("Azure")
+	require.NoError(t, err)
+
+	t.Run("KubernetesCluster", func(t *testing.T) {
+		values := map[string]any{
+			"location": "West Europe",
+			"sku_tier": "Standard",
+			"default_node_pool": []any{map[string]any{
+				"vm_size":   "Standard_D4s_v5",
+				"min_count": float64(3),
+			}},
+		}
+		components, err := provider.PlanResourceComponents("azurerm_kubernetes_cluster", values)
+		require.NoError(t, err)
+		require.Len(t, components, 2)
+
+		assert.Equal(t, "Uptime SLA", components[0].Name)
+		assert.Equal(t, "westeurope", *components[0].ProductFilter.Location)
+		assert.Equal(t, "Default node pool: Compute", components[1].Name)
+		assert.True(t, components[1].HourlyQuantity.Equal(decimal.NewFromInt(3)))
+	})
+
+	t.Run("KubernetesClusterFreeTier", func(t *testing.T) {
+		values := map[string]any{
+			"location":          "westeurope",
+			"default_node_pool": []any{map[string]any{"vm_size": "Standard_B2s", "node_count": float64(1)}},
+		}
+		components, err := provider.PlanResourceComponents("azurerm_kubernetes_cluster", values)
+		require.NoError(t, err)
+		require.Len(t, components, 1)
+		assert.Equal(t, "Default node pool: Compute", components[0].Name)
+	})
+
+	t.Run("StorageAccount", func(t *testing.T) {
+		tcs := []struct {
+			name    string
+			values  map[string]any
+			skuName string
+		}{
+			{
+				name:    "Defaults",
+				values:  map[string]any{"location": "eastus"},
+				skuName: "Hot LRS",
+			},
+			{
+				name:    "Cool",
+				values:  map[string]any{"location": "eastus", "account_tier": "Standard", "account_replication_type": "GRS", "access_tier": "Cool"},
+				skuName: "Cool GRS",
+			},
+			{
+				name:    "Premium",
+				values:  map[string]any{"location": "eastus", "account_tier": "Premium", "account_replication_type": "ZRS", "access_tier": "Hot"},
+				skuName: "Premium ZRS",
+			},
+		}
+		for _, tc := range tcs {
+			t.Run(tc.name, func(t *testing.T) {
+				components, err := provider.PlanResourceComponents("azurerm_storage_account", tc.values)
+				require.NoError(t, err)
+				require.Len(t, components, 3)
+				for _, comp := range components {
+					assert.Equal(t, []string{tc.skuName}, comp.Details)
+					assert.True(t, comp.Usage)
+				}
+			})
+		}
+	})
+
+	t.Run("Unsupported", func(t *testing.T) {
+		components, err := provider.PlanResourceComponents("azurerm_cosmosdb_account", map[string]any{"location": "eastus"})
+		assert.ErrorIs(t, err, query.ErrUnsupported)
+		assert.Empty(t, components)
+	})
+}
+
+func TestProvider_ResourceComponents(t *testing.T) {
+	provider, err := azure.NewProvider("Azure")
+	require.NoError(t, err)
+
+	t.Run("StorageAccount", func(t *testing.T) {
+		components, err := provider.ResourceComponents(zap.NewNop(), "azurerm_storage_account", map[string]interface{}{
+			"RegionCode":             "eastus",
+			"StorageGB":              float64(500),
+			"MonthlyWriteOperations": float64(250000),
+		})
+		require.NoError(t, err)
+		require.Len(t, components, 3)
+		assert.True(t, components[0].MonthlyQuantity.Equal(decimal.NewFromInt(500)))
+		assert.True(t, components[1].MonthlyQuantity.Equal(decimal.NewFromInt(25)))
+	})
+
+	t.Run("Unsupported", func(t *testing.T) {
+		_, err := provider.ResourceComponents(zap.NewNop(), "azurerm_cosmosdb_account", map[string]interface{}{})
+		assert.ErrorIs(t, err, query.ErrUnsupported)
+	})
+}
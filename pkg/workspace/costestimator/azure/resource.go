@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/opengovern/opengovernance/pkg/workspace/api"
 	"github.com/opengovern/opengovernance/pkg/workspace/costestimator/query"
+	"github.com/opengovern/opengovernance/pkg/workspace/costestimator/util"
 	"go.uber.org/zap"
 )
 
@@ -76,7 +77,8 @@ func NewProvider(key string) (*Provider, error) {
 // Name returns the Provider's common name.
 func (p *Provider) Name() string { return p.key }
 
-// ResourceComponents returns Component queries for a given terraform.Resource.
+// ResourceComponents returns Component queries for a given terraform.Resource. Resource types without an
+// estimator return query.ErrUnsupported.
 func (p *Provider) ResourceComponents(logger *zap.Logger, resourceType string, request any) ([]query.Component, error) {
 	fmt.Println("REQUEST: ", request)
 	fmt.Println("RESOURCE TYPE: ", resourceType)
@@ -163,8 +165,36 @@ func (p *Provider) ResourceComponents(logger *zap.Logger, resourceType string, r
 		vals := decodeVirtualNetworkValues(vnRequest)
 		logger.Info("Vals", zap.Any("Vals", vals))
 		return p.newVirtualNetwork(vals).Components(), nil
+	case "azurerm_kubernetes_cluster":
+		req, ok := request.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("could not parse request")
+		}
+		vals := decodeKubernetesClusterValues(api.GetAzureKubernetesClusterRequest{
+			RegionCode: util.ValueString(req, "RegionCode"),
+			SkuTier:    util.ValueString(req, "SkuTier"),
+			NodeVMSize: util.ValueString(req, "NodeVMSize"),
+			NodeCount:  util.ValueFloat(req, "NodeCount"),
+		})
+		return p.newKubernetesCluster(vals).Components(), nil
+	case "azurerm_storage_account":
+		req, ok := request.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("could not parse request")
+		}
+		vals := decodeStorageAccountValues(api.GetAzureStorageAccountRequest{
+			RegionCode:             util.ValueString(req, "RegionCode"),
+			AccountKind:            util.ValueString(req, "AccountKind"),
+			AccountTier:            util.ValueString(req, "AccountTier"),
+			ReplicationType:        util.ValueString(req, "ReplicationType"),
+			AccessTier:             util.ValueString(req, "AccessTier"),
+			StorageGB:              util.ValueFloat(req, "StorageGB"),
+			MonthlyWriteOperations: util.ValueFloat(req, "MonthlyWriteOperations"),
+			MonthlyReadOperations:  util.ValueFloat(req, "MonthlyReadOperations"),
+		})
+		return p.newStorageAccount(vals).Components(), nil
 	default:
-		return nil, fmt.Errorf("resource type not found in cost calculator")
+		return nil, fmt.Errorf("%w: %s", query.ErrUnsupported, resourceType)
 	}
 }
 
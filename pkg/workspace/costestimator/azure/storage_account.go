@@ -0,0 +1,137 @@
+package azure
+
+import (
+	"fmt"
+	"github.com/opengovern/opengovernance/pkg/workspace/api"
+	"github.com/opengovern/opengovernance/pkg/workspace/costestimator/product"
+	"github.com/opengovern/opengovernance/pkg/workspace/costestimator/query"
+	"github.com/opengovern/opengovernance/pkg/workspace/costestimator/util"
+	"github.com/shopspring/decimal"
+)
+
+// StorageAccount is the entity that holds the logic to calculate price
+// of the blob storage of the azurerm_storage_account
+type StorageAccount struct {
+	provider *Provider
+
+	location        string
+	accountKind     string
+	accountTier     string
+	replicationType string
+	accessTier      string
+
+	// storageGB and the monthly operations are usage values, the data of an account is not part of its definition
+	storageGB              decimal.Decimal
+	monthlyWriteOperations decimal.Decimal
+	monthlyReadOperations  decimal.Decimal
+}
+
+// storageAccountValues is holds the values that we need to be able
+// to calculate the price of the StorageAccount
+type storageAccountValues struct {
+	Location               string  `mapstructure:"location"`
+	AccountKind            string  `mapstructure:"account_kind"`
+	AccountTier            string  `mapstructure:"account_tier"`
+	ReplicationType        string  `mapstructure:"account_replication_type"`
+	AccessTier             string  `mapstructure:"access_tier"`
+	StorageGB              float64 `mapstructure:"storage_gb"`
+	MonthlyWriteOperations float64 `mapstructure:"monthly_write_operations"`
+	MonthlyReadOperations  float64 `mapstructure:"monthly_read_operations"`
+}
+
+// decodeStorageAccountValues decodes and returns storageAccountValues from a Terraform values map.
+func decodeStorageAccountValues(request api.GetAzureStorageAccountRequest) storageAccountValues {
+	return storageAccountValues{
+		Location:               request.RegionCode,
+		AccountKind:            request.AccountKind,
+		AccountTier:            request.AccountTier,
+		ReplicationType:        request.ReplicationType,
+		AccessTier:             request.AccessTier,
+		StorageGB:              request.StorageGB,
+		MonthlyWriteOperations: request.MonthlyWriteOperations,
+		MonthlyReadOperations:  request.MonthlyReadOperations,
+	}
+}
+
+// newStorageAccount initializes a new StorageAccount from the provider
+func (p *Provider) newStorageAccount(vals storageAccountValues) *StorageAccount {
+	inst := &StorageAccount{
+		provider: p,
+
+		location:               getLocationName(vals.Location),
+		accountKind:            "StorageV2",
+		accountTier:            "Standard",
+		replicationType:        "LRS",
+		accessTier:             "Hot",
+		storageGB:              decimal.NewFromFloat(vals.StorageGB),
+		monthlyWriteOperations: decimal.NewFromFloat(vals.MonthlyWriteOperations),
+		monthlyReadOperations:  decimal.NewFromFloat(vals.MonthlyReadOperations),
+	}
+
+	if vals.AccountKind != "" {
+		inst.accountKind = vals.AccountKind
+	}
+	if vals.AccountTier != "" {
+		inst.accountTier = vals.AccountTier
+	}
+	if vals.ReplicationType != "" {
+		inst.replicationType = vals.ReplicationType
+	}
+	if vals.AccessTier != "" {
+		inst.accessTier = vals.AccessTier
+	}
+
+	return inst
+}
+
+// Components returns the price component queries that make up this StorageAccount. The operations are priced by
+// 10K operations.
+func (inst *StorageAccount) Components() []query.Component {
+	return []query.Component{
+		inst.blobComponent("Capacity", "GB", "Data Stored$", inst.storageGB),
+		inst.blobComponent("Write operations", "10K operations", "Write Operations$", inst.monthlyWriteOperations.Div(decimal.NewFromInt(10000))),
+		inst.blobComponent("Read operations", "10K operations", "Read Operations$", inst.monthlyReadOperations.Div(decimal.NewFromInt(10000))),
+	}
+}
+
+// skuName returns the sku of the blob prices of the account, the premium accounts have no access tier.
+func (inst *StorageAccount) skuName() string {
+	if inst.accountTier == "Premium" {
+		return fmt.Sprintf("Premium %s", inst.replicationType)
+	}
+	return fmt.Sprintf("%s %s", inst.accessTier, inst.replicationType)
+}
+
+// productName returns the product of the blob prices of the account.
+func (inst *StorageAccount) productName() string {
+	switch {
+	case inst.accountTier == "Premium":
+		return "Premium Block Blob"
+	case inst.accountKind == "BlobStorage":
+		return "Blob Storage"
+	default:
+		return "General Block Blob v2"
+	}
+}
+
+func (inst *StorageAccount) blobComponent(name, unit, meterRegex string, quantity decimal.Decimal) query.Component {
+	return query.Component{
+		Name:            name,
+		MonthlyQuantity: quantity,
+		Unit:            unit,
+		Details:         []string{inst.skuName()},
+		Usage:           true,
+		ProductFilter: &product.Filter{
+			Provider: util.StringPtr(inst.provider.key),
+			// the blob prices are kept apart from the managed disk prices of the "Storage" family
+			Service:  util.StringPtr("Storage"),
+			Family:   util.StringPtr("Blob Storage"),
+			Location: util.StringPtr(inst.location),
+			AttributeFilters: []*product.AttributeFilter{
+				{Key: "product_name", Value: util.StringPtr(inst.productName())},
+				{Key: "sku_name", Value: util.StringPtr(inst.skuName())},
+				{Key: "meter_name", ValueRegex: util.StringPtr(meterRegex)},
+			},
+		},
+	}
+}
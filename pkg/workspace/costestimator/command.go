@@ -0,0 +1,167 @@
+package costestimator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/opengovern/og-util/pkg/httpclient"
+	"github.com/opengovern/opengovernance/pkg/workspace/api"
+	"github.com/opengovern/opengovernance/pkg/workspace/costestimator/terraform"
+	"github.com/spf13/cobra"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// PlanCommand estimates the cost impact of a terraform plan through the workspace cost estimator, the markdown
+// output is meant to be posted as a pull request comment by CI.
+func PlanCommand() *cobra.Command {
+	var (
+		planFile   string
+		baseURL    string
+		token      string
+		regionCode string
+		output     string
+		all        bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "plan",
+		Short: "Estimates the monthly cost diff of a terraform plan from `terraform show -json` output",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			switch {
+			case baseURL == "":
+				return errors.New("missing required flag 'url'")
+			case output != "markdown" && output != "json":
+				return fmt.Errorf("invalid output %s, valid values are markdown and json", output)
+			default:
+				return nil
+			}
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			var data []byte
+			var err error
+			if planFile == "-" {
+				data, err = io.ReadAll(cmd.InOrStdin())
+			} else {
+				data, err = os.ReadFile(planFile)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to read plan: %w", err)
+			}
+			// fail before calling the estimator when the input is not a plan
+			if _, err := terraform.ParsePlan(data); err != nil {
+				return err
+			}
+
+			response, err := requestPlanCost(cmd.Context(), baseURL, token, regionCode, data)
+			if err != nil {
+				return err
+			}
+
+			if output == "json" {
+				out, err := json.MarshalIndent(response, "", "  ")
+				if err != nil {
+					return err
+				}
+				_, err = fmt.Fprintln(cmd.OutOrStdout(), string(out))
+				return err
+			}
+			_, err = fmt.Fprint(cmd.OutOrStdout(), PlanCostMarkdown(*response, all))
+			return err
+		},
+	}
+
+	cmd.Flags().StringVar(&planFile, "plan", "-", "Path of the `terraform show -json` output, - reads it from stdin")
+	cmd.Flags().StringVar(&baseURL, "url", os.Getenv("COST_ESTIMATOR_URL"), "Base URL of the workspace service")
+	cmd.Flags().StringVar(&token, "token", os.Getenv("COST_ESTIMATOR_TOKEN"), "API token sent as the bearer token")
+	cmd.Flags().StringVar(&regionCode, "region", "", "AWS region used when the plan does not configure the aws provider region")
+	cmd.Flags().StringVar(&output, "output", "markdown", "Output format, markdown or json")
+	cmd.Flags().BoolVar(&all, "all", false, "List the resources without a cost change too")
+
+	return cmd
+}
+
+func requestPlanCost(ctx context.Context, baseURL, token, regionCode string, plan []byte) (*api.GetTerraformPlanCostResponse, error) {
+	url := fmt.Sprintf("%s/api/v1/costestimator/terraform-plan?regionCode=%s", strings.TrimSuffix(baseURL, "/"), url.QueryEscape(regionCode))
+
+	headers := map[string]string{}
+	if token != "" {
+		headers["Authorization"] = "Bearer " + token
+	}
+	var response api.GetTerraformPlanCostResponse
+	if _, err := httpclient.DoRequest(ctx, http.MethodPost, url, headers, plan, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// PlanCostMarkdown renders the cost diff of a plan as a markdown table, the resources without a cost change are
+// only listed when all is set.
+func PlanCostMarkdown(response api.GetTerraformPlanCostResponse, all bool) string {
+	currency := response.Currency
+	if currency == "" {
+		currency = "USD"
+	}
+
+	var sb strings.Builder
+	sb.WriteString("### Terraform plan cost impact\n\n")
+	sb.WriteString(fmt.Sprintf("Monthly cost: %s → %s (%s %s)\n\n",
+		formatCost(response.PriorMonthlyCost), formatCost(response.PlannedMonthlyCost), formatCostDiff(response.MonthlyCostDiff), currency))
+
+	var rows []api.TerraformResourceCostDiff
+	for _, res := range response.Resources {
+		if all || math.Abs(res.MonthlyCostDiff) >= 0.005 || len(res.Errors) > 0 {
+			rows = append(rows, res)
+		}
+	}
+	if len(rows) > 0 {
+		sb.WriteString("| Resource | Prior | Planned | Diff |\n")
+		sb.WriteString("|---|---:|---:|---:|\n")
+		for _, res := range rows {
+			address := "`" + res.Address + "`"
+			if len(res.Errors) > 0 {
+				address += " ⚠️ price not found for some components"
+			}
+			sb.WriteString(fmt.Sprintf("| %s | %s | %s | %s |\n", address,
+				formatCost(res.PriorMonthlyCost), formatCost(res.PlannedMonthlyCost), formatCostDiff(res.MonthlyCostDiff)))
+		}
+		sb.WriteString("\n")
+	} else {
+		sb.WriteString("No resource changes its cost.\n\n")
+	}
+
+	if len(response.SkippedAddresses) > 0 {
+		sb.WriteString(fmt.Sprintf("<details><summary>%d resources are not estimated</summary>\n\n", len(response.SkippedAddresses)))
+		unsupported := make(map[string]bool)
+		for _, address := range response.UnsupportedAddresses {
+			unsupported[address] = true
+		}
+		for _, address := range response.SkippedAddresses {
+			if unsupported[address] {
+				sb.WriteString("- `" + address + "` (resource type not supported)\n")
+			} else {
+				sb.WriteString("- `" + address + "`\n")
+			}
+		}
+		sb.WriteString("\n</details>\n")
+	}
+	return sb.String()
+}
+
+func formatCost(v float64) string {
+	return fmt.Sprintf("%.2f", v)
+}
+
+func formatCostDiff(v float64) string {
+	if v > 0 {
+		return fmt.Sprintf("+%.2f", v)
+	}
+	return fmt.Sprintf("%.2f", v)
+}
@@ -0,0 +1,147 @@
+package costestimator
+
+import (
+	"errors"
+	"fmt"
+	"github.com/opengovern/opengovernance/pkg/workspace/api"
+	"github.com/opengovern/opengovernance/pkg/workspace/costestimator/aws"
+	"github.com/opengovern/opengovernance/pkg/workspace/costestimator/azure"
+	"github.com/opengovern/opengovernance/pkg/workspace/costestimator/backend"
+	"github.com/opengovern/opengovernance/pkg/workspace/costestimator/cost"
+	"github.com/opengovern/opengovernance/pkg/workspace/costestimator/postgresql"
+	"github.com/opengovern/opengovernance/pkg/workspace/costestimator/query"
+	"github.com/opengovern/opengovernance/pkg/workspace/costestimator/terraform"
+	"github.com/opengovern/opengovernance/pkg/workspace/db"
+	"go.uber.org/zap"
+	"sort"
+	"strings"
+)
+
+// CalcPlanCosts estimates the monthly cost of the prior state and of the planned values of a terraform plan and
+// returns the difference by resource. regionCode is used for the aws resources when the plan does not configure
+// the region of the aws provider.
+func CalcPlanCosts(db *db.Database, logger *zap.Logger, plan *terraform.Plan, regionCode string) (*api.GetTerraformPlanCostResponse, error) {
+	if region := plan.ProviderRegion("aws"); region != "" {
+		regionCode = region
+	}
+
+	backend := postgresql.NewBackend(db)
+	priorQueries, priorUnsupported := QueryResources(logger, plan.PriorResources(), regionCode)
+	prior, err := planState(backend, priorQueries)
+	if err != nil {
+		logger.Error("Error while making prior cost state", zap.Error(err))
+		return nil, err
+	}
+	plannedQueries, plannedUnsupported := QueryResources(logger, plan.PlannedResources(), regionCode)
+	planned, err := planState(backend, plannedQueries)
+	if err != nil {
+		logger.Error("Error while making planned cost state", zap.Error(err))
+		return nil, err
+	}
+
+	costPlan := cost.NewPlan("terraform", prior, planned)
+	priorCost, err := costPlan.PriorCost()
+	if err != nil {
+		return nil, err
+	}
+	plannedCost, err := costPlan.PlannedCost()
+	if err != nil {
+		return nil, err
+	}
+
+	currency := plannedCost.Currency
+	if currency == "" {
+		currency = priorCost.Currency
+	}
+	response := api.GetTerraformPlanCostResponse{
+		Currency:           currency,
+		PriorMonthlyCost:   priorCost.InexactFloat64(),
+		PlannedMonthlyCost: plannedCost.InexactFloat64(),
+		MonthlyCostDiff:    plannedCost.Sub(priorCost.Decimal).InexactFloat64(),
+		SkippedAddresses:   costPlan.SkippedAddresses(),
+	}
+	unsupported := make(map[string]bool)
+	for _, address := range append(priorUnsupported, plannedUnsupported...) {
+		if !unsupported[address] {
+			unsupported[address] = true
+			response.UnsupportedAddresses = append(response.UnsupportedAddresses, address)
+		}
+	}
+	sort.Strings(response.UnsupportedAddresses)
+	for _, rd := range costPlan.ResourceDifferences() {
+		rdPrior, err := rd.PriorCost()
+		if err != nil {
+			return nil, err
+		}
+		rdPlanned, err := rd.PlannedCost()
+		if err != nil {
+			return nil, err
+		}
+		diff := api.TerraformResourceCostDiff{
+			Address:            rd.Address,
+			Provider:           rd.Provider,
+			Type:               rd.Type,
+			PriorMonthlyCost:   rdPrior.InexactFloat64(),
+			PlannedMonthlyCost: rdPlanned.InexactFloat64(),
+			MonthlyCostDiff:    rdPlanned.Sub(rdPrior.Decimal).InexactFloat64(),
+		}
+		if errs := rd.Errors(); len(errs) > 0 {
+			diff.Errors = make(map[string]string)
+			for name, err := range errs {
+				diff.Errors[name] = err.Error()
+			}
+		}
+		response.Resources = append(response.Resources, diff)
+	}
+	sort.Slice(response.Resources, func(i, j int) bool {
+		return response.Resources[i].Address < response.Resources[j].Address
+	})
+	return &response, nil
+}
+
+// QueryResources maps the resources of a terraform plan to cost queries. Resources without an estimator, or with
+// values the estimator can not read, are returned without components so the cost state marks them as skipped, the
+// addresses of the ones without an estimator are returned as unsupported.
+func QueryResources(logger *zap.Logger, resources []terraform.Resource, regionCode string) ([]query.Resource, []string) {
+	awsProvider, _ := aws.NewProvider("AWS")
+	azureProvider, _ := azure.NewProvider("Azure")
+
+	var queries []query.Resource
+	var unsupported []string
+	for _, res := range resources {
+		resource := query.Resource{
+			Address: res.Address,
+			Type:    res.Type,
+		}
+		var components []query.Component
+		var err error
+		switch {
+		case strings.HasPrefix(res.Type, "aws_"):
+			resource.Provider = "AWS"
+			components, err = awsProvider.PlanResourceComponents(res.Type, res.Values, regionCode)
+		case strings.HasPrefix(res.Type, "azurerm_"):
+			resource.Provider = "Azure"
+			components, err = azureProvider.PlanResourceComponents(res.Type, res.Values)
+		default:
+			err = fmt.Errorf("%w: %s", query.ErrUnsupported, res.Type)
+		}
+		if errors.Is(err, query.ErrUnsupported) {
+			unsupported = append(unsupported, res.Address)
+		} else if err != nil {
+			logger.Warn("Skipping terraform resource", zap.String("address", res.Address), zap.Error(err))
+		} else {
+			resource.Components = components
+		}
+		queries = append(queries, resource)
+	}
+	return queries, unsupported
+}
+
+// planState returns the cost state of the queries, nil when there are none since a plan can create every resource
+// or destroy all of them.
+func planState(backend backend.Backend, queries []query.Resource) (*cost.State, error) {
+	if len(queries) == 0 {
+		return nil, nil
+	}
+	return cost.NewState(backend, queries)
+}
@@ -108,6 +108,70 @@ type AwsRdsIopsPrice struct {
 	Price            decimal.Decimal
 }
 
+// AwsNatGatewayPrice Service = AmazonEC2, ProductFamily = NAT Gateway
+type AwsNatGatewayPrice struct {
+	SKU           string `gorm:"primaryKey"`
+	EffectiveDate int64
+	RegionCode    string
+	UsageType     string
+	PriceUnit     string
+	Price         decimal.Decimal
+}
+
+// AwsEfsPrice Service = AmazonEFS, ProductFamily = (Storage), (Provisioned Throughput)
+type AwsEfsPrice struct {
+	SKU           string `gorm:"primaryKey"`
+	EffectiveDate int64
+	ProductFamily string
+	RegionCode    string
+	StorageClass  string
+	UsageType     string
+	PriceUnit     string
+	Price         decimal.Decimal
+}
+
+// AwsS3StoragePrice Service = AmazonS3, ProductFamily = Storage
+type AwsS3StoragePrice struct {
+	SKU           string `gorm:"primaryKey"`
+	EffectiveDate int64
+	RegionCode    string
+	VolumeType    string
+	StartingRange string
+	PriceUnit     string
+	Price         decimal.Decimal
+}
+
+// AwsS3RequestPrice Service = AmazonS3, ProductFamily = API Request
+type AwsS3RequestPrice struct {
+	SKU           string `gorm:"primaryKey"`
+	EffectiveDate int64
+	RegionCode    string
+	Group         string
+	PriceUnit     string
+	Price         decimal.Decimal
+}
+
+// AwsEksPrice Service = AmazonEKS, ProductFamily = Compute
+type AwsEksPrice struct {
+	SKU           string `gorm:"primaryKey"`
+	EffectiveDate int64
+	RegionCode    string
+	UsageType     string
+	PriceUnit     string
+	Price         decimal.Decimal
+}
+
+// AwsElastiCachePrice Service = AmazonElastiCache, ProductFamily = Cache Instance
+type AwsElastiCachePrice struct {
+	SKU           string `gorm:"primaryKey"`
+	EffectiveDate int64
+	RegionCode    string
+	InstanceType  string
+	CacheEngine   string
+	PriceUnit     string
+	Price         decimal.Decimal
+}
+
 // AzureVirtualMachinePrice Service = Virtual Machines, Family = Compute
 type AzureVirtualMachinePrice struct {
 	SKU           string `gorm:"primaryKey"`
@@ -170,3 +234,26 @@ type AzureSqlServerDatabaseLicenseCostComponentPrice struct {
 	PriceUnit     string
 	Price         float64
 }
+
+// AzureKubernetesPrice Service = Azure Kubernetes Service, Family = Compute
+type AzureKubernetesPrice struct {
+	SKU           string `gorm:"primaryKey"`
+	EffectiveDate int64
+	ArmRegionName string
+	SkuName       string
+	MeterName     string
+	PriceUnit     string
+	Price         decimal.Decimal
+}
+
+// AzureBlobStoragePrice Service = Storage, Family = Blob Storage, the block blob products of the storage accounts
+type AzureBlobStoragePrice struct {
+	SKU           string `gorm:"primaryKey"`
+	EffectiveDate int64
+	ArmRegionName string
+	ProductName   string
+	SkuName       string
+	MeterName     string
+	PriceUnit     string
+	Price         decimal.Decimal
+}
@@ -43,10 +43,19 @@ var tables = map[string]string{
 	"AmazonRDS Database Instance":      "aws_rdsinstance_prices",
 	"AmazonRDS Database Storage":       "aws_rdsstorage_prices",
 	"AmazonRDS Provisioned IOPS":       "aws_rdsiops_prices",
+	"AmazonEC2 NAT Gateway":            "aws_natgateway_prices",
+	"AmazonEFS Storage":                "aws_efs_prices",
+	"AmazonEFS Provisioned Throughput": "aws_efs_prices",
+	"AmazonS3 Storage":                 "aws_s3storage_prices",
+	"AmazonS3 API Request":             "aws_s3request_prices",
+	"AmazonEKS Compute":                "aws_eks_prices",
+	"AmazonElastiCache Cache Instance": "aws_elasticache_prices",
 
-	"Virtual Machines Compute":   "azure_virtualmachine_prices",
-	"Storage Storage":            "azure_managedstorage_prices",
-	"Load Balancer Networking":   "azure_loadbalancer_prices",
-	"Virtual Network Networking": "azure_virtualnetwork_prices",
-	"VPN Gateway Networking":     "azure_vpngateway_prices",
+	"Virtual Machines Compute":         "azure_virtualmachine_prices",
+	"Storage Storage":                  "azure_managedstorage_prices",
+	"Load Balancer Networking":         "azure_loadbalancer_prices",
+	"Virtual Network Networking":       "azure_virtualnetwork_prices",
+	"VPN Gateway Networking":           "azure_vpngateway_prices",
+	"Azure Kubernetes Service Compute": "azure_kubernetes_prices",
+	"Storage Blob Storage":             "azure_blobstorage_prices",
 }
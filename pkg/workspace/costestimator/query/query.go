@@ -1,12 +1,18 @@
 package query
 
 import (
+	"errors"
+
 	"github.com/shopspring/decimal"
 
 	"github.com/opengovern/opengovernance/pkg/workspace/costestimator/price"
 	"github.com/opengovern/opengovernance/pkg/workspace/costestimator/product"
 )
 
+// ErrUnsupported is returned by the providers for the resource types they have no estimator for, the resource is
+// then reported as unsupported rather than counted with a zero cost.
+var ErrUnsupported = errors.New("resource type is not supported by the cost estimator")
+
 // Resource represents a single cloud resource. It has a unique Address and a collection of multiple
 // Component queries.
 type Resource struct {
@@ -0,0 +1,114 @@
+package terraform
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Plan is the part of the `terraform show -json` output of a plan file the cost estimator reads.
+type Plan struct {
+	FormatVersion string        `json:"format_version"`
+	PriorState    *State        `json:"prior_state"`
+	PlannedValues *Values       `json:"planned_values"`
+	Configuration Configuration `json:"configuration"`
+}
+
+// State is a snapshot of the resources, the plan carries the state it was made against as the prior state.
+type State struct {
+	Values *Values `json:"values"`
+}
+
+type Values struct {
+	RootModule Module `json:"root_module"`
+}
+
+type Module struct {
+	Address      string     `json:"address"`
+	Resources    []Resource `json:"resources"`
+	ChildModules []Module   `json:"child_modules"`
+}
+
+// Resource is a resource of a module with the values of its attributes, the attributes that are not known until
+// apply are missing from the values.
+type Resource struct {
+	Address      string         `json:"address"`
+	Mode         string         `json:"mode"`
+	Type         string         `json:"type"`
+	Name         string         `json:"name"`
+	ProviderName string         `json:"provider_name"`
+	Values       map[string]any `json:"values"`
+}
+
+type Configuration struct {
+	ProviderConfig map[string]ProviderConfig `json:"provider_config"`
+}
+
+type ProviderConfig struct {
+	Name        string                     `json:"name"`
+	Expressions map[string]json.RawMessage `json:"expressions"`
+}
+
+// ParsePlan parses the JSON output of `terraform show -json <planfile>`.
+func ParsePlan(data []byte) (*Plan, error) {
+	var plan Plan
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return nil, fmt.Errorf("failed to parse terraform plan: %w", err)
+	}
+	if plan.FormatVersion == "" {
+		return nil, fmt.Errorf("not a terraform plan, format_version is missing")
+	}
+	if plan.PlannedValues == nil {
+		return nil, fmt.Errorf("not a terraform plan, planned_values is missing")
+	}
+	return &plan, nil
+}
+
+// PriorResources returns the managed resources of the prior state.
+func (p Plan) PriorResources() []Resource {
+	if p.PriorState == nil || p.PriorState.Values == nil {
+		return nil
+	}
+	return managedResources(p.PriorState.Values.RootModule)
+}
+
+// PlannedResources returns the managed resources of the planned values.
+func (p Plan) PlannedResources() []Resource {
+	if p.PlannedValues == nil {
+		return nil
+	}
+	return managedResources(p.PlannedValues.RootModule)
+}
+
+// ProviderRegion returns the region the provider is configured with, empty when it is not a constant of the
+// configuration.
+func (p Plan) ProviderRegion(provider string) string {
+	config, ok := p.Configuration.ProviderConfig[provider]
+	if !ok {
+		return ""
+	}
+	raw, ok := config.Expressions["region"]
+	if !ok {
+		return ""
+	}
+	var expression struct {
+		ConstantValue any `json:"constant_value"`
+	}
+	if err := json.Unmarshal(raw, &expression); err != nil {
+		return ""
+	}
+	region, _ := expression.ConstantValue.(string)
+	return region
+}
+
+func managedResources(module Module) []Resource {
+	var resources []Resource
+	for _, res := range module.Resources {
+		if res.Mode == "managed" {
+			resources = append(resources, res)
+		}
+	}
+	for _, child := range module.ChildModules {
+		resources = append(resources, managedResources(child)...)
+	}
+	return resources
+}
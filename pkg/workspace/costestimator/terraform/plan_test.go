@@ -0,0 +1,70 @@
+package terraform
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPlan = `{
+  "format_version": "1.2",
+  "prior_state": {
+    "values": {
+      "root_module": {
+        "resources": [
+          {"address": "aws_instance.web", "mode": "managed", "type": "aws_instance", "name": "web", "values": {"instance_type": "t3.large"}},
+          {"address": "data.aws_ami.ubuntu", "mode": "data", "type": "aws_ami", "name": "ubuntu", "values": {}}
+        ]
+      }
+    }
+  },
+  "planned_values": {
+    "root_module": {
+      "resources": [
+        {"address": "aws_instance.web", "mode": "managed", "type": "aws_instance", "name": "web", "values": {"instance_type": "t3.medium"}}
+      ],
+      "child_modules": [
+        {
+          "address": "module.db",
+          "resources": [
+            {"address": "module.db.aws_db_instance.main", "mode": "managed", "type": "aws_db_instance", "name": "main", "values": {"instance_class": "db.t3.micro", "multi_az": true}}
+          ]
+        }
+      ]
+    }
+  },
+  "configuration": {
+    "provider_config": {
+      "aws": {"name": "aws", "expressions": {"region": {"constant_value": "eu-west-1"}, "assume_role": [{"role_arn": {"constant_value": "arn"}}]}},
+      "azurerm": {"name": "azurerm", "expressions": {"features": [{}]}}
+    }
+  }
+}`
+
+func TestParsePlan(t *testing.T) {
+	plan, err := ParsePlan([]byte(testPlan))
+	require.NoError(t, err)
+
+	prior := plan.PriorResources()
+	require.Len(t, prior, 1)
+	assert.Equal(t, "aws_instance.web", prior[0].Address)
+
+	planned := plan.PlannedResources()
+	require.Len(t, planned, 2)
+	assert.Equal(t, "t3.medium", planned[0].Values["instance_type"])
+	assert.Equal(t, "module.db.aws_db_instance.main", planned[1].Address)
+	assert.Equal(t, true, planned[1].Values["multi_az"])
+
+	assert.Equal(t, "eu-west-1", plan.ProviderRegion("aws"))
+	assert.Empty(t, plan.ProviderRegion("azurerm"))
+	assert.Empty(t, plan.ProviderRegion("google"))
+}
+
+func TestParsePlanInvalid(t *testing.T) {
+	_, err := ParsePlan([]byte(`{"resources": []}`))
+	assert.Error(t, err)
+
+	_, err = ParsePlan([]byte(`not json`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,55 @@
+package util
+
+// ValueString returns the string value of key in a Terraform values map or an empty string when it is missing or
+// unknown.
+func ValueString(values map[string]any, key string) string {
+	v, _ := values[key].(string)
+	return v
+}
+
+// ValueFloat returns the number value of key in a Terraform values map or zero when it is missing or unknown.
+func ValueFloat(values map[string]any, key string) float64 {
+	switch v := values[key].(type) {
+	case float64:
+		return v
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	}
+	return 0
+}
+
+// ValueBool returns the bool value of key in a Terraform values map or false when it is missing or unknown.
+func ValueBool(values map[string]any, key string) bool {
+	v, _ := values[key].(bool)
+	return v
+}
+
+// ValueBlock returns the first element of the nested block key in a Terraform values map or nil when the block is
+// not set.
+func ValueBlock(values map[string]any, key string) map[string]any {
+	switch v := values[key].(type) {
+	case []any:
+		if len(v) > 0 {
+			block, _ := v[0].(map[string]any)
+			return block
+		}
+	case map[string]any:
+		return v
+	}
+	return nil
+}
+
+// ValueStrings returns the string elements of the list key in a Terraform values map, the elements that are not
+// known are left out.
+func ValueStrings(values map[string]any, key string) []string {
+	list, _ := values[key].([]any)
+	var strs []string
+	for _, v := range list {
+		if s, ok := v.(string); ok {
+			strs = append(strs, s)
+		}
+	}
+	return strs
+}
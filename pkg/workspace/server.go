@@ -51,6 +51,7 @@ import (
 
 	"github.com/labstack/echo/v4"
 	authapi "github.com/opengovern/opengovernance/pkg/auth/api"
+	"github.com/opengovern/opengovernance/pkg/auth/audit"
 	authclient "github.com/opengovern/opengovernance/pkg/auth/client"
 	"github.com/opengovern/opengovernance/pkg/workspace/api"
 	"go.uber.org/zap"
@@ -73,11 +74,13 @@ type Server struct {
 	StateManager       *statemanager.Service
 	vault              vault.VaultSourceConfig
 	vaultSecretHandler vault.VaultSecretHandler
+	auditRecorder      *audit.Recorder
 }
 
 func NewServer(ctx context.Context, logger *zap.Logger, cfg config.Config) (*Server, error) {
 	s := &Server{
-		cfg: cfg,
+		cfg:           cfg,
+		auditRecorder: audit.NewServiceRecorder(ctx, logger, "workspace", cfg.Auth.BaseURL),
 	}
 
 	s.e, _ = httpserver2.Register(logger, s)
@@ -182,6 +185,8 @@ func NewServer(ctx context.Context, logger *zap.Logger, cfg config.Config) (*Ser
 }
 
 func (s *Server) Register(e *echo.Echo) {
+	e.Use(audit.Middleware(s.auditRecorder))
+
 	v1Group := e.Group("/api/v1")
 
 	workspaceGroup := v1Group.Group("/workspace")
@@ -207,6 +212,7 @@ func (s *Server) Register(e *echo.Echo) {
 	costEstimatorGroup := v1Group.Group("/costestimator")
 	costEstimatorGroup.GET("/aws", httpserver2.AuthorizeHandler(s.GetAwsCost, api2.ViewerRole))
 	costEstimatorGroup.GET("/azure", httpserver2.AuthorizeHandler(s.GetAzureCost, api2.ViewerRole))
+	costEstimatorGroup.POST("/terraform-plan", httpserver2.AuthorizeHandler(s.GetTerraformPlanCost, api2.ViewerRole))
 
 	v3 := e.Group("/api/v3")
 	v3.PUT("/sample/purge", httpserver2.AuthorizeHandler(s.PurgeSampleData, api2.ViewerRole))
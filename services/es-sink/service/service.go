@@ -9,6 +9,7 @@ import (
 	"github.com/opengovern/og-util/pkg/es"
 	"github.com/opengovern/og-util/pkg/jq"
 	essdk "github.com/opengovern/og-util/pkg/opengovernance-es-sdk"
+	"github.com/opengovern/opengovernance/pkg/inventory/graph"
 	"github.com/opengovern/opengovernance/pkg/utils"
 	"go.uber.org/zap"
 	"time"
@@ -88,6 +89,7 @@ func (s *EsSinkService) ConsumeCycle(ctx context.Context) {
 		}
 
 		s.esSinkModule.QueueDoc(doc)
+		s.queueResourceEdges(doc)
 
 		err = msg.Ack()
 		if err != nil {
@@ -105,6 +107,29 @@ func (s *EsSinkService) ConsumeCycle(ctx context.Context) {
 	consumeCtx.Stop()
 }
 
+// queueResourceEdges indexes the relationships of described resources next to the resource itself,
+// so the edges of a resource are always as fresh as its last description.
+func (s *EsSinkService) queueResourceEdges(doc es.DocBase) {
+	for _, edge := range graph.ExtractEdges(doc) {
+		keys, idx := edge.KeysAndIndex()
+		edge.EsID = es.HashOf(keys...)
+		edge.EsIndex = idx
+
+		edgeJson, err := json.Marshal(edge)
+		if err != nil {
+			s.logger.Error("failed to marshal resource edge", zap.Error(err))
+			continue
+		}
+		var edgeDoc es.DocBase
+		err = json.Unmarshal(edgeJson, &edgeDoc)
+		if err != nil {
+			s.logger.Error("failed to unmarshal resource edge", zap.Error(err))
+			continue
+		}
+		s.esSinkModule.QueueDoc(edgeDoc)
+	}
+}
+
 type FailedDoc struct {
 	Doc es.DocBase `json:"doc"`
 	Err string     `json:"err"`
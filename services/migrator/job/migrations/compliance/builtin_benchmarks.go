@@ -0,0 +1,74 @@
+package compliance
+
+import (
+	"github.com/jackc/pgtype"
+	"github.com/opengovern/opengovernance/pkg/compliance/api"
+	"github.com/opengovern/opengovernance/pkg/compliance/db"
+	"github.com/opengovern/opengovernance/pkg/types"
+)
+
+const (
+	TagGovernanceBenchmarkID = "tag_governance"
+	TagGovernanceControlID   = "tag_governance_policy_compliance"
+)
+
+// tagGovernanceQuery turns the tag policy evaluations of the analytics worker into findings,
+// one per resource across all the policies it is in scope of.
+const tagGovernanceQuery = `SELECT
+  resource_id AS resource,
+  resource_id AS kaytu_resource_id,
+  connection_id AS kaytu_account_id,
+  max(resource_name) AS name,
+  max(location) AS location,
+  CASE WHEN bool_and(coalesce(compliant, false)) THEN 'ok' ELSE 'alarm' END AS status,
+  CASE WHEN bool_and(coalesce(compliant, false)) THEN 'tags follow all tag policies'
+    ELSE string_agg(reason, '; ') FILTER (WHERE NOT coalesce(compliant, false)) END AS reason
+FROM
+  kaytu_tag_policy_results
+GROUP BY
+  resource_id, connection_id`
+
+// ExtractBuiltinBenchmarks adds the benchmarks that are not defined in the compliance git repository.
+func (g *GitParser) ExtractBuiltinBenchmarks() error {
+	connectors := []string{"AWS", "Azure"}
+
+	q := db.Query{
+		ID:             TagGovernanceControlID,
+		QueryToExecute: tagGovernanceQuery,
+		Connector:      connectors,
+		ListOfTables:   []string{"kaytu_tag_policy_results"},
+		Engine:         api.QueryEngine_OdysseusSQL,
+		Global:         true,
+	}
+	g.controlsQueries[q.ID] = q
+	g.queries = append(g.queries, q)
+
+	control := db.Control{
+		ID:          TagGovernanceControlID,
+		Title:       "Resources should follow the tag policies",
+		Description: "Checks the tags of each resource against the required keys, allowed values and case rules of the tag policies it is in scope of.",
+		Connector:   connectors,
+		Enabled:     true,
+		QueryID:     &q.ID,
+		Severity:    types.FindingSeverityMedium,
+		Managed:     true,
+	}
+	g.controls = append(g.controls, control)
+
+	metadata := pgtype.JSONB{}
+	if err := metadata.Set([]byte("")); err != nil {
+		return err
+	}
+	g.benchmarks = append(g.benchmarks, db.Benchmark{
+		ID:          TagGovernanceBenchmarkID,
+		Title:       "Tag Governance",
+		DisplayCode: "Tag Governance",
+		Connector:   connectors,
+		Description: "Built-in benchmark reporting the resources that violate the tag policies.",
+		Enabled:     true,
+		AutoAssign:  true,
+		Metadata:    metadata,
+		Controls:    []db.Control{control},
+	})
+	return nil
+}
@@ -68,8 +68,10 @@ func (m Migration) Run(ctx context.Context, conf config.MigratorConfig, logger *
 			currentRcMap[rc.ID] = rc
 		}
 
-		tx.Model(&inventory.ResourceCollection{}).Where("1=1").Unscoped().Delete(&inventory.ResourceCollection{})
-		tx.Model(&inventory.ResourceCollectionTag{}).Where("1=1").Unscoped().Delete(&inventory.ResourceCollectionTag{})
+		// collections defined through the inventory API are not part of the configuration
+		tx.Model(&inventory.ResourceCollectionTag{}).Where("resource_collection_id IN (?)",
+			tx.Model(&inventory.ResourceCollection{}).Select("id").Where("definition_json IS NULL")).Unscoped().Delete(&inventory.ResourceCollectionTag{})
+		tx.Model(&inventory.ResourceCollection{}).Where("definition_json IS NULL").Unscoped().Delete(&inventory.ResourceCollection{})
 		for _, resourceCollection := range resourceCollections {
 			filtersJson, err := json.Marshal(resourceCollection.Filters)
 			if err != nil {
@@ -85,7 +87,8 @@ func (m Migration) Run(ctx context.Context, conf config.MigratorConfig, logger *
 			}
 
 			createdAt := time.Now()
-			if currentRc, ok := currentRcMap[resourceCollection.ID]; ok {
+			currentRc, exists := currentRcMap[resourceCollection.ID]
+			if exists {
 				createdAt = currentRc.Created
 				if createdAt.IsZero() || createdAt.Year() == 1 {
 					createdAt = time.Now()
@@ -96,12 +99,20 @@ func (m Migration) Run(ctx context.Context, conf config.MigratorConfig, logger *
 			}
 
 			dbResourceCollection := inventory.ResourceCollection{
-				ID:          resourceCollection.ID,
-				Name:        resourceCollection.Name,
-				FiltersJson: jsonb,
-				Description: resourceCollection.Description,
-				Status:      resourceCollection.Status,
-				Created:     createdAt,
+				ID:             resourceCollection.ID,
+				Name:           resourceCollection.Name,
+				FiltersJson:    jsonb,
+				DefinitionJson: pgtype.JSONB{Status: pgtype.Null},
+				Description:    resourceCollection.Description,
+				Status:         resourceCollection.Status,
+				Created:        createdAt,
+			}
+			if exists {
+				dbResourceCollection.MembershipJobID = currentRc.MembershipJobID
+				dbResourceCollection.MaterializedAt = currentRc.MaterializedAt
+				dbResourceCollection.MemberCount = currentRc.MemberCount
+				dbResourceCollection.AddedCount = currentRc.AddedCount
+				dbResourceCollection.RemovedCount = currentRc.RemovedCount
 			}
 			err = tx.Clauses(clause.OnConflict{
 				DoNothing: true,
@@ -5,6 +5,7 @@ import (
 	"github.com/alitto/pond"
 	"github.com/labstack/echo/v4"
 	"github.com/opengovern/opengovernance/services/wastage/api/wastage"
+	"github.com/opengovern/opengovernance/services/wastage/api/wastage/lifecycle"
 	"github.com/opengovern/opengovernance/services/wastage/api/wastage/limit"
 	"github.com/opengovern/opengovernance/services/wastage/config"
 	"github.com/opengovern/opengovernance/services/wastage/cost"
@@ -22,6 +23,7 @@ type API struct {
 	recomSvc       *recommendation.Service
 	ingestionSvc   *ingestion.Service
 	limitsSvc      *limit.Service
+	lifecycleSvc   *lifecycle.Service
 	usageRepo      repo.UsageV2Repo
 	usageV1Repo    repo.UsageRepo
 	userRepo       repo.UserRepo
@@ -29,7 +31,7 @@ type API struct {
 	logger         *zap.Logger
 }
 
-func New(cfg config.WastageConfig, logger *zap.Logger, blobClient *azblob.Client, blobWorkerPool *pond.WorkerPool, costSvc *cost.Service, recomSvc *recommendation.Service, ingestionSvc *ingestion.Service, limitsSvc *limit.Service, usageV1Repo repo.UsageRepo, usageRepo repo.UsageV2Repo, userRepo repo.UserRepo, orgRepo repo.OrganizationRepo) *API {
+func New(cfg config.WastageConfig, logger *zap.Logger, blobClient *azblob.Client, blobWorkerPool *pond.WorkerPool, costSvc *cost.Service, recomSvc *recommendation.Service, ingestionSvc *ingestion.Service, limitsSvc *limit.Service, lifecycleSvc *lifecycle.Service, usageV1Repo repo.UsageRepo, usageRepo repo.UsageV2Repo, userRepo repo.UserRepo, orgRepo repo.OrganizationRepo) *API {
 	return &API{
 		cfg:            cfg,
 		blobClient:     blobClient,
@@ -37,6 +39,7 @@ func New(cfg config.WastageConfig, logger *zap.Logger, blobClient *azblob.Client
 		costSvc:        costSvc,
 		recomSvc:       recomSvc,
 		limitsSvc:      limitsSvc,
+		lifecycleSvc:   lifecycleSvc,
 		ingestionSvc:   ingestionSvc,
 		usageV1Repo:    usageV1Repo,
 		usageRepo:      usageRepo,
@@ -47,6 +50,6 @@ func New(cfg config.WastageConfig, logger *zap.Logger, blobClient *azblob.Client
 }
 
 func (api *API) Register(e *echo.Echo) {
-	qThr := wastage.New(api.cfg, api.blobClient, api.blobWorkerPool, api.costSvc, api.recomSvc, api.ingestionSvc, api.limitsSvc, api.usageV1Repo, api.usageRepo, api.userRepo, api.orgRepo, api.logger)
+	qThr := wastage.New(api.cfg, api.blobClient, api.blobWorkerPool, api.costSvc, api.recomSvc, api.ingestionSvc, api.limitsSvc, api.lifecycleSvc, api.usageV1Repo, api.usageRepo, api.userRepo, api.orgRepo, api.logger)
 	qThr.Register(e)
 }
@@ -0,0 +1,114 @@
+package entity
+
+import (
+	types2 "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"time"
+)
+
+type AwsCommitmentType string
+
+const (
+	AwsCommitmentTypeReservedInstance        AwsCommitmentType = "ReservedInstance"
+	AwsCommitmentTypeComputeSavingsPlans     AwsCommitmentType = "ComputeSavingsPlans"
+	AwsCommitmentTypeEC2InstanceSavingsPlans AwsCommitmentType = "EC2InstanceSavingsPlans"
+)
+
+type AwsCommitmentService string
+
+const (
+	AwsCommitmentServiceEC2 AwsCommitmentService = "AmazonEC2"
+	AwsCommitmentServiceRDS AwsCommitmentService = "AmazonRDS"
+)
+
+// AwsCommitmentUsageKey identifies the usage a commitment can apply to, Operation is the usage operation of ec2
+// instances and the database fields are used by rds instances.
+type AwsCommitmentUsageKey struct {
+	Service          AwsCommitmentService `json:"service"`
+	Region           string               `json:"region"`
+	InstanceType     string               `json:"instanceType"`
+	Operation        string               `json:"operation"`
+	DatabaseEngine   string               `json:"databaseEngine"`
+	DatabaseEdition  string               `json:"databaseEdition"`
+	DeploymentOption string               `json:"deploymentOption"`
+}
+
+type AwsCommitmentHourlyUsage struct {
+	Timestamp time.Time `json:"timestamp"`
+	// Instances is the number of instances running in the hour, partial hours are fractions
+	Instances float64 `json:"instances"`
+}
+
+// AwsCommitmentUsage is the hourly usage of an instance type, e.g. built from spend metrics.
+type AwsCommitmentUsage struct {
+	AwsCommitmentUsageKey
+	Hourly []AwsCommitmentHourlyUsage `json:"hourly"`
+}
+
+// AwsCommitmentInstance is an instance with the datapoints sent for its rightsizing, every datapoint counts as the
+// instance running for the period of the datapoint.
+type AwsCommitmentInstance struct {
+	AwsCommitmentUsageKey
+	HashedInstanceId string                        `json:"hashedInstanceId"`
+	Metrics          map[string][]types2.Datapoint `json:"metrics"`
+}
+
+type AwsExistingCommitment struct {
+	Type AwsCommitmentType `json:"type"`
+	AwsCommitmentUsageKey
+	// InstanceFamily of ec2 instance savings plans
+	InstanceFamily string `json:"instanceFamily"`
+	// Count of reserved instances
+	Count int64 `json:"count"`
+	// HourlyCommitment of savings plans in dollars
+	HourlyCommitment float64    `json:"hourlyCommitment"`
+	End              *time.Time `json:"end"`
+}
+
+type AwsCommitmentsRequest struct {
+	RequestId      *string                 `json:"requestId"`
+	CliVersion     *string                 `json:"cliVersion"`
+	Identification map[string]string       `json:"identification"`
+	Usage          []AwsCommitmentUsage    `json:"usage"`
+	Instances      []AwsCommitmentInstance `json:"instances"`
+	Commitments    []AwsExistingCommitment `json:"commitments"`
+	Preferences    map[string]*string      `json:"preferences"`
+	Loading        bool                    `json:"loading"`
+}
+
+type AwsCommitmentRecommendation struct {
+	Type AwsCommitmentType `json:"type"`
+	AwsCommitmentUsageKey
+	InstanceFamily string `json:"instanceFamily"`
+	Term           string `json:"term"`
+	PurchaseOption string `json:"purchaseOption"`
+	OfferingClass  string `json:"offeringClass"`
+
+	// Quantity of reserved instances to buy
+	Quantity int64 `json:"quantity"`
+	// HourlyCommitment of the savings plans to buy, in dollars
+	HourlyCommitment float64 `json:"hourlyCommitment"`
+
+	UpfrontCost          float64 `json:"upfrontCost"`
+	RecurringMonthlyCost float64 `json:"recurringMonthlyCost"`
+	// EffectiveMonthlyCost is the recurring cost with the upfront cost spread over the term
+	EffectiveMonthlyCost float64 `json:"effectiveMonthlyCost"`
+	// OnDemandMonthlyCost of the usage the commitment covers
+	OnDemandMonthlyCost     float64 `json:"onDemandMonthlyCost"`
+	EstimatedMonthlySavings float64 `json:"estimatedMonthlySavings"`
+	SavingsPercent          float64 `json:"savingsPercent"`
+	// BreakEvenMonths is when the savings pay back the upfront cost
+	BreakEvenMonths float64 `json:"breakEvenMonths"`
+	// CoveragePercent of the on demand equivalent cost covered by the existing commitments and this one
+	CoveragePercent    float64 `json:"coveragePercent"`
+	UtilizationPercent float64 `json:"utilizationPercent"`
+}
+
+type AwsCommitmentsResponse struct {
+	LookbackHours int `json:"lookbackHours"`
+	// OnDemandMonthlyCost of the usage that is not covered by the existing commitments
+	OnDemandMonthlyCost     float64                       `json:"onDemandMonthlyCost"`
+	ExistingCoveragePercent float64                       `json:"existingCoveragePercent"`
+	Recommendations         []AwsCommitmentRecommendation `json:"recommendations"`
+	// Unpriced usages have no on demand price in the catalog and are left out
+	Unpriced []AwsCommitmentUsageKey `json:"unpriced"`
+}
@@ -0,0 +1,52 @@
+package entity
+
+import types2 "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+
+type DynamoDBBillingMode string
+
+const (
+	DynamoDBBillingModeProvisioned   DynamoDBBillingMode = "PROVISIONED"
+	DynamoDBBillingModePayPerRequest DynamoDBBillingMode = "PAY_PER_REQUEST"
+)
+
+type DynamoDBTable struct {
+	HashedTableName               string              `json:"hashedTableName"`
+	BillingMode                   DynamoDBBillingMode `json:"billingMode"`
+	ProvisionedReadCapacityUnits  *int64              `json:"provisionedReadCapacityUnits"`
+	ProvisionedWriteCapacityUnits *int64              `json:"provisionedWriteCapacityUnits"`
+}
+
+type DynamoDBTableWastageRequest struct {
+	RequestId      *string                       `json:"requestId"`
+	CliVersion     *string                       `json:"cliVersion"`
+	Identification map[string]string             `json:"identification"`
+	Table          DynamoDBTable                 `json:"table"`
+	Metrics        map[string][]types2.Datapoint `json:"metrics"`
+	Region         string                        `json:"region"`
+	Preferences    map[string]*string            `json:"preferences"`
+	Loading        bool                          `json:"loading"`
+}
+
+type RightsizingDynamoDBTable struct {
+	Region             string              `json:"region"`
+	BillingMode        DynamoDBBillingMode `json:"billingMode"`
+	ReadCapacityUnits  *int64              `json:"readCapacityUnits"`
+	WriteCapacityUnits *int64              `json:"writeCapacityUnits"`
+	Cost               float64             `json:"cost"`
+	CostComponents     map[string]float64  `json:"costComponents"`
+}
+
+type DynamoDBTableRightsizingRecommendation struct {
+	Current     RightsizingDynamoDBTable  `json:"current"`
+	Recommended *RightsizingDynamoDBTable `json:"recommended"`
+
+	// Consumed capacity units per second
+	ConsumedReadCapacity  Usage `json:"consumedReadCapacity"`
+	ConsumedWriteCapacity Usage `json:"consumedWriteCapacity"`
+
+	Description string `json:"description"`
+}
+
+type DynamoDBTableWastageResponse struct {
+	RightSizing DynamoDBTableRightsizingRecommendation `json:"rightSizing"`
+}
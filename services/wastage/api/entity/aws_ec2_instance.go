@@ -3,6 +3,7 @@ package entity
 import (
 	types2 "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"time"
 )
 
 type EC2Placement struct {
@@ -47,6 +48,52 @@ type EC2InstanceWastageRequest struct {
 	Region         string                                   `json:"region"`
 	Preferences    map[string]*string                       `json:"preferences"`
 	Loading        bool                                     `json:"loading"`
+
+	// Modes are the alternative recommendations to make besides the rightsizing
+	Modes    []EC2RecommendationMode `json:"modes"`
+	Workload *EC2Workload            `json:"workload"`
+}
+
+type EC2RecommendationMode string
+
+const (
+	EC2RecommendationModeArchitecture EC2RecommendationMode = "ArchitectureMigration"
+	EC2RecommendationModeSpot         EC2RecommendationMode = "Spot"
+)
+
+type EC2RecommendationRisk string
+
+const (
+	EC2RecommendationRiskLow    EC2RecommendationRisk = "Low"
+	EC2RecommendationRiskMedium EC2RecommendationRisk = "Medium"
+	EC2RecommendationRiskHigh   EC2RecommendationRisk = "High"
+)
+
+// EC2Workload describes the instance beyond its type, the architecture migration and spot modes use it to tell
+// how risky moving the workload is.
+type EC2Workload struct {
+	// ImageArchitecture and ImagePlatformDetails of the AMI the instance is launched from, e.g. x86_64 and Linux/UNIX
+	ImageArchitecture    string `json:"imageArchitecture"`
+	ImagePlatformDetails string `json:"imagePlatformDetails"`
+
+	AutoScalingGroup bool              `json:"autoScalingGroup"`
+	Tags             map[string]string `json:"tags"`
+	LaunchTime       *time.Time        `json:"launchTime"`
+
+	// SpotPrices is the current hourly spot price by instance type in the availability zone of the instance,
+	// an estimated discount is used for the types without a price
+	SpotPrices map[string]float64 `json:"spotPrices"`
+}
+
+type EC2AlternativeRecommendation struct {
+	Mode     EC2RecommendationMode  `json:"mode"`
+	Instance RightsizingEC2Instance `json:"instance"`
+	// MonthlySavings compared to the current instance
+	MonthlySavings float64               `json:"monthlySavings"`
+	Risk           EC2RecommendationRisk `json:"risk"`
+	// SpotScore is the suitability of the workload for spot from 0 to 100
+	SpotScore int      `json:"spotScore,omitempty"`
+	Caveats   []string `json:"caveats"`
 }
 
 type RightsizingEC2Instance struct {
@@ -83,6 +130,9 @@ type RightSizingRecommendation struct {
 	EBSIops           Usage `json:"ebsIops"`
 	NetworkThroughput Usage `json:"networkThroughput"`
 
+	// Alternatives are ranked by their savings
+	Alternatives []EC2AlternativeRecommendation `json:"alternatives,omitempty"`
+
 	Description string `json:"description"`
 }
 
@@ -0,0 +1,48 @@
+package entity
+
+import types2 "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+
+type ElastiCacheCluster struct {
+	HashedClusterId string `json:"hashedClusterId"`
+	Engine          string `json:"engine"`
+	EngineVersion   string `json:"engineVersion"`
+	NodeType        string `json:"nodeType"`
+	NumNodes        int32  `json:"numNodes"`
+}
+
+type ElastiCacheClusterWastageRequest struct {
+	RequestId      *string                       `json:"requestId"`
+	CliVersion     *string                       `json:"cliVersion"`
+	Identification map[string]string             `json:"identification"`
+	Cluster        ElastiCacheCluster            `json:"cluster"`
+	Metrics        map[string][]types2.Datapoint `json:"metrics"`
+	Region         string                        `json:"region"`
+	Preferences    map[string]*string            `json:"preferences"`
+	Loading        bool                          `json:"loading"`
+}
+
+type RightsizingElastiCacheCluster struct {
+	Region             string  `json:"region"`
+	Engine             string  `json:"engine"`
+	NodeType           string  `json:"nodeType"`
+	NumNodes           int32   `json:"numNodes"`
+	VCPU               float64 `json:"vCPU"`
+	MemoryGb           float64 `json:"memoryGb"`
+	NetworkPerformance string  `json:"networkPerformance"`
+
+	Cost float64 `json:"cost"`
+}
+
+type ElastiCacheClusterRightsizingRecommendation struct {
+	Current     RightsizingElastiCacheCluster  `json:"current"`
+	Recommended *RightsizingElastiCacheCluster `json:"recommended"`
+
+	CPU             Usage `json:"cpu"`
+	MemoryUsedBytes Usage `json:"memoryUsedBytes"`
+
+	Description string `json:"description"`
+}
+
+type ElastiCacheClusterWastageResponse struct {
+	RightSizing ElastiCacheClusterRightsizingRecommendation `json:"rightSizing"`
+}
@@ -0,0 +1,55 @@
+package entity
+
+import (
+	types2 "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"time"
+)
+
+type AwsIdleResourceType string
+
+const (
+	AwsIdleResourceTypeElasticIP    AwsIdleResourceType = "ElasticIP"
+	AwsIdleResourceTypeNATGateway   AwsIdleResourceType = "NATGateway"
+	AwsIdleResourceTypeLoadBalancer AwsIdleResourceType = "LoadBalancer"
+	AwsIdleResourceTypeEBSSnapshot  AwsIdleResourceType = "EBSSnapshot"
+)
+
+type AwsIdleResource struct {
+	HashedResourceId string              `json:"hashedResourceId"`
+	Type             AwsIdleResourceType `json:"type"`
+	CreatedAt        *time.Time          `json:"createdAt"`
+
+	// ElasticIP
+	Associated bool `json:"associated"`
+	// LoadBalancer, one of application, network, gateway or classic
+	LoadBalancerType string `json:"loadBalancerType"`
+	// EBSSnapshot
+	SnapshotSizeGb     *float64 `json:"snapshotSizeGb"`
+	SourceVolumeExists bool     `json:"sourceVolumeExists"`
+	UsedByImage        bool     `json:"usedByImage"`
+}
+
+type AwsIdleResourcesWastageRequest struct {
+	RequestId      *string                                  `json:"requestId"`
+	CliVersion     *string                                  `json:"cliVersion"`
+	Identification map[string]string                        `json:"identification"`
+	Resources      []AwsIdleResource                        `json:"resources"`
+	Metrics        map[string]map[string][]types2.Datapoint `json:"metrics"`
+	Region         string                                   `json:"region"`
+	Preferences    map[string]*string                       `json:"preferences"`
+	Loading        bool                                     `json:"loading"`
+}
+
+type AwsIdleResourceRecommendation struct {
+	HashedResourceId string              `json:"hashedResourceId"`
+	Type             AwsIdleResourceType `json:"type"`
+	Idle             bool                `json:"idle"`
+	Reason           string              `json:"reason"`
+	// MonthlyCost of keeping the resource, the whole of it is saved by deleting an idle resource
+	MonthlyCost    float64            `json:"monthlyCost"`
+	CostComponents map[string]float64 `json:"costComponents"`
+}
+
+type AwsIdleResourcesWastageResponse struct {
+	Resources map[string]AwsIdleResourceRecommendation `json:"resources"`
+}
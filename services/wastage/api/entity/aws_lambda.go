@@ -0,0 +1,47 @@
+package entity
+
+import types2 "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+
+type LambdaFunction struct {
+	HashedFunctionName string `json:"hashedFunctionName"`
+	Runtime            string `json:"runtime"`
+	Architecture       string `json:"architecture"`
+	MemorySizeMb       int32  `json:"memorySizeMb"`
+	TimeoutSeconds     int32  `json:"timeoutSeconds"`
+}
+
+type LambdaFunctionWastageRequest struct {
+	RequestId      *string                       `json:"requestId"`
+	CliVersion     *string                       `json:"cliVersion"`
+	Identification map[string]string             `json:"identification"`
+	Function       LambdaFunction                `json:"function"`
+	Metrics        map[string][]types2.Datapoint `json:"metrics"`
+	Region         string                        `json:"region"`
+	Preferences    map[string]*string            `json:"preferences"`
+	Loading        bool                          `json:"loading"`
+}
+
+type RightsizingLambdaFunction struct {
+	Region       string `json:"region"`
+	Architecture string `json:"architecture"`
+	MemorySizeMb int32  `json:"memorySizeMb"`
+
+	// Monthly cost of the observed invocations with the given memory size and architecture
+	Cost           float64            `json:"cost"`
+	CostComponents map[string]float64 `json:"costComponents"`
+}
+
+type LambdaFunctionRightsizingRecommendation struct {
+	Current     RightsizingLambdaFunction  `json:"current"`
+	Recommended *RightsizingLambdaFunction `json:"recommended"`
+
+	MaxMemoryUsedMb    Usage   `json:"maxMemoryUsedMb"`
+	DurationMs         Usage   `json:"durationMs"`
+	MonthlyInvocations float64 `json:"monthlyInvocations"`
+
+	Description string `json:"description"`
+}
+
+type LambdaFunctionWastageResponse struct {
+	RightSizing LambdaFunctionRightsizingRecommendation `json:"rightSizing"`
+}
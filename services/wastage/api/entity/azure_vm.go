@@ -1,8 +1,91 @@
 package entity
 
+import "time"
+
 type AzureVM struct {
-	Id           string `json:"id"`
-	Zone         string `json:"zone"`
-	Region       string `json:"region"`
-	InstanceType string `json:"instance_type"`
+	Id              string `json:"id"`
+	Zone            string `json:"zone"`
+	Region          string `json:"region"`
+	InstanceType    string `json:"instance_type"`
+	OperatingSystem string `json:"operating_system"`
+	Spot            bool   `json:"spot"`
+}
+
+type AzureManagedDisk struct {
+	Id     string `json:"id"`
+	Zone   string `json:"zone"`
+	Region string `json:"region"`
+	// Tier is the storage account type of the disk, e.g. Premium_LRS, StandardSSD_LRS or Standard_LRS
+	Tier   string `json:"tier"`
+	SizeGb int64  `json:"size_gb"`
+}
+
+// AzureMonitorDatapoint mirrors a single metric value returned by the Azure Monitor metrics API.
+type AzureMonitorDatapoint struct {
+	TimeStamp time.Time `json:"timeStamp"`
+	Average   *float64  `json:"average"`
+	Minimum   *float64  `json:"minimum"`
+	Maximum   *float64  `json:"maximum"`
+	Total     *float64  `json:"total"`
+	Count     *float64  `json:"count"`
+}
+
+type AzureVMWastageRequest struct {
+	RequestId      *string                                       `json:"requestId"`
+	CliVersion     *string                                       `json:"cliVersion"`
+	Identification map[string]string                             `json:"identification"`
+	VM             AzureVM                                       `json:"vm"`
+	Disks          []AzureManagedDisk                            `json:"disks"`
+	Metrics        map[string][]AzureMonitorDatapoint            `json:"metrics"`
+	DiskMetrics    map[string]map[string][]AzureMonitorDatapoint `json:"diskMetrics"`
+	Preferences    map[string]*string                            `json:"preferences"`
+	Loading        bool                                          `json:"loading"`
+}
+
+type RightsizingAzureVM struct {
+	InstanceType    string  `json:"instanceType"`
+	Family          string  `json:"family"`
+	Region          string  `json:"region"`
+	OperatingSystem string  `json:"operatingSystem"`
+	Spot            bool    `json:"spot"`
+	VCPU            int64   `json:"vCPU"`
+	MemoryGB        float64 `json:"memoryGB"`
+	CpuArchitecture string  `json:"cpuArchitecture"`
+	PremiumIO       bool    `json:"premiumIO"`
+	Cost            float64 `json:"cost"`
+}
+
+type AzureVMRightSizingRecommendation struct {
+	Current     RightsizingAzureVM  `json:"current"`
+	Recommended *RightsizingAzureVM `json:"recommended"`
+
+	VCPU              Usage `json:"vCPU"`
+	Memory            Usage `json:"memory"`
+	NetworkThroughput Usage `json:"networkThroughput"`
+
+	Description string `json:"description"`
+}
+
+type RightsizingAzureManagedDisk struct {
+	Tier               string  `json:"tier"`
+	Name               string  `json:"name"`
+	SizeGb             int64   `json:"sizeGb"`
+	BaseIops           int64   `json:"baseIops"`
+	BaseThroughputMBps int64   `json:"baseThroughputMBps"`
+	Cost               float64 `json:"cost"`
+}
+
+type AzureManagedDiskRecommendation struct {
+	Current     RightsizingAzureManagedDisk  `json:"current"`
+	Recommended *RightsizingAzureManagedDisk `json:"recommended"`
+
+	IOPS       Usage `json:"iops"`
+	Throughput Usage `json:"throughput"`
+
+	Description string `json:"description"`
+}
+
+type AzureVMWastageResponse struct {
+	RightSizing     AzureVMRightSizingRecommendation          `json:"rightSizing"`
+	DiskRightSizing map[string]AzureManagedDiskRecommendation `json:"disks"`
 }
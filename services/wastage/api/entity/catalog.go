@@ -0,0 +1,21 @@
+package entity
+
+import "time"
+
+type CatalogVersion struct {
+	DataType  string            `json:"dataType"`
+	Version   string            `json:"version"`
+	Source    string            `json:"source"`
+	Tables    map[string]string `json:"tables"`
+	Current   bool              `json:"current"`
+	CreatedAt time.Time         `json:"createdAt"`
+}
+
+type CatalogVersionsResponse struct {
+	DataType       string           `json:"dataType"`
+	CurrentVersion string           `json:"currentVersion"`
+	CurrentSource  string           `json:"currentSource"`
+	Pinned         bool             `json:"pinned"`
+	UpdatedAt      *time.Time       `json:"updatedAt,omitempty"`
+	Versions       []CatalogVersion `json:"versions"`
+}
@@ -0,0 +1,83 @@
+package entity
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+// KubernetesClusterNode is a node of the cluster, cpu is in cores and memory is in bytes.
+type KubernetesClusterNode struct {
+	Id     string            `json:"id"`
+	Labels map[string]string `json:"labels"`
+	Taints []corev1.Taint    `json:"taints"`
+
+	CPUCapacity       float64 `json:"cpuCapacity"`
+	MemoryCapacity    float64 `json:"memoryCapacity"`
+	CPUAllocatable    float64 `json:"cpuAllocatable"`
+	MemoryAllocatable float64 `json:"memoryAllocatable"`
+
+	// MonthlyCost of the node, it is looked up by the node labels when not given
+	MonthlyCost *float64 `json:"monthlyCost"`
+}
+
+// KubernetesClusterWorkload is a workload with the recommended requests of one replica, cpu is in cores and memory
+// is in bytes. DaemonSets run a replica on every node they are allowed on.
+type KubernetesClusterWorkload struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Kind      string `json:"kind"`
+	Replicas  int32  `json:"replicas"`
+
+	CPURequest    float64 `json:"cpuRequest"`
+	MemoryRequest float64 `json:"memoryRequest"`
+
+	NodeSelector map[string]string   `json:"nodeSelector"`
+	Tolerations  []corev1.Toleration `json:"tolerations"`
+}
+
+type KubernetesClusterWastageRequest struct {
+	RequestId      *string                     `json:"requestId"`
+	CliVersion     *string                     `json:"cliVersion"`
+	Identification map[string]string           `json:"identification"`
+	Nodes          []KubernetesClusterNode     `json:"nodes"`
+	Workloads      []KubernetesClusterWorkload `json:"workloads"`
+	Preferences    map[string]*string          `json:"preferences"`
+	Loading        bool                        `json:"loading"`
+}
+
+type KubernetesNodePool struct {
+	InstanceType string `json:"instanceType"`
+	Nodes        int    `json:"nodes"`
+	// CPU and Memory are the capacity of one node
+	CPU         float64 `json:"cpu"`
+	Memory      float64 `json:"memory"`
+	MonthlyCost float64 `json:"monthlyCost"`
+
+	CPUHeadroomPercent    float64 `json:"cpuHeadroomPercent"`
+	MemoryHeadroomPercent float64 `json:"memoryHeadroomPercent"`
+}
+
+type KubernetesNodePoolRecommendation struct {
+	Name     string `json:"name"`
+	Provider string `json:"provider"`
+	Pods     int    `json:"pods"`
+
+	Current     KubernetesNodePool  `json:"current"`
+	Recommended *KubernetesNodePool `json:"recommended"`
+
+	Description string `json:"description"`
+}
+
+type KubernetesClusterWastageResponse struct {
+	Provider  string                             `json:"provider"`
+	NodePools []KubernetesNodePoolRecommendation `json:"nodePools"`
+	// Unschedulable are the workloads with replicas that do not fit on any node they are allowed on
+	Unschedulable []string `json:"unschedulable"`
+
+	CurrentMonthlyCost      float64 `json:"currentMonthlyCost"`
+	RecommendedMonthlyCost  float64 `json:"recommendedMonthlyCost"`
+	ProjectedMonthlySavings float64 `json:"projectedMonthlySavings"`
+
+	// CPUHeadroomPercent and MemoryHeadroomPercent are left in the cluster after the recommendations
+	CPUHeadroomPercent    float64 `json:"cpuHeadroomPercent"`
+	MemoryHeadroomPercent float64 `json:"memoryHeadroomPercent"`
+}
@@ -0,0 +1,79 @@
+package entity
+
+import (
+	"github.com/opengovern/opengovernance/services/wastage/db/model"
+	"time"
+)
+
+type Recommendation struct {
+	ID             uint                      `json:"id"`
+	ApiEndpoint    string                    `json:"apiEndpoint"`
+	ResourceID     string                    `json:"resourceId"`
+	AccountID      string                    `json:"accountId"`
+	State          model.RecommendationState `json:"state"`
+	StateChangedAt time.Time                 `json:"stateChangedAt"`
+	DismissReason  *string                   `json:"dismissReason"`
+
+	CurrentType      string  `json:"currentType"`
+	CurrentCost      float64 `json:"currentCost"`
+	RecommendedType  string  `json:"recommendedType"`
+	RecommendedCost  float64 `json:"recommendedCost"`
+	PotentialSavings float64 `json:"potentialSavings"`
+
+	AppliedType     *string    `json:"appliedType"`
+	AppliedAt       *time.Time `json:"appliedAt"`
+	CostAfter       *float64   `json:"costAfter"`
+	RealizedSavings *float64   `json:"realizedSavings"`
+	VerifiedAt      *time.Time `json:"verifiedAt"`
+
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// NewRecommendation converts model.Recommendation
+func NewRecommendation(m model.Recommendation) Recommendation {
+	return Recommendation{
+		ID:               m.ID,
+		ApiEndpoint:      m.ApiEndpoint,
+		ResourceID:       m.ResourceID,
+		AccountID:        m.AccountID,
+		State:            m.State,
+		StateChangedAt:   m.StateChangedAt,
+		DismissReason:    m.DismissReason,
+		CurrentType:      m.CurrentType,
+		CurrentCost:      m.CurrentCost,
+		RecommendedType:  m.RecommendedType,
+		RecommendedCost:  m.RecommendedCost,
+		PotentialSavings: m.PotentialSavings,
+		AppliedType:      m.AppliedType,
+		AppliedAt:        m.AppliedAt,
+		CostAfter:        m.CostAfter,
+		RealizedSavings:  m.RealizedSavings,
+		VerifiedAt:       m.VerifiedAt,
+		CreatedAt:        m.CreatedAt,
+	}
+}
+
+type RecommendationStateRequest struct {
+	State model.RecommendationState `json:"state"`
+	// Reason is required to dismiss a recommendation
+	Reason *string `json:"reason"`
+}
+
+type RecommendationSavingsGroup struct {
+	Group           string                              `json:"group"`
+	Recommendations int64                               `json:"recommendations"`
+	States          map[model.RecommendationState]int64 `json:"states"`
+	// PotentialSavings of the open and accepted recommendations
+	PotentialSavings float64 `json:"potentialSavings"`
+	// DismissedSavings is the potential savings of the dismissed recommendations
+	DismissedSavings float64 `json:"dismissedSavings"`
+	// PendingSavings are realized by the applied recommendations that are not verified yet
+	PendingSavings  float64 `json:"pendingSavings"`
+	RealizedSavings float64 `json:"realizedSavings"`
+}
+
+type RecommendationSavingsReport struct {
+	GroupBy string                       `json:"groupBy"`
+	Groups  []RecommendationSavingsGroup `json:"groups"`
+	Total   RecommendationSavingsGroup   `json:"total"`
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/opengovern/og-util/pkg/httpserver"
 	"github.com/opengovern/opengovernance/pkg/utils"
 	"github.com/opengovern/opengovernance/services/wastage/api/entity"
+	"github.com/opengovern/opengovernance/services/wastage/api/wastage/lifecycle"
 	"github.com/opengovern/opengovernance/services/wastage/api/wastage/limit"
 	"github.com/opengovern/opengovernance/services/wastage/config"
 	"github.com/opengovern/opengovernance/services/wastage/cost"
@@ -50,9 +51,10 @@ type API struct {
 	recomSvc       *recommendation.Service
 	ingestionSvc   *ingestion.Service
 	limitsSvc      *limit.Service
+	lifecycleSvc   *lifecycle.Service
 }
 
-func New(cfg config.WastageConfig, blobClient *azblob.Client, blobWorkerPool *pond.WorkerPool, costSvc *cost.Service, recomSvc *recommendation.Service, ingestionService *ingestion.Service, limitsSvc *limit.Service, usageV1Repo repo.UsageRepo, usageRepo repo.UsageV2Repo, userRepo repo.UserRepo, orgRepo repo.OrganizationRepo, logger *zap.Logger) API {
+func New(cfg config.WastageConfig, blobClient *azblob.Client, blobWorkerPool *pond.WorkerPool, costSvc *cost.Service, recomSvc *recommendation.Service, ingestionService *ingestion.Service, limitsSvc *limit.Service, lifecycleSvc *lifecycle.Service, usageV1Repo repo.UsageRepo, usageRepo repo.UsageV2Repo, userRepo repo.UserRepo, orgRepo repo.OrganizationRepo, logger *zap.Logger) API {
 	return API{
 		cfg:            cfg,
 		blobClient:     blobClient,
@@ -65,6 +67,7 @@ func New(cfg config.WastageConfig, blobClient *azblob.Client, blobWorkerPool *po
 		orgRepo:        orgRepo,
 		ingestionSvc:   ingestionService,
 		limitsSvc:      limitsSvc,
+		lifecycleSvc:   lifecycleSvc,
 		tracer:         otel.GetTracerProvider().Tracer("wastage.http.sources"),
 		logger:         logger.Named("wastage-api"),
 	}
@@ -76,6 +79,10 @@ func (s API) Register(e *echo.Echo) {
 	g.POST("/ec2-instance", httpserver.AuthorizeHandler(s.EC2Instance, api.ViewerRole))
 	g.POST("/aws-rds", httpserver.AuthorizeHandler(s.AwsRDS, api.ViewerRole))
 	g.POST("/aws-rds-cluster", httpserver.AuthorizeHandler(s.AwsRDSCluster, api.ViewerRole))
+	g.POST("/aws-commitments", httpserver.AuthorizeHandler(s.AwsCommitments, api.ViewerRole))
+	g.GET("/recommendations", httpserver.AuthorizeHandler(s.ListRecommendations, api.ViewerRole))
+	g.GET("/recommendations/report", httpserver.AuthorizeHandler(s.RecommendationsReport, api.ViewerRole))
+	g.PUT("/recommendations/:id/state", httpserver.AuthorizeHandler(s.UpdateRecommendationState, api.ViewerRole))
 	i := e.Group("/api/v1/wastage-ingestion")
 	i.PUT("/ingest/:service", httpserver.AuthorizeHandler(s.TriggerIngest, api.InternalRole))
 	i.GET("/usages/:id", httpserver.AuthorizeHandler(s.GetUsage, api.InternalRole))
@@ -88,6 +95,10 @@ func (s API) Register(e *echo.Echo) {
 	i.PUT("/user/:userId", httpserver.AuthorizeHandler(s.UpdateUser, api.InternalRole))
 	i.POST("/organization", httpserver.AuthorizeHandler(s.CreateOrganization, api.InternalRole))
 	i.PUT("/organization/:organizationId", httpserver.AuthorizeHandler(s.UpdateOrganization, api.InternalRole))
+	i.GET("/catalog/:dataType/versions", httpserver.AuthorizeHandler(s.ListCatalogVersions, api.InternalRole))
+	i.PUT("/catalog/:dataType/pin", httpserver.AuthorizeHandler(s.PinCatalog, api.InternalRole))
+	i.PUT("/catalog/:dataType/unpin", httpserver.AuthorizeHandler(s.UnpinCatalog, api.InternalRole))
+	i.PUT("/catalog/:dataType/rollback/:version", httpserver.AuthorizeHandler(s.RollbackCatalog, api.InternalRole))
 }
 
 func (s API) Configuration(c echo.Context) error {
@@ -209,6 +220,19 @@ func (s API) EC2Instance(echoCtx echo.Context) error {
 
 			statsOut, _ := json.Marshal(stats)
 			usage.Statistics = statsOut
+
+			s.observeRecommendation(ctx, lifecycle.Observation{
+				ApiEndpoint:     usage.ApiEndpoint,
+				ResourceID:      stats.ResourceID,
+				AccountID:       stats.AccountID,
+				OrgEmail:        stats.OrgEmail,
+				Auth0UserId:     stats.Auth0UserId,
+				UsageID:         usage.ID,
+				CurrentType:     resp.RightSizing.Current.InstanceType,
+				CurrentCost:     instanceCost,
+				RecommendedType: recom.InstanceType,
+				RecommendedCost: recomInstanceCost,
+			})
 		}
 		err = s.usageRepo.Update(usage.ID, usage)
 		if err != nil {
@@ -255,7 +279,7 @@ func (s API) EC2Instance(echoCtx echo.Context) error {
 		}
 	}
 
-	ec2RightSizingRecom, err := s.recomSvc.EC2InstanceRecommendation(ctx, req.Region, req.Instance, req.Volumes, req.Metrics, req.VolumeMetrics, req.Preferences, usageAverageType)
+	ec2RightSizingRecom, err := s.recomSvc.EC2InstanceRecommendation(ctx, req.Region, req.Instance, req.Volumes, req.Metrics, req.VolumeMetrics, req.Preferences, usageAverageType, req.Modes, req.Workload)
 	if err != nil {
 		err = fmt.Errorf("failed to get ec2 instance recommendation: %s", err.Error())
 		return err
@@ -389,6 +413,19 @@ func (s API) AwsRDS(echoCtx echo.Context) error {
 
 			statsOut, _ := json.Marshal(stats)
 			usage.Statistics = statsOut
+
+			s.observeRecommendation(ctx, lifecycle.Observation{
+				ApiEndpoint:     usage.ApiEndpoint,
+				ResourceID:      stats.ResourceID,
+				AccountID:       stats.AccountID,
+				OrgEmail:        stats.OrgEmail,
+				Auth0UserId:     stats.Auth0UserId,
+				UsageID:         usage.ID,
+				CurrentType:     resp.RightSizing.Current.InstanceType,
+				CurrentCost:     resp.RightSizing.Current.Cost,
+				RecommendedType: recom.InstanceType,
+				RecommendedCost: recom.Cost,
+			})
 		}
 		err = s.usageRepo.Update(usage.ID, usage)
 		if err != nil {
@@ -659,6 +696,224 @@ func (s API) AwsRDSCluster(echoCtx echo.Context) error {
 	return echoCtx.JSON(http.StatusOK, resp)
 }
 
+// AwsCommitments godoc
+//
+//	@Summary		List savings plans and reserved instances recommendations
+//	@Description	List savings plans and reserved instances recommendations for the hourly usage of EC2 and RDS instances
+//	@Security		BearerToken
+//	@Tags			wastage
+//	@Produce		json
+//	@Param			request	body		entity.AwsCommitmentsRequest	true	"Request"
+//	@Success		200		{object}	entity.AwsCommitmentsResponse
+//	@Router			/wastage/api/v1/wastage/aws-commitments [post]
+func (s API) AwsCommitments(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+	start := time.Now()
+	ctx = otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(echoCtx.Request().Header))
+	ctx, span := s.tracer.Start(ctx, "get")
+	defer span.End()
+
+	var req entity.AwsCommitmentsRequest
+	if err := echoCtx.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if err := echoCtx.Validate(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	var resp entity.AwsCommitmentsResponse
+	var err error
+
+	stats := model.Statistics{
+		AccountID:   req.Identification["account"],
+		OrgEmail:    req.Identification["org_m_email"],
+		Auth0UserId: httpserver.GetUserID(echoCtx),
+	}
+	statsOut, _ := json.Marshal(stats)
+
+	fullReqJson, _ := json.Marshal(req)
+	trimmed := req
+	trimmed.Usage = nil
+	trimmed.Instances = nil
+	trimmedReqJson, _ := json.Marshal(trimmed)
+
+	if req.RequestId == nil {
+		id := uuid.New().String()
+		req.RequestId = &id
+	}
+
+	s.blobWorkerPool.Submit(func() {
+		_, err := s.blobClient.UploadBuffer(context.Background(), s.cfg.AzBlob.Container, fmt.Sprintf("aws-commitments/%s.json", *req.RequestId), fullReqJson, &azblob.UploadBufferOptions{AccessTier: utils.GetPointer(blob.AccessTierCold)})
+		if err != nil {
+			s.logger.Error("failed to upload usage to blob storage", zap.Error(err))
+		}
+	})
+	usage := model.UsageV2{
+		ApiEndpoint:    "aws-commitments",
+		Request:        trimmedReqJson,
+		RequestId:      req.RequestId,
+		CliVersion:     req.CliVersion,
+		Response:       nil,
+		FailureMessage: nil,
+		Statistics:     statsOut,
+	}
+	err = s.usageRepo.Create(&usage)
+	if err != nil {
+		s.logger.Error("failed to create usage", zap.Error(err))
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			fmsg := err.Error()
+			usage.FailureMessage = &fmsg
+		} else {
+			usage.Response, _ = json.Marshal(resp)
+			id := uuid.New()
+			responseId := id.String()
+			usage.ResponseId = &responseId
+
+			// the recommendations are alternatives, only the best one is counted
+			savings := 0.0
+			if len(resp.Recommendations) > 0 {
+				savings = resp.Recommendations[0].EstimatedMonthlySavings
+			}
+			stats.CurrentCost = resp.OnDemandMonthlyCost
+			stats.RecommendedCost = resp.OnDemandMonthlyCost - savings
+			stats.Savings = savings
+
+			statsOut, _ := json.Marshal(stats)
+			usage.Statistics = statsOut
+		}
+		err = s.usageRepo.Update(usage.ID, usage)
+		if err != nil {
+			s.logger.Error("failed to update usage", zap.Error(err), zap.Any("usage", usage))
+		}
+	}()
+	if req.Loading {
+		return echoCtx.JSON(http.StatusOK, entity.AwsCommitmentsResponse{})
+	}
+
+	commitmentsRecom, err := s.recomSvc.AwsCommitmentsRecommendation(ctx, req)
+	if err != nil {
+		s.logger.Error("failed to get aws commitments recommendation", zap.Error(err))
+		return err
+	}
+
+	elapsed := time.Since(start).Seconds()
+	usage.Latency = &elapsed
+	err = s.usageRepo.Update(usage.ID, usage)
+	if err != nil {
+		s.logger.Error("failed to update usage", zap.Error(err), zap.Any("usage", usage))
+	}
+
+	// DO NOT change this, resp is used in updating usage
+	resp = *commitmentsRecom
+	// DO NOT change this, resp is used in updating usage
+	return echoCtx.JSON(http.StatusOK, resp)
+}
+
+// observeRecommendation tracks the recommendation of a response, the request does not fail when tracking fails.
+func (s API) observeRecommendation(ctx context.Context, o lifecycle.Observation) {
+	if err := s.lifecycleSvc.Observe(ctx, o); err != nil {
+		s.logger.Error("failed to observe recommendation", zap.String("endpoint", o.ApiEndpoint), zap.Error(err))
+	}
+}
+
+// ListRecommendations godoc
+//
+//	@Summary		List tracked recommendations
+//	@Description	List the recommendations of the user with their lifecycle state and realized savings
+//	@Security		BearerToken
+//	@Tags			wastage
+//	@Produce		json
+//	@Param			accountId	query		string	false	"Account ID"
+//	@Param			state		query		string	false	"State"	Enums(open, accepted, dismissed, applied, verified)
+//	@Success		200			{object}	[]entity.Recommendation
+//	@Router			/wastage/api/v1/wastage/recommendations [get]
+func (s API) ListRecommendations(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+
+	recs, err := s.lifecycleSvc.List(ctx, repo.RecommendationFilter{
+		Auth0UserId: httpserver.GetUserID(echoCtx),
+		AccountID:   echoCtx.QueryParam("accountId"),
+		State:       model.RecommendationState(echoCtx.QueryParam("state")),
+	})
+	if err != nil {
+		s.logger.Error("failed to list recommendations", zap.Error(err))
+		return err
+	}
+	return echoCtx.JSON(http.StatusOK, recs)
+}
+
+// RecommendationsReport godoc
+//
+//	@Summary		Report potential and realized savings
+//	@Description	Sum the potential and realized savings of the recommendations of the user per account or organization
+//	@Security		BearerToken
+//	@Tags			wastage
+//	@Produce		json
+//	@Param			groupBy	query		string	false	"Group by"	Enums(account, organization)
+//	@Success		200		{object}	entity.RecommendationSavingsReport
+//	@Router			/wastage/api/v1/wastage/recommendations/report [get]
+func (s API) RecommendationsReport(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+
+	groupBy := echoCtx.QueryParam("groupBy")
+	if groupBy == "" {
+		groupBy = repo.RecommendationGroupByAccount
+	}
+	if groupBy != repo.RecommendationGroupByAccount && groupBy != repo.RecommendationGroupByOrganization {
+		return echo.NewHTTPError(http.StatusBadRequest, "groupBy must be account or organization")
+	}
+
+	report, err := s.lifecycleSvc.Report(ctx, groupBy, repo.RecommendationFilter{
+		Auth0UserId: httpserver.GetUserID(echoCtx),
+	})
+	if err != nil {
+		s.logger.Error("failed to build recommendations report", zap.Error(err))
+		return err
+	}
+	return echoCtx.JSON(http.StatusOK, report)
+}
+
+// UpdateRecommendationState godoc
+//
+//	@Summary		Update recommendation state
+//	@Description	Accept, dismiss with a reason, reopen or mark a recommendation as applied
+//	@Security		BearerToken
+//	@Tags			wastage
+//	@Produce		json
+//	@Param			id		path		string								true	"Recommendation ID"
+//	@Param			request	body		entity.RecommendationStateRequest	true	"Request"
+//	@Success		200		{object}	entity.Recommendation
+//	@Router			/wastage/api/v1/wastage/recommendations/{id}/state [put]
+func (s API) UpdateRecommendationState(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+
+	id, err := strconv.ParseUint(echoCtx.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid recommendation id")
+	}
+	var req entity.RecommendationStateRequest
+	if err := echoCtx.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	rec, err := s.lifecycleSvc.Transition(ctx, httpserver.GetUserID(echoCtx), uint(id), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, lifecycle.ErrNotFound):
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		case errors.Is(err, lifecycle.ErrInvalidTransition), errors.Is(err, lifecycle.ErrReasonRequired):
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		s.logger.Error("failed to update recommendation state", zap.Uint64("id", id), zap.Error(err))
+		return err
+	}
+	return echoCtx.JSON(http.StatusOK, rec)
+}
+
 func (s API) TriggerIngest(echoCtx echo.Context) error {
 	ctx := echoCtx.Request().Context()
 	ctx = otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(echoCtx.Request().Header))
@@ -700,6 +955,86 @@ func (s API) TriggerIngest(echoCtx echo.Context) error {
 	return echoCtx.NoContent(http.StatusOK)
 }
 
+func (s API) ListCatalogVersions(echoCtx echo.Context) error {
+	dataType := echoCtx.Param("dataType")
+
+	current, err := s.ingestionSvc.Catalog.Current(dataType)
+	if err != nil {
+		s.logger.Error("failed to get current catalog version", zap.Error(err), zap.String("dataType", dataType))
+		return err
+	}
+	versions, err := s.ingestionSvc.Catalog.ListVersions(dataType)
+	if err != nil {
+		s.logger.Error("failed to list catalog versions", zap.Error(err), zap.String("dataType", dataType))
+		return err
+	}
+
+	resp := entity.CatalogVersionsResponse{
+		DataType: dataType,
+		Versions: make([]entity.CatalogVersion, 0, len(versions)),
+	}
+	if current != nil {
+		resp.CurrentVersion = current.Version
+		resp.CurrentSource = current.Source
+		resp.Pinned = current.Pinned
+		resp.UpdatedAt = &current.UpdatedAt
+	}
+	for _, v := range versions {
+		resp.Versions = append(resp.Versions, entity.CatalogVersion{
+			DataType:  v.DataType,
+			Version:   v.Version,
+			Source:    v.Source,
+			Tables:    v.Tables,
+			Current:   current != nil && current.Version == v.Version && current.Source == v.Source,
+			CreatedAt: v.CreatedAt,
+		})
+	}
+
+	return echoCtx.JSON(http.StatusOK, resp)
+}
+
+func (s API) PinCatalog(echoCtx echo.Context) error {
+	return s.setCatalogPinned(echoCtx, true)
+}
+
+func (s API) UnpinCatalog(echoCtx echo.Context) error {
+	return s.setCatalogPinned(echoCtx, false)
+}
+
+func (s API) setCatalogPinned(echoCtx echo.Context, pinned bool) error {
+	dataType := echoCtx.Param("dataType")
+
+	err := s.ingestionSvc.Catalog.SetPinned(dataType, pinned)
+	if err != nil {
+		if errors.Is(err, ingestion.ErrCatalogVersionNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "no catalog ingested for data type")
+		}
+		s.logger.Error("failed to pin catalog", zap.Error(err), zap.String("dataType", dataType), zap.Bool("pinned", pinned))
+		return err
+	}
+
+	return echoCtx.NoContent(http.StatusOK)
+}
+
+func (s API) RollbackCatalog(echoCtx echo.Context) error {
+	dataType := echoCtx.Param("dataType")
+	version := echoCtx.Param("version")
+
+	err := s.ingestionSvc.Catalog.Rollback(dataType, version)
+	if err != nil {
+		if errors.Is(err, ingestion.ErrCatalogVersionNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		}
+		if errors.Is(err, ingestion.ErrCatalogVersionNotRetained) {
+			return echo.NewHTTPError(http.StatusConflict, err.Error())
+		}
+		s.logger.Error("failed to rollback catalog", zap.Error(err), zap.String("dataType", dataType), zap.String("version", version))
+		return err
+	}
+
+	return echoCtx.NoContent(http.StatusOK)
+}
+
 func (s API) MigrateUsages(echoCtx echo.Context) error {
 	go func() {
 		ctx := context.Background()
@@ -13,6 +13,7 @@ import (
 	"github.com/opengovern/og-util/pkg/httpserver"
 	"github.com/opengovern/opengovernance/pkg/utils"
 	"github.com/opengovern/opengovernance/services/wastage/api/entity"
+	"github.com/opengovern/opengovernance/services/wastage/api/wastage/lifecycle"
 	"github.com/opengovern/opengovernance/services/wastage/api/wastage/limit"
 	"github.com/opengovern/opengovernance/services/wastage/config"
 	"github.com/opengovern/opengovernance/services/wastage/db/model"
@@ -45,10 +46,11 @@ type awsPluginServer struct {
 	recomSvc  *recommendation.Service
 
 	limitService *limit.Service
+	lifecycleSvc *lifecycle.Service
 }
 
 func newAwsPluginServer(logger *zap.Logger, cfg config.WastageConfig, blobClient *azblob.Client, blobWorkerPool *pond.WorkerPool,
-	usageRepo repo.UsageV2Repo, recomSvc *recommendation.Service, limitService *limit.Service) *awsPluginServer {
+	usageRepo repo.UsageV2Repo, recomSvc *recommendation.Service, limitService *limit.Service, lifecycleSvc *lifecycle.Service) *awsPluginServer {
 
 	return &awsPluginServer{
 		cfg:            cfg,
@@ -59,6 +61,7 @@ func newAwsPluginServer(logger *zap.Logger, cfg config.WastageConfig, blobClient
 		usageRepo:      usageRepo,
 		recomSvc:       recomSvc,
 		limitService:   limitService,
+		lifecycleSvc:   lifecycleSvc,
 	}
 }
 
@@ -174,6 +177,21 @@ func (s *awsPluginServer) EC2InstanceOptimization(ctx context.Context, req *aws.
 
 			statsOut, _ := json.Marshal(stats)
 			usage.Statistics = statsOut
+
+			if resp.RightSizing != nil && resp.RightSizing.Current != nil {
+				s.observeRecommendation(ctx, lifecycle.Observation{
+					ApiEndpoint:     usage.ApiEndpoint,
+					ResourceID:      stats.ResourceID,
+					AccountID:       stats.AccountID,
+					OrgEmail:        stats.OrgEmail,
+					Auth0UserId:     stats.Auth0UserId,
+					UsageID:         usage.ID,
+					CurrentType:     resp.RightSizing.Current.InstanceType,
+					CurrentCost:     instanceCost,
+					RecommendedType: recom.InstanceType,
+					RecommendedCost: recomInstanceCost,
+				})
+			}
 		}
 		err = s.usageRepo.Update(usage.ID, usage)
 		if err != nil {
@@ -343,6 +361,19 @@ func (s *awsPluginServer) RDSInstanceOptimization(ctx context.Context, req *aws.
 
 			statsOut, _ := json.Marshal(stats)
 			usage.Statistics = statsOut
+
+			s.observeRecommendation(ctx, lifecycle.Observation{
+				ApiEndpoint:     usage.ApiEndpoint,
+				ResourceID:      stats.ResourceID,
+				AccountID:       stats.AccountID,
+				OrgEmail:        stats.OrgEmail,
+				Auth0UserId:     stats.Auth0UserId,
+				UsageID:         usage.ID,
+				CurrentType:     resp.RightSizing.Current.InstanceType,
+				CurrentCost:     resp.RightSizing.Current.Cost,
+				RecommendedType: recom.InstanceType,
+				RecommendedCost: recom.Cost,
+			})
 		}
 		err = s.usageRepo.Update(usage.ID, usage)
 		if err != nil {
@@ -613,3 +644,10 @@ func (s *awsPluginServer) RDSClusterOptimization(ctx context.Context, req *aws.R
 
 	return &resp, nil
 }
+
+// observeRecommendation tracks the recommendation of a response, the request does not fail when tracking fails.
+func (s *awsPluginServer) observeRecommendation(ctx context.Context, o lifecycle.Observation) {
+	if err := s.lifecycleSvc.Observe(ctx, o); err != nil {
+		s.logger.Error("failed to observe recommendation", zap.String("endpoint", o.ApiEndpoint), zap.Error(err))
+	}
+}
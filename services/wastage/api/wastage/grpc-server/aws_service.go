@@ -0,0 +1,80 @@
+package grpc_server
+
+import (
+	"context"
+	"github.com/opengovern/opengovernance/services/wastage/api/entity"
+	"google.golang.org/grpc"
+)
+
+// The aws plugin proto only covers EC2 and RDS, the optimizations of the other aws services are described by hand
+// and exchange the entity types as JSON, the same way as the azure optimization service.
+const (
+	AwsServicesOptimizationServiceName = "aws.ServicesOptimization"
+
+	ElastiCacheClusterOptimizationFullMethodName = "/aws.ServicesOptimization/ElastiCacheClusterOptimization"
+	LambdaFunctionOptimizationFullMethodName     = "/aws.ServicesOptimization/LambdaFunctionOptimization"
+	DynamoDBTableOptimizationFullMethodName      = "/aws.ServicesOptimization/DynamoDBTableOptimization"
+	IdleResourcesOptimizationFullMethodName      = "/aws.ServicesOptimization/IdleResourcesOptimization"
+	CommitmentsOptimizationFullMethodName        = "/aws.ServicesOptimization/CommitmentsOptimization"
+)
+
+type AwsServicesOptimizationServer interface {
+	ElastiCacheClusterOptimization(context.Context, *entity.ElastiCacheClusterWastageRequest) (*entity.ElastiCacheClusterWastageResponse, error)
+	LambdaFunctionOptimization(context.Context, *entity.LambdaFunctionWastageRequest) (*entity.LambdaFunctionWastageResponse, error)
+	DynamoDBTableOptimization(context.Context, *entity.DynamoDBTableWastageRequest) (*entity.DynamoDBTableWastageResponse, error)
+	IdleResourcesOptimization(context.Context, *entity.AwsIdleResourcesWastageRequest) (*entity.AwsIdleResourcesWastageResponse, error)
+	CommitmentsOptimization(context.Context, *entity.AwsCommitmentsRequest) (*entity.AwsCommitmentsResponse, error)
+}
+
+func RegisterAwsServicesOptimizationServer(s grpc.ServiceRegistrar, srv AwsServicesOptimizationServer) {
+	s.RegisterService(&awsServicesOptimizationServiceDesc, srv)
+}
+
+// jsonUnaryHandler builds the grpc handler of a JSON method from the typed server method.
+func jsonUnaryHandler[Srv any, Req any, Resp any](fullMethod string, method func(Srv, context.Context, *Req) (*Resp, error)) func(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	return func(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+		in := new(Req)
+		if err := dec(in); err != nil {
+			return nil, err
+		}
+		if interceptor == nil {
+			return method(srv.(Srv), ctx, in)
+		}
+		info := &grpc.UnaryServerInfo{
+			Server:     srv,
+			FullMethod: fullMethod,
+		}
+		handler := func(ctx context.Context, req any) (any, error) {
+			return method(srv.(Srv), ctx, req.(*Req))
+		}
+		return interceptor(ctx, in, info, handler)
+	}
+}
+
+var awsServicesOptimizationServiceDesc = grpc.ServiceDesc{
+	ServiceName: AwsServicesOptimizationServiceName,
+	HandlerType: (*AwsServicesOptimizationServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ElastiCacheClusterOptimization",
+			Handler:    jsonUnaryHandler(ElastiCacheClusterOptimizationFullMethodName, AwsServicesOptimizationServer.ElastiCacheClusterOptimization),
+		},
+		{
+			MethodName: "LambdaFunctionOptimization",
+			Handler:    jsonUnaryHandler(LambdaFunctionOptimizationFullMethodName, AwsServicesOptimizationServer.LambdaFunctionOptimization),
+		},
+		{
+			MethodName: "DynamoDBTableOptimization",
+			Handler:    jsonUnaryHandler(DynamoDBTableOptimizationFullMethodName, AwsServicesOptimizationServer.DynamoDBTableOptimization),
+		},
+		{
+			MethodName: "IdleResourcesOptimization",
+			Handler:    jsonUnaryHandler(IdleResourcesOptimizationFullMethodName, AwsServicesOptimizationServer.IdleResourcesOptimization),
+		},
+		{
+			MethodName: "CommitmentsOptimization",
+			Handler:    jsonUnaryHandler(CommitmentsOptimizationFullMethodName, AwsServicesOptimizationServer.CommitmentsOptimization),
+		},
+	},
+	Streams: []grpc.StreamDesc{},
+}
@@ -0,0 +1,298 @@
+package grpc_server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+	"github.com/google/uuid"
+	"github.com/opengovern/og-util/pkg/httpserver"
+	"github.com/opengovern/opengovernance/pkg/utils"
+	"github.com/opengovern/opengovernance/services/wastage/api/entity"
+	"github.com/opengovern/opengovernance/services/wastage/db/model"
+	"github.com/opengovern/opengovernance/services/wastage/recommendation"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/metadata"
+	"time"
+)
+
+// awsServiceOptimization holds what differs between the optimizations of the aws services, the usage tracking,
+// blob upload and limit checks are the same for all of them.
+type awsServiceOptimization[Resp any] struct {
+	endpoint  string
+	limitName string
+	// checkLimit is optional, the optimization is not limited without it
+	checkLimit     func(ctx context.Context, auth0UserId, orgEmail string) (bool, error)
+	requestId      *string
+	cliVersion     *string
+	identification map[string]string
+	resourceId     string
+	loading        bool
+	// fullRequest is uploaded to the blob storage, trimmedRequest without the metrics is stored in the usage
+	fullRequest    any
+	trimmedRequest any
+	// recommend returns the response with its current and recommended monthly cost
+	recommend func(ctx context.Context) (*Resp, float64, float64, error)
+}
+
+func runAwsServiceOptimization[Resp any](ctx context.Context, s *awsPluginServer, o awsServiceOptimization[Resp]) (*Resp, error) {
+	start := time.Now()
+	ctx, span := s.tracer.Start(ctx, "get")
+	defer span.End()
+
+	var resp *Resp
+	var currentCost, recommendedCost float64
+	var err error
+
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil, fmt.Errorf("failed to get incoming context")
+	}
+
+	userIds := md.Get(httpserver.XKaytuUserIDHeader)
+	if len(userIds) == 0 {
+		return nil, fmt.Errorf("user not found")
+	}
+	userId := userIds[0]
+
+	stats := model.Statistics{
+		AccountID:   o.identification["account"],
+		OrgEmail:    o.identification["org_m_email"],
+		ResourceID:  o.resourceId,
+		Auth0UserId: userId,
+	}
+	statsOut, _ := json.Marshal(stats)
+
+	fullReqJson, _ := json.Marshal(o.fullRequest)
+	trimmedReqJson, _ := json.Marshal(o.trimmedRequest)
+
+	requestId := o.requestId
+	if requestId == nil {
+		id := uuid.New().String()
+		requestId = &id
+	}
+
+	s.blobWorkerPool.Submit(func() {
+		_, err := s.blobClient.UploadBuffer(context.Background(), s.cfg.AzBlob.Container, fmt.Sprintf("%s/%s.json", o.endpoint, *requestId), fullReqJson, &azblob.UploadBufferOptions{AccessTier: utils.GetPointer(blob.AccessTierCold)})
+		if err != nil {
+			s.logger.Error("failed to upload usage to blob storage", zap.Error(err))
+		}
+	})
+
+	usage := model.UsageV2{
+		ApiEndpoint:    o.endpoint,
+		Request:        trimmedReqJson,
+		RequestId:      requestId,
+		CliVersion:     o.cliVersion,
+		Response:       nil,
+		FailureMessage: nil,
+		Statistics:     statsOut,
+	}
+	err = s.usageRepo.Create(&usage)
+	if err != nil {
+		s.logger.Error("failed to create usage", zap.Error(err))
+		return nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			fmsg := err.Error()
+			usage.FailureMessage = &fmsg
+		} else {
+			usage.Response, _ = json.Marshal(resp)
+			id := uuid.New()
+			responseId := id.String()
+			usage.ResponseId = &responseId
+
+			stats.CurrentCost = currentCost
+			stats.RecommendedCost = recommendedCost
+			stats.Savings = currentCost - recommendedCost
+
+			statsOut, _ := json.Marshal(stats)
+			usage.Statistics = statsOut
+		}
+		err = s.usageRepo.Update(usage.ID, usage)
+		if err != nil {
+			s.logger.Error("failed to update usage", zap.Error(err), zap.Any("usage", usage))
+		}
+	}()
+	if o.loading {
+		return nil, nil
+	}
+
+	if o.checkLimit != nil {
+		ok, err = o.checkLimit(ctx, userId, o.identification["org_m_email"])
+		if err != nil {
+			s.logger.Error("failed to check limit", zap.String("endpoint", o.endpoint), zap.Error(err))
+			return nil, err
+		}
+		if !ok {
+			err = s.limitService.CheckPremiumAndSendErr(ctx, userId, o.identification["org_m_email"], o.limitName)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	r, current, recommended, err := o.recommend(ctx)
+	if err != nil {
+		s.logger.Error("failed to get recommendation", zap.String("endpoint", o.endpoint), zap.Error(err))
+		return nil, err
+	}
+
+	elapsed := time.Since(start).Seconds()
+	usage.Latency = &elapsed
+	err = s.usageRepo.Update(usage.ID, usage)
+	if err != nil {
+		s.logger.Error("failed to update usage", zap.Error(err), zap.Any("usage", usage))
+	}
+
+	// DO NOT change this, resp is used in updating usage
+	resp, currentCost, recommendedCost = r, current, recommended
+	// DO NOT change this, resp is used in updating usage
+	return resp, nil
+}
+
+func (s *awsPluginServer) ElastiCacheClusterOptimization(ctx context.Context, req *entity.ElastiCacheClusterWastageRequest) (*entity.ElastiCacheClusterWastageResponse, error) {
+	trimmed := *req
+	trimmed.Metrics = nil
+	return runAwsServiceOptimization(ctx, s, awsServiceOptimization[entity.ElastiCacheClusterWastageResponse]{
+		endpoint:       "aws-elasticache",
+		limitName:      "elasticache cluster",
+		checkLimit:     s.limitService.CheckElastiCacheClusterLimit,
+		requestId:      req.RequestId,
+		cliVersion:     req.CliVersion,
+		identification: req.Identification,
+		resourceId:     req.Cluster.HashedClusterId,
+		loading:        req.Loading,
+		fullRequest:    req,
+		trimmedRequest: trimmed,
+		recommend: func(ctx context.Context) (*entity.ElastiCacheClusterWastageResponse, float64, float64, error) {
+			recom, err := s.recomSvc.ElastiCacheClusterRecommendation(ctx, req.Region, req.Cluster, req.Metrics, req.Preferences, recommendation.UsageAverageTypeMax)
+			if err != nil {
+				return nil, 0, 0, err
+			}
+			recommended := recom.Current.Cost
+			if recom.Recommended != nil {
+				recommended = recom.Recommended.Cost
+			}
+			return &entity.ElastiCacheClusterWastageResponse{RightSizing: *recom}, recom.Current.Cost, recommended, nil
+		},
+	})
+}
+
+func (s *awsPluginServer) LambdaFunctionOptimization(ctx context.Context, req *entity.LambdaFunctionWastageRequest) (*entity.LambdaFunctionWastageResponse, error) {
+	trimmed := *req
+	trimmed.Metrics = nil
+	return runAwsServiceOptimization(ctx, s, awsServiceOptimization[entity.LambdaFunctionWastageResponse]{
+		endpoint:       "aws-lambda",
+		limitName:      "lambda function",
+		checkLimit:     s.limitService.CheckLambdaFunctionLimit,
+		requestId:      req.RequestId,
+		cliVersion:     req.CliVersion,
+		identification: req.Identification,
+		resourceId:     req.Function.HashedFunctionName,
+		loading:        req.Loading,
+		fullRequest:    req,
+		trimmedRequest: trimmed,
+		recommend: func(ctx context.Context) (*entity.LambdaFunctionWastageResponse, float64, float64, error) {
+			recom, err := s.recomSvc.LambdaFunctionRecommendation(ctx, req.Region, req.Function, req.Metrics, req.Preferences)
+			if err != nil {
+				return nil, 0, 0, err
+			}
+			recommended := recom.Current.Cost
+			if recom.Recommended != nil {
+				recommended = recom.Recommended.Cost
+			}
+			return &entity.LambdaFunctionWastageResponse{RightSizing: *recom}, recom.Current.Cost, recommended, nil
+		},
+	})
+}
+
+func (s *awsPluginServer) DynamoDBTableOptimization(ctx context.Context, req *entity.DynamoDBTableWastageRequest) (*entity.DynamoDBTableWastageResponse, error) {
+	trimmed := *req
+	trimmed.Metrics = nil
+	return runAwsServiceOptimization(ctx, s, awsServiceOptimization[entity.DynamoDBTableWastageResponse]{
+		endpoint:       "aws-dynamodb",
+		limitName:      "dynamodb table",
+		checkLimit:     s.limitService.CheckDynamoDBTableLimit,
+		requestId:      req.RequestId,
+		cliVersion:     req.CliVersion,
+		identification: req.Identification,
+		resourceId:     req.Table.HashedTableName,
+		loading:        req.Loading,
+		fullRequest:    req,
+		trimmedRequest: trimmed,
+		recommend: func(ctx context.Context) (*entity.DynamoDBTableWastageResponse, float64, float64, error) {
+			recom, err := s.recomSvc.DynamoDBTableRecommendation(ctx, req.Region, req.Table, req.Metrics, req.Preferences)
+			if err != nil {
+				return nil, 0, 0, err
+			}
+			recommended := recom.Current.Cost
+			if recom.Recommended != nil {
+				recommended = recom.Recommended.Cost
+			}
+			return &entity.DynamoDBTableWastageResponse{RightSizing: *recom}, recom.Current.Cost, recommended, nil
+		},
+	})
+}
+
+func (s *awsPluginServer) IdleResourcesOptimization(ctx context.Context, req *entity.AwsIdleResourcesWastageRequest) (*entity.AwsIdleResourcesWastageResponse, error) {
+	trimmed := *req
+	trimmed.Metrics = nil
+	return runAwsServiceOptimization(ctx, s, awsServiceOptimization[entity.AwsIdleResourcesWastageResponse]{
+		endpoint:       "aws-idle-resources",
+		limitName:      "idle resources",
+		checkLimit:     s.limitService.CheckIdleResourcesLimit,
+		requestId:      req.RequestId,
+		cliVersion:     req.CliVersion,
+		identification: req.Identification,
+		loading:        req.Loading,
+		fullRequest:    req,
+		trimmedRequest: trimmed,
+		recommend: func(ctx context.Context) (*entity.AwsIdleResourcesWastageResponse, float64, float64, error) {
+			resp := entity.AwsIdleResourcesWastageResponse{Resources: make(map[string]entity.AwsIdleResourceRecommendation)}
+			var current, recommended float64
+			for _, resource := range req.Resources {
+				recom, err := s.recomSvc.AwsIdleResourceRecommendation(ctx, req.Region, resource, req.Metrics[resource.HashedResourceId], req.Preferences)
+				if err != nil {
+					return nil, 0, 0, fmt.Errorf("failed to get idle resource %s recommendation: %w", resource.HashedResourceId, err)
+				}
+				resp.Resources[resource.HashedResourceId] = *recom
+				current += recom.MonthlyCost
+				if !recom.Idle {
+					recommended += recom.MonthlyCost
+				}
+			}
+			return &resp, current, recommended, nil
+		},
+	})
+}
+
+func (s *awsPluginServer) CommitmentsOptimization(ctx context.Context, req *entity.AwsCommitmentsRequest) (*entity.AwsCommitmentsResponse, error) {
+	trimmed := *req
+	trimmed.Usage = nil
+	trimmed.Instances = nil
+	return runAwsServiceOptimization(ctx, s, awsServiceOptimization[entity.AwsCommitmentsResponse]{
+		endpoint:       "aws-commitments",
+		requestId:      req.RequestId,
+		cliVersion:     req.CliVersion,
+		identification: req.Identification,
+		loading:        req.Loading,
+		fullRequest:    req,
+		trimmedRequest: trimmed,
+		recommend: func(ctx context.Context) (*entity.AwsCommitmentsResponse, float64, float64, error) {
+			resp, err := s.recomSvc.AwsCommitmentsRecommendation(ctx, *req)
+			if err != nil {
+				return nil, 0, 0, err
+			}
+			// the usage statistics count the savings of the best recommendation, the recommendations are alternatives
+			savings := 0.0
+			if len(resp.Recommendations) > 0 {
+				savings = resp.Recommendations[0].EstimatedMonthlySavings
+			}
+			return resp, resp.OnDemandMonthlyCost, resp.OnDemandMonthlyCost - savings, nil
+		},
+	})
+}
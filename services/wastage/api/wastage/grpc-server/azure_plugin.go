@@ -0,0 +1,194 @@
+package grpc_server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+	"github.com/alitto/pond"
+	"github.com/google/uuid"
+	"github.com/opengovern/og-util/pkg/httpserver"
+	"github.com/opengovern/opengovernance/pkg/utils"
+	"github.com/opengovern/opengovernance/services/wastage/api/entity"
+	"github.com/opengovern/opengovernance/services/wastage/config"
+	"github.com/opengovern/opengovernance/services/wastage/db/model"
+	"github.com/opengovern/opengovernance/services/wastage/db/repo"
+	"github.com/opengovern/opengovernance/services/wastage/recommendation"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/metadata"
+	"time"
+)
+
+type azurePluginServer struct {
+	cfg config.WastageConfig
+
+	tracer trace.Tracer
+	logger *zap.Logger
+
+	blobClient     *azblob.Client
+	blobWorkerPool *pond.WorkerPool
+
+	usageRepo repo.UsageV2Repo
+	recomSvc  *recommendation.Service
+}
+
+func newAzurePluginServer(logger *zap.Logger, cfg config.WastageConfig, blobClient *azblob.Client, blobWorkerPool *pond.WorkerPool, usageRepo repo.UsageV2Repo, recomSvc *recommendation.Service) *azurePluginServer {
+	return &azurePluginServer{
+		cfg:            cfg,
+		tracer:         otel.GetTracerProvider().Tracer("wastage.http.sources"),
+		logger:         logger.Named("grpc"),
+		blobClient:     blobClient,
+		blobWorkerPool: blobWorkerPool,
+		usageRepo:      usageRepo,
+		recomSvc:       recomSvc,
+	}
+}
+
+func (s *azurePluginServer) AzureVMOptimization(ctx context.Context, req *entity.AzureVMWastageRequest) (*entity.AzureVMWastageResponse, error) {
+	start := time.Now()
+	ctx, span := s.tracer.Start(ctx, "get")
+	defer span.End()
+
+	var resp entity.AzureVMWastageResponse
+	var err error
+
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil, fmt.Errorf("failed to get incoming context")
+	}
+
+	userIds := md.Get(httpserver.XKaytuUserIDHeader)
+	userId := ""
+	if len(userIds) == 0 {
+		return nil, fmt.Errorf("user not found")
+	}
+	userId = userIds[0]
+
+	stats := model.Statistics{
+		AccountID:   req.Identification["subscription_id"],
+		OrgEmail:    "",
+		ResourceID:  req.VM.Id,
+		Auth0UserId: userId,
+	}
+	statsOut, _ := json.Marshal(stats)
+
+	fullReqJson, _ := json.Marshal(req)
+	metrics := req.Metrics
+	diskMetrics := req.DiskMetrics
+	req.Metrics = nil
+	req.DiskMetrics = nil
+	trimmedReqJson, _ := json.Marshal(req)
+	req.Metrics = metrics
+	req.DiskMetrics = diskMetrics
+
+	if req.RequestId == nil {
+		id := uuid.New().String()
+		req.RequestId = &id
+	}
+
+	s.blobWorkerPool.Submit(func() {
+		_, err = s.blobClient.UploadBuffer(context.Background(), s.cfg.AzBlob.Container, fmt.Sprintf("azure-vm/%s.json", *req.RequestId), fullReqJson, &azblob.UploadBufferOptions{AccessTier: utils.GetPointer(blob.AccessTierCold)})
+		if err != nil {
+			s.logger.Error("failed to upload usage to blob storage", zap.Error(err))
+		}
+	})
+
+	usage := model.UsageV2{
+		ApiEndpoint:    "azure-vm",
+		Request:        trimmedReqJson,
+		RequestId:      req.RequestId,
+		CliVersion:     req.CliVersion,
+		Response:       nil,
+		FailureMessage: nil,
+		Statistics:     statsOut,
+	}
+	err = s.usageRepo.Create(&usage)
+	if err != nil {
+		s.logger.Error("failed to create usage", zap.Error(err))
+		return nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			fmsg := err.Error()
+			usage.FailureMessage = &fmsg
+		} else {
+			usage.Response, _ = json.Marshal(resp)
+			id := uuid.New()
+			responseId := id.String()
+			usage.ResponseId = &responseId
+
+			recom := entity.RightsizingAzureVM{}
+			if resp.RightSizing.Recommended != nil {
+				recom = *resp.RightSizing.Recommended
+			}
+
+			diskCurrentCost := 0.0
+			diskRecommendedCost := 0.0
+			for _, v := range resp.DiskRightSizing {
+				diskCurrentCost += v.Current.Cost
+				if v.Recommended != nil {
+					diskRecommendedCost += v.Recommended.Cost
+				}
+			}
+			stats.AzureManagedDiskCurrentCost = diskCurrentCost
+			stats.AzureManagedDiskRecommendedCost = diskRecommendedCost
+			stats.AzureManagedDiskSavings = diskCurrentCost - diskRecommendedCost
+			stats.AzureManagedDiskCount = len(resp.DiskRightSizing)
+
+			stats.AzureVMCurrentCost = resp.RightSizing.Current.Cost
+			stats.AzureVMRecommendedCost = recom.Cost
+			stats.AzureVMSavings = resp.RightSizing.Current.Cost - recom.Cost
+
+			stats.CurrentCost = stats.AzureVMCurrentCost + stats.AzureManagedDiskCurrentCost
+			stats.RecommendedCost = stats.AzureVMRecommendedCost + stats.AzureManagedDiskRecommendedCost
+			stats.Savings = stats.AzureVMSavings + stats.AzureManagedDiskSavings
+
+			statsOut, _ := json.Marshal(stats)
+			usage.Statistics = statsOut
+		}
+		err = s.usageRepo.Update(usage.ID, usage)
+		if err != nil {
+			s.logger.Error("failed to update usage", zap.Error(err), zap.Any("usage", usage))
+		}
+	}()
+	if req.Loading {
+		return nil, nil
+	}
+
+	vmRightSizingRecom, _, recomSKU, err := s.recomSvc.AzureVMRecommendation(ctx, req.VM, req.Metrics, req.Preferences)
+	if err != nil {
+		s.logger.Error("failed to get azure vm recommendation", zap.Error(err))
+		return nil, err
+	}
+
+	diskRightSizingRecoms := make(map[string]entity.AzureManagedDiskRecommendation)
+	for _, disk := range req.Disks {
+		var diskRightSizingRecom *entity.AzureManagedDiskRecommendation
+		diskRightSizingRecom, err = s.recomSvc.AzureManagedDiskRecommendation(ctx, disk, recomSKU, req.DiskMetrics[disk.Id], req.Preferences)
+		if err != nil {
+			err = fmt.Errorf("failed to get Azure Managed Disk %s recommendation: %s", disk.Id, err.Error())
+			return nil, err
+		}
+		diskRightSizingRecoms[disk.Id] = *diskRightSizingRecom
+	}
+
+	elapsed := time.Since(start).Seconds()
+	usage.Latency = &elapsed
+	err = s.usageRepo.Update(usage.ID, usage)
+	if err != nil {
+		s.logger.Error("failed to update usage", zap.Error(err), zap.Any("usage", usage))
+	}
+
+	// DO NOT change this, resp is used in updating usage
+	resp = entity.AzureVMWastageResponse{
+		RightSizing:     *vmRightSizingRecom,
+		DiskRightSizing: diskRightSizingRecoms,
+	}
+	// DO NOT change this, resp is used in updating usage
+
+	return &resp, nil
+}
@@ -0,0 +1,74 @@
+package grpc_server
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/opengovern/opengovernance/services/wastage/api/entity"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+)
+
+// There is no protobuf definition for the azure plugin yet, so the azure optimization service is described by hand
+// and exchanges the entity types as JSON. Clients have to call it with grpc.CallContentSubtype(jsonCodecName).
+const (
+	jsonCodecName = "json"
+
+	AzureOptimizationServiceName      = "azure.Optimization"
+	AzureVMOptimizationFullMethodName = "/azure.Optimization/AzureVMOptimization"
+)
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+func (jsonCodec) Name() string {
+	return jsonCodecName
+}
+
+func init() {
+	encoding.RegisterCodec(jsonCodec{})
+}
+
+type AzureOptimizationServer interface {
+	AzureVMOptimization(context.Context, *entity.AzureVMWastageRequest) (*entity.AzureVMWastageResponse, error)
+}
+
+func RegisterAzureOptimizationServer(s grpc.ServiceRegistrar, srv AzureOptimizationServer) {
+	s.RegisterService(&azureOptimizationServiceDesc, srv)
+}
+
+func azureVMOptimizationHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(entity.AzureVMWastageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AzureOptimizationServer).AzureVMOptimization(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AzureVMOptimizationFullMethodName,
+	}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(AzureOptimizationServer).AzureVMOptimization(ctx, req.(*entity.AzureVMWastageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var azureOptimizationServiceDesc = grpc.ServiceDesc{
+	ServiceName: AzureOptimizationServiceName,
+	HandlerType: (*AzureOptimizationServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "AzureVMOptimization",
+			Handler:    azureVMOptimizationHandler,
+		},
+	},
+	Streams: []grpc.StreamDesc{},
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/alitto/pond"
 	"github.com/google/uuid"
 	"github.com/opengovern/opengovernance/pkg/utils"
+	"github.com/opengovern/opengovernance/services/wastage/api/wastage/lifecycle"
 	"github.com/opengovern/opengovernance/services/wastage/api/wastage/limit"
 	"github.com/opengovern/opengovernance/services/wastage/config"
 	"github.com/opengovern/opengovernance/services/wastage/db/repo"
@@ -25,19 +26,22 @@ type Server struct {
 	kubernetesPluginServer *kubernetesPluginServer
 	gcpPluginServer        *gcpPluginServer
 	awsPluginServer        *awsPluginServer
+	azurePluginServer      *azurePluginServer
 }
 
 func NewServer(logger *zap.Logger, cfg config.WastageConfig, blobClient *azblob.Client, blobWorkerPool *pond.WorkerPool,
-	usageRepo repo.UsageV2Repo, recomSvc *recommendation.Service, limitSvc *limit.Service) *Server {
+	usageRepo repo.UsageV2Repo, recomSvc *recommendation.Service, limitSvc *limit.Service, lifecycleSvc *lifecycle.Service) *Server {
 	kuberServer := newKubernetesPluginServer(logger, cfg, blobClient, blobWorkerPool, usageRepo, recomSvc)
 	gcpServer := newGcpPluginServer(logger, cfg, blobClient, blobWorkerPool, usageRepo, recomSvc)
-	awsServer := newAwsPluginServer(logger, cfg, blobClient, blobWorkerPool, usageRepo, recomSvc, limitSvc)
+	awsServer := newAwsPluginServer(logger, cfg, blobClient, blobWorkerPool, usageRepo, recomSvc, limitSvc, lifecycleSvc)
+	azureServer := newAzurePluginServer(logger, cfg, blobClient, blobWorkerPool, usageRepo, recomSvc)
 
 	svr := Server{
 		logger:                 logger,
 		kubernetesPluginServer: kuberServer,
 		gcpPluginServer:        gcpServer,
 		awsPluginServer:        awsServer,
+		azurePluginServer:      azureServer,
 	}
 	return &svr
 }
@@ -80,6 +84,9 @@ func StartGrpcServer(server *Server, grpcServerAddress string, authGRPCURI strin
 	kubernetesPluginProto.RegisterOptimizationServer(s, server.kubernetesPluginServer)
 	gcpPluginProto.RegisterOptimizationServer(s, server.gcpPluginServer)
 	awsPluginProto.RegisterOptimizationServer(s, server.awsPluginServer)
+	RegisterAzureOptimizationServer(s, server.azurePluginServer)
+	RegisterAwsServicesOptimizationServer(s, server.awsPluginServer)
+	RegisterKubernetesClusterOptimizationServer(s, server.kubernetesPluginServer)
 	server.logger.Info("server listening at", zap.String("address", lis.Addr().String()))
 	utils.EnsureRunGoroutine(func() {
 		if err = s.Serve(lis); err != nil {
@@ -10,6 +10,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/opengovern/og-util/pkg/httpserver"
 	"github.com/opengovern/opengovernance/pkg/utils"
+	"github.com/opengovern/opengovernance/services/wastage/api/entity"
 	"github.com/opengovern/opengovernance/services/wastage/config"
 	"github.com/opengovern/opengovernance/services/wastage/db/model"
 	"github.com/opengovern/opengovernance/services/wastage/db/repo"
@@ -870,3 +871,120 @@ func (s *kubernetesPluginServer) KubernetesNodeGetCost(ctx context.Context, req
 
 	return &resp, nil
 }
+
+func (s *kubernetesPluginServer) KubernetesClusterOptimization(ctx context.Context, req *entity.KubernetesClusterWastageRequest) (*entity.KubernetesClusterWastageResponse, error) {
+	start := time.Now()
+	ctx, span := s.tracer.Start(ctx, "get")
+	defer span.End()
+
+	var resp entity.KubernetesClusterWastageResponse
+	var err error
+
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil, fmt.Errorf("failed to get incoming context")
+	}
+
+	userIds := md.Get(httpserver.XKaytuUserIDHeader)
+	userId := ""
+	if len(userIds) == 0 {
+		return nil, fmt.Errorf("user not found")
+	}
+	userId = userIds[0]
+
+	email := req.Identification["cluster_name"]
+	if !strings.Contains(email, "@") {
+		email = email + "@local.temp"
+	}
+
+	accountId := req.Identification["auth_info_name"]
+	if accountId == "" {
+		accountId = req.Identification["cluster_server"]
+	}
+	stats := model.Statistics{
+		AccountID:   accountId,
+		OrgEmail:    email,
+		ResourceID:  req.Identification["cluster_name"],
+		Auth0UserId: userId,
+	}
+	statsOut, _ := json.Marshal(stats)
+
+	fullReqJson, _ := json.Marshal(req)
+	nodes, workloads := req.Nodes, req.Workloads
+	req.Nodes, req.Workloads = nil, nil
+	trimmedReqJson, _ := json.Marshal(req)
+	req.Nodes, req.Workloads = nodes, workloads
+
+	requestId := req.RequestId
+	if requestId == nil {
+		id := uuid.New().String()
+		requestId = &id
+	}
+
+	s.blobWorkerPool.Submit(func() {
+		_, err = s.blobClient.UploadBuffer(context.Background(), s.cfg.AzBlob.Container, fmt.Sprintf("kubernetes-cluster/%s.json", *requestId), fullReqJson, &azblob.UploadBufferOptions{AccessTier: utils.GetPointer(blob.AccessTierCold)})
+		if err != nil {
+			s.logger.Error("failed to upload usage to blob storage", zap.Error(err))
+		}
+	})
+
+	usage := model.UsageV2{
+		ApiEndpoint:    "kubernetes-cluster",
+		Request:        trimmedReqJson,
+		RequestId:      requestId,
+		CliVersion:     req.CliVersion,
+		Response:       nil,
+		FailureMessage: nil,
+		Statistics:     statsOut,
+	}
+	err = s.usageRepo.Create(&usage)
+	if err != nil {
+		s.logger.Error("failed to create usage", zap.Error(err))
+		return nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			fmsg := err.Error()
+			usage.FailureMessage = &fmsg
+		} else {
+			usage.Response, _ = json.Marshal(resp)
+			id := uuid.New()
+			responseId := id.String()
+			usage.ResponseId = &responseId
+
+			stats.CurrentCost = resp.CurrentMonthlyCost
+			stats.RecommendedCost = resp.RecommendedMonthlyCost
+			stats.Savings = resp.ProjectedMonthlySavings
+			statsOut, _ := json.Marshal(stats)
+			usage.Statistics = statsOut
+		}
+		err = s.usageRepo.Update(usage.ID, usage)
+		if err != nil {
+			s.logger.Error("failed to update usage", zap.Error(err), zap.Any("usage", usage))
+		}
+	}()
+	if req.Loading {
+		return nil, nil
+	}
+
+	clusterRecom, err := s.recomSvc.KubernetesClusterRecommendation(ctx, req.Nodes, req.Workloads, req.Preferences)
+	if err != nil {
+		s.logger.Error("failed to get kubernetes cluster recommendation", zap.Error(err))
+		return nil, err
+	}
+
+	elapsed := time.Since(start).Seconds()
+	usage.Latency = &elapsed
+	err = s.usageRepo.Update(usage.ID, usage)
+	if err != nil {
+		s.logger.Error("failed to update usage", zap.Error(err), zap.Any("usage", usage))
+		return nil, err
+	}
+
+	// DO NOT change this, resp is used in updating usage
+	resp = *clusterRecom
+	// DO NOT change this, resp is used in updating usage
+
+	return &resp, nil
+}
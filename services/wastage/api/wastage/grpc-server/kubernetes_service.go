@@ -0,0 +1,35 @@
+package grpc_server
+
+import (
+	"context"
+	"github.com/opengovern/opengovernance/services/wastage/api/entity"
+	"google.golang.org/grpc"
+)
+
+// The kubernetes plugin proto only covers single objects, the cluster optimization is described by hand and
+// exchanges the entity types as JSON, the same way as the aws services optimization.
+const (
+	KubernetesClusterOptimizationServiceName = "kubernetes.ClusterOptimization"
+
+	KubernetesClusterOptimizationFullMethodName = "/kubernetes.ClusterOptimization/KubernetesClusterOptimization"
+)
+
+type KubernetesClusterOptimizationServer interface {
+	KubernetesClusterOptimization(context.Context, *entity.KubernetesClusterWastageRequest) (*entity.KubernetesClusterWastageResponse, error)
+}
+
+func RegisterKubernetesClusterOptimizationServer(s grpc.ServiceRegistrar, srv KubernetesClusterOptimizationServer) {
+	s.RegisterService(&kubernetesClusterOptimizationServiceDesc, srv)
+}
+
+var kubernetesClusterOptimizationServiceDesc = grpc.ServiceDesc{
+	ServiceName: KubernetesClusterOptimizationServiceName,
+	HandlerType: (*KubernetesClusterOptimizationServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "KubernetesClusterOptimization",
+			Handler:    jsonUnaryHandler(KubernetesClusterOptimizationFullMethodName, KubernetesClusterOptimizationServer.KubernetesClusterOptimization),
+		},
+	},
+	Streams: []grpc.StreamDesc{},
+}
@@ -0,0 +1,259 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/opengovern/opengovernance/services/wastage/api/entity"
+	"github.com/opengovern/opengovernance/services/wastage/db/model"
+	"github.com/opengovern/opengovernance/services/wastage/db/repo"
+	"go.uber.org/zap"
+	"sort"
+	"strings"
+	"time"
+)
+
+// VerificationDelay is how long an applied change has to stay in place before its savings are verified.
+const VerificationDelay = 7 * 24 * time.Hour
+
+var (
+	ErrNotFound          = errors.New("recommendation not found")
+	ErrInvalidTransition = errors.New("invalid recommendation state transition")
+	ErrReasonRequired    = errors.New("a reason is required to dismiss a recommendation")
+)
+
+// Observation is what a wastage request tells about a resource, RecommendedType is empty when nothing is
+// recommended for it.
+type Observation struct {
+	ApiEndpoint string
+	ResourceID  string
+	AccountID   string
+	OrgEmail    string
+	Auth0UserId string
+	UsageID     uint
+
+	CurrentType     string
+	CurrentCost     float64
+	RecommendedType string
+	RecommendedCost float64
+}
+
+func (o Observation) hasRecommendation() bool {
+	return o.RecommendedType != "" && !strings.EqualFold(o.RecommendedType, o.CurrentType) && o.CurrentCost > o.RecommendedCost
+}
+
+type Service struct {
+	logger             *zap.Logger
+	recommendationRepo repo.RecommendationRepo
+}
+
+func NewLifecycleService(logger *zap.Logger, recommendationRepo repo.RecommendationRepo) *Service {
+	return &Service{
+		logger:             logger.Named("lifecycle"),
+		recommendationRepo: recommendationRepo,
+	}
+}
+
+// Observe records the recommendation of the request, or moves the tracked recommendation of the resource when
+// the request shows the resource has changed.
+func (s *Service) Observe(ctx context.Context, o Observation) error {
+	if o.ResourceID == "" || o.CurrentType == "" {
+		return nil
+	}
+
+	rec, err := s.recommendationRepo.GetActiveByResource(ctx, o.ApiEndpoint, o.ResourceID)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if rec == nil {
+		if !o.hasRecommendation() {
+			return nil
+		}
+		rec = &model.Recommendation{
+			ApiEndpoint:    o.ApiEndpoint,
+			ResourceID:     o.ResourceID,
+			AccountID:      o.AccountID,
+			OrgEmail:       o.OrgEmail,
+			Auth0UserId:    o.Auth0UserId,
+			State:          model.RecommendationStateOpen,
+			StateChangedAt: now,
+			CurrentType:    o.CurrentType,
+			LastUsageID:    o.UsageID,
+		}
+		refreshRecommendation(rec, o)
+		return s.recommendationRepo.Create(ctx, rec)
+	}
+
+	observe(rec, o, now)
+	return s.recommendationRepo.Save(ctx, rec)
+}
+
+// observe moves the recommendation by what the latest request of its resource shows.
+func observe(rec *model.Recommendation, o Observation, now time.Time) {
+	rec.LastUsageID = o.UsageID
+	changed := !strings.EqualFold(o.CurrentType, rec.CurrentType)
+
+	switch rec.State {
+	case model.RecommendationStateOpen, model.RecommendationStateAccepted, model.RecommendationStateDismissed:
+		if changed {
+			appliedType := o.CurrentType
+			rec.AppliedType = &appliedType
+			rec.AppliedAt = &now
+			measureSavings(rec, o)
+			setState(rec, model.RecommendationStateApplied, now)
+			return
+		}
+		if rec.State != model.RecommendationStateDismissed {
+			refreshRecommendation(rec, o)
+		}
+	case model.RecommendationStateApplied:
+		if !changed {
+			// the change was reverted
+			rec.AppliedType, rec.AppliedAt, rec.CostAfter, rec.RealizedSavings = nil, nil, nil, nil
+			refreshRecommendation(rec, o)
+			setState(rec, model.RecommendationStateOpen, now)
+			return
+		}
+		appliedType := o.CurrentType
+		rec.AppliedType = &appliedType
+		if rec.AppliedAt == nil {
+			rec.AppliedAt = &now
+		}
+		measureSavings(rec, o)
+		if now.Sub(*rec.AppliedAt) >= VerificationDelay {
+			rec.VerifiedAt = &now
+			setState(rec, model.RecommendationStateVerified, now)
+		}
+	}
+}
+
+func refreshRecommendation(rec *model.Recommendation, o Observation) {
+	rec.CurrentCost = o.CurrentCost
+	rec.RecommendedType = o.RecommendedType
+	rec.RecommendedCost = o.RecommendedCost
+	rec.PotentialSavings = 0
+	if o.hasRecommendation() {
+		rec.PotentialSavings = o.CurrentCost - o.RecommendedCost
+	}
+}
+
+// measureSavings compares the cost of the resource before the recommendation with its cost now.
+func measureSavings(rec *model.Recommendation, o Observation) {
+	costAfter := o.CurrentCost
+	realized := rec.CurrentCost - costAfter
+	rec.CostAfter = &costAfter
+	rec.RealizedSavings = &realized
+}
+
+func setState(rec *model.Recommendation, state model.RecommendationState, now time.Time) {
+	rec.State = state
+	rec.StateChangedAt = now
+}
+
+func (s *Service) List(ctx context.Context, filter repo.RecommendationFilter) ([]entity.Recommendation, error) {
+	recs, err := s.recommendationRepo.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]entity.Recommendation, 0, len(recs))
+	for _, rec := range recs {
+		result = append(result, entity.NewRecommendation(rec))
+	}
+	return result, nil
+}
+
+// Transition moves a recommendation of the user to another state, applied and verified are usually reached by
+// Observe and can be set by hand when the change is not seen by a later request.
+func (s *Service) Transition(ctx context.Context, auth0UserId string, id uint, req entity.RecommendationStateRequest) (*entity.Recommendation, error) {
+	rec, err := s.recommendationRepo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if rec == nil || rec.Auth0UserId != auth0UserId {
+		return nil, ErrNotFound
+	}
+	if err := transition(rec, req, time.Now()); err != nil {
+		return nil, err
+	}
+	if err := s.recommendationRepo.Save(ctx, rec); err != nil {
+		return nil, err
+	}
+	result := entity.NewRecommendation(*rec)
+	return &result, nil
+}
+
+func transition(rec *model.Recommendation, req entity.RecommendationStateRequest, now time.Time) error {
+	if !rec.CanTransition(req.State) {
+		return fmt.Errorf("%w: %s to %s", ErrInvalidTransition, rec.State, req.State)
+	}
+
+	switch req.State {
+	case model.RecommendationStateDismissed:
+		if req.Reason == nil || strings.TrimSpace(*req.Reason) == "" {
+			return ErrReasonRequired
+		}
+		reason := strings.TrimSpace(*req.Reason)
+		rec.DismissReason = &reason
+	case model.RecommendationStateOpen, model.RecommendationStateAccepted:
+		rec.DismissReason = nil
+		rec.AppliedType, rec.AppliedAt, rec.CostAfter, rec.RealizedSavings = nil, nil, nil, nil
+	case model.RecommendationStateApplied:
+		// the savings are measured by the next request of the resource
+		appliedType := rec.RecommendedType
+		rec.AppliedType = &appliedType
+		rec.AppliedAt = &now
+	case model.RecommendationStateVerified:
+		if rec.RealizedSavings == nil {
+			return fmt.Errorf("%w: the realized savings are not measured yet", ErrInvalidTransition)
+		}
+		rec.VerifiedAt = &now
+	}
+	setState(rec, req.State, now)
+	return nil
+}
+
+// Report sums the potential and realized savings of the recommendations by account or organization.
+func (s *Service) Report(ctx context.Context, groupBy string, filter repo.RecommendationFilter) (*entity.RecommendationSavingsReport, error) {
+	rows, err := s.recommendationRepo.GetSavings(ctx, groupBy, filter)
+	if err != nil {
+		return nil, err
+	}
+	return buildReport(groupBy, rows), nil
+}
+
+func buildReport(groupBy string, rows []repo.RecommendationSavings) *entity.RecommendationSavingsReport {
+	report := entity.RecommendationSavingsReport{
+		GroupBy: groupBy,
+		Total:   entity.RecommendationSavingsGroup{States: make(map[model.RecommendationState]int64)},
+	}
+	groups := make(map[string]*entity.RecommendationSavingsGroup)
+	for _, row := range rows {
+		group, ok := groups[row.Group]
+		if !ok {
+			group = &entity.RecommendationSavingsGroup{Group: row.Group, States: make(map[model.RecommendationState]int64)}
+			groups[row.Group] = group
+		}
+		for _, g := range []*entity.RecommendationSavingsGroup{group, &report.Total} {
+			g.Recommendations += row.Count
+			g.States[row.State] += row.Count
+			switch row.State {
+			case model.RecommendationStateOpen, model.RecommendationStateAccepted:
+				g.PotentialSavings += row.PotentialSavings
+			case model.RecommendationStateDismissed:
+				g.DismissedSavings += row.PotentialSavings
+			case model.RecommendationStateApplied:
+				g.PendingSavings += row.RealizedSavings
+			case model.RecommendationStateVerified:
+				g.RealizedSavings += row.RealizedSavings
+			}
+		}
+	}
+	for _, group := range groups {
+		report.Groups = append(report.Groups, *group)
+	}
+	sort.Slice(report.Groups, func(i, j int) bool {
+		return report.Groups[i].Group < report.Groups[j].Group
+	})
+	return &report
+}
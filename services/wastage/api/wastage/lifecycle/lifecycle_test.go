@@ -0,0 +1,81 @@
+package lifecycle
+
+import (
+	"github.com/opengovern/opengovernance/services/wastage/api/entity"
+	"github.com/opengovern/opengovernance/services/wastage/db/model"
+	"github.com/opengovern/opengovernance/services/wastage/db/repo"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestObserveAppliedAndVerified(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	rec := &model.Recommendation{
+		State:           model.RecommendationStateAccepted,
+		CurrentType:     "m5.xlarge",
+		CurrentCost:     140,
+		RecommendedType: "m5.large",
+		RecommendedCost: 70,
+	}
+
+	observe(rec, Observation{CurrentType: "m5.xlarge", CurrentCost: 150, RecommendedType: "m5.large", RecommendedCost: 75}, now)
+	assert.Equal(t, model.RecommendationStateAccepted, rec.State)
+	assert.Equal(t, 75.0, rec.PotentialSavings)
+
+	observe(rec, Observation{CurrentType: "t3.large", CurrentCost: 60}, now.Add(time.Hour))
+	assert.Equal(t, model.RecommendationStateApplied, rec.State)
+	assert.Equal(t, "t3.large", *rec.AppliedType)
+	assert.Equal(t, 90.0, *rec.RealizedSavings)
+
+	observe(rec, Observation{CurrentType: "t3.large", CurrentCost: 65}, now.Add(VerificationDelay+time.Hour))
+	assert.Equal(t, model.RecommendationStateVerified, rec.State)
+	assert.Equal(t, 85.0, *rec.RealizedSavings)
+}
+
+func TestObserveReverted(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	rec := &model.Recommendation{State: model.RecommendationStateOpen, CurrentType: "db.r5.large", CurrentCost: 200}
+
+	observe(rec, Observation{CurrentType: "db.r5.xlarge", CurrentCost: 400}, now)
+	assert.Equal(t, model.RecommendationStateApplied, rec.State)
+	assert.Equal(t, -200.0, *rec.RealizedSavings)
+
+	observe(rec, Observation{CurrentType: "db.r5.large", CurrentCost: 200, RecommendedType: "db.r5.medium", RecommendedCost: 100}, now)
+	assert.Equal(t, model.RecommendationStateOpen, rec.State)
+	assert.Nil(t, rec.AppliedType)
+	assert.Nil(t, rec.RealizedSavings)
+	assert.Equal(t, 100.0, rec.PotentialSavings)
+}
+
+func TestTransition(t *testing.T) {
+	now := time.Now()
+	rec := &model.Recommendation{State: model.RecommendationStateOpen}
+
+	assert.ErrorIs(t, transition(rec, entity.RecommendationStateRequest{State: model.RecommendationStateDismissed}, now), ErrReasonRequired)
+	reason := "needed for the peak season"
+	assert.NoError(t, transition(rec, entity.RecommendationStateRequest{State: model.RecommendationStateDismissed, Reason: &reason}, now))
+	assert.Equal(t, reason, *rec.DismissReason)
+
+	assert.ErrorIs(t, transition(rec, entity.RecommendationStateRequest{State: model.RecommendationStateVerified}, now), ErrInvalidTransition)
+	assert.NoError(t, transition(rec, entity.RecommendationStateRequest{State: model.RecommendationStateOpen}, now))
+	assert.Nil(t, rec.DismissReason)
+}
+
+func TestBuildReport(t *testing.T) {
+	report := buildReport(repo.RecommendationGroupByAccount, []repo.RecommendationSavings{
+		{Group: "b", State: model.RecommendationStateOpen, Count: 2, PotentialSavings: 100},
+		{Group: "a", State: model.RecommendationStateVerified, Count: 1, PotentialSavings: 50, RealizedSavings: 45},
+		{Group: "a", State: model.RecommendationStateApplied, Count: 1, PotentialSavings: 30, RealizedSavings: 20},
+		{Group: "a", State: model.RecommendationStateDismissed, Count: 1, PotentialSavings: 10},
+	})
+
+	assert.Len(t, report.Groups, 2)
+	assert.Equal(t, "a", report.Groups[0].Group)
+	assert.Equal(t, int64(3), report.Groups[0].Recommendations)
+	assert.Equal(t, 45.0, report.Groups[0].RealizedSavings)
+	assert.Equal(t, 20.0, report.Groups[0].PendingSavings)
+	assert.Equal(t, 10.0, report.Groups[0].DismissedSavings)
+	assert.Equal(t, int64(5), report.Total.Recommendations)
+	assert.Equal(t, 100.0, report.Total.PotentialSavings)
+}
@@ -7,9 +7,19 @@ const (
 	UserRDSClusterLimit  = int32(50)
 	UserAccountLimit     = int32(5)
 
+	UserElastiCacheClusterLimit = int32(100)
+	UserLambdaFunctionLimit     = int32(500)
+	UserDynamoDBTableLimit      = int32(100)
+	UserIdleResourcesLimit      = int32(500)
+
 	OrgEC2InstanceLimit = int32(2000)
 	OrgEBSVolumeLimit   = int32(2000)
 	OrgRDSInstanceLimit = int32(1000)
 	OrgRDSClusterLimit  = int32(500)
 	OrgAccountLimit     = int32(5)
+
+	OrgElastiCacheClusterLimit = int32(1000)
+	OrgLambdaFunctionLimit     = int32(2000)
+	OrgDynamoDBTableLimit      = int32(1000)
+	OrgIdleResourcesLimit      = int32(2000)
 )
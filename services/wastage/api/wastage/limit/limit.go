@@ -175,3 +175,46 @@ func checkAccountInList(acc string, accounts []string) bool {
 	}
 	return false
 }
+
+func (s *Service) CheckElastiCacheClusterLimit(ctx context.Context, auth0UserId, orgEmail string) (bool, error) {
+	return s.checkLimit(ctx, "ElastiCache Cluster", "aws-elasticache", UserElastiCacheClusterLimit, OrgElastiCacheClusterLimit, auth0UserId, orgEmail)
+}
+
+func (s *Service) CheckLambdaFunctionLimit(ctx context.Context, auth0UserId, orgEmail string) (bool, error) {
+	return s.checkLimit(ctx, "Lambda Function", "aws-lambda", UserLambdaFunctionLimit, OrgLambdaFunctionLimit, auth0UserId, orgEmail)
+}
+
+func (s *Service) CheckDynamoDBTableLimit(ctx context.Context, auth0UserId, orgEmail string) (bool, error) {
+	return s.checkLimit(ctx, "DynamoDB Table", "aws-dynamodb", UserDynamoDBTableLimit, OrgDynamoDBTableLimit, auth0UserId, orgEmail)
+}
+
+func (s *Service) CheckIdleResourcesLimit(ctx context.Context, auth0UserId, orgEmail string) (bool, error) {
+	return s.checkLimit(ctx, "Idle Resources", "aws-idle-resources", UserIdleResourcesLimit, OrgIdleResourcesLimit, auth0UserId, orgEmail)
+}
+
+// checkLimit counts the optimizations of an endpoint, the org limit is checked first and the user limit after it.
+func (s *Service) checkLimit(ctx context.Context, name, endpoint string, userLimit, orgLimit int32, auth0UserId, orgEmail string) (bool, error) {
+	s.logger.Info(fmt.Sprintf("Checking %s limit", name), zap.String("auth0UserId", auth0UserId), zap.String("orgEmail", orgEmail))
+	if orgEmail != "" && strings.Contains(orgEmail, "@") {
+		org := strings.Split(orgEmail, "@")
+		if org[1] != "" {
+			orgCount, err := s.usageRepo.GetOptimizationsCountForOrg(ctx, endpoint, org[1])
+			if err != nil {
+				return false, err
+			}
+			if orgCount < int64(orgLimit) {
+				return true, nil
+			}
+			s.logger.Info(fmt.Sprintf("Org %s limit reached", name), zap.String("orgEmail", org[1]))
+		}
+	}
+	userCount, err := s.usageRepo.GetOptimizationsCountForUser(ctx, endpoint, auth0UserId)
+	if err != nil {
+		return false, err
+	}
+	if userCount < int64(userLimit) {
+		return true, nil
+	}
+	s.logger.Info(fmt.Sprintf("User %s limit reached", name), zap.String("auth0UserId", auth0UserId))
+	return false, nil
+}
@@ -0,0 +1,72 @@
+package limit
+
+import (
+	"context"
+	"github.com/opengovern/opengovernance/services/wastage/db/repo"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"testing"
+)
+
+type fakeUsageRepo struct {
+	repo.UsageV2Repo
+	// optimizations counted by endpoint and user id or org address
+	userCounts map[[2]string]int64
+	orgCounts  map[[2]string]int64
+}
+
+func (r fakeUsageRepo) GetOptimizationsCountForUser(ctx context.Context, endpoint, userId string) (int64, error) {
+	return r.userCounts[[2]string{endpoint, userId}], nil
+}
+
+func (r fakeUsageRepo) GetOptimizationsCountForOrg(ctx context.Context, endpoint, orgAddress string) (int64, error) {
+	return r.orgCounts[[2]string{endpoint, orgAddress}], nil
+}
+
+func TestAwsServiceLimits(t *testing.T) {
+	checks := []struct {
+		endpoint  string
+		userLimit int32
+		orgLimit  int32
+		check     func(s *Service) func(ctx context.Context, auth0UserId, orgEmail string) (bool, error)
+	}{
+		{"aws-elasticache", UserElastiCacheClusterLimit, OrgElastiCacheClusterLimit, func(s *Service) func(context.Context, string, string) (bool, error) {
+			return s.CheckElastiCacheClusterLimit
+		}},
+		{"aws-lambda", UserLambdaFunctionLimit, OrgLambdaFunctionLimit, func(s *Service) func(context.Context, string, string) (bool, error) {
+			return s.CheckLambdaFunctionLimit
+		}},
+		{"aws-dynamodb", UserDynamoDBTableLimit, OrgDynamoDBTableLimit, func(s *Service) func(context.Context, string, string) (bool, error) {
+			return s.CheckDynamoDBTableLimit
+		}},
+		{"aws-idle-resources", UserIdleResourcesLimit, OrgIdleResourcesLimit, func(s *Service) func(context.Context, string, string) (bool, error) {
+			return s.CheckIdleResourcesLimit
+		}},
+	}
+
+	for _, c := range checks {
+		tests := []struct {
+			name      string
+			userCount int64
+			orgCount  int64
+			orgEmail  string
+			want      bool
+		}{
+			{name: "under both limits", orgEmail: "admin@example.com", want: true},
+			{name: "org under its limit", userCount: int64(c.userLimit), orgCount: int64(c.orgLimit) - 1, orgEmail: "admin@example.com", want: true},
+			{name: "org limit reached, user under its limit", userCount: int64(c.userLimit) - 1, orgCount: int64(c.orgLimit), orgEmail: "admin@example.com", want: true},
+			{name: "both limits reached", userCount: int64(c.userLimit), orgCount: int64(c.orgLimit), orgEmail: "admin@example.com"},
+			{name: "no org, user limit reached", userCount: int64(c.userLimit), orgCount: 0, orgEmail: "admin"},
+			{name: "no org, user under its limit", userCount: int64(c.userLimit) - 1, want: true},
+		}
+		for _, tt := range tests {
+			s := NewLimitService(zap.NewNop(), nil, nil, fakeUsageRepo{
+				userCounts: map[[2]string]int64{{c.endpoint, "user-1"}: tt.userCount},
+				orgCounts:  map[[2]string]int64{{c.endpoint, "example.com"}: tt.orgCount},
+			})
+			ok, err := c.check(s)(context.Background(), "user-1", tt.orgEmail)
+			assert.NoError(t, err, c.endpoint, tt.name)
+			assert.Equal(t, tt.want, ok, c.endpoint, tt.name)
+		}
+	}
+}
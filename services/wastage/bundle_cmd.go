@@ -0,0 +1,115 @@
+package wastage
+
+import (
+	"fmt"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/opengovern/opengovernance/services/wastage/ingestion"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// bundleCommand builds an offline pricing bundle that can be used with the bundle pricing source in environments
+// without access to the provider price lists.
+func bundleCommand() *cobra.Command {
+	var (
+		output    string
+		version   string
+		providers []string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "bundle",
+		Short: "Build an offline pricing bundle",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+			logger, err := zap.NewProduction()
+			if err != nil {
+				return err
+			}
+			logger = logger.Named("bundle")
+
+			cmd.SilenceUsage = true
+
+			if version == "" {
+				version = time.Now().UTC().Format("20060102T150405Z")
+			}
+
+			dir := output
+			archive := strings.HasSuffix(output, ".tar.gz")
+			if archive {
+				dir, err = os.MkdirTemp("", "wastage-pricing-bundle-")
+				if err != nil {
+					return err
+				}
+				defer os.RemoveAll(dir)
+			}
+
+			w, err := ingestion.NewBundleWriter(dir, version)
+			if err != nil {
+				return err
+			}
+
+			for _, provider := range providers {
+				logger.Info("fetching prices", zap.String("provider", provider))
+				switch provider {
+				case "aws":
+					svc := ingestion.New(logger, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+					err = svc.WriteBundle(ctx, w)
+				case "gcp":
+					gcpCredentials := map[string]string{
+						"type":         "service_account",
+						"project_id":   GCPProjectID,
+						"private_key":  GCPPrivateKey,
+						"client_email": GCPClientEmail,
+					}
+					var svc *ingestion.GcpService
+					svc, err = ingestion.NewGcpService(ctx, logger, nil, nil, nil, nil, nil, nil, gcpCredentials, GCPProjectID)
+					if err != nil {
+						return err
+					}
+					err = svc.WriteBundle(ctx, w)
+				case "azure":
+					if AzureSubscriptionID == "" {
+						return fmt.Errorf("AZURE_SUBSCRIPTION_ID is required for azure prices")
+					}
+					var cred *azidentity.DefaultAzureCredential
+					cred, err = azidentity.NewDefaultAzureCredential(nil)
+					if err != nil {
+						return err
+					}
+					svc := ingestion.NewAzureService(logger, nil, nil, nil, nil, nil, cred, AzureSubscriptionID)
+					err = svc.WriteBundle(ctx, w)
+				default:
+					return fmt.Errorf("unknown provider %s", provider)
+				}
+				if err != nil {
+					return fmt.Errorf("failed to fetch %s prices: %w", provider, err)
+				}
+			}
+
+			if err = w.Close(); err != nil {
+				return err
+			}
+
+			if archive {
+				if err = ingestion.PackBundle(dir, output); err != nil {
+					return err
+				}
+			}
+
+			abs, _ := filepath.Abs(output)
+			logger.Info("pricing bundle written", zap.String("version", version), zap.String("output", abs))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&output, "output", "pricing-bundle.tar.gz", "Output directory, or .tar.gz file")
+	cmd.Flags().StringVar(&version, "version", "", "Bundle version, defaults to the current time")
+	cmd.Flags().StringSliceVar(&providers, "providers", []string{"aws", "gcp", "azure"}, "Providers to include in the bundle")
+
+	return cmd
+}
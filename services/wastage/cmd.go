@@ -1,6 +1,7 @@
 package wastage
 
 import (
+	"fmt"
 	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
 	"github.com/alitto/pond"
@@ -8,6 +9,7 @@ import (
 	"github.com/opengovern/og-util/pkg/koanf"
 	"github.com/opengovern/opengovernance/services/wastage/api"
 	grpc_server "github.com/opengovern/opengovernance/services/wastage/api/wastage/grpc-server"
+	"github.com/opengovern/opengovernance/services/wastage/api/wastage/lifecycle"
 	"github.com/opengovern/opengovernance/services/wastage/api/wastage/limit"
 	"github.com/opengovern/opengovernance/services/wastage/config"
 	"github.com/opengovern/opengovernance/services/wastage/cost"
@@ -28,6 +30,8 @@ var (
 	GCPProjectID   = os.Getenv("GCP_PROJECT_ID")
 	GCPPrivateKey  = os.Getenv("GCP_PRIVATE_KEY")
 	GCPClientEmail = os.Getenv("GCP_CLIENT_EMAIL")
+
+	AzureSubscriptionID = os.Getenv("AZURE_SUBSCRIPTION_ID")
 )
 
 func Command() *cobra.Command {
@@ -59,9 +63,9 @@ func Command() *cobra.Command {
 				logger.Error("failed to create citext extension", zap.Error(err))
 				return err
 			}
-			err = db.Conn().AutoMigrate(&model.DataAge{}, &model.Usage{}, &model.User{}, &model.Organization{})
+			err = db.Conn().AutoMigrate(&model.DataAge{}, &model.CatalogVersion{}, &model.Usage{}, &model.User{}, &model.Organization{})
 
-			err = usageDb.Conn().AutoMigrate(&model.Usage{}, &model.UsageV2{})
+			err = usageDb.Conn().AutoMigrate(&model.Usage{}, &model.UsageV2{}, &model.Recommendation{})
 			if err != nil {
 				logger.Error("failed to auto migrate", zap.Error(err))
 				return err
@@ -74,9 +78,17 @@ func Command() *cobra.Command {
 			computeMachineTypeRepo := repo.NewGCPComputeMachineTypeRepo(db)
 			computeDiskTypeRepo := repo.NewGCPComputeDiskTypeRepo(db)
 			computeSKURepo := repo.NewGCPComputeSKURepo(db)
+			azureVMSKURepo := repo.NewAzureVMSKURepo(db)
+			azureManagedDiskTypeRepo := repo.NewAzureManagedDiskTypeRepo(db)
+			elastiCacheNodeTypeRepo := repo.NewElastiCacheNodeTypeRepo(db)
+			lambdaPriceRepo := repo.NewLambdaPriceRepo(db)
+			dynamoDBPriceRepo := repo.NewDynamoDBPriceRepo(db)
+			awsIdleResourcePriceRepo := repo.NewAWSIdleResourcePriceRepo(db)
+			awsCommitmentPriceRepo := repo.NewAWSCommitmentPriceRepo(db)
 			dataAgeRepo := repo.NewDataAgeRepo(db)
 			usageV2Repo := repo.NewUsageV2Repo(usageDb)
 			usageV1Repo := repo.NewUsageRepo(usageDb)
+			recommendationRepo := repo.NewRecommendationRepo(usageDb)
 			userRepo := repo.NewUserRepo(db)
 			orgRepo := repo.NewOrganizationRepo(db)
 			costSvc := cost.New(cnf.Pennywise.BaseURL)
@@ -93,8 +105,41 @@ func Command() *cobra.Command {
 				return err
 			}
 
-			recomSvc := recommendation.New(logger, ec2InstanceRepo, ebsVolumeRepo, rdsInstanceRepo, rdsStorageRepo, computeMachineTypeRepo, computeDiskTypeRepo, computeSKURepo, cnf.OpenAIToken, costSvc)
-			ingestionSvc := ingestion.New(logger, db, ec2InstanceRepo, rdsRepo, rdsInstanceRepo, rdsStorageRepo, ebsVolumeRepo, dataAgeRepo)
+			recomSvc := recommendation.New(logger, ec2InstanceRepo, ebsVolumeRepo, rdsInstanceRepo, rdsStorageRepo, computeMachineTypeRepo, computeDiskTypeRepo, computeSKURepo, azureVMSKURepo, azureManagedDiskTypeRepo, elastiCacheNodeTypeRepo, lambdaPriceRepo, dynamoDBPriceRepo, awsIdleResourcePriceRepo, awsCommitmentPriceRepo, cnf.OpenAIToken, costSvc)
+
+			var pricingSource ingestion.PricingSource
+			switch cnf.Pricing.Source {
+			case "", ingestion.PricingSourceRemote:
+				pricingSource = ingestion.NewRemoteSource()
+			case ingestion.PricingSourceBundle:
+				pricingSource, err = ingestion.NewBundleSource(cnf.Pricing.BundlePath)
+				if err != nil {
+					logger.Error("failed to load pricing bundle", zap.String("path", cnf.Pricing.BundlePath), zap.Error(err))
+					return err
+				}
+			default:
+				return fmt.Errorf("unknown pricing source %s", cnf.Pricing.Source)
+			}
+			logger.Info("pricing source", zap.String("source", pricingSource.Name()), zap.String("version", pricingSource.Version()))
+
+			catalog := ingestion.NewCatalogManager(logger, pricingSource, dataAgeRepo, repo.NewCatalogVersionRepo(db))
+			catalog.RegisterView(ingestion.CatalogViewEC2InstanceTypes, ec2InstanceRepo)
+			catalog.RegisterView(ingestion.CatalogViewEBSVolumeTypes, ebsVolumeRepo)
+			catalog.RegisterView(ingestion.CatalogViewRDSDBInstances, rdsInstanceRepo)
+			catalog.RegisterView(ingestion.CatalogViewRDSDBStorages, rdsStorageRepo)
+			catalog.RegisterView(ingestion.CatalogViewRDSProducts, rdsRepo)
+			catalog.RegisterView(ingestion.CatalogViewGCPComputeMachineTypes, computeMachineTypeRepo)
+			catalog.RegisterView(ingestion.CatalogViewGCPComputeDiskTypes, computeDiskTypeRepo)
+			catalog.RegisterView(ingestion.CatalogViewGCPComputeSKUs, computeSKURepo)
+			catalog.RegisterView(ingestion.CatalogViewAzureVMSKUs, azureVMSKURepo)
+			catalog.RegisterView(ingestion.CatalogViewAzureManagedDiskTypes, azureManagedDiskTypeRepo)
+			catalog.RegisterView(ingestion.CatalogViewElastiCacheNodeTypes, elastiCacheNodeTypeRepo)
+			catalog.RegisterView(ingestion.CatalogViewLambdaPrices, lambdaPriceRepo)
+			catalog.RegisterView(ingestion.CatalogViewDynamoDBPrices, dynamoDBPriceRepo)
+			catalog.RegisterView(ingestion.CatalogViewAWSIdleResourcePrices, awsIdleResourcePriceRepo)
+			catalog.RegisterView(ingestion.CatalogViewAWSCommitmentPrices, awsCommitmentPriceRepo)
+
+			ingestionSvc := ingestion.New(logger, db, ec2InstanceRepo, rdsRepo, rdsInstanceRepo, rdsStorageRepo, ebsVolumeRepo, elastiCacheNodeTypeRepo, lambdaPriceRepo, dynamoDBPriceRepo, awsIdleResourcePriceRepo, awsCommitmentPriceRepo, dataAgeRepo, catalog)
 
 			gcpCredentials := map[string]string{
 				"type":         "service_account",
@@ -102,10 +147,17 @@ func Command() *cobra.Command {
 				"private_key":  GCPPrivateKey,
 				"client_email": GCPClientEmail,
 			}
-			gcpIngestionSvc, err := ingestion.NewGcpService(ctx, logger, dataAgeRepo, computeMachineTypeRepo, computeDiskTypeRepo, computeSKURepo, db, gcpCredentials, GCPProjectID)
+			gcpIngestionSvc, err := ingestion.NewGcpService(ctx, logger, dataAgeRepo, computeMachineTypeRepo, computeDiskTypeRepo, computeSKURepo, db, catalog, gcpCredentials, GCPProjectID)
 			go ingestionSvc.Start(ctx)
 			go gcpIngestionSvc.Start(ctx)
 
+			if AzureSubscriptionID != "" {
+				azureIngestionSvc := ingestion.NewAzureService(logger, dataAgeRepo, azureVMSKURepo, azureManagedDiskTypeRepo, db, catalog, cred, AzureSubscriptionID)
+				go azureIngestionSvc.Start(ctx)
+			} else {
+				logger.Warn("AZURE_SUBSCRIPTION_ID is not set, azure ingestion is disabled")
+			}
+
 			blobWorkerPool := pond.New(50, 1000000,
 				pond.Strategy(pond.Eager()),
 				pond.Context(ctx),
@@ -113,8 +165,9 @@ func Command() *cobra.Command {
 				pond.MinWorkers(1))
 
 			limitSvc := limit.NewLimitService(logger, userRepo, orgRepo, usageV2Repo)
+			lifecycleSvc := lifecycle.NewLifecycleService(logger, recommendationRepo)
 
-			grpcServer := grpc_server.NewServer(logger, cnf, blobClient, blobWorkerPool, usageV2Repo, recomSvc, limitSvc)
+			grpcServer := grpc_server.NewServer(logger, cnf, blobClient, blobWorkerPool, usageV2Repo, recomSvc, limitSvc, lifecycleSvc)
 			err = grpc_server.StartGrpcServer(grpcServer, cnf.Grpc.Address, AuthGRPCURI)
 			if err != nil {
 				return err
@@ -124,10 +177,12 @@ func Command() *cobra.Command {
 				ctx,
 				logger,
 				cnf.Http.Address,
-				api.New(cnf, logger, blobClient, blobWorkerPool, costSvc, recomSvc, ingestionSvc, limitSvc, usageV1Repo, usageV2Repo, userRepo, orgRepo),
+				api.New(cnf, logger, blobClient, blobWorkerPool, costSvc, recomSvc, ingestionSvc, limitSvc, lifecycleSvc, usageV1Repo, usageV2Repo, userRepo, orgRepo),
 			)
 		},
 	}
 
+	cmd.AddCommand(bundleCommand())
+
 	return cmd
 }
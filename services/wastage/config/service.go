@@ -8,6 +8,13 @@ type AzBlobConfig struct {
 	Container  string `json:"container" koanf:"container"`
 }
 
+// PricingConfig selects where the ingestion reads the price lists from, "remote" downloads them from the
+// providers and "bundle" reads them from the offline bundle at BundlePath.
+type PricingConfig struct {
+	Source     string `json:"source" koanf:"source"`
+	BundlePath string `json:"bundlePath" koanf:"bundle_path"`
+}
+
 type WastageConfig struct {
 	Postgres    koanf.Postgres     `json:"postgres,omitempty" koanf:"postgres"`
 	Http        koanf.HttpServer   `json:"http,omitempty" koanf:"http"`
@@ -15,4 +22,5 @@ type WastageConfig struct {
 	Pennywise   koanf.KaytuService `json:"pennywise" koanf:"pennywise"`
 	OpenAIToken string             `json:"openAIToken" koanf:"openai_token"`
 	AzBlob      AzBlobConfig       `json:"azBlob" koanf:"az_blob"`
+	Pricing     PricingConfig      `json:"pricing" koanf:"pricing"`
 }
@@ -0,0 +1,130 @@
+package model
+
+import (
+	"gorm.io/gorm"
+	"strconv"
+	"strings"
+)
+
+const (
+	AWSCommitmentTypeReservedInstance        = "ReservedInstance"
+	AWSCommitmentTypeComputeSavingsPlans     = "ComputeSavingsPlans"
+	AWSCommitmentTypeEC2InstanceSavingsPlans = "EC2InstanceSavingsPlans"
+)
+
+// AWSCommitmentPrice is the price of an instance type under a reserved instance or savings plans offering.
+// Reserved instances have an upfront fee and an hourly rate, savings plans only have the discounted hourly rate
+// of the instance type, the commitment itself is bought in dollars per hour.
+type AWSCommitmentPrice struct {
+	gorm.Model
+
+	CommitmentType      string `gorm:"index"`
+	ServiceCode         string `gorm:"index"` // AmazonEC2 or AmazonRDS
+	RegionCode          string `gorm:"index"`
+	InstanceType        string `gorm:"index;type:citext"`
+	InstanceFamily      string `gorm:"index"` // e.g. m5 or db.r6g
+	Operation           string `gorm:"index"` // the usage operation of ec2 instances, e.g. RunInstances:0002
+	DatabaseEngine      string `gorm:"index;type:citext"`
+	DatabaseEdition     string `gorm:"index;type:citext"`
+	DeploymentOption    string `gorm:"index"`
+	LeaseContractLength string `gorm:"index"` // 1yr or 3yr
+	PurchaseOption      string `gorm:"index"` // No Upfront, Partial Upfront or All Upfront
+	OfferingClass       string `gorm:"index"` // standard or convertible, empty for savings plans
+
+	HourlyRate float64
+	UpfrontFee float64
+
+	SKU           string
+	TermType      string
+	ProductFamily string
+	Tenancy       string
+	CapacityState string
+	PreInstalled  string
+	LocationType  string
+	Unit          string
+	PricePerUnit  float64
+}
+
+// PopulateFromMap reads a reserved instance row of the ec2 or rds price list, the upfront fee and the hourly rate
+// of the same offering are on separate rows and are merged with Merge.
+func (p *AWSCommitmentPrice) PopulateFromMap(columns map[string]int, row []string) {
+	for col, index := range columns {
+		switch col {
+		case "SKU":
+			p.SKU = row[index]
+		case "TermType":
+			p.TermType = row[index]
+		case "Product Family":
+			p.ProductFamily = row[index]
+		case "serviceCode":
+			p.ServiceCode = row[index]
+		case "Region Code":
+			p.RegionCode = row[index]
+		case "Location Type":
+			p.LocationType = row[index]
+		case "Instance Type":
+			p.InstanceType = row[index]
+		case "operation":
+			p.Operation = row[index]
+		case "Database Engine":
+			p.DatabaseEngine = row[index]
+		case "Database Edition":
+			p.DatabaseEdition = row[index]
+		case "Deployment Option":
+			p.DeploymentOption = row[index]
+		case "Tenancy":
+			p.Tenancy = row[index]
+		case "CapacityStatus":
+			p.CapacityState = row[index]
+		case "Pre Installed S/W":
+			p.PreInstalled = row[index]
+		case "LeaseContractLength":
+			p.LeaseContractLength = row[index]
+		case "PurchaseOption":
+			p.PurchaseOption = row[index]
+		case "OfferingClass":
+			p.OfferingClass = row[index]
+		case "Unit":
+			p.Unit = row[index]
+		case "PricePerUnit":
+			p.PricePerUnit, _ = strconv.ParseFloat(row[index], 64)
+		}
+	}
+
+	p.CommitmentType = AWSCommitmentTypeReservedInstance
+	// the Instance Family column is the category, e.g. General purpose, the family is the prefix of the type
+	if i := strings.LastIndex(p.InstanceType, "."); i > 0 {
+		p.InstanceFamily = p.InstanceType[:i]
+	}
+	if strings.EqualFold(p.Unit, "Quantity") {
+		p.UpfrontFee = p.PricePerUnit
+	} else {
+		p.HourlyRate = p.PricePerUnit
+	}
+}
+
+func (p *AWSCommitmentPrice) DoIngest() bool {
+	if p.TermType != "Reserved" || p.LocationType == "AWS Outposts" || p.InstanceType == "" || p.RegionCode == "" {
+		return false
+	}
+	switch p.ServiceCode {
+	case "AmazonEC2":
+		// only the default tenancy without pre installed software, the same rows the on demand prices are taken from
+		return (p.ProductFamily == "Compute Instance" || p.ProductFamily == "Compute Instance (bare metal)") &&
+			p.Tenancy == "Shared" && p.CapacityState == "Used" && p.PreInstalled == "NA"
+	case "AmazonRDS":
+		return p.ProductFamily == "Database Instance"
+	}
+	return false
+}
+
+// OfferingKey identifies the reserved instance offering a row belongs to.
+func (p *AWSCommitmentPrice) OfferingKey() string {
+	return strings.Join([]string{p.SKU, p.LeaseContractLength, p.PurchaseOption, p.OfferingClass}, "|")
+}
+
+// Merge adds the upfront fee or the hourly rate of another row of the same offering.
+func (p *AWSCommitmentPrice) Merge(o AWSCommitmentPrice) {
+	p.UpfrontFee += o.UpfrontFee
+	p.HourlyRate += o.HourlyRate
+}
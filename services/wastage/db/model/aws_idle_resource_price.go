@@ -0,0 +1,10 @@
+package model
+
+import "gorm.io/gorm"
+
+// AWSIdleResourcePrice holds the prices of the network resources and snapshots that are billed while idle: public IPv4 addresses, NAT gateways, load balancers and EBS snapshots.
+type AWSIdleResourcePrice struct {
+	gorm.Model
+
+	AWSPriceListItem
+}
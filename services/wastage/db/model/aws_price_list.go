@@ -0,0 +1,72 @@
+package model
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// AWSPriceListItem holds the columns shared by the usage based price lists of AWS, where a price is identified
+// by its usage type rather than by a product with hardware attributes.
+type AWSPriceListItem struct {
+	SKU              string
+	TermType         string `gorm:"index"`
+	ProductFamily    string `gorm:"index"`
+	PriceGroup       string `gorm:"index"`
+	UsageType        string `gorm:"index"` // without the region prefix, e.g. NatGateway-Hours
+	Operation        string
+	RegionCode       string `gorm:"index"`
+	LocationType     string
+	Unit             string
+	StartingRange    float64
+	PricePerUnit     float64
+	PriceDescription string
+}
+
+func (p *AWSPriceListItem) PopulateFromMap(columns map[string]int, row []string) {
+	for col, index := range columns {
+		switch col {
+		case "SKU":
+			p.SKU = row[index]
+		case "TermType":
+			p.TermType = row[index]
+		case "Product Family":
+			p.ProductFamily = row[index]
+		case "Group":
+			p.PriceGroup = row[index]
+		case "usageType":
+			p.UsageType = trimUsageTypeRegion(row[index])
+		case "operation":
+			p.Operation = row[index]
+		case "Region Code":
+			p.RegionCode = row[index]
+		case "Location Type":
+			p.LocationType = row[index]
+		case "Unit":
+			p.Unit = row[index]
+		case "StartingRange":
+			p.StartingRange, _ = strconv.ParseFloat(row[index], 64)
+		case "PricePerUnit":
+			p.PricePerUnit, _ = strconv.ParseFloat(row[index], 64)
+		case "PriceDescription":
+			p.PriceDescription = row[index]
+		}
+	}
+}
+
+func (p *AWSPriceListItem) DoIngest() bool {
+	return p.TermType == "OnDemand" && p.LocationType != "AWS Outposts" && p.RegionCode != ""
+}
+
+// trimUsageTypeRegion removes the region prefix of a usage type, USW2-NatGateway-Hours becomes NatGateway-Hours.
+// Usage types of us-east-1 have no prefix and are returned as they are.
+func trimUsageTypeRegion(usageType string) string {
+	prefix, rest, ok := strings.Cut(usageType, "-")
+	if !ok || len(prefix) < 3 || len(prefix) > 5 || strings.ToUpper(prefix) != prefix {
+		return usageType
+	}
+	if !unicode.IsDigit(rune(prefix[len(prefix)-1])) {
+		return usageType
+	}
+	return rest
+}
@@ -0,0 +1,19 @@
+package model
+
+import "gorm.io/gorm"
+
+type AzureManagedDiskType struct {
+	gorm.Model
+
+	// Basic fields
+	Name   string `gorm:"index"`
+	Tier   string `gorm:"index"`
+	Region string `gorm:"index"`
+
+	SizeGb             int64
+	BaseIops           int64
+	BaseThroughputMBps int64
+
+	// UnitPrice is the monthly retail price in USD
+	UnitPrice float64
+}
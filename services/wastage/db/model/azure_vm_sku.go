@@ -0,0 +1,50 @@
+package model
+
+import (
+	"gorm.io/gorm"
+	"strconv"
+	"strings"
+)
+
+type AzureVMSKU struct {
+	gorm.Model
+
+	// Basic fields
+	Name            string `gorm:"index"`
+	Family          string `gorm:"index"`
+	Region          string `gorm:"index"`
+	OperatingSystem string `gorm:"index"`
+	Spot            bool   `gorm:"index"`
+
+	VCpu                  int64
+	MemoryGB              float64
+	MaxDataDiskCount      int64
+	PremiumIO             bool
+	AcceleratedNetworking bool
+	CpuArchitecture       string
+
+	// UnitPrice is the hourly retail price in USD
+	UnitPrice float64
+}
+
+// PopulateFromCapabilities fills the hardware fields of the SKU from the capability name/value pairs
+// returned by the Microsoft.Compute resource SKUs API.
+func (p *AzureVMSKU) PopulateFromCapabilities(family string, capabilities map[string]string) {
+	p.Family = family
+	p.VCpu = parseInt64(capabilities["vCPUs"])
+	p.MemoryGB = parseFloat64(capabilities["MemoryGB"])
+	p.MaxDataDiskCount = parseInt64(capabilities["MaxDataDiskCount"])
+	p.PremiumIO = strings.EqualFold(capabilities["PremiumIO"], "true")
+	p.AcceleratedNetworking = strings.EqualFold(capabilities["AcceleratedNetworkingEnabled"], "true")
+	p.CpuArchitecture = capabilities["CpuArchitectureType"]
+}
+
+func parseInt64(v string) int64 {
+	i, _ := strconv.ParseInt(v, 10, 64)
+	return i
+}
+
+func parseFloat64(v string) float64 {
+	f, _ := strconv.ParseFloat(v, 64)
+	return f
+}
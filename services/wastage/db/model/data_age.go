@@ -5,4 +5,21 @@ import "time"
 type DataAge struct {
 	DataType  string `gorm:"primaryKey"`
 	UpdatedAt time.Time
+
+	// Version is the catalog version the views of this data type currently point to
+	Version string
+	// Source is the pricing source the current version was ingested from
+	Source string
+	// Pinned stops the ingestion from replacing the current version
+	Pinned bool
+}
+
+// CatalogVersion records the tables an ingested catalog version was written to, so views can be pointed back to them.
+type CatalogVersion struct {
+	ID        uint   `gorm:"primaryKey"`
+	DataType  string `gorm:"index"`
+	Version   string `gorm:"index"`
+	Source    string
+	Tables    map[string]string `gorm:"serializer:json"`
+	CreatedAt time.Time
 }
@@ -0,0 +1,10 @@
+package model
+
+import "gorm.io/gorm"
+
+// DynamoDBPrice holds the DynamoDB capacity prices, the usage type tells provisioned capacity (ReadCapacityUnit-Hrs) and on demand requests (ReadRequestUnits) apart.
+type DynamoDBPrice struct {
+	gorm.Model
+
+	AWSPriceListItem
+}
@@ -0,0 +1,94 @@
+package model
+
+import (
+	"gorm.io/gorm"
+	"strconv"
+	"strings"
+)
+
+type ElastiCacheNodeType struct {
+	gorm.Model
+
+	// Basic fields
+
+	InstanceType string  `gorm:"index;type:citext"`
+	CacheEngine  string  `gorm:"index;type:citext"`
+	RegionCode   string  `gorm:"index"`
+	VCpu         float64 `gorm:"index"`
+	MemoryGb     float64 `gorm:"index"`
+
+	PricePerUnit float64 `gorm:"index:price_idx,sort:asc"`
+
+	SKU                string
+	TermType           string
+	ProductFamily      string
+	Location           string
+	LocationType       string
+	CurrentGeneration  string
+	InstanceFamily     string
+	Memory             string
+	NetworkPerformance string
+	UsageType          string
+	Unit               string
+	PricePerUnitStr    string
+	Currency           string
+}
+
+func (p *ElastiCacheNodeType) PopulateFromMap(columns map[string]int, row []string) {
+	for col, index := range columns {
+		switch col {
+		case "SKU":
+			p.SKU = row[index]
+		case "TermType":
+			p.TermType = row[index]
+		case "Product Family":
+			p.ProductFamily = row[index]
+		case "Location":
+			p.Location = row[index]
+		case "Location Type":
+			p.LocationType = row[index]
+		case "Region Code":
+			p.RegionCode = row[index]
+		case "Instance Type":
+			p.InstanceType = row[index]
+		case "Cache Engine":
+			p.CacheEngine = row[index]
+		case "Current Generation":
+			p.CurrentGeneration = row[index]
+		case "Instance Family":
+			p.InstanceFamily = row[index]
+		case "vCPU":
+			i, err := strconv.ParseFloat(row[index], 64)
+			if err == nil {
+				p.VCpu = i
+			}
+		case "Memory":
+			p.Memory = row[index]
+			for _, part := range strings.Split(row[index], " ") {
+				i, err := strconv.ParseFloat(part, 64)
+				if err == nil {
+					p.MemoryGb = max(p.MemoryGb, i)
+				}
+			}
+		case "Network Performance":
+			p.NetworkPerformance = row[index]
+		case "usageType":
+			p.UsageType = row[index]
+		case "Unit":
+			p.Unit = row[index]
+		case "PricePerUnit":
+			p.PricePerUnit, _ = strconv.ParseFloat(row[index], 64)
+			p.PricePerUnitStr = row[index]
+		case "Currency":
+			p.Currency = row[index]
+		}
+	}
+}
+
+// DoIngest keeps the on demand prices of the cache nodes, serverless caches and reserved nodes are not rightsized.
+func (p *ElastiCacheNodeType) DoIngest() bool {
+	return p.ProductFamily == "Cache Instance" &&
+		p.TermType == "OnDemand" &&
+		p.LocationType != "AWS Outposts" &&
+		p.PricePerUnit > 0
+}
@@ -0,0 +1,10 @@
+package model
+
+import "gorm.io/gorm"
+
+// LambdaPrice holds the Lambda duration and request prices, PriceGroup tells them apart (AWS-Lambda-Duration, AWS-Lambda-Requests-ARM, ...).
+type LambdaPrice struct {
+	gorm.Model
+
+	AWSPriceListItem
+}
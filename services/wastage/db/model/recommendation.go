@@ -0,0 +1,68 @@
+package model
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+type RecommendationState string
+
+const (
+	RecommendationStateOpen      RecommendationState = "open"
+	RecommendationStateAccepted  RecommendationState = "accepted"
+	RecommendationStateDismissed RecommendationState = "dismissed"
+	RecommendationStateApplied   RecommendationState = "applied"
+	RecommendationStateVerified  RecommendationState = "verified"
+)
+
+// RecommendationTransitions are the states a recommendation can move to from every state, applied is usually
+// detected from a later request of the same resource and verified is terminal.
+var RecommendationTransitions = map[RecommendationState][]RecommendationState{
+	RecommendationStateOpen:      {RecommendationStateAccepted, RecommendationStateDismissed, RecommendationStateApplied},
+	RecommendationStateAccepted:  {RecommendationStateOpen, RecommendationStateDismissed, RecommendationStateApplied},
+	RecommendationStateDismissed: {RecommendationStateOpen, RecommendationStateApplied},
+	RecommendationStateApplied:   {RecommendationStateOpen, RecommendationStateVerified},
+	RecommendationStateVerified:  {},
+}
+
+// Recommendation is a rightsizing recommendation of a resource that is tracked from the request it was made in,
+// until the change is applied and its savings are verified.
+type Recommendation struct {
+	gorm.Model
+
+	ApiEndpoint string `gorm:"index:idx_recommendation_resource"`
+	ResourceID  string `gorm:"index:idx_recommendation_resource"`
+	AccountID   string `gorm:"index"`
+	OrgEmail    string `gorm:"index"`
+	Auth0UserId string `gorm:"index"`
+
+	State          RecommendationState `gorm:"index"`
+	StateChangedAt time.Time
+	DismissReason  *string
+
+	// CurrentType is the instance type or size of the resource when the recommendation was made
+	CurrentType      string
+	CurrentCost      float64
+	RecommendedType  string
+	RecommendedCost  float64
+	PotentialSavings float64
+
+	AppliedType *string
+	AppliedAt   *time.Time
+	// CostAfter is the cost of the resource in the latest request after the change
+	CostAfter       *float64
+	RealizedSavings *float64
+	VerifiedAt      *time.Time
+
+	// LastUsageID is the usage of the latest request of the resource
+	LastUsageID uint
+}
+
+func (r Recommendation) CanTransition(to RecommendationState) bool {
+	for _, s := range RecommendationTransitions[r.State] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
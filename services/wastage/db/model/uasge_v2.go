@@ -58,4 +58,13 @@ type Statistics struct {
 	GCPComputeInstanceCurrentCost     float64 `json:"gcpComputeInstanceCurrentCost"`
 	GCPComputeInstanceRecommendedCost float64 `json:"gcpComputeInstanceRecommendedCost"`
 	GCPComputeInstanceSavings         float64 `json:"gcpComputeInstanceSavings"`
+
+	AzureVMCurrentCost     float64 `json:"azureVMCurrentCost"`
+	AzureVMRecommendedCost float64 `json:"azureVMRecommendedCost"`
+	AzureVMSavings         float64 `json:"azureVMSavings"`
+
+	AzureManagedDiskCurrentCost     float64 `json:"azureManagedDiskCurrentCost"`
+	AzureManagedDiskRecommendedCost float64 `json:"azureManagedDiskRecommendedCost"`
+	AzureManagedDiskSavings         float64 `json:"azureManagedDiskSavings"`
+	AzureManagedDiskCount           int     `json:"azureManagedDiskCount"`
 }
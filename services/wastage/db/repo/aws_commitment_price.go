@@ -0,0 +1,178 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"github.com/opengovern/opengovernance/services/wastage/db/connector"
+	"github.com/opengovern/opengovernance/services/wastage/db/model"
+	"github.com/sony/sonyflake"
+	"gorm.io/gorm"
+	"slices"
+	"time"
+)
+
+type AWSCommitmentPriceRepo interface {
+	Create(tableName string, tx *gorm.DB, m *model.AWSCommitmentPrice) error
+	Delete(tableName string, id string) error
+	List() ([]model.AWSCommitmentPrice, error)
+	ListByInstanceType(ctx context.Context, commitmentType, region, instanceType string, pref map[string]any) ([]model.AWSCommitmentPrice, error)
+	CreateNewTable() (string, error)
+	MoveViewTransaction(tableName string) error
+	RemoveOldTables(currentTableName string, keep ...string) error
+}
+
+type AWSCommitmentPriceRepoImpl struct {
+	db *connector.Database
+
+	viewName string
+}
+
+func NewAWSCommitmentPriceRepo(db *connector.Database) AWSCommitmentPriceRepo {
+	stmt := &gorm.Statement{DB: db.Conn()}
+	stmt.Parse(&model.AWSCommitmentPrice{})
+
+	return &AWSCommitmentPriceRepoImpl{
+		db: db,
+
+		viewName: stmt.Schema.Table,
+	}
+}
+
+func (r *AWSCommitmentPriceRepoImpl) Create(tableName string, tx *gorm.DB, m *model.AWSCommitmentPrice) error {
+	if tx == nil {
+		tx = r.db.Conn()
+	}
+	tx = tx.Table(tableName)
+	return tx.Create(&m).Error
+}
+
+func (r *AWSCommitmentPriceRepoImpl) Delete(tableName string, id string) error {
+	return r.db.Conn().Table(tableName).Where("id=?", id).Delete(&model.AWSCommitmentPrice{}).Error
+}
+
+func (r *AWSCommitmentPriceRepoImpl) List() ([]model.AWSCommitmentPrice, error) {
+	var m []model.AWSCommitmentPrice
+	tx := r.db.Conn().Table(r.viewName).Find(&m)
+	return m, tx.Error
+}
+
+// ListByInstanceType returns the offerings of every term and payment option of an instance type, pref filters
+// the platform columns, e.g. operation for ec2 or database_engine for rds.
+func (r *AWSCommitmentPriceRepoImpl) ListByInstanceType(ctx context.Context, commitmentType, region, instanceType string, pref map[string]any) ([]model.AWSCommitmentPrice, error) {
+	var ms []model.AWSCommitmentPrice
+	tx := r.db.Conn().Table(r.viewName).WithContext(ctx).
+		Where("commitment_type = ?", commitmentType).
+		Where("region_code = ?", region).
+		Where("instance_type = ?", instanceType)
+	for k, v := range pref {
+		tx = tx.Where(k, v)
+	}
+	tx = tx.Find(&ms)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return ms, nil
+}
+
+func (r *AWSCommitmentPriceRepoImpl) CreateNewTable() (string, error) {
+	sf := sonyflake.NewSonyflake(sonyflake.Settings{})
+	var commitmentPriceTable string
+	for {
+		id, err := sf.NextID()
+		if err != nil {
+			return "", err
+		}
+
+		commitmentPriceTable = fmt.Sprintf("%s_%s_%d",
+			r.viewName,
+			time.Now().Format("2006_01_02"),
+			id,
+		)
+		var c int32
+		tx := r.db.Conn().Raw(fmt.Sprintf(`
+		SELECT count(*)
+		FROM information_schema.tables
+		WHERE table_schema = current_schema
+		AND table_name = '%s';
+	`, commitmentPriceTable)).First(&c)
+		if tx.Error != nil {
+			return "", err
+		}
+		if c == 0 {
+			break
+		}
+	}
+
+	err := r.db.Conn().Table(commitmentPriceTable).AutoMigrate(&model.AWSCommitmentPrice{})
+	if err != nil {
+		return "", err
+	}
+	return commitmentPriceTable, nil
+}
+
+func (r *AWSCommitmentPriceRepoImpl) MoveViewTransaction(tableName string) error {
+	tx := r.db.Conn().Begin()
+	var err error
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	dropViewQuery := fmt.Sprintf("DROP VIEW IF EXISTS %s", r.viewName)
+	tx = tx.Exec(dropViewQuery)
+	err = tx.Error
+	if err != nil {
+		return err
+	}
+
+	createViewQuery := fmt.Sprintf(`
+  CREATE OR REPLACE VIEW %s AS
+  SELECT *
+  FROM %s;
+`, r.viewName, tableName)
+
+	tx = tx.Exec(createViewQuery)
+	err = tx.Error
+	if err != nil {
+		return err
+	}
+
+	tx = tx.Commit()
+	err = tx.Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *AWSCommitmentPriceRepoImpl) getOldTables(currentTableName string) ([]string, error) {
+	query := fmt.Sprintf(`
+		SELECT table_name
+		FROM information_schema.tables
+		WHERE table_schema = current_schema
+		AND table_name LIKE '%s_%%' AND table_name <> '%s';
+	`, r.viewName, currentTableName)
+
+	var tableNames []string
+	tx := r.db.Conn().Raw(query).Find(&tableNames)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return tableNames, nil
+}
+
+func (r *AWSCommitmentPriceRepoImpl) RemoveOldTables(currentTableName string, keep ...string) error {
+	tableNames, err := r.getOldTables(currentTableName)
+	if err != nil {
+		return err
+	}
+	for _, tn := range tableNames {
+		if slices.Contains(keep, tn) {
+			continue
+		}
+		err = r.db.Conn().Migrator().DropTable(tn)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
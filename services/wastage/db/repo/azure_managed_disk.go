@@ -0,0 +1,212 @@
+package repo
+
+import (
+	"errors"
+	"fmt"
+	"github.com/opengovern/opengovernance/services/wastage/db/connector"
+	"github.com/opengovern/opengovernance/services/wastage/db/model"
+	"github.com/sony/sonyflake"
+	"gorm.io/gorm"
+	"slices"
+	"time"
+)
+
+type AzureManagedDiskTypeRepo interface {
+	Create(tableName string, tx *gorm.DB, m *model.AzureManagedDiskType) error
+	Delete(tableName string, id string) error
+	List() ([]model.AzureManagedDiskType, error)
+	Get(name, region string) (*model.AzureManagedDiskType, error)
+	GetByTierAndSize(tier, region string, sizeGb int64) (*model.AzureManagedDiskType, error)
+	GetCheapest(sizeGb int64, iops, throughputMBps float64, pref map[string]interface{}) (*model.AzureManagedDiskType, error)
+	CreateNewTable() (string, error)
+	MoveViewTransaction(tableName string) error
+	RemoveOldTables(currentTableName string, keep ...string) error
+}
+
+type AzureManagedDiskTypeRepoImpl struct {
+	db *connector.Database
+
+	viewName string
+}
+
+func NewAzureManagedDiskTypeRepo(db *connector.Database) AzureManagedDiskTypeRepo {
+	stmt := &gorm.Statement{DB: db.Conn()}
+	stmt.Parse(&model.AzureManagedDiskType{})
+
+	return &AzureManagedDiskTypeRepoImpl{
+		db: db,
+
+		viewName: stmt.Schema.Table,
+	}
+}
+
+func (r *AzureManagedDiskTypeRepoImpl) Create(tableName string, tx *gorm.DB, m *model.AzureManagedDiskType) error {
+	if tx == nil {
+		tx = r.db.Conn()
+	}
+	tx = tx.Table(tableName)
+	return tx.Create(&m).Error
+}
+
+func (r *AzureManagedDiskTypeRepoImpl) Delete(tableName string, name string) error {
+	return r.db.Conn().Table(tableName).Where("name=?", name).Delete(&model.AzureManagedDiskType{}).Error
+}
+
+func (r *AzureManagedDiskTypeRepoImpl) List() ([]model.AzureManagedDiskType, error) {
+	var m []model.AzureManagedDiskType
+	tx := r.db.Conn().Table(r.viewName).Find(&m)
+	return m, tx.Error
+}
+
+func (r *AzureManagedDiskTypeRepoImpl) Get(name, region string) (*model.AzureManagedDiskType, error) {
+	var m model.AzureManagedDiskType
+	tx := r.db.Conn().Table(r.viewName).Where("name = ?", name).Where("region = ?", region).First(&m)
+	if tx.Error != nil {
+		if errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, tx.Error
+	}
+	return &m, nil
+}
+
+// GetByTierAndSize returns the smallest disk size of the tier that fits sizeGb, which is the size Azure bills for.
+func (r *AzureManagedDiskTypeRepoImpl) GetByTierAndSize(tier, region string, sizeGb int64) (*model.AzureManagedDiskType, error) {
+	var m model.AzureManagedDiskType
+	tx := r.db.Conn().Table(r.viewName).
+		Where("tier = ?", tier).
+		Where("region = ?", region).
+		Where("size_gb >= ?", sizeGb).
+		Order("size_gb ASC").
+		First(&m)
+	if tx.Error != nil {
+		if errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, tx.Error
+	}
+	return &m, nil
+}
+
+func (r *AzureManagedDiskTypeRepoImpl) GetCheapest(sizeGb int64, iops, throughputMBps float64, pref map[string]interface{}) (*model.AzureManagedDiskType, error) {
+	var m model.AzureManagedDiskType
+	tx := r.db.Conn().Table(r.viewName).
+		Where("size_gb >= ?", sizeGb).
+		Where("base_iops >= ?", iops).
+		Where("base_throughput_m_bps >= ?", throughputMBps).
+		Where("unit_price != 0")
+	for k, v := range pref {
+		tx = tx.Where(k, v)
+	}
+	tx = tx.Order("unit_price ASC").First(&m)
+	if tx.Error != nil {
+		if errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, tx.Error
+	}
+	return &m, nil
+}
+
+func (r *AzureManagedDiskTypeRepoImpl) CreateNewTable() (string, error) {
+	sf := sonyflake.NewSonyflake(sonyflake.Settings{})
+	var azureManagedDiskTypeTable string
+	for {
+		id, err := sf.NextID()
+		if err != nil {
+			return "", err
+		}
+
+		azureManagedDiskTypeTable = fmt.Sprintf("%s_%s_%d",
+			r.viewName,
+			time.Now().Format("2006_01_02"),
+			id,
+		)
+		var c int32
+		tx := r.db.Conn().Raw(fmt.Sprintf(`
+		SELECT count(*)
+		FROM information_schema.tables
+		WHERE table_schema = current_schema
+		AND table_name = '%s';
+	`, azureManagedDiskTypeTable)).First(&c)
+		if tx.Error != nil {
+			return "", err
+		}
+		if c == 0 {
+			break
+		}
+	}
+
+	err := r.db.Conn().Table(azureManagedDiskTypeTable).AutoMigrate(&model.AzureManagedDiskType{})
+	if err != nil {
+		return "", err
+	}
+	return azureManagedDiskTypeTable, nil
+}
+
+func (r *AzureManagedDiskTypeRepoImpl) MoveViewTransaction(tableName string) error {
+	tx := r.db.Conn().Begin()
+	var err error
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	dropViewQuery := fmt.Sprintf("DROP VIEW IF EXISTS %s", r.viewName)
+	tx = tx.Exec(dropViewQuery)
+	err = tx.Error
+	if err != nil {
+		return err
+	}
+
+	createViewQuery := fmt.Sprintf(`
+  CREATE OR REPLACE VIEW %s AS
+  SELECT *
+  FROM %s;
+`, r.viewName, tableName)
+
+	tx = tx.Exec(createViewQuery)
+	err = tx.Error
+	if err != nil {
+		return err
+	}
+
+	tx = tx.Commit()
+	err = tx.Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *AzureManagedDiskTypeRepoImpl) getOldTables(currentTableName string) ([]string, error) {
+	query := fmt.Sprintf(`
+		SELECT table_name
+		FROM information_schema.tables
+		WHERE table_schema = current_schema
+		AND table_name LIKE '%s_%%' AND table_name <> '%s';
+	`, r.viewName, currentTableName)
+
+	var tableNames []string
+	tx := r.db.Conn().Raw(query).Find(&tableNames)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return tableNames, nil
+}
+
+func (r *AzureManagedDiskTypeRepoImpl) RemoveOldTables(currentTableName string, keep ...string) error {
+	tableNames, err := r.getOldTables(currentTableName)
+	if err != nil {
+		return err
+	}
+	for _, tn := range tableNames {
+		if slices.Contains(keep, tn) {
+			continue
+		}
+		err = r.db.Conn().Migrator().DropTable(tn)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,216 @@
+package repo
+
+import (
+	"errors"
+	"fmt"
+	"github.com/opengovern/opengovernance/services/wastage/db/connector"
+	"github.com/opengovern/opengovernance/services/wastage/db/model"
+	"github.com/sony/sonyflake"
+	"gorm.io/gorm"
+	"slices"
+	"time"
+)
+
+type AzureVMSKURepo interface {
+	Create(tableName string, tx *gorm.DB, m *model.AzureVMSKU) error
+	Delete(tableName string, id string) error
+	List() ([]model.AzureVMSKU, error)
+	Get(name, region, operatingSystem string, spot bool) (*model.AzureVMSKU, error)
+	GetCheapestByCoreAndMemory(cpu, memory float64, pref map[string]interface{}) (*model.AzureVMSKU, error)
+	ListByCoreAndMemory(cpu, memory float64, pref map[string]interface{}) ([]model.AzureVMSKU, error)
+	CreateNewTable() (string, error)
+	MoveViewTransaction(tableName string) error
+	RemoveOldTables(currentTableName string, keep ...string) error
+}
+
+type AzureVMSKURepoImpl struct {
+	db *connector.Database
+
+	viewName string
+}
+
+func NewAzureVMSKURepo(db *connector.Database) AzureVMSKURepo {
+	stmt := &gorm.Statement{DB: db.Conn()}
+	stmt.Parse(&model.AzureVMSKU{})
+
+	return &AzureVMSKURepoImpl{
+		db: db,
+
+		viewName: stmt.Schema.Table,
+	}
+}
+
+func (r *AzureVMSKURepoImpl) Create(tableName string, tx *gorm.DB, m *model.AzureVMSKU) error {
+	if tx == nil {
+		tx = r.db.Conn()
+	}
+	tx = tx.Table(tableName)
+	return tx.Create(&m).Error
+}
+
+func (r *AzureVMSKURepoImpl) Delete(tableName string, name string) error {
+	return r.db.Conn().Table(tableName).Where("name=?", name).Delete(&model.AzureVMSKU{}).Error
+}
+
+func (r *AzureVMSKURepoImpl) List() ([]model.AzureVMSKU, error) {
+	var m []model.AzureVMSKU
+	tx := r.db.Conn().Table(r.viewName).Find(&m)
+	return m, tx.Error
+}
+
+func (r *AzureVMSKURepoImpl) Get(name, region, operatingSystem string, spot bool) (*model.AzureVMSKU, error) {
+	var m model.AzureVMSKU
+	tx := r.db.Conn().Table(r.viewName).
+		Where("LOWER(name) = LOWER(?)", name).
+		Where("region = ?", region).
+		Where("operating_system = ?", operatingSystem).
+		Where("spot = ?", spot).
+		Order("unit_price ASC").
+		First(&m)
+	if tx.Error != nil {
+		if errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, tx.Error
+	}
+	return &m, nil
+}
+
+func (r *AzureVMSKURepoImpl) GetCheapestByCoreAndMemory(cpu, memory float64, pref map[string]interface{}) (*model.AzureVMSKU, error) {
+	var m model.AzureVMSKU
+	tx := r.db.Conn().Table(r.viewName).
+		Where("v_cpu >= ?", cpu).
+		Where("memory_gb >= ?", memory).
+		Where("unit_price != 0")
+	for k, v := range pref {
+		tx = tx.Where(k, v)
+	}
+	tx = tx.Order("unit_price ASC").First(&m)
+	if tx.Error != nil {
+		if errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, tx.Error
+	}
+	return &m, nil
+}
+
+// ListByCoreAndMemory lists the SKUs with at least the given vCPUs and memory, cheapest first.
+func (r *AzureVMSKURepoImpl) ListByCoreAndMemory(cpu, memory float64, pref map[string]interface{}) ([]model.AzureVMSKU, error) {
+	var ms []model.AzureVMSKU
+	tx := r.db.Conn().Table(r.viewName).
+		Where("v_cpu >= ?", cpu).
+		Where("memory_gb >= ?", memory).
+		Where("unit_price != 0")
+	for k, v := range pref {
+		tx = tx.Where(k, v)
+	}
+	tx = tx.Order("unit_price ASC").Find(&ms)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return ms, nil
+}
+
+func (r *AzureVMSKURepoImpl) CreateNewTable() (string, error) {
+	sf := sonyflake.NewSonyflake(sonyflake.Settings{})
+	var azureVMSKUTable string
+	for {
+		id, err := sf.NextID()
+		if err != nil {
+			return "", err
+		}
+
+		azureVMSKUTable = fmt.Sprintf("%s_%s_%d",
+			r.viewName,
+			time.Now().Format("2006_01_02"),
+			id,
+		)
+		var c int32
+		tx := r.db.Conn().Raw(fmt.Sprintf(`
+		SELECT count(*)
+		FROM information_schema.tables
+		WHERE table_schema = current_schema
+		AND table_name = '%s';
+	`, azureVMSKUTable)).First(&c)
+		if tx.Error != nil {
+			return "", err
+		}
+		if c == 0 {
+			break
+		}
+	}
+
+	err := r.db.Conn().Table(azureVMSKUTable).AutoMigrate(&model.AzureVMSKU{})
+	if err != nil {
+		return "", err
+	}
+	return azureVMSKUTable, nil
+}
+
+func (r *AzureVMSKURepoImpl) MoveViewTransaction(tableName string) error {
+	tx := r.db.Conn().Begin()
+	var err error
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	dropViewQuery := fmt.Sprintf("DROP VIEW IF EXISTS %s", r.viewName)
+	tx = tx.Exec(dropViewQuery)
+	err = tx.Error
+	if err != nil {
+		return err
+	}
+
+	createViewQuery := fmt.Sprintf(`
+  CREATE OR REPLACE VIEW %s AS
+  SELECT *
+  FROM %s;
+`, r.viewName, tableName)
+
+	tx = tx.Exec(createViewQuery)
+	err = tx.Error
+	if err != nil {
+		return err
+	}
+
+	tx = tx.Commit()
+	err = tx.Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *AzureVMSKURepoImpl) getOldTables(currentTableName string) ([]string, error) {
+	query := fmt.Sprintf(`
+		SELECT table_name
+		FROM information_schema.tables
+		WHERE table_schema = current_schema
+		AND table_name LIKE '%s_%%' AND table_name <> '%s';
+	`, r.viewName, currentTableName)
+
+	var tableNames []string
+	tx := r.db.Conn().Raw(query).Find(&tableNames)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return tableNames, nil
+}
+
+func (r *AzureVMSKURepoImpl) RemoveOldTables(currentTableName string, keep ...string) error {
+	tableNames, err := r.getOldTables(currentTableName)
+	if err != nil {
+		return err
+	}
+	for _, tn := range tableNames {
+		if slices.Contains(keep, tn) {
+			continue
+		}
+		err = r.db.Conn().Migrator().DropTable(tn)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package repo
+
+import (
+	"errors"
+	"github.com/opengovern/opengovernance/services/wastage/db/connector"
+	"github.com/opengovern/opengovernance/services/wastage/db/model"
+	"gorm.io/gorm"
+)
+
+type CatalogVersionRepo interface {
+	Create(m *model.CatalogVersion) error
+	Get(dataType, version string) (*model.CatalogVersion, error)
+	List(dataType string, limit int) ([]model.CatalogVersion, error)
+	TableExists(tableName string) (bool, error)
+}
+
+type CatalogVersionRepoImpl struct {
+	db *connector.Database
+}
+
+func NewCatalogVersionRepo(db *connector.Database) CatalogVersionRepo {
+	return &CatalogVersionRepoImpl{
+		db: db,
+	}
+}
+
+func (r *CatalogVersionRepoImpl) Create(m *model.CatalogVersion) error {
+	return r.db.Conn().Create(m).Error
+}
+
+func (r *CatalogVersionRepoImpl) Get(dataType, version string) (*model.CatalogVersion, error) {
+	var m model.CatalogVersion
+	tx := r.db.Conn().Model(&model.CatalogVersion{}).
+		Where("data_type = ?", dataType).
+		Where("version = ?", version).
+		Order("id DESC").
+		First(&m)
+	if tx.Error != nil {
+		if errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, tx.Error
+	}
+	return &m, nil
+}
+
+// List returns the versions of the data type, newest first. A limit of zero returns all of them.
+func (r *CatalogVersionRepoImpl) List(dataType string, limit int) ([]model.CatalogVersion, error) {
+	var ms []model.CatalogVersion
+	tx := r.db.Conn().Model(&model.CatalogVersion{})
+	if dataType != "" {
+		tx = tx.Where("data_type = ?", dataType)
+	}
+	tx = tx.Order("id DESC")
+	if limit > 0 {
+		tx = tx.Limit(limit)
+	}
+	tx = tx.Find(&ms)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return ms, nil
+}
+
+func (r *CatalogVersionRepoImpl) TableExists(tableName string) (bool, error) {
+	var c int64
+	tx := r.db.Conn().Raw(`
+		SELECT count(*)
+		FROM information_schema.tables
+		WHERE table_schema = current_schema
+		AND table_name = ?
+	`, tableName).Scan(&c)
+	if tx.Error != nil {
+		return false, tx.Error
+	}
+	return c > 0, nil
+}
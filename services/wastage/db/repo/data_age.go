@@ -12,6 +12,7 @@ type DataAgeRepo interface {
 	Get(dataType string) (*model.DataAge, error)
 	Update(dataType string, m model.DataAge) error
 	Delete(dataType string) error
+	SetPinned(dataType string, pinned bool) error
 	List() ([]model.DataAge, error)
 }
 
@@ -49,6 +50,10 @@ func (r *DataAgeRepoImpl) Delete(dataType string) error {
 	return r.db.Conn().Unscoped().Delete(&model.DataAge{DataType: dataType}).Error
 }
 
+func (r *DataAgeRepoImpl) SetPinned(dataType string, pinned bool) error {
+	return r.db.Conn().Model(&model.DataAge{}).Where("data_type=?", dataType).Update("pinned", pinned).Error
+}
+
 func (r *DataAgeRepoImpl) List() ([]model.DataAge, error) {
 	var ms []model.DataAge
 	tx := r.db.Conn().Model(&model.DataAge{}).Find(&ms)
@@ -10,6 +10,7 @@ import (
 	"github.com/sony/sonyflake"
 	"gorm.io/gorm"
 	"math"
+	"slices"
 	"time"
 )
 
@@ -22,7 +23,7 @@ type EBSVolumeTypeRepo interface {
 	Truncate(tx *gorm.DB) error
 	GetCheapestTypeWithSpecs(ctx context.Context, region string, volumeSize int32, iops int32, throughput float64, validTypes []types.VolumeType) (types.VolumeType, int32, int32, float64, string, error)
 	MoveViewTransaction(tableName string) error
-	RemoveOldTables(tableName string) error
+	RemoveOldTables(currentTableName string, keep ...string) error
 	CreateNewTable() (string, error)
 }
 
@@ -463,12 +464,15 @@ func (r *EBSVolumeTypeRepoImpl) getOldTables(currentTableName string) ([]string,
 	return tableNames, nil
 }
 
-func (r *EBSVolumeTypeRepoImpl) RemoveOldTables(currentTableName string) error {
+func (r *EBSVolumeTypeRepoImpl) RemoveOldTables(currentTableName string, keep ...string) error {
 	tableNames, err := r.getOldTables(currentTableName)
 	if err != nil {
 		return err
 	}
 	for _, tn := range tableNames {
+		if slices.Contains(keep, tn) {
+			continue
+		}
 		err = r.db.Conn().Migrator().DropTable(tn)
 		if err != nil {
 			return err
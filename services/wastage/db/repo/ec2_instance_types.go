@@ -8,6 +8,7 @@ import (
 	"github.com/opengovern/opengovernance/services/wastage/db/model"
 	"github.com/sony/sonyflake"
 	"gorm.io/gorm"
+	"slices"
 	"time"
 )
 
@@ -20,10 +21,11 @@ type EC2InstanceTypeRepo interface {
 	Delete(tableName string, id uint) error
 	List() ([]model.EC2InstanceType, error)
 	GetCheapestByCoreAndNetwork(ctx context.Context, bandwidth float64, pref map[string]interface{}) (*model.EC2InstanceType, error)
+	ListByCoreAndMemory(ctx context.Context, vCpu, memoryGB float64, pref map[string]interface{}) ([]model.EC2InstanceType, error)
 	Truncate(tx *gorm.DB) error
 	ListByInstanceType(ctx context.Context, instanceType, operation, region string) ([]model.EC2InstanceType, error)
 	MoveViewTransaction(tableName string) error
-	RemoveOldTables(tableName string) error
+	RemoveOldTables(currentTableName string, keep ...string) error
 	CreateNewTable() (string, error)
 }
 
@@ -83,6 +85,24 @@ func (r *EC2InstanceTypeRepoImpl) GetCheapestByCoreAndNetwork(ctx context.Contex
 	return &m, nil
 }
 
+// ListByCoreAndMemory lists the instance types with at least the given vCPUs and memory, cheapest first.
+func (r *EC2InstanceTypeRepoImpl) ListByCoreAndMemory(ctx context.Context, vCpu, memoryGB float64, pref map[string]interface{}) ([]model.EC2InstanceType, error) {
+	var ms []model.EC2InstanceType
+	tx := r.db.Conn().Table(r.viewName).WithContext(ctx).
+		Where("v_cpu >= ?", vCpu).
+		Where("memory_gb >= ?", memoryGB).
+		Where("capacity_status = 'Used'").
+		Where("price_per_unit != 0")
+	for k, v := range pref {
+		tx = tx.Where(k, v)
+	}
+	tx = tx.Order("price_per_unit ASC").Find(&ms)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return ms, nil
+}
+
 func (r *EC2InstanceTypeRepoImpl) Update(tableName string, id uint, m model.EC2InstanceType) error {
 	return r.db.Conn().Table(tableName).Where("id=?", id).Updates(&m).Error
 }
@@ -240,12 +260,15 @@ func (r *EC2InstanceTypeRepoImpl) getOldTables(currentTableName string) ([]strin
 	return tableNames, nil
 }
 
-func (r *EC2InstanceTypeRepoImpl) RemoveOldTables(currentTableName string) error {
+func (r *EC2InstanceTypeRepoImpl) RemoveOldTables(currentTableName string, keep ...string) error {
 	tableNames, err := r.getOldTables(currentTableName)
 	if err != nil {
 		return err
 	}
 	for _, tn := range tableNames {
+		if slices.Contains(keep, tn) {
+			continue
+		}
 		err = r.db.Conn().Migrator().DropTable(tn)
 		if err != nil {
 			return err
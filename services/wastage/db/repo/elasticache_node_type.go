@@ -0,0 +1,198 @@
+package repo
+
+import (
+	"errors"
+	"fmt"
+	"github.com/opengovern/opengovernance/services/wastage/db/connector"
+	"github.com/opengovern/opengovernance/services/wastage/db/model"
+	"github.com/sony/sonyflake"
+	"gorm.io/gorm"
+	"slices"
+	"time"
+)
+
+type ElastiCacheNodeTypeRepo interface {
+	Create(tableName string, tx *gorm.DB, m *model.ElastiCacheNodeType) error
+	Delete(tableName string, id string) error
+	List() ([]model.ElastiCacheNodeType, error)
+	Get(region, engine, instanceType string) (*model.ElastiCacheNodeType, error)
+	GetCheapest(region, engine string, vCpu, memoryGb float64, pref map[string]interface{}) (*model.ElastiCacheNodeType, error)
+	CreateNewTable() (string, error)
+	MoveViewTransaction(tableName string) error
+	RemoveOldTables(currentTableName string, keep ...string) error
+}
+
+type ElastiCacheNodeTypeRepoImpl struct {
+	db *connector.Database
+
+	viewName string
+}
+
+func NewElastiCacheNodeTypeRepo(db *connector.Database) ElastiCacheNodeTypeRepo {
+	stmt := &gorm.Statement{DB: db.Conn()}
+	stmt.Parse(&model.ElastiCacheNodeType{})
+
+	return &ElastiCacheNodeTypeRepoImpl{
+		db: db,
+
+		viewName: stmt.Schema.Table,
+	}
+}
+
+func (r *ElastiCacheNodeTypeRepoImpl) Create(tableName string, tx *gorm.DB, m *model.ElastiCacheNodeType) error {
+	if tx == nil {
+		tx = r.db.Conn()
+	}
+	tx = tx.Table(tableName)
+	return tx.Create(&m).Error
+}
+
+func (r *ElastiCacheNodeTypeRepoImpl) Delete(tableName string, id string) error {
+	return r.db.Conn().Table(tableName).Where("id=?", id).Delete(&model.ElastiCacheNodeType{}).Error
+}
+
+func (r *ElastiCacheNodeTypeRepoImpl) List() ([]model.ElastiCacheNodeType, error) {
+	var m []model.ElastiCacheNodeType
+	tx := r.db.Conn().Table(r.viewName).Find(&m)
+	return m, tx.Error
+}
+
+func (r *ElastiCacheNodeTypeRepoImpl) Get(region, engine, instanceType string) (*model.ElastiCacheNodeType, error) {
+	var m model.ElastiCacheNodeType
+	tx := r.db.Conn().Table(r.viewName).
+		Where("region_code = ?", region).
+		Where("cache_engine = ?", engine).
+		Where("instance_type = ?", instanceType).
+		Order("price_per_unit ASC").
+		First(&m)
+	if tx.Error != nil {
+		if errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, tx.Error
+	}
+	return &m, nil
+}
+
+func (r *ElastiCacheNodeTypeRepoImpl) GetCheapest(region, engine string, vCpu, memoryGb float64, pref map[string]interface{}) (*model.ElastiCacheNodeType, error) {
+	var m model.ElastiCacheNodeType
+	tx := r.db.Conn().Table(r.viewName).
+		Where("region_code = ?", region).
+		Where("cache_engine = ?", engine).
+		Where("v_cpu >= ?", vCpu).
+		Where("memory_gb >= ?", memoryGb)
+	for k, v := range pref {
+		tx = tx.Where(k, v)
+	}
+	tx = tx.Order("price_per_unit ASC").First(&m)
+	if tx.Error != nil {
+		if errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, tx.Error
+	}
+	return &m, nil
+}
+
+func (r *ElastiCacheNodeTypeRepoImpl) CreateNewTable() (string, error) {
+	sf := sonyflake.NewSonyflake(sonyflake.Settings{})
+	var elastiCacheNodeTypeTable string
+	for {
+		id, err := sf.NextID()
+		if err != nil {
+			return "", err
+		}
+
+		elastiCacheNodeTypeTable = fmt.Sprintf("%s_%s_%d",
+			r.viewName,
+			time.Now().Format("2006_01_02"),
+			id,
+		)
+		var c int32
+		tx := r.db.Conn().Raw(fmt.Sprintf(`
+		SELECT count(*)
+		FROM information_schema.tables
+		WHERE table_schema = current_schema
+		AND table_name = '%s';
+	`, elastiCacheNodeTypeTable)).First(&c)
+		if tx.Error != nil {
+			return "", err
+		}
+		if c == 0 {
+			break
+		}
+	}
+
+	err := r.db.Conn().Table(elastiCacheNodeTypeTable).AutoMigrate(&model.ElastiCacheNodeType{})
+	if err != nil {
+		return "", err
+	}
+	return elastiCacheNodeTypeTable, nil
+}
+
+func (r *ElastiCacheNodeTypeRepoImpl) MoveViewTransaction(tableName string) error {
+	tx := r.db.Conn().Begin()
+	var err error
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	dropViewQuery := fmt.Sprintf("DROP VIEW IF EXISTS %s", r.viewName)
+	tx = tx.Exec(dropViewQuery)
+	err = tx.Error
+	if err != nil {
+		return err
+	}
+
+	createViewQuery := fmt.Sprintf(`
+  CREATE OR REPLACE VIEW %s AS
+  SELECT *
+  FROM %s;
+`, r.viewName, tableName)
+
+	tx = tx.Exec(createViewQuery)
+	err = tx.Error
+	if err != nil {
+		return err
+	}
+
+	tx = tx.Commit()
+	err = tx.Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *ElastiCacheNodeTypeRepoImpl) getOldTables(currentTableName string) ([]string, error) {
+	query := fmt.Sprintf(`
+		SELECT table_name
+		FROM information_schema.tables
+		WHERE table_schema = current_schema
+		AND table_name LIKE '%s_%%' AND table_name <> '%s';
+	`, r.viewName, currentTableName)
+
+	var tableNames []string
+	tx := r.db.Conn().Raw(query).Find(&tableNames)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return tableNames, nil
+}
+
+func (r *ElastiCacheNodeTypeRepoImpl) RemoveOldTables(currentTableName string, keep ...string) error {
+	tableNames, err := r.getOldTables(currentTableName)
+	if err != nil {
+		return err
+	}
+	for _, tn := range tableNames {
+		if slices.Contains(keep, tn) {
+			continue
+		}
+		err = r.db.Conn().Migrator().DropTable(tn)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/opengovern/opengovernance/services/wastage/db/model"
 	"github.com/sony/sonyflake"
 	"gorm.io/gorm"
+	"slices"
 	"time"
 )
 
@@ -16,9 +17,10 @@ type GCPComputeMachineTypeRepo interface {
 	List() ([]model.GCPComputeMachineType, error)
 	Get(machineType string) (*model.GCPComputeMachineType, error)
 	GetCheapestByCoreAndMemory(cpu, memory float64, pref map[string]interface{}) (*model.GCPComputeMachineType, error)
+	ListByCoreAndMemory(cpu, memory float64, pref map[string]interface{}) ([]model.GCPComputeMachineType, error)
 	CreateNewTable() (string, error)
 	MoveViewTransaction(tableName string) error
-	RemoveOldTables(currentTableName string) error
+	RemoveOldTables(currentTableName string, keep ...string) error
 }
 
 type GCPComputeMachineTypeRepoImpl struct {
@@ -81,6 +83,23 @@ func (r *GCPComputeMachineTypeRepoImpl) GetCheapestByCoreAndMemory(cpu, memory f
 	return &m, nil
 }
 
+// ListByCoreAndMemory lists the machine types with at least the given cpus and memory in MB, cheapest first.
+func (r *GCPComputeMachineTypeRepoImpl) ListByCoreAndMemory(cpu, memory float64, pref map[string]interface{}) ([]model.GCPComputeMachineType, error) {
+	var ms []model.GCPComputeMachineType
+	tx := r.db.Conn().Table(r.viewName).
+		Where("guest_cpus >= ?", cpu).
+		Where("memory_mb >= ?", memory).
+		Where("unit_price != 0")
+	for k, v := range pref {
+		tx = tx.Where(k, v)
+	}
+	tx = tx.Order("unit_price ASC").Find(&ms)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return ms, nil
+}
+
 func (r *GCPComputeMachineTypeRepoImpl) CreateNewTable() (string, error) {
 	sf := sonyflake.NewSonyflake(sonyflake.Settings{})
 	var gcpComputeMachineTypeTable string
@@ -167,12 +186,15 @@ func (r *GCPComputeMachineTypeRepoImpl) getOldTables(currentTableName string) ([
 	return tableNames, nil
 }
 
-func (r *GCPComputeMachineTypeRepoImpl) RemoveOldTables(currentTableName string) error {
+func (r *GCPComputeMachineTypeRepoImpl) RemoveOldTables(currentTableName string, keep ...string) error {
 	tableNames, err := r.getOldTables(currentTableName)
 	if err != nil {
 		return err
 	}
 	for _, tn := range tableNames {
+		if slices.Contains(keep, tn) {
+			continue
+		}
 		err = r.db.Conn().Migrator().DropTable(tn)
 		if err != nil {
 			return err
@@ -7,6 +7,7 @@ import (
 	"github.com/opengovern/opengovernance/services/wastage/db/model"
 	"github.com/sony/sonyflake"
 	"gorm.io/gorm"
+	"slices"
 	"time"
 )
 
@@ -18,7 +19,7 @@ type GCPComputeSKURepo interface {
 	GetCheapestCustomRam(machineFamily string, pref map[string]interface{}) (*model.GCPComputeSKU, error)
 	CreateNewTable() (string, error)
 	MoveViewTransaction(tableName string) error
-	RemoveOldTables(currentTableName string) error
+	RemoveOldTables(currentTableName string, keep ...string) error
 }
 
 type GCPComputeSKURepoImpl struct {
@@ -184,12 +185,15 @@ func (r *GCPComputeSKURepoImpl) getOldTables(currentTableName string) ([]string,
 	return tableNames, nil
 }
 
-func (r *GCPComputeSKURepoImpl) RemoveOldTables(currentTableName string) error {
+func (r *GCPComputeSKURepoImpl) RemoveOldTables(currentTableName string, keep ...string) error {
 	tableNames, err := r.getOldTables(currentTableName)
 	if err != nil {
 		return err
 	}
 	for _, tn := range tableNames {
+		if slices.Contains(keep, tn) {
+			continue
+		}
 		err = r.db.Conn().Migrator().DropTable(tn)
 		if err != nil {
 			return err
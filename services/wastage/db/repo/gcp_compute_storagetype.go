@@ -7,6 +7,7 @@ import (
 	"github.com/opengovern/opengovernance/services/wastage/db/model"
 	"github.com/sony/sonyflake"
 	"gorm.io/gorm"
+	"slices"
 	"time"
 )
 
@@ -18,7 +19,7 @@ type GCPComputeDiskTypeRepo interface {
 	GetCheapest(pref map[string]interface{}) (*model.GCPComputeDiskType, error)
 	CreateNewTable() (string, error)
 	MoveViewTransaction(tableName string) error
-	RemoveOldTables(currentTableName string) error
+	RemoveOldTables(currentTableName string, keep ...string) error
 }
 
 type GCPComputeDiskTypeRepoImpl struct {
@@ -164,12 +165,15 @@ func (r *GCPComputeDiskTypeRepoImpl) getOldTables(currentTableName string) ([]st
 	return tableNames, nil
 }
 
-func (r *GCPComputeDiskTypeRepoImpl) RemoveOldTables(currentTableName string) error {
+func (r *GCPComputeDiskTypeRepoImpl) RemoveOldTables(currentTableName string, keep ...string) error {
 	tableNames, err := r.getOldTables(currentTableName)
 	if err != nil {
 		return err
 	}
 	for _, tn := range tableNames {
+		if slices.Contains(keep, tn) {
+			continue
+		}
 		err = r.db.Conn().Migrator().DropTable(tn)
 		if err != nil {
 			return err
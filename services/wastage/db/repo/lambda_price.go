@@ -0,0 +1,178 @@
+package repo
+
+import (
+	"errors"
+	"fmt"
+	"github.com/opengovern/opengovernance/services/wastage/db/connector"
+	"github.com/opengovern/opengovernance/services/wastage/db/model"
+	"github.com/sony/sonyflake"
+	"gorm.io/gorm"
+	"slices"
+	"time"
+)
+
+type LambdaPriceRepo interface {
+	Create(tableName string, tx *gorm.DB, m *model.LambdaPrice) error
+	Delete(tableName string, id string) error
+	List() ([]model.LambdaPrice, error)
+	Get(region, group string) (*model.LambdaPrice, error)
+	CreateNewTable() (string, error)
+	MoveViewTransaction(tableName string) error
+	RemoveOldTables(currentTableName string, keep ...string) error
+}
+
+type LambdaPriceRepoImpl struct {
+	db *connector.Database
+
+	viewName string
+}
+
+func NewLambdaPriceRepo(db *connector.Database) LambdaPriceRepo {
+	stmt := &gorm.Statement{DB: db.Conn()}
+	stmt.Parse(&model.LambdaPrice{})
+
+	return &LambdaPriceRepoImpl{
+		db: db,
+
+		viewName: stmt.Schema.Table,
+	}
+}
+
+func (r *LambdaPriceRepoImpl) Create(tableName string, tx *gorm.DB, m *model.LambdaPrice) error {
+	if tx == nil {
+		tx = r.db.Conn()
+	}
+	tx = tx.Table(tableName)
+	return tx.Create(&m).Error
+}
+
+func (r *LambdaPriceRepoImpl) Delete(tableName string, id string) error {
+	return r.db.Conn().Table(tableName).Where("id=?", id).Delete(&model.LambdaPrice{}).Error
+}
+
+func (r *LambdaPriceRepoImpl) List() ([]model.LambdaPrice, error) {
+	var m []model.LambdaPrice
+	tx := r.db.Conn().Table(r.viewName).Find(&m)
+	return m, tx.Error
+}
+
+// Get returns the price of the first paid tier of a price group.
+func (r *LambdaPriceRepoImpl) Get(region, group string) (*model.LambdaPrice, error) {
+	var m model.LambdaPrice
+	tx := r.db.Conn().Table(r.viewName).
+		Where("region_code = ?", region).
+		Where("price_group = ?", group).
+		Where("price_per_unit > 0").
+		Order("starting_range ASC").
+		First(&m)
+	if tx.Error != nil {
+		if errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, tx.Error
+	}
+	return &m, nil
+}
+
+func (r *LambdaPriceRepoImpl) CreateNewTable() (string, error) {
+	sf := sonyflake.NewSonyflake(sonyflake.Settings{})
+	var lambdaPriceTable string
+	for {
+		id, err := sf.NextID()
+		if err != nil {
+			return "", err
+		}
+
+		lambdaPriceTable = fmt.Sprintf("%s_%s_%d",
+			r.viewName,
+			time.Now().Format("2006_01_02"),
+			id,
+		)
+		var c int32
+		tx := r.db.Conn().Raw(fmt.Sprintf(`
+		SELECT count(*)
+		FROM information_schema.tables
+		WHERE table_schema = current_schema
+		AND table_name = '%s';
+	`, lambdaPriceTable)).First(&c)
+		if tx.Error != nil {
+			return "", err
+		}
+		if c == 0 {
+			break
+		}
+	}
+
+	err := r.db.Conn().Table(lambdaPriceTable).AutoMigrate(&model.LambdaPrice{})
+	if err != nil {
+		return "", err
+	}
+	return lambdaPriceTable, nil
+}
+
+func (r *LambdaPriceRepoImpl) MoveViewTransaction(tableName string) error {
+	tx := r.db.Conn().Begin()
+	var err error
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	dropViewQuery := fmt.Sprintf("DROP VIEW IF EXISTS %s", r.viewName)
+	tx = tx.Exec(dropViewQuery)
+	err = tx.Error
+	if err != nil {
+		return err
+	}
+
+	createViewQuery := fmt.Sprintf(`
+  CREATE OR REPLACE VIEW %s AS
+  SELECT *
+  FROM %s;
+`, r.viewName, tableName)
+
+	tx = tx.Exec(createViewQuery)
+	err = tx.Error
+	if err != nil {
+		return err
+	}
+
+	tx = tx.Commit()
+	err = tx.Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *LambdaPriceRepoImpl) getOldTables(currentTableName string) ([]string, error) {
+	query := fmt.Sprintf(`
+		SELECT table_name
+		FROM information_schema.tables
+		WHERE table_schema = current_schema
+		AND table_name LIKE '%s_%%' AND table_name <> '%s';
+	`, r.viewName, currentTableName)
+
+	var tableNames []string
+	tx := r.db.Conn().Raw(query).Find(&tableNames)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return tableNames, nil
+}
+
+func (r *LambdaPriceRepoImpl) RemoveOldTables(currentTableName string, keep ...string) error {
+	tableNames, err := r.getOldTables(currentTableName)
+	if err != nil {
+		return err
+	}
+	for _, tn := range tableNames {
+		if slices.Contains(keep, tn) {
+			continue
+		}
+		err = r.db.Conn().Migrator().DropTable(tn)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/opengovern/opengovernance/services/wastage/db/model"
 	"github.com/sony/sonyflake"
 	"gorm.io/gorm"
+	"slices"
 	"time"
 )
 
@@ -22,7 +23,7 @@ type RDSDBInstanceRepo interface {
 	ListByInstanceType(ctx context.Context, region, instanceType, engine, engineEdition, clusterType string) ([]model.RDSDBInstance, error)
 	GetCheapestByPref(ctx context.Context, pref map[string]any) (*model.RDSDBInstance, error)
 	MoveViewTransaction(tableName string) error
-	RemoveOldTables(tableName string) error
+	RemoveOldTables(currentTableName string, keep ...string) error
 	CreateNewTable() (string, error)
 }
 
@@ -239,12 +240,15 @@ func (r *RDSDBInstanceRepoImpl) getOldTables(currentTableName string) ([]string,
 	return tableNames, nil
 }
 
-func (r *RDSDBInstanceRepoImpl) RemoveOldTables(currentTableName string) error {
+func (r *RDSDBInstanceRepoImpl) RemoveOldTables(currentTableName string, keep ...string) error {
 	tableNames, err := r.getOldTables(currentTableName)
 	if err != nil {
 		return err
 	}
 	for _, tn := range tableNames {
+		if slices.Contains(keep, tn) {
+			continue
+		}
 		err = r.db.Conn().Migrator().DropTable(tn)
 		if err != nil {
 			return err
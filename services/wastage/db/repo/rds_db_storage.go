@@ -11,6 +11,7 @@ import (
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 	"math"
+	"slices"
 	"strings"
 	"time"
 )
@@ -24,7 +25,7 @@ type RDSDBStorageRepo interface {
 	Truncate(tx *gorm.DB) error
 	GetCheapestBySpecs(ctx context.Context, region, engine, edition string, clusterType entity.AwsRdsClusterType, volumeSize, iops int32, throughput float64, validTypes []model.RDSDBStorageVolumeType) (*model.RDSDBStorage, int32, int32, float64, string, error)
 	MoveViewTransaction(tableName string) error
-	RemoveOldTables(tableName string) error
+	RemoveOldTables(currentTableName string, keep ...string) error
 	CreateNewTable() (string, error)
 }
 
@@ -590,12 +591,15 @@ func (r *RDSDBStorageRepoImpl) getOldTables(currentTableName string) ([]string,
 	return tableNames, nil
 }
 
-func (r *RDSDBStorageRepoImpl) RemoveOldTables(currentTableName string) error {
+func (r *RDSDBStorageRepoImpl) RemoveOldTables(currentTableName string, keep ...string) error {
 	tableNames, err := r.getOldTables(currentTableName)
 	if err != nil {
 		return err
 	}
 	for _, tn := range tableNames {
+		if slices.Contains(keep, tn) {
+			continue
+		}
 		err = r.db.Conn().Migrator().DropTable(tn)
 		if err != nil {
 			return err
@@ -7,6 +7,7 @@ import (
 	"github.com/opengovern/opengovernance/services/wastage/db/model"
 	"github.com/sony/sonyflake"
 	"gorm.io/gorm"
+	"slices"
 	"time"
 )
 
@@ -18,7 +19,7 @@ type RDSProductRepo interface {
 	List() ([]model.RDSProduct, error)
 	Truncate(tx *gorm.DB) error
 	MoveViewTransaction(tableName string) error
-	RemoveOldTables(tableName string) error
+	RemoveOldTables(currentTableName string, keep ...string) error
 	CreateNewTable() (string, error)
 }
 
@@ -173,12 +174,15 @@ func (r *RDSProductRepoImpl) getOldTables(currentTableName string) ([]string, er
 	return tableNames, nil
 }
 
-func (r *RDSProductRepoImpl) RemoveOldTables(currentTableName string) error {
+func (r *RDSProductRepoImpl) RemoveOldTables(currentTableName string, keep ...string) error {
 	tableNames, err := r.getOldTables(currentTableName)
 	if err != nil {
 		return err
 	}
 	for _, tn := range tableNames {
+		if slices.Contains(keep, tn) {
+			continue
+		}
 		err = r.db.Conn().Migrator().DropTable(tn)
 		if err != nil {
 			return err
@@ -0,0 +1,141 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/opengovern/opengovernance/services/wastage/db/connector"
+	"github.com/opengovern/opengovernance/services/wastage/db/model"
+	"gorm.io/gorm"
+)
+
+const (
+	RecommendationGroupByAccount      = "account"
+	RecommendationGroupByOrganization = "organization"
+)
+
+type RecommendationFilter struct {
+	Auth0UserId string
+	// OrgAddress is the domain of the organization emails
+	OrgAddress string
+	AccountID  string
+	State      model.RecommendationState
+}
+
+// RecommendationSavings is the potential and realized savings of the recommendations of a group in a state.
+type RecommendationSavings struct {
+	Group            string
+	State            model.RecommendationState
+	Count            int64
+	PotentialSavings float64
+	RealizedSavings  float64
+}
+
+type RecommendationRepo interface {
+	Create(ctx context.Context, m *model.Recommendation) error
+	Save(ctx context.Context, m *model.Recommendation) error
+	Get(ctx context.Context, id uint) (*model.Recommendation, error)
+	GetActiveByResource(ctx context.Context, endpoint, resourceId string) (*model.Recommendation, error)
+	List(ctx context.Context, filter RecommendationFilter) ([]model.Recommendation, error)
+	GetSavings(ctx context.Context, groupBy string, filter RecommendationFilter) ([]RecommendationSavings, error)
+}
+
+type RecommendationRepoImpl struct {
+	db *connector.Database
+}
+
+func NewRecommendationRepo(db *connector.Database) RecommendationRepo {
+	return &RecommendationRepoImpl{
+		db: db,
+	}
+}
+
+func (r *RecommendationRepoImpl) Create(ctx context.Context, m *model.Recommendation) error {
+	return r.db.Conn().WithContext(ctx).Create(m).Error
+}
+
+// Save updates every field of the recommendation, the optional fields are cleared when they are nil.
+func (r *RecommendationRepoImpl) Save(ctx context.Context, m *model.Recommendation) error {
+	return r.db.Conn().WithContext(ctx).Save(m).Error
+}
+
+func (r *RecommendationRepoImpl) Get(ctx context.Context, id uint) (*model.Recommendation, error) {
+	var m model.Recommendation
+	tx := r.db.Conn().WithContext(ctx).Model(&model.Recommendation{}).Where("id = ?", id).First(&m)
+	if tx.Error != nil {
+		if errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, tx.Error
+	}
+	return &m, nil
+}
+
+// GetActiveByResource returns the latest recommendation of the resource that is not verified yet.
+func (r *RecommendationRepoImpl) GetActiveByResource(ctx context.Context, endpoint, resourceId string) (*model.Recommendation, error) {
+	var m model.Recommendation
+	tx := r.db.Conn().WithContext(ctx).Model(&model.Recommendation{}).
+		Where("api_endpoint = ?", endpoint).
+		Where("resource_id = ?", resourceId).
+		Where("state <> ?", model.RecommendationStateVerified).
+		Order("id DESC").
+		First(&m)
+	if tx.Error != nil {
+		if errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, tx.Error
+	}
+	return &m, nil
+}
+
+func (r *RecommendationRepoImpl) filter(tx *gorm.DB, filter RecommendationFilter) *gorm.DB {
+	if filter.Auth0UserId != "" {
+		tx = tx.Where("auth0_user_id = ?", filter.Auth0UserId)
+	}
+	if filter.OrgAddress != "" {
+		tx = tx.Where("org_email LIKE ?", fmt.Sprintf("%%@%s", filter.OrgAddress))
+	}
+	if filter.AccountID != "" {
+		tx = tx.Where("account_id = ?", filter.AccountID)
+	}
+	if filter.State != "" {
+		tx = tx.Where("state = ?", filter.State)
+	}
+	return tx
+}
+
+func (r *RecommendationRepoImpl) List(ctx context.Context, filter RecommendationFilter) ([]model.Recommendation, error) {
+	var ms []model.Recommendation
+	tx := r.filter(r.db.Conn().WithContext(ctx).Model(&model.Recommendation{}), filter).
+		Order("id DESC").
+		Find(&ms)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return ms, nil
+}
+
+func (r *RecommendationRepoImpl) GetSavings(ctx context.Context, groupBy string, filter RecommendationFilter) ([]RecommendationSavings, error) {
+	var group string
+	switch groupBy {
+	case RecommendationGroupByAccount:
+		group = "account_id"
+	case RecommendationGroupByOrganization:
+		group = "split_part(org_email, '@', 2)"
+	default:
+		return nil, fmt.Errorf("unknown group by %s", groupBy)
+	}
+
+	var rows []RecommendationSavings
+	tx := r.filter(r.db.Conn().WithContext(ctx).Model(&model.Recommendation{}), filter).
+		Select(fmt.Sprintf("%s AS \"group\", state, count(*) AS count, "+
+			"coalesce(sum(potential_savings), 0) AS potential_savings, "+
+			"coalesce(sum(realized_savings), 0) AS realized_savings", group)).
+		Group(fmt.Sprintf("%s, state", group)).
+		Scan(&rows)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return rows, nil
+}
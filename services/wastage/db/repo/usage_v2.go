@@ -23,6 +23,8 @@ type UsageV2Repo interface {
 	GetRDSClusterOptimizationsCountForOrg(ctx context.Context, orgAddress string) (int64, error)
 	GetEC2InstanceOptimizationsCountForUser(ctx context.Context, userId string) (int64, error)
 	GetEC2InstanceOptimizationsCountForOrg(ctx context.Context, orgAddress string) (int64, error)
+	GetOptimizationsCountForUser(ctx context.Context, endpoint, userId string) (int64, error)
+	GetOptimizationsCountForOrg(ctx context.Context, endpoint, orgAddress string) (int64, error)
 	GetAccountsForUser(ctx context.Context, userId string) ([]string, error)
 	GetAccountsForOrg(ctx context.Context, orgAddress string) ([]string, error)
 }
@@ -251,6 +253,40 @@ func (r *UsageV2RepoImpl) GetEC2InstanceOptimizationsCountForOrg(ctx context.Con
 	return count, nil
 }
 
+func (r *UsageV2RepoImpl) GetOptimizationsCountForUser(ctx context.Context, endpoint, userId string) (int64, error) {
+	var count int64
+	err := r.db.Conn().WithContext(ctx).
+		Raw(`
+			SELECT COUNT(*) 
+			FROM usage_v2 
+			WHERE api_endpoint = ? 
+			AND statistics ->> 'auth0UserId' = ? 
+			AND request ->> 'loading' <> 'true'
+		`, endpoint, userId).
+		Scan(&count).Error
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *UsageV2RepoImpl) GetOptimizationsCountForOrg(ctx context.Context, endpoint, orgAddress string) (int64, error) {
+	var count int64
+	err := r.db.Conn().WithContext(ctx).
+		Raw(`
+			SELECT COUNT(*) 
+			FROM usage_v2 
+			WHERE api_endpoint = ? 
+			AND statistics ->> 'orgEmail' LIKE ? 
+			AND request ->> 'loading' <> 'true'
+		`, endpoint, fmt.Sprintf("%%@%s", orgAddress)).
+		Scan(&count).Error
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
 //func (r *UsageV2RepoImpl) GetEBSVolumeOptimizationsCountForUser(userId string) (int64, error) {
 //	var count int64
 //	err := r.db.Conn().Model(&model.UsageV2{}).
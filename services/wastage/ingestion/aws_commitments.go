@@ -0,0 +1,203 @@
+package ingestion
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/opengovern/opengovernance/services/wastage/db/model"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const savingsPlansRegionIndexURL = "https://pricing.us-east-1.amazonaws.com/savingsPlan/v1.0/aws/AWSComputeSavingsPlan/current/region_index.json"
+
+// savingsPlansOperations are the usage operations of the operating systems without pre installed software,
+// the rates of the other operations are not ingested to keep the catalog small.
+var savingsPlansOperations = map[string]bool{
+	"RunInstances":      true, // Linux/UNIX
+	"RunInstances:0002": true, // Windows
+	"RunInstances:0010": true, // Red Hat Enterprise Linux
+	"RunInstances:000g": true, // SUSE Linux
+	"RunInstances:0g00": true, // Ubuntu Pro
+}
+
+// savingsPlansRates holds the discounted ec2 rates of the compute and ec2 instance savings plans of every region.
+type savingsPlansRates struct {
+	Rates []savingsPlanRate `json:"rates"`
+}
+
+type savingsPlanRate struct {
+	CommitmentType      string  `json:"commitmentType"`
+	RegionCode          string  `json:"regionCode"`
+	InstanceType        string  `json:"instanceType"`
+	InstanceFamily      string  `json:"instanceFamily"`
+	Operation           string  `json:"operation"`
+	LeaseContractLength string  `json:"leaseContractLength"`
+	PurchaseOption      string  `json:"purchaseOption"`
+	Rate                float64 `json:"rate"`
+}
+
+// IngestCommitments ingests the reserved instance offerings of the ec2 and rds price lists and the savings plans rates.
+func (s *Service) IngestCommitments(ctx context.Context) error {
+	version := s.Catalog.Source().Version()
+	priceTable, err := s.awsCommitmentPriceRepo.CreateNewTable()
+	if err != nil {
+		s.logger.Error("failed to auto migrate",
+			zap.String("table", "aws_commitment_prices"),
+			zap.Error(err))
+		return err
+	}
+
+	var transaction *gorm.DB
+	for _, file := range []CatalogFile{CatalogAWSEC2PriceList, CatalogAWSRDSPriceList} {
+		// the upfront fee and the hourly rate of an offering are separate rows
+		offerings := make(map[string]*model.AWSCommitmentPrice)
+		err = s.readPriceList(ctx, file, func(columns map[string]int, row []string) error {
+			v := model.AWSCommitmentPrice{}
+			v.PopulateFromMap(columns, row)
+			if !v.DoIngest() {
+				return nil
+			}
+			if o, ok := offerings[v.OfferingKey()]; ok {
+				o.Merge(v)
+			} else {
+				offerings[v.OfferingKey()] = &v
+			}
+			return nil
+		})
+		if err != nil {
+			s.logger.Error("failed to read reserved instance offerings", zap.String("file", string(file)), zap.Error(err))
+			return err
+		}
+		for _, v := range offerings {
+			if err = s.awsCommitmentPriceRepo.Create(priceTable, transaction, v); err != nil {
+				return err
+			}
+		}
+	}
+
+	rates, err := loadCatalogJSON(ctx, s.Catalog.Source(), CatalogAWSSavingsPlansRates, fetchSavingsPlansRates)
+	if err != nil {
+		s.logger.Error("failed to load savings plans rates", zap.Error(err))
+		return err
+	}
+	for _, rate := range rates.Rates {
+		v := model.AWSCommitmentPrice{
+			CommitmentType:      rate.CommitmentType,
+			ServiceCode:         "AmazonEC2",
+			RegionCode:          rate.RegionCode,
+			InstanceType:        rate.InstanceType,
+			InstanceFamily:      rate.InstanceFamily,
+			Operation:           rate.Operation,
+			LeaseContractLength: rate.LeaseContractLength,
+			PurchaseOption:      rate.PurchaseOption,
+			HourlyRate:          rate.Rate,
+			PricePerUnit:        rate.Rate,
+			Unit:                "Hrs",
+			TermType:            "SavingsPlan",
+			Tenancy:             "Shared",
+		}
+		if err = s.awsCommitmentPriceRepo.Create(priceTable, transaction, &v); err != nil {
+			return err
+		}
+	}
+
+	return s.Catalog.Commit("AWS::Commitments", version, map[string]string{
+		CatalogViewAWSCommitmentPrices: priceTable,
+	})
+}
+
+// fetchSavingsPlansRates downloads the savings plans price list of every region, the rates are published per region
+// so they are kept in a single json file of the catalog.
+func fetchSavingsPlansRates(ctx context.Context) (savingsPlansRates, error) {
+	var result savingsPlansRates
+
+	body, err := httpGet(ctx, savingsPlansRegionIndexURL)
+	if err != nil {
+		return result, err
+	}
+	var index struct {
+		Regions []struct {
+			RegionCode string `json:"regionCode"`
+			VersionURL string `json:"versionUrl"`
+		} `json:"regions"`
+	}
+	err = json.NewDecoder(body).Decode(&index)
+	body.Close()
+	if err != nil {
+		return result, err
+	}
+
+	for _, region := range index.Regions {
+		url := "https://pricing.us-east-1.amazonaws.com" + strings.TrimSuffix(region.VersionURL, ".json") + ".csv"
+		rates, err := readSavingsPlansRates(ctx, region.RegionCode, url)
+		if err != nil {
+			return result, fmt.Errorf("failed to read savings plans rates of %s: %w", region.RegionCode, err)
+		}
+		result.Rates = append(result.Rates, rates...)
+	}
+	return result, nil
+}
+
+func readSavingsPlansRates(ctx context.Context, regionCode, url string) ([]savingsPlanRate, error) {
+	body, err := httpGet(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	csvr := csv.NewReader(body)
+	csvr.FieldsPerRecord = -1
+
+	var columns map[string]int
+	for {
+		values, err := csvr.Read()
+		if err != nil {
+			return nil, err
+		}
+		if len(values) > 2 {
+			columns = readColumnPositions(values)
+			break
+		}
+	}
+
+	var rates []savingsPlanRate
+	for {
+		row, err := csvr.Read()
+		if err != nil {
+			if err != io.EOF {
+				return nil, err
+			}
+			return rates, nil
+		}
+
+		if row[columns["DiscountedServiceCode"]] != "AmazonEC2" || !savingsPlansOperations[row[columns["DiscountedOperation"]]] {
+			continue
+		}
+		// BoxUsage is the usage of shared tenancy instances, e.g. USE1-BoxUsage:m5.large
+		_, instanceType, ok := strings.Cut(row[columns["DiscountedUsageType"]], "BoxUsage:")
+		if !ok {
+			continue
+		}
+		rate, err := strconv.ParseFloat(row[columns["DiscountedRate"]], 64)
+		if err != nil {
+			continue
+		}
+		instanceFamily, _, _ := strings.Cut(instanceType, ".")
+
+		rates = append(rates, savingsPlanRate{
+			CommitmentType:      row[columns["Product Family"]],
+			RegionCode:          regionCode,
+			InstanceType:        instanceType,
+			InstanceFamily:      instanceFamily,
+			Operation:           row[columns["DiscountedOperation"]],
+			LeaseContractLength: row[columns["LeaseContractLength"]] + "yr",
+			PurchaseOption:      row[columns["PurchaseOption"]],
+			Rate:                rate,
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package ingestion
+
+import (
+	"context"
+	"encoding/csv"
+	"github.com/opengovern/opengovernance/services/wastage/db/model"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"io"
+	"time"
+)
+
+// awsIdleResourceUsageTypes are the usage types billed for resources that keep costing money while they are idle.
+var awsIdleResourceUsageTypes = map[string]bool{
+	"PublicIPv4:IdleAddress":  true,
+	"PublicIPv4:InUseAddress": true,
+	"ElasticIP:IdleAddress":   true,
+	"NatGateway-Hours":        true,
+	"NatGateway-Bytes":        true,
+	"LoadBalancerUsage":       true,
+	"EBS:SnapshotUsage":       true,
+}
+
+func (s *Service) ingestIfStale(ctx context.Context, dataType string, maxAge time.Duration, ingest func(ctx context.Context) error, files ...CatalogFile) {
+	ok, err := s.Catalog.ShouldIngest(dataType, maxAge, files...)
+	if err != nil {
+		s.logger.Error("failed to check data age", zap.String("dataType", dataType), zap.Error(err))
+		return
+	}
+	if !ok {
+		s.logger.Info("ingest not started", zap.String("dataType", dataType))
+		return
+	}
+
+	s.logger.Info("ingest started", zap.String("dataType", dataType))
+	if err = ingest(ctx); err != nil {
+		s.logger.Error("failed to ingest", zap.String("dataType", dataType), zap.Error(err))
+	}
+}
+
+// readPriceList calls fn for every price row of an AWS price list csv file.
+func (s *Service) readPriceList(ctx context.Context, file CatalogFile, fn func(columns map[string]int, row []string) error) error {
+	priceList, err := s.Catalog.Source().Open(ctx, file)
+	if err != nil {
+		return err
+	}
+	defer priceList.Close()
+
+	csvr := csv.NewReader(priceList)
+	csvr.FieldsPerRecord = -1
+
+	var columns map[string]int
+	for {
+		values, err := csvr.Read()
+		if err != nil {
+			return err
+		}
+
+		if len(values) > 2 {
+			columns = readColumnPositions(values)
+			break
+		}
+	}
+
+	for {
+		row, err := csvr.Read()
+		if err != nil {
+			if err != io.EOF {
+				return err
+			}
+			return nil
+		}
+		if err = fn(columns, row); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Service) IngestElastiCache(ctx context.Context) error {
+	version := s.Catalog.Source().Version()
+	nodeTypeTable, err := s.elastiCacheNodeTypeRepo.CreateNewTable()
+	if err != nil {
+		s.logger.Error("failed to auto migrate",
+			zap.String("table", "elasticache_node_types"),
+			zap.Error(err))
+		return err
+	}
+
+	var transaction *gorm.DB
+	err = s.readPriceList(ctx, CatalogAWSElastiCachePriceList, func(columns map[string]int, row []string) error {
+		v := model.ElastiCacheNodeType{}
+		v.PopulateFromMap(columns, row)
+		if !v.DoIngest() {
+			return nil
+		}
+		return s.elastiCacheNodeTypeRepo.Create(nodeTypeTable, transaction, &v)
+	})
+	if err != nil {
+		s.logger.Error("failed to ingest elasticache prices", zap.Error(err))
+		return err
+	}
+
+	return s.Catalog.Commit("AWS::ElastiCache::CacheCluster", version, map[string]string{
+		CatalogViewElastiCacheNodeTypes: nodeTypeTable,
+	})
+}
+
+func (s *Service) IngestLambda(ctx context.Context) error {
+	version := s.Catalog.Source().Version()
+	priceTable, err := s.lambdaPriceRepo.CreateNewTable()
+	if err != nil {
+		s.logger.Error("failed to auto migrate",
+			zap.String("table", "lambda_prices"),
+			zap.Error(err))
+		return err
+	}
+
+	var transaction *gorm.DB
+	err = s.readPriceList(ctx, CatalogAWSLambdaPriceList, func(columns map[string]int, row []string) error {
+		v := model.LambdaPrice{}
+		v.PopulateFromMap(columns, row)
+		if !v.DoIngest() {
+			return nil
+		}
+		return s.lambdaPriceRepo.Create(priceTable, transaction, &v)
+	})
+	if err != nil {
+		s.logger.Error("failed to ingest lambda prices", zap.Error(err))
+		return err
+	}
+
+	return s.Catalog.Commit("AWS::Lambda::Function", version, map[string]string{
+		CatalogViewLambdaPrices: priceTable,
+	})
+}
+
+func (s *Service) IngestDynamoDB(ctx context.Context) error {
+	version := s.Catalog.Source().Version()
+	priceTable, err := s.dynamoDBPriceRepo.CreateNewTable()
+	if err != nil {
+		s.logger.Error("failed to auto migrate",
+			zap.String("table", "dynamodb_prices"),
+			zap.Error(err))
+		return err
+	}
+
+	var transaction *gorm.DB
+	err = s.readPriceList(ctx, CatalogAWSDynamoDBPriceList, func(columns map[string]int, row []string) error {
+		v := model.DynamoDBPrice{}
+		v.PopulateFromMap(columns, row)
+		if !v.DoIngest() {
+			return nil
+		}
+		return s.dynamoDBPriceRepo.Create(priceTable, transaction, &v)
+	})
+	if err != nil {
+		s.logger.Error("failed to ingest dynamodb prices", zap.Error(err))
+		return err
+	}
+
+	return s.Catalog.Commit("AWS::DynamoDB::Table", version, map[string]string{
+		CatalogViewDynamoDBPrices: priceTable,
+	})
+}
+
+// IngestIdleResources ingests the prices of public IPv4 addresses from the VPC price list, load balancers from the
+// ELB price list and NAT gateways and EBS snapshots from the EC2 price list.
+func (s *Service) IngestIdleResources(ctx context.Context) error {
+	version := s.Catalog.Source().Version()
+	priceTable, err := s.awsIdleResourcePriceRepo.CreateNewTable()
+	if err != nil {
+		s.logger.Error("failed to auto migrate",
+			zap.String("table", "aws_idle_resource_prices"),
+			zap.Error(err))
+		return err
+	}
+
+	var transaction *gorm.DB
+	for _, file := range []CatalogFile{CatalogAWSVPCPriceList, CatalogAWSELBPriceList, CatalogAWSEC2PriceList} {
+		err = s.readPriceList(ctx, file, func(columns map[string]int, row []string) error {
+			v := model.AWSIdleResourcePrice{}
+			v.PopulateFromMap(columns, row)
+			if !v.DoIngest() || !awsIdleResourceUsageTypes[v.UsageType] {
+				return nil
+			}
+			return s.awsIdleResourcePriceRepo.Create(priceTable, transaction, &v)
+		})
+		if err != nil {
+			s.logger.Error("failed to ingest idle resource prices", zap.String("file", string(file)), zap.Error(err))
+			return err
+		}
+	}
+
+	return s.Catalog.Commit("AWS::IdleResources", version, map[string]string{
+		CatalogViewAWSIdleResourcePrices: priceTable,
+	})
+}
@@ -0,0 +1,372 @@
+package ingestion
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/opengovern/opengovernance/services/wastage/db/connector"
+	"github.com/opengovern/opengovernance/services/wastage/db/model"
+	"github.com/opengovern/opengovernance/services/wastage/db/repo"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	azureRetailPricesURL = "https://prices.azure.com/api/retail/prices"
+	azureResourceSKUsURL = "https://management.azure.com/subscriptions/%s/providers/Microsoft.Compute/skus?api-version=2021-07-01"
+	azureManagementScope = "https://management.azure.com/.default"
+)
+
+var (
+	azureManagedDiskProducts = map[string]string{
+		"Premium SSD Managed Disks":  "Premium_LRS",
+		"Standard SSD Managed Disks": "StandardSSD_LRS",
+		"Standard HDD Managed Disks": "Standard_LRS",
+	}
+
+	// azureManagedDiskLimits holds the provisioned size and the baseline performance of each fixed size
+	// managed disk, as published in https://learn.microsoft.com/en-us/azure/virtual-machines/disks-types.
+	azureManagedDiskLimits = map[string]struct {
+		SizeGb             int64
+		BaseIops           int64
+		BaseThroughputMBps int64
+	}{
+		"P1": {4, 120, 25}, "P2": {8, 120, 25}, "P3": {16, 120, 25}, "P4": {32, 120, 25},
+		"P6": {64, 240, 50}, "P10": {128, 500, 100}, "P15": {256, 1100, 125}, "P20": {512, 2300, 150},
+		"P30": {1024, 5000, 200}, "P40": {2048, 7500, 250}, "P50": {4096, 7500, 250}, "P60": {8192, 16000, 500},
+		"P70": {16384, 18000, 750}, "P80": {32767, 20000, 900},
+
+		"E1": {4, 500, 60}, "E2": {8, 500, 60}, "E3": {16, 500, 60}, "E4": {32, 500, 60},
+		"E6": {64, 500, 60}, "E10": {128, 500, 60}, "E15": {256, 500, 60}, "E20": {512, 500, 60},
+		"E30": {1024, 500, 60}, "E40": {2048, 500, 60}, "E50": {4096, 500, 60}, "E60": {8192, 2000, 400},
+		"E70": {16384, 4000, 600}, "E80": {32767, 6000, 750},
+
+		"S4": {32, 500, 60}, "S6": {64, 500, 60}, "S10": {128, 500, 60}, "S15": {256, 500, 60},
+		"S20": {512, 500, 60}, "S30": {1024, 500, 60}, "S40": {2048, 500, 60}, "S50": {4096, 500, 60},
+		"S60": {8192, 1300, 300}, "S70": {16384, 2000, 500}, "S80": {32767, 2000, 500},
+	}
+)
+
+type azureRetailPrice struct {
+	RetailPrice          float64 `json:"retailPrice"`
+	ArmRegionName        string  `json:"armRegionName"`
+	ProductName          string  `json:"productName"`
+	SkuName              string  `json:"skuName"`
+	MeterName            string  `json:"meterName"`
+	ArmSkuName           string  `json:"armSkuName"`
+	UnitOfMeasure        string  `json:"unitOfMeasure"`
+	Type                 string  `json:"type"`
+	IsPrimaryMeterRegion bool    `json:"isPrimaryMeterRegion"`
+}
+
+type azureRetailPricesPage struct {
+	Items        []azureRetailPrice `json:"Items"`
+	NextPageLink string             `json:"NextPageLink"`
+}
+
+type azureResourceSKU struct {
+	ResourceType string `json:"resourceType"`
+	Name         string `json:"name"`
+	Family       string `json:"family"`
+	Capabilities []struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	} `json:"capabilities"`
+}
+
+type azureResourceSKUsPage struct {
+	Value    []azureResourceSKU `json:"value"`
+	NextLink string             `json:"nextLink"`
+}
+
+type AzureService struct {
+	logger *zap.Logger
+
+	credential     azcore.TokenCredential
+	subscriptionID string
+
+	DataAgeRepo repo.DataAgeRepo
+	Catalog     *CatalogManager
+
+	db                  *connector.Database
+	vmSKURepo           repo.AzureVMSKURepo
+	managedDiskTypeRepo repo.AzureManagedDiskTypeRepo
+}
+
+func NewAzureService(logger *zap.Logger, dataAgeRepo repo.DataAgeRepo, vmSKURepo repo.AzureVMSKURepo, managedDiskTypeRepo repo.AzureManagedDiskTypeRepo,
+	db *connector.Database, catalog *CatalogManager, credential azcore.TokenCredential, subscriptionID string) *AzureService {
+	return &AzureService{
+		logger:              logger,
+		credential:          credential,
+		subscriptionID:      subscriptionID,
+		DataAgeRepo:         dataAgeRepo,
+		Catalog:             catalog,
+		db:                  db,
+		vmSKURepo:           vmSKURepo,
+		managedDiskTypeRepo: managedDiskTypeRepo,
+	}
+}
+
+func (s *AzureService) Start(ctx context.Context) {
+	s.logger.Info("Azure Ingestion service started")
+	defer func() {
+		if r := recover(); r != nil {
+			s.logger.Error("azure ingestion paniced", zap.Error(fmt.Errorf("%v", r)))
+			time.Sleep(15 * time.Minute)
+			go s.Start(ctx)
+		}
+	}()
+
+	ticker := time.NewTicker(2 * time.Minute)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		s.logger.Info("checking data age")
+		ingest, err := s.Catalog.ShouldIngest("AzureVirtualMachines", 30*24*time.Hour,
+			CatalogAzureResourceSKUs, CatalogAzureVMPrices, CatalogAzureManagedDiskPrices)
+		if err != nil {
+			s.logger.Error("failed to check azure virtual machines data age", zap.Error(err))
+			continue
+		}
+		if ingest {
+			s.logger.Info("azure virtual machines ingest started")
+			err = s.IngestVirtualMachines(ctx)
+			if err != nil {
+				s.logger.Error("failed to ingest azure virtual machines", zap.Error(err))
+				continue
+			}
+		} else {
+			s.logger.Info("azure virtual machines ingest not started")
+		}
+	}
+}
+
+func (s *AzureService) IngestVirtualMachines(ctx context.Context) error {
+	version := s.Catalog.Source().Version()
+	vmSKUTable, err := s.vmSKURepo.CreateNewTable()
+	if err != nil {
+		s.logger.Error("failed to auto migrate",
+			zap.String("table", "azure_vm_sku"),
+			zap.Error(err))
+		return err
+	}
+
+	managedDiskTable, err := s.managedDiskTypeRepo.CreateNewTable()
+	if err != nil {
+		s.logger.Error("failed to auto migrate",
+			zap.String("table", "azure_managed_disk_type"),
+			zap.Error(err))
+		return err
+	}
+
+	var transaction *gorm.DB
+
+	resourceSKUs, err := loadCatalogJSON(ctx, s.Catalog.Source(), CatalogAzureResourceSKUs, s.fetchResourceSKUs)
+	if err != nil {
+		s.logger.Error("failed to fetch resource skus", zap.Error(err))
+		return err
+	}
+	s.logger.Info("fetched resource skus", zap.Int("count", len(resourceSKUs)))
+
+	vmPrices, err := loadCatalogJSON(ctx, s.Catalog.Source(), CatalogAzureVMPrices, s.fetchVMPrices)
+	if err != nil {
+		s.logger.Error("failed to fetch virtual machine prices", zap.Error(err))
+		return err
+	}
+	for _, price := range vmPrices {
+		if !price.IsPrimaryMeterRegion || price.ArmSkuName == "" || price.UnitOfMeasure != "1 Hour" {
+			continue
+		}
+		if strings.Contains(price.SkuName, "Low Priority") {
+			continue
+		}
+		sku, ok := resourceSKUs[strings.ToLower(price.ArmSkuName)]
+		if !ok {
+			continue
+		}
+
+		v := model.AzureVMSKU{
+			Name:            price.ArmSkuName,
+			Region:          price.ArmRegionName,
+			OperatingSystem: "Linux",
+			Spot:            strings.Contains(price.SkuName, "Spot"),
+			UnitPrice:       price.RetailPrice,
+		}
+		if strings.Contains(price.ProductName, "Windows") {
+			v.OperatingSystem = "Windows"
+		}
+		capabilities := make(map[string]string)
+		for _, c := range sku.Capabilities {
+			capabilities[c.Name] = c.Value
+		}
+		v.PopulateFromCapabilities(sku.Family, capabilities)
+
+		err = s.vmSKURepo.Create(vmSKUTable, transaction, &v)
+		if err != nil {
+			s.logger.Error("failed to create azure vm sku", zap.Error(err))
+			continue
+		}
+	}
+
+	diskPrices, err := loadCatalogJSON(ctx, s.Catalog.Source(), CatalogAzureManagedDiskPrices, s.fetchManagedDiskPrices)
+	if err != nil {
+		s.logger.Error("failed to fetch managed disk prices", zap.Error(err))
+		return err
+	}
+	for _, price := range diskPrices {
+		tier, ok := azureManagedDiskProducts[price.ProductName]
+		if !ok {
+			continue
+		}
+		if price.UnitOfMeasure != "1/Month" || !strings.HasSuffix(price.MeterName, "Disk") && !strings.HasSuffix(price.MeterName, "Disks") {
+			continue
+		}
+		parts := strings.Fields(price.SkuName)
+		if len(parts) != 2 || parts[1] != "LRS" {
+			continue
+		}
+		limits, ok := azureManagedDiskLimits[parts[0]]
+		if !ok {
+			continue
+		}
+
+		disk := model.AzureManagedDiskType{
+			Name:               parts[0],
+			Tier:               tier,
+			Region:             price.ArmRegionName,
+			SizeGb:             limits.SizeGb,
+			BaseIops:           limits.BaseIops,
+			BaseThroughputMBps: limits.BaseThroughputMBps,
+			UnitPrice:          price.RetailPrice,
+		}
+		err = s.managedDiskTypeRepo.Create(managedDiskTable, transaction, &disk)
+		if err != nil {
+			s.logger.Error("failed to create azure managed disk type", zap.Error(err))
+			continue
+		}
+	}
+
+	err = s.Catalog.Commit("AzureVirtualMachines", version, map[string]string{
+		CatalogViewAzureVMSKUs:           vmSKUTable,
+		CatalogViewAzureManagedDiskTypes: managedDiskTable,
+	})
+	if err != nil {
+		s.logger.Error("failed to commit azure virtual machines catalog", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// WriteBundle fetches the virtual machine resource skus and the virtual machine and managed disk prices into a pricing bundle.
+func (s *AzureService) WriteBundle(ctx context.Context, w *BundleWriter) error {
+	resourceSKUs, err := s.fetchResourceSKUs(ctx)
+	if err != nil {
+		return err
+	}
+	if err = w.AddJSON(CatalogAzureResourceSKUs, resourceSKUs); err != nil {
+		return err
+	}
+
+	vmPrices, err := s.fetchVMPrices(ctx)
+	if err != nil {
+		return err
+	}
+	if err = w.AddJSON(CatalogAzureVMPrices, vmPrices); err != nil {
+		return err
+	}
+
+	diskPrices, err := s.fetchManagedDiskPrices(ctx)
+	if err != nil {
+		return err
+	}
+	return w.AddJSON(CatalogAzureManagedDiskPrices, diskPrices)
+}
+
+func (s *AzureService) fetchVMPrices(ctx context.Context) ([]azureRetailPrice, error) {
+	return s.fetchRetailPrices(ctx, "serviceName eq 'Virtual Machines' and priceType eq 'Consumption'")
+}
+
+func (s *AzureService) fetchManagedDiskPrices(ctx context.Context) ([]azureRetailPrice, error) {
+	var results []azureRetailPrice
+	for product := range azureManagedDiskProducts {
+		prices, err := s.fetchRetailPrices(ctx, fmt.Sprintf("serviceName eq 'Storage' and priceType eq 'Consumption' and productName eq '%s'", product))
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, prices...)
+	}
+	return results, nil
+}
+
+func (s *AzureService) fetchRetailPrices(ctx context.Context, filter string) ([]azureRetailPrice, error) {
+	var results []azureRetailPrice
+
+	next := fmt.Sprintf("%s?$filter=%s", azureRetailPricesURL, url.QueryEscape(filter))
+	for next != "" {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, next, nil)
+		if err != nil {
+			return nil, err
+		}
+		var page azureRetailPricesPage
+		if err = doAzureRequest(req, &page); err != nil {
+			return nil, err
+		}
+		results = append(results, page.Items...)
+		next = page.NextPageLink
+	}
+
+	return results, nil
+}
+
+func (s *AzureService) fetchResourceSKUs(ctx context.Context) (map[string]azureResourceSKU, error) {
+	token, err := s.credential.GetToken(ctx, policy.TokenRequestOptions{Scopes: []string{azureManagementScope}})
+	if err != nil {
+		return nil, err
+	}
+
+	results := make(map[string]azureResourceSKU)
+	next := fmt.Sprintf(azureResourceSKUsURL, s.subscriptionID)
+	for next != "" {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, next, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+token.Token)
+		var page azureResourceSKUsPage
+		if err = doAzureRequest(req, &page); err != nil {
+			return nil, err
+		}
+		for _, sku := range page.Value {
+			if sku.ResourceType != "virtualMachines" {
+				continue
+			}
+			if _, ok := results[strings.ToLower(sku.Name)]; ok {
+				continue
+			}
+			results[strings.ToLower(sku.Name)] = sku
+		}
+		next = page.NextLink
+	}
+
+	return results, nil
+}
+
+func doAzureRequest(req *http.Request, out any) error {
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("request to %s failed with status code %d", req.URL.Host, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
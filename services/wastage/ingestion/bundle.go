@@ -0,0 +1,289 @@
+package ingestion
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const BundleManifestFile = "manifest.json"
+
+type BundleManifest struct {
+	Version   string               `json:"version"`
+	CreatedAt time.Time            `json:"createdAt"`
+	Files     []BundleManifestItem `json:"files"`
+}
+
+type BundleManifestItem struct {
+	Name   CatalogFile `json:"name"`
+	SHA256 string      `json:"sha256"`
+	Size   int64       `json:"size"`
+}
+
+// BundleSource serves the price files from a bundle built with the bundle subcommand, either extracted in a
+// directory or as a .tar.gz file. Every file is checked against the checksum in the manifest when the source is opened.
+type BundleSource struct {
+	dir      string
+	manifest BundleManifest
+}
+
+func NewBundleSource(path string) (*BundleSource, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	dir := path
+	if !info.IsDir() {
+		dir, err = os.MkdirTemp("", "wastage-pricing-bundle-")
+		if err != nil {
+			return nil, err
+		}
+		if err = extractBundle(path, dir); err != nil {
+			return nil, fmt.Errorf("failed to extract pricing bundle %s: %w", path, err)
+		}
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, BundleManifestFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pricing bundle manifest: %w", err)
+	}
+	var manifest BundleManifest
+	if err = json.Unmarshal(content, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse pricing bundle manifest: %w", err)
+	}
+	if manifest.Version == "" {
+		return nil, errors.New("pricing bundle manifest has no version")
+	}
+
+	s := &BundleSource{
+		dir:      dir,
+		manifest: manifest,
+	}
+	if err = s.Verify(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *BundleSource) Name() string {
+	return PricingSourceBundle
+}
+
+func (s *BundleSource) Version() string {
+	return s.manifest.Version
+}
+
+func (s *BundleSource) Manifest() BundleManifest {
+	return s.manifest
+}
+
+// Verify checks the size and checksum of every file listed in the manifest.
+func (s *BundleSource) Verify() error {
+	for _, item := range s.manifest.Files {
+		sum, size, err := hashFile(filepath.Join(s.dir, string(item.Name)))
+		if err != nil {
+			return fmt.Errorf("failed to read %s from pricing bundle: %w", item.Name, err)
+		}
+		if size != item.Size || sum != item.SHA256 {
+			return fmt.Errorf("checksum mismatch for %s in pricing bundle %s", item.Name, s.manifest.Version)
+		}
+	}
+	return nil
+}
+
+func (s *BundleSource) Has(file CatalogFile) bool {
+	for _, item := range s.manifest.Files {
+		if item.Name == file {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *BundleSource) Open(_ context.Context, file CatalogFile) (io.ReadCloser, error) {
+	for _, item := range s.manifest.Files {
+		if item.Name == file {
+			return os.Open(filepath.Join(s.dir, string(item.Name)))
+		}
+	}
+	return nil, fmt.Errorf("%s is not in pricing bundle %s", file, s.manifest.Version)
+}
+
+// BundleWriter writes price files into a directory and keeps track of them in the manifest.
+type BundleWriter struct {
+	dir      string
+	manifest BundleManifest
+}
+
+func NewBundleWriter(dir, version string) (*BundleWriter, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &BundleWriter{
+		dir: dir,
+		manifest: BundleManifest{
+			Version:   version,
+			CreatedAt: time.Now().UTC(),
+		},
+	}, nil
+}
+
+func (w *BundleWriter) AddFile(name CatalogFile, r io.Reader) error {
+	path := filepath.Join(w.dir, string(name))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	size, err := io.Copy(io.MultiWriter(f, h), r)
+	if err != nil {
+		return err
+	}
+	w.manifest.Files = append(w.manifest.Files, BundleManifestItem{
+		Name:   name,
+		SHA256: hex.EncodeToString(h.Sum(nil)),
+		Size:   size,
+	})
+	return nil
+}
+
+func (w *BundleWriter) AddJSON(name CatalogFile, v any) error {
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(json.NewEncoder(pw).Encode(v))
+	}()
+	return w.AddFile(name, pr)
+}
+
+// Close writes the manifest, the directory is a valid bundle afterwards.
+func (w *BundleWriter) Close() error {
+	content, err := json.MarshalIndent(w.manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(w.dir, BundleManifestFile), content, 0o644)
+}
+
+// PackBundle archives a bundle directory into a .tar.gz file.
+func PackBundle(dir, output string) error {
+	f, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gw := gzip.NewWriter(f)
+	tw := tar.NewWriter(gw)
+
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		hdr, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		hdr.Name = filepath.ToSlash(rel)
+		if err = tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		src, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+		_, err = io.Copy(tw, src)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if err = tw.Close(); err != nil {
+		return err
+	}
+	return gw.Close()
+}
+
+func extractBundle(path, dir string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer gr.Close()
+
+	tr := tar.NewReader(gr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		target := filepath.Join(dir, filepath.FromSlash(hdr.Name))
+		if !strings.HasPrefix(target, filepath.Clean(dir)+string(os.PathSeparator)) {
+			return fmt.Errorf("invalid file path %s in pricing bundle", hdr.Name)
+		}
+		if err = os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return err
+		}
+		out, err := os.Create(target)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(out, tr)
+		out.Close()
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func hashFile(path string) (string, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(h.Sum(nil)), size, nil
+}
@@ -0,0 +1,60 @@
+package ingestion
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBundleRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+
+	w, err := NewBundleWriter(filepath.Join(dir, "bundle"), "2024.06")
+	require.NoError(t, err)
+	require.NoError(t, w.AddFile(CatalogAWSEC2PriceList, strings.NewReader("a,b,c\n1,2,3\n")))
+	require.NoError(t, w.AddJSON(CatalogGCPComputeSKUs, []string{"sku-1", "sku-2"}))
+	require.NoError(t, w.Close())
+
+	archive := filepath.Join(dir, "bundle.tar.gz")
+	require.NoError(t, PackBundle(filepath.Join(dir, "bundle"), archive))
+
+	source, err := NewBundleSource(archive)
+	require.NoError(t, err)
+	assert.Equal(t, PricingSourceBundle, source.Name())
+	assert.Equal(t, "2024.06", source.Version())
+	assert.True(t, source.Has(CatalogAWSEC2PriceList))
+	assert.False(t, source.Has(CatalogAzureVMPrices))
+
+	r, err := source.Open(context.Background(), CatalogAWSEC2PriceList)
+	require.NoError(t, err)
+	content, err := io.ReadAll(r)
+	r.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "a,b,c\n1,2,3\n", string(content))
+
+	skus, err := loadCatalogJSON(context.Background(), source, CatalogGCPComputeSKUs, func(ctx context.Context) ([]string, error) {
+		t.Fatal("fetch must not be called for files in the bundle")
+		return nil, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sku-1", "sku-2"}, skus)
+}
+
+func TestBundleChecksumMismatch(t *testing.T) {
+	dir := t.TempDir()
+
+	w, err := NewBundleWriter(dir, "2024.06")
+	require.NoError(t, err)
+	require.NoError(t, w.AddFile(CatalogAWSRDSPriceList, strings.NewReader("original")))
+	require.NoError(t, w.Close())
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, string(CatalogAWSRDSPriceList)), []byte("tampered"), 0o644))
+
+	_, err = NewBundleSource(dir)
+	assert.ErrorContains(t, err, "checksum mismatch")
+}
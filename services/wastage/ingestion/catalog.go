@@ -0,0 +1,242 @@
+package ingestion
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/opengovern/opengovernance/services/wastage/db/model"
+	"github.com/opengovern/opengovernance/services/wastage/db/repo"
+	"go.uber.org/zap"
+	"time"
+)
+
+// Names of the catalog views, used as keys of the tables map passed to CatalogManager.Commit.
+const (
+	CatalogViewEC2InstanceTypes       = "ec2_instance_types"
+	CatalogViewEBSVolumeTypes         = "ebs_volume_types"
+	CatalogViewRDSDBInstances         = "rds_db_instances"
+	CatalogViewRDSDBStorages          = "rds_db_storages"
+	CatalogViewRDSProducts            = "rds_products"
+	CatalogViewElastiCacheNodeTypes   = "elasticache_node_types"
+	CatalogViewLambdaPrices           = "lambda_prices"
+	CatalogViewDynamoDBPrices         = "dynamodb_prices"
+	CatalogViewAWSIdleResourcePrices  = "aws_idle_resource_prices"
+	CatalogViewAWSCommitmentPrices    = "aws_commitment_prices"
+	CatalogViewGCPComputeMachineTypes = "gcp_compute_machine_types"
+	CatalogViewGCPComputeDiskTypes    = "gcp_compute_disk_types"
+	CatalogViewGCPComputeSKUs         = "gcp_compute_skus"
+	CatalogViewAzureVMSKUs            = "azure_vm_skus"
+	CatalogViewAzureManagedDiskTypes  = "azure_managed_disk_types"
+)
+
+// catalogVersionsToKeep is the number of ingested versions whose tables are kept around for rollbacks.
+const catalogVersionsToKeep = 3
+
+var (
+	ErrCatalogVersionNotFound    = errors.New("catalog version not found")
+	ErrCatalogVersionNotRetained = errors.New("catalog version tables are no longer retained")
+)
+
+// CatalogView is a catalog table that is read through a view pointing to the ingested table of the current version.
+type CatalogView interface {
+	MoveViewTransaction(tableName string) error
+	RemoveOldTables(currentTableName string, keep ...string) error
+}
+
+// CatalogManager tracks which version of each catalog data type the views point to.
+type CatalogManager struct {
+	logger *zap.Logger
+
+	source      PricingSource
+	dataAgeRepo repo.DataAgeRepo
+	versionRepo repo.CatalogVersionRepo
+
+	views map[string]CatalogView
+}
+
+func NewCatalogManager(logger *zap.Logger, source PricingSource, dataAgeRepo repo.DataAgeRepo, versionRepo repo.CatalogVersionRepo) *CatalogManager {
+	return &CatalogManager{
+		logger:      logger.Named("catalog"),
+		source:      source,
+		dataAgeRepo: dataAgeRepo,
+		versionRepo: versionRepo,
+		views:       make(map[string]CatalogView),
+	}
+}
+
+func (m *CatalogManager) RegisterView(name string, view CatalogView) {
+	m.views[name] = view
+}
+
+func (m *CatalogManager) Source() PricingSource {
+	return m.source
+}
+
+// ShouldIngest tells whether dataType has to be ingested again. Pinned data types are never ingested, a bundle is
+// ingested once per version if it has all the files of the data type and the remote source whenever the current
+// data is older than maxAge.
+func (m *CatalogManager) ShouldIngest(dataType string, maxAge time.Duration, files ...CatalogFile) (bool, error) {
+	if bundle, ok := m.source.(*BundleSource); ok {
+		for _, f := range files {
+			if !bundle.Has(f) {
+				m.logger.Info("catalog file is not in the pricing bundle", zap.String("dataType", dataType), zap.String("file", string(f)))
+				return false, nil
+			}
+		}
+	}
+
+	dataAge, err := m.dataAgeRepo.Get(dataType)
+	if err != nil {
+		return false, err
+	}
+	if dataAge == nil {
+		return true, nil
+	}
+	if dataAge.Pinned {
+		m.logger.Info("catalog is pinned", zap.String("dataType", dataType), zap.String("version", dataAge.Version))
+		return false, nil
+	}
+	if m.source.Name() == PricingSourceBundle {
+		return dataAge.Version != m.source.Version() || dataAge.Source != m.source.Name(), nil
+	}
+	return dataAge.UpdatedAt.Before(time.Now().Add(-maxAge)), nil
+}
+
+// Commit points the views of dataType to the freshly ingested tables, drops the tables of versions that are
+// no longer retained and records the new version.
+func (m *CatalogManager) Commit(dataType, version string, tables map[string]string) error {
+	previous, err := m.versionRepo.List(dataType, catalogVersionsToKeep-1)
+	if err != nil {
+		return err
+	}
+
+	for name, table := range tables {
+		view, ok := m.views[name]
+		if !ok {
+			return fmt.Errorf("catalog view %s is not registered", name)
+		}
+		if err = view.MoveViewTransaction(table); err != nil {
+			return err
+		}
+
+		var keep []string
+		for _, p := range previous {
+			if t, ok := p.Tables[name]; ok {
+				keep = append(keep, t)
+			}
+		}
+		if err = view.RemoveOldTables(table, keep...); err != nil {
+			return err
+		}
+	}
+
+	err = m.versionRepo.Create(&model.CatalogVersion{
+		DataType: dataType,
+		Version:  version,
+		Source:   m.source.Name(),
+		Tables:   tables,
+	})
+	if err != nil {
+		return err
+	}
+
+	return m.setCurrent(dataType, version, m.source.Name(), false)
+}
+
+func (m *CatalogManager) ListVersions(dataType string) ([]model.CatalogVersion, error) {
+	return m.versionRepo.List(dataType, 0)
+}
+
+func (m *CatalogManager) Current(dataType string) (*model.DataAge, error) {
+	return m.dataAgeRepo.Get(dataType)
+}
+
+func (m *CatalogManager) SetPinned(dataType string, pinned bool) error {
+	dataAge, err := m.dataAgeRepo.Get(dataType)
+	if err != nil {
+		return err
+	}
+	if dataAge == nil {
+		return ErrCatalogVersionNotFound
+	}
+	return m.dataAgeRepo.SetPinned(dataType, pinned)
+}
+
+// Rollback points the views of dataType back to the tables of a previously ingested version and pins it, so the
+// next ingestion does not replace it.
+func (m *CatalogManager) Rollback(dataType, version string) error {
+	v, err := m.versionRepo.Get(dataType, version)
+	if err != nil {
+		return err
+	}
+	if v == nil {
+		return ErrCatalogVersionNotFound
+	}
+
+	for name, table := range v.Tables {
+		if _, ok := m.views[name]; !ok {
+			return fmt.Errorf("catalog view %s is not registered", name)
+		}
+		exists, err := m.versionRepo.TableExists(table)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrCatalogVersionNotRetained
+		}
+	}
+	for name, table := range v.Tables {
+		if err = m.views[name].MoveViewTransaction(table); err != nil {
+			return err
+		}
+	}
+
+	m.logger.Info("catalog rolled back", zap.String("dataType", dataType), zap.String("version", version))
+	return m.setCurrent(dataType, v.Version, v.Source, true)
+}
+
+func (m *CatalogManager) setCurrent(dataType, version, source string, pinned bool) error {
+	dataAge, err := m.dataAgeRepo.Get(dataType)
+	if err != nil {
+		return err
+	}
+	if dataAge == nil {
+		return m.dataAgeRepo.Create(&model.DataAge{
+			DataType:  dataType,
+			UpdatedAt: time.Now(),
+			Version:   version,
+			Source:    source,
+			Pinned:    pinned,
+		})
+	}
+
+	err = m.dataAgeRepo.Update(dataType, model.DataAge{
+		DataType:  dataType,
+		UpdatedAt: time.Now(),
+		Version:   version,
+		Source:    source,
+	})
+	if err != nil {
+		return err
+	}
+	return m.dataAgeRepo.SetPinned(dataType, pinned)
+}
+
+// loadCatalogJSON reads a JSON catalog file from the pricing source, or calls fetch when the source does not serve it.
+func loadCatalogJSON[T any](ctx context.Context, source PricingSource, file CatalogFile, fetch func(ctx context.Context) (T, error)) (T, error) {
+	var result T
+	r, err := source.Open(ctx, file)
+	if errors.Is(err, ErrNotInSource) {
+		return fetch(ctx)
+	}
+	if err != nil {
+		return result, err
+	}
+	defer r.Close()
+
+	if err = json.NewDecoder(r).Decode(&result); err != nil {
+		return result, fmt.Errorf("failed to decode %s: %w", file, err)
+	}
+	return result, nil
+}
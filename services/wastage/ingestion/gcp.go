@@ -35,6 +35,7 @@ type GcpService struct {
 	project    string
 
 	DataAgeRepo repo.DataAgeRepo
+	Catalog     *CatalogManager
 
 	db                     *connector.Database
 	computeMachineTypeRepo repo.GCPComputeMachineTypeRepo
@@ -43,7 +44,7 @@ type GcpService struct {
 }
 
 func NewGcpService(ctx context.Context, logger *zap.Logger, dataAgeRepo repo.DataAgeRepo, computeMachineTypeRepo repo.GCPComputeMachineTypeRepo,
-	computeStorageTypeRepo repo.GCPComputeDiskTypeRepo, computeSKURepo repo.GCPComputeSKURepo, db *connector.Database, gcpCredentials map[string]string, projectId string) (*GcpService, error) {
+	computeStorageTypeRepo repo.GCPComputeDiskTypeRepo, computeSKURepo repo.GCPComputeSKURepo, db *connector.Database, catalog *CatalogManager, gcpCredentials map[string]string, projectId string) (*GcpService, error) {
 	configJson, err := json.Marshal(gcpCredentials)
 	if err != nil {
 		return nil, err
@@ -64,6 +65,7 @@ func NewGcpService(ctx context.Context, logger *zap.Logger, dataAgeRepo repo.Dat
 	return &GcpService{
 		logger:                 logger,
 		DataAgeRepo:            dataAgeRepo,
+		Catalog:                catalog,
 		db:                     db,
 		apiService:             apiService,
 		compute:                compute,
@@ -89,54 +91,27 @@ func (s *GcpService) Start(ctx context.Context) {
 
 	for range ticker.C {
 		s.logger.Info("checking data age")
-		dataAge, err := s.DataAgeRepo.List()
+		ingest, err := s.Catalog.ShouldIngest("GCPComputeEngine", 365*24*time.Hour,
+			CatalogGCPComputeSKUs, CatalogGCPComputeMachineTypes, CatalogGCPComputeDiskTypes)
 		if err != nil {
-			s.logger.Error("failed to list data age", zap.Error(err))
+			s.logger.Error("failed to check gcp compute engine data age", zap.Error(err))
 			continue
 		}
-
-		var computeData *model.DataAge
-		for _, data := range dataAge {
-			data := data
-			switch data.DataType {
-			case "GCPComputeEngine":
-				computeData = &data
-			}
-		}
-
-		if computeData == nil || computeData.UpdatedAt.Before(time.Now().Add(-365*24*time.Hour)) {
+		if ingest {
 			s.logger.Info("gcp compute engine ingest started")
 			err = s.IngestComputeInstance(ctx)
 			if err != nil {
 				s.logger.Error("failed to ingest gcp compute engine", zap.Error(err))
 				continue
 			}
-			if computeData == nil {
-				err = s.DataAgeRepo.Create(&model.DataAge{
-					DataType:  "GCPComputeEngine",
-					UpdatedAt: time.Now(),
-				})
-				if err != nil {
-					s.logger.Error("failed to create data age", zap.Error(err))
-					continue
-				}
-			} else {
-				err = s.DataAgeRepo.Update("GCPComputeEngine", model.DataAge{
-					DataType:  "GCPComputeEngine",
-					UpdatedAt: time.Now(),
-				})
-				if err != nil {
-					s.logger.Error("failed to update data age", zap.Error(err))
-					continue
-				}
-			}
 		} else {
-			s.logger.Info("gcp compute engine ingest not started: ", zap.Any("usage", computeData))
+			s.logger.Info("gcp compute engine ingest not started")
 		}
 	}
 }
 
 func (s *GcpService) IngestComputeInstance(ctx context.Context) error {
+	version := s.Catalog.Source().Version()
 	computeMachineTypeTable, err := s.computeMachineTypeRepo.CreateNewTable()
 	if err != nil {
 		s.logger.Error("failed to auto migrate",
@@ -164,8 +139,9 @@ func (s *GcpService) IngestComputeInstance(ctx context.Context) error {
 	var transaction *gorm.DB
 	machineTypePrices := make(map[string]map[string]map[string]float64)
 	diskTypePrices := make(map[string]map[string]float64)
-	skus, err := s.fetchSKUs(ctx, services["ComputeEngine"])
+	skus, err := loadCatalogJSON(ctx, s.Catalog.Source(), CatalogGCPComputeSKUs, s.fetchComputeSKUs)
 	if err != nil {
+		s.logger.Error("failed to load skus", zap.Error(err))
 		return err
 	}
 	for _, sku := range skus {
@@ -243,7 +219,7 @@ func (s *GcpService) IngestComputeInstance(ctx context.Context) error {
 		}
 	}
 
-	types, err := s.fetchMachineTypes(ctx)
+	types, err := loadCatalogJSON(ctx, s.Catalog.Source(), CatalogGCPComputeMachineTypes, s.fetchMachineTypes)
 	if err != nil {
 		s.logger.Error("failed to fetch machine types", zap.Error(err))
 		return err
@@ -290,7 +266,7 @@ func (s *GcpService) IngestComputeInstance(ctx context.Context) error {
 		s.logger.Info("created compute machine type", zap.String("name", mt.Name))
 	}
 
-	diskTypes, err := s.fetchDiskTypes(ctx)
+	diskTypes, err := loadCatalogJSON(ctx, s.Catalog.Source(), CatalogGCPComputeDiskTypes, s.fetchDiskTypes)
 	if err != nil {
 		s.logger.Error("failed to fetch disk types", zap.Error(err))
 		return err
@@ -317,37 +293,46 @@ func (s *GcpService) IngestComputeInstance(ctx context.Context) error {
 		s.logger.Info("created compute storage type", zap.String("name", mt.Name))
 	}
 
-	err = s.computeMachineTypeRepo.MoveViewTransaction(computeMachineTypeTable)
+	err = s.Catalog.Commit("GCPComputeEngine", version, map[string]string{
+		CatalogViewGCPComputeMachineTypes: computeMachineTypeTable,
+		CatalogViewGCPComputeDiskTypes:    computeDiskTable,
+		CatalogViewGCPComputeSKUs:         computeSKUTable,
+	})
 	if err != nil {
+		s.logger.Error("failed to commit gcp compute engine catalog", zap.Error(err))
 		return err
 	}
 
-	err = s.computeMachineTypeRepo.RemoveOldTables(computeMachineTypeTable)
+	return nil
+}
+
+// WriteBundle fetches the compute engine skus, machine types and disk types into a pricing bundle.
+func (s *GcpService) WriteBundle(ctx context.Context, w *BundleWriter) error {
+	skus, err := s.fetchComputeSKUs(ctx)
 	if err != nil {
 		return err
 	}
-
-	err = s.computeDiskTypeRepo.MoveViewTransaction(computeDiskTable)
-	if err != nil {
+	if err = w.AddJSON(CatalogGCPComputeSKUs, skus); err != nil {
 		return err
 	}
 
-	err = s.computeDiskTypeRepo.RemoveOldTables(computeDiskTable)
+	types, err := s.fetchMachineTypes(ctx)
 	if err != nil {
 		return err
 	}
-
-	err = s.computeSKURepo.MoveViewTransaction(computeSKUTable)
-	if err != nil {
+	if err = w.AddJSON(CatalogGCPComputeMachineTypes, types); err != nil {
 		return err
 	}
 
-	err = s.computeSKURepo.RemoveOldTables(computeSKUTable)
+	diskTypes, err := s.fetchDiskTypes(ctx)
 	if err != nil {
 		return err
 	}
+	return w.AddJSON(CatalogGCPComputeDiskTypes, diskTypes)
+}
 
-	return nil
+func (s *GcpService) fetchComputeSKUs(ctx context.Context) ([]*cloudbilling.Sku, error) {
+	return s.fetchSKUs(ctx, services["ComputeEngine"])
 }
 
 func (s *GcpService) fetchSKUs(ctx context.Context, service string) ([]*cloudbilling.Sku, error) {
@@ -14,7 +14,6 @@ import (
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 	"io"
-	"net/http"
 	"strings"
 	"time"
 )
@@ -23,6 +22,7 @@ type Service struct {
 	logger *zap.Logger
 
 	DataAgeRepo repo.DataAgeRepo
+	Catalog     *CatalogManager
 
 	db                *connector.Database
 	ec2InstanceRepo   repo.EC2InstanceTypeRepo
@@ -30,9 +30,17 @@ type Service struct {
 	rdsInstanceRepo   repo.RDSDBInstanceRepo
 	ebsVolumeTypeRepo repo.EBSVolumeTypeRepo
 	storageRepo       repo.RDSDBStorageRepo
+
+	elastiCacheNodeTypeRepo  repo.ElastiCacheNodeTypeRepo
+	lambdaPriceRepo          repo.LambdaPriceRepo
+	dynamoDBPriceRepo        repo.DynamoDBPriceRepo
+	awsIdleResourcePriceRepo repo.AWSIdleResourcePriceRepo
+	awsCommitmentPriceRepo   repo.AWSCommitmentPriceRepo
 }
 
-func New(logger *zap.Logger, db *connector.Database, ec2InstanceRepo repo.EC2InstanceTypeRepo, rdsRepo repo.RDSProductRepo, rdsInstanceRepo repo.RDSDBInstanceRepo, storageRepo repo.RDSDBStorageRepo, ebsVolumeRepo repo.EBSVolumeTypeRepo, dataAgeRepo repo.DataAgeRepo) *Service {
+func New(logger *zap.Logger, db *connector.Database, ec2InstanceRepo repo.EC2InstanceTypeRepo, rdsRepo repo.RDSProductRepo, rdsInstanceRepo repo.RDSDBInstanceRepo, storageRepo repo.RDSDBStorageRepo, ebsVolumeRepo repo.EBSVolumeTypeRepo,
+	elastiCacheNodeTypeRepo repo.ElastiCacheNodeTypeRepo, lambdaPriceRepo repo.LambdaPriceRepo, dynamoDBPriceRepo repo.DynamoDBPriceRepo, awsIdleResourcePriceRepo repo.AWSIdleResourcePriceRepo,
+	awsCommitmentPriceRepo repo.AWSCommitmentPriceRepo, dataAgeRepo repo.DataAgeRepo, catalog *CatalogManager) *Service {
 	return &Service{
 		logger:            logger,
 		db:                db,
@@ -42,6 +50,13 @@ func New(logger *zap.Logger, db *connector.Database, ec2InstanceRepo repo.EC2Ins
 		storageRepo:       storageRepo,
 		ebsVolumeTypeRepo: ebsVolumeRepo,
 		DataAgeRepo:       dataAgeRepo,
+		Catalog:           catalog,
+
+		elastiCacheNodeTypeRepo:  elastiCacheNodeTypeRepo,
+		lambdaPriceRepo:          lambdaPriceRepo,
+		dynamoDBPriceRepo:        dynamoDBPriceRepo,
+		awsIdleResourcePriceRepo: awsIdleResourcePriceRepo,
+		awsCommitmentPriceRepo:   awsCommitmentPriceRepo,
 	}
 }
 
@@ -60,83 +75,46 @@ func (s *Service) Start(ctx context.Context) {
 
 	for range ticker.C {
 		s.logger.Info("checking data age")
-		dataAge, err := s.DataAgeRepo.List()
+		ingest, err := s.Catalog.ShouldIngest("AWS::EC2::Instance", 365*24*time.Hour,
+			CatalogAWSEC2PriceList, CatalogAWSEC2InstanceTypesEbs)
 		if err != nil {
-			s.logger.Error("failed to list data age", zap.Error(err))
+			s.logger.Error("failed to check ec2 instance data age", zap.Error(err))
 			continue
 		}
-
-		var ec2InstanceData *model.DataAge
-		var rdsData *model.DataAge
-		for _, data := range dataAge {
-			data := data
-			switch data.DataType {
-			case "AWS::EC2::Instance":
-				ec2InstanceData = &data
-			case "AWS::RDS::Instance":
-				rdsData = &data
-			}
-		}
-
-		if ec2InstanceData == nil || ec2InstanceData.UpdatedAt.Before(time.Now().Add(-365*24*time.Hour)) {
+		if ingest {
 			s.logger.Info("ec2 instance ingest started")
 			err = s.IngestEc2Instances(ctx)
 			if err != nil {
 				s.logger.Error("failed to ingest ec2 instances", zap.Error(err))
 				continue
 			}
-			if ec2InstanceData == nil {
-				err = s.DataAgeRepo.Create(&model.DataAge{
-					DataType:  "AWS::EC2::Instance",
-					UpdatedAt: time.Now(),
-				})
-				if err != nil {
-					s.logger.Error("failed to create data age", zap.Error(err))
-					continue
-				}
-			} else {
-				err = s.DataAgeRepo.Update("AWS::EC2::Instance", model.DataAge{
-					DataType:  "AWS::EC2::Instance",
-					UpdatedAt: time.Now(),
-				})
-				if err != nil {
-					s.logger.Error("failed to update data age", zap.Error(err))
-					continue
-				}
-			}
 		} else {
-			s.logger.Info("ec2 instance ingest not started: ", zap.Any("usage", ec2InstanceData))
+			s.logger.Info("ec2 instance ingest not started")
 		}
 
-		if rdsData == nil || rdsData.UpdatedAt.Before(time.Now().Add(-7*24*time.Hour)) {
+		ingest, err = s.Catalog.ShouldIngest("AWS::RDS::Instance", 7*24*time.Hour, CatalogAWSRDSPriceList)
+		if err != nil {
+			s.logger.Error("failed to check rds data age", zap.Error(err))
+			continue
+		}
+		if ingest {
 			s.logger.Info("rds ingest started")
-			err = s.IngestRDS()
+			err = s.IngestRDS(ctx)
 			if err != nil {
 				s.logger.Error("failed to ingest rds", zap.Error(err))
 				continue
 			}
-			if rdsData == nil {
-				err = s.DataAgeRepo.Create(&model.DataAge{
-					DataType:  "AWS::RDS::Instance",
-					UpdatedAt: time.Now(),
-				})
-				if err != nil {
-					s.logger.Error("failed to create rds data age", zap.Error(err))
-					continue
-				}
-			} else {
-				err = s.DataAgeRepo.Update("AWS::RDS::Instance", model.DataAge{
-					DataType:  "AWS::RDS::Instance",
-					UpdatedAt: time.Now(),
-				})
-				if err != nil {
-					s.logger.Error("failed to update rds data age", zap.Error(err))
-					continue
-				}
-			}
 		} else {
-			s.logger.Info("rds ingest not started: ", zap.Any("usage", rdsData))
+			s.logger.Info("rds ingest not started")
 		}
+
+		s.ingestIfStale(ctx, "AWS::ElastiCache::CacheCluster", 30*24*time.Hour, s.IngestElastiCache, CatalogAWSElastiCachePriceList)
+		s.ingestIfStale(ctx, "AWS::Lambda::Function", 30*24*time.Hour, s.IngestLambda, CatalogAWSLambdaPriceList)
+		s.ingestIfStale(ctx, "AWS::DynamoDB::Table", 30*24*time.Hour, s.IngestDynamoDB, CatalogAWSDynamoDBPriceList)
+		s.ingestIfStale(ctx, "AWS::IdleResources", 365*24*time.Hour, s.IngestIdleResources,
+			CatalogAWSVPCPriceList, CatalogAWSELBPriceList, CatalogAWSEC2PriceList)
+		s.ingestIfStale(ctx, "AWS::Commitments", 30*24*time.Hour, s.IngestCommitments,
+			CatalogAWSEC2PriceList, CatalogAWSRDSPriceList, CatalogAWSSavingsPlansRates)
 	}
 
 	s.logger.Error("Ingestion service stopped", zap.Time("time", time.Now()))
@@ -147,6 +125,7 @@ func (s *Service) IngestEc2Instances(ctx context.Context) error {
 	//defer func() {
 	//	transaction.Rollback()
 	//}()
+	version := s.Catalog.Source().Version()
 	ec2InstanceTypeTable, err := s.ec2InstanceRepo.CreateNewTable()
 	if err != nil {
 		s.logger.Error("failed to auto migrate",
@@ -180,18 +159,27 @@ func (s *Service) IngestEc2Instances(ctx context.Context) error {
 	//	return err
 	//}
 
-	s.logger.Info("ingested ec2 instances")
+	err = s.Catalog.Commit("AWS::EC2::Instance", version, map[string]string{
+		CatalogViewEC2InstanceTypes: ec2InstanceTypeTable,
+		CatalogViewEBSVolumeTypes:   ebsVolumeTypeTable,
+	})
+	if err != nil {
+		s.logger.Error("failed to commit ec2 instances catalog", zap.Error(err))
+		return err
+	}
+
+	s.logger.Info("ingested ec2 instances", zap.String("version", version))
 
 	return nil
 }
 
 func (s *Service) ingestEc2InstancesBase(ctx context.Context, ec2InstanceTypeTable, ebsVolumeTypeTable string, transaction *gorm.DB) error {
-	url := "https://pricing.us-east-1.amazonaws.com/offers/v1.0/aws/AmazonEC2/current/index.csv"
-	resp, err := http.Get(url)
+	priceList, err := s.Catalog.Source().Open(ctx, CatalogAWSEC2PriceList)
 	if err != nil {
 		return err
 	}
-	csvr := csv.NewReader(resp.Body)
+	defer priceList.Close()
+	csvr := csv.NewReader(priceList)
 	csvr.FieldsPerRecord = -1
 
 	var columns map[string]int
@@ -255,100 +243,153 @@ func (s *Service) ingestEc2InstancesBase(ctx context.Context, ec2InstanceTypeTab
 		}
 	}
 
-	err = s.ec2InstanceRepo.MoveViewTransaction(ec2InstanceTypeTable)
-	if err != nil {
-		return err
+	return nil
+}
+
+// WriteBundle downloads the aws price lists and fetches the ec2 instance types ebs info and the savings plans rates
+// into a pricing bundle.
+func (s *Service) WriteBundle(ctx context.Context, w *BundleWriter) error {
+	remote := NewRemoteSource()
+	for _, file := range []CatalogFile{CatalogAWSEC2PriceList, CatalogAWSRDSPriceList, CatalogAWSElastiCachePriceList,
+		CatalogAWSLambdaPriceList, CatalogAWSDynamoDBPriceList, CatalogAWSVPCPriceList, CatalogAWSELBPriceList} {
+		r, err := remote.Open(ctx, file)
+		if err != nil {
+			return err
+		}
+		err = w.AddFile(file, r)
+		r.Close()
+		if err != nil {
+			return err
+		}
 	}
 
-	err = s.ebsVolumeTypeRepo.MoveViewTransaction(ebsVolumeTypeTable)
+	ebsInfo, err := s.fetchEc2InstanceTypesEbs(ctx)
 	if err != nil {
 		return err
 	}
+	if err = w.AddJSON(CatalogAWSEC2InstanceTypesEbs, ebsInfo); err != nil {
+		return err
+	}
 
-	err = s.ec2InstanceRepo.RemoveOldTables(ec2InstanceTypeTable)
+	rates, err := fetchSavingsPlansRates(ctx)
 	if err != nil {
 		return err
 	}
+	return w.AddJSON(CatalogAWSSavingsPlansRates, rates)
+}
+
+// ec2InstanceTypesEbs holds the EBS optimized limits of the instance types of every region, Default holds the
+// us-east-1 limits used for the types that are missing in their own region.
+type ec2InstanceTypesEbs struct {
+	Regions map[string]map[string]*ec2types.EbsOptimizedInfo `json:"regions"`
+	Default map[string]*ec2types.EbsOptimizedInfo            `json:"default"`
+}
 
-	err = s.ebsVolumeTypeRepo.RemoveOldTables(ebsVolumeTypeTable)
+func (s *Service) ingestEc2InstancesExtra(ctx context.Context, ec2InstanceTypeTable string, transaction *gorm.DB) error {
+	ebsInfo, err := loadCatalogJSON(ctx, s.Catalog.Source(), CatalogAWSEC2InstanceTypesEbs, s.fetchEc2InstanceTypesEbs)
 	if err != nil {
+		s.logger.Error("failed to load ec2 instance types ebs info", zap.Error(err))
 		return err
 	}
 
+	for region, instanceTypes := range ebsInfo.Regions {
+		for instanceType, info := range instanceTypes {
+			extras := getEc2InstanceExtrasMap(info)
+			if len(extras) == 0 {
+				s.logger.Warn("no extras found", zap.String("region", region), zap.String("instanceType", instanceType))
+				continue
+			}
+			s.logger.Info("updating extras", zap.String("region", region), zap.String("instanceType", instanceType), zap.Any("extras", extras))
+			err = s.ec2InstanceRepo.UpdateExtrasByRegionAndType(ec2InstanceTypeTable, transaction, region, instanceType, extras)
+			if err != nil {
+				s.logger.Error("failed to update extras", zap.Error(err), zap.String("region", region), zap.String("instanceType", instanceType))
+				return err
+			}
+		}
+	}
+
+	// Populate the still missing extras with the us-east-1 region data
+	for instanceType, info := range ebsInfo.Default {
+		extras := getEc2InstanceExtrasMap(info)
+		if len(extras) == 0 {
+			s.logger.Warn("no extras found", zap.String("region", "all"), zap.String("instanceType", instanceType))
+			continue
+		}
+		s.logger.Info("updating extras", zap.String("region", "all"), zap.String("instanceType", instanceType), zap.Any("extras", extras))
+		err = s.ec2InstanceRepo.UpdateNullExtrasByType(ec2InstanceTypeTable, transaction, instanceType, extras)
+		if err != nil {
+			s.logger.Error("failed to update extras", zap.Error(err), zap.String("region", "all"), zap.String("instanceType", instanceType))
+			return err
+		}
+	}
+
 	return nil
 }
 
-func (s *Service) ingestEc2InstancesExtra(ctx context.Context, ec2InstanceTypeTable string, transaction *gorm.DB) error {
+func (s *Service) fetchEc2InstanceTypesEbs(ctx context.Context) (ec2InstanceTypesEbs, error) {
+	result := ec2InstanceTypesEbs{
+		Regions: make(map[string]map[string]*ec2types.EbsOptimizedInfo),
+		Default: make(map[string]*ec2types.EbsOptimizedInfo),
+	}
+
 	sdkConfig, err := config.LoadDefaultConfig(ctx, config.WithRegion("us-east-1"))
 	if err != nil {
 		s.logger.Error("failed to load SDK config", zap.Error(err))
-		return err
+		return result, err
 	}
 	baseEc2Client := ec2.NewFromConfig(sdkConfig)
 
 	regions, err := baseEc2Client.DescribeRegions(ctx, &ec2.DescribeRegionsInput{AllRegions: aws.Bool(false)})
 	if err != nil {
 		s.logger.Error("failed to describe regions", zap.Error(err))
-		return err
+		return result, err
 	}
 
 	for _, region := range regions.Regions {
 		cnf, err := config.LoadDefaultConfig(ctx, config.WithRegion(*region.RegionName))
 		if err != nil {
 			s.logger.Error("failed to load SDK config", zap.Error(err), zap.String("region", *region.RegionName))
-			return err
+			return result, err
 		}
+		regionTypes := make(map[string]*ec2types.EbsOptimizedInfo)
 		ec2Client := ec2.NewFromConfig(cnf)
 		paginator := ec2.NewDescribeInstanceTypesPaginator(ec2Client, &ec2.DescribeInstanceTypesInput{})
 		for paginator.HasMorePages() {
 			output, err := paginator.NextPage(ctx)
 			if err != nil {
 				s.logger.Error("failed to get next page", zap.Error(err), zap.String("region", *region.RegionName))
-				return err
+				return result, err
 			}
 			for _, instanceType := range output.InstanceTypes {
-				extras := getEc2InstanceExtrasMap(instanceType)
-				if len(extras) == 0 {
-					s.logger.Warn("no extras found", zap.String("region", *region.RegionName), zap.String("instanceType", string(instanceType.InstanceType)))
+				if instanceType.EbsInfo == nil {
 					continue
 				}
-				s.logger.Info("updating extras", zap.String("region", *region.RegionName), zap.String("instanceType", string(instanceType.InstanceType)), zap.Any("extras", extras))
-				err = s.ec2InstanceRepo.UpdateExtrasByRegionAndType(ec2InstanceTypeTable, transaction, *region.RegionName, string(instanceType.InstanceType), extras)
-				if err != nil {
-					s.logger.Error("failed to update extras", zap.Error(err), zap.String("region", *region.RegionName), zap.String("instanceType", string(instanceType.InstanceType)))
-					return err
-				}
+				regionTypes[string(instanceType.InstanceType)] = instanceType.EbsInfo.EbsOptimizedInfo
 			}
 		}
+		result.Regions[*region.RegionName] = regionTypes
 	}
 
-	// Populate the still missing extras with the us-east-1 region data
 	paginator := ec2.NewDescribeInstanceTypesPaginator(baseEc2Client, &ec2.DescribeInstanceTypesInput{})
 	for paginator.HasMorePages() {
 		output, err := paginator.NextPage(ctx)
 		if err != nil {
 			s.logger.Error("failed to get next page", zap.Error(err), zap.String("region", "all"))
-			return err
+			return result, err
 		}
 		for _, instanceType := range output.InstanceTypes {
-			extras := getEc2InstanceExtrasMap(instanceType)
-			if len(extras) == 0 {
-				s.logger.Warn("no extras found", zap.String("region", "all"), zap.String("instanceType", string(instanceType.InstanceType)))
+			if instanceType.EbsInfo == nil {
 				continue
 			}
-			s.logger.Info("updating extras", zap.String("region", "all"), zap.String("instanceType", string(instanceType.InstanceType)), zap.Any("extras", extras))
-			err = s.ec2InstanceRepo.UpdateNullExtrasByType(ec2InstanceTypeTable, transaction, string(instanceType.InstanceType), extras)
-			if err != nil {
-				s.logger.Error("failed to update extras", zap.Error(err), zap.String("region", "all"), zap.String("instanceType", string(instanceType.InstanceType)))
-				return err
-			}
+			result.Default[string(instanceType.InstanceType)] = instanceType.EbsInfo.EbsOptimizedInfo
 		}
 	}
 
-	return nil
+	return result, nil
 }
 
-func (s *Service) IngestRDS() error {
+func (s *Service) IngestRDS(ctx context.Context) error {
+	version := s.Catalog.Source().Version()
 	rdsInstancesTable, err := s.rdsInstanceRepo.CreateNewTable()
 	if err != nil {
 		s.logger.Error("failed to auto migrate",
@@ -371,12 +412,12 @@ func (s *Service) IngestRDS() error {
 		return err
 	}
 
-	url := "https://pricing.us-east-1.amazonaws.com/offers/v1.0/aws/AmazonRDS/current/index.csv"
-	resp, err := http.Get(url)
+	priceList, err := s.Catalog.Source().Open(ctx, CatalogAWSRDSPriceList)
 	if err != nil {
 		return err
 	}
-	csvr := csv.NewReader(resp.Body)
+	defer priceList.Close()
+	csvr := csv.NewReader(priceList)
 	csvr.FieldsPerRecord = -1
 
 	var columns map[string]int
@@ -468,39 +509,13 @@ func (s *Service) IngestRDS() error {
 		s.logger.Error("failed to update nil ebs throughput", zap.Error(err))
 	}
 
-	err = s.rdsInstanceRepo.MoveViewTransaction(rdsInstancesTable)
-	if err != nil {
-		s.logger.Error("failed to move view", zap.String("table", rdsInstancesTable), zap.Error(err))
-		return err
-	}
-
-	err = s.rdsRepo.MoveViewTransaction(rdsProductsTable)
-	if err != nil {
-		s.logger.Error("failed to move view", zap.String("table", rdsProductsTable), zap.Error(err))
-		return err
-	}
-
-	err = s.storageRepo.MoveViewTransaction(rdsStorageTable)
-	if err != nil {
-		s.logger.Error("failed to move view", zap.String("table", rdsStorageTable), zap.Error(err))
-		return err
-	}
-
-	err = s.rdsInstanceRepo.RemoveOldTables(rdsInstancesTable)
-	if err != nil {
-		s.logger.Error("failed to remove old tables", zap.String("table", rdsInstancesTable), zap.Error(err))
-		return err
-	}
-
-	err = s.rdsRepo.RemoveOldTables(rdsProductsTable)
-	if err != nil {
-		s.logger.Error("failed to remove old tables", zap.String("table", rdsProductsTable), zap.Error(err))
-		return err
-	}
-
-	err = s.storageRepo.RemoveOldTables(rdsStorageTable)
+	err = s.Catalog.Commit("AWS::RDS::Instance", version, map[string]string{
+		CatalogViewRDSDBInstances: rdsInstancesTable,
+		CatalogViewRDSDBStorages:  rdsStorageTable,
+		CatalogViewRDSProducts:    rdsProductsTable,
+	})
 	if err != nil {
-		s.logger.Error("failed to remove old tables", zap.String("table", rdsStorageTable), zap.Error(err))
+		s.logger.Error("failed to commit rds catalog", zap.Error(err))
 		return err
 	}
 
@@ -511,26 +526,26 @@ func (s *Service) IngestRDS() error {
 	return nil
 }
 
-func getEc2InstanceExtrasMap(instanceType ec2types.InstanceTypeInfo) map[string]any {
+func getEc2InstanceExtrasMap(ebsOptimizedInfo *ec2types.EbsOptimizedInfo) map[string]any {
 	extras := map[string]any{}
-	if instanceType.EbsInfo != nil && instanceType.EbsInfo.EbsOptimizedInfo != nil {
-		if instanceType.EbsInfo.EbsOptimizedInfo.BaselineBandwidthInMbps != nil {
-			extras["ebs_baseline_bandwidth"] = *instanceType.EbsInfo.EbsOptimizedInfo.BaselineBandwidthInMbps
+	if ebsOptimizedInfo != nil {
+		if ebsOptimizedInfo.BaselineBandwidthInMbps != nil {
+			extras["ebs_baseline_bandwidth"] = *ebsOptimizedInfo.BaselineBandwidthInMbps
 		}
-		if instanceType.EbsInfo.EbsOptimizedInfo.MaximumBandwidthInMbps != nil {
-			extras["ebs_maximum_bandwidth"] = *instanceType.EbsInfo.EbsOptimizedInfo.MaximumBandwidthInMbps
+		if ebsOptimizedInfo.MaximumBandwidthInMbps != nil {
+			extras["ebs_maximum_bandwidth"] = *ebsOptimizedInfo.MaximumBandwidthInMbps
 		}
-		if instanceType.EbsInfo.EbsOptimizedInfo.BaselineIops != nil {
-			extras["ebs_baseline_iops"] = *instanceType.EbsInfo.EbsOptimizedInfo.BaselineIops
+		if ebsOptimizedInfo.BaselineIops != nil {
+			extras["ebs_baseline_iops"] = *ebsOptimizedInfo.BaselineIops
 		}
-		if instanceType.EbsInfo.EbsOptimizedInfo.MaximumIops != nil {
-			extras["ebs_maximum_iops"] = *instanceType.EbsInfo.EbsOptimizedInfo.MaximumIops
+		if ebsOptimizedInfo.MaximumIops != nil {
+			extras["ebs_maximum_iops"] = *ebsOptimizedInfo.MaximumIops
 		}
-		if instanceType.EbsInfo.EbsOptimizedInfo.BaselineThroughputInMBps != nil {
-			extras["ebs_baseline_throughput"] = *instanceType.EbsInfo.EbsOptimizedInfo.BaselineThroughputInMBps
+		if ebsOptimizedInfo.BaselineThroughputInMBps != nil {
+			extras["ebs_baseline_throughput"] = *ebsOptimizedInfo.BaselineThroughputInMBps
 		}
-		if instanceType.EbsInfo.EbsOptimizedInfo.MaximumThroughputInMBps != nil {
-			extras["ebs_maximum_throughput"] = *instanceType.EbsInfo.EbsOptimizedInfo.MaximumThroughputInMBps
+		if ebsOptimizedInfo.MaximumThroughputInMBps != nil {
+			extras["ebs_maximum_throughput"] = *ebsOptimizedInfo.MaximumThroughputInMBps
 		}
 	}
 	return extras
@@ -0,0 +1,97 @@
+package ingestion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+type CatalogFile string
+
+const (
+	CatalogAWSEC2PriceList         CatalogFile = "aws/ec2_price_list.csv"
+	CatalogAWSRDSPriceList         CatalogFile = "aws/rds_price_list.csv"
+	CatalogAWSEC2InstanceTypesEbs  CatalogFile = "aws/ec2_instance_types_ebs.json"
+	CatalogAWSElastiCachePriceList CatalogFile = "aws/elasticache_price_list.csv"
+	CatalogAWSLambdaPriceList      CatalogFile = "aws/lambda_price_list.csv"
+	CatalogAWSDynamoDBPriceList    CatalogFile = "aws/dynamodb_price_list.csv"
+	CatalogAWSVPCPriceList         CatalogFile = "aws/vpc_price_list.csv"
+	CatalogAWSELBPriceList         CatalogFile = "aws/elb_price_list.csv"
+	CatalogAWSSavingsPlansRates    CatalogFile = "aws/savings_plans_rates.json"
+	CatalogGCPComputeSKUs          CatalogFile = "gcp/compute_skus.json"
+	CatalogGCPComputeMachineTypes  CatalogFile = "gcp/compute_machine_types.json"
+	CatalogGCPComputeDiskTypes     CatalogFile = "gcp/compute_disk_types.json"
+	CatalogAzureResourceSKUs       CatalogFile = "azure/resource_skus.json"
+	CatalogAzureVMPrices           CatalogFile = "azure/vm_prices.json"
+	CatalogAzureManagedDiskPrices  CatalogFile = "azure/managed_disk_prices.json"
+)
+
+const (
+	PricingSourceRemote = "remote"
+	PricingSourceBundle = "bundle"
+)
+
+// ErrNotInSource is returned by a PricingSource for files it does not serve as a file, the ingestion
+// then fetches the same data from the provider API instead.
+var ErrNotInSource = errors.New("catalog file is not served by the pricing source")
+
+// PricingSource provides the raw price files the ingestion builds the catalog from.
+type PricingSource interface {
+	Name() string
+	// Version identifies the content of the source, ingesting the same version twice yields the same catalog.
+	Version() string
+	Open(ctx context.Context, file CatalogFile) (io.ReadCloser, error)
+}
+
+var remoteCatalogURLs = map[CatalogFile]string{
+	CatalogAWSEC2PriceList:         "https://pricing.us-east-1.amazonaws.com/offers/v1.0/aws/AmazonEC2/current/index.csv",
+	CatalogAWSRDSPriceList:         "https://pricing.us-east-1.amazonaws.com/offers/v1.0/aws/AmazonRDS/current/index.csv",
+	CatalogAWSElastiCachePriceList: "https://pricing.us-east-1.amazonaws.com/offers/v1.0/aws/AmazonElastiCache/current/index.csv",
+	CatalogAWSLambdaPriceList:      "https://pricing.us-east-1.amazonaws.com/offers/v1.0/aws/AWSLambda/current/index.csv",
+	CatalogAWSDynamoDBPriceList:    "https://pricing.us-east-1.amazonaws.com/offers/v1.0/aws/AmazonDynamoDB/current/index.csv",
+	CatalogAWSVPCPriceList:         "https://pricing.us-east-1.amazonaws.com/offers/v1.0/aws/AmazonVPC/current/index.csv",
+	CatalogAWSELBPriceList:         "https://pricing.us-east-1.amazonaws.com/offers/v1.0/aws/AWSELB/current/index.csv",
+}
+
+// RemoteSource downloads the price lists from the providers, everything else is fetched from the provider APIs.
+type RemoteSource struct{}
+
+func NewRemoteSource() *RemoteSource {
+	return &RemoteSource{}
+}
+
+func (s *RemoteSource) Name() string {
+	return PricingSourceRemote
+}
+
+// Version of a remote source is the time it is read at, since the providers do not version their price lists.
+func (s *RemoteSource) Version() string {
+	return time.Now().UTC().Format("20060102T150405Z")
+}
+
+func (s *RemoteSource) Open(ctx context.Context, file CatalogFile) (io.ReadCloser, error) {
+	url, ok := remoteCatalogURLs[file]
+	if !ok {
+		return nil, ErrNotInSource
+	}
+	return httpGet(ctx, url)
+}
+
+func httpGet(ctx context.Context, url string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("failed to download %s, status code = %d", url, resp.StatusCode)
+	}
+	return resp.Body, nil
+}
@@ -0,0 +1,590 @@
+package recommendation
+
+import (
+	"context"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/opengovern/opengovernance/services/wastage/api/entity"
+	"github.com/opengovern/opengovernance/services/wastage/db/model"
+	"go.uber.org/zap"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// commitmentUsageGroup is the hourly usage of an instance type over the lookback window.
+type commitmentUsageGroup struct {
+	key      entity.AwsCommitmentUsageKey
+	family   string
+	onDemand float64
+	// total is the number of instances of every hour, usage is what is left after the existing commitments
+	total []float64
+	usage []float64
+	// savingsPlansRates by commitment type and then by term and payment option
+	savingsPlansRates map[string]map[commitmentOffering]float64
+}
+
+type commitmentOffering struct {
+	term           string
+	purchaseOption string
+}
+
+// AwsCommitmentsRecommendation recommends the reserved instances and savings plans to buy for the hourly usage of the
+// request, after the existing commitments are applied to it.
+func (s *Service) AwsCommitmentsRecommendation(ctx context.Context, req entity.AwsCommitmentsRequest) (*entity.AwsCommitmentsResponse, error) {
+	hourly := commitmentHourlyUsage(req)
+	if len(hourly) == 0 {
+		return &entity.AwsCommitmentsResponse{}, nil
+	}
+
+	var first, last int64 = math.MaxInt64, math.MinInt64
+	for _, hours := range hourly {
+		for h := range hours {
+			first = min(first, h)
+			last = max(last, h)
+		}
+	}
+	hoursCount := int((last-first)/3600) + 1
+
+	resp := entity.AwsCommitmentsResponse{LookbackHours: hoursCount}
+	var groups []*commitmentUsageGroup
+	for key, hours := range hourly {
+		onDemand, err := s.commitmentOnDemandPrice(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		if onDemand == 0 {
+			resp.Unpriced = append(resp.Unpriced, key)
+			continue
+		}
+
+		g := commitmentUsageGroup{
+			key:      key,
+			family:   instanceFamily(key.InstanceType),
+			onDemand: onDemand,
+			total:    make([]float64, hoursCount),
+			usage:    make([]float64, hoursCount),
+		}
+		for h, v := range hours {
+			g.total[(h-first)/3600] = v
+			g.usage[(h-first)/3600] = v
+		}
+		if key.Service == entity.AwsCommitmentServiceEC2 {
+			g.savingsPlansRates, err = s.savingsPlansRates(ctx, key)
+			if err != nil {
+				return nil, err
+			}
+		}
+		groups = append(groups, &g)
+	}
+	// the map order is random, sorting keeps the recommendations of the same request the same
+	sort.Slice(groups, func(i, j int) bool {
+		return commitmentUsageKeyString(groups[i].key) < commitmentUsageKeyString(groups[j].key)
+	})
+	sort.Slice(resp.Unpriced, func(i, j int) bool {
+		return commitmentUsageKeyString(resp.Unpriced[i]) < commitmentUsageKeyString(resp.Unpriced[j])
+	})
+
+	applyExistingCommitments(groups, req.Commitments, hoursCount)
+
+	var totalCost, uncoveredCost float64
+	for _, g := range groups {
+		for h := 0; h < hoursCount; h++ {
+			totalCost += g.total[h] * g.onDemand
+			uncoveredCost += g.usage[h] * g.onDemand
+		}
+	}
+	if totalCost == 0 {
+		return &resp, nil
+	}
+	monthly := awsHoursPerMonth / float64(hoursCount)
+	resp.OnDemandMonthlyCost = uncoveredCost * monthly
+	resp.ExistingCoveragePercent = (totalCost - uncoveredCost) / totalCost * 100
+
+	filter := newCommitmentFilter(req.Preferences)
+	coverage := func(coveredCost float64) float64 {
+		return (totalCost - uncoveredCost + coveredCost) / totalCost * 100
+	}
+
+	for _, g := range groups {
+		recoms, err := s.reservedInstanceRecommendations(ctx, g, filter, monthly, coverage)
+		if err != nil {
+			return nil, err
+		}
+		resp.Recommendations = append(resp.Recommendations, recoms...)
+	}
+
+	var ec2Groups []*commitmentUsageGroup
+	familyGroups := make(map[string][]*commitmentUsageGroup)
+	var families []string
+	for _, g := range groups {
+		if g.key.Service != entity.AwsCommitmentServiceEC2 {
+			continue
+		}
+		ec2Groups = append(ec2Groups, g)
+		k := g.key.Region + "|" + g.family
+		if _, ok := familyGroups[k]; !ok {
+			families = append(families, k)
+		}
+		familyGroups[k] = append(familyGroups[k], g)
+	}
+	resp.Recommendations = append(resp.Recommendations,
+		savingsPlansRecommendations(entity.AwsCommitmentTypeComputeSavingsPlans, ec2Groups, filter, hoursCount, monthly, coverage)...)
+	for _, k := range families {
+		resp.Recommendations = append(resp.Recommendations,
+			savingsPlansRecommendations(entity.AwsCommitmentTypeEC2InstanceSavingsPlans, familyGroups[k], filter, hoursCount, monthly, coverage)...)
+	}
+
+	sort.SliceStable(resp.Recommendations, func(i, j int) bool {
+		return resp.Recommendations[i].EstimatedMonthlySavings > resp.Recommendations[j].EstimatedMonthlySavings
+	})
+
+	s.logger.Info("commitments analyzed", zap.Int("groups", len(groups)), zap.Int("hours", hoursCount),
+		zap.Int("recommendations", len(resp.Recommendations)))
+	return &resp, nil
+}
+
+// commitmentHourlyUsage merges the usage and the instances of the request into the number of instances running in
+// every hour, keyed by the unix time of the hour.
+func commitmentHourlyUsage(req entity.AwsCommitmentsRequest) map[entity.AwsCommitmentUsageKey]map[int64]float64 {
+	result := make(map[entity.AwsCommitmentUsageKey]map[int64]float64)
+	add := func(key entity.AwsCommitmentUsageKey, hour int64, v float64) {
+		key = normalizeCommitmentUsageKey(key)
+		if _, ok := result[key]; !ok {
+			result[key] = make(map[int64]float64)
+		}
+		result[key][hour] += v
+	}
+
+	for _, u := range req.Usage {
+		for _, h := range u.Hourly {
+			add(u.AwsCommitmentUsageKey, h.Timestamp.Truncate(time.Hour).Unix(), h.Instances)
+		}
+	}
+
+	for _, instance := range req.Instances {
+		running := make(map[int64]bool)
+		for _, dps := range instance.Metrics {
+			period := datapointsPeriod(dps)
+			for _, dp := range dps {
+				if dp.Timestamp == nil || !hasDatapointValue(dp) {
+					continue
+				}
+				for t := dp.Timestamp.Truncate(time.Hour); t.Before(dp.Timestamp.Add(period)); t = t.Add(time.Hour) {
+					running[t.Unix()] = true
+				}
+			}
+		}
+		for h := range running {
+			add(instance.AwsCommitmentUsageKey, h, 1)
+		}
+	}
+	return result
+}
+
+func hasDatapointValue(dp types.Datapoint) bool {
+	return dp.Average != nil || dp.Maximum != nil || dp.Minimum != nil || dp.Sum != nil || dp.SampleCount != nil
+}
+
+func normalizeCommitmentUsageKey(key entity.AwsCommitmentUsageKey) entity.AwsCommitmentUsageKey {
+	if key.Service == "" {
+		key.Service = entity.AwsCommitmentServiceEC2
+	}
+	if key.Service == entity.AwsCommitmentServiceEC2 && key.Operation == "" {
+		key.Operation = "RunInstances"
+	}
+	return key
+}
+
+func commitmentUsageKeyString(key entity.AwsCommitmentUsageKey) string {
+	return strings.Join([]string{string(key.Service), key.Region, key.InstanceType, key.Operation,
+		key.DatabaseEngine, key.DatabaseEdition, key.DeploymentOption}, "|")
+}
+
+// instanceFamily returns m5 for m5.large and db.r6g for db.r6g.xlarge.
+func instanceFamily(instanceType string) string {
+	if i := strings.LastIndex(instanceType, "."); i > 0 {
+		return instanceType[:i]
+	}
+	return instanceType
+}
+
+func (s *Service) commitmentOnDemandPrice(ctx context.Context, key entity.AwsCommitmentUsageKey) (float64, error) {
+	switch key.Service {
+	case entity.AwsCommitmentServiceEC2:
+		rows, err := s.ec2InstanceRepo.ListByInstanceType(ctx, key.InstanceType, key.Operation, key.Region)
+		if err != nil {
+			return 0, err
+		}
+		for _, row := range rows {
+			if row.Tenancy == "Shared" && row.PricePerUnit > 0 {
+				return row.PricePerUnit, nil
+			}
+		}
+	case entity.AwsCommitmentServiceRDS:
+		rows, err := s.awsRDSDBInstanceRepo.ListByInstanceType(ctx, key.Region, key.InstanceType, key.DatabaseEngine, key.DatabaseEdition, key.DeploymentOption)
+		if err != nil {
+			return 0, err
+		}
+		for _, row := range rows {
+			if row.PricePerUnit > 0 {
+				return row.PricePerUnit, nil
+			}
+		}
+	default:
+		return 0, fmt.Errorf("unknown commitment service %s", key.Service)
+	}
+	return 0, nil
+}
+
+func (s *Service) savingsPlansRates(ctx context.Context, key entity.AwsCommitmentUsageKey) (map[string]map[commitmentOffering]float64, error) {
+	result := make(map[string]map[commitmentOffering]float64)
+	for _, commitmentType := range []string{model.AWSCommitmentTypeComputeSavingsPlans, model.AWSCommitmentTypeEC2InstanceSavingsPlans} {
+		rows, err := s.awsCommitmentPriceRepo.ListByInstanceType(ctx, commitmentType, key.Region, key.InstanceType, map[string]any{
+			"operation = ?": key.Operation,
+		})
+		if err != nil {
+			return nil, err
+		}
+		result[commitmentType] = make(map[commitmentOffering]float64)
+		for _, row := range rows {
+			result[commitmentType][commitmentOffering{term: row.LeaseContractLength, purchaseOption: row.PurchaseOption}] = row.HourlyRate
+		}
+	}
+	return result, nil
+}
+
+// applyExistingCommitments removes the usage covered by the active reserved instances and savings plans. Existing
+// savings plans are applied with their one year no upfront rates, the usage with the highest discount first.
+func applyExistingCommitments(groups []*commitmentUsageGroup, commitments []entity.AwsExistingCommitment, hoursCount int) {
+	now := time.Now()
+	var savingsPlans []entity.AwsExistingCommitment
+	for _, c := range commitments {
+		if c.End != nil && c.End.Before(now) {
+			continue
+		}
+		switch c.Type {
+		case entity.AwsCommitmentTypeReservedInstance:
+			key := normalizeCommitmentUsageKey(c.AwsCommitmentUsageKey)
+			for h := 0; h < hoursCount; h++ {
+				left := float64(c.Count)
+				for _, g := range groups {
+					if left <= 0 {
+						break
+					}
+					if !reservedInstanceMatches(key, g.key) {
+						continue
+					}
+					covered := math.Min(left, g.usage[h])
+					g.usage[h] -= covered
+					left -= covered
+				}
+			}
+		case entity.AwsCommitmentTypeEC2InstanceSavingsPlans, entity.AwsCommitmentTypeComputeSavingsPlans:
+			savingsPlans = append(savingsPlans, c)
+		}
+	}
+	// instance savings plans apply before compute savings plans
+	sort.SliceStable(savingsPlans, func(i, j int) bool {
+		return savingsPlans[i].Type == entity.AwsCommitmentTypeEC2InstanceSavingsPlans &&
+			savingsPlans[j].Type != entity.AwsCommitmentTypeEC2InstanceSavingsPlans
+	})
+
+	defaultOffering := commitmentOffering{term: "1yr", purchaseOption: "No Upfront"}
+	for _, sp := range savingsPlans {
+		var applicable []*commitmentUsageGroup
+		for _, g := range groups {
+			if g.key.Service != entity.AwsCommitmentServiceEC2 || g.savingsPlansRates[string(sp.Type)][defaultOffering] == 0 {
+				continue
+			}
+			if sp.Type == entity.AwsCommitmentTypeEC2InstanceSavingsPlans && (g.key.Region != sp.Region || g.family != sp.InstanceFamily) {
+				continue
+			}
+			applicable = append(applicable, g)
+		}
+		sort.SliceStable(applicable, func(i, j int) bool {
+			return applicable[i].savingsPlansRates[string(sp.Type)][defaultOffering]/applicable[i].onDemand <
+				applicable[j].savingsPlansRates[string(sp.Type)][defaultOffering]/applicable[j].onDemand
+		})
+		for h := 0; h < hoursCount; h++ {
+			budget := sp.HourlyCommitment
+			for _, g := range applicable {
+				if budget <= 0 {
+					break
+				}
+				rate := g.savingsPlansRates[string(sp.Type)][defaultOffering]
+				covered := math.Min(g.usage[h], budget/rate)
+				g.usage[h] -= covered
+				budget -= covered * rate
+			}
+		}
+	}
+}
+
+func reservedInstanceMatches(ri, usage entity.AwsCommitmentUsageKey) bool {
+	return ri.Service == usage.Service && ri.Region == usage.Region && strings.EqualFold(ri.InstanceType, usage.InstanceType) &&
+		(ri.Operation == "" || ri.Operation == usage.Operation) &&
+		(ri.DatabaseEngine == "" || strings.EqualFold(ri.DatabaseEngine, usage.DatabaseEngine)) &&
+		(ri.DatabaseEdition == "" || strings.EqualFold(ri.DatabaseEdition, usage.DatabaseEdition)) &&
+		(ri.DeploymentOption == "" || ri.DeploymentOption == usage.DeploymentOption)
+}
+
+// commitmentFilter holds the terms, payment options and reserved instance offering class to recommend.
+type commitmentFilter struct {
+	terms          map[string]bool
+	purchaseOption map[string]bool
+	offeringClass  string
+}
+
+func newCommitmentFilter(preferences map[string]*string) commitmentFilter {
+	f := commitmentFilter{offeringClass: "standard"}
+	list := func(name string) map[string]bool {
+		if preferences[name] == nil || *preferences[name] == "" {
+			return nil
+		}
+		m := make(map[string]bool)
+		for _, v := range strings.Split(*preferences[name], ",") {
+			m[strings.TrimSpace(v)] = true
+		}
+		return m
+	}
+	f.terms = list("Terms")
+	f.purchaseOption = list("PaymentOptions")
+	if preferences["OfferingClass"] != nil && *preferences["OfferingClass"] != "" {
+		f.offeringClass = *preferences["OfferingClass"]
+	}
+	return f
+}
+
+func (f commitmentFilter) allows(term, purchaseOption string) bool {
+	return (f.terms == nil || f.terms[term]) && (f.purchaseOption == nil || f.purchaseOption[purchaseOption])
+}
+
+func termHours(term string) float64 {
+	switch term {
+	case "3yr":
+		return 3 * 8760
+	default:
+		return 8760
+	}
+}
+
+func (s *Service) reservedInstanceRecommendations(ctx context.Context, g *commitmentUsageGroup, filter commitmentFilter,
+	monthly float64, coverage func(float64) float64) ([]entity.AwsCommitmentRecommendation, error) {
+	pref := map[string]any{
+		"service_code = ?":   string(g.key.Service),
+		"offering_class = ?": filter.offeringClass,
+	}
+	switch g.key.Service {
+	case entity.AwsCommitmentServiceEC2:
+		pref["operation = ?"] = g.key.Operation
+	case entity.AwsCommitmentServiceRDS:
+		pref["database_engine = ?"] = g.key.DatabaseEngine
+		pref["deployment_option = ?"] = g.key.DeploymentOption
+		if g.key.DatabaseEdition != "" {
+			pref["database_edition = ?"] = g.key.DatabaseEdition
+		}
+	}
+	offerings, err := s.awsCommitmentPriceRepo.ListByInstanceType(ctx, model.AWSCommitmentTypeReservedInstance, g.key.Region, g.key.InstanceType, pref)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []entity.AwsCommitmentRecommendation
+	for _, o := range offerings {
+		if !filter.allows(o.LeaseContractLength, o.PurchaseOption) {
+			continue
+		}
+		effectiveHourly := o.HourlyRate + o.UpfrontFee/termHours(o.LeaseContractLength)
+		quantity, coveredHours := bestReservedQuantity(g.usage, g.onDemand, effectiveHourly)
+		if quantity == 0 {
+			continue
+		}
+
+		r := entity.AwsCommitmentRecommendation{
+			Type:                  entity.AwsCommitmentTypeReservedInstance,
+			AwsCommitmentUsageKey: g.key,
+			InstanceFamily:        g.family,
+			Term:                  o.LeaseContractLength,
+			PurchaseOption:        o.PurchaseOption,
+			OfferingClass:         o.OfferingClass,
+			Quantity:              quantity,
+			UpfrontCost:           o.UpfrontFee * float64(quantity),
+			RecurringMonthlyCost:  o.HourlyRate * float64(quantity) * awsHoursPerMonth,
+			EffectiveMonthlyCost:  effectiveHourly * float64(quantity) * awsHoursPerMonth,
+			OnDemandMonthlyCost:   g.onDemand * coveredHours * monthly,
+			CoveragePercent:       coverage(g.onDemand * coveredHours),
+			UtilizationPercent:    coveredHours / (float64(quantity) * float64(len(g.usage))) * 100,
+		}
+		fillCommitmentSavings(&r)
+		result = append(result, r)
+	}
+	return result, nil
+}
+
+// bestReservedQuantity returns the number of reserved instances that saves the most, a reserved instance pays off
+// when the instances run in a larger share of the hours than the ratio of its effective rate to the on demand price.
+func bestReservedQuantity(usage []float64, onDemand, effectiveHourly float64) (int64, float64) {
+	if effectiveHourly >= onDemand {
+		return 0, 0
+	}
+	peak := 0.0
+	for _, u := range usage {
+		peak = max(peak, u)
+	}
+
+	var best int64
+	var bestSavings, bestCovered float64
+	for n := int64(1); float64(n) <= math.Ceil(peak); n++ {
+		covered := 0.0
+		for _, u := range usage {
+			covered += math.Min(u, float64(n))
+		}
+		savings := onDemand*covered - effectiveHourly*float64(n)*float64(len(usage))
+		if savings > bestSavings {
+			best, bestSavings, bestCovered = n, savings, covered
+		}
+	}
+	return best, bestCovered
+}
+
+func savingsPlansRecommendations(commitmentType entity.AwsCommitmentType, groups []*commitmentUsageGroup, filter commitmentFilter,
+	hoursCount int, monthly float64, coverage func(float64) float64) []entity.AwsCommitmentRecommendation {
+	if len(groups) == 0 {
+		return nil
+	}
+
+	offerings := make(map[commitmentOffering]bool)
+	for _, g := range groups {
+		for o := range g.savingsPlansRates[string(commitmentType)] {
+			offerings[o] = true
+		}
+	}
+	sortedOfferings := make([]commitmentOffering, 0, len(offerings))
+	for o := range offerings {
+		sortedOfferings = append(sortedOfferings, o)
+	}
+	sort.Slice(sortedOfferings, func(i, j int) bool {
+		if sortedOfferings[i].term != sortedOfferings[j].term {
+			return sortedOfferings[i].term < sortedOfferings[j].term
+		}
+		return sortedOfferings[i].purchaseOption < sortedOfferings[j].purchaseOption
+	})
+
+	var result []entity.AwsCommitmentRecommendation
+	for _, o := range sortedOfferings {
+		if !filter.allows(o.term, o.purchaseOption) {
+			continue
+		}
+		// the cost of every hour at the savings plans rates and at the on demand prices
+		spCost := make([]float64, hoursCount)
+		odCost := make([]float64, hoursCount)
+		for _, g := range groups {
+			rate := g.savingsPlansRates[string(commitmentType)][o]
+			if rate == 0 {
+				continue
+			}
+			for h := 0; h < hoursCount; h++ {
+				spCost[h] += g.usage[h] * rate
+				odCost[h] += g.usage[h] * g.onDemand
+			}
+		}
+
+		commitment := bestHourlyCommitment(spCost, odCost)
+		if commitment == 0 {
+			continue
+		}
+		usedCommitment, coveredOd := savingsPlanCoverage(commitment, spCost, odCost)
+
+		upfrontShare := 0.0
+		switch o.purchaseOption {
+		case "All Upfront":
+			upfrontShare = 1
+		case "Partial Upfront":
+			upfrontShare = 0.5
+		}
+
+		r := entity.AwsCommitmentRecommendation{
+			Type:                 commitmentType,
+			Term:                 o.term,
+			PurchaseOption:       o.purchaseOption,
+			HourlyCommitment:     commitment,
+			UpfrontCost:          commitment * termHours(o.term) * upfrontShare,
+			RecurringMonthlyCost: commitment * awsHoursPerMonth * (1 - upfrontShare),
+			EffectiveMonthlyCost: commitment * awsHoursPerMonth,
+			OnDemandMonthlyCost:  coveredOd * monthly,
+			CoveragePercent:      coverage(coveredOd),
+			UtilizationPercent:   usedCommitment / (commitment * float64(hoursCount)) * 100,
+		}
+		if commitmentType == entity.AwsCommitmentTypeEC2InstanceSavingsPlans {
+			r.Service = entity.AwsCommitmentServiceEC2
+			r.Region = groups[0].key.Region
+			r.InstanceFamily = groups[0].family
+		}
+		fillCommitmentSavings(&r)
+		if r.EstimatedMonthlySavings <= 0 {
+			continue
+		}
+		result = append(result, r)
+	}
+	return result
+}
+
+// bestHourlyCommitment returns the savings plans commitment that saves the most. The savings only change slope at
+// the hourly costs, so it is enough to check those.
+func bestHourlyCommitment(spCost, odCost []float64) float64 {
+	type hour struct{ sp, od float64 }
+	hours := make([]hour, 0, len(spCost))
+	for h := range spCost {
+		if spCost[h] > 0 {
+			hours = append(hours, hour{spCost[h], odCost[h]})
+		}
+	}
+	if len(hours) == 0 {
+		return 0
+	}
+	sort.Slice(hours, func(i, j int) bool { return hours[i].sp < hours[j].sp })
+
+	// suffixRatio[k] is the on demand cost covered per committed dollar in the hours from k on
+	suffixRatio := make([]float64, len(hours)+1)
+	for k := len(hours) - 1; k >= 0; k-- {
+		suffixRatio[k] = suffixRatio[k+1] + hours[k].od/hours[k].sp
+	}
+
+	var best, bestSavings, lowerOd float64
+	for k, h := range hours {
+		lowerOd += h.od
+		c := h.sp
+		savings := lowerOd + c*suffixRatio[k+1] - c*float64(len(spCost))
+		if savings > bestSavings {
+			best, bestSavings = c, savings
+		}
+	}
+	// savings plans are bought in tenths of a cent
+	return math.Floor(best*1000) / 1000
+}
+
+// savingsPlanCoverage returns the commitment used over the hours and the on demand cost it covers.
+func savingsPlanCoverage(commitment float64, spCost, odCost []float64) (float64, float64) {
+	var used, coveredOd float64
+	for h := range spCost {
+		if spCost[h] == 0 {
+			continue
+		}
+		u := math.Min(commitment, spCost[h])
+		used += u
+		coveredOd += u * odCost[h] / spCost[h]
+	}
+	return used, coveredOd
+}
+
+func fillCommitmentSavings(r *entity.AwsCommitmentRecommendation) {
+	r.EstimatedMonthlySavings = r.OnDemandMonthlyCost - r.EffectiveMonthlyCost
+	if r.OnDemandMonthlyCost > 0 {
+		r.SavingsPercent = r.EstimatedMonthlySavings / r.OnDemandMonthlyCost * 100
+	}
+	if monthlyReturn := r.OnDemandMonthlyCost - r.RecurringMonthlyCost; r.UpfrontCost > 0 && monthlyReturn > 0 {
+		r.BreakEvenMonths = r.UpfrontCost / monthlyReturn
+	}
+}
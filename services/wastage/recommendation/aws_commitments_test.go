@@ -0,0 +1,247 @@
+package recommendation
+
+import (
+	"context"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/opengovern/opengovernance/services/wastage/api/entity"
+	"github.com/opengovern/opengovernance/services/wastage/db/model"
+	"github.com/opengovern/opengovernance/services/wastage/db/repo"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"testing"
+	"time"
+)
+
+func TestBestReservedQuantity(t *testing.T) {
+	// 2 instances all the time and a third one in a quarter of the hours
+	usage := make([]float64, 100)
+	for i := range usage {
+		usage[i] = 2
+		if i < 25 {
+			usage[i] = 3
+		}
+	}
+
+	quantity, covered := bestReservedQuantity(usage, 1, 0.6)
+	assert.Equal(t, int64(2), quantity)
+	assert.Equal(t, 200.0, covered)
+
+	quantity, _ = bestReservedQuantity(usage, 1, 0.2)
+	assert.Equal(t, int64(3), quantity)
+
+	quantity, _ = bestReservedQuantity(usage, 1, 1.2)
+	assert.Equal(t, int64(0), quantity)
+}
+
+func TestBestHourlyCommitment(t *testing.T) {
+	spCost := []float64{0.5, 0.5, 0.5, 1, 0}
+	odCost := []float64{1, 1, 1, 2, 0}
+
+	commitment := bestHourlyCommitment(spCost, odCost)
+	assert.Equal(t, 0.5, commitment)
+
+	used, coveredOd := savingsPlanCoverage(commitment, spCost, odCost)
+	assert.InDelta(t, 2.0, used, 0.0001)
+	assert.InDelta(t, 4.0, coveredOd, 0.0001)
+}
+
+type fakeEC2InstanceTypeRepo struct {
+	repo.EC2InstanceTypeRepo
+	prices map[string]float64
+}
+
+func (r fakeEC2InstanceTypeRepo) ListByInstanceType(ctx context.Context, instanceType, operation, region string) ([]model.EC2InstanceType, error) {
+	price, ok := r.prices[instanceType]
+	if !ok {
+		return nil, nil
+	}
+	return []model.EC2InstanceType{{InstanceType: instanceType, Operation: operation, RegionCode: region, Tenancy: "Shared", PricePerUnit: price}}, nil
+}
+
+type fakeAWSCommitmentPriceRepo struct {
+	repo.AWSCommitmentPriceRepo
+	prices []model.AWSCommitmentPrice
+}
+
+func (r fakeAWSCommitmentPriceRepo) ListByInstanceType(ctx context.Context, commitmentType, region, instanceType string, pref map[string]any) ([]model.AWSCommitmentPrice, error) {
+	var result []model.AWSCommitmentPrice
+	for _, p := range r.prices {
+		if p.CommitmentType != commitmentType || p.RegionCode != region || p.InstanceType != instanceType {
+			continue
+		}
+		if class, ok := pref["offering_class = ?"]; ok && p.OfferingClass != class {
+			continue
+		}
+		result = append(result, p)
+	}
+	return result, nil
+}
+
+func TestAwsCommitmentsRecommendation(t *testing.T) {
+	const onDemand = 0.096
+	type offering struct {
+		commitmentType string
+		term           string
+		purchaseOption string
+		offeringClass  string
+		hourlyRate     float64
+		upfrontFee     float64
+		// wantCommitment of savings plans, three instances at the rate rounded down to a tenth of a cent
+		wantCommitment float64
+	}
+	offerings := []offering{
+		{model.AWSCommitmentTypeReservedInstance, "1yr", "No Upfront", "standard", 0.060, 0, 0},
+		{model.AWSCommitmentTypeReservedInstance, "1yr", "Partial Upfront", "standard", 0.029, 250, 0},
+		{model.AWSCommitmentTypeReservedInstance, "1yr", "All Upfront", "standard", 0, 490, 0},
+		{model.AWSCommitmentTypeReservedInstance, "3yr", "No Upfront", "standard", 0.041, 0, 0},
+		{model.AWSCommitmentTypeReservedInstance, "3yr", "Partial Upfront", "standard", 0.019, 500, 0},
+		{model.AWSCommitmentTypeReservedInstance, "3yr", "All Upfront", "standard", 0, 950, 0},
+		{model.AWSCommitmentTypeComputeSavingsPlans, "1yr", "No Upfront", "", 0.0681, 0, 0.204},
+		{model.AWSCommitmentTypeComputeSavingsPlans, "1yr", "Partial Upfront", "", 0.0652, 0, 0.195},
+		{model.AWSCommitmentTypeComputeSavingsPlans, "1yr", "All Upfront", "", 0.0633, 0, 0.189},
+		{model.AWSCommitmentTypeComputeSavingsPlans, "3yr", "No Upfront", "", 0.0471, 0, 0.141},
+		{model.AWSCommitmentTypeComputeSavingsPlans, "3yr", "Partial Upfront", "", 0.0452, 0, 0.135},
+		{model.AWSCommitmentTypeComputeSavingsPlans, "3yr", "All Upfront", "", 0.0433, 0, 0.129},
+		{model.AWSCommitmentTypeEC2InstanceSavingsPlans, "1yr", "No Upfront", "", 0.0601, 0, 0.180},
+		{model.AWSCommitmentTypeEC2InstanceSavingsPlans, "1yr", "Partial Upfront", "", 0.0572, 0, 0.171},
+		{model.AWSCommitmentTypeEC2InstanceSavingsPlans, "1yr", "All Upfront", "", 0.0553, 0, 0.165},
+		{model.AWSCommitmentTypeEC2InstanceSavingsPlans, "3yr", "No Upfront", "", 0.0411, 0, 0.123},
+		{model.AWSCommitmentTypeEC2InstanceSavingsPlans, "3yr", "Partial Upfront", "", 0.0392, 0, 0.117},
+		{model.AWSCommitmentTypeEC2InstanceSavingsPlans, "3yr", "All Upfront", "", 0.0373, 0, 0.111},
+	}
+	prices := []model.AWSCommitmentPrice{
+		// convertible reserved instances are only recommended when preferred
+		{CommitmentType: model.AWSCommitmentTypeReservedInstance, RegionCode: "us-east-1", InstanceType: "m5.large",
+			LeaseContractLength: "1yr", PurchaseOption: "No Upfront", OfferingClass: "convertible", HourlyRate: 0.070},
+	}
+	for _, o := range offerings {
+		prices = append(prices, model.AWSCommitmentPrice{CommitmentType: o.commitmentType, RegionCode: "us-east-1", InstanceType: "m5.large",
+			LeaseContractLength: o.term, PurchaseOption: o.purchaseOption, OfferingClass: o.offeringClass, HourlyRate: o.hourlyRate, UpfrontFee: o.upfrontFee})
+	}
+	s := &Service{
+		logger:                 zap.NewNop(),
+		ec2InstanceRepo:        fakeEC2InstanceTypeRepo{prices: map[string]float64{"m5.large": onDemand, "c5.large": 0.085}},
+		awsCommitmentPriceRepo: fakeAWSCommitmentPriceRepo{prices: prices},
+	}
+
+	// three m5.large in 90 of 100 hours and two in the rest, next to a c5.large without commitment offerings
+	start := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	m5 := entity.AwsCommitmentUsage{AwsCommitmentUsageKey: entity.AwsCommitmentUsageKey{Region: "us-east-1", InstanceType: "m5.large"}}
+	c5 := entity.AwsCommitmentUsage{AwsCommitmentUsageKey: entity.AwsCommitmentUsageKey{Region: "us-east-1", InstanceType: "c5.large"}}
+	for h := 0; h < 100; h++ {
+		instances := 3.0
+		if h >= 90 {
+			instances = 2
+		}
+		ts := start.Add(time.Duration(h) * time.Hour)
+		m5.Hourly = append(m5.Hourly, entity.AwsCommitmentHourlyUsage{Timestamp: ts, Instances: instances})
+		c5.Hourly = append(c5.Hourly, entity.AwsCommitmentHourlyUsage{Timestamp: ts, Instances: 1})
+	}
+	req := entity.AwsCommitmentsRequest{Usage: []entity.AwsCommitmentUsage{m5, c5}}
+
+	resp, err := s.AwsCommitmentsRecommendation(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, 100, resp.LookbackHours)
+	monthly := awsHoursPerMonth / 100.0
+	totalCost := onDemand*290 + 0.085*100
+	assert.InDelta(t, totalCost*monthly, resp.OnDemandMonthlyCost, 0.0001)
+	assert.Equal(t, 0.0, resp.ExistingCoveragePercent)
+	assert.Len(t, resp.Recommendations, len(offerings))
+	for i := 1; i < len(resp.Recommendations); i++ {
+		assert.GreaterOrEqual(t, resp.Recommendations[i-1].EstimatedMonthlySavings, resp.Recommendations[i].EstimatedMonthlySavings)
+	}
+
+	find := func(recoms []entity.AwsCommitmentRecommendation, o offering) *entity.AwsCommitmentRecommendation {
+		for _, r := range recoms {
+			if string(r.Type) == o.commitmentType && r.Term == o.term && r.PurchaseOption == o.purchaseOption {
+				r := r
+				return &r
+			}
+		}
+		return nil
+	}
+	for _, o := range offerings {
+		name := o.commitmentType + " " + o.term + " " + o.purchaseOption
+		r := find(resp.Recommendations, o)
+		if !assert.NotNil(t, r, name) {
+			continue
+		}
+
+		var coveredOd, upfront, recurring, effective, utilization float64
+		if o.commitmentType == model.AWSCommitmentTypeReservedInstance {
+			// the third instance runs in 90% of the hours, more than any effective rate is of the on demand price
+			assert.Equal(t, int64(3), r.Quantity, name)
+			assert.Equal(t, o.offeringClass, r.OfferingClass, name)
+			coveredOd = onDemand * 290
+			upfront = o.upfrontFee * 3
+			recurring = o.hourlyRate * 3 * awsHoursPerMonth
+			effective = (o.hourlyRate + o.upfrontFee/termHours(o.term)) * 3 * awsHoursPerMonth
+			utilization = 290.0 / 300 * 100
+		} else {
+			c := o.wantCommitment
+			assert.InDelta(t, c, r.HourlyCommitment, 0.0000001, name)
+			// the commitment is used up in the hours of three instances and covers two instances in the rest
+			used := 90*c + 10*2*o.hourlyRate
+			coveredOd = used * onDemand / o.hourlyRate
+			upfrontShare := map[string]float64{"No Upfront": 0, "Partial Upfront": 0.5, "All Upfront": 1}[o.purchaseOption]
+			upfront = c * termHours(o.term) * upfrontShare
+			recurring = c * awsHoursPerMonth * (1 - upfrontShare)
+			effective = c * awsHoursPerMonth
+			utilization = used / (c * 100) * 100
+		}
+		if o.commitmentType == model.AWSCommitmentTypeEC2InstanceSavingsPlans {
+			assert.Equal(t, "m5", r.InstanceFamily, name)
+			assert.Equal(t, "us-east-1", r.Region, name)
+		}
+
+		assert.InDelta(t, upfront, r.UpfrontCost, 0.0001, name)
+		assert.InDelta(t, recurring, r.RecurringMonthlyCost, 0.0001, name)
+		assert.InDelta(t, effective, r.EffectiveMonthlyCost, 0.0001, name)
+		assert.InDelta(t, coveredOd*monthly, r.OnDemandMonthlyCost, 0.0001, name)
+		assert.InDelta(t, coveredOd*monthly-effective, r.EstimatedMonthlySavings, 0.0001, name)
+		assert.Greater(t, r.EstimatedMonthlySavings, 0.0, name)
+		assert.InDelta(t, coveredOd/totalCost*100, r.CoveragePercent, 0.0001, name)
+		assert.InDelta(t, utilization, r.UtilizationPercent, 0.0001, name)
+		if upfront > 0 {
+			assert.InDelta(t, upfront/(coveredOd*monthly-recurring), r.BreakEvenMonths, 0.0001, name)
+		} else {
+			assert.Equal(t, 0.0, r.BreakEvenMonths, name)
+		}
+	}
+
+	// the preferences narrow down the terms, payment options and offering class
+	req.Preferences = map[string]*string{
+		"Terms":          aws.String("1yr"),
+		"PaymentOptions": aws.String("No Upfront"),
+		"OfferingClass":  aws.String("convertible"),
+	}
+	resp, err = s.AwsCommitmentsRecommendation(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Len(t, resp.Recommendations, 3)
+	for _, r := range resp.Recommendations {
+		assert.Equal(t, "1yr", r.Term)
+		assert.Equal(t, "No Upfront", r.PurchaseOption)
+		if r.Type == entity.AwsCommitmentTypeReservedInstance {
+			assert.Equal(t, "convertible", r.OfferingClass)
+		}
+	}
+
+	// two existing reserved instances leave the third instance, it runs in 90% of the hours
+	req.Preferences = nil
+	req.Commitments = []entity.AwsExistingCommitment{{
+		Type:                  entity.AwsCommitmentTypeReservedInstance,
+		AwsCommitmentUsageKey: entity.AwsCommitmentUsageKey{Region: "us-east-1", InstanceType: "m5.large"},
+		Count:                 2,
+	}}
+	resp, err = s.AwsCommitmentsRecommendation(context.Background(), req)
+	assert.NoError(t, err)
+	existingCost := onDemand * 200
+	assert.InDelta(t, existingCost/totalCost*100, resp.ExistingCoveragePercent, 0.0001)
+	assert.InDelta(t, (totalCost-existingCost)*monthly, resp.OnDemandMonthlyCost, 0.0001)
+	r := find(resp.Recommendations, offerings[0])
+	if assert.NotNil(t, r) {
+		assert.Equal(t, int64(1), r.Quantity)
+		assert.InDelta(t, 90.0, r.UtilizationPercent, 0.0001)
+		assert.InDelta(t, (existingCost+onDemand*90)/totalCost*100, r.CoveragePercent, 0.0001)
+	}
+}
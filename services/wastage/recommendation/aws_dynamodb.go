@@ -0,0 +1,161 @@
+package recommendation
+
+import (
+	"context"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/opengovern/opengovernance/services/wastage/api/entity"
+	"math"
+	"strconv"
+)
+
+const (
+	dynamoDBMetricConsumedReadCapacity  = "ConsumedReadCapacityUnits"
+	dynamoDBMetricConsumedWriteCapacity = "ConsumedWriteCapacityUnits"
+
+	dynamoDBReadCapacityUsageType  = "ReadCapacityUnit-Hrs"
+	dynamoDBWriteCapacityUsageType = "WriteCapacityUnit-Hrs"
+	dynamoDBReadRequestUsageType   = "ReadRequestUnits"
+	dynamoDBWriteRequestUsageType  = "WriteRequestUnits"
+)
+
+type dynamoDBPrices struct {
+	readCapacityHour  float64
+	writeCapacityHour float64
+	readRequest       float64
+	writeRequest      float64
+}
+
+func (s *Service) DynamoDBTableRecommendation(
+	ctx context.Context,
+	region string,
+	table entity.DynamoDBTable,
+	metrics map[string][]types.Datapoint,
+	preferences map[string]*string,
+) (*entity.DynamoDBTableRightsizingRecommendation, error) {
+	prices, err := s.getDynamoDBPrices(region)
+	if err != nil {
+		return nil, err
+	}
+
+	readUsage := extractUsage(sumToRateDatapoints(metrics[dynamoDBMetricConsumedReadCapacity]), UsageAverageTypeMax)
+	writeUsage := extractUsage(sumToRateDatapoints(metrics[dynamoDBMetricConsumedWriteCapacity]), UsageAverageTypeMax)
+	monthlyReads := monthlyAmount(metrics[dynamoDBMetricConsumedReadCapacity])
+	monthlyWrites := monthlyAmount(metrics[dynamoDBMetricConsumedWriteCapacity])
+
+	onDemand := dynamoDBOnDemandRightsizing(region, monthlyReads, monthlyWrites, prices)
+
+	var current entity.RightsizingDynamoDBTable
+	switch table.BillingMode {
+	case entity.DynamoDBBillingModePayPerRequest:
+		current = onDemand
+	case entity.DynamoDBBillingModeProvisioned, "":
+		current = dynamoDBProvisionedRightsizing(region, getValueOrZero(table.ProvisionedReadCapacityUnits), getValueOrZero(table.ProvisionedWriteCapacityUnits), prices)
+	default:
+		return nil, fmt.Errorf("unknown dynamodb billing mode %s", table.BillingMode)
+	}
+
+	result := entity.DynamoDBTableRightsizingRecommendation{
+		Current:               current,
+		ConsumedReadCapacity:  readUsage,
+		ConsumedWriteCapacity: writeUsage,
+	}
+
+	capacityBreathingRoom := int64(0)
+	if preferences["CapacityBreathingRoom"] != nil {
+		capacityBreathingRoom, _ = strconv.ParseInt(*preferences["CapacityBreathingRoom"], 10, 64)
+	}
+
+	// provisioned capacity has to cover the peak consumption, the autoscaling of the table is not taken into account
+	neededRead := int64(math.Max(1, math.Ceil(calculateHeadroom(getValueOrZero(readUsage.Max), capacityBreathingRoom))))
+	neededWrite := int64(math.Max(1, math.Ceil(calculateHeadroom(getValueOrZero(writeUsage.Max), capacityBreathingRoom))))
+	provisioned := dynamoDBProvisionedRightsizing(region, neededRead, neededWrite, prices)
+
+	candidates := []entity.RightsizingDynamoDBTable{provisioned, onDemand}
+	if preferences["BillingMode"] != nil && *preferences["BillingMode"] != "" {
+		switch entity.DynamoDBBillingMode(*preferences["BillingMode"]) {
+		case entity.DynamoDBBillingModeProvisioned:
+			candidates = []entity.RightsizingDynamoDBTable{provisioned}
+		case entity.DynamoDBBillingModePayPerRequest:
+			candidates = []entity.RightsizingDynamoDBTable{onDemand}
+		}
+	}
+	for _, c := range candidates {
+		c := c
+		if c.Cost < current.Cost && (result.Recommended == nil || c.Cost < result.Recommended.Cost) {
+			result.Recommended = &c
+		}
+	}
+
+	result.Description = generateDynamoDBDescription(table, result)
+
+	return &result, nil
+}
+
+func (s *Service) getDynamoDBPrices(region string) (dynamoDBPrices, error) {
+	var prices dynamoDBPrices
+	for usageType, price := range map[string]*float64{
+		dynamoDBReadCapacityUsageType:  &prices.readCapacityHour,
+		dynamoDBWriteCapacityUsageType: &prices.writeCapacityHour,
+		dynamoDBReadRequestUsageType:   &prices.readRequest,
+		dynamoDBWriteRequestUsageType:  &prices.writeRequest,
+	} {
+		p, err := s.dynamoDBPriceRepo.Get(region, usageType)
+		if err != nil {
+			return prices, err
+		}
+		if p == nil {
+			return prices, fmt.Errorf("dynamodb %s price not found in region %s", usageType, region)
+		}
+		*price = p.PricePerUnit
+	}
+	return prices, nil
+}
+
+func dynamoDBProvisionedRightsizing(region string, readCapacity, writeCapacity int64, prices dynamoDBPrices) entity.RightsizingDynamoDBTable {
+	readCost := float64(readCapacity) * prices.readCapacityHour * awsHoursPerMonth
+	writeCost := float64(writeCapacity) * prices.writeCapacityHour * awsHoursPerMonth
+	return entity.RightsizingDynamoDBTable{
+		Region:             region,
+		BillingMode:        entity.DynamoDBBillingModeProvisioned,
+		ReadCapacityUnits:  &readCapacity,
+		WriteCapacityUnits: &writeCapacity,
+		Cost:               readCost + writeCost,
+		CostComponents: map[string]float64{
+			"ReadCapacity":  readCost,
+			"WriteCapacity": writeCost,
+		},
+	}
+}
+
+func dynamoDBOnDemandRightsizing(region string, monthlyReads, monthlyWrites float64, prices dynamoDBPrices) entity.RightsizingDynamoDBTable {
+	readCost := monthlyReads * prices.readRequest
+	writeCost := monthlyWrites * prices.writeRequest
+	return entity.RightsizingDynamoDBTable{
+		Region:      region,
+		BillingMode: entity.DynamoDBBillingModePayPerRequest,
+		Cost:        readCost + writeCost,
+		CostComponents: map[string]float64{
+			"ReadRequests":  readCost,
+			"WriteRequests": writeCost,
+		},
+	}
+}
+
+func generateDynamoDBDescription(table entity.DynamoDBTable, result entity.DynamoDBTableRightsizingRecommendation) string {
+	description := fmt.Sprintf("The table is billed in %s mode for $%.2f a month.", result.Current.BillingMode, result.Current.Cost)
+	if table.BillingMode != entity.DynamoDBBillingModePayPerRequest {
+		description += fmt.Sprintf(" It has %d read and %d write capacity units provisioned.",
+			getValueOrZero(table.ProvisionedReadCapacityUnits), getValueOrZero(table.ProvisionedWriteCapacityUnits))
+	}
+	description += fmt.Sprintf(" It consumes up to %.2f read and %.2f write capacity units per second.",
+		getValueOrZero(result.ConsumedReadCapacity.Max), getValueOrZero(result.ConsumedWriteCapacity.Max))
+	if result.Recommended == nil {
+		return description + " The current capacity mode is already the cheapest."
+	}
+	if result.Recommended.BillingMode == entity.DynamoDBBillingModeProvisioned {
+		return description + fmt.Sprintf(" Provisioning %d read and %d write capacity units would cost $%.2f a month.",
+			*result.Recommended.ReadCapacityUnits, *result.Recommended.WriteCapacityUnits, result.Recommended.Cost)
+	}
+	return description + fmt.Sprintf(" Switching to on-demand capacity would cost $%.2f a month.", result.Recommended.Cost)
+}
@@ -0,0 +1,341 @@
+package recommendation
+
+import (
+	"context"
+	"fmt"
+	types2 "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/labstack/echo/v4"
+	"github.com/opengovern/opengovernance/services/wastage/api/entity"
+	"github.com/opengovern/opengovernance/services/wastage/db/model"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// spotEstimatedDiscount is used for the instance types without a spot price in the request, spot prices are usually
+// lower but they follow the spare capacity of every pool.
+const spotEstimatedDiscount = 0.5
+
+var (
+	ec2StatelessTagKeys = []string{"stateless", "spot", "spot-ok", "spot-ready", "interruptible"}
+	ec2StatefulTagKeys  = []string{"stateful", "persistent", "database"}
+)
+
+type ec2ArchitectureTarget struct {
+	arch string
+	// processor is a like pattern of the physical processor, empty for any processor of the architecture
+	processor string
+	name      string
+}
+
+// ec2InstanceAlternatives makes the recommendations of the requested modes, base is the instance type the spot
+// price is compared on, the rightsized one if there is any.
+func (s *Service) ec2InstanceAlternatives(
+	ctx context.Context,
+	instance entity.EC2Instance,
+	volumes []entity.EC2Volume,
+	metrics map[string][]types2.Datapoint,
+	current entity.RightsizingEC2Instance,
+	currentType, base model.EC2InstanceType,
+	neededNetworkThroughput float64,
+	pref map[string]any,
+	modes []entity.EC2RecommendationMode,
+	workload *entity.EC2Workload,
+) ([]entity.EC2AlternativeRecommendation, error) {
+	if workload == nil {
+		workload = &entity.EC2Workload{}
+	}
+
+	var alternatives []entity.EC2AlternativeRecommendation
+	for _, mode := range modes {
+		switch mode {
+		case entity.EC2RecommendationModeArchitecture:
+			archAlternatives, err := s.ec2ArchitectureAlternatives(ctx, instance, volumes, metrics, current, currentType, neededNetworkThroughput, pref, *workload)
+			if err != nil {
+				return nil, err
+			}
+			alternatives = append(alternatives, archAlternatives...)
+		case entity.EC2RecommendationModeSpot:
+			spotAlternative, err := s.ec2SpotAlternative(ctx, instance, volumes, metrics, current, base, *workload)
+			if err != nil {
+				return nil, err
+			}
+			if spotAlternative != nil {
+				alternatives = append(alternatives, *spotAlternative)
+			}
+		default:
+			return nil, echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("unknown recommendation mode: %s", mode))
+		}
+	}
+
+	sort.SliceStable(alternatives, func(i, j int) bool {
+		return alternatives[i].MonthlySavings > alternatives[j].MonthlySavings
+	})
+	return alternatives, nil
+}
+
+// ec2ArchitectureAlternatives finds the cheapest instance type of every other architecture and processor vendor
+// that fits the same needs as the rightsizing.
+func (s *Service) ec2ArchitectureAlternatives(
+	ctx context.Context,
+	instance entity.EC2Instance,
+	volumes []entity.EC2Volume,
+	metrics map[string][]types2.Datapoint,
+	current entity.RightsizingEC2Instance,
+	currentType model.EC2InstanceType,
+	neededNetworkThroughput float64,
+	pref map[string]any,
+	workload entity.EC2Workload,
+) ([]entity.EC2AlternativeRecommendation, error) {
+	var alternatives []entity.EC2AlternativeRecommendation
+	for _, target := range ec2ArchitectureTargets(currentType) {
+		caveats, risk, ok := ec2ArchitectureCompatibility(currentType, target, workload)
+		if !ok {
+			continue
+		}
+
+		targetPref := make(map[string]any)
+		for k, v := range pref {
+			// the architecture related preferences pin the current architecture
+			if strings.HasPrefix(k, "physical_processor") || strings.HasPrefix(k, "instance_family") {
+				continue
+			}
+			targetPref[k] = v
+		}
+		delete(targetPref, "pre_installed_sw = ?")
+		targetPref["operation = ?"] = currentType.Operation
+		targetPref["physical_processor_arch = ?"] = target.arch
+		if target.processor != "" {
+			targetPref["physical_processor like ?"] = target.processor
+		}
+
+		instanceType, err := s.ec2InstanceRepo.GetCheapestByCoreAndNetwork(ctx, neededNetworkThroughput, targetPref)
+		if err != nil {
+			err = fmt.Errorf("failed to find cheapest %s ec2 instance: %s", target.name, err.Error())
+			return nil, err
+		}
+		if instanceType == nil {
+			continue
+		}
+		alternative, err := s.ec2RightsizingInstance(ctx, instance, volumes, metrics, *instanceType)
+		if err != nil {
+			return nil, err
+		}
+		if current.Cost-alternative.Cost <= 0 {
+			continue
+		}
+
+		alternatives = append(alternatives, entity.EC2AlternativeRecommendation{
+			Mode:           entity.EC2RecommendationModeArchitecture,
+			Instance:       *alternative,
+			MonthlySavings: current.Cost - alternative.Cost,
+			Risk:           risk,
+			Caveats:        caveats,
+		})
+	}
+	return alternatives, nil
+}
+
+func ec2ArchitectureTargets(currentType model.EC2InstanceType) []ec2ArchitectureTarget {
+	processor := strings.ToLower(currentType.PhysicalProcessor)
+	switch currentType.PhysicalProcessorArch {
+	case "x86_64":
+		targets := []ec2ArchitectureTarget{{arch: "arm64", name: "Graviton"}}
+		if !strings.Contains(processor, "amd") {
+			targets = append(targets, ec2ArchitectureTarget{arch: "x86_64", processor: "%AMD%", name: "AMD"})
+		}
+		if !strings.Contains(processor, "intel") {
+			targets = append(targets, ec2ArchitectureTarget{arch: "x86_64", processor: "%Intel%", name: "Intel"})
+		}
+		return targets
+	case "arm64":
+		return []ec2ArchitectureTarget{{arch: "x86_64", name: "x86_64"}}
+	}
+	// mac instances run on dedicated hosts of their own architecture
+	return nil
+}
+
+// ec2ArchitectureCompatibility tells whether the workload can move to the target with the AMI it runs today,
+// and what has to be checked before moving.
+func ec2ArchitectureCompatibility(currentType model.EC2InstanceType, target ec2ArchitectureTarget, workload entity.EC2Workload) ([]string, entity.EC2RecommendationRisk, bool) {
+	if target.arch == currentType.PhysicalProcessorArch {
+		return []string{
+			fmt.Sprintf("The same AMI runs on %s processors, software tuned for the instruction set extensions of %s may perform differently", target.name, currentType.PhysicalProcessor),
+		}, entity.EC2RecommendationRiskLow, true
+	}
+
+	platform := strings.ToLower(workload.ImagePlatformDetails)
+	if target.arch == "arm64" && strings.Contains(platform, "windows") {
+		return nil, "", false
+	}
+
+	var caveats []string
+	switch strings.ToLower(workload.ImageArchitecture) {
+	case "":
+		caveats = append(caveats, fmt.Sprintf("The architecture of the AMI is unknown, an %s AMI of the same operating system is needed", target.arch))
+	case target.arch:
+	default:
+		caveats = append(caveats, fmt.Sprintf("The AMI is %s, the instance needs an %s AMI of the same operating system", workload.ImageArchitecture, target.arch))
+	}
+	caveats = append(caveats, fmt.Sprintf("The software and its dependencies, e.g. native libraries and container images, need %s builds", target.arch))
+	if strings.Contains(platform, "sql server") {
+		caveats = append(caveats, "SQL Server images are not published for every architecture")
+		return caveats, entity.EC2RecommendationRiskHigh, true
+	}
+	return caveats, entity.EC2RecommendationRiskMedium, true
+}
+
+// ec2SpotAlternative prices running the base instance type on spot, the risk comes from how well the workload
+// tolerates the interruptions.
+func (s *Service) ec2SpotAlternative(
+	ctx context.Context,
+	instance entity.EC2Instance,
+	volumes []entity.EC2Volume,
+	metrics map[string][]types2.Datapoint,
+	current entity.RightsizingEC2Instance,
+	base model.EC2InstanceType,
+	workload entity.EC2Workload,
+) (*entity.EC2AlternativeRecommendation, error) {
+	if instance.InstanceLifecycle == types.InstanceLifecycleTypeSpot {
+		return nil, nil
+	}
+	// spot instances can not be launched on dedicated hosts
+	if instance.Tenancy == types.TenancyHost || (instance.Placement != nil && instance.Placement.Tenancy == types.TenancyHost) {
+		return nil, nil
+	}
+
+	score, caveats := ec2SpotSuitability(workload, metrics["CPUUtilization"], time.Now())
+
+	onDemandHourly := base.PricePerUnit
+	spotHourly, ok := workload.SpotPrices[base.InstanceType]
+	if !ok || spotHourly <= 0 {
+		spotHourly = onDemandHourly * (1 - spotEstimatedDiscount)
+		caveats = append(caveats, fmt.Sprintf("No spot price of %s was sent, an estimated discount of %.0f%% is used", base.InstanceType, spotEstimatedDiscount*100))
+	}
+	if spotHourly >= onDemandHourly {
+		return nil, nil
+	}
+
+	alternative, err := s.ec2RightsizingInstance(ctx, instance, volumes, metrics, base)
+	if err != nil {
+		return nil, err
+	}
+	spotDiscount := (onDemandHourly - spotHourly) * awsHoursPerMonth
+	alternative.Cost -= spotDiscount
+	costComponents := make(map[string]float64)
+	for k, v := range alternative.CostComponents {
+		costComponents[k] = v
+	}
+	costComponents["Spot discount"] = -spotDiscount
+	alternative.CostComponents = costComponents
+
+	if current.Cost-alternative.Cost <= 0 {
+		return nil, nil
+	}
+	return &entity.EC2AlternativeRecommendation{
+		Mode:           entity.EC2RecommendationModeSpot,
+		Instance:       *alternative,
+		MonthlySavings: current.Cost - alternative.Cost,
+		Risk:           ec2SpotRisk(score),
+		SpotScore:      score,
+		Caveats:        caveats,
+	}, nil
+}
+
+// ec2SpotSuitability scores from 0 to 100 how well the workload tolerates spot interruptions, from its auto scaling
+// group membership, its statelessness tags and its uptime pattern.
+func ec2SpotSuitability(workload entity.EC2Workload, cpuDatapoints []types2.Datapoint, now time.Time) (int, []string) {
+	score := 30
+	var caveats []string
+
+	if workload.AutoScalingGroup {
+		score += 30
+	} else {
+		caveats = append(caveats, "The instance is not in an auto scaling group, nothing replaces it when it is interrupted")
+	}
+
+	stateless := ec2StatelessTag(workload.Tags)
+	switch {
+	case stateless == nil:
+		caveats = append(caveats, "The instance is not tagged as stateless, make sure no data is kept only on the instance")
+	case *stateless:
+		score += 30
+	default:
+		score -= 30
+		caveats = append(caveats, "The instance is tagged as stateful, the data on the instance is lost when it is interrupted")
+	}
+
+	// instances that do not run all the time, e.g. batch jobs, are usually fine with being interrupted
+	if window := datapointsWindow(cpuDatapoints); window > 0 {
+		running := time.Duration(len(cpuDatapoints)) * datapointsPeriod(cpuDatapoints)
+		if float64(running)/float64(window) < 0.9 {
+			score += 10
+		}
+	}
+	if workload.LaunchTime != nil {
+		age := now.Sub(*workload.LaunchTime)
+		if age < 7*24*time.Hour {
+			score += 10
+		} else if age > 90*24*time.Hour && !workload.AutoScalingGroup {
+			score -= 10
+			caveats = append(caveats, fmt.Sprintf("The instance has been running for %d days, long running instances are usually not built to be replaced", int(age.Hours()/24)))
+		}
+	}
+
+	if score < 0 {
+		score = 0
+	} else if score > 100 {
+		score = 100
+	}
+	return score, caveats
+}
+
+// ec2StatelessTag returns whether the tags mark the instance as stateless or stateful, nil when they tell neither.
+func ec2StatelessTag(tags map[string]string) *bool {
+	isTrue := func(v string) bool {
+		switch strings.ToLower(strings.TrimSpace(v)) {
+		case "true", "yes", "1", "enabled":
+			return true
+		}
+		return false
+	}
+	for k, v := range tags {
+		k = strings.ToLower(k)
+		for _, key := range ec2StatefulTagKeys {
+			if k == key && isTrue(v) {
+				stateless := false
+				return &stateless
+			}
+		}
+		if (k == "workload" || k == "workload-type") && strings.EqualFold(v, "stateful") {
+			stateless := false
+			return &stateless
+		}
+	}
+	for k, v := range tags {
+		k = strings.ToLower(k)
+		for _, key := range ec2StatelessTagKeys {
+			if k == key && isTrue(v) {
+				stateless := true
+				return &stateless
+			}
+		}
+		if (k == "workload" || k == "workload-type") && strings.EqualFold(v, "stateless") {
+			stateless := true
+			return &stateless
+		}
+	}
+	return nil
+}
+
+func ec2SpotRisk(score int) entity.EC2RecommendationRisk {
+	switch {
+	case score >= 70:
+		return entity.EC2RecommendationRiskLow
+	case score >= 40:
+		return entity.EC2RecommendationRiskMedium
+	}
+	return entity.EC2RecommendationRiskHigh
+}
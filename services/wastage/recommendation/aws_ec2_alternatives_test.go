@@ -0,0 +1,57 @@
+package recommendation
+
+import (
+	types2 "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/opengovern/opengovernance/services/wastage/api/entity"
+	"github.com/opengovern/opengovernance/services/wastage/db/model"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestEc2SpotSuitability(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	score, caveats := ec2SpotSuitability(entity.EC2Workload{
+		AutoScalingGroup: true,
+		Tags:             map[string]string{"Stateless": "true"},
+	}, nil, now)
+	assert.Equal(t, 90, score)
+	assert.Empty(t, caveats)
+	assert.Equal(t, entity.EC2RecommendationRiskLow, ec2SpotRisk(score))
+
+	launch := now.Add(-200 * 24 * time.Hour)
+	score, caveats = ec2SpotSuitability(entity.EC2Workload{
+		Tags:       map[string]string{"workload-type": "stateful"},
+		LaunchTime: &launch,
+	}, nil, now)
+	assert.Equal(t, 0, score)
+	assert.Len(t, caveats, 3)
+	assert.Equal(t, entity.EC2RecommendationRiskHigh, ec2SpotRisk(score))
+
+	// running 3 of the 4 hours counts as an intermittent workload
+	var datapoints []types2.Datapoint
+	for _, h := range []int{0, 1, 3} {
+		ts := now.Add(time.Duration(h) * time.Hour)
+		datapoints = append(datapoints, types2.Datapoint{Timestamp: &ts})
+	}
+	score, _ = ec2SpotSuitability(entity.EC2Workload{AutoScalingGroup: true}, datapoints, now)
+	assert.Equal(t, 70, score)
+}
+
+func TestEc2ArchitectureTargets(t *testing.T) {
+	targets := ec2ArchitectureTargets(model.EC2InstanceType{PhysicalProcessorArch: "x86_64", PhysicalProcessor: "Intel Xeon Platinum 8175"})
+	assert.Len(t, targets, 2)
+	assert.Equal(t, "arm64", targets[0].arch)
+	assert.Equal(t, "%AMD%", targets[1].processor)
+
+	_, _, ok := ec2ArchitectureCompatibility(model.EC2InstanceType{PhysicalProcessorArch: "x86_64"}, targets[0], entity.EC2Workload{ImagePlatformDetails: "Windows"})
+	assert.False(t, ok)
+
+	caveats, risk, ok := ec2ArchitectureCompatibility(model.EC2InstanceType{PhysicalProcessorArch: "x86_64"}, targets[0], entity.EC2Workload{ImageArchitecture: "x86_64", ImagePlatformDetails: "Linux/UNIX"})
+	assert.True(t, ok)
+	assert.Equal(t, entity.EC2RecommendationRiskMedium, risk)
+	assert.Len(t, caveats, 2)
+
+	assert.Empty(t, ec2ArchitectureTargets(model.EC2InstanceType{PhysicalProcessorArch: "arm64_mac"}))
+}
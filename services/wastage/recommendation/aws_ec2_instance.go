@@ -100,7 +100,7 @@ func (s *Service) EC2InstanceRecommendationGrpc(
 		zap.Any("volumes", newVolumes), zap.Any("metrics len", len(metrics)), zap.Any("volumeMetrics len", len(newVolumeMetrics)),
 		zap.Any("preferences", newPreferences), zap.Any("usageAverageType", usageAverageType))
 
-	result, err := s.EC2InstanceRecommendation(ctx, region, newInstance, newVolumes, newMetrics, newVolumeMetrics, newPreferences, usageAverageType)
+	result, err := s.EC2InstanceRecommendation(ctx, region, newInstance, newVolumes, newMetrics, newVolumeMetrics, newPreferences, usageAverageType, nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -196,6 +196,8 @@ func (s *Service) EC2InstanceRecommendation(
 	volumeMetrics map[string]map[string][]types2.Datapoint,
 	preferences map[string]*string,
 	usageAverageType UsageAverageType,
+	modes []entity.EC2RecommendationMode,
+	workload *entity.EC2Workload,
 ) (*entity.RightSizingRecommendation, error) {
 	cpuUsage := extractUsage(metrics["CPUUtilization"], usageAverageType)
 	memoryUsage := extractUsage(metrics["mem_used_percent"], usageAverageType)
@@ -344,49 +346,10 @@ func (s *Service) EC2InstanceRecommendation(
 		return nil, err
 	}
 	if rightSizedInstanceType != nil {
-		newInstance := instance
-		newInstance.InstanceType = types.InstanceType(rightSizedInstanceType.InstanceType)
-		newInstance.UsageOperation = rightSizedInstanceType.Operation
-		if newInstance.Placement == nil {
-			newInstance.Placement = &entity.EC2Placement{}
-		}
-		if rightSizedInstanceType.Tenancy == "Dedicated" {
-			newInstance.Placement.Tenancy = types.TenancyDedicated
-		} else if rightSizedInstanceType.Tenancy == "Host" {
-			newInstance.Placement.Tenancy = types.TenancyHost
-		} else {
-			newInstance.Placement.Tenancy = types.TenancyDefault
-		}
-		recommendedCost, recommendedComponentCost, err := s.costSvc.GetEC2InstanceCost(ctx, rightSizedInstanceType.RegionCode, newInstance, volumes, metrics)
+		recommended, err = s.ec2RightsizingInstance(ctx, instance, volumes, metrics, *rightSizedInstanceType)
 		if err != nil {
-			err = fmt.Errorf("failed to get recommended ec2 instance cost: %s", err.Error())
 			return nil, err
 		}
-		recomLicensePrice, err := s.costSvc.EstimateLicensePrice(ctx, newInstance)
-		if err != nil {
-			err = fmt.Errorf("failed to get recommended ec2 instance license price: %s", err.Error())
-			return nil, err
-		}
-		recommended = &entity.RightsizingEC2Instance{
-			Region:            rightSizedInstanceType.RegionCode,
-			InstanceType:      rightSizedInstanceType.InstanceType,
-			Processor:         rightSizedInstanceType.PhysicalProcessor,
-			Architecture:      rightSizedInstanceType.PhysicalProcessorArch,
-			VCPU:              int64(rightSizedInstanceType.VCpu),
-			Memory:            rightSizedInstanceType.MemoryGB,
-			NetworkThroughput: rightSizedInstanceType.NetworkPerformance,
-			ENASupported:      rightSizedInstanceType.EnhancedNetworkingSupported,
-			Cost:              recommendedCost,
-			CostComponents:    recommendedComponentCost,
-			LicensePrice:      recomLicensePrice,
-			License:           newInstance.UsageOperation,
-		}
-		if rightSizedInstanceType.EbsBaselineThroughput != nil {
-			recommended.EBSBandwidth = fmt.Sprintf("%.2f MB/s", *rightSizedInstanceType.EbsBaselineThroughput)
-		}
-		if rightSizedInstanceType.EbsBaselineIops != nil {
-			recommended.EBSIops = fmt.Sprintf("%d io/s", *rightSizedInstanceType.EbsBaselineIops)
-		}
 	}
 
 	recommendation := entity.RightSizingRecommendation{
@@ -402,6 +365,17 @@ func (s *Service) EC2InstanceRecommendation(
 		recommendation.Memory = memoryUsage
 	}
 
+	if len(modes) > 0 {
+		base := currentInstanceType
+		if rightSizedInstanceType != nil {
+			base = *rightSizedInstanceType
+		}
+		recommendation.Alternatives, err = s.ec2InstanceAlternatives(ctx, instance, volumes, metrics, current, currentInstanceType, base, neededNetworkThroughput, pref, modes, workload)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	if preferences["ExcludeUpsizingFeature"] != nil {
 		if *preferences["ExcludeUpsizingFeature"] == "Yes" {
 			if recommendation.Recommended != nil && recommendation.Recommended.Cost > recommendation.Current.Cost {
@@ -420,6 +394,58 @@ func (s *Service) EC2InstanceRecommendation(
 
 	return &recommendation, nil
 }
+
+// ec2RightsizingInstance prices the instance when it is moved to the given instance type.
+func (s *Service) ec2RightsizingInstance(ctx context.Context, instance entity.EC2Instance, volumes []entity.EC2Volume, metrics map[string][]types2.Datapoint, instanceType model.EC2InstanceType) (*entity.RightsizingEC2Instance, error) {
+	newInstance := instance
+	newInstance.InstanceType = types.InstanceType(instanceType.InstanceType)
+	newInstance.UsageOperation = instanceType.Operation
+	if newInstance.Placement == nil {
+		newInstance.Placement = &entity.EC2Placement{}
+	} else {
+		placement := *newInstance.Placement
+		newInstance.Placement = &placement
+	}
+	if instanceType.Tenancy == "Dedicated" {
+		newInstance.Placement.Tenancy = types.TenancyDedicated
+	} else if instanceType.Tenancy == "Host" {
+		newInstance.Placement.Tenancy = types.TenancyHost
+	} else {
+		newInstance.Placement.Tenancy = types.TenancyDefault
+	}
+	cost, componentCost, err := s.costSvc.GetEC2InstanceCost(ctx, instanceType.RegionCode, newInstance, volumes, metrics)
+	if err != nil {
+		err = fmt.Errorf("failed to get recommended ec2 instance cost: %s", err.Error())
+		return nil, err
+	}
+	licensePrice, err := s.costSvc.EstimateLicensePrice(ctx, newInstance)
+	if err != nil {
+		err = fmt.Errorf("failed to get recommended ec2 instance license price: %s", err.Error())
+		return nil, err
+	}
+	rightsizing := &entity.RightsizingEC2Instance{
+		Region:            instanceType.RegionCode,
+		InstanceType:      instanceType.InstanceType,
+		Processor:         instanceType.PhysicalProcessor,
+		Architecture:      instanceType.PhysicalProcessorArch,
+		VCPU:              int64(instanceType.VCpu),
+		Memory:            instanceType.MemoryGB,
+		NetworkThroughput: instanceType.NetworkPerformance,
+		ENASupported:      instanceType.EnhancedNetworkingSupported,
+		Cost:              cost,
+		CostComponents:    componentCost,
+		LicensePrice:      licensePrice,
+		License:           newInstance.UsageOperation,
+	}
+	if instanceType.EbsBaselineThroughput != nil {
+		rightsizing.EBSBandwidth = fmt.Sprintf("%.2f MB/s", *instanceType.EbsBaselineThroughput)
+	}
+	if instanceType.EbsBaselineIops != nil {
+		rightsizing.EBSIops = fmt.Sprintf("%d io/s", *instanceType.EbsBaselineIops)
+	}
+	return rightsizing, nil
+}
+
 func bpsToMBps(bps *float64) float64 {
 	if bps == nil {
 		return 0
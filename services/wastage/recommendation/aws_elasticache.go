@@ -0,0 +1,191 @@
+package recommendation
+
+import (
+	"context"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/opengovern/opengovernance/services/wastage/api/entity"
+	"github.com/opengovern/opengovernance/services/wastage/db/model"
+	"github.com/opengovern/opengovernance/services/wastage/recommendation/preferences/aws_elasticache"
+	"strconv"
+	"strings"
+)
+
+const (
+	elastiCacheMetricCPU                    = "CPUUtilization"
+	elastiCacheMetricEngineCPU              = "EngineCPUUtilization"
+	elastiCacheMetricBytesUsedForCache      = "BytesUsedForCache"
+	elastiCacheMetricBytesUsedForCacheItems = "BytesUsedForCacheItems"
+
+	// elastiCacheRedisReservedMemory is the default reserved-memory-percent of redis and valkey parameter groups,
+	// the memory left for backups and replication on top of the dataset.
+	elastiCacheRedisReservedMemory = 25
+)
+
+func (s *Service) ElastiCacheClusterRecommendation(
+	ctx context.Context,
+	region string,
+	cluster entity.ElastiCacheCluster,
+	metrics map[string][]types.Datapoint,
+	preferences map[string]*string,
+	usageAverageType UsageAverageType,
+) (*entity.ElastiCacheClusterRightsizingRecommendation, error) {
+	if cluster.NodeType == "" {
+		return nil, fmt.Errorf("no node type provided")
+	}
+	if cluster.NumNodes <= 0 {
+		cluster.NumNodes = 1
+	}
+
+	current, err := s.elastiCacheNodeTypeRepo.Get(region, cluster.Engine, cluster.NodeType)
+	if err != nil {
+		return nil, err
+	}
+	if current == nil {
+		return nil, fmt.Errorf("elasticache node type %s for %s not found in region %s", cluster.NodeType, cluster.Engine, region)
+	}
+
+	isRedis := !strings.EqualFold(cluster.Engine, "memcached")
+
+	cpuUsage := extractUsage(metrics[elastiCacheMetricCPU], usageAverageType)
+	memoryMetric := elastiCacheMetricBytesUsedForCacheItems
+	if isRedis {
+		memoryMetric = elastiCacheMetricBytesUsedForCache
+	}
+	// memory is always sized for the peak usage, running out of it means evictions
+	memoryUsage := extractUsage(metrics[memoryMetric], UsageAverageTypeMax)
+
+	result := entity.ElastiCacheClusterRightsizingRecommendation{
+		Current:         elastiCacheNodeTypeToRightsizing(*current, region, cluster.NumNodes),
+		CPU:             cpuUsage,
+		MemoryUsedBytes: memoryUsage,
+	}
+
+	cpuBreathingRoom := int64(0)
+	if preferences["CPUBreathingRoom"] != nil {
+		cpuBreathingRoom, _ = strconv.ParseInt(*preferences["CPUBreathingRoom"], 10, 64)
+	}
+	memoryBreathingRoom := int64(0)
+	if preferences["MemoryBreathingRoom"] != nil {
+		memoryBreathingRoom, _ = strconv.ParseInt(*preferences["MemoryBreathingRoom"], 10, 64)
+	}
+
+	neededCPU := current.VCpu
+	if cpuUsage.Avg != nil {
+		neededCPU = current.VCpu * calculateHeadroom(*cpuUsage.Avg, cpuBreathingRoom) / 100.0
+	}
+	// redis runs its commands on a single thread, a busy engine thread is not helped by a smaller node
+	engineCPU := extractUsage(metrics[elastiCacheMetricEngineCPU], usageAverageType)
+	if isRedis && engineCPU.Avg != nil && *engineCPU.Avg > 90 {
+		neededCPU = current.VCpu
+	}
+
+	neededMemoryGb := current.MemoryGb
+	if memoryUsage.Max != nil {
+		neededMemoryGb = calculateHeadroom(*memoryUsage.Max/(1024*1024*1024), memoryBreathingRoom)
+		if isRedis {
+			neededMemoryGb = calculateHeadroom(neededMemoryGb, elastiCacheRedisReservedMemory)
+		}
+	}
+
+	pref := map[string]any{}
+	for k, v := range preferences {
+		if _, ok := aws_elasticache.PreferenceNodeDBKey[k]; !ok {
+			continue
+		}
+		var vl any
+		if v == nil || *v == "" {
+			vl = extractFromElastiCacheNodeType(*current, k)
+		} else {
+			vl = *v
+		}
+
+		cond := "="
+		if sc, ok := aws_elasticache.PreferenceNodeSpecialCond[k]; ok {
+			cond = sc
+		}
+		switch k {
+		case "vCPU", "MemoryGB":
+			if str, ok := vl.(string); ok {
+				vl, _ = strconv.ParseFloat(str, 64)
+			}
+		}
+		pref[fmt.Sprintf("%s %s ?", aws_elasticache.PreferenceNodeDBKey[k], cond)] = vl
+	}
+	if preferences["ExcludeBurstableInstances"] != nil && *preferences["ExcludeBurstableInstances"] == "Yes" {
+		pref["instance_type NOT LIKE ?"] = "cache.t%"
+	}
+
+	suggested, err := s.elastiCacheNodeTypeRepo.GetCheapest(region, cluster.Engine, neededCPU, neededMemoryGb, pref)
+	if err != nil {
+		return nil, err
+	}
+	if suggested != nil {
+		recommended := elastiCacheNodeTypeToRightsizing(*suggested, region, cluster.NumNodes)
+		result.Recommended = &recommended
+	} else {
+		suggested = current
+	}
+
+	result.Description = generateElastiCacheDescription(cluster, current, suggested, cpuUsage, memoryUsage, neededCPU, neededMemoryGb)
+
+	if preferences["ExcludeUpsizingFeature"] != nil && *preferences["ExcludeUpsizingFeature"] == "Yes" {
+		if result.Recommended != nil && result.Recommended.Cost > result.Current.Cost {
+			result.Recommended = &result.Current
+			result.Description = "No recommendation available as upsizing feature is disabled"
+		}
+	}
+
+	return &result, nil
+}
+
+func elastiCacheNodeTypeToRightsizing(nodeType model.ElastiCacheNodeType, region string, numNodes int32) entity.RightsizingElastiCacheCluster {
+	return entity.RightsizingElastiCacheCluster{
+		Region:             region,
+		Engine:             nodeType.CacheEngine,
+		NodeType:           nodeType.InstanceType,
+		NumNodes:           numNodes,
+		VCPU:               nodeType.VCpu,
+		MemoryGb:           nodeType.MemoryGb,
+		NetworkPerformance: nodeType.NetworkPerformance,
+		Cost:               nodeType.PricePerUnit * awsHoursPerMonth * float64(numNodes),
+	}
+}
+
+func extractFromElastiCacheNodeType(nodeType model.ElastiCacheNodeType, k string) any {
+	switch k {
+	case "vCPU":
+		return nodeType.VCpu
+	case "MemoryGB":
+		return nodeType.MemoryGb
+	case "NodeType":
+		return nodeType.InstanceType
+	case "InstanceFamily":
+		return nodeType.InstanceFamily
+	case "CurrentGeneration":
+		return nodeType.CurrentGeneration
+	}
+	return ""
+}
+
+func generateElastiCacheDescription(cluster entity.ElastiCacheCluster, current, suggested *model.ElastiCacheNodeType,
+	cpuUsage, memoryUsage entity.Usage, neededCPU, neededMemoryGb float64) string {
+	description := fmt.Sprintf("The %s cluster runs %d %s node(s) with %.0f vCPUs and %.2f GiB of memory each.",
+		cluster.Engine, cluster.NumNodes, current.InstanceType, current.VCpu, current.MemoryGb)
+	if cpuUsage.Avg != nil {
+		description += fmt.Sprintf(" CPU utilization is %.1f%% on average and %.1f%% at peak.", *cpuUsage.Avg, getValueOrZero(cpuUsage.Max))
+	}
+	if memoryUsage.Max != nil {
+		description += fmt.Sprintf(" The cache uses up to %.2f GiB of memory.", *memoryUsage.Max/(1024*1024*1024))
+	} else {
+		description += " There is no memory usage data, so the memory size is kept."
+	}
+	description += fmt.Sprintf(" With the breathing room the nodes need %.2f vCPUs and %.2f GiB of memory.", neededCPU, neededMemoryGb)
+	if suggested.InstanceType == current.InstanceType {
+		description += " The current node type is already the cheapest one that fits."
+	} else {
+		description += fmt.Sprintf(" %s is the cheapest node type that fits, at $%.2f per node per month instead of $%.2f.",
+			suggested.InstanceType, suggested.PricePerUnit*awsHoursPerMonth, current.PricePerUnit*awsHoursPerMonth)
+	}
+	return description
+}
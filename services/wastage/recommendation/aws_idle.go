@@ -0,0 +1,132 @@
+package recommendation
+
+import (
+	"context"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/opengovern/opengovernance/services/wastage/api/entity"
+	"github.com/opengovern/opengovernance/services/wastage/db/model"
+	"strconv"
+	"time"
+)
+
+const (
+	natGatewayMetricBytesInFromSource      = "BytesInFromSource"
+	natGatewayMetricBytesInFromDestination = "BytesInFromDestination"
+	loadBalancerMetricRequestCount         = "RequestCount"
+	loadBalancerMetricNewFlowCount         = "NewFlowCount"
+)
+
+func (s *Service) AwsIdleResourceRecommendation(
+	ctx context.Context,
+	region string,
+	resource entity.AwsIdleResource,
+	metrics map[string][]types.Datapoint,
+	preferences map[string]*string,
+) (*entity.AwsIdleResourceRecommendation, error) {
+	result := entity.AwsIdleResourceRecommendation{
+		HashedResourceId: resource.HashedResourceId,
+		Type:             resource.Type,
+		CostComponents:   map[string]float64{},
+	}
+
+	switch resource.Type {
+	case entity.AwsIdleResourceTypeElasticIP:
+		price, err := s.getAwsIdleResourcePrice(region, "", "PublicIPv4:IdleAddress", "ElasticIP:IdleAddress")
+		if err != nil {
+			return nil, err
+		}
+		result.CostComponents["IPv4Address"] = price.PricePerUnit * awsHoursPerMonth
+		result.Idle = !resource.Associated
+		if result.Idle {
+			result.Reason = "The Elastic IP is not associated with any instance or network interface."
+		}
+	case entity.AwsIdleResourceTypeNATGateway:
+		hours, err := s.getAwsIdleResourcePrice(region, "", "NatGateway-Hours")
+		if err != nil {
+			return nil, err
+		}
+		bytes, err := s.getAwsIdleResourcePrice(region, "", "NatGateway-Bytes")
+		if err != nil {
+			return nil, err
+		}
+		processedGb := (monthlyAmount(metrics[natGatewayMetricBytesInFromSource]) +
+			monthlyAmount(metrics[natGatewayMetricBytesInFromDestination])) / (1024 * 1024 * 1024)
+		result.CostComponents["Hours"] = hours.PricePerUnit * awsHoursPerMonth
+		result.CostComponents["DataProcessing"] = bytes.PricePerUnit * processedGb
+
+		idleGb := 1.0
+		if preferences["NATGatewayIdleGB"] != nil {
+			if v, err := strconv.ParseFloat(*preferences["NATGatewayIdleGB"], 64); err == nil {
+				idleGb = v
+			}
+		}
+		result.Idle = processedGb < idleGb
+		if result.Idle {
+			result.Reason = fmt.Sprintf("The NAT gateway processes %.2f GB a month, less than %.2f GB.", processedGb, idleGb)
+		}
+	case entity.AwsIdleResourceTypeLoadBalancer:
+		productFamily, metric := "Load Balancer", loadBalancerMetricRequestCount
+		switch resource.LoadBalancerType {
+		case "application":
+			productFamily = "Load Balancer-Application"
+		case "network":
+			productFamily, metric = "Load Balancer-Network", loadBalancerMetricNewFlowCount
+		case "gateway":
+			productFamily, metric = "Load Balancer-Gateway", loadBalancerMetricNewFlowCount
+		case "classic", "":
+		default:
+			return nil, fmt.Errorf("unknown load balancer type %s", resource.LoadBalancerType)
+		}
+		price, err := s.getAwsIdleResourcePrice(region, productFamily, "LoadBalancerUsage")
+		if err != nil {
+			return nil, err
+		}
+		result.CostComponents["Hours"] = price.PricePerUnit * awsHoursPerMonth
+		if _, ok := metrics[metric]; ok {
+			result.Idle = getValueOrZero(sumOfDatapoints(metrics[metric])) == 0
+		}
+		if result.Idle {
+			result.Reason = fmt.Sprintf("The load balancer had no %s in the observed period.", metric)
+		}
+	case entity.AwsIdleResourceTypeEBSSnapshot:
+		price, err := s.getAwsIdleResourcePrice(region, "", "EBS:SnapshotUsage")
+		if err != nil {
+			return nil, err
+		}
+		result.CostComponents["Storage"] = price.PricePerUnit * getValueOrZero(resource.SnapshotSizeGb)
+
+		minAgeDays := int64(30)
+		if preferences["SnapshotMinAgeDays"] != nil {
+			if v, err := strconv.ParseInt(*preferences["SnapshotMinAgeDays"], 10, 64); err == nil {
+				minAgeDays = v
+			}
+		}
+		oldEnough := resource.CreatedAt != nil && time.Since(*resource.CreatedAt) >= time.Duration(minAgeDays)*24*time.Hour
+		result.Idle = !resource.SourceVolumeExists && !resource.UsedByImage && oldEnough
+		if result.Idle {
+			result.Reason = fmt.Sprintf("The snapshot is older than %d days, its source volume is deleted and no image uses it.", minAgeDays)
+		}
+	default:
+		return nil, fmt.Errorf("unknown idle resource type %s", resource.Type)
+	}
+
+	for _, v := range result.CostComponents {
+		result.MonthlyCost += v
+	}
+	return &result, nil
+}
+
+// getAwsIdleResourcePrice returns the price of the first usage type found in the region.
+func (s *Service) getAwsIdleResourcePrice(region, productFamily string, usageTypes ...string) (*model.AWSIdleResourcePrice, error) {
+	for _, usageType := range usageTypes {
+		price, err := s.awsIdleResourcePriceRepo.Get(region, productFamily, usageType)
+		if err != nil {
+			return nil, err
+		}
+		if price != nil {
+			return price, nil
+		}
+	}
+	return nil, fmt.Errorf("price of %v not found in region %s", usageTypes, region)
+}
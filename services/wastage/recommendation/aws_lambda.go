@@ -0,0 +1,163 @@
+package recommendation
+
+import (
+	"context"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/opengovern/opengovernance/services/wastage/api/entity"
+	"math"
+	"strconv"
+)
+
+const (
+	lambdaMetricDuration    = "Duration"
+	lambdaMetricInvocations = "Invocations"
+	// lambdaMetricUsedMemoryMax is published by Lambda Insights, functions without it keep their memory size
+	lambdaMetricUsedMemoryMax = "used_memory_max"
+
+	lambdaArchitectureX86 = "x86_64"
+	lambdaArchitectureARM = "arm64"
+
+	// lambda memory is configured in 1 MB increments between these bounds
+	lambdaMinMemoryMb = 128
+	lambdaMaxMemoryMb = 10240
+)
+
+type lambdaPrices struct {
+	duration float64 // per GB-second
+	requests float64 // per request
+}
+
+func (s *Service) LambdaFunctionRecommendation(
+	ctx context.Context,
+	region string,
+	function entity.LambdaFunction,
+	metrics map[string][]types.Datapoint,
+	preferences map[string]*string,
+) (*entity.LambdaFunctionRightsizingRecommendation, error) {
+	if function.MemorySizeMb <= 0 {
+		return nil, fmt.Errorf("no memory size provided")
+	}
+	if function.Architecture == "" {
+		function.Architecture = lambdaArchitectureX86
+	}
+
+	currentPrices, err := s.getLambdaPrices(region, function.Architecture)
+	if err != nil {
+		return nil, err
+	}
+
+	durationUsage := extractUsage(metrics[lambdaMetricDuration], UsageAverageTypeAverage)
+	memoryUsage := extractUsage(metrics[lambdaMetricUsedMemoryMax], UsageAverageTypeMax)
+	monthlyInvocations := monthlyAmount(metrics[lambdaMetricInvocations])
+	durationMs := getValueOrZero(durationUsage.Avg)
+
+	result := entity.LambdaFunctionRightsizingRecommendation{
+		Current:            lambdaRightsizing(region, function.Architecture, function.MemorySizeMb, monthlyInvocations, durationMs, currentPrices),
+		MaxMemoryUsedMb:    memoryUsage,
+		DurationMs:         durationUsage,
+		MonthlyInvocations: monthlyInvocations,
+	}
+
+	memoryBreathingRoom := int64(0)
+	if preferences["MemoryBreathingRoom"] != nil {
+		memoryBreathingRoom, _ = strconv.ParseInt(*preferences["MemoryBreathingRoom"], 10, 64)
+	}
+
+	neededMemoryMb := function.MemorySizeMb
+	if memoryUsage.Max != nil {
+		neededMemoryMb = roundLambdaMemory(calculateHeadroom(*memoryUsage.Max, memoryBreathingRoom))
+	}
+
+	architecture := function.Architecture
+	if preferences["Architecture"] != nil && *preferences["Architecture"] != "" {
+		architecture = *preferences["Architecture"]
+	}
+	recommendedPrices := currentPrices
+	if architecture != function.Architecture {
+		recommendedPrices, err = s.getLambdaPrices(region, architecture)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if neededMemoryMb != function.MemorySizeMb || architecture != function.Architecture {
+		recommended := lambdaRightsizing(region, architecture, neededMemoryMb, monthlyInvocations, durationMs, recommendedPrices)
+		result.Recommended = &recommended
+	}
+
+	result.Description = generateLambdaDescription(function, result, memoryUsage, durationMs)
+
+	if preferences["ExcludeUpsizingFeature"] != nil && *preferences["ExcludeUpsizingFeature"] == "Yes" {
+		if result.Recommended != nil && result.Recommended.Cost > result.Current.Cost {
+			result.Recommended = &result.Current
+			result.Description = "No recommendation available as upsizing feature is disabled"
+		}
+	}
+
+	return &result, nil
+}
+
+func (s *Service) getLambdaPrices(region, architecture string) (lambdaPrices, error) {
+	durationGroup, requestsGroup := "AWS-Lambda-Duration", "AWS-Lambda-Requests"
+	switch architecture {
+	case lambdaArchitectureX86:
+	case lambdaArchitectureARM:
+		durationGroup, requestsGroup = "AWS-Lambda-Duration-ARM", "AWS-Lambda-Requests-ARM"
+	default:
+		return lambdaPrices{}, fmt.Errorf("unknown lambda architecture %s", architecture)
+	}
+
+	duration, err := s.lambdaPriceRepo.Get(region, durationGroup)
+	if err != nil {
+		return lambdaPrices{}, err
+	}
+	requests, err := s.lambdaPriceRepo.Get(region, requestsGroup)
+	if err != nil {
+		return lambdaPrices{}, err
+	}
+	if duration == nil || requests == nil {
+		return lambdaPrices{}, fmt.Errorf("lambda %s prices not found in region %s", architecture, region)
+	}
+	return lambdaPrices{duration: duration.PricePerUnit, requests: requests.PricePerUnit}, nil
+}
+
+// lambdaRightsizing prices the observed invocations with the given memory size, assuming the duration stays the same.
+func lambdaRightsizing(region, architecture string, memoryMb int32, monthlyInvocations, durationMs float64, prices lambdaPrices) entity.RightsizingLambdaFunction {
+	computeCost := monthlyInvocations * (durationMs / 1000) * (float64(memoryMb) / 1024) * prices.duration
+	requestsCost := monthlyInvocations * prices.requests
+	return entity.RightsizingLambdaFunction{
+		Region:       region,
+		Architecture: architecture,
+		MemorySizeMb: memoryMb,
+		Cost:         computeCost + requestsCost,
+		CostComponents: map[string]float64{
+			"Duration": computeCost,
+			"Requests": requestsCost,
+		},
+	}
+}
+
+func roundLambdaMemory(memoryMb float64) int32 {
+	rounded := int32(math.Ceil(memoryMb))
+	return min(max(rounded, lambdaMinMemoryMb), lambdaMaxMemoryMb)
+}
+
+func generateLambdaDescription(function entity.LambdaFunction, result entity.LambdaFunctionRightsizingRecommendation, memoryUsage entity.Usage, durationMs float64) string {
+	description := fmt.Sprintf("The function has %d MB of memory on %s and runs about %.0f times a month for %.0f ms on average.",
+		function.MemorySizeMb, function.Architecture, result.MonthlyInvocations, durationMs)
+	if memoryUsage.Max == nil {
+		description += " There is no memory usage data, enable Lambda Insights to rightsize the memory."
+	} else {
+		description += fmt.Sprintf(" It uses up to %.0f MB of memory.", *memoryUsage.Max)
+	}
+	if result.Recommended == nil {
+		return description + " The current configuration is already a good fit."
+	}
+	description += fmt.Sprintf(" Running it with %d MB on %s would cost $%.2f a month instead of $%.2f.",
+		result.Recommended.MemorySizeMb, result.Recommended.Architecture, result.Recommended.Cost, result.Current.Cost)
+	if result.Recommended.MemorySizeMb < function.MemorySizeMb {
+		description += " Lambda allocates CPU in proportion to memory, so CPU bound functions may run longer with less memory."
+	}
+	return description
+}
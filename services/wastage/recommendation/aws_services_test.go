@@ -0,0 +1,442 @@
+package recommendation
+
+import (
+	"context"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	types2 "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/opengovern/opengovernance/services/wastage/api/entity"
+	"github.com/opengovern/opengovernance/services/wastage/db/model"
+	"github.com/opengovern/opengovernance/services/wastage/db/repo"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMonthlyAmount(t *testing.T) {
+	n := time.Now()
+	var dps []types2.Datapoint
+	for i := 0; i < 24; i++ {
+		dps = append(dps, types2.Datapoint{Sum: aws.Float64(100), Timestamp: aws.Time(n.Add(time.Duration(i) * time.Hour))})
+	}
+
+	assert.InDelta(t, 100*730, monthlyAmount(dps), 0.001)
+	assert.Equal(t, 0.0, monthlyAmount(nil))
+
+	rates := sumToRateDatapoints(dps)
+	assert.Len(t, rates, 24)
+	assert.InDelta(t, 100.0/3600, *rates[0].Maximum, 0.0001)
+}
+
+func TestRoundLambdaMemory(t *testing.T) {
+	assert.Equal(t, int32(128), roundLambdaMemory(10))
+	assert.Equal(t, int32(129), roundLambdaMemory(129))
+	assert.Equal(t, int32(201), roundLambdaMemory(200.2))
+	assert.Equal(t, int32(10240), roundLambdaMemory(20000))
+}
+
+// hourlyDatapoints returns a datapoint per hour with the same statistics, metrics of sums and averages read the ones they need.
+func hourlyDatapoints(n int, v float64) []types2.Datapoint {
+	start := time.Now().Add(-time.Duration(n) * time.Hour)
+	var dps []types2.Datapoint
+	for i := 0; i < n; i++ {
+		dps = append(dps, types2.Datapoint{
+			Average:   aws.Float64(v),
+			Maximum:   aws.Float64(v),
+			Minimum:   aws.Float64(v),
+			Sum:       aws.Float64(v),
+			Timestamp: aws.Time(start.Add(time.Duration(i) * time.Hour)),
+		})
+	}
+	return dps
+}
+
+type fakeElastiCacheNodeTypeRepo struct {
+	repo.ElastiCacheNodeTypeRepo
+	nodeTypes []model.ElastiCacheNodeType
+}
+
+func (r fakeElastiCacheNodeTypeRepo) Get(region, engine, instanceType string) (*model.ElastiCacheNodeType, error) {
+	for _, n := range r.nodeTypes {
+		if n.RegionCode == region && n.CacheEngine == engine && n.InstanceType == instanceType {
+			n := n
+			return &n, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r fakeElastiCacheNodeTypeRepo) GetCheapest(region, engine string, vCpu, memoryGb float64, pref map[string]interface{}) (*model.ElastiCacheNodeType, error) {
+	var cheapest *model.ElastiCacheNodeType
+	for _, n := range r.nodeTypes {
+		n := n
+		if n.RegionCode != region || n.CacheEngine != engine || n.VCpu < vCpu || n.MemoryGb < memoryGb {
+			continue
+		}
+		if _, ok := pref["instance_type NOT LIKE ?"]; ok && strings.HasPrefix(n.InstanceType, "cache.t") {
+			continue
+		}
+		if cheapest == nil || n.PricePerUnit < cheapest.PricePerUnit {
+			cheapest = &n
+		}
+	}
+	return cheapest, nil
+}
+
+func TestElastiCacheClusterRecommendation(t *testing.T) {
+	nodeType := func(engine, instanceType string, vCpu, memoryGb, price float64) model.ElastiCacheNodeType {
+		return model.ElastiCacheNodeType{InstanceType: instanceType, CacheEngine: engine, RegionCode: "us-east-1", VCpu: vCpu, MemoryGb: memoryGb, PricePerUnit: price}
+	}
+	s := &Service{elastiCacheNodeTypeRepo: fakeElastiCacheNodeTypeRepo{nodeTypes: []model.ElastiCacheNodeType{
+		nodeType("redis", "cache.m5.xlarge", 4, 12.93, 0.311),
+		nodeType("redis", "cache.m5.large", 2, 6.38, 0.156),
+		nodeType("redis", "cache.t3.medium", 2, 3.09, 0.068),
+		nodeType("redis", "cache.t3.small", 2, 1.37, 0.034),
+		nodeType("memcached", "cache.m5.large", 2, 6.38, 0.156),
+		nodeType("memcached", "cache.t3.small", 2, 1.37, 0.034),
+	}}}
+	gb := 1024.0 * 1024 * 1024
+
+	tests := []struct {
+		name        string
+		cluster     entity.ElastiCacheCluster
+		metrics     map[string][]types2.Datapoint
+		preferences map[string]*string
+		want        string
+	}{
+		{
+			// 2 GiB of data needs 2.67 GiB with the reserved memory of redis
+			name:    "redis keeps reserved memory",
+			cluster: entity.ElastiCacheCluster{Engine: "redis", NodeType: "cache.m5.xlarge", NumNodes: 2},
+			metrics: map[string][]types2.Datapoint{
+				elastiCacheMetricCPU:               hourlyDatapoints(24, 10),
+				elastiCacheMetricBytesUsedForCache: hourlyDatapoints(24, 2*gb),
+			},
+			want: "cache.t3.medium",
+		},
+		{
+			// 1.2 GiB would not fit a t3.small with the reserved memory of redis
+			name:    "memcached has no reserved memory",
+			cluster: entity.ElastiCacheCluster{Engine: "memcached", NodeType: "cache.m5.large", NumNodes: 1},
+			metrics: map[string][]types2.Datapoint{
+				elastiCacheMetricCPU:                    hourlyDatapoints(24, 10),
+				elastiCacheMetricBytesUsedForCacheItems: hourlyDatapoints(24, 1.2*gb),
+			},
+			want: "cache.t3.small",
+		},
+		{
+			name:    "burstable node types excluded",
+			cluster: entity.ElastiCacheCluster{Engine: "redis", NodeType: "cache.m5.xlarge", NumNodes: 2},
+			metrics: map[string][]types2.Datapoint{
+				elastiCacheMetricCPU:               hourlyDatapoints(24, 10),
+				elastiCacheMetricBytesUsedForCache: hourlyDatapoints(24, 2*gb),
+			},
+			preferences: map[string]*string{"ExcludeBurstableInstances": aws.String("Yes")},
+			want:        "cache.m5.large",
+		},
+		{
+			name:    "busy redis engine keeps its cpus",
+			cluster: entity.ElastiCacheCluster{Engine: "redis", NodeType: "cache.m5.xlarge", NumNodes: 2},
+			metrics: map[string][]types2.Datapoint{
+				elastiCacheMetricCPU:               hourlyDatapoints(24, 10),
+				elastiCacheMetricEngineCPU:         hourlyDatapoints(24, 95),
+				elastiCacheMetricBytesUsedForCache: hourlyDatapoints(24, 2*gb),
+			},
+			want: "cache.m5.xlarge",
+		},
+		{
+			name:    "upsizing excluded",
+			cluster: entity.ElastiCacheCluster{Engine: "redis", NodeType: "cache.t3.medium", NumNodes: 1},
+			metrics: map[string][]types2.Datapoint{
+				elastiCacheMetricCPU:               hourlyDatapoints(24, 10),
+				elastiCacheMetricBytesUsedForCache: hourlyDatapoints(24, 3*gb),
+			},
+			preferences: map[string]*string{"ExcludeUpsizingFeature": aws.String("Yes")},
+			want:        "cache.t3.medium",
+		},
+	}
+	for _, tt := range tests {
+		recom, err := s.ElastiCacheClusterRecommendation(context.Background(), "us-east-1", tt.cluster, tt.metrics, tt.preferences, UsageAverageTypeMax)
+		assert.NoError(t, err, tt.name)
+		assert.NotNil(t, recom.Recommended, tt.name)
+		assert.Equal(t, tt.want, recom.Recommended.NodeType, tt.name)
+		assert.Equal(t, tt.cluster.NumNodes, recom.Recommended.NumNodes, tt.name)
+		assert.LessOrEqual(t, recom.Recommended.Cost, recom.Current.Cost, tt.name)
+	}
+
+	recom, err := s.ElastiCacheClusterRecommendation(context.Background(), "us-east-1", entity.ElastiCacheCluster{Engine: "redis", NodeType: "cache.m5.xlarge", NumNodes: 2}, nil, nil, UsageAverageTypeMax)
+	assert.NoError(t, err)
+	assert.InDelta(t, 0.311*awsHoursPerMonth*2, recom.Current.Cost, 0.0001)
+
+	_, err = s.ElastiCacheClusterRecommendation(context.Background(), "us-east-1", entity.ElastiCacheCluster{Engine: "redis", NodeType: "cache.r7g.large"}, nil, nil, UsageAverageTypeMax)
+	assert.Error(t, err)
+}
+
+type fakeLambdaPriceRepo struct {
+	repo.LambdaPriceRepo
+	prices map[string]float64
+}
+
+func (r fakeLambdaPriceRepo) Get(region, group string) (*model.LambdaPrice, error) {
+	price, ok := r.prices[group]
+	if !ok {
+		return nil, nil
+	}
+	return &model.LambdaPrice{AWSPriceListItem: model.AWSPriceListItem{RegionCode: region, PriceGroup: group, PricePerUnit: price}}, nil
+}
+
+func TestLambdaFunctionRecommendation(t *testing.T) {
+	s := &Service{lambdaPriceRepo: fakeLambdaPriceRepo{prices: map[string]float64{
+		"AWS-Lambda-Duration":     0.0000166667,
+		"AWS-Lambda-Requests":     0.0000002,
+		"AWS-Lambda-Duration-ARM": 0.0000133334,
+		"AWS-Lambda-Requests-ARM": 0.0000002,
+	}}}
+	metrics := func(usedMemoryMb float64) map[string][]types2.Datapoint {
+		m := map[string][]types2.Datapoint{
+			lambdaMetricDuration:    hourlyDatapoints(24, 100),
+			lambdaMetricInvocations: hourlyDatapoints(24, 100),
+		}
+		if usedMemoryMb > 0 {
+			m[lambdaMetricUsedMemoryMax] = hourlyDatapoints(24, usedMemoryMb)
+		}
+		return m
+	}
+
+	recom, err := s.LambdaFunctionRecommendation(context.Background(), "us-east-1", entity.LambdaFunction{MemorySizeMb: 1024}, metrics(200.4), nil)
+	assert.NoError(t, err)
+	assert.InDelta(t, 100*730, recom.MonthlyInvocations, 0.001)
+	// 73000 invocations of 100 ms with 1 GB
+	assert.InDelta(t, 73000*0.1*0.0000166667, recom.Current.CostComponents["Duration"], 0.000001)
+	assert.InDelta(t, 73000*0.0000002, recom.Current.CostComponents["Requests"], 0.000001)
+	assert.NotNil(t, recom.Recommended)
+	assert.Equal(t, int32(201), recom.Recommended.MemorySizeMb)
+	assert.Equal(t, lambdaArchitectureX86, recom.Recommended.Architecture)
+	assert.Less(t, recom.Recommended.Cost, recom.Current.Cost)
+
+	recom, err = s.LambdaFunctionRecommendation(context.Background(), "us-east-1", entity.LambdaFunction{MemorySizeMb: 1024}, metrics(200), map[string]*string{
+		"MemoryBreathingRoom": aws.String("50"),
+		"Architecture":        aws.String(lambdaArchitectureARM),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(400), recom.Recommended.MemorySizeMb)
+	assert.Equal(t, lambdaArchitectureARM, recom.Recommended.Architecture)
+	assert.InDelta(t, 73000*0.1*(400.0/1024)*0.0000133334, recom.Recommended.CostComponents["Duration"], 0.000001)
+
+	// without lambda insights the memory is kept
+	recom, err = s.LambdaFunctionRecommendation(context.Background(), "us-east-1", entity.LambdaFunction{MemorySizeMb: 1024}, metrics(0), nil)
+	assert.NoError(t, err)
+	assert.Nil(t, recom.Recommended)
+
+	recom, err = s.LambdaFunctionRecommendation(context.Background(), "us-east-1", entity.LambdaFunction{MemorySizeMb: 128}, metrics(500), map[string]*string{
+		"ExcludeUpsizingFeature": aws.String("Yes"),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(128), recom.Recommended.MemorySizeMb)
+
+	_, err = s.LambdaFunctionRecommendation(context.Background(), "us-east-1", entity.LambdaFunction{MemorySizeMb: 128, Architecture: "mips"}, metrics(0), nil)
+	assert.Error(t, err)
+	_, err = (&Service{lambdaPriceRepo: fakeLambdaPriceRepo{}}).LambdaFunctionRecommendation(context.Background(), "us-east-1", entity.LambdaFunction{MemorySizeMb: 128}, metrics(0), nil)
+	assert.Error(t, err)
+}
+
+type fakeDynamoDBPriceRepo struct {
+	repo.DynamoDBPriceRepo
+	prices map[string]float64
+}
+
+func (r fakeDynamoDBPriceRepo) Get(region, usageType string) (*model.DynamoDBPrice, error) {
+	price, ok := r.prices[usageType]
+	if !ok {
+		return nil, nil
+	}
+	return &model.DynamoDBPrice{AWSPriceListItem: model.AWSPriceListItem{RegionCode: region, UsageType: usageType, PricePerUnit: price}}, nil
+}
+
+func TestDynamoDBTableRecommendation(t *testing.T) {
+	s := &Service{dynamoDBPriceRepo: fakeDynamoDBPriceRepo{prices: map[string]float64{
+		dynamoDBReadCapacityUsageType:  0.00013,
+		dynamoDBWriteCapacityUsageType: 0.00065,
+		dynamoDBReadRequestUsageType:   0.00000025,
+		dynamoDBWriteRequestUsageType:  0.00000125,
+	}}}
+	// a read and a write capacity unit per second all the time
+	metrics := map[string][]types2.Datapoint{
+		dynamoDBMetricConsumedReadCapacity:  hourlyDatapoints(24, 3600),
+		dynamoDBMetricConsumedWriteCapacity: hourlyDatapoints(24, 3600),
+	}
+	provisionedCost := func(read, write float64) float64 {
+		return (read*0.00013 + write*0.00065) * awsHoursPerMonth
+	}
+	onDemandCost := 3600*730*0.00000025 + 3600*730*0.00000125
+
+	table := entity.DynamoDBTable{
+		BillingMode:                   entity.DynamoDBBillingModeProvisioned,
+		ProvisionedReadCapacityUnits:  aws.Int64(100),
+		ProvisionedWriteCapacityUnits: aws.Int64(100),
+	}
+	recom, err := s.DynamoDBTableRecommendation(context.Background(), "us-east-1", table, metrics, nil)
+	assert.NoError(t, err)
+	assert.InDelta(t, provisionedCost(100, 100), recom.Current.Cost, 0.0001)
+	assert.InDelta(t, 1.0, *recom.ConsumedReadCapacity.Max, 0.0001)
+	assert.Equal(t, entity.DynamoDBBillingModeProvisioned, recom.Recommended.BillingMode)
+	assert.Equal(t, int64(1), *recom.Recommended.ReadCapacityUnits)
+	assert.Equal(t, int64(1), *recom.Recommended.WriteCapacityUnits)
+	assert.InDelta(t, provisionedCost(1, 1), recom.Recommended.Cost, 0.0001)
+
+	recom, err = s.DynamoDBTableRecommendation(context.Background(), "us-east-1", table, metrics, map[string]*string{
+		"CapacityBreathingRoom": aws.String("50"),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), *recom.Recommended.ReadCapacityUnits)
+
+	recom, err = s.DynamoDBTableRecommendation(context.Background(), "us-east-1", table, metrics, map[string]*string{
+		"BillingMode": aws.String(string(entity.DynamoDBBillingModePayPerRequest)),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, entity.DynamoDBBillingModePayPerRequest, recom.Recommended.BillingMode)
+	assert.InDelta(t, onDemandCost, recom.Recommended.Cost, 0.0001)
+
+	// steady traffic is cheaper with provisioned capacity
+	recom, err = s.DynamoDBTableRecommendation(context.Background(), "us-east-1", entity.DynamoDBTable{BillingMode: entity.DynamoDBBillingModePayPerRequest}, metrics, nil)
+	assert.NoError(t, err)
+	assert.InDelta(t, onDemandCost, recom.Current.Cost, 0.0001)
+	assert.Equal(t, entity.DynamoDBBillingModeProvisioned, recom.Recommended.BillingMode)
+
+	// nothing is recommended when the preferred mode costs more
+	recom, err = s.DynamoDBTableRecommendation(context.Background(), "us-east-1", entity.DynamoDBTable{BillingMode: entity.DynamoDBBillingModePayPerRequest}, metrics, map[string]*string{
+		"BillingMode": aws.String(string(entity.DynamoDBBillingModePayPerRequest)),
+	})
+	assert.NoError(t, err)
+	assert.Nil(t, recom.Recommended)
+
+	_, err = s.DynamoDBTableRecommendation(context.Background(), "us-east-1", entity.DynamoDBTable{BillingMode: "RESERVED"}, metrics, nil)
+	assert.Error(t, err)
+	_, err = (&Service{dynamoDBPriceRepo: fakeDynamoDBPriceRepo{}}).DynamoDBTableRecommendation(context.Background(), "us-east-1", table, metrics, nil)
+	assert.Error(t, err)
+}
+
+type fakeAWSIdleResourcePriceRepo struct {
+	repo.AWSIdleResourcePriceRepo
+	// prices by product family and usage type
+	prices map[[2]string]float64
+}
+
+func (r fakeAWSIdleResourcePriceRepo) Get(region, productFamily, usageType string) (*model.AWSIdleResourcePrice, error) {
+	price, ok := r.prices[[2]string{productFamily, usageType}]
+	if !ok {
+		return nil, nil
+	}
+	return &model.AWSIdleResourcePrice{AWSPriceListItem: model.AWSPriceListItem{RegionCode: region, ProductFamily: productFamily, UsageType: usageType, PricePerUnit: price}}, nil
+}
+
+func TestAwsIdleResourceRecommendation(t *testing.T) {
+	s := &Service{awsIdleResourcePriceRepo: fakeAWSIdleResourcePriceRepo{prices: map[[2]string]float64{
+		{"", "ElasticIP:IdleAddress"}:                      0.005,
+		{"", "NatGateway-Hours"}:                           0.045,
+		{"", "NatGateway-Bytes"}:                           0.045,
+		{"Load Balancer-Application", "LoadBalancerUsage"}: 0.0225,
+		{"Load Balancer-Network", "LoadBalancerUsage"}:     0.0225,
+		{"Load Balancer", "LoadBalancerUsage"}:             0.025,
+		{"", "EBS:SnapshotUsage"}:                          0.05,
+	}}}
+	gb := 1024.0 * 1024 * 1024
+	old := time.Now().Add(-60 * 24 * time.Hour)
+	recent := time.Now().Add(-24 * time.Hour)
+
+	tests := []struct {
+		name        string
+		resource    entity.AwsIdleResource
+		metrics     map[string][]types2.Datapoint
+		preferences map[string]*string
+		wantIdle    bool
+		wantCost    float64
+	}{
+		{
+			// the ipv4 price is not in the region, the elastic ip one is used instead
+			name:     "unassociated elastic ip",
+			resource: entity.AwsIdleResource{Type: entity.AwsIdleResourceTypeElasticIP},
+			wantIdle: true,
+			wantCost: 0.005 * awsHoursPerMonth,
+		},
+		{
+			name:     "associated elastic ip",
+			resource: entity.AwsIdleResource{Type: entity.AwsIdleResourceTypeElasticIP, Associated: true},
+			wantCost: 0.005 * awsHoursPerMonth,
+		},
+		{
+			name:     "nat gateway under the idle traffic",
+			resource: entity.AwsIdleResource{Type: entity.AwsIdleResourceTypeNATGateway},
+			metrics: map[string][]types2.Datapoint{
+				natGatewayMetricBytesInFromSource: hourlyDatapoints(730, gb/2/730),
+			},
+			wantIdle: true,
+			wantCost: 0.045*awsHoursPerMonth + 0.045*0.5,
+		},
+		{
+			name:     "nat gateway over the preferred idle traffic",
+			resource: entity.AwsIdleResource{Type: entity.AwsIdleResourceTypeNATGateway},
+			metrics: map[string][]types2.Datapoint{
+				natGatewayMetricBytesInFromSource: hourlyDatapoints(730, gb/2/730),
+			},
+			preferences: map[string]*string{"NATGatewayIdleGB": aws.String("0.1")},
+			wantCost:    0.045*awsHoursPerMonth + 0.045*0.5,
+		},
+		{
+			name:     "application load balancer without requests",
+			resource: entity.AwsIdleResource{Type: entity.AwsIdleResourceTypeLoadBalancer, LoadBalancerType: "application"},
+			metrics:  map[string][]types2.Datapoint{loadBalancerMetricRequestCount: hourlyDatapoints(24, 0)},
+			wantIdle: true,
+			wantCost: 0.0225 * awsHoursPerMonth,
+		},
+		{
+			name:     "network load balancer with flows",
+			resource: entity.AwsIdleResource{Type: entity.AwsIdleResourceTypeLoadBalancer, LoadBalancerType: "network"},
+			metrics:  map[string][]types2.Datapoint{loadBalancerMetricNewFlowCount: hourlyDatapoints(24, 5)},
+			wantCost: 0.0225 * awsHoursPerMonth,
+		},
+		{
+			name:     "classic load balancer without metrics",
+			resource: entity.AwsIdleResource{Type: entity.AwsIdleResourceTypeLoadBalancer},
+			wantCost: 0.025 * awsHoursPerMonth,
+		},
+		{
+			name:     "orphaned old snapshot",
+			resource: entity.AwsIdleResource{Type: entity.AwsIdleResourceTypeEBSSnapshot, CreatedAt: &old, SnapshotSizeGb: aws.Float64(100)},
+			wantIdle: true,
+			wantCost: 5,
+		},
+		{
+			name:     "orphaned recent snapshot",
+			resource: entity.AwsIdleResource{Type: entity.AwsIdleResourceTypeEBSSnapshot, CreatedAt: &recent, SnapshotSizeGb: aws.Float64(100)},
+			wantCost: 5,
+		},
+		{
+			name:        "orphaned recent snapshot with a shorter min age",
+			resource:    entity.AwsIdleResource{Type: entity.AwsIdleResourceTypeEBSSnapshot, CreatedAt: &recent, SnapshotSizeGb: aws.Float64(100)},
+			preferences: map[string]*string{"SnapshotMinAgeDays": aws.String("0")},
+			wantIdle:    true,
+			wantCost:    5,
+		},
+		{
+			name:     "snapshot used by an image",
+			resource: entity.AwsIdleResource{Type: entity.AwsIdleResourceTypeEBSSnapshot, CreatedAt: &old, SnapshotSizeGb: aws.Float64(100), UsedByImage: true},
+			wantCost: 5,
+		},
+	}
+	for _, tt := range tests {
+		recom, err := s.AwsIdleResourceRecommendation(context.Background(), "us-east-1", tt.resource, tt.metrics, tt.preferences)
+		assert.NoError(t, err, tt.name)
+		assert.Equal(t, tt.wantIdle, recom.Idle, tt.name)
+		assert.Equal(t, tt.wantIdle, recom.Reason != "", tt.name)
+		assert.InDelta(t, tt.wantCost, recom.MonthlyCost, 0.0001, tt.name)
+	}
+
+	// there is no gateway load balancer price
+	_, err := s.AwsIdleResourceRecommendation(context.Background(), "us-east-1", entity.AwsIdleResource{Type: entity.AwsIdleResourceTypeLoadBalancer, LoadBalancerType: "gateway"}, nil, nil)
+	assert.Error(t, err)
+	_, err = s.AwsIdleResourceRecommendation(context.Background(), "us-east-1", entity.AwsIdleResource{Type: entity.AwsIdleResourceTypeLoadBalancer, LoadBalancerType: "internal"}, nil, nil)
+	assert.Error(t, err)
+	_, err = s.AwsIdleResourceRecommendation(context.Background(), "us-east-1", entity.AwsIdleResource{Type: "VPNConnection"}, nil, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,477 @@
+package recommendation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/opengovern/opengovernance/services/wastage/api/entity"
+	"github.com/opengovern/opengovernance/services/wastage/db/model"
+	"github.com/opengovern/opengovernance/services/wastage/recommendation/preferences/azure_vm"
+	"github.com/sashabaranov/go-openai"
+	"go.uber.org/zap"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	azureHoursPerMonth = 730
+
+	azureMetricCPUPercentage       = "Percentage CPU"
+	azureMetricAvailableMemory     = "Available Memory Bytes"
+	azureMetricNetworkIn           = "Network In Total"
+	azureMetricNetworkOut          = "Network Out Total"
+	azureMetricDiskReadOperations  = "Composite Disk Read Operations/sec"
+	azureMetricDiskWriteOperations = "Composite Disk Write Operations/sec"
+	azureMetricDiskReadBytes       = "Composite Disk Read Bytes/sec"
+	azureMetricDiskWriteBytes      = "Composite Disk Write Bytes/sec"
+)
+
+func (s *Service) AzureVMRecommendation(
+	ctx context.Context,
+	vm entity.AzureVM,
+	metrics map[string][]entity.AzureMonitorDatapoint,
+	preferences map[string]*string,
+) (*entity.AzureVMRightSizingRecommendation, *model.AzureVMSKU, *model.AzureVMSKU, error) {
+	if vm.InstanceType == "" {
+		return nil, nil, nil, fmt.Errorf("no instance type provided")
+	}
+	if vm.OperatingSystem == "" {
+		vm.OperatingSystem = "Linux"
+	}
+
+	currentSKU, err := s.azureVMSKURepo.Get(vm.InstanceType, vm.Region, vm.OperatingSystem, vm.Spot)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if currentSKU == nil {
+		return nil, nil, nil, fmt.Errorf("azure vm size %s not found in region %s", vm.InstanceType, vm.Region)
+	}
+
+	cpuUsage := extractAzureUsage(metrics[azureMetricCPUPercentage])
+	availableMemoryUsage := extractAzureUsage(metrics[azureMetricAvailableMemory])
+	networkUsage := extractAzureUsage(sumMergeAzureDatapoints(metrics[azureMetricNetworkIn], metrics[azureMetricNetworkOut]))
+
+	memoryUsageGB := entity.Usage{
+		Avg: funcP(availableMemoryUsage.Avg, availableMemoryUsage.Avg, func(a, _ float64) float64 { return currentSKU.MemoryGB - a/(1024*1024*1024) }),
+		Min: funcP(availableMemoryUsage.Max, availableMemoryUsage.Max, func(a, _ float64) float64 { return currentSKU.MemoryGB - a/(1024*1024*1024) }),
+		Max: funcP(availableMemoryUsage.Min, availableMemoryUsage.Min, func(a, _ float64) float64 { return currentSKU.MemoryGB - a/(1024*1024*1024) }),
+	}
+
+	result := entity.AzureVMRightSizingRecommendation{
+		Current:           azureVMSKUToRightsizing(*currentSKU),
+		VCPU:              cpuUsage,
+		Memory:            memoryUsageGB,
+		NetworkThroughput: networkUsage,
+	}
+
+	cpuBreathingRoom := int64(0)
+	if preferences["CPUBreathingRoom"] != nil {
+		cpuBreathingRoom, _ = strconv.ParseInt(*preferences["CPUBreathingRoom"], 10, 64)
+	}
+	memoryBreathingRoom := int64(0)
+	if preferences["MemoryBreathingRoom"] != nil {
+		memoryBreathingRoom, _ = strconv.ParseInt(*preferences["MemoryBreathingRoom"], 10, 64)
+	}
+
+	neededCPU := float64(currentSKU.VCpu)
+	if cpuUsage.Avg != nil {
+		neededCPU = float64(currentSKU.VCpu) * calculateHeadroom(*cpuUsage.Avg, cpuBreathingRoom) / 100.0
+	}
+	if neededCPU < 1 {
+		neededCPU = 1
+	}
+
+	// without the azure monitor agent there is no memory datapoint, in that case we keep the current memory size
+	neededMemoryGB := currentSKU.MemoryGB
+	if memoryUsageGB.Avg != nil {
+		neededMemoryGB = calculateHeadroom(*memoryUsageGB.Avg, memoryBreathingRoom)
+	}
+	if neededMemoryGB < 0.5 {
+		neededMemoryGB = 0.5
+	}
+
+	pref := map[string]any{
+		"region = ?":           vm.Region,
+		"operating_system = ?": vm.OperatingSystem,
+	}
+	for k, v := range preferences {
+		if _, ok := azure_vm.PreferenceInstanceKey[k]; !ok {
+			continue
+		}
+		var vl any
+		if v == nil || *v == "" {
+			vl = extractFromAzureVM(vm, *currentSKU, k)
+		} else {
+			vl = *v
+		}
+
+		cond := "="
+		if sc, ok := azure_vm.PreferenceInstanceSpecialCond[k]; ok {
+			cond = sc
+		}
+		switch k {
+		case "vCPU", "MemoryGB":
+			if str, ok := vl.(string); ok {
+				vl, _ = strconv.ParseFloat(str, 64)
+			}
+		case "PremiumIO", "AcceleratedNetworking":
+			if str, ok := vl.(string); ok {
+				vl = str == "Yes"
+			}
+		}
+		pref[fmt.Sprintf("%s %s ?", azure_vm.PreferenceInstanceKey[k], cond)] = vl
+	}
+
+	spot := vm.Spot
+	if preferences["ProvisioningModel"] != nil && *preferences["ProvisioningModel"] != "" {
+		spot = *preferences["ProvisioningModel"] == "Spot"
+	}
+	pref["spot = ?"] = spot
+
+	if preferences["ExcludeBurstableInstances"] != nil && *preferences["ExcludeBurstableInstances"] == "Yes" {
+		pref["LOWER(family) NOT LIKE ?"] = "standardb%"
+	}
+
+	suggestedSKU, err := s.azureVMSKURepo.GetCheapestByCoreAndMemory(neededCPU, neededMemoryGB, pref)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if suggestedSKU != nil {
+		recommended := azureVMSKUToRightsizing(*suggestedSKU)
+		result.Recommended = &recommended
+	} else {
+		suggestedSKU = currentSKU
+	}
+
+	description, err := s.generateAzureVMDescription(ctx, vm, preferences, currentSKU, suggestedSKU, cpuUsage, memoryUsageGB, neededCPU, neededMemoryGB)
+	if err != nil {
+		s.logger.Error("Failed to generate description", zap.Error(err))
+	} else {
+		result.Description = description
+	}
+
+	if preferences["ExcludeUpsizingFeature"] != nil && *preferences["ExcludeUpsizingFeature"] == "Yes" {
+		if result.Recommended != nil && result.Recommended.Cost > result.Current.Cost {
+			result.Recommended = &result.Current
+			result.Description = "No recommendation available as upsizing feature is disabled"
+			return &result, currentSKU, currentSKU, nil
+		}
+	}
+
+	return &result, currentSKU, suggestedSKU, nil
+}
+
+func (s *Service) AzureManagedDiskRecommendation(
+	ctx context.Context,
+	disk entity.AzureManagedDisk,
+	recommendedSKU *model.AzureVMSKU,
+	metrics map[string][]entity.AzureMonitorDatapoint,
+	preferences map[string]*string,
+) (*entity.AzureManagedDiskRecommendation, error) {
+	currentType, err := s.azureManagedDiskTypeRepo.GetByTierAndSize(disk.Tier, disk.Region, disk.SizeGb)
+	if err != nil {
+		return nil, err
+	}
+	if currentType == nil {
+		return nil, fmt.Errorf("azure managed disk tier %s with %dGB not found in region %s", disk.Tier, disk.SizeGb, disk.Region)
+	}
+
+	iopsUsage := extractAzureUsage(sumMergeAzureDatapoints(metrics[azureMetricDiskReadOperations], metrics[azureMetricDiskWriteOperations]))
+	throughputUsageBytes := extractAzureUsage(sumMergeAzureDatapoints(metrics[azureMetricDiskReadBytes], metrics[azureMetricDiskWriteBytes]))
+	throughputUsageMB := entity.Usage{
+		Avg: funcP(throughputUsageBytes.Avg, throughputUsageBytes.Avg, func(a, _ float64) float64 { return a / (1024 * 1024) }),
+		Min: funcP(throughputUsageBytes.Min, throughputUsageBytes.Min, func(a, _ float64) float64 { return a / (1024 * 1024) }),
+		Max: funcP(throughputUsageBytes.Max, throughputUsageBytes.Max, func(a, _ float64) float64 { return a / (1024 * 1024) }),
+	}
+
+	result := entity.AzureManagedDiskRecommendation{
+		Current:    azureManagedDiskTypeToRightsizing(*currentType),
+		IOPS:       iopsUsage,
+		Throughput: throughputUsageMB,
+	}
+
+	iopsBreathingRoom := int64(0)
+	if preferences["IOPSBreathingRoom"] != nil {
+		iopsBreathingRoom, _ = strconv.ParseInt(*preferences["IOPSBreathingRoom"], 10, 64)
+	}
+	throughputBreathingRoom := int64(0)
+	if preferences["ThroughputBreathingRoom"] != nil {
+		throughputBreathingRoom, _ = strconv.ParseInt(*preferences["ThroughputBreathingRoom"], 10, 64)
+	}
+
+	neededIops := pCalculateHeadroom(iopsUsage.Avg, iopsBreathingRoom)
+	neededThroughputMB := pCalculateHeadroom(throughputUsageMB.Avg, throughputBreathingRoom)
+
+	pref := map[string]any{
+		"region = ?": disk.Region,
+	}
+	for k, v := range preferences {
+		if _, ok := azure_vm.PreferenceDiskKey[k]; !ok {
+			continue
+		}
+		var vl any
+		if v == nil || *v == "" {
+			vl = extractFromAzureManagedDisk(disk, k)
+		} else {
+			vl = *v
+		}
+		pref[fmt.Sprintf("%s = ?", azure_vm.PreferenceDiskKey[k])] = vl
+	}
+	// premium disks can only be attached to sizes that support premium storage
+	if recommendedSKU != nil && !recommendedSKU.PremiumIO {
+		pref["tier != ?"] = "Premium_LRS"
+	}
+
+	// managed disks can not be shrunk, so the recommended disk is at least as big as the current one
+	suggestedType, err := s.azureManagedDiskTypeRepo.GetCheapest(disk.SizeGb, neededIops, neededThroughputMB, pref)
+	if err != nil {
+		return nil, err
+	}
+	if suggestedType != nil {
+		recommended := azureManagedDiskTypeToRightsizing(*suggestedType)
+		result.Recommended = &recommended
+	} else {
+		suggestedType = currentType
+	}
+
+	description, err := s.generateAzureManagedDiskDescription(ctx, disk, preferences, currentType, suggestedType, iopsUsage, throughputUsageMB, neededIops, neededThroughputMB)
+	if err != nil {
+		s.logger.Error("Failed to generate description", zap.Error(err))
+	} else {
+		result.Description = description
+	}
+
+	if preferences["ExcludeUpsizingFeature"] != nil && *preferences["ExcludeUpsizingFeature"] == "Yes" {
+		if result.Recommended != nil && result.Recommended.Cost > result.Current.Cost {
+			result.Recommended = &result.Current
+			result.Description = "No recommendation available as upsizing feature is disabled"
+		}
+	}
+
+	return &result, nil
+}
+
+func azureVMSKUToRightsizing(sku model.AzureVMSKU) entity.RightsizingAzureVM {
+	return entity.RightsizingAzureVM{
+		InstanceType:    sku.Name,
+		Family:          sku.Family,
+		Region:          sku.Region,
+		OperatingSystem: sku.OperatingSystem,
+		Spot:            sku.Spot,
+		VCPU:            sku.VCpu,
+		MemoryGB:        sku.MemoryGB,
+		CpuArchitecture: sku.CpuArchitecture,
+		PremiumIO:       sku.PremiumIO,
+		Cost:            sku.UnitPrice * azureHoursPerMonth,
+	}
+}
+
+func azureManagedDiskTypeToRightsizing(diskType model.AzureManagedDiskType) entity.RightsizingAzureManagedDisk {
+	return entity.RightsizingAzureManagedDisk{
+		Tier:               diskType.Tier,
+		Name:               diskType.Name,
+		SizeGb:             diskType.SizeGb,
+		BaseIops:           diskType.BaseIops,
+		BaseThroughputMBps: diskType.BaseThroughputMBps,
+		Cost:               diskType.UnitPrice,
+	}
+}
+
+func extractFromAzureVM(vm entity.AzureVM, sku model.AzureVMSKU, k string) any {
+	switch k {
+	case "Region":
+		return vm.Region
+	case "vCPU":
+		return sku.VCpu
+	case "MemoryGB":
+		return sku.MemoryGB
+	case "Family":
+		return sku.Family
+	case "OperatingSystem":
+		return vm.OperatingSystem
+	case "CPUArchitecture":
+		return sku.CpuArchitecture
+	case "PremiumIO":
+		return sku.PremiumIO
+	case "AcceleratedNetworking":
+		return sku.AcceleratedNetworking
+	}
+	return ""
+}
+
+func extractFromAzureManagedDisk(disk entity.AzureManagedDisk, k string) any {
+	switch k {
+	case "Region":
+		return disk.Region
+	case "Tier":
+		return disk.Tier
+	}
+	return ""
+}
+
+func extractAzureUsage(dps []entity.AzureMonitorDatapoint) entity.Usage {
+	var minV, avgV, maxV *float64
+	var sum float64
+	var count int
+	for _, dp := range dps {
+		if dp.Average == nil {
+			continue
+		}
+		sum += *dp.Average
+		count++
+		minV = funcP(minV, dp.Average, math.Min)
+		peak := dp.Average
+		if dp.Maximum != nil {
+			peak = dp.Maximum
+		}
+		maxV = funcP(maxV, peak, math.Max)
+	}
+	if count > 0 {
+		avg := sum / float64(count)
+		avgV = &avg
+	}
+
+	return entity.Usage{
+		Avg: avgV,
+		Min: minV,
+		Max: maxV,
+	}
+}
+
+func sumMergeAzureDatapoints(in []entity.AzureMonitorDatapoint, out []entity.AzureMonitorDatapoint) []entity.AzureMonitorDatapoint {
+	sum := func(aa, bb float64) float64 {
+		return aa + bb
+	}
+
+	dps := map[int64]*entity.AzureMonitorDatapoint{}
+	for _, dp := range in {
+		dp := dp
+		dps[dp.TimeStamp.Unix()] = &dp
+	}
+	for _, dp := range out {
+		dp := dp
+		if dps[dp.TimeStamp.Unix()] == nil {
+			dps[dp.TimeStamp.Unix()] = &dp
+			continue
+		}
+
+		dps[dp.TimeStamp.Unix()].Average = funcP(dps[dp.TimeStamp.Unix()].Average, dp.Average, sum)
+		dps[dp.TimeStamp.Unix()].Maximum = funcP(dps[dp.TimeStamp.Unix()].Maximum, dp.Maximum, sum)
+		dps[dp.TimeStamp.Unix()].Minimum = funcP(dps[dp.TimeStamp.Unix()].Minimum, dp.Minimum, sum)
+		dps[dp.TimeStamp.Unix()].Total = funcP(dps[dp.TimeStamp.Unix()].Total, dp.Total, sum)
+		dps[dp.TimeStamp.Unix()].Count = funcP(dps[dp.TimeStamp.Unix()].Count, dp.Count, sum)
+	}
+
+	var dpArr []entity.AzureMonitorDatapoint
+	for _, dp := range dps {
+		dpArr = append(dpArr, *dp)
+	}
+	sort.Slice(dpArr, func(i, j int) bool {
+		return dpArr[i].TimeStamp.Unix() < dpArr[j].TimeStamp.Unix()
+	})
+	return dpArr
+}
+
+func (s *Service) generateAzureVMDescription(ctx context.Context, vm entity.AzureVM, preferences map[string]*string,
+	currentSKU, suggestedSKU *model.AzureVMSKU, cpuUsage, memoryUsageGB entity.Usage, neededCPU, neededMemoryGB float64) (string, error) {
+	var usage string
+	if cpuUsage.Avg != nil {
+		usage = fmt.Sprintf("- %s has %d vCPUs. Usage over the course of last week is min=%.2f%%, avg=%.2f%%, max=%.2f%%, so you only need %.2f vCPUs. %s has %d vCPUs.\n", currentSKU.Name, currentSKU.VCpu, PFloat(cpuUsage.Min), PFloat(cpuUsage.Avg), PFloat(cpuUsage.Max), neededCPU, suggestedSKU.Name, suggestedSKU.VCpu)
+	} else {
+		usage = fmt.Sprintf("- %s has %d vCPUs. Usage is not available. %s has %d vCPUs.\n", currentSKU.Name, currentSKU.VCpu, suggestedSKU.Name, suggestedSKU.VCpu)
+	}
+	if memoryUsageGB.Avg != nil {
+		usage += fmt.Sprintf("- %s has %.1fGB Memory. Usage over the course of last week is min=%.2fGB, avg=%.2fGB, max=%.2fGB, so you only need %.2fGB Memory. %s has %.1fGB Memory.\n", currentSKU.Name, currentSKU.MemoryGB, PFloat(memoryUsageGB.Min), PFloat(memoryUsageGB.Avg), PFloat(memoryUsageGB.Max), neededMemoryGB, suggestedSKU.Name, suggestedSKU.MemoryGB)
+	} else {
+		usage += fmt.Sprintf("- %s has %.1fGB Memory. Usage is not available. You need to install Azure Monitor Agent on your virtual machine to get this data. %s has %.1fGB Memory.\n", currentSKU.Name, currentSKU.MemoryGB, suggestedSKU.Name, suggestedSKU.MemoryGB)
+	}
+
+	needs := ""
+	for k, v := range preferences {
+		if azure_vm.PreferenceInstanceKey[k] == "" {
+			continue
+		}
+		if v == nil {
+			vl := extractFromAzureVM(vm, *currentSKU, k)
+			needs += fmt.Sprintf("- You asked %s to be same as the current virtual machine value which is %v\n", k, vl)
+		} else {
+			needs += fmt.Sprintf("- You asked %s to be %s\n", k, *v)
+		}
+	}
+
+	prompt := fmt.Sprintf(`
+I'm giving recommendation on Azure Virtual Machine right sizing. Based on user's usage and needs I have concluded that the best option for him is to use %s instead of %s. I need help summarizing the explanation into 280 characters (it's not a tweet! dont use hashtag!) while keeping these rules:
+- mention the requirements from user side.
+- for those fields which are changing make sure you mention the change.
+
+Here's usage data:
+%s
+
+User's needs:
+%s
+`, suggestedSKU.Name, currentSKU.Name, usage, needs)
+
+	return s.summarizeDescription(ctx, prompt)
+}
+
+func (s *Service) generateAzureManagedDiskDescription(ctx context.Context, disk entity.AzureManagedDisk, preferences map[string]*string,
+	currentType, suggestedType *model.AzureManagedDiskType, iopsUsage, throughputUsageMB entity.Usage, neededIops, neededThroughputMB float64) (string, error) {
+	usage := fmt.Sprintf("- %s %s has %d IOPS. Usage over the course of last week is min=%.2f, avg=%.2f, max=%.2f, so you only need %.2f IOPS. %s %s has %d IOPS.\n", currentType.Tier, currentType.Name, currentType.BaseIops, PFloat(iopsUsage.Min), PFloat(iopsUsage.Avg), PFloat(iopsUsage.Max), neededIops, suggestedType.Tier, suggestedType.Name, suggestedType.BaseIops)
+	usage += fmt.Sprintf("- %s %s has %d MB/s throughput. Usage over the course of last week is min=%.2f MB/s, avg=%.2f MB/s, max=%.2f MB/s, so you only need %.2f MB/s. %s %s has %d MB/s.\n", currentType.Tier, currentType.Name, currentType.BaseThroughputMBps, PFloat(throughputUsageMB.Min), PFloat(throughputUsageMB.Avg), PFloat(throughputUsageMB.Max), neededThroughputMB, suggestedType.Tier, suggestedType.Name, suggestedType.BaseThroughputMBps)
+	usage += fmt.Sprintf("- The disk is %dGB and managed disks can not be shrunk.\n", disk.SizeGb)
+
+	needs := ""
+	for k, v := range preferences {
+		if azure_vm.PreferenceDiskKey[k] == "" {
+			continue
+		}
+		if v == nil {
+			vl := extractFromAzureManagedDisk(disk, k)
+			needs += fmt.Sprintf("- You asked %s to be same as the current disk value which is %v\n", k, vl)
+		} else {
+			needs += fmt.Sprintf("- You asked %s to be %s\n", k, *v)
+		}
+	}
+
+	prompt := fmt.Sprintf(`
+I'm giving recommendation on Azure Managed Disk right sizing. Based on user's usage and needs I have concluded that the best option for him is to use %s %s instead of %s %s. I need help summarizing the explanation into 280 characters (it's not a tweet! dont use hashtag!) while keeping these rules:
+- mention the requirements from user side.
+- for those fields which are changing make sure you mention the change.
+
+Here's usage data:
+%s
+
+User's needs:
+%s
+`, suggestedType.Tier, suggestedType.Name, currentType.Tier, currentType.Name, usage, needs)
+
+	return s.summarizeDescription(ctx, prompt)
+}
+
+func (s *Service) summarizeDescription(ctx context.Context, prompt string) (string, error) {
+	resp, err := s.openaiSvc.CreateChatCompletion(
+		ctx,
+		openai.ChatCompletionRequest{
+			Model: openai.GPT4TurboPreview,
+			Messages: []openai.ChatCompletionMessage{
+				{
+					Role:    openai.ChatMessageRoleUser,
+					Content: prompt,
+				},
+			},
+		},
+	)
+	if err != nil {
+		return "", err
+	}
+
+	if len(resp.Choices) == 0 {
+		return "", errors.New("empty choices")
+	}
+
+	s.logger.Info("GPT results", zap.String("prompt", prompt), zap.String("result", strings.TrimSpace(resp.Choices[0].Message.Content)))
+
+	return strings.TrimSpace(resp.Choices[0].Message.Content), nil
+}
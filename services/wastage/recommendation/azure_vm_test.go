@@ -0,0 +1,362 @@
+package recommendation
+
+import (
+	"context"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/opengovern/opengovernance/services/wastage/api/entity"
+	"github.com/opengovern/opengovernance/services/wastage/db/model"
+	"github.com/opengovern/opengovernance/services/wastage/db/repo"
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAzureDiskUsage(t *testing.T) {
+	n := time.Now()
+	reads := []entity.AzureMonitorDatapoint{
+		{TimeStamp: n, Average: aws.Float64(100), Maximum: aws.Float64(300)},
+		{TimeStamp: n.Add(time.Hour), Average: aws.Float64(50)},
+	}
+	writes := []entity.AzureMonitorDatapoint{
+		{TimeStamp: n, Average: aws.Float64(20), Maximum: aws.Float64(40)},
+		{TimeStamp: n.Add(2 * time.Hour), Average: nil},
+	}
+
+	merged := sumMergeAzureDatapoints(reads, writes)
+	assert.Len(t, merged, 3)
+	assert.Equal(t, 120.0, *merged[0].Average)
+	assert.Equal(t, 340.0, *merged[0].Maximum)
+
+	usage := extractAzureUsage(merged)
+	assert.Equal(t, 85.0, *usage.Avg)
+	assert.Equal(t, 50.0, *usage.Min)
+	assert.Equal(t, 340.0, *usage.Max)
+
+	empty := extractAzureUsage(nil)
+	assert.Nil(t, empty.Avg)
+	assert.Nil(t, empty.Max)
+}
+
+// fakePrefMatches applies the "column op ?" conditions of the preferences to the columns of a row.
+func fakePrefMatches(columns map[string]any, pref map[string]any) bool {
+	toFloat := func(v any) float64 {
+		f, _ := strconv.ParseFloat(fmt.Sprint(v), 64)
+		return f
+	}
+	for k, v := range pref {
+		if k == "LOWER(family) NOT LIKE ?" {
+			if strings.HasPrefix(strings.ToLower(fmt.Sprint(columns["family"])), strings.TrimSuffix(fmt.Sprint(v), "%")) {
+				return false
+			}
+			continue
+		}
+		parts := strings.Fields(k)
+		column := columns[parts[0]]
+		switch parts[1] {
+		case "=":
+			if fmt.Sprint(column) != fmt.Sprint(v) {
+				return false
+			}
+		case "!=":
+			if fmt.Sprint(column) == fmt.Sprint(v) {
+				return false
+			}
+		case ">=":
+			if toFloat(column) < toFloat(v) {
+				return false
+			}
+		default:
+			panic("unknown condition " + k)
+		}
+	}
+	return true
+}
+
+type fakeAzureVMSKURepo struct {
+	repo.AzureVMSKURepo
+	skus []model.AzureVMSKU
+}
+
+func (r fakeAzureVMSKURepo) Get(name, region, operatingSystem string, spot bool) (*model.AzureVMSKU, error) {
+	for _, sku := range r.skus {
+		if strings.EqualFold(sku.Name, name) && sku.Region == region && sku.OperatingSystem == operatingSystem && sku.Spot == spot {
+			sku := sku
+			return &sku, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r fakeAzureVMSKURepo) GetCheapestByCoreAndMemory(cpu, memory float64, pref map[string]interface{}) (*model.AzureVMSKU, error) {
+	var cheapest *model.AzureVMSKU
+	for _, sku := range r.skus {
+		sku := sku
+		columns := map[string]any{
+			"region":                 sku.Region,
+			"operating_system":       sku.OperatingSystem,
+			"spot":                   sku.Spot,
+			"family":                 sku.Family,
+			"v_cpu":                  sku.VCpu,
+			"memory_gb":              sku.MemoryGB,
+			"premium_io":             sku.PremiumIO,
+			"accelerated_networking": sku.AcceleratedNetworking,
+			"cpu_architecture":       sku.CpuArchitecture,
+		}
+		if float64(sku.VCpu) < cpu || sku.MemoryGB < memory || !fakePrefMatches(columns, pref) {
+			continue
+		}
+		if cheapest == nil || sku.UnitPrice < cheapest.UnitPrice {
+			cheapest = &sku
+		}
+	}
+	return cheapest, nil
+}
+
+type fakeAzureManagedDiskTypeRepo struct {
+	repo.AzureManagedDiskTypeRepo
+	diskTypes []model.AzureManagedDiskType
+}
+
+func (r fakeAzureManagedDiskTypeRepo) GetByTierAndSize(tier, region string, sizeGb int64) (*model.AzureManagedDiskType, error) {
+	var smallest *model.AzureManagedDiskType
+	for _, d := range r.diskTypes {
+		d := d
+		if d.Tier == tier && d.Region == region && d.SizeGb >= sizeGb && (smallest == nil || d.SizeGb < smallest.SizeGb) {
+			smallest = &d
+		}
+	}
+	return smallest, nil
+}
+
+func (r fakeAzureManagedDiskTypeRepo) GetCheapest(sizeGb int64, iops, throughputMBps float64, pref map[string]interface{}) (*model.AzureManagedDiskType, error) {
+	var cheapest *model.AzureManagedDiskType
+	for _, d := range r.diskTypes {
+		d := d
+		columns := map[string]any{"region": d.Region, "tier": d.Tier}
+		if d.SizeGb < sizeGb || float64(d.BaseIops) < iops || float64(d.BaseThroughputMBps) < throughputMBps || !fakePrefMatches(columns, pref) {
+			continue
+		}
+		if cheapest == nil || d.UnitPrice < cheapest.UnitPrice {
+			cheapest = &d
+		}
+	}
+	return cheapest, nil
+}
+
+// newTestOpenAIClient returns a client of a server that summarizes every prompt the same way.
+func newTestOpenAIClient(t *testing.T) *openai.Client {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"choices":[{"index":0,"message":{"role":"assistant","content":" summary "}}]}`))
+	}))
+	t.Cleanup(server.Close)
+	config := openai.DefaultConfig("test")
+	config.BaseURL = server.URL
+	return openai.NewClientWithConfig(config)
+}
+
+func azureHourlyDatapoints(n int, v float64) []entity.AzureMonitorDatapoint {
+	start := time.Now().Add(-time.Duration(n) * time.Hour)
+	var dps []entity.AzureMonitorDatapoint
+	for i := 0; i < n; i++ {
+		dps = append(dps, entity.AzureMonitorDatapoint{TimeStamp: start.Add(time.Duration(i) * time.Hour), Average: aws.Float64(v)})
+	}
+	return dps
+}
+
+func TestAzureVMRecommendation(t *testing.T) {
+	sku := func(name, family string, vCpu int64, memoryGB float64, premiumIO bool, price float64) model.AzureVMSKU {
+		return model.AzureVMSKU{Name: name, Family: family, Region: "eastus", OperatingSystem: "Linux", VCpu: vCpu, MemoryGB: memoryGB, PremiumIO: premiumIO, UnitPrice: price}
+	}
+	spot := sku("Standard_D2s_v5", "standardDSv5Family", 2, 8, true, 0.0192)
+	spot.Spot = true
+	windows := sku("Standard_D2s_v5", "standardDSv5Family", 2, 8, true, 0.188)
+	windows.OperatingSystem = "Windows"
+	s := &Service{
+		logger:    zap.NewNop(),
+		openaiSvc: newTestOpenAIClient(t),
+		azureVMSKURepo: fakeAzureVMSKURepo{skus: []model.AzureVMSKU{
+			sku("Standard_D4s_v5", "standardDSv5Family", 4, 16, true, 0.192),
+			sku("Standard_D2s_v5", "standardDSv5Family", 2, 8, true, 0.096),
+			sku("Standard_D2_v5", "standardDv5Family", 2, 8, false, 0.094),
+			sku("Standard_E2s_v5", "standardESv5Family", 2, 16, true, 0.126),
+			sku("Standard_B2s", "standardBSFamily", 2, 4, true, 0.0416),
+			spot,
+			windows,
+		}},
+	}
+	gb := 1024.0 * 1024 * 1024
+	// 20% of 4 vCPUs and 4 of 16 GB of memory used
+	metrics := map[string][]entity.AzureMonitorDatapoint{
+		azureMetricCPUPercentage:   azureHourlyDatapoints(24, 20),
+		azureMetricAvailableMemory: azureHourlyDatapoints(24, 12*gb),
+	}
+	current := entity.AzureVM{Region: "eastus", InstanceType: "Standard_D4s_v5"}
+
+	tests := []struct {
+		name        string
+		vm          entity.AzureVM
+		metrics     map[string][]entity.AzureMonitorDatapoint
+		preferences map[string]*string
+		want        string
+		wantSpot    bool
+	}{
+		{name: "cheapest fitting size", vm: current, metrics: metrics, want: "Standard_B2s"},
+		{
+			name: "burstable sizes excluded", vm: current, metrics: metrics,
+			preferences: map[string]*string{"ExcludeBurstableInstances": aws.String("Yes")},
+			want:        "Standard_D2_v5",
+		},
+		{
+			name: "same family as the current size", vm: current, metrics: metrics,
+			preferences: map[string]*string{"Family": nil},
+			want:        "Standard_D2s_v5",
+		},
+		{
+			name: "premium storage kept", vm: current, metrics: metrics,
+			preferences: map[string]*string{"PremiumIO": nil, "ExcludeBurstableInstances": aws.String("Yes")},
+			want:        "Standard_D2s_v5",
+		},
+		{
+			name: "premium storage not needed", vm: current, metrics: metrics,
+			preferences: map[string]*string{"PremiumIO": aws.String("No")},
+			want:        "Standard_D2_v5",
+		},
+		{
+			name: "at least the preferred vCPUs", vm: current, metrics: metrics,
+			preferences: map[string]*string{"vCPU": aws.String("4")},
+			want:        "Standard_D4s_v5",
+		},
+		{
+			name: "at least the current memory", vm: current, metrics: metrics,
+			preferences: map[string]*string{"MemoryGB": nil},
+			want:        "Standard_E2s_v5",
+		},
+		{
+			name: "memory breathing room", vm: current, metrics: metrics,
+			preferences: map[string]*string{"MemoryBreathingRoom": aws.String("50")},
+			want:        "Standard_D2_v5",
+		},
+		{
+			// without the azure monitor agent the memory size is kept
+			name: "no memory metrics", vm: current,
+			metrics: map[string][]entity.AzureMonitorDatapoint{azureMetricCPUPercentage: azureHourlyDatapoints(24, 20)},
+			want:    "Standard_E2s_v5",
+		},
+		{
+			name: "spot provisioning", vm: current, metrics: metrics,
+			preferences: map[string]*string{"ProvisioningModel": aws.String("Spot"), "Family": nil},
+			want:        "Standard_D2s_v5",
+			wantSpot:    true,
+		},
+		{
+			name: "operating system kept", vm: entity.AzureVM{Region: "eastus", InstanceType: "Standard_D2s_v5", OperatingSystem: "Windows"},
+			metrics: metrics,
+			want:    "Standard_D2s_v5",
+		},
+	}
+	for _, tt := range tests {
+		recom, currentSKU, suggestedSKU, err := s.AzureVMRecommendation(context.Background(), tt.vm, tt.metrics, tt.preferences)
+		if !assert.NoError(t, err, tt.name) {
+			continue
+		}
+		assert.Equal(t, tt.vm.InstanceType, currentSKU.Name, tt.name)
+		assert.Equal(t, tt.want, suggestedSKU.Name, tt.name)
+		assert.Equal(t, tt.want, recom.Recommended.InstanceType, tt.name)
+		assert.Equal(t, tt.wantSpot, recom.Recommended.Spot, tt.name)
+		assert.Equal(t, currentSKU.OperatingSystem, recom.Recommended.OperatingSystem, tt.name)
+		assert.InDelta(t, suggestedSKU.UnitPrice*azureHoursPerMonth, recom.Recommended.Cost, 0.0001, tt.name)
+		assert.Equal(t, "summary", recom.Description, tt.name)
+	}
+
+	// 90% of 2 vCPUs and with the breathing room 7 GB of memory only fit larger sizes
+	recom, currentSKU, suggestedSKU, err := s.AzureVMRecommendation(context.Background(), entity.AzureVM{Region: "eastus", InstanceType: "Standard_B2s"},
+		map[string][]entity.AzureMonitorDatapoint{
+			azureMetricCPUPercentage:   azureHourlyDatapoints(24, 90),
+			azureMetricAvailableMemory: azureHourlyDatapoints(24, 0.5*gb),
+		}, map[string]*string{"MemoryBreathingRoom": aws.String("50"), "ExcludeUpsizingFeature": aws.String("Yes")})
+	assert.NoError(t, err)
+	assert.Equal(t, recom.Current, *recom.Recommended)
+	assert.Equal(t, currentSKU, suggestedSKU)
+	assert.Equal(t, "No recommendation available as upsizing feature is disabled", recom.Description)
+
+	_, _, _, err = s.AzureVMRecommendation(context.Background(), entity.AzureVM{Region: "eastus", InstanceType: "Standard_F2s_v2"}, metrics, nil)
+	assert.Error(t, err)
+}
+
+func TestAzureManagedDiskRecommendation(t *testing.T) {
+	diskType := func(tier, name string, sizeGb, iops, throughput int64, price float64) model.AzureManagedDiskType {
+		return model.AzureManagedDiskType{Tier: tier, Name: name, Region: "eastus", SizeGb: sizeGb, BaseIops: iops, BaseThroughputMBps: throughput, UnitPrice: price}
+	}
+	s := &Service{
+		logger:    zap.NewNop(),
+		openaiSvc: newTestOpenAIClient(t),
+		azureManagedDiskTypeRepo: fakeAzureManagedDiskTypeRepo{diskTypes: []model.AzureManagedDiskType{
+			diskType("Premium_LRS", "P10", 128, 500, 100, 19.71),
+			diskType("Premium_LRS", "P15", 256, 1100, 125, 38.01),
+			diskType("StandardSSD_LRS", "E10", 128, 500, 60, 9.60),
+			diskType("StandardSSD_LRS", "E15", 256, 500, 60, 19.20),
+			diskType("Standard_LRS", "S10", 128, 500, 60, 5.89),
+			diskType("Standard_LRS", "S15", 256, 500, 60, 11.33),
+		}},
+	}
+	premiumSKU := &model.AzureVMSKU{Name: "Standard_D2s_v5", PremiumIO: true}
+	standardSKU := &model.AzureVMSKU{Name: "Standard_D2_v5"}
+	mb := 1024.0 * 1024
+	metrics := func(iops, throughputMB float64) map[string][]entity.AzureMonitorDatapoint {
+		return map[string][]entity.AzureMonitorDatapoint{
+			azureMetricDiskReadOperations:  azureHourlyDatapoints(24, iops/2),
+			azureMetricDiskWriteOperations: azureHourlyDatapoints(24, iops/2),
+			azureMetricDiskReadBytes:       azureHourlyDatapoints(24, throughputMB*mb/2),
+			azureMetricDiskWriteBytes:      azureHourlyDatapoints(24, throughputMB*mb/2),
+		}
+	}
+	premium := entity.AzureManagedDisk{Region: "eastus", Tier: "Premium_LRS", SizeGb: 100}
+
+	tests := []struct {
+		name        string
+		disk        entity.AzureManagedDisk
+		sku         *model.AzureVMSKU
+		metrics     map[string][]entity.AzureMonitorDatapoint
+		preferences map[string]*string
+		want        string
+	}{
+		{name: "cheapest fitting disk", disk: premium, sku: premiumSKU, metrics: metrics(200, 10), want: "S10"},
+		{name: "same tier as the current disk", disk: premium, sku: premiumSKU, metrics: metrics(200, 10), preferences: map[string]*string{"Tier": nil}, want: "P10"},
+		{name: "preferred tier", disk: premium, sku: premiumSKU, metrics: metrics(200, 10), preferences: map[string]*string{"Tier": aws.String("StandardSSD_LRS")}, want: "E10"},
+		{name: "throughput", disk: premium, sku: premiumSKU, metrics: metrics(200, 80), want: "P10"},
+		{name: "iops breathing room", disk: premium, sku: premiumSKU, metrics: metrics(300, 10), preferences: map[string]*string{"IOPSBreathingRoom": aws.String("50")}, want: "P15"},
+		// disks are never shrunk
+		{name: "at least the current size", disk: entity.AzureManagedDisk{Region: "eastus", Tier: "Premium_LRS", SizeGb: 200}, sku: premiumSKU, metrics: metrics(200, 10), want: "S15"},
+	}
+	for _, tt := range tests {
+		recom, err := s.AzureManagedDiskRecommendation(context.Background(), tt.disk, tt.sku, tt.metrics, tt.preferences)
+		if !assert.NoError(t, err, tt.name) {
+			continue
+		}
+		assert.Equal(t, tt.want, recom.Recommended.Name, tt.name)
+		assert.Equal(t, "summary", recom.Description, tt.name)
+	}
+
+	// only premium disks have the iops, but the recommended size can not attach them
+	recom, err := s.AzureManagedDiskRecommendation(context.Background(), premium, standardSKU, metrics(800, 10), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "P10", recom.Current.Name)
+	assert.Nil(t, recom.Recommended)
+
+	recom, err = s.AzureManagedDiskRecommendation(context.Background(), entity.AzureManagedDisk{Region: "eastus", Tier: "Standard_LRS", SizeGb: 100}, premiumSKU, metrics(800, 10),
+		map[string]*string{"ExcludeUpsizingFeature": aws.String("Yes")})
+	assert.NoError(t, err)
+	assert.Equal(t, "S10", recom.Recommended.Name)
+	assert.Equal(t, "No recommendation available as upsizing feature is disabled", recom.Description)
+
+	_, err = s.AzureManagedDiskRecommendation(context.Background(), entity.AzureManagedDisk{Region: "eastus", Tier: "UltraSSD_LRS", SizeGb: 100}, premiumSKU, metrics(200, 10), nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,637 @@
+package recommendation
+
+import (
+	"context"
+	"fmt"
+	"github.com/opengovern/opengovernance/services/wastage/api/entity"
+	pb "github.com/opengovern/plugin-kubernetes-internal/plugin/proto/src/golang"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	corev1 "k8s.io/api/core/v1"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	kubernetesHoursPerMonth = 730
+	// kubernetesAllocatableRatio is the share of the node capacity left to the pods when the node does not tell
+	kubernetesAllocatableRatio = 0.9
+
+	kubernetesProviderEKS = "EKS"
+	kubernetesProviderGKE = "GKE"
+	kubernetesProviderAKS = "AKS"
+
+	gib = 1024 * 1024 * 1024
+	mib = 1024 * 1024
+)
+
+var (
+	kubernetesNodePoolLabels     = []string{"eks.amazonaws.com/nodegroup", "cloud.google.com/gke-nodepool", "kubernetes.azure.com/agentpool", "agentpool", "karpenter.sh/nodepool"}
+	kubernetesInstanceTypeLabels = []string{"node.kubernetes.io/instance-type", "beta.kubernetes.io/instance-type"}
+	kubernetesRegionLabels       = []string{"topology.kubernetes.io/region", "failure-domain.beta.kubernetes.io/region"}
+	kubernetesZoneLabels         = []string{"topology.kubernetes.io/zone", "failure-domain.beta.kubernetes.io/zone", "topology.gke.io/zone"}
+	kubernetesArchLabels         = []string{"kubernetes.io/arch", "beta.kubernetes.io/arch"}
+	kubernetesOSLabels           = []string{"kubernetes.io/os", "beta.kubernetes.io/os"}
+	gkeArmMachineFamilies        = []string{"t2a", "c4a"}
+)
+
+// clusterPod is one replica of a workload with its recommended requests.
+type clusterPod struct {
+	workload     string
+	cpu          float64
+	memory       float64
+	nodeSelector map[string]string
+	tolerations  []corev1.Toleration
+}
+
+// clusterBin is a node, or a node of a simulated pool, with the cpu and memory left on it.
+type clusterBin struct {
+	labels map[string]string
+	taints []corev1.Taint
+	cpu    float64
+	memory float64
+}
+
+type clusterPool struct {
+	name         string
+	provider     string
+	instanceType string
+	labels       map[string]string
+	taints       []corev1.Taint
+
+	nodes int
+	// cpu and memory are the capacity of one node
+	cpu               float64
+	memory            float64
+	cpuAllocatable    float64
+	memoryAllocatable float64
+	monthlyCost       float64
+
+	// daemonCPU and daemonMemory are requested by the daemonsets on every node of the pool
+	daemonCPU    float64
+	daemonMemory float64
+
+	pods []clusterPod
+}
+
+// allocatableRatio is the share of the node capacity the pool leaves to the pods.
+func (p clusterPool) allocatableRatio() (float64, float64) {
+	cpuRatio, memoryRatio := kubernetesAllocatableRatio, kubernetesAllocatableRatio
+	if p.nodes > 0 && p.cpu > 0 && p.cpuAllocatable > 0 {
+		cpuRatio = p.cpuAllocatable / (float64(p.nodes) * p.cpu)
+	}
+	if p.nodes > 0 && p.memory > 0 && p.memoryAllocatable > 0 {
+		memoryRatio = p.memoryAllocatable / (float64(p.nodes) * p.memory)
+	}
+	return cpuRatio, memoryRatio
+}
+
+// usage is what the pods and the daemonsets request on a pool of the given size.
+func (p clusterPool) usage(nodes int) (float64, float64) {
+	cpu, memory := float64(nodes)*p.daemonCPU, float64(nodes)*p.daemonMemory
+	for _, pod := range p.pods {
+		cpu += pod.cpu
+		memory += pod.memory
+	}
+	return cpu, memory
+}
+
+func (p clusterPool) current() entity.KubernetesNodePool {
+	usedCPU, usedMemory := p.usage(p.nodes)
+	return entity.KubernetesNodePool{
+		InstanceType:          p.instanceType,
+		Nodes:                 p.nodes,
+		CPU:                   p.cpu,
+		Memory:                p.memory,
+		MonthlyCost:           p.monthlyCost,
+		CPUHeadroomPercent:    headroomPercent(usedCPU, p.cpuAllocatable),
+		MemoryHeadroomPercent: headroomPercent(usedMemory, p.memoryAllocatable),
+	}
+}
+
+// nodePoolCandidate is an instance type a pool can be moved to, cpu is in cores and memory is in bytes.
+type nodePoolCandidate struct {
+	instanceType string
+	cpu          float64
+	memory       float64
+	hourlyCost   float64
+}
+
+type clusterOptions struct {
+	cpuBreathingRoom    float64
+	memoryBreathingRoom float64
+	minNodes            int
+}
+
+func clusterOptionsFromPreferences(preferences map[string]*string) (clusterOptions, error) {
+	opts := clusterOptions{
+		cpuBreathingRoom:    10,
+		memoryBreathingRoom: 10,
+		minNodes:            1,
+	}
+	for _, k := range []string{"CPUBreathingRoom", "MemoryBreathingRoom"} {
+		v, ok := preferences[k]
+		if !ok || v == nil || *v == "" {
+			continue
+		}
+		vPercent, err := strconv.ParseFloat(*v, 64)
+		if err != nil || vPercent < 0 || vPercent >= 100 {
+			return opts, status.Errorf(codes.InvalidArgument, "invalid %s value: %s", k, *v)
+		}
+		if k == "CPUBreathingRoom" {
+			opts.cpuBreathingRoom = vPercent
+		} else {
+			opts.memoryBreathingRoom = vPercent
+		}
+	}
+	if v, ok := preferences["MinNodesPerPool"]; ok && v != nil && *v != "" {
+		minNodes, err := strconv.Atoi(*v)
+		if err != nil || minNodes < 0 {
+			return opts, status.Errorf(codes.InvalidArgument, "invalid MinNodesPerPool value: %s", *v)
+		}
+		opts.minNodes = minNodes
+	}
+	return opts, nil
+}
+
+// KubernetesClusterRecommendation bin-packs the recommended requests of the workloads on the nodes of the cluster and
+// recommends for every node pool the cheapest instance type and node count that still fits its pods.
+func (s *Service) KubernetesClusterRecommendation(
+	ctx context.Context,
+	nodes []entity.KubernetesClusterNode,
+	workloads []entity.KubernetesClusterWorkload,
+	preferences map[string]*string,
+) (*entity.KubernetesClusterWastageResponse, error) {
+	opts, err := clusterOptionsFromPreferences(preferences)
+	if err != nil {
+		return nil, err
+	}
+
+	var pods, daemonSets []clusterPod
+	for _, w := range workloads {
+		pod := clusterPod{
+			workload:     fmt.Sprintf("%s/%s", w.Namespace, w.Name),
+			cpu:          w.CPURequest,
+			memory:       w.MemoryRequest,
+			nodeSelector: w.NodeSelector,
+			tolerations:  w.Tolerations,
+		}
+		if strings.EqualFold(w.Kind, "DaemonSet") {
+			daemonSets = append(daemonSets, pod)
+			continue
+		}
+		for i := int32(0); i < w.Replicas; i++ {
+			pods = append(pods, pod)
+		}
+	}
+
+	pools := make(map[string]*clusterPool)
+	var bins []*clusterBin
+	var binPools []*clusterPool
+	for _, node := range nodes {
+		var nodeCost float64
+		if node.MonthlyCost != nil {
+			nodeCost = *node.MonthlyCost
+		} else {
+			nodeCost, err = s.KubernetesNodeCost(ctx, pb.KubernetesNode{Id: node.Id, Labels: node.Labels})
+			if err != nil {
+				s.logger.Error("failed to get kubernetes node cost", zap.String("node", node.Id), zap.Error(err))
+				return nil, err
+			}
+		}
+
+		instanceType, _ := getValueFromLabelList(node.Labels, kubernetesInstanceTypeLabels)
+		poolName, ok := getValueFromLabelList(node.Labels, kubernetesNodePoolLabels)
+		if !ok {
+			poolName = "default"
+		}
+		key := poolName + "/" + instanceType
+		pool, ok := pools[key]
+		if !ok {
+			pool = &clusterPool{
+				name:         poolName,
+				provider:     kubernetesNodeProvider(node.Labels),
+				instanceType: instanceType,
+				labels:       node.Labels,
+				taints:       node.Taints,
+				cpu:          node.CPUCapacity,
+				memory:       node.MemoryCapacity,
+			}
+			pool.daemonCPU, pool.daemonMemory = daemonSetsOverhead(daemonSets, node.Labels, node.Taints)
+			pools[key] = pool
+		}
+
+		cpuAllocatable, memoryAllocatable := node.CPUAllocatable, node.MemoryAllocatable
+		if cpuAllocatable == 0 {
+			cpuAllocatable = node.CPUCapacity * kubernetesAllocatableRatio
+		}
+		if memoryAllocatable == 0 {
+			memoryAllocatable = node.MemoryCapacity * kubernetesAllocatableRatio
+		}
+		pool.nodes++
+		pool.cpuAllocatable += cpuAllocatable
+		pool.memoryAllocatable += memoryAllocatable
+		pool.monthlyCost += nodeCost
+
+		daemonCPU, daemonMemory := daemonSetsOverhead(daemonSets, node.Labels, node.Taints)
+		bins = append(bins, &clusterBin{
+			labels: node.Labels,
+			taints: node.Taints,
+			cpu:    cpuAllocatable - daemonCPU,
+			memory: memoryAllocatable - daemonMemory,
+		})
+		binPools = append(binPools, pool)
+	}
+
+	resp := entity.KubernetesClusterWastageResponse{}
+	unschedulable := make(map[string]bool)
+	for i, bin := range packPods(pods, bins) {
+		if bin < 0 {
+			if !unschedulable[pods[i].workload] {
+				unschedulable[pods[i].workload] = true
+				resp.Unschedulable = append(resp.Unschedulable, pods[i].workload)
+			}
+			continue
+		}
+		binPools[bin].pods = append(binPools[bin].pods, pods[i])
+	}
+	sort.Strings(resp.Unschedulable)
+
+	var keys []string
+	for k := range pools {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var usedCPU, usedMemory, allocatableCPU, allocatableMemory float64
+	for _, k := range keys {
+		pool := *pools[k]
+		if resp.Provider == "" {
+			resp.Provider = pool.provider
+		}
+
+		rec := entity.KubernetesNodePoolRecommendation{
+			Name:     pool.name,
+			Provider: pool.provider,
+			Pods:     len(pool.pods),
+			Current:  pool.current(),
+		}
+		chosen := rec.Current
+		if reason := nodePoolKeepReason(pool); reason != "" {
+			rec.Description = reason
+		} else {
+			recommended, err := s.recommendNodePool(ctx, pool, opts)
+			if err != nil {
+				return nil, err
+			}
+			if recommended != nil && recommended.MonthlyCost < rec.Current.MonthlyCost {
+				rec.Recommended = recommended
+				rec.Description = fmt.Sprintf("Replace the %d %s nodes with %d %s nodes", rec.Current.Nodes, rec.Current.InstanceType,
+					recommended.Nodes, recommended.InstanceType)
+				chosen = *recommended
+			} else {
+				rec.Description = "The node pool is already the cheapest fit for its pods"
+			}
+		}
+		resp.NodePools = append(resp.NodePools, rec)
+		resp.CurrentMonthlyCost += rec.Current.MonthlyCost
+		resp.RecommendedMonthlyCost += chosen.MonthlyCost
+
+		cpuRatio, memoryRatio := pool.allocatableRatio()
+		poolCPU, poolMemory := pool.usage(chosen.Nodes)
+		usedCPU += poolCPU
+		usedMemory += poolMemory
+		allocatableCPU += float64(chosen.Nodes) * chosen.CPU * cpuRatio
+		allocatableMemory += float64(chosen.Nodes) * chosen.Memory * memoryRatio
+	}
+	resp.ProjectedMonthlySavings = resp.CurrentMonthlyCost - resp.RecommendedMonthlyCost
+	resp.CPUHeadroomPercent = headroomPercent(usedCPU, allocatableCPU)
+	resp.MemoryHeadroomPercent = headroomPercent(usedMemory, allocatableMemory)
+
+	return &resp, nil
+}
+
+// recommendNodePool looks up the instance types large enough for every pod of the pool and returns the cheapest
+// pool made of one of them, nil when none fits.
+func (s *Service) recommendNodePool(ctx context.Context, pool clusterPool, opts clusterOptions) (*entity.KubernetesNodePool, error) {
+	var maxCPU, maxMemory float64
+	for _, pod := range pool.pods {
+		maxCPU = max(maxCPU, pod.cpu)
+		maxMemory = max(maxMemory, pod.memory)
+	}
+	cpuRatio, memoryRatio := pool.allocatableRatio()
+	minCPU := (maxCPU + pool.daemonCPU) / (cpuRatio * (1 - opts.cpuBreathingRoom/100))
+	minMemory := (maxMemory + pool.daemonMemory) / (memoryRatio * (1 - opts.memoryBreathingRoom/100))
+
+	candidates, err := s.kubernetesNodePoolCandidates(ctx, pool, minCPU, minMemory)
+	if err != nil {
+		s.logger.Error("failed to list node pool candidates", zap.String("pool", pool.name), zap.Error(err))
+		return nil, err
+	}
+	return bestNodePool(pool, candidates, opts), nil
+}
+
+// kubernetesNodePoolCandidates lists the on-demand instance types of the pool provider in the pool location and
+// architecture with at least the given cpu cores and memory bytes.
+func (s *Service) kubernetesNodePoolCandidates(ctx context.Context, pool clusterPool, minCPU, minMemory float64) ([]nodePoolCandidate, error) {
+	arch, _ := getValueFromLabelList(pool.labels, kubernetesArchLabels)
+	burstable := strings.HasPrefix(strings.ToLower(pool.instanceType), "t") || strings.HasPrefix(strings.ToLower(pool.instanceType), "standard_b")
+
+	var candidates []nodePoolCandidate
+	switch pool.provider {
+	case kubernetesProviderEKS:
+		region, ok := getValueFromLabelList(pool.labels, kubernetesRegionLabels)
+		if !ok {
+			return nil, nil
+		}
+		pref := map[string]any{
+			"region_code = ?":             region,
+			"tenancy = ?":                 "Shared",
+			"operation = ?":               "RunInstances",
+			"pre_installed_sw = ?":        "NA",
+			"physical_processor_arch = ?": "x86_64",
+		}
+		if arch == "arm64" {
+			pref["physical_processor_arch = ?"] = "arm64"
+		}
+		if !burstable {
+			pref["NOT(instance_type like ?)"] = "t%"
+		}
+		instanceTypes, err := s.ec2InstanceRepo.ListByCoreAndMemory(ctx, minCPU, minMemory/gib, pref)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range instanceTypes {
+			candidates = append(candidates, nodePoolCandidate{
+				instanceType: t.InstanceType,
+				cpu:          t.VCpu,
+				memory:       t.MemoryGB * gib,
+				hourlyCost:   t.PricePerUnit,
+			})
+		}
+	case kubernetesProviderGKE:
+		zone, ok := getValueFromLabelList(pool.labels, kubernetesZoneLabels)
+		if !ok {
+			return nil, nil
+		}
+		pref := map[string]any{
+			"zone = ?":                  zone,
+			"preemptible = ?":           false,
+			"machine_family NOT IN (?)": gkeArmMachineFamilies,
+		}
+		if arch == "arm64" {
+			delete(pref, "machine_family NOT IN (?)")
+			pref["machine_family IN (?)"] = gkeArmMachineFamilies
+		}
+		machineTypes, err := s.gcpComputeMachineTypeRepo.ListByCoreAndMemory(minCPU, minMemory/mib, pref)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range machineTypes {
+			candidates = append(candidates, nodePoolCandidate{
+				instanceType: t.MachineType,
+				cpu:          float64(t.GuestCpus),
+				memory:       float64(t.MemoryMb) * mib,
+				hourlyCost:   t.UnitPrice,
+			})
+		}
+	case kubernetesProviderAKS:
+		region, ok := getValueFromLabelList(pool.labels, kubernetesRegionLabels)
+		if !ok {
+			return nil, nil
+		}
+		pref := map[string]any{
+			"region = ?":           region,
+			"operating_system = ?": "Linux",
+			"spot = ?":             false,
+			"cpu_architecture = ?": "x64",
+		}
+		if arch == "arm64" {
+			pref["cpu_architecture = ?"] = "Arm64"
+		}
+		if !burstable {
+			pref["LOWER(family) NOT LIKE ?"] = "standardb%"
+		}
+		skus, err := s.azureVMSKURepo.ListByCoreAndMemory(minCPU, minMemory/gib, pref)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range skus {
+			candidates = append(candidates, nodePoolCandidate{
+				instanceType: t.Name,
+				cpu:          float64(t.VCpu),
+				memory:       t.MemoryGB * gib,
+				hourlyCost:   t.UnitPrice,
+			})
+		}
+	}
+	return candidates, nil
+}
+
+// bestNodePool simulates the pool on every candidate and returns the cheapest one the pods fit on.
+func bestNodePool(pool clusterPool, candidates []nodePoolCandidate, opts clusterOptions) *entity.KubernetesNodePool {
+	var best *entity.KubernetesNodePool
+	for _, candidate := range candidates {
+		simulated, ok := simulateNodePool(pool, candidate, opts)
+		if !ok {
+			continue
+		}
+		if best == nil || simulated.MonthlyCost < best.MonthlyCost {
+			best = &simulated
+		}
+	}
+	return best
+}
+
+// simulateNodePool sizes a pool of the candidate for the pods of the pool keeping the breathing room free on every
+// node, it returns false when a pod does not fit on a node of the candidate.
+func simulateNodePool(pool clusterPool, candidate nodePoolCandidate, opts clusterOptions) (entity.KubernetesNodePool, bool) {
+	cpuRatio, memoryRatio := pool.allocatableRatio()
+	cpuAllocatable := candidate.cpu * cpuRatio
+	memoryAllocatable := candidate.memory * memoryRatio
+	freeCPU := cpuAllocatable*(1-opts.cpuBreathingRoom/100) - pool.daemonCPU
+	freeMemory := memoryAllocatable*(1-opts.memoryBreathingRoom/100) - pool.daemonMemory
+	if freeCPU <= 0 || freeMemory <= 0 {
+		return entity.KubernetesNodePool{}, false
+	}
+
+	nodes := nodesNeeded(pool.pods, freeCPU, freeMemory)
+	if nodes < 0 {
+		return entity.KubernetesNodePool{}, false
+	}
+	nodes = max(nodes, opts.minNodes)
+
+	usedCPU, usedMemory := pool.usage(nodes)
+	return entity.KubernetesNodePool{
+		InstanceType:          candidate.instanceType,
+		Nodes:                 nodes,
+		CPU:                   candidate.cpu,
+		Memory:                candidate.memory,
+		MonthlyCost:           float64(nodes) * candidate.hourlyCost * kubernetesHoursPerMonth,
+		CPUHeadroomPercent:    headroomPercent(usedCPU, float64(nodes)*cpuAllocatable),
+		MemoryHeadroomPercent: headroomPercent(usedMemory, float64(nodes)*memoryAllocatable),
+	}, true
+}
+
+// nodePoolKeepReason tells why the instance type of the pool is not changed, empty when it can be.
+func nodePoolKeepReason(pool clusterPool) string {
+	switch {
+	case pool.provider == "":
+		return "The cloud provider of the node pool is unknown"
+	case pool.instanceType == "":
+		return "The instance type of the node pool is unknown"
+	case kubernetesSpotNode(pool.labels):
+		return "Spot node pools are not resized"
+	}
+	if nodeOS, _ := getValueFromLabelList(pool.labels, kubernetesOSLabels); nodeOS == "windows" {
+		return "Windows node pools are not resized"
+	}
+	for _, pod := range pool.pods {
+		if _, ok := getValueFromLabelList(pod.nodeSelector, kubernetesInstanceTypeLabels); ok {
+			return fmt.Sprintf("%s is pinned to the instance type of the node pool", pod.workload)
+		}
+	}
+	return ""
+}
+
+func kubernetesNodeProvider(labels map[string]string) string {
+	for labelKey := range labels {
+		labelKey := strings.ToLower(labelKey)
+		switch {
+		case strings.HasPrefix(labelKey, "eks.amazonaws.com/"):
+			return kubernetesProviderEKS
+		case strings.HasPrefix(labelKey, "kubernetes.azure.com/"):
+			return kubernetesProviderAKS
+		case strings.HasPrefix(labelKey, "cloud.google.com/"):
+			return kubernetesProviderGKE
+		}
+	}
+	return ""
+}
+
+func kubernetesSpotNode(labels map[string]string) bool {
+	return strings.EqualFold(labels["eks.amazonaws.com/capacityType"], "SPOT") ||
+		labels["karpenter.sh/capacity-type"] == "spot" ||
+		labels["cloud.google.com/gke-provisioning"] == "spot" ||
+		labels["cloud.google.com/gke-spot"] == "true" ||
+		labels["cloud.google.com/gke-preemptible"] == "true" ||
+		labels["kubernetes.azure.com/scalesetpriority"] == "spot"
+}
+
+// daemonSetsOverhead sums the requests of the daemonsets allowed on the node.
+func daemonSetsOverhead(daemonSets []clusterPod, labels map[string]string, taints []corev1.Taint) (float64, float64) {
+	var cpu, memory float64
+	for _, ds := range daemonSets {
+		if podAllowedOnNode(ds, labels, taints) {
+			cpu += ds.cpu
+			memory += ds.memory
+		}
+	}
+	return cpu, memory
+}
+
+// podAllowedOnNode checks the node selector of the pod against the node labels and its tolerations against the
+// taints that keep pods away.
+func podAllowedOnNode(pod clusterPod, labels map[string]string, taints []corev1.Taint) bool {
+	for k, v := range pod.nodeSelector {
+		if lv, ok := labels[k]; !ok || lv != v {
+			return false
+		}
+	}
+	for i := range taints {
+		if taints[i].Effect != corev1.TaintEffectNoSchedule && taints[i].Effect != corev1.TaintEffectNoExecute {
+			continue
+		}
+		tolerated := false
+		for j := range pod.tolerations {
+			if pod.tolerations[j].ToleratesTaint(&taints[i]) {
+				tolerated = true
+				break
+			}
+		}
+		if !tolerated {
+			return false
+		}
+	}
+	return true
+}
+
+// podsDecreasing orders the pods by their share of a node with the given cpu and memory, largest first.
+func podsDecreasing(pods []clusterPod, cpu, memory float64) []int {
+	size := func(pod clusterPod) float64 {
+		var share float64
+		if cpu > 0 {
+			share += pod.cpu / cpu
+		}
+		if memory > 0 {
+			share += pod.memory / memory
+		}
+		return share
+	}
+	order := make([]int, len(pods))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return size(pods[order[i]]) > size(pods[order[j]])
+	})
+	return order
+}
+
+// packPods places the pods first-fit-decreasing on the bins they are allowed on and returns the bin of every pod,
+// -1 for the pods that do not fit anywhere.
+func packPods(pods []clusterPod, bins []*clusterBin) []int {
+	var maxCPU, maxMemory float64
+	for _, bin := range bins {
+		maxCPU = max(maxCPU, bin.cpu)
+		maxMemory = max(maxMemory, bin.memory)
+	}
+
+	placement := make([]int, len(pods))
+	for _, i := range podsDecreasing(pods, maxCPU, maxMemory) {
+		placement[i] = -1
+		for b, bin := range bins {
+			if pods[i].cpu <= bin.cpu && pods[i].memory <= bin.memory && podAllowedOnNode(pods[i], bin.labels, bin.taints) {
+				bin.cpu -= pods[i].cpu
+				bin.memory -= pods[i].memory
+				placement[i] = b
+				break
+			}
+		}
+	}
+	return placement
+}
+
+// nodesNeeded is the number of nodes with the given free cpu and memory the pods are packed on first-fit-decreasing,
+// -1 when a pod does not fit on an empty node.
+func nodesNeeded(pods []clusterPod, cpu, memory float64) int {
+	var bins []*clusterBin
+	for _, i := range podsDecreasing(pods, cpu, memory) {
+		pod := pods[i]
+		if pod.cpu > cpu || pod.memory > memory {
+			return -1
+		}
+		placed := false
+		for _, bin := range bins {
+			if pod.cpu <= bin.cpu && pod.memory <= bin.memory {
+				bin.cpu -= pod.cpu
+				bin.memory -= pod.memory
+				placed = true
+				break
+			}
+		}
+		if !placed {
+			bins = append(bins, &clusterBin{cpu: cpu - pod.cpu, memory: memory - pod.memory})
+		}
+	}
+	return len(bins)
+}
+
+func headroomPercent(used, allocatable float64) float64 {
+	if allocatable <= 0 {
+		return 0
+	}
+	return (1 - used/allocatable) * 100
+}
@@ -0,0 +1,105 @@
+package recommendation
+
+import (
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"testing"
+)
+
+func TestPodAllowedOnNode(t *testing.T) {
+	labels := map[string]string{"eks.amazonaws.com/nodegroup": "gpu"}
+	taints := []corev1.Taint{
+		{Key: "nvidia.com/gpu", Value: "true", Effect: corev1.TaintEffectNoSchedule},
+		{Key: "spot", Effect: corev1.TaintEffectPreferNoSchedule},
+	}
+
+	assert.False(t, podAllowedOnNode(clusterPod{}, labels, taints))
+	assert.True(t, podAllowedOnNode(clusterPod{
+		tolerations: []corev1.Toleration{{Key: "nvidia.com/gpu", Operator: corev1.TolerationOpExists}},
+	}, labels, taints))
+	assert.False(t, podAllowedOnNode(clusterPod{
+		nodeSelector: map[string]string{"eks.amazonaws.com/nodegroup": "general"},
+		tolerations:  []corev1.Toleration{{Key: "nvidia.com/gpu", Operator: corev1.TolerationOpExists}},
+	}, labels, taints))
+}
+
+func TestPackPods(t *testing.T) {
+	gpuTaint := []corev1.Taint{{Key: "gpu", Effect: corev1.TaintEffectNoSchedule}}
+	bins := []*clusterBin{
+		{cpu: 2, memory: 4 * gib},
+		{cpu: 4, memory: 8 * gib, taints: gpuTaint},
+	}
+	pods := []clusterPod{
+		{workload: "default/api", cpu: 1, memory: 1 * gib},
+		{workload: "default/api", cpu: 1, memory: 1 * gib},
+		{workload: "default/trainer", cpu: 3, memory: 4 * gib, tolerations: []corev1.Toleration{{Key: "gpu", Operator: corev1.TolerationOpExists}}},
+		{workload: "default/batch", cpu: 1, memory: 1 * gib},
+	}
+
+	assert.Equal(t, []int{0, 0, 1, -1}, packPods(pods, bins))
+}
+
+func TestNodesNeeded(t *testing.T) {
+	pods := []clusterPod{
+		{cpu: 1.5, memory: 1 * gib},
+		{cpu: 1.5, memory: 1 * gib},
+		{cpu: 0.5, memory: 1 * gib},
+		{cpu: 0.5, memory: 1 * gib},
+	}
+	assert.Equal(t, 2, nodesNeeded(pods, 2, 4*gib))
+	assert.Equal(t, 1, nodesNeeded(pods, 4, 4*gib))
+	assert.Equal(t, -1, nodesNeeded(pods, 1, 4*gib))
+	assert.Equal(t, 0, nodesNeeded(nil, 1, 1))
+}
+
+func TestBestNodePool(t *testing.T) {
+	pool := clusterPool{
+		name:              "general",
+		provider:          kubernetesProviderEKS,
+		instanceType:      "m5.4xlarge",
+		nodes:             3,
+		cpu:               16,
+		memory:            64 * gib,
+		cpuAllocatable:    3 * 16 * 0.9,
+		memoryAllocatable: 3 * 64 * gib * 0.9,
+		monthlyCost:       3 * 0.768 * kubernetesHoursPerMonth,
+		daemonCPU:         0.2,
+		daemonMemory:      0.5 * gib,
+	}
+	for i := 0; i < 10; i++ {
+		pool.pods = append(pool.pods, clusterPod{workload: "default/api", cpu: 1, memory: 2 * gib})
+	}
+	opts := clusterOptions{cpuBreathingRoom: 10, memoryBreathingRoom: 10, minNodes: 2}
+
+	best := bestNodePool(pool, []nodePoolCandidate{
+		{instanceType: "m5.large", cpu: 2, memory: 8 * gib, hourlyCost: 0.096},
+		{instanceType: "m5.xlarge", cpu: 4, memory: 16 * gib, hourlyCost: 0.192},
+		{instanceType: "m5.2xlarge", cpu: 8, memory: 32 * gib, hourlyCost: 0.4},
+	}, opts)
+
+	if assert.NotNil(t, best) {
+		// m5.large fits a single pod per node, m5.xlarge three and m5.2xlarge six
+		assert.Equal(t, "m5.xlarge", best.InstanceType)
+		assert.Equal(t, 4, best.Nodes)
+		assert.InDelta(t, 4*0.192*kubernetesHoursPerMonth, best.MonthlyCost, 0.001)
+		assert.Less(t, best.MonthlyCost, pool.current().MonthlyCost)
+		assert.Greater(t, best.CPUHeadroomPercent, 0.0)
+	}
+
+	assert.Nil(t, bestNodePool(pool, []nodePoolCandidate{{instanceType: "t3.nano", cpu: 0.5, memory: 0.5 * gib, hourlyCost: 0.005}}, opts))
+}
+
+func TestNodePoolKeepReason(t *testing.T) {
+	pool := clusterPool{
+		provider:     kubernetesProviderGKE,
+		instanceType: "e2-standard-4",
+		labels:       map[string]string{"cloud.google.com/gke-nodepool": "default-pool"},
+	}
+	assert.Empty(t, nodePoolKeepReason(pool))
+
+	pool.pods = []clusterPod{{workload: "default/db", nodeSelector: map[string]string{"node.kubernetes.io/instance-type": "e2-standard-4"}}}
+	assert.Equal(t, "default/db is pinned to the instance type of the node pool", nodePoolKeepReason(pool))
+
+	pool.labels["cloud.google.com/gke-spot"] = "true"
+	assert.Equal(t, "Spot node pools are not resized", nodePoolKeepReason(pool))
+}
@@ -0,0 +1,16 @@
+package aws_elasticache
+
+var (
+	PreferenceNodeDBKey = map[string]string{
+		"vCPU":              "v_cpu",
+		"MemoryGB":          "memory_gb",
+		"NodeType":          "instance_type",
+		"InstanceFamily":    "instance_family",
+		"CurrentGeneration": "current_generation",
+	}
+
+	PreferenceNodeSpecialCond = map[string]string{
+		"vCPU":     ">=",
+		"MemoryGB": ">=",
+	}
+)
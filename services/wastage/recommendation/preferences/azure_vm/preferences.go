@@ -0,0 +1,24 @@
+package azure_vm
+
+var (
+	PreferenceInstanceKey = map[string]string{
+		"Region":                "region",
+		"vCPU":                  "v_cpu",
+		"MemoryGB":              "memory_gb",
+		"Family":                "family",
+		"OperatingSystem":       "operating_system",
+		"CPUArchitecture":       "cpu_architecture",
+		"PremiumIO":             "premium_io",
+		"AcceleratedNetworking": "accelerated_networking",
+	}
+
+	PreferenceInstanceSpecialCond = map[string]string{
+		"vCPU":     ">=",
+		"MemoryGB": ">=",
+	}
+
+	PreferenceDiskKey = map[string]string{
+		"Region": "region",
+		"Tier":   "tier",
+	}
+)
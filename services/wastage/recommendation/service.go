@@ -17,11 +17,18 @@ type Service struct {
 	gcpComputeMachineTypeRepo repo.GCPComputeMachineTypeRepo
 	gcpComputeDiskTypeRepo    repo.GCPComputeDiskTypeRepo
 	gcpComputeSKURepo         repo.GCPComputeSKURepo
+	azureVMSKURepo            repo.AzureVMSKURepo
+	azureManagedDiskTypeRepo  repo.AzureManagedDiskTypeRepo
+	elastiCacheNodeTypeRepo   repo.ElastiCacheNodeTypeRepo
+	lambdaPriceRepo           repo.LambdaPriceRepo
+	dynamoDBPriceRepo         repo.DynamoDBPriceRepo
+	awsIdleResourcePriceRepo  repo.AWSIdleResourcePriceRepo
+	awsCommitmentPriceRepo    repo.AWSCommitmentPriceRepo
 	openaiSvc                 *openai.Client
 	costSvc                   *cost.Service
 }
 
-func New(logger *zap.Logger, ec2InstanceRepo repo.EC2InstanceTypeRepo, ebsVolumeRepo repo.EBSVolumeTypeRepo, awsRDSDBInstanceRepo repo.RDSDBInstanceRepo, awsRDSDBStorageRepo repo.RDSDBStorageRepo, gcpComputeMachineTypeRepo repo.GCPComputeMachineTypeRepo, gcpComputeDiskTypeRepo repo.GCPComputeDiskTypeRepo, gcpComputeSKURepo repo.GCPComputeSKURepo, token string, costSvc *cost.Service) *Service {
+func New(logger *zap.Logger, ec2InstanceRepo repo.EC2InstanceTypeRepo, ebsVolumeRepo repo.EBSVolumeTypeRepo, awsRDSDBInstanceRepo repo.RDSDBInstanceRepo, awsRDSDBStorageRepo repo.RDSDBStorageRepo, gcpComputeMachineTypeRepo repo.GCPComputeMachineTypeRepo, gcpComputeDiskTypeRepo repo.GCPComputeDiskTypeRepo, gcpComputeSKURepo repo.GCPComputeSKURepo, azureVMSKURepo repo.AzureVMSKURepo, azureManagedDiskTypeRepo repo.AzureManagedDiskTypeRepo, elastiCacheNodeTypeRepo repo.ElastiCacheNodeTypeRepo, lambdaPriceRepo repo.LambdaPriceRepo, dynamoDBPriceRepo repo.DynamoDBPriceRepo, awsIdleResourcePriceRepo repo.AWSIdleResourcePriceRepo, awsCommitmentPriceRepo repo.AWSCommitmentPriceRepo, token string, costSvc *cost.Service) *Service {
 	return &Service{
 		logger:                    logger,
 		ec2InstanceRepo:           ec2InstanceRepo,
@@ -31,6 +38,13 @@ func New(logger *zap.Logger, ec2InstanceRepo repo.EC2InstanceTypeRepo, ebsVolume
 		gcpComputeMachineTypeRepo: gcpComputeMachineTypeRepo,
 		gcpComputeDiskTypeRepo:    gcpComputeDiskTypeRepo,
 		gcpComputeSKURepo:         gcpComputeSKURepo,
+		azureVMSKURepo:            azureVMSKURepo,
+		azureManagedDiskTypeRepo:  azureManagedDiskTypeRepo,
+		elastiCacheNodeTypeRepo:   elastiCacheNodeTypeRepo,
+		lambdaPriceRepo:           lambdaPriceRepo,
+		dynamoDBPriceRepo:         dynamoDBPriceRepo,
+		awsIdleResourcePriceRepo:  awsIdleResourcePriceRepo,
+		awsCommitmentPriceRepo:    awsCommitmentPriceRepo,
 		openaiSvc:                 openai.NewClient(token),
 		costSvc:                   costSvc,
 	}
@@ -8,8 +8,11 @@ import (
 	"google.golang.org/protobuf/types/known/wrapperspb"
 	"math"
 	"sort"
+	"time"
 )
 
+const awsHoursPerMonth = 730
+
 func funcP(a, b *float64, f func(aa, bb float64) float64) *float64 {
 	if a == nil && b == nil {
 		return nil
@@ -391,3 +394,94 @@ func extractGCPUsage(ts []*gcp.DataPoint) gcp.Usage {
 		Max: maxW,
 	}
 }
+
+func sumOfDatapoints(datapoints []types.Datapoint) *float64 {
+	hasNonNil := false
+	sum := 0.0
+	for _, dp := range datapoints {
+		if dp.Sum == nil {
+			continue
+		}
+		hasNonNil = true
+		sum += *dp.Sum
+	}
+	if !hasNonNil {
+		return nil
+	}
+	return &sum
+}
+
+// datapointsPeriod guesses the period of the datapoints from the smallest gap between two of them,
+// a single datapoint is considered to cover a day.
+func datapointsPeriod(datapoints []types.Datapoint) time.Duration {
+	var period time.Duration
+	for i := 1; i < len(datapoints); i++ {
+		if datapoints[i].Timestamp == nil || datapoints[i-1].Timestamp == nil {
+			continue
+		}
+		gap := datapoints[i].Timestamp.Sub(*datapoints[i-1].Timestamp)
+		if gap < 0 {
+			gap = -gap
+		}
+		if gap > 0 && (period == 0 || gap < period) {
+			period = gap
+		}
+	}
+	if period == 0 {
+		return 24 * time.Hour
+	}
+	return period
+}
+
+// datapointsWindow is the duration covered by the datapoints, including the period of the last one.
+func datapointsWindow(datapoints []types.Datapoint) time.Duration {
+	var first, last *time.Time
+	for _, dp := range datapoints {
+		if dp.Timestamp == nil {
+			continue
+		}
+		if first == nil || dp.Timestamp.Before(*first) {
+			first = dp.Timestamp
+		}
+		if last == nil || dp.Timestamp.After(*last) {
+			last = dp.Timestamp
+		}
+	}
+	if first == nil {
+		return 0
+	}
+	return last.Sub(*first) + datapointsPeriod(datapoints)
+}
+
+// sumToRateDatapoints turns the Sum of every datapoint into a per second rate stored in Average and Maximum,
+// so the rates can be summarized with extractUsage.
+func sumToRateDatapoints(datapoints []types.Datapoint) []types.Datapoint {
+	period := datapointsPeriod(datapoints).Seconds()
+	result := make([]types.Datapoint, 0, len(datapoints))
+	for _, dp := range datapoints {
+		if dp.Sum == nil {
+			continue
+		}
+		rate := *dp.Sum / period
+		result = append(result, types.Datapoint{
+			Timestamp: dp.Timestamp,
+			Average:   &rate,
+			Maximum:   &rate,
+			Minimum:   &rate,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Timestamp.Before(*result[j].Timestamp)
+	})
+	return result
+}
+
+// monthlyAmount scales the total Sum of the datapoints to a month of 730 hours.
+func monthlyAmount(datapoints []types.Datapoint) float64 {
+	sum := sumOfDatapoints(datapoints)
+	window := datapointsWindow(datapoints)
+	if sum == nil || window == 0 {
+		return 0
+	}
+	return *sum * (awsHoursPerMonth * float64(time.Hour)) / float64(window)
+}